@@ -20,6 +20,7 @@ var shippedSystemPackages = []string{
 	"exec",
 	"firewall",
 	"fs",
+	"integrity",
 	"inventory",
 	"log",
 	"netconfig",
@@ -44,6 +45,7 @@ var forwardSystemPackages = []string{
 	"catrust",
 	"dns",
 	"firewall",
+	"integrity",
 	"netconfig",
 	"smart",
 	"timesync",
@@ -80,8 +82,8 @@ func TestSystemCapabilityInventoryIsExact(t *testing.T) {
 	if !slices.Equal(got, shippedSystemPackages) {
 		t.Fatalf("shipped SDK system packages = %v, want exact target inventory %v", got, shippedSystemPackages)
 	}
-	if len(forwardSystemPackages) != 7 {
-		t.Fatalf("forward capability inventory has %d packages, want exactly 7", len(forwardSystemPackages))
+	if len(forwardSystemPackages) != 8 {
+		t.Fatalf("forward capability inventory has %d packages, want exactly 8", len(forwardSystemPackages))
 	}
 	for _, name := range forwardSystemPackages {
 		if !slices.Contains(shippedSystemPackages, name) {
@@ -0,0 +1,112 @@
+package archtest
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+// slogHandlerConstructors build a handler that writes attributes as given.
+// Outside logging/ they would bypass the RedactingHandler SetupLogger wraps
+// every logger in.
+var slogHandlerConstructors = map[string]bool{"NewTextHandler": true, "NewJSONHandler": true}
+
+// logMethods are the slog.Logger methods and package functions that emit a
+// record.
+var logMethods = map[string]bool{
+	"Debug": true, "Info": true, "Warn": true, "Error": true, "Log": true, "LogAttrs": true,
+	"DebugContext": true, "InfoContext": true, "WarnContext": true, "ErrorContext": true,
+}
+
+// TestLogHandlersAreRedacting locks the logging boundary: a writing slog
+// handler (slog.NewTextHandler / slog.NewJSONHandler) may only be constructed
+// in logging/, where SetupLogger wraps it in the RedactingHandler. A handler
+// built anywhere else would log exec.Secret, SealedValue and secret-named
+// attributes as the call site passed them. Tests are exempt: they build
+// handlers over buffers to assert on output.
+func TestLogHandlersAreRedacting(t *testing.T) {
+	root := moduleRoot(t)
+	files := walkGoFiles(t, root, func(rel string) bool {
+		return !strings.HasPrefix(rel, "gen/") && !strings.HasPrefix(rel, "archtest/")
+	})
+	if len(files) == 0 {
+		t.Fatal("matches-zero guard: walked zero Go files")
+	}
+
+	sanctioned := 0
+	for _, gf := range files {
+		slogName := importLocalName(gf, "log/slog")
+		if slogName == "" {
+			continue
+		}
+		ast.Inspect(gf.ast, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !slogHandlerConstructors[sel.Sel.Name] {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != slogName {
+				return true
+			}
+			if strings.HasPrefix(gf.rel, "logging/") {
+				sanctioned++
+				return true
+			}
+			t.Errorf("%s:%d: %s builds an unredacted log handler — use logging.SetupLogger, or wrap it in logging.NewRedactingHandler inside logging/",
+				gf.rel, gf.line(call), render(gf.fset, call))
+			return true
+		})
+	}
+	if sanctioned == 0 {
+		t.Fatal("matches-zero guard: found no handler construction in logging/ — the detector is dead or SetupLogger moved")
+	}
+}
+
+// TestLogCallsNeverReveal forbids handing a Reveal() result to a log call.
+// The redacting handler catches an exec.Secret by type and a secret by key
+// name, but a revealed plaintext under an innocuous key is just a string; the
+// only defence is never producing it at a log call.
+func TestLogCallsNeverReveal(t *testing.T) {
+	root := moduleRoot(t)
+	files := walkGoFiles(t, root, func(rel string) bool {
+		return !strings.HasPrefix(rel, "gen/") && !strings.HasPrefix(rel, "archtest/")
+	})
+	if len(files) == 0 {
+		t.Fatal("matches-zero guard: walked zero Go files")
+	}
+
+	sawLogCall := false
+	for _, gf := range files {
+		ast.Inspect(gf.ast, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !logMethods[sel.Sel.Name] {
+				return true
+			}
+			sawLogCall = true
+			for _, arg := range call.Args {
+				ast.Inspect(arg, func(n ast.Node) bool {
+					inner, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					if s, ok := inner.Fun.(*ast.SelectorExpr); ok && s.Sel.Name == "Reveal" && len(inner.Args) == 0 {
+						t.Errorf("%s:%d: %s logs a revealed secret — log the Secret itself, which redacts",
+							gf.rel, gf.line(call), render(gf.fset, inner))
+					}
+					return true
+				})
+			}
+			return true
+		})
+	}
+	if !sawLogCall {
+		t.Fatal("matches-zero guard: found no log calls anywhere — the detector is dead")
+	}
+}
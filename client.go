@@ -3,6 +3,7 @@ package sdk
 
 import (
 	"context"
+	"crypto/ecdh"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -43,6 +44,11 @@ type Client struct {
 	authToken string
 	logger    *slog.Logger
 
+	// sealingPublicKey returns the agent's current X25519 recipient public
+	// key for Hello. A function rather than a value so a rotated key is
+	// announced on the next connect without rebuilding the client.
+	sealingPublicKey func() *ecdh.PublicKey
+
 	// httpClient is the underlying transport carrier, retained so the agent
 	// can release its idle connections on reconnect (CloseIdleConnections) and
 	// not leak a transport per reconnect attempt (WS13 #8).
@@ -196,6 +202,16 @@ func WithAuth(deviceID, authToken string) ClientOption {
 	}}
 }
 
+// WithSealingPublicKey sets where Hello reads the agent's current X25519
+// recipient public key from — normally the agent keyring's PublicKey method.
+// Hello is refused without it: control seals every secret it sends to the
+// key the latest Hello announced.
+func WithSealingPublicKey(current func() *ecdh.PublicKey) ClientOption {
+	return &funcOption{func(c *Client, _ **http.Client) {
+		c.sealingPublicKey = current
+	}}
+}
+
 // WithLogger sets a custom structured logger for the client.
 func WithLogger(l *slog.Logger) ClientOption {
 	return &funcOption{func(c *Client, _ **http.Client) {
@@ -429,6 +445,11 @@ func RegisterAgent(ctx context.Context, controlURL string, token, hostname, agen
 	if err != nil {
 		return nil, fmt.Errorf("register: %w", err)
 	}
+	// The agent pins this key and seals to it; refuse one that is not an
+	// X25519 public key rather than persist it.
+	if _, err := ecdh.X25519().NewPublicKey(resp.Msg.ControlSealingPublicKey); err != nil {
+		return nil, fmt.Errorf("register: invalid control sealing public key: %w", err)
+	}
 
 	return &RegisterAgentResult{
 		DeviceID:                resp.Msg.DeviceId.GetValue(),
@@ -653,22 +674,36 @@ func (c *Client) send(ctx context.Context, msg *pm.AgentMessage) error {
 	}
 }
 
-// SendHello sends a hello message to the server.
+// ErrNoSealingPublicKey is returned by SendHello when the client was built
+// without WithSealingPublicKey.
+var ErrNoSealingPublicKey = errors.New("no sealing public key configured (WithSealingPublicKey)")
+
+// SendHello sends a hello message to the server, announcing the agent's
+// current sealing public key.
 func (c *Client) SendHello(ctx context.Context, hostname, agentVersion string) error {
 	c.mu.RLock()
 	deviceID := c.deviceID
 	authToken := c.authToken
 	c.mu.RUnlock()
 
+	if c.sealingPublicKey == nil {
+		return ErrNoSealingPublicKey
+	}
+	sealingPublicKey := c.sealingPublicKey()
+	if sealingPublicKey == nil {
+		return ErrNoSealingPublicKey
+	}
+
 	return c.send(ctx, &pm.AgentMessage{
 		Id: NewULID(),
 		Payload: &pm.AgentMessage_Hello{
 			Hello: &pm.Hello{
-				DeviceId:     &pm.DeviceId{Value: deviceID},
-				AgentVersion: agentVersion,
-				Hostname:     hostname,
-				AuthToken:    authToken,
-				Arch:         runtime.GOARCH,
+				DeviceId:              &pm.DeviceId{Value: deviceID},
+				AgentVersion:          agentVersion,
+				Hostname:              hostname,
+				AuthToken:             authToken,
+				Arch:                  runtime.GOARCH,
+				AgentSealingPublicKey: sealingPublicKey.Bytes(),
 			},
 		},
 	})
@@ -1403,6 +1438,12 @@ func (c *Client) dispatchServerMessage(ctx context.Context, msg *pm.ServerMessag
 			c.logger.Warn("dropping Welcome with nil payload", "message_id", msg.Id)
 			return nil
 		}
+		// Welcome is a lifecycle arm, but it carries the sealing key the
+		// agent pins: a malformed one must not reach OnWelcome.
+		if err := c.validateInbound(p.Welcome); err != nil {
+			c.logger.Warn("dropping invalid Welcome", "message_id", msg.Id, "error", err)
+			return nil
+		}
 		c.applyWelcomeHeartbeat(p.Welcome)
 		if err := handler.OnWelcome(ctx, p.Welcome); err != nil {
 			return fmt.Errorf("handle welcome: %w", err)
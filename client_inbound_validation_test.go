@@ -135,6 +135,44 @@ func TestDispatch_RejectsInvalidInboundCommands(t *testing.T) {
 	}
 }
 
+// Welcome carries control's sealing key, which the agent pins: a malformed
+// key is dropped before OnWelcome, while an absent one — from a control that
+// predates sealing keys — still gets through.
+func TestDispatch_RejectsInvalidWelcome(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		key  []byte
+		want int32
+	}{
+		{"short_key_dropped", make([]byte, 31), 0},
+		{"valid_key", make([]byte, 32), 1},
+		{"no_key", nil, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewClient("https://gw.invalid", WithAuth(validULID, ""))
+			h := &welcomeCounter{}
+			msg := &pm.ServerMessage{Id: "m", Payload: &pm.ServerMessage_Welcome{
+				Welcome: &pm.Welcome{ServerVersion: "test", ControlSealingPublicKey: tc.key}}}
+			if err := c.dispatchServerMessage(context.Background(), msg, h); err != nil {
+				t.Fatalf("dispatch: %v", err)
+			}
+			if got := atomic.LoadInt32(&h.welcomes); got != tc.want {
+				t.Errorf("OnWelcome called %d time(s), want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+type welcomeCounter struct {
+	recordingHandler
+	welcomes int32
+}
+
+func (h *welcomeCounter) OnWelcome(context.Context, *pm.Welcome) error {
+	atomic.AddInt32(&h.welcomes, 1)
+	return nil
+}
+
 // settle waits a short, fixed period for a dispatch-spawned goroutine to have
 // run, so a "handler must NOT be called" assertion is not racing the spawn.
 func settle() { time.Sleep(150 * time.Millisecond) }
@@ -149,6 +187,8 @@ func settle() { time.Sleep(150 * time.Millisecond) }
 //     validated at the request site, not at dispatch; and
 //   - connection-lifecycle arms (OnWelcome / OnError) — these carry no
 //     operator-issued command parameters that drive privileged device work.
+//     Welcome is validated regardless, for the sealing key it carries
+//     (TestDispatch_RejectsInvalidWelcome).
 //
 // The set is discovered from the proto descriptor + the dispatch AST, with a
 // matches-zero guard and a guard that every discovered arm is actually handled
@@ -3,6 +3,8 @@ package sdk
 import (
 	"bytes"
 	"context"
+	"crypto/ecdh"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -10,6 +12,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -33,6 +36,9 @@ type agentLoopback struct {
 	serverURL  string
 	handler    *recordingAgentHandler
 	httpClient *http.Client
+	// sealingKey is the agent sealing key every loopback client announces
+	// in Hello.
+	sealingKey *ecdh.PrivateKey
 }
 
 // recordingAgentHandler is the server-side AgentServiceHandler the SDK Client
@@ -113,16 +119,22 @@ func newAgentLoopback(t *testing.T) *agentLoopback {
 		},
 	}
 
+	sealingKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate sealing key: %v", err)
+	}
+
 	return &agentLoopback{
 		srv:        srv,
 		serverURL:  srv.URL,
 		handler:    handler,
 		httpClient: hc,
+		sealingKey: sealingKey,
 	}
 }
 
 func (l *agentLoopback) newClient(extra ...ClientOption) *Client {
-	opts := append([]ClientOption{WithHTTPClient(l.httpClient)}, extra...)
+	opts := append([]ClientOption{WithHTTPClient(l.httpClient), WithSealingPublicKey(l.sealingKey.PublicKey)}, extra...)
 	return NewClient(l.serverURL, opts...)
 }
 
@@ -240,6 +252,26 @@ var controlSealingKey = bytes.Repeat([]byte{0x02}, 32)
 // rejected at the boundary rather than exercising the path under test.
 func testSealingPubKey() []byte { return bytes.Repeat([]byte{0x01}, 32) }
 
+// The agent pins the key RegisterAgent returns, so one that is not a 32-byte
+// X25519 key fails the registration instead of being persisted.
+func TestRegisterAgent_RejectsInvalidControlSealingKey(t *testing.T) {
+	for name, key := range map[string][]byte{"missing": nil, "short": controlSealingKey[:31]} {
+		t.Run(name, func(t *testing.T) {
+			cl := newControlLoopback(t)
+			cl.handler.registerFn = func(*connect.Request[pm.RegisterRequest]) (*connect.Response[pm.RegisterResponse], error) {
+				return connect.NewResponse(&pm.RegisterResponse{
+					DeviceId:                &pm.DeviceId{Value: "01HXXXXXXXXXXXXXXXXXXXXXX0"},
+					ControlSealingPublicKey: key,
+				}), nil
+			}
+			got, err := RegisterAgent(context.Background(), cl.serverURL, "token-x", "host-1", "v1.2.3", []byte("csr"), testSealingPubKey())
+			if err == nil || !strings.Contains(err.Error(), "control sealing public key") {
+				t.Fatalf("RegisterAgent = %+v, %v; want an invalid key error", got, err)
+			}
+		})
+	}
+}
+
 func TestRegisterAgent_ServerErrorPropagates(t *testing.T) {
 	cl := newControlLoopback(t)
 	cl.handler.registerFn = func(_ *connect.Request[pm.RegisterRequest]) (*connect.Response[pm.RegisterResponse], error) {
@@ -530,6 +562,75 @@ func TestConcurrentSend_PreservesEveryMessage(t *testing.T) {
 	}
 }
 
+// SendHello reads the sealing key from the provider on every call, so a Hello
+// sent after a keyring rotation announces the new key without rebuilding the
+// client. Announcing a stale key would have control seal to a key the agent
+// prunes at the end of the overlap.
+func TestSendHello_AnnouncesCurrentSealingKey(t *testing.T) {
+	l := newAgentLoopback(t)
+	next, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate sealing key: %v", err)
+	}
+	var current atomic.Pointer[ecdh.PrivateKey]
+	current.Store(l.sealingKey)
+	c := l.newClient(WithAuth("device-x", "tok"), WithSealingPublicKey(func() *ecdh.PublicKey {
+		return current.Load().PublicKey()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := c.SendHello(ctx, "h", "v"); err != nil {
+		t.Fatalf("SendHello: %v", err)
+	}
+	current.Store(next)
+	if err := c.SendHello(ctx, "h", "v"); err != nil {
+		t.Fatalf("SendHello after rotation: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var got []*pm.AgentMessage
+	for {
+		got = l.handler.snapshot()
+		if len(got) >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(got) != 2 {
+		t.Fatalf("received %d messages, want 2", len(got))
+	}
+	for i, want := range [][]byte{l.sealingKey.PublicKey().Bytes(), next.PublicKey().Bytes()} {
+		if announced := got[i].GetHello().GetAgentSealingPublicKey(); !bytes.Equal(announced, want) {
+			t.Errorf("Hello %d announced %x, want %x", i, announced, want)
+		}
+	}
+}
+
+// Hello without a sealing key fails validation on control, so SendHello
+// refuses before the frame leaves the agent.
+func TestSendHello_WithoutSealingKey_Errors(t *testing.T) {
+	l := newAgentLoopback(t)
+	c := l.newClient(WithAuth("device-x", "tok"), WithSealingPublicKey(nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := c.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.SendHello(ctx, "h", "v"); !errors.Is(err, ErrNoSealingPublicKey) {
+		t.Fatalf("SendHello = %v, want ErrNoSealingPublicKey", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Server-side oddities the SDK must survive
 // ---------------------------------------------------------------------------
@@ -566,7 +667,7 @@ func TestRun_UnknownServerMessage_DoesNotTerminate(t *testing.T) {
 		// Push a Welcome so we can observe end-to-end dispatch worked.
 		if err := s.Send(&pm.ServerMessage{
 			Id:      NewULID(),
-			Payload: &pm.ServerMessage_Welcome{Welcome: &pm.Welcome{}},
+			Payload: &pm.ServerMessage_Welcome{Welcome: &pm.Welcome{ServerVersion: "test"}},
 		}); err != nil {
 			return err
 		}
@@ -704,7 +805,10 @@ func startMTLSTestServer(t *testing.T, serverCertPEM, serverKeyPEM []byte, clien
 	}
 	handler := &recordingControlHandler{}
 	handler.registerFn = func(*connect.Request[pm.RegisterRequest]) (*connect.Response[pm.RegisterResponse], error) {
-		return connect.NewResponse(&pm.RegisterResponse{DeviceId: &pm.DeviceId{Value: "ok"}}), nil
+		return connect.NewResponse(&pm.RegisterResponse{
+			DeviceId:                &pm.DeviceId{Value: "ok"},
+			ControlSealingPublicKey: controlSealingKey,
+		}), nil
 	}
 	path, h := powermanagev1connect.NewControlServiceHandler(handler)
 	mux := http.NewServeMux()
@@ -813,7 +917,10 @@ func TestWithMTLSFromPEMAndSystemRoots_RejectsUntrustedServer(t *testing.T) {
 func TestWithHTTPClient_AppliedToControlCalls(t *testing.T) {
 	cl := newControlLoopback(t)
 	cl.handler.registerFn = func(_ *connect.Request[pm.RegisterRequest]) (*connect.Response[pm.RegisterResponse], error) {
-		return connect.NewResponse(&pm.RegisterResponse{DeviceId: &pm.DeviceId{Value: "id"}}), nil
+		return connect.NewResponse(&pm.RegisterResponse{
+			DeviceId:                &pm.DeviceId{Value: "id"},
+			ControlSealingPublicKey: controlSealingKey,
+		}), nil
 	}
 
 	var called atomic.Int32
@@ -28,11 +28,11 @@ func quietLogger() *slog.Logger { return slog.New(slog.NewTextHandler(io.Discard
 
 // testSealedValue is a SealedValue long enough to pass the `min=61` boundary
 // check (crypto.MinSealedLen: 32-byte ephemeral key + 12-byte nonce + 16-byte
-// tag + at least one plaintext byte). A shorter fixture would be rejected at
-// validateInbound, so a test using one would assert the rejection path while
-// claiming to exercise the happy one.
+// tag + at least one plaintext byte), with a crypto.KeyIDLen key id. A shorter
+// fixture would be rejected at validateInbound, so a test using one would
+// assert the rejection path while claiming to exercise the happy one.
 func testSealedValue() *pm.SealedValue {
-	return &pm.SealedValue{Version: 1, Ciphertext: bytes.Repeat([]byte{0x7f}, 61)}
+	return &pm.SealedValue{Version: 1, Ciphertext: bytes.Repeat([]byte{0x7f}, 61), KeyId: bytes.Repeat([]byte{0x5a}, 8)}
 }
 
 // Every Client method that sends a stream request and then BLOCKS on a pending
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1/powermanagev1connect"
+)
+
+const (
+	reportFormatJSON = "json"
+	reportFormatCSV  = "csv"
+	reportFormatHTML = "html"
+
+	// reportPageSize is the largest page ListDevices accepts.
+	reportPageSize = 100
+	// ungroupedName labels devices that belong to no device group.
+	ungroupedName = "(ungrouped)"
+)
+
+func (a *app) complianceCommand() *cobra.Command {
+	command := &cobra.Command{Use: "compliance", Short: "Report on device compliance"}
+	var policyID, groupID, format string
+	report := &cobra.Command{
+		Use:   "report",
+		Short: "Aggregate compliance per rule and per device group",
+		Long: "Fetch the compliance policy status of every device (or every member of --group),\n" +
+			"aggregate it per policy rule and per device group, and flag rules past their grace period.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if format != reportFormatJSON && format != reportFormatCSV && format != reportFormatHTML {
+				return fmt.Errorf("report format %q must be json, csv or html", format)
+			}
+			if a.output != "" && format != reportFormatJSON {
+				return fmt.Errorf("-o formats the JSON report; it cannot be combined with --format %s", format)
+			}
+			devices, err := a.loadComplianceDevices(cmd.Context(), groupID)
+			if err != nil {
+				return err
+			}
+			result := buildComplianceReport(devices, policyID, groupID, a.now())
+			var out bytes.Buffer
+			switch format {
+			case reportFormatCSV:
+				err = writeComplianceCSV(&out, result)
+			case reportFormatHTML:
+				err = complianceHTML.Execute(&out, result)
+			default:
+				return a.writeDocument(result, nil)
+			}
+			if err != nil {
+				return fmt.Errorf("render compliance report: %w", err)
+			}
+			if _, err := out.WriteTo(a.stdout); err != nil {
+				return fmt.Errorf("write compliance report: %w", err)
+			}
+			return nil
+		},
+	}
+	report.Flags().StringVar(&policyID, "policy", "", "report only this compliance policy ID (default: every policy)")
+	report.Flags().StringVar(&groupID, "group", "", "report only members of this device group ID (default: every device)")
+	report.Flags().StringVar(&format, "format", reportFormatJSON, "report format: json, csv or html")
+	command.AddCommand(report)
+	return command
+}
+
+// complianceDevice is one device's policy evaluation and the device groups it
+// is counted under.
+type complianceDevice struct {
+	id       string
+	hostname string
+	groups   []complianceGroup
+	status   *pmv1.GetDeviceCompliancePolicyStatusResponse
+}
+
+type complianceGroup struct {
+	id   string
+	name string
+}
+
+func (a *app) loadComplianceDevices(ctx context.Context, groupID string) ([]complianceDevice, error) {
+	var devices []complianceDevice
+	if groupID != "" {
+		response, err := callAuthenticated(ctx, a, &pmv1.GetDeviceGroupRequest{Id: groupID},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetDeviceGroupRequest]) (*connect.Response[pmv1.GetDeviceGroupResponse], error) {
+				return c.GetDeviceGroup(ctx, r)
+			})
+		if err != nil {
+			return nil, err
+		}
+		group := complianceGroup{id: groupID, name: response.Msg.GetGroup().GetName()}
+		for _, member := range response.Msg.Devices {
+			devices = append(devices, complianceDevice{id: member.DeviceId, hostname: member.Hostname, groups: []complianceGroup{group}})
+		}
+	} else {
+		listed, err := a.listAllDevices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, device := range listed {
+			response, err := callAuthenticated(ctx, a, &pmv1.ListDeviceGroupsForDeviceRequest{DeviceId: device.Id},
+				func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListDeviceGroupsForDeviceRequest]) (*connect.Response[pmv1.ListDeviceGroupsForDeviceResponse], error) {
+					return c.ListDeviceGroupsForDevice(ctx, r)
+				})
+			if err != nil {
+				return nil, err
+			}
+			entry := complianceDevice{id: device.Id, hostname: device.Hostname}
+			for _, group := range response.Msg.Groups {
+				entry.groups = append(entry.groups, complianceGroup{id: group.Id, name: group.Name})
+			}
+			devices = append(devices, entry)
+		}
+	}
+	for i := range devices {
+		response, err := callAuthenticated(ctx, a, &pmv1.GetDeviceCompliancePolicyStatusRequest{DeviceId: devices[i].id},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetDeviceCompliancePolicyStatusRequest]) (*connect.Response[pmv1.GetDeviceCompliancePolicyStatusResponse], error) {
+				return c.GetDeviceCompliancePolicyStatus(ctx, r)
+			})
+		if err != nil {
+			return nil, fmt.Errorf("compliance status of device %s: %w", devices[i].id, err)
+		}
+		devices[i].status = response.Msg
+	}
+	return devices, nil
+}
+
+func (a *app) listAllDevices(ctx context.Context) ([]*pmv1.Device, error) {
+	var devices []*pmv1.Device
+	seen := map[string]bool{}
+	pageToken := ""
+	for {
+		response, err := callAuthenticated(ctx, a, &pmv1.ListDevicesRequest{PageSize: reportPageSize, PageToken: pageToken},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListDevicesRequest]) (*connect.Response[pmv1.ListDevicesResponse], error) {
+				return c.ListDevices(ctx, r)
+			})
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, response.Msg.Devices...)
+		pageToken = response.Msg.NextPageToken
+		if pageToken == "" {
+			return devices, nil
+		}
+		if seen[pageToken] {
+			return nil, errors.New("control repeated a device page token")
+		}
+		seen[pageToken] = true
+	}
+}
+
+// complianceReport is the aggregated report. Its JSON form is the report's
+// stable machine-readable format.
+type complianceReport struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	PolicyID    string                 `json:"policy_id,omitempty"`
+	GroupID     string                 `json:"group_id,omitempty"`
+	Devices     int                    `json:"devices"`
+	Rules       []complianceRuleRow    `json:"rules"`
+	Groups      []complianceGroupRow   `json:"groups"`
+	Results     []complianceResultItem `json:"results"`
+}
+
+// complianceCounts tallies statuses. PastGrace counts the failures whose
+// grace period has run out.
+type complianceCounts struct {
+	Compliant     int `json:"compliant"`
+	NonCompliant  int `json:"non_compliant"`
+	InGracePeriod int `json:"in_grace_period"`
+	Unknown       int `json:"unknown"`
+	PastGrace     int `json:"past_grace"`
+}
+
+func (c *complianceCounts) add(status pmv1.ComplianceStatus, pastGrace bool) {
+	switch status {
+	case pmv1.ComplianceStatus_COMPLIANCE_STATUS_COMPLIANT:
+		c.Compliant++
+	case pmv1.ComplianceStatus_COMPLIANCE_STATUS_NON_COMPLIANT:
+		c.NonCompliant++
+	case pmv1.ComplianceStatus_COMPLIANCE_STATUS_IN_GRACE_PERIOD:
+		c.InGracePeriod++
+	default:
+		c.Unknown++
+	}
+	if pastGrace {
+		c.PastGrace++
+	}
+}
+
+type complianceRuleRow struct {
+	PolicyID         string `json:"policy_id"`
+	PolicyName       string `json:"policy_name"`
+	ActionID         string `json:"action_id"`
+	ActionName       string `json:"action_name"`
+	GracePeriodHours int32  `json:"grace_period_hours"`
+	complianceCounts
+}
+
+// complianceGroupRow counts devices, by their status under the reported
+// policy (or their overall status), per device group.
+type complianceGroupRow struct {
+	GroupID   string `json:"group_id,omitempty"`
+	GroupName string `json:"group_name"`
+	complianceCounts
+}
+
+// complianceResultItem is one rule evaluated on one device.
+type complianceResultItem struct {
+	DeviceID         string     `json:"device_id"`
+	Hostname         string     `json:"hostname"`
+	Groups           []string   `json:"groups"`
+	PolicyID         string     `json:"policy_id"`
+	PolicyName       string     `json:"policy_name"`
+	ActionID         string     `json:"action_id"`
+	ActionName       string     `json:"action_name"`
+	Status           string     `json:"status"`
+	GracePeriodHours int32      `json:"grace_period_hours"`
+	CheckedAt        *time.Time `json:"checked_at,omitempty"`
+	FirstFailedAt    *time.Time `json:"first_failed_at,omitempty"`
+	GraceExpiresAt   *time.Time `json:"grace_expires_at,omitempty"`
+	PastGrace        bool       `json:"past_grace"`
+}
+
+// buildComplianceReport aggregates device evaluations. policyID, when set,
+// limits the report to that policy; groupID is recorded for the reader.
+// Rules, groups and results are sorted so two runs over the same data
+// produce the same report.
+func buildComplianceReport(devices []complianceDevice, policyID, groupID string, now time.Time) complianceReport {
+	report := complianceReport{GeneratedAt: now.UTC(), PolicyID: policyID, GroupID: groupID}
+	rules := map[[2]string]*complianceRuleRow{}
+	groups := map[string]*complianceGroupRow{}
+	for _, device := range devices {
+		deviceStatus := device.status.GetOverallStatus()
+		devicePastGrace := false
+		reported := policyID == ""
+		groupNames := make([]string, 0, len(device.groups))
+		for _, group := range device.groups {
+			groupNames = append(groupNames, group.name)
+		}
+		for _, policy := range device.status.GetPolicies() {
+			if policyID != "" && policy.PolicyId != policyID {
+				continue
+			}
+			if policyID != "" {
+				deviceStatus, reported = policy.Status, true
+			}
+			for _, rule := range policy.Rules {
+				pastGrace := rulePastGrace(rule, now)
+				devicePastGrace = devicePastGrace || pastGrace
+				key := [2]string{policy.PolicyId, rule.ActionId}
+				row, ok := rules[key]
+				if !ok {
+					row = &complianceRuleRow{
+						PolicyID: policy.PolicyId, PolicyName: policy.PolicyName,
+						ActionID: rule.ActionId, ActionName: rule.ActionName, GracePeriodHours: rule.GracePeriodHours,
+					}
+					rules[key] = row
+				}
+				row.add(rule.Status, pastGrace)
+				report.Results = append(report.Results, complianceResultItem{
+					DeviceID: device.id, Hostname: device.hostname, Groups: groupNames,
+					PolicyID: policy.PolicyId, PolicyName: policy.PolicyName,
+					ActionID: rule.ActionId, ActionName: rule.ActionName,
+					Status:           trimEnumPrefix("ComplianceStatus", protoreflect.Name(rule.Status.String())),
+					GracePeriodHours: rule.GracePeriodHours,
+					CheckedAt:        optionalTime(rule.CheckedAt.IsValid(), rule.CheckedAt.AsTime()),
+					FirstFailedAt:    optionalTime(rule.FirstFailedAt.IsValid(), rule.FirstFailedAt.AsTime()),
+					GraceExpiresAt:   optionalTime(rule.GraceExpiresAt.IsValid(), rule.GraceExpiresAt.AsTime()),
+					PastGrace:        pastGrace,
+				})
+			}
+		}
+		if !reported {
+			continue
+		}
+		report.Devices++
+		memberships := device.groups
+		if len(memberships) == 0 {
+			memberships = []complianceGroup{{name: ungroupedName}}
+		}
+		for _, group := range memberships {
+			row, ok := groups[group.id]
+			if !ok {
+				row = &complianceGroupRow{GroupID: group.id, GroupName: group.name}
+				groups[group.id] = row
+			}
+			row.add(deviceStatus, devicePastGrace)
+		}
+	}
+	for _, row := range rules {
+		report.Rules = append(report.Rules, *row)
+	}
+	slices.SortFunc(report.Rules, func(a, b complianceRuleRow) int {
+		return strings.Compare(a.PolicyName+"\x00"+a.ActionName+"\x00"+a.ActionID, b.PolicyName+"\x00"+b.ActionName+"\x00"+b.ActionID)
+	})
+	for _, row := range groups {
+		report.Groups = append(report.Groups, *row)
+	}
+	slices.SortFunc(report.Groups, func(a, b complianceGroupRow) int {
+		return strings.Compare(a.GroupName+"\x00"+a.GroupID, b.GroupName+"\x00"+b.GroupID)
+	})
+	slices.SortStableFunc(report.Results, func(a, b complianceResultItem) int {
+		return strings.Compare(a.Hostname+"\x00"+a.DeviceID, b.Hostname+"\x00"+b.DeviceID)
+	})
+	if report.Rules == nil {
+		report.Rules = []complianceRuleRow{}
+	}
+	if report.Groups == nil {
+		report.Groups = []complianceGroupRow{}
+	}
+	if report.Results == nil {
+		report.Results = []complianceResultItem{}
+	}
+	return report
+}
+
+// rulePastGrace reports whether a failing rule has outlived its grace period.
+// A NON_COMPLIANT rule is past grace by definition; an IN_GRACE_PERIOD rule
+// is past grace once its deadline has gone by, which happens when the last
+// evaluation predates the deadline.
+func rulePastGrace(rule *pmv1.DevicePolicyRuleEvaluation, now time.Time) bool {
+	switch rule.Status {
+	case pmv1.ComplianceStatus_COMPLIANCE_STATUS_NON_COMPLIANT:
+		return true
+	case pmv1.ComplianceStatus_COMPLIANCE_STATUS_IN_GRACE_PERIOD:
+		deadline := rule.GraceExpiresAt
+		if !deadline.IsValid() {
+			if !rule.FirstFailedAt.IsValid() {
+				return false
+			}
+			return !now.Before(rule.FirstFailedAt.AsTime().Add(time.Duration(rule.GracePeriodHours) * time.Hour))
+		}
+		return !now.Before(deadline.AsTime())
+	default:
+		return false
+	}
+}
+
+func optionalTime(valid bool, t time.Time) *time.Time {
+	if !valid {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// writeComplianceCSV writes one row per rule per device, the granularity
+// auditors sample from.
+func writeComplianceCSV(w io.Writer, report complianceReport) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{
+		"device_id", "hostname", "groups", "policy_id", "policy_name", "action_id", "action_name",
+		"status", "grace_period_hours", "checked_at", "first_failed_at", "grace_expires_at", "past_grace",
+	}); err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		record := []string{
+			result.DeviceID, result.Hostname, strings.Join(result.Groups, ";"),
+			result.PolicyID, result.PolicyName, result.ActionID, result.ActionName,
+			result.Status, strconv.Itoa(int(result.GracePeriodHours)),
+			formatReportTime(result.CheckedAt), formatReportTime(result.FirstFailedAt), formatReportTime(result.GraceExpiresAt),
+			strconv.FormatBool(result.PastGrace),
+		}
+		for i, cell := range record {
+			record[i] = csvSafeCell(cell)
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// csvSafeCell keeps a spreadsheet from evaluating a cell as a formula.
+// Hostnames, group and policy names are chosen by whoever controls a device
+// or policy, and auditors open the CSV in a spreadsheet, so a cell starting
+// with a formula character is prefixed with ' and reads as text.
+func csvSafeCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func formatReportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+var complianceHTML = template.Must(template.New("compliance").Funcs(template.FuncMap{
+	"time": formatReportTime,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Compliance report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #999; padding: 0.3em 0.6em; text-align: left; }
+th { background: #eee; }
+.past-grace { background: #fdd; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Compliance report</h1>
+<p>Generated {{.GeneratedAt.Format "2006-01-02T15:04:05Z07:00"}} for {{.Devices}} device(s){{if .PolicyID}}, policy {{.PolicyID}}{{end}}{{if .GroupID}}, device group {{.GroupID}}{{end}}.</p>
+<h2>Rules</h2>
+<table>
+<tr><th>Policy</th><th>Rule</th><th>Grace (hours)</th><th>Compliant</th><th>Non-compliant</th><th>In grace</th><th>Unknown</th><th>Past grace</th></tr>
+{{range .Rules}}<tr{{if .PastGrace}} class="past-grace"{{end}}><td>{{.PolicyName}}</td><td>{{.ActionName}}</td><td>{{.GracePeriodHours}}</td><td>{{.Compliant}}</td><td>{{.NonCompliant}}</td><td>{{.InGracePeriod}}</td><td>{{.Unknown}}</td><td>{{.PastGrace}}</td></tr>
+{{end}}</table>
+<h2>Device groups</h2>
+<table>
+<tr><th>Group</th><th>Compliant</th><th>Non-compliant</th><th>In grace</th><th>Unknown</th><th>Past grace</th></tr>
+{{range .Groups}}<tr{{if .PastGrace}} class="past-grace"{{end}}><td>{{.GroupName}}</td><td>{{.Compliant}}</td><td>{{.NonCompliant}}</td><td>{{.InGracePeriod}}</td><td>{{.Unknown}}</td><td>{{.PastGrace}}</td></tr>
+{{end}}</table>
+<h2>Rules past their grace period</h2>
+<table>
+<tr><th>Device</th><th>Groups</th><th>Policy</th><th>Rule</th><th>Status</th><th>First failed</th><th>Grace expired</th><th>Checked</th></tr>
+{{range .Results}}{{if .PastGrace}}<tr class="past-grace"><td>{{.Hostname}} ({{.DeviceID}})</td><td>{{range $i, $g := .Groups}}{{if $i}}, {{end}}{{$g}}{{end}}</td><td>{{.PolicyName}}</td><td>{{.ActionName}}</td><td>{{.Status}}</td><td>{{time .FirstFailedAt}}</td><td>{{time .GraceExpiresAt}}</td><td>{{time .CheckedAt}}</td></tr>
+{{end}}{{end}}</table>
+</body>
+</html>
+`))
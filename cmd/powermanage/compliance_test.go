@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1/powermanagev1connect"
+)
+
+var complianceNow = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func complianceFixture() map[string]*pmv1.GetDeviceCompliancePolicyStatusResponse {
+	rule := func(id string, status pmv1.ComplianceStatus, graceExpires time.Time) *pmv1.DevicePolicyRuleEvaluation {
+		evaluation := &pmv1.DevicePolicyRuleEvaluation{ActionId: id, ActionName: "rule " + id, Status: status, GracePeriodHours: 24}
+		if !graceExpires.IsZero() {
+			evaluation.GraceExpiresAt = timestamppb.New(graceExpires)
+		}
+		return evaluation
+	}
+	compliant := pmv1.ComplianceStatus_COMPLIANCE_STATUS_COMPLIANT
+	inGrace := pmv1.ComplianceStatus_COMPLIANCE_STATUS_IN_GRACE_PERIOD
+	return map[string]*pmv1.GetDeviceCompliancePolicyStatusResponse{
+		"device-1": {OverallStatus: compliant, Policies: []*pmv1.DevicePolicyEvaluation{
+			{PolicyId: "policy-disk", PolicyName: "Disk", Status: compliant, Rules: []*pmv1.DevicePolicyRuleEvaluation{
+				rule("luks", compliant, time.Time{}),
+			}},
+		}},
+		"device-2": {OverallStatus: pmv1.ComplianceStatus_COMPLIANCE_STATUS_NON_COMPLIANT, Policies: []*pmv1.DevicePolicyEvaluation{
+			{PolicyId: "policy-disk", PolicyName: "Disk", Status: inGrace, Rules: []*pmv1.DevicePolicyRuleEvaluation{
+				// The last evaluation predates the deadline, so the rule is
+				// still reported in grace but is already past it.
+				rule("luks", inGrace, complianceNow.Add(-time.Hour)),
+			}},
+			{PolicyId: "policy-updates", PolicyName: "Updates", Status: inGrace, Rules: []*pmv1.DevicePolicyRuleEvaluation{
+				rule("patches", inGrace, complianceNow.Add(time.Hour)),
+			}},
+		}},
+	}
+}
+
+func TestBuildComplianceReportAggregatesPerRuleAndGroup(t *testing.T) {
+	statuses := complianceFixture()
+	kiosks := complianceGroup{id: "group-kiosks", name: "Kiosks"}
+	devices := []complianceDevice{
+		{id: "device-1", hostname: "kiosk-1", groups: []complianceGroup{kiosks}, status: statuses["device-1"]},
+		{id: "device-2", hostname: "laptop-2", status: statuses["device-2"]},
+	}
+
+	report := buildComplianceReport(devices, "", "", complianceNow)
+	assert.Equal(t, 2, report.Devices)
+	require.Len(t, report.Rules, 2)
+	assert.Equal(t, "luks", report.Rules[0].ActionID)
+	assert.Equal(t, complianceCounts{Compliant: 1, InGracePeriod: 1, PastGrace: 1}, report.Rules[0].complianceCounts)
+	assert.Equal(t, complianceCounts{InGracePeriod: 1}, report.Rules[1].complianceCounts)
+	require.Len(t, report.Groups, 2)
+	assert.Equal(t, ungroupedName, report.Groups[0].GroupName)
+	assert.Equal(t, complianceCounts{NonCompliant: 1, PastGrace: 1}, report.Groups[0].complianceCounts)
+	assert.Equal(t, complianceCounts{Compliant: 1}, report.Groups[1].complianceCounts)
+	require.Len(t, report.Results, 3)
+	assert.True(t, report.Results[1].PastGrace)
+
+	report = buildComplianceReport(devices, "policy-updates", "", complianceNow)
+	assert.Equal(t, 1, report.Devices, "devices without the policy are left out")
+	require.Len(t, report.Groups, 1)
+	assert.Equal(t, complianceCounts{InGracePeriod: 1}, report.Groups[0].complianceCounts)
+}
+
+func TestComplianceReportCommandFormats(t *testing.T) {
+	statuses := complianceFixture()
+	mux := http.NewServeMux()
+	mux.Handle(powermanagev1connect.ControlServiceListDevicesProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceListDevicesProcedure,
+			func(_ context.Context, request *connect.Request[pmv1.ListDevicesRequest]) (*connect.Response[pmv1.ListDevicesResponse], error) {
+				if request.Msg.PageToken == "" {
+					return connect.NewResponse(&pmv1.ListDevicesResponse{
+						Devices: []*pmv1.Device{{Id: "device-1", Hostname: "kiosk-1"}}, NextPageToken: "page-2",
+					}), nil
+				}
+				return connect.NewResponse(&pmv1.ListDevicesResponse{Devices: []*pmv1.Device{{Id: "device-2", Hostname: "laptop-2"}}}), nil
+			}))
+	mux.Handle(powermanagev1connect.ControlServiceListDeviceGroupsForDeviceProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceListDeviceGroupsForDeviceProcedure,
+			func(_ context.Context, request *connect.Request[pmv1.ListDeviceGroupsForDeviceRequest]) (*connect.Response[pmv1.ListDeviceGroupsForDeviceResponse], error) {
+				if request.Msg.DeviceId != "device-1" {
+					return connect.NewResponse(&pmv1.ListDeviceGroupsForDeviceResponse{}), nil
+				}
+				return connect.NewResponse(&pmv1.ListDeviceGroupsForDeviceResponse{Groups: []*pmv1.DeviceGroup{{Id: "group-kiosks", Name: "Kiosks"}}}), nil
+			}))
+	mux.Handle(powermanagev1connect.ControlServiceGetDeviceCompliancePolicyStatusProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceGetDeviceCompliancePolicyStatusProcedure,
+			func(_ context.Context, request *connect.Request[pmv1.GetDeviceCompliancePolicyStatusRequest]) (*connect.Response[pmv1.GetDeviceCompliancePolicyStatusResponse], error) {
+				return connect.NewResponse(statuses[request.Msg.DeviceId]), nil
+			}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	directory := filepath.Join(t.TempDir(), "powermanage")
+	stdout := &bytes.Buffer{}
+	a := &app{
+		stdin: strings.NewReader(""), stdout: stdout, stderr: &bytes.Buffer{},
+		httpClient: server.Client(), now: func() time.Time { return complianceNow },
+		configPath: filepath.Join(directory, "config.json"), sessionPath: filepath.Join(directory, "session.json"),
+	}
+	require.NoError(t, writeSession(a.sessionPath, sessionFile{
+		ServerURL: server.URL, AccessToken: "access", RefreshToken: "refresh", ExpiresAt: complianceNow.Add(time.Hour),
+	}))
+	run := func(args ...string) string {
+		t.Helper()
+		stdout.Reset()
+		command := a.complianceCommand()
+		command.SetArgs(append([]string{"report"}, args...))
+		require.NoError(t, command.ExecuteContext(t.Context()))
+		return stdout.String()
+	}
+
+	var report complianceReport
+	require.NoError(t, json.Unmarshal([]byte(run()), &report))
+	assert.Equal(t, 2, report.Devices)
+	assert.Len(t, report.Results, 3)
+
+	records, err := csv.NewReader(strings.NewReader(run("--format", "csv"))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, "past_grace", records[0][12])
+	assert.Equal(t, []string{"device-1", "kiosk-1", "Kiosks", "policy-disk", "Disk", "luks", "rule luks", "COMPLIANT", "24", "", "", "", "false"}, records[1])
+
+	html := run("--format", "html", "--policy", "policy-disk")
+	assert.Contains(t, html, `<tr class="past-grace"><td>laptop-2 (device-2)</td>`)
+	assert.NotContains(t, html, "Updates")
+
+	command := a.complianceCommand()
+	command.SetArgs([]string{"report", "--format", "pdf"})
+	assert.ErrorContains(t, command.ExecuteContext(t.Context()), "json, csv or html")
+
+	a.output = "jsonpath={.devices}"
+	assert.Equal(t, "2\n", run())
+	command = a.complianceCommand()
+	command.SetArgs([]string{"report", "--format", "csv"})
+	assert.ErrorContains(t, command.ExecuteContext(t.Context()), "cannot be combined with --format csv")
+	a.output = "table"
+	command = a.complianceCommand()
+	command.SetArgs([]string{"report"})
+	assert.ErrorContains(t, command.ExecuteContext(t.Context()), "no table output")
+}
+
+// Names a device or policy owner controls must not run as spreadsheet
+// formulas when an auditor opens the CSV.
+func TestComplianceCSVEscapesFormulas(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeComplianceCSV(&out, complianceReport{Results: []complianceResultItem{{
+		DeviceID: "device-1", Hostname: `=HYPERLINK("http://evil.example","x")`, Groups: []string{"@ops", "kiosks"},
+		PolicyID: "policy-1", PolicyName: "+1", ActionID: "action-1", ActionName: "-cmd", Status: "COMPLIANT",
+	}}}))
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{
+		"device-1", `'=HYPERLINK("http://evil.example","x")`, "'@ops;kiosks", "policy-1", "'+1", "action-1", "'-cmd",
+		"COMPLIANT", "0", "", "", "", "false",
+	}, records[1])
+	assert.Equal(t, "'\t1", csvSafeCell("\t1"))
+	assert.Equal(t, "a=b", csvSafeCell("a=b"))
+}
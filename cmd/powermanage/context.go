@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// contextEnv selects the active context when --context is not given.
+	contextEnv = "POWERMANAGE_CONTEXT"
+	// defaultContextName is the context 'config set-server' creates when no
+	// context exists yet.
+	defaultContextName = "default"
+	// destructiveAnnotation marks a command that deletes, revokes or disables
+	// something, so the active context is shown before it runs.
+	destructiveAnnotation = "powermanage/destructive"
+	// localAnnotation marks a command group that only edits the local
+	// configuration, so it runs even when the selected context does not
+	// resolve.
+	localAnnotation = "powermanage/local"
+)
+
+var errNoActiveContext = errors.New("no active context (run 'powermanage context add <name> --server <url>')")
+
+func (a *app) contextCommand() *cobra.Command {
+	command := local(&cobra.Command{Use: "context", Short: "Manage named server contexts"})
+	var server string
+	add := &cobra.Command{
+		Use: "add <name>", Short: "Add a context and make it current", Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := validateContextName(args[0]); err != nil {
+				return err
+			}
+			serverURL, err := validateServerURL(server)
+			if err != nil {
+				return err
+			}
+			config, err := readConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+			if _, exists := config.Contexts[args[0]]; exists {
+				return fmt.Errorf("context %q already exists", args[0])
+			}
+			if config.Contexts == nil {
+				config.Contexts = map[string]contextConfig{}
+			}
+			config.Contexts[args[0]] = contextConfig{ServerURL: serverURL}
+			config.CurrentContext = args[0]
+			return writePrivateJSON(a.configPath, config)
+		},
+	}
+	add.Flags().StringVar(&server, "server", "", "control-server URL")
+	_ = add.MarkFlagRequired("server")
+	command.AddCommand(add,
+		&cobra.Command{
+			Use: "use <name>", Short: "Switch the current context", Args: cobra.ExactArgs(1),
+			RunE: func(_ *cobra.Command, args []string) error {
+				config, err := readConfig(a.configPath)
+				if err != nil {
+					return err
+				}
+				if _, exists := config.Contexts[args[0]]; !exists {
+					return fmt.Errorf("context %q is not configured", args[0])
+				}
+				config.CurrentContext = args[0]
+				return writePrivateJSON(a.configPath, config)
+			},
+		},
+		&cobra.Command{
+			Use: "list", Short: "List contexts", Args: cobra.NoArgs,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				config, err := readConfig(a.configPath)
+				if err != nil {
+					return err
+				}
+				active, _ := a.activeContextName(config)
+				return a.writeDocument(contextListDocument(config, active), func(w io.Writer) error {
+					return writeContextList(w, config, active)
+				})
+			},
+		},
+		a.contextDeleteCommand(),
+	)
+	return command
+}
+
+func (a *app) contextDeleteCommand() *cobra.Command {
+	var noRevoke bool
+	command := destructive(&cobra.Command{
+		Use: "delete <name>", Short: "Revoke a context's session and delete the context", Args: cobra.ExactArgs(1),
+		Long: "Delete a context. Its refresh token is revoked at the control server first, then the local\n" +
+			"session and the context are removed. If the server cannot be reached nothing is deleted;\n" +
+			"pass --no-revoke to remove them locally anyway, leaving the token valid until it expires.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := readConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+			if _, exists := config.Contexts[args[0]]; !exists {
+				return fmt.Errorf("context %q is not configured", args[0])
+			}
+			sessionPath := a.contextSessionPath(args[0])
+			if !noRevoke {
+				if err := a.revokeSession(cmd.Context(), sessionPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("revoke session of context %q (nothing deleted): %w", args[0], err)
+				}
+			}
+			if err := os.Remove(sessionPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("remove local session: %w", err)
+			}
+			delete(config.Contexts, args[0])
+			if config.CurrentContext == args[0] {
+				config.CurrentContext = ""
+			}
+			return writePrivateJSON(a.configPath, config)
+		},
+	})
+	command.Flags().BoolVar(&noRevoke, "no-revoke", false, "delete locally without revoking the session at the server")
+	return command
+}
+
+// destructive marks command so the root pre-run shows the active context
+// before it runs.
+func destructive(command *cobra.Command) *cobra.Command {
+	if command.Annotations == nil {
+		command.Annotations = map[string]string{}
+	}
+	command.Annotations[destructiveAnnotation] = "true"
+	return command
+}
+
+// local marks command and its subcommands as needing no resolved context.
+func local(command *cobra.Command) *cobra.Command {
+	if command.Annotations == nil {
+		command.Annotations = map[string]string{}
+	}
+	command.Annotations[localAnnotation] = "true"
+	return command
+}
+
+// isLocal reports whether command or a parent is marked local.
+func isLocal(command *cobra.Command) bool {
+	for ; command != nil; command = command.Parent() {
+		if command.Annotations[localAnnotation] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// activeContextName resolves the context for this invocation: --context, then
+// POWERMANAGE_CONTEXT, then the configured current context.
+func (a *app) activeContextName(config configFile) (string, error) {
+	name := a.contextName
+	if name == "" {
+		name = config.CurrentContext
+	}
+	if name == "" {
+		return "", errNoActiveContext
+	}
+	if err := validateContextName(name); err != nil {
+		return "", err
+	}
+	if _, exists := config.Contexts[name]; !exists {
+		return "", fmt.Errorf("context %q is not configured", name)
+	}
+	return name, nil
+}
+
+func (a *app) activeContext() (string, contextConfig, error) {
+	config, err := readConfig(a.configPath)
+	if err != nil {
+		return "", contextConfig{}, err
+	}
+	name, err := a.activeContextName(config)
+	if err != nil {
+		return "", contextConfig{}, err
+	}
+	return name, config.Contexts[name], nil
+}
+
+func (a *app) contextSessionPath(name string) string {
+	return filepath.Join(a.sessionDir, name+".json")
+}
+
+// selectContext points the session path at the active context before a
+// command runs, and shows that context on stderr for destructive commands. A
+// command that needs no context (context add, config set-server) still runs
+// when none resolves; one that needs a session then fails with
+// errNoActiveContext. A --context or POWERMANAGE_CONTEXT naming a context that
+// does not exist fails every command except the local context and config
+// ones, which are how it gets fixed.
+func (a *app) selectContext(command *cobra.Command) error {
+	if a.contextName == "" {
+		a.contextName = os.Getenv(contextEnv)
+	}
+	name, selected, err := a.activeContext()
+	if err != nil {
+		if a.contextName != "" && !isLocal(command) {
+			return err
+		}
+		return nil
+	}
+	a.sessionPath = a.contextSessionPath(name)
+	if command.Annotations[destructiveAnnotation] != "" {
+		if _, err := fmt.Fprintf(a.stderr, "Context: %s (%s)\n", name, selected.ServerURL); err != nil {
+			return fmt.Errorf("show active context: %w", err)
+		}
+	}
+	return nil
+}
+
+// contextList is the document 'context list -o' renders.
+type contextList struct {
+	Contexts []contextListItem `json:"contexts"`
+}
+
+type contextListItem struct {
+	Name      string `json:"name"`
+	ServerURL string `json:"server_url"`
+	Active    bool   `json:"active"`
+}
+
+func contextListDocument(config configFile, active string) contextList {
+	list := contextList{Contexts: []contextListItem{}}
+	for _, name := range slices.Sorted(maps.Keys(config.Contexts)) {
+		list.Contexts = append(list.Contexts, contextListItem{Name: name, ServerURL: config.Contexts[name].ServerURL, Active: name == active})
+	}
+	return list
+}
+
+func writeContextList(w io.Writer, config configFile, active string) error {
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var out strings.Builder
+	for _, name := range names {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Fprintf(&out, "%s %s\t%s\n", marker, name, config.Contexts[name].ServerURL)
+	}
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("write contexts: %w", err)
+	}
+	return nil
+}
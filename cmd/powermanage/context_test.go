@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1/powermanagev1connect"
+)
+
+func newContextTestApp(t *testing.T) *app {
+	t.Helper()
+	directory := filepath.Join(t.TempDir(), "powermanage")
+	return &app{
+		stdin: strings.NewReader(""), stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, now: time.Now,
+		configPath: filepath.Join(directory, "config.json"), sessionDir: filepath.Join(directory, "sessions"),
+	}
+}
+
+func runContextCommand(t *testing.T, a *app, args ...string) error {
+	t.Helper()
+	command := a.contextCommand()
+	command.SetArgs(args)
+	return command.ExecuteContext(t.Context())
+}
+
+func TestContextAddUseListDelete(t *testing.T) {
+	a := newContextTestApp(t)
+	require.NoError(t, runContextCommand(t, a, "add", "staging", "--server", "https://staging.example.com"))
+	require.NoError(t, runContextCommand(t, a, "add", "prod", "--server", "https://prod.example.com"))
+	assert.ErrorContains(t, runContextCommand(t, a, "add", "prod", "--server", "https://other.example.com"), "already exists")
+	assert.Error(t, runContextCommand(t, a, "add", "../prod", "--server", "https://prod.example.com"))
+	assert.Error(t, runContextCommand(t, a, "add", "lab", "--server", "http://lab.example.com"))
+
+	config, err := readConfig(a.configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", config.CurrentContext)
+
+	require.NoError(t, runContextCommand(t, a, "use", "staging"))
+	assert.ErrorContains(t, runContextCommand(t, a, "use", "missing"), "not configured")
+	require.NoError(t, runContextCommand(t, a, "list"))
+	assert.Equal(t, "  prod\thttps://prod.example.com\n* staging\thttps://staging.example.com\n", a.stdout.(*bytes.Buffer).String())
+
+	require.NoError(t, runContextCommand(t, a, "delete", "staging"))
+	config, err = readConfig(a.configPath)
+	require.NoError(t, err)
+	assert.Empty(t, config.CurrentContext)
+	assert.NotContains(t, config.Contexts, "staging")
+}
+
+// Deleting a context revokes its refresh token first. When control rejects
+// the revocation nothing is deleted, unless --no-revoke says to go ahead.
+func TestContextDeleteRevokesSession(t *testing.T) {
+	var revoked []string
+	fail := false
+	mux := http.NewServeMux()
+	mux.Handle(powermanagev1connect.ControlServiceLogoutProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceLogoutProcedure,
+			func(_ context.Context, request *connect.Request[pmv1.LogoutRequest]) (*connect.Response[pmv1.LogoutResponse], error) {
+				if fail {
+					return nil, connect.NewError(connect.CodeUnavailable, errors.New("down"))
+				}
+				revoked = append(revoked, request.Msg.RefreshToken)
+				return connect.NewResponse(&pmv1.LogoutResponse{}), nil
+			}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	a := newContextTestApp(t)
+	a.httpClient = server.Client()
+	for _, name := range []string{"staging", "prod"} {
+		require.NoError(t, runContextCommand(t, a, "add", name, "--server", server.URL))
+		require.NoError(t, writeSession(a.contextSessionPath(name), sessionFile{
+			ServerURL: server.URL, AccessToken: "access", RefreshToken: name + "-refresh", ExpiresAt: time.Now().Add(time.Hour),
+		}))
+	}
+
+	require.NoError(t, runContextCommand(t, a, "delete", "staging"))
+	assert.Equal(t, []string{"staging-refresh"}, revoked)
+	assert.NoFileExists(t, a.contextSessionPath("staging"))
+
+	fail = true
+	assert.ErrorContains(t, runContextCommand(t, a, "delete", "prod"), "nothing deleted")
+	assert.FileExists(t, a.contextSessionPath("prod"))
+	config, err := readConfig(a.configPath)
+	require.NoError(t, err)
+	assert.Contains(t, config.Contexts, "prod")
+
+	require.NoError(t, runContextCommand(t, a, "delete", "prod", "--no-revoke"))
+	assert.Equal(t, []string{"staging-refresh"}, revoked)
+	assert.NoFileExists(t, a.contextSessionPath("prod"))
+	config, err = readConfig(a.configPath)
+	require.NoError(t, err)
+	assert.Empty(t, config.Contexts)
+}
+
+// A config written before contexts reads as its server in the default
+// context, and the next write stores it that way.
+func TestReadConfigMigratesSingleServerConfig(t *testing.T) {
+	a := newContextTestApp(t)
+	require.NoError(t, writePrivateJSON(a.configPath, map[string]string{"server_url": "https://control.example.com"}))
+
+	config, err := readConfig(a.configPath)
+	require.NoError(t, err)
+	want := configFile{
+		CurrentContext: defaultContextName,
+		Contexts:       map[string]contextConfig{defaultContextName: {ServerURL: "https://control.example.com"}},
+	}
+	assert.Equal(t, want, config)
+
+	require.NoError(t, runContextCommand(t, a, "add", "staging", "--server", "https://staging.example.com"))
+	raw, err := os.ReadFile(a.configPath)
+	require.NoError(t, err)
+	var stored map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(raw, &stored))
+	assert.NotContains(t, stored, "server_url")
+	config, err = readConfig(a.configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "https://control.example.com", config.Contexts[defaultContextName].ServerURL)
+
+	require.NoError(t, writePrivateJSON(a.configPath, map[string]any{
+		"server_url": "https://control.example.com", "current_context": "staging",
+	}))
+	_, err = readConfig(a.configPath)
+	assert.ErrorContains(t, err, "both")
+
+	require.NoError(t, writePrivateJSON(a.configPath, map[string]string{"server_url": "http://control.example.com"}))
+	_, err = readConfig(a.configPath)
+	assert.Error(t, err)
+}
+
+func TestSetServerCreatesDefaultContext(t *testing.T) {
+	a := newContextTestApp(t)
+	command := a.configCommand()
+	command.SetArgs([]string{"set-server", "https://control.example.com"})
+	require.NoError(t, command.ExecuteContext(t.Context()))
+
+	config, err := readConfig(a.configPath)
+	require.NoError(t, err)
+	assert.Equal(t, configFile{
+		CurrentContext: defaultContextName,
+		Contexts:       map[string]contextConfig{defaultContextName: {ServerURL: "https://control.example.com"}},
+	}, config)
+}
+
+func TestSelectContextChoosesSessionAndAnnouncesDestructiveCommands(t *testing.T) {
+	a := newContextTestApp(t)
+	require.NoError(t, runContextCommand(t, a, "add", "staging", "--server", "https://staging.example.com"))
+	require.NoError(t, runContextCommand(t, a, "add", "prod", "--server", "https://prod.example.com"))
+
+	t.Setenv(contextEnv, "staging")
+	require.NoError(t, a.selectContext(&cobra.Command{Use: "list"}))
+	assert.Equal(t, a.contextSessionPath("staging"), a.sessionPath)
+	assert.Empty(t, a.stderr.(*bytes.Buffer).String())
+
+	// --context wins over the environment.
+	a.contextName = "prod"
+	require.NoError(t, a.selectContext(destructive(&cobra.Command{Use: "delete <id>"})))
+	assert.Equal(t, a.contextSessionPath("prod"), a.sessionPath)
+	assert.Equal(t, "Context: prod (https://prod.example.com)\n", a.stderr.(*bytes.Buffer).String())
+
+	a.contextName = "missing"
+	assert.ErrorContains(t, a.selectContext(&cobra.Command{Use: "list"}), "not configured")
+}
+
+func TestCommandsWithoutContextFailClearly(t *testing.T) {
+	a := newContextTestApp(t)
+	require.NoError(t, a.selectContext(&cobra.Command{Use: "whoami"}))
+	_, err := a.currentSession(t.Context(), false)
+	assert.ErrorIs(t, err, errNoActiveContext)
+	_, _, err = a.publicClient()
+	assert.ErrorIs(t, err, errNoActiveContext)
+}
+
+func TestUnknownSelectedContextOnlyBlocksRemoteCommands(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(contextEnv, "staging")
+	run := func(args ...string) error {
+		root, err := newRootCommand()
+		require.NoError(t, err)
+		root.SetArgs(args)
+		return root.ExecuteContext(t.Context())
+	}
+
+	require.NoError(t, run("context", "add", "prod", "--server", "https://prod.example.com"))
+	require.NoError(t, run("context", "use", "prod"))
+	require.NoError(t, run("config", "set-server", "--context", "prod", "https://control.example.com"))
+	assert.ErrorContains(t, run("whoami"), `context "staging" is not configured`)
+	assert.ErrorContains(t, run("device", "list", "--context", "lab"), `context "lab" is not configured`)
+
+	require.NoError(t, run("context", "add", "staging", "--server", "https://staging.example.com"))
+	_, configPath, _, err := configPaths()
+	require.NoError(t, err)
+	config, err := readConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "https://control.example.com", config.Contexts["prod"].ServerURL)
+	assert.Equal(t, "staging", config.CurrentContext)
+}
@@ -42,7 +42,11 @@ type app struct {
 	openBrowser func(string) error
 	now         func() time.Time
 	configPath  string
+	sessionDir  string
 	sessionPath string
+	contextName string
+	output      string
+	noHeaders   bool
 }
 
 func main() {
@@ -58,41 +62,67 @@ func main() {
 }
 
 func newRootCommand() (*cobra.Command, error) {
-	_, configPath, sessionPath, err := configPaths()
+	_, configPath, sessionDir, err := configPaths()
 	if err != nil {
 		return nil, err
 	}
 	a := &app{
 		stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr,
 		httpClient: &http.Client{Timeout: requestTimeout}, openBrowser: openBrowser,
-		now: time.Now, configPath: configPath, sessionPath: sessionPath,
+		now: time.Now, configPath: configPath, sessionDir: sessionDir,
 	}
 	root := &cobra.Command{
 		Use: "powermanage", Short: "Operate a Power Manage control server",
 		SilenceUsage: true, SilenceErrors: true,
 		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := parseOutputFormat(a.output); err != nil {
+				return err
+			}
+			return a.selectContext(cmd)
+		},
 	}
+	root.PersistentFlags().StringVarP(&a.output, "output", "o", "", "output format: table, wide, json, yaml, jsonpath=<template> or go-template=<template> (default: json, or the text of can-i and context list)")
+	root.PersistentFlags().BoolVar(&a.noHeaders, "no-headers", false, "omit the header row from table and wide output")
+	root.PersistentFlags().StringVar(&a.contextName, "context", "", "context to use (default: $"+contextEnv+", then the current context)")
 	root.SetIn(a.stdin)
 	root.SetOut(a.stdout)
 	root.SetErr(a.stderr)
 	root.AddCommand(
-		a.configCommand(), a.bootstrapCommand(), a.loginCommand(), a.authCommand(),
-		a.whoamiCommand(), a.logoutCommand(), a.actionCommand(), a.assignmentCommand(),
-		a.enrollmentTokenCommand(),
+		a.configCommand(), a.contextCommand(), a.bootstrapCommand(), a.loginCommand(), a.authCommand(),
+		a.whoamiCommand(), a.logoutCommand(), a.deviceCommand(), a.actionCommand(), a.assignmentCommand(),
+		a.enrollmentTokenCommand(), a.roleCommand(), a.userGroupCommand(), a.canICommand(),
+		a.complianceCommand(), a.maintenanceCommand(),
 	)
 	return root, nil
 }
 
 func (a *app) configCommand() *cobra.Command {
-	command := &cobra.Command{Use: "config", Short: "Configure the local client"}
+	command := local(&cobra.Command{Use: "config", Short: "Configure the local client"})
 	command.AddCommand(&cobra.Command{
-		Use: "set-server <url>", Args: cobra.ExactArgs(1),
+		Use: "set-server <url>", Short: "Set the server of the active context", Args: cobra.ExactArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
 			serverURL, err := validateServerURL(args[0])
 			if err != nil {
 				return err
 			}
-			return writePrivateJSON(a.configPath, configFile{ServerURL: serverURL})
+			config, err := readConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+			name, err := a.activeContextName(config)
+			if errors.Is(err, errNoActiveContext) {
+				name, err = defaultContextName, nil
+			}
+			if err != nil {
+				return err
+			}
+			if config.Contexts == nil {
+				config.Contexts = map[string]contextConfig{}
+			}
+			config.Contexts[name] = contextConfig{ServerURL: serverURL}
+			config.CurrentContext = name
+			return writePrivateJSON(a.configPath, config)
 		},
 	})
 	return command
@@ -131,7 +161,7 @@ func (a *app) bootstrapCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return writeProtoJSON(a.stdout, response.Msg)
+			return a.writeOutput(response.Msg)
 		},
 	}
 	oidc.Flags().StringVar(&file, "file", "", "ProtoJSON request file, or - for stdin")
@@ -186,7 +216,7 @@ func (a *app) whoamiCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return writeProtoJSON(a.stdout, response.Msg)
+			return a.writeOutput(response.Msg)
 		},
 	}
 }
@@ -195,24 +225,48 @@ func (a *app) logoutCommand() *cobra.Command {
 	return &cobra.Command{
 		Use: "logout", Short: "Revoke and remove the local session",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			session, err := readSession(a.sessionPath)
-			if err != nil {
-				return err
-			}
-			client := a.client(session.ServerURL)
-			ctx, cancel := context.WithTimeout(cmd.Context(), requestTimeout)
-			defer cancel()
-			if _, err := client.Logout(ctx, connect.NewRequest(&pmv1.LogoutRequest{RefreshToken: session.RefreshToken})); err != nil {
-				return err
-			}
-			if err := os.Remove(a.sessionPath); err != nil && !errors.Is(err, os.ErrNotExist) {
-				return fmt.Errorf("remove local session: %w", err)
+			if a.sessionPath == "" {
+				return errNoActiveContext
 			}
-			return nil
+			return a.revokeSession(cmd.Context(), a.sessionPath)
 		},
 	}
 }
 
+// revokeSession revokes the refresh token of the session stored at path, then
+// removes the local copy. The local copy stays when the server call fails, so
+// the revocation can be retried.
+func (a *app) revokeSession(ctx context.Context, path string) error {
+	session, err := readSession(path)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	if _, err := a.client(session.ServerURL).Logout(ctx, connect.NewRequest(&pmv1.LogoutRequest{RefreshToken: session.RefreshToken})); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove local session: %w", err)
+	}
+	return nil
+}
+
+func (a *app) deviceCommand() *cobra.Command {
+	command := &cobra.Command{Use: "device", Short: "Inspect enrolled devices"}
+	command.AddCommand(
+		idRPC(a, "get <id>", func(id string) *pmv1.GetDeviceRequest { return &pmv1.GetDeviceRequest{Id: id} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetDeviceRequest]) (*connect.Response[pmv1.GetDeviceResponse], error) {
+				return c.GetDevice(ctx, r)
+			}),
+		emptyRPC(a, "list", &pmv1.ListDevicesRequest{},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListDevicesRequest]) (*connect.Response[pmv1.ListDevicesResponse], error) {
+				return c.ListDevices(ctx, r)
+			}),
+	)
+	return command
+}
+
 func (a *app) actionCommand() *cobra.Command {
 	command := &cobra.Command{Use: "action", Short: "Manage actions"}
 	command.AddCommand(
@@ -228,10 +282,10 @@ func (a *app) actionCommand() *cobra.Command {
 			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListActionsRequest]) (*connect.Response[pmv1.ListActionsResponse], error) {
 				return c.ListActions(ctx, r)
 			}),
-		idRPC(a, "delete <id>", func(id string) *pmv1.DeleteActionRequest { return &pmv1.DeleteActionRequest{Id: id} },
+		destructive(idRPC(a, "delete <id>", func(id string) *pmv1.DeleteActionRequest { return &pmv1.DeleteActionRequest{Id: id} },
 			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.DeleteActionRequest]) (*connect.Response[pmv1.DeleteActionResponse], error) {
 				return c.DeleteAction(ctx, r)
-			}),
+			})),
 	)
 	return command
 }
@@ -247,10 +301,10 @@ func (a *app) assignmentCommand() *cobra.Command {
 			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListAssignmentsRequest]) (*connect.Response[pmv1.ListAssignmentsResponse], error) {
 				return c.ListAssignments(ctx, r)
 			}),
-		idRPC(a, "delete <id>", func(id string) *pmv1.DeleteAssignmentRequest { return &pmv1.DeleteAssignmentRequest{Id: id} },
+		destructive(idRPC(a, "delete <id>", func(id string) *pmv1.DeleteAssignmentRequest { return &pmv1.DeleteAssignmentRequest{Id: id} },
 			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.DeleteAssignmentRequest]) (*connect.Response[pmv1.DeleteAssignmentResponse], error) {
 				return c.DeleteAssignment(ctx, r)
-			}),
+			})),
 	)
 	return command
 }
@@ -271,25 +325,21 @@ func (a *app) enrollmentTokenCommand() *cobra.Command {
 				return c.ListTokens(ctx, r)
 			}),
 		setTokenDisabledRPC(a, "enable <id>", false),
-		setTokenDisabledRPC(a, "disable <id>", true),
-		idRPC(a, "delete <id>", func(id string) *pmv1.DeleteTokenRequest { return &pmv1.DeleteTokenRequest{Id: id} },
+		destructive(setTokenDisabledRPC(a, "disable <id>", true)),
+		destructive(idRPC(a, "delete <id>", func(id string) *pmv1.DeleteTokenRequest { return &pmv1.DeleteTokenRequest{Id: id} },
 			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.DeleteTokenRequest]) (*connect.Response[pmv1.DeleteTokenResponse], error) {
 				return c.DeleteToken(ctx, r)
-			}),
+			})),
 	)
 	return command
 }
 
 func (a *app) publicClient() (powermanagev1connect.ControlServiceClient, string, error) {
-	var config configFile
-	if err := readPrivateJSON(a.configPath, &config); err != nil {
-		return nil, "", fmt.Errorf("read local config (run 'powermanage config set-server <url>'): %w", err)
-	}
-	serverURL, err := validateServerURL(config.ServerURL)
+	_, selected, err := a.activeContext()
 	if err != nil {
-		return nil, "", fmt.Errorf("invalid stored server URL: %w", err)
+		return nil, "", err
 	}
-	return a.client(serverURL), serverURL, nil
+	return a.client(selected.ServerURL), selected.ServerURL, nil
 }
 
 func (a *app) client(serverURL string) powermanagev1connect.ControlServiceClient {
@@ -369,6 +419,9 @@ func isExpiredTokenError(err error) bool {
 }
 
 func (a *app) currentSession(ctx context.Context, forceRefresh bool) (sessionFile, error) {
+	if a.sessionPath == "" {
+		return sessionFile{}, errNoActiveContext
+	}
 	if !forceRefresh {
 		session, err := readSession(a.sessionPath)
 		if err != nil {
@@ -446,7 +499,7 @@ func fileRPC[I, O any](a *app, use string, newRequest func() *I, call func(conte
 			if err != nil {
 				return err
 			}
-			return writeRPCResponse(a.stdout, response.Msg)
+			return writeRPCResponse(a, response.Msg)
 		},
 	}
 	command.Flags().StringVar(&path, "file", "", "ProtoJSON request file, or - for stdin")
@@ -462,7 +515,7 @@ func idRPC[I, O any](a *app, use string, newRequest func(string) *I, call func(c
 			if err != nil {
 				return err
 			}
-			return writeRPCResponse(a.stdout, response.Msg)
+			return writeRPCResponse(a, response.Msg)
 		},
 	}
 }
@@ -475,17 +528,17 @@ func emptyRPC[I, O any](a *app, use string, request *I, call func(context.Contex
 			if err != nil {
 				return err
 			}
-			return writeRPCResponse(a.stdout, response.Msg)
+			return writeRPCResponse(a, response.Msg)
 		},
 	}
 }
 
-func writeRPCResponse[T any](w io.Writer, response *T) error {
+func writeRPCResponse[T any](a *app, response *T) error {
 	message, ok := any(response).(proto.Message)
 	if !ok {
 		return errors.New("response is not a protobuf message")
 	}
-	return writeProtoJSON(w, message)
+	return a.writeOutput(message)
 }
 
 func setTokenDisabledRPC(a *app, use string, disabled bool) *cobra.Command {
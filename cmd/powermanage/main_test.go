@@ -316,7 +316,7 @@ func TestLoginKeepsTheAuthorizationCodeAndVerifierAtTheIdentityProvider(t *testi
 		httpClient: control.Client(), now: time.Now,
 		configPath: filepath.Join(directory, "config.json"), sessionPath: filepath.Join(directory, "session.json"),
 	}
-	require.NoError(t, writePrivateJSON(a.configPath, configFile{ServerURL: control.URL}))
+	require.NoError(t, writePrivateJSON(a.configPath, configFile{CurrentContext: "default", Contexts: map[string]contextConfig{"default": {ServerURL: control.URL}}}))
 	testCtx := t.Context()
 	a.openBrowser = func(loginURL string) error {
 		parsed, err := url.Parse(loginURL)
@@ -349,7 +349,7 @@ func TestLoginKeepsTheAuthorizationCodeAndVerifierAtTheIdentityProvider(t *testi
 		httpClient: control.Client(), now: time.Now,
 		configPath: filepath.Join(wrongDirectory, "config.json"), sessionPath: filepath.Join(wrongDirectory, "session.json"),
 	}
-	require.NoError(t, writePrivateJSON(wrongStateApp.configPath, configFile{ServerURL: control.URL}))
+	require.NoError(t, writePrivateJSON(wrongStateApp.configPath, configFile{CurrentContext: "default", Contexts: map[string]contextConfig{"default": {ServerURL: control.URL}}}))
 	wrongStateApp.openBrowser = func(loginURL string) error {
 		parsed, err := url.Parse(loginURL)
 		require.NoError(t, err)
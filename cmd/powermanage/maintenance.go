@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/spf13/cobra"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1/powermanagev1connect"
+	"github.com/manchtools/power-manage-sdk/maintenance"
+)
+
+const (
+	previewTimeLayout = "Mon 2006-01-02 15:04 MST"
+	maxPreviewWeeks   = 52
+)
+
+func (a *app) maintenanceCommand() *cobra.Command {
+	command := &cobra.Command{Use: "maintenance", Short: "Inspect maintenance windows"}
+	var deviceGroups, userGroups []string
+	var device, zone string
+	var weeks int
+	preview := &cobra.Command{
+		Use:   "preview",
+		Short: "List the upcoming intervals of an effective maintenance window",
+		Long: "Combine the windows of the given groups the way control does (any empty window allows\n" +
+			"everything) and list when dispatch is allowed over the next weeks. --device adds the\n" +
+			"device's own device groups; user groups reaching it through assignments must be passed\n" +
+			"with --user-group.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if len(deviceGroups) == 0 && len(userGroups) == 0 && device == "" {
+				return errors.New("pass --device, --device-group or --user-group")
+			}
+			if weeks < 1 || weeks > maxPreviewWeeks {
+				return fmt.Errorf("--weeks must be between 1 and %d", maxPreviewWeeks)
+			}
+			location := time.Local
+			if zone != "" {
+				loaded, err := time.LoadLocation(zone)
+				if err != nil {
+					return fmt.Errorf("load time zone: %w", err)
+				}
+				location = loaded
+			}
+			windows, err := a.loadMaintenanceWindows(cmd.Context(), device, deviceGroups, userGroups)
+			if err != nil {
+				return err
+			}
+			window := maintenance.Union(windows...)
+			if err := maintenance.Validate(window); err != nil {
+				return err
+			}
+			return writeMaintenancePreview(a.stdout, window, a.now().In(location), time.Duration(weeks)*7*24*time.Hour, a.noHeaders)
+		},
+	}
+	preview.Flags().StringArrayVar(&deviceGroups, "device-group", nil, "device group ID whose window applies (repeatable)")
+	preview.Flags().StringArrayVar(&userGroups, "user-group", nil, "user group ID whose window applies (repeatable)")
+	preview.Flags().StringVar(&device, "device", "", "device ID whose device groups' windows apply")
+	preview.Flags().IntVar(&weeks, "weeks", 2, "number of weeks to preview")
+	preview.Flags().StringVar(&zone, "timezone", "", "IANA time zone the device runs in, for windows without their own (default: this machine's)")
+	command.AddCommand(preview)
+	return command
+}
+
+func (a *app) loadMaintenanceWindows(ctx context.Context, device string, deviceGroups, userGroups []string) ([]*pmv1.MaintenanceWindow, error) {
+	var windows []*pmv1.MaintenanceWindow
+	if device != "" {
+		response, err := callAuthenticated(ctx, a, &pmv1.ListDeviceGroupsForDeviceRequest{DeviceId: device},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListDeviceGroupsForDeviceRequest]) (*connect.Response[pmv1.ListDeviceGroupsForDeviceResponse], error) {
+				return c.ListDeviceGroupsForDevice(ctx, r)
+			})
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range response.Msg.Groups {
+			windows = append(windows, group.MaintenanceWindow)
+		}
+	}
+	for _, id := range deviceGroups {
+		response, err := callAuthenticated(ctx, a, &pmv1.GetDeviceGroupRequest{Id: id},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetDeviceGroupRequest]) (*connect.Response[pmv1.GetDeviceGroupResponse], error) {
+				return c.GetDeviceGroup(ctx, r)
+			})
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, response.Msg.GetGroup().GetMaintenanceWindow())
+	}
+	for _, id := range userGroups {
+		response, err := callAuthenticated(ctx, a, &pmv1.GetUserGroupRequest{Id: id},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetUserGroupRequest]) (*connect.Response[pmv1.GetUserGroupResponse], error) {
+				return c.GetUserGroup(ctx, r)
+			})
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, response.Msg.GetGroup().GetMaintenanceWindow())
+	}
+	return windows, nil
+}
+
+// writeMaintenancePreview lists the allowed intervals in [from, from+horizon).
+// A device with no groups, or any group without a window, is never gated
+// unless a group blacks out dates.
+func writeMaintenancePreview(w io.Writer, window *pmv1.MaintenanceWindow, from time.Time, horizon time.Duration, noHeaders bool) error {
+	var out bytes.Buffer
+	if maintenance.Unconstrained(window) {
+		out.WriteString("No maintenance window applies: dispatch is always allowed.\n")
+	} else {
+		table := tabwriter.NewWriter(&out, 0, 0, 3, ' ', 0)
+		if !noHeaders {
+			fmt.Fprintln(table, "OPENS\tCLOSES\tDURATION")
+		}
+		for interval := range maintenance.Intervals(window, from, horizon) {
+			fmt.Fprintf(table, "%s\t%s\t%s\n",
+				interval.Start.Format(previewTimeLayout), interval.End.Format(previewTimeLayout), interval.End.Sub(interval.Start))
+		}
+		if err := table.Flush(); err != nil {
+			return fmt.Errorf("format maintenance preview: %w", err)
+		}
+	}
+	if _, err := out.WriteTo(w); err != nil {
+		return fmt.Errorf("write maintenance preview: %w", err)
+	}
+	return nil
+}
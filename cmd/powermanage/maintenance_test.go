@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1/powermanagev1connect"
+)
+
+func TestMaintenancePreviewUnionsGroupWindows(t *testing.T) {
+	windows := map[string]*pmv1.MaintenanceWindow{
+		"group-nightly": {Schedule: []*pmv1.MaintenanceWindowEntry{{Days: []string{"mon"}, Allow: "22:00-02:00"}}},
+		"group-weekend": {Schedule: []*pmv1.MaintenanceWindowEntry{{Days: []string{"sat"}, Allow: "08:00-12:00"}}},
+		"group-open":    {},
+		"group-tokyo":   {TimeZone: "Asia/Tokyo", Schedule: []*pmv1.MaintenanceWindowEntry{{Days: []string{"tue"}, Allow: "09:00-10:00"}}},
+		"group-freeze": {
+			Schedule:      []*pmv1.MaintenanceWindowEntry{{Days: []string{"sun"}, Allow: "08:00-12:00"}},
+			BlackoutDates: []*pmv1.MaintenanceWindowDate{{Date: "2026-05-09", Allow: "10:00-23:00"}},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(powermanagev1connect.ControlServiceGetDeviceGroupProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceGetDeviceGroupProcedure,
+			func(_ context.Context, request *connect.Request[pmv1.GetDeviceGroupRequest]) (*connect.Response[pmv1.GetDeviceGroupResponse], error) {
+				return connect.NewResponse(&pmv1.GetDeviceGroupResponse{Group: &pmv1.DeviceGroup{
+					Id: request.Msg.Id, MaintenanceWindow: windows[request.Msg.Id],
+				}}), nil
+			}))
+	mux.Handle(powermanagev1connect.ControlServiceListDeviceGroupsForDeviceProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceListDeviceGroupsForDeviceProcedure,
+			func(context.Context, *connect.Request[pmv1.ListDeviceGroupsForDeviceRequest]) (*connect.Response[pmv1.ListDeviceGroupsForDeviceResponse], error) {
+				return connect.NewResponse(&pmv1.ListDeviceGroupsForDeviceResponse{Groups: []*pmv1.DeviceGroup{
+					{Id: "group-open", MaintenanceWindow: windows["group-open"]},
+				}}), nil
+			}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// Monday 2026-05-04 12:00 UTC.
+	now := time.Date(2026, time.May, 4, 12, 0, 0, 0, time.UTC)
+	directory := filepath.Join(t.TempDir(), "powermanage")
+	stdout := &bytes.Buffer{}
+	a := &app{
+		stdin: strings.NewReader(""), stdout: stdout, stderr: &bytes.Buffer{},
+		httpClient: server.Client(), now: func() time.Time { return now },
+		configPath: filepath.Join(directory, "config.json"), sessionPath: filepath.Join(directory, "session.json"),
+	}
+	require.NoError(t, writeSession(a.sessionPath, sessionFile{
+		ServerURL: server.URL, AccessToken: "access", RefreshToken: "refresh", ExpiresAt: now.Add(time.Hour),
+	}))
+	run := func(args ...string) (string, error) {
+		stdout.Reset()
+		command := a.maintenanceCommand()
+		command.SetArgs(append([]string{"preview"}, args...))
+		err := command.ExecuteContext(t.Context())
+		return stdout.String(), err
+	}
+
+	output, err := run("--device-group", "group-nightly", "--device-group", "group-weekend", "--weeks", "1", "--timezone", "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"OPENS                      CLOSES                     DURATION\n"+
+		"Mon 2026-05-04 22:00 UTC   Tue 2026-05-05 02:00 UTC   4h0m0s\n"+
+		"Sat 2026-05-09 08:00 UTC   Sat 2026-05-09 12:00 UTC   4h0m0s\n", output)
+
+	// The Tokyo window keeps its own zone, and one group's blackout trims
+	// another group's Saturday.
+	output, err = run("--device-group", "group-weekend", "--device-group", "group-tokyo", "--device-group", "group-freeze", "--weeks", "1", "--timezone", "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"OPENS                      CLOSES                     DURATION\n"+
+		"Tue 2026-05-05 00:00 UTC   Tue 2026-05-05 01:00 UTC   1h0m0s\n"+
+		"Sat 2026-05-09 08:00 UTC   Sat 2026-05-09 10:00 UTC   2h0m0s\n"+
+		"Sun 2026-05-10 08:00 UTC   Sun 2026-05-10 12:00 UTC   4h0m0s\n", output)
+
+	output, err = run("--device-group", "group-nightly", "--device", "device-1")
+	require.NoError(t, err)
+	assert.Contains(t, output, "always allowed", "an empty window in the union removes the gate")
+
+	_, err = run()
+	assert.ErrorContains(t, err, "--device")
+	_, err = run("--device-group", "group-nightly", "--timezone", "Mars/Olympus_Mons")
+	assert.ErrorContains(t, err, "time zone")
+}
@@ -0,0 +1,714 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// outputFormat is a parsed -o value. expression holds the JSONPath or Go
+// template for the two formats that take one.
+type outputFormat struct {
+	kind       string
+	expression string
+}
+
+const (
+	outputJSON       = "json"
+	outputYAML       = "yaml"
+	outputTable      = "table"
+	outputWide       = "wide"
+	outputJSONPath   = "jsonpath"
+	outputGoTemplate = "go-template"
+)
+
+// parseOutputFormat parses an -o value. An empty value is ProtoJSON, the
+// format every command printed before -o existed.
+func parseOutputFormat(raw string) (outputFormat, error) {
+	if raw == "" {
+		return outputFormat{kind: outputJSON}, nil
+	}
+	kind, expression, hasExpression := strings.Cut(raw, "=")
+	switch kind {
+	case outputJSON, outputYAML, outputTable, outputWide:
+		if hasExpression {
+			return outputFormat{}, fmt.Errorf("output format %q takes no expression", kind)
+		}
+		return outputFormat{kind: kind}, nil
+	case outputJSONPath, outputGoTemplate:
+		if expression == "" {
+			return outputFormat{}, fmt.Errorf("output format %s needs an expression: -o %s=...", kind, kind)
+		}
+		return outputFormat{kind: kind, expression: expression}, nil
+	default:
+		return outputFormat{}, fmt.Errorf("unknown output format %q (want table, wide, json, yaml, jsonpath=... or go-template=...)", raw)
+	}
+}
+
+// writeOutput renders a response message in the format chosen with -o.
+func (a *app) writeOutput(message proto.Message) error {
+	format, err := parseOutputFormat(a.output)
+	if err != nil {
+		return err
+	}
+	switch format.kind {
+	case outputJSON:
+		return writeProtoJSON(a.stdout, message)
+	case outputTable, outputWide:
+		return writeTable(a.stdout, message, format.kind == outputWide, a.noHeaders)
+	}
+	value, err := genericValue(message)
+	if err != nil {
+		return err
+	}
+	return a.writeGeneric(format, value)
+}
+
+// writeDocument renders a command's own document, for the commands whose
+// output is not a response message. json is the document's JSON encoding, and
+// yaml, jsonpath and go-template read that same encoding. table and wide print
+// the command's text through table; a command without one rejects them. With
+// no -o, the command prints its table if it has one, otherwise JSON.
+func (a *app) writeDocument(document any, table func(io.Writer) error) error {
+	format, err := parseOutputFormat(a.output)
+	if err != nil {
+		return err
+	}
+	if a.output == "" && table != nil {
+		format.kind = outputTable
+	}
+	switch format.kind {
+	case outputTable, outputWide:
+		if table == nil {
+			return fmt.Errorf("this command has no %s output; use -o json, yaml, jsonpath or go-template", format.kind)
+		}
+		return table(a.stdout)
+	case outputJSON:
+		var out bytes.Buffer
+		encoder := json.NewEncoder(&out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("encode JSON: %w", err)
+		}
+		if _, err := out.WriteTo(a.stdout); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+		return nil
+	}
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("encode JSON: %w", err)
+	}
+	value, err := decodeGeneric(raw)
+	if err != nil {
+		return err
+	}
+	return a.writeGeneric(format, value)
+}
+
+// writeGeneric renders a decoded JSON value as yaml, jsonpath or go-template.
+func (a *app) writeGeneric(format outputFormat, value any) error {
+	var out bytes.Buffer
+	switch format.kind {
+	case outputYAML:
+		writeYAML(&out, value)
+	case outputJSONPath:
+		path, err := parseJSONPath(format.expression)
+		if err != nil {
+			return err
+		}
+		path.execute(&out, value)
+		out.WriteByte('\n')
+	case outputGoTemplate:
+		tmpl, err := template.New("output").Parse(format.expression)
+		if err != nil {
+			return fmt.Errorf("parse go-template: %w", err)
+		}
+		if err := tmpl.Execute(&out, value); err != nil {
+			return fmt.Errorf("execute go-template: %w", err)
+		}
+		out.WriteByte('\n')
+	}
+	if _, err := out.WriteTo(a.stdout); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// genericValue converts message to the decoded form of its ProtoJSON, so YAML,
+// JSONPath and templates see the same field names and value encodings as -o
+// json.
+func genericValue(message proto.Message) (any, error) {
+	raw, err := protojson.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("encode ProtoJSON: %w", err)
+	}
+	return decodeGeneric(raw)
+}
+
+// decodeGeneric decodes JSON keeping numbers as written, so a 64-bit value
+// prints the same in every format.
+func decodeGeneric(raw []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("decode JSON: %w", err)
+	}
+	return value, nil
+}
+
+// column is one table column, read from the proto field of the same name.
+// wide columns appear only with -o wide.
+type column struct {
+	header string
+	field  protoreflect.Name
+	wide   bool
+}
+
+// resourceColumns are the table columns per resource message. Scripts parse
+// these, so keep their order stable and add new columns at the end.
+var resourceColumns = map[protoreflect.FullName][]column{
+	"powermanage.v1.Device": {
+		{header: "ID", field: "id"},
+		{header: "HOSTNAME", field: "hostname"},
+		{header: "STATUS", field: "status"},
+		{header: "COMPLIANCE", field: "compliance_status"},
+		{header: "LAST SEEN", field: "last_seen_at"},
+		{header: "AGENT", field: "agent_version", wide: true},
+		{header: "CERT EXPIRES", field: "cert_expires_at", wide: true},
+		{header: "LABELS", field: "labels", wide: true},
+	},
+	"powermanage.v1.ManagedAction": {
+		{header: "ID", field: "id"},
+		{header: "NAME", field: "name"},
+		{header: "TYPE", field: "type"},
+		{header: "STATE", field: "desired_state"},
+		{header: "TIMEOUT", field: "timeout_seconds", wide: true},
+		{header: "CREATED", field: "created_at", wide: true},
+		{header: "CREATED BY", field: "created_by", wide: true},
+	},
+	"powermanage.v1.Assignment": {
+		{header: "ID", field: "id"},
+		{header: "SOURCE", field: "source_type"},
+		{header: "SOURCE ID", field: "source_id"},
+		{header: "TARGET", field: "target_type"},
+		{header: "TARGET ID", field: "target_id"},
+		{header: "MODE", field: "mode"},
+		{header: "SOURCE NAME", field: "source_name", wide: true},
+		{header: "TARGET NAME", field: "target_name", wide: true},
+		{header: "CREATED", field: "created_at", wide: true},
+	},
+	"powermanage.v1.RegistrationToken": {
+		{header: "ID", field: "id"},
+		{header: "NAME", field: "name"},
+		{header: "USES", field: "current_uses"},
+		{header: "MAX USES", field: "max_uses"},
+		{header: "EXPIRES", field: "expires_at"},
+		{header: "DISABLED", field: "disabled"},
+		{header: "ONE TIME", field: "one_time", wide: true},
+		{header: "OWNER", field: "owner_id", wide: true},
+		{header: "CREATED", field: "created_at", wide: true},
+	},
+	"powermanage.v1.Role": {
+		{header: "ID", field: "id"},
+		{header: "NAME", field: "name"},
+		{header: "SYSTEM", field: "is_system"},
+		{header: "DESCRIPTION", field: "description", wide: true},
+		{header: "PERMISSIONS", field: "permissions", wide: true},
+	},
+	"powermanage.v1.UserGroup": {
+		{header: "ID", field: "id"},
+		{header: "NAME", field: "name"},
+		{header: "MEMBERS", field: "member_count"},
+		{header: "DYNAMIC", field: "is_dynamic"},
+		{header: "DESCRIPTION", field: "description", wide: true},
+		{header: "SCIM", field: "is_scim_managed", wide: true},
+	},
+	"powermanage.v1.PermissionInfo": {
+		{header: "KEY", field: "key"},
+		{header: "TARGET", field: "target_kind"},
+	},
+}
+
+// tableRows finds the resources in a response: the elements of its repeated
+// resource field for a list, or its singular resource field for a get.
+func tableRows(message protoreflect.Message) ([]protoreflect.Message, []column, error) {
+	fields := message.Descriptor().Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		if field.Message() == nil || field.IsMap() {
+			continue
+		}
+		columns, ok := resourceColumns[field.Message().FullName()]
+		if !ok {
+			continue
+		}
+		if !field.IsList() {
+			if !message.Has(field) {
+				return nil, columns, nil
+			}
+			return []protoreflect.Message{message.Get(field).Message()}, columns, nil
+		}
+		list := message.Get(field).List()
+		rows := make([]protoreflect.Message, list.Len())
+		for j := range list.Len() {
+			rows[j] = list.Get(j).Message()
+		}
+		return rows, columns, nil
+	}
+	return nil, nil, fmt.Errorf("%s has no table columns; use -o json, yaml, jsonpath or go-template", message.Descriptor().Name())
+}
+
+func writeTable(w io.Writer, message proto.Message, wide, noHeaders bool) error {
+	rows, columns, err := tableRows(message.ProtoReflect())
+	if err != nil {
+		return err
+	}
+	columns = slices.DeleteFunc(slices.Clone(columns), func(c column) bool { return c.wide && !wide })
+	var out bytes.Buffer
+	table := tabwriter.NewWriter(&out, 0, 0, 3, ' ', 0)
+	if !noHeaders {
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = c.header
+		}
+		fmt.Fprintln(table, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = formatCell(row, c.field)
+		}
+		fmt.Fprintln(table, strings.Join(cells, "\t"))
+	}
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("format table: %w", err)
+	}
+	if _, err := out.WriteTo(w); err != nil {
+		return fmt.Errorf("write table: %w", err)
+	}
+	return nil
+}
+
+// formatCell renders one field for a table. Unset fields print as <none> so
+// every row keeps the same number of whitespace-separated cells.
+func formatCell(row protoreflect.Message, name protoreflect.Name) string {
+	const none = "<none>"
+	field := row.Descriptor().Fields().ByName(name)
+	if field == nil || (!row.Has(field) && (field.Kind() == protoreflect.MessageKind || field.IsList() || field.IsMap() || field.Kind() == protoreflect.StringKind)) {
+		return none
+	}
+	value := row.Get(field)
+	switch {
+	case field.IsMap():
+		var pairs []string
+		value.Map().Range(func(key protoreflect.MapKey, v protoreflect.Value) bool {
+			pairs = append(pairs, key.String()+"="+v.String())
+			return true
+		})
+		slices.Sort(pairs)
+		return strings.Join(pairs, ",")
+	case field.IsList():
+		items := make([]string, value.List().Len())
+		for i := range items {
+			items[i] = formatScalar(field, value.List().Get(i))
+		}
+		return strings.Join(items, ",")
+	default:
+		return formatScalar(field, value)
+	}
+}
+
+func formatScalar(field protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch field.Kind() {
+	case protoreflect.EnumKind:
+		number := value.Enum()
+		enum := field.Enum().Values().ByNumber(number)
+		if enum == nil {
+			return strconv.Itoa(int(number))
+		}
+		return trimEnumPrefix(field.Enum().Name(), enum.Name())
+	case protoreflect.MessageKind:
+		if timestamp, ok := value.Message().Interface().(*timestamppb.Timestamp); ok {
+			return timestamp.AsTime().UTC().Format(time.RFC3339)
+		}
+		return "<object>"
+	default:
+		return value.String()
+	}
+}
+
+// trimEnumPrefix drops the type-name prefix protobuf style puts on enum values,
+// so DEVICE_STATUS_ONLINE in DeviceStatus prints as ONLINE.
+func trimEnumPrefix(enum, value protoreflect.Name) string {
+	var prefix strings.Builder
+	for i, r := range string(enum) {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			prefix.WriteByte('_')
+		}
+		prefix.WriteRune(r)
+	}
+	prefix.WriteByte('_')
+	trimmed, ok := strings.CutPrefix(string(value), strings.ToUpper(prefix.String()))
+	if !ok || trimmed == "" {
+		return string(value)
+	}
+	return trimmed
+}
+
+// writeYAML renders a decoded ProtoJSON value as block-style YAML. Strings
+// that could read as another type are double-quoted with JSON escaping, which
+// YAML accepts.
+func writeYAML(out *bytes.Buffer, value any) {
+	if lines := yamlBlock(value); lines != nil {
+		for _, line := range lines {
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+		return
+	}
+	out.WriteString(yamlScalar(value))
+	out.WriteByte('\n')
+}
+
+// yamlBlock returns the lines of a non-empty mapping or sequence, or nil for a
+// value that is written inline.
+func yamlBlock(value any) []string {
+	var lines []string
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			nested := yamlBlock(v[key])
+			if nested == nil {
+				lines = append(lines, yamlString(key)+": "+yamlScalar(v[key]))
+				continue
+			}
+			lines = append(lines, yamlString(key)+":")
+			for _, line := range nested {
+				lines = append(lines, "  "+line)
+			}
+		}
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+		for _, item := range v {
+			nested := yamlBlock(item)
+			if nested == nil {
+				lines = append(lines, "- "+yamlScalar(item))
+				continue
+			}
+			lines = append(lines, "- "+nested[0])
+			for _, line := range nested[1:] {
+				lines = append(lines, "  "+line)
+			}
+		}
+	default:
+		return nil
+	}
+	return lines
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		return yamlString(v)
+	case map[string]any:
+		return "{}"
+	case []any:
+		return "[]"
+	default:
+		return yamlString(fmt.Sprint(v))
+	}
+}
+
+var (
+	yamlPlainString    = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./+-]*$`)
+	yamlReservedString = map[string]bool{
+		"true": true, "false": true, "null": true, "yes": true, "no": true,
+		"on": true, "off": true, "y": true, "n": true,
+	}
+)
+
+func yamlString(s string) string {
+	if yamlPlainString.MatchString(s) && !yamlReservedString[strings.ToLower(s)] {
+		return s
+	}
+	var quoted bytes.Buffer
+	encoder := json.NewEncoder(&quoted)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s) // encoding a string cannot fail
+	return strings.TrimSuffix(quoted.String(), "\n")
+}
+
+// jsonPathNode is one piece of a parsed JSONPath template: literal text, a
+// path whose values are printed, or a range whose body runs once per element.
+type jsonPathNode struct {
+	kind jsonPathNodeKind
+	text string
+	path []jsonPathStep
+	body jsonPathTemplate
+}
+
+type jsonPathNodeKind int
+
+const (
+	jsonPathText jsonPathNodeKind = iota
+	jsonPathValue
+	jsonPathRange
+)
+
+// jsonPathStep selects a key, an index, or every element (wildcard).
+type jsonPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+type jsonPathTemplate []jsonPathNode
+
+// parseJSONPath accepts the subset of kubectl JSONPath scripts use: {.a.b},
+// {.items[0]}, {.items[*].id}, {range .items[*]}...{end}, and quoted
+// literals such as {"\t"}. Filters and recursive descent are not supported.
+func parseJSONPath(raw string) (jsonPathTemplate, error) {
+	nodes, _, err := parseJSONPathNodes(raw, false)
+	if err != nil {
+		return nil, fmt.Errorf("parse jsonpath: %w", err)
+	}
+	return nodes, nil
+}
+
+// parseJSONPathNodes parses until the input ends or, inside a range, until the
+// matching {end}, returning the input that follows it.
+func parseJSONPathNodes(raw string, inRange bool) (jsonPathTemplate, string, error) {
+	var nodes jsonPathTemplate
+	for raw != "" {
+		open := strings.IndexByte(raw, '{')
+		if open < 0 {
+			nodes = append(nodes, jsonPathNode{kind: jsonPathText, text: raw})
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jsonPathNode{kind: jsonPathText, text: raw[:open]})
+		}
+		end := closingBrace(raw[open:])
+		if end < 0 {
+			return nil, "", errors.New("unterminated {")
+		}
+		expression := strings.TrimSpace(raw[open+1 : open+end])
+		raw = raw[open+end+1:]
+		switch {
+		case expression == "end":
+			if !inRange {
+				return nil, "", errors.New("{end} without {range}")
+			}
+			return nodes, raw, nil
+		case strings.HasPrefix(expression, "range "):
+			path, err := parseJSONPathSteps(strings.TrimSpace(strings.TrimPrefix(expression, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parseJSONPathNodes(raw, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{kind: jsonPathRange, path: path, body: body})
+			raw = rest
+		case strings.HasPrefix(expression, `"`):
+			text, err := strconv.Unquote(expression)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid literal %s", expression)
+			}
+			nodes = append(nodes, jsonPathNode{kind: jsonPathText, text: text})
+		default:
+			path, err := parseJSONPathSteps(expression)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{kind: jsonPathValue, path: path})
+		}
+	}
+	if inRange {
+		return nil, "", errors.New("{range} without {end}")
+	}
+	return nodes, "", nil
+}
+
+// closingBrace returns the offset of the brace closing s[0], skipping braces
+// inside a quoted literal.
+func closingBrace(s string) int {
+	quoted := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseJSONPathSteps(expression string) ([]jsonPathStep, error) {
+	rest := strings.TrimPrefix(expression, "$")
+	if rest == "" || (rest[0] != '.' && rest[0] != '[') {
+		return nil, fmt.Errorf("jsonpath expression %q must start with . or [", expression)
+	}
+	var steps []jsonPathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			if rest == "" {
+				return steps, nil
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("jsonpath expression %q has an empty key", expression)
+			}
+			if key == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+			} else {
+				steps = append(steps, jsonPathStep{key: key})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath expression %q has an unterminated [", expression)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			if selector == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath expression %q: unsupported selector [%s]", expression, selector)
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("jsonpath expression %q is malformed", expression)
+		}
+	}
+	return steps, nil
+}
+
+// resolveJSONPath returns every value the path selects from value. Missing
+// keys select nothing rather than failing, because ProtoJSON omits fields at
+// their zero value.
+func resolveJSONPath(value any, steps []jsonPathStep) []any {
+	current := []any{value}
+	for _, step := range steps {
+		var next []any
+		for _, v := range current {
+			switch typed := v.(type) {
+			case map[string]any:
+				switch {
+				case step.wildcard:
+					keys := make([]string, 0, len(typed))
+					for key := range typed {
+						keys = append(keys, key)
+					}
+					slices.Sort(keys)
+					for _, key := range keys {
+						next = append(next, typed[key])
+					}
+				case !step.isIndex:
+					if item, ok := typed[step.key]; ok {
+						next = append(next, item)
+					}
+				}
+			case []any:
+				switch {
+				case step.wildcard:
+					next = append(next, typed...)
+				case step.isIndex:
+					index := step.index
+					if index < 0 {
+						index += len(typed)
+					}
+					if index >= 0 && index < len(typed) {
+						next = append(next, typed[index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func (t jsonPathTemplate) execute(out *bytes.Buffer, value any) {
+	for _, node := range t {
+		switch node.kind {
+		case jsonPathRange:
+			items := resolveJSONPath(value, node.path)
+			if len(items) == 1 {
+				if list, ok := items[0].([]any); ok {
+					items = list
+				}
+			}
+			for _, item := range items {
+				node.body.execute(out, item)
+			}
+		case jsonPathValue:
+			for i, item := range resolveJSONPath(value, node.path) {
+				if i > 0 {
+					out.WriteByte(' ')
+				}
+				out.WriteString(jsonPathString(item))
+			}
+		default:
+			out.WriteString(node.text)
+		}
+	}
+}
+
+func jsonPathString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+)
+
+func testDevices() *pmv1.ListDevicesResponse {
+	seen := timestamppb.New(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	return &pmv1.ListDevicesResponse{Devices: []*pmv1.Device{
+		{
+			Id: "01KDEVICE1", Hostname: "kiosk-1", Status: pmv1.DeviceStatus_DEVICE_STATUS_ONLINE, LastSeenAt: seen,
+			AgentVersion: "1.4.0", Labels: map[string]string{"site": "hq", "role": "kiosk"},
+		},
+		{Id: "01KDEVICE2", Hostname: "laptop-7"},
+	}, TotalCount: 2}
+}
+
+func renderOutput(t *testing.T, format string, noHeaders bool, message protoreflect.ProtoMessage) (string, error) {
+	t.Helper()
+	stdout := &bytes.Buffer{}
+	a := &app{stdout: stdout, output: format, noHeaders: noHeaders}
+	err := a.writeOutput(message)
+	return stdout.String(), err
+}
+
+func TestResourceColumnsNameRealFields(t *testing.T) {
+	for name, columns := range resourceColumns {
+		descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+		require.NoError(t, err, name)
+		message, ok := descriptor.(protoreflect.MessageDescriptor)
+		require.True(t, ok, name)
+		for _, c := range columns {
+			assert.NotNil(t, message.Fields().ByName(c.field), "%s.%s", name, c.field)
+		}
+	}
+}
+
+func TestTableOutput(t *testing.T) {
+	output, err := renderOutput(t, "table", false, testDevices())
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"ID           HOSTNAME   STATUS        COMPLIANCE   LAST SEEN\n"+
+		"01KDEVICE1   kiosk-1    ONLINE        UNKNOWN      2026-03-01T12:00:00Z\n"+
+		"01KDEVICE2   laptop-7   UNSPECIFIED   UNKNOWN      <none>\n", output)
+
+	output, err = renderOutput(t, "wide", true, testDevices())
+	require.NoError(t, err)
+	assert.Equal(t, ""+
+		"01KDEVICE1   kiosk-1    ONLINE        UNKNOWN   2026-03-01T12:00:00Z   1.4.0    <none>   role=kiosk,site=hq\n"+
+		"01KDEVICE2   laptop-7   UNSPECIFIED   UNKNOWN   <none>                 <none>   <none>   <none>\n", output)
+
+	output, err = renderOutput(t, "table", false, &pmv1.GetTokenResponse{Token: &pmv1.RegistrationToken{Id: "01KTOKEN", Name: "lab", MaxUses: 5}})
+	require.NoError(t, err)
+	assert.Contains(t, output, "01KTOKEN   lab    0      5          <none>    false\n")
+
+	_, err = renderOutput(t, "table", false, &pmv1.DeleteActionResponse{})
+	assert.ErrorContains(t, err, "no table columns")
+}
+
+func TestStructuredOutput(t *testing.T) {
+	output, err := renderOutput(t, "yaml", false, testDevices())
+	require.NoError(t, err)
+	assert.Equal(t, `devices:
+  - agentVersion: "1.4.0"
+    hostname: kiosk-1
+    id: "01KDEVICE1"
+    labels:
+      role: kiosk
+      site: hq
+    lastSeenAt: "2026-03-01T12:00:00Z"
+    status: DEVICE_STATUS_ONLINE
+  - hostname: laptop-7
+    id: "01KDEVICE2"
+totalCount: 2
+`, output)
+
+	output, err = renderOutput(t, `jsonpath={range .devices[*]}{.id}{"\t"}{.hostname}{"\n"}{end}`, false, testDevices())
+	require.NoError(t, err)
+	assert.Equal(t, "01KDEVICE1\tkiosk-1\n01KDEVICE2\tlaptop-7\n\n", output)
+
+	output, err = renderOutput(t, "jsonpath={.devices[*].hostname}", false, testDevices())
+	require.NoError(t, err)
+	assert.Equal(t, "kiosk-1 laptop-7\n", output)
+
+	output, err = renderOutput(t, "jsonpath={.devices[0].labels.site} {.totalCount}", false, testDevices())
+	require.NoError(t, err)
+	assert.Equal(t, "hq 2\n", output)
+
+	output, err = renderOutput(t, `go-template={{range .devices}}{{.hostname}} {{end}}`, false, testDevices())
+	require.NoError(t, err)
+	assert.Equal(t, "kiosk-1 laptop-7 \n", output)
+}
+
+// Commands that print their own document (can-i, context list, compliance
+// report) honour -o like the resource commands, and keep their text output
+// when -o is not given.
+func TestDocumentOutput(t *testing.T) {
+	config := configFile{Contexts: map[string]contextConfig{
+		"prod": {ServerURL: "https://prod.example.com"}, "lab": {ServerURL: "https://lab.example.com"},
+	}}
+	document := contextListDocument(config, "prod")
+	text := func(w io.Writer) error { return writeContextList(w, config, "prod") }
+	render := func(format string, table func(io.Writer) error) (string, error) {
+		stdout := &bytes.Buffer{}
+		err := (&app{stdout: stdout, output: format}).writeDocument(document, table)
+		return stdout.String(), err
+	}
+
+	for _, format := range []string{"", "table", "wide"} {
+		output, err := render(format, text)
+		require.NoError(t, err)
+		assert.Equal(t, "  lab\thttps://lab.example.com\n* prod\thttps://prod.example.com\n", output, format)
+	}
+	for _, format := range []string{"", "json"} {
+		output, err := render(format, nil)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(output, "{\n  \"contexts\": [\n"), output)
+	}
+	output, err := render("json", text)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"server_url": "https://prod.example.com"`)
+
+	output, err = render("yaml", text)
+	require.NoError(t, err)
+	assert.Equal(t, `contexts:
+  - active: false
+    name: lab
+    server_url: "https://lab.example.com"
+  - active: true
+    name: prod
+    server_url: "https://prod.example.com"
+`, output)
+
+	output, err = render(`jsonpath={range .contexts[*]}{.name}{" "}{end}`, text)
+	require.NoError(t, err)
+	assert.Equal(t, "lab prod \n", output)
+
+	output, err = render(`go-template={{range .contexts}}{{if .active}}{{.name}}{{end}}{{end}}`, text)
+	require.NoError(t, err)
+	assert.Equal(t, "prod\n", output)
+
+	_, err = render("table", nil)
+	assert.ErrorContains(t, err, "no table output")
+}
+
+func TestParseOutputFormatRejectsMalformedFormats(t *testing.T) {
+	for _, format := range []string{"xml", "json=x", "jsonpath=", "go-template"} {
+		_, err := parseOutputFormat(format)
+		assert.Error(t, err, format)
+	}
+	for _, expression := range []string{"{.a", "{range .a[*]}{.b}", "{end}", "{a.b}", `{.a[x]}`} {
+		_, err := parseJSONPath(expression)
+		assert.Error(t, err, expression)
+	}
+}
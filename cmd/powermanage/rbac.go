@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/spf13/cobra"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1/powermanagev1connect"
+)
+
+// errPermissionNotGranted makes a "no" from can-i a non-zero exit, so a review
+// script can gate on the answer without parsing the explanation.
+var errPermissionNotGranted = errors.New("permission not granted")
+
+func (a *app) roleCommand() *cobra.Command {
+	command := &cobra.Command{Use: "role", Short: "Manage roles and role grants"}
+	command.AddCommand(
+		fileRPC(a, "create", func() *pmv1.CreateRoleRequest { return &pmv1.CreateRoleRequest{} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.CreateRoleRequest]) (*connect.Response[pmv1.CreateRoleResponse], error) {
+				return c.CreateRole(ctx, r)
+			}),
+		idRPC(a, "get <id>", func(id string) *pmv1.GetRoleRequest { return &pmv1.GetRoleRequest{Id: id} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetRoleRequest]) (*connect.Response[pmv1.GetRoleResponse], error) {
+				return c.GetRole(ctx, r)
+			}),
+		emptyRPC(a, "list", &pmv1.ListRolesRequest{},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListRolesRequest]) (*connect.Response[pmv1.ListRolesResponse], error) {
+				return c.ListRoles(ctx, r)
+			}),
+		fileRPC(a, "update", func() *pmv1.UpdateRoleRequest { return &pmv1.UpdateRoleRequest{} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.UpdateRoleRequest]) (*connect.Response[pmv1.UpdateRoleResponse], error) {
+				return c.UpdateRole(ctx, r)
+			}),
+		destructive(idRPC(a, "delete <id>", func(id string) *pmv1.DeleteRoleRequest { return &pmv1.DeleteRoleRequest{Id: id} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.DeleteRoleRequest]) (*connect.Response[pmv1.DeleteRoleResponse], error) {
+				return c.DeleteRole(ctx, r)
+			})),
+		emptyRPC(a, "permissions", &pmv1.ListPermissionsRequest{},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListPermissionsRequest]) (*connect.Response[pmv1.ListPermissionsResponse], error) {
+				return c.ListPermissions(ctx, r)
+			}),
+		scopedGrantRPC(a, "assign <role-id> <user-id>",
+			func(args []string, kind pmv1.RoleGrantScopeKind, scopeID string) *pmv1.AssignRoleToUserRequest {
+				return &pmv1.AssignRoleToUserRequest{RoleId: args[0], UserId: args[1], ScopeKind: kind, ScopeId: scopeID}
+			},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.AssignRoleToUserRequest]) (*connect.Response[pmv1.AssignRoleToUserResponse], error) {
+				return c.AssignRoleToUser(ctx, r)
+			}),
+		destructive(scopedGrantRPC(a, "revoke <role-id> <user-id>",
+			func(args []string, kind pmv1.RoleGrantScopeKind, scopeID string) *pmv1.RevokeRoleFromUserRequest {
+				return &pmv1.RevokeRoleFromUserRequest{RoleId: args[0], UserId: args[1], ScopeKind: kind, ScopeId: scopeID}
+			},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.RevokeRoleFromUserRequest]) (*connect.Response[pmv1.RevokeRoleFromUserResponse], error) {
+				return c.RevokeRoleFromUser(ctx, r)
+			})),
+	)
+	return command
+}
+
+func (a *app) userGroupCommand() *cobra.Command {
+	command := &cobra.Command{Use: "usergroup", Short: "Manage user groups and their role grants"}
+	command.AddCommand(
+		fileRPC(a, "create", func() *pmv1.CreateUserGroupRequest { return &pmv1.CreateUserGroupRequest{} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.CreateUserGroupRequest]) (*connect.Response[pmv1.CreateUserGroupResponse], error) {
+				return c.CreateUserGroup(ctx, r)
+			}),
+		idRPC(a, "get <id>", func(id string) *pmv1.GetUserGroupRequest { return &pmv1.GetUserGroupRequest{Id: id} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetUserGroupRequest]) (*connect.Response[pmv1.GetUserGroupResponse], error) {
+				return c.GetUserGroup(ctx, r)
+			}),
+		emptyRPC(a, "list", &pmv1.ListUserGroupsRequest{},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListUserGroupsRequest]) (*connect.Response[pmv1.ListUserGroupsResponse], error) {
+				return c.ListUserGroups(ctx, r)
+			}),
+		fileRPC(a, "update", func() *pmv1.UpdateUserGroupRequest { return &pmv1.UpdateUserGroupRequest{} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.UpdateUserGroupRequest]) (*connect.Response[pmv1.UpdateUserGroupResponse], error) {
+				return c.UpdateUserGroup(ctx, r)
+			}),
+		destructive(idRPC(a, "delete <id>", func(id string) *pmv1.DeleteUserGroupRequest { return &pmv1.DeleteUserGroupRequest{Id: id} },
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.DeleteUserGroupRequest]) (*connect.Response[pmv1.DeleteUserGroupResponse], error) {
+				return c.DeleteUserGroup(ctx, r)
+			})),
+		pairRPC(a, "add-user <group-id> <user-id>",
+			func(groupID, userID string) *pmv1.AddUserToGroupRequest {
+				return &pmv1.AddUserToGroupRequest{GroupId: groupID, UserId: userID}
+			},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.AddUserToGroupRequest]) (*connect.Response[pmv1.AddUserToGroupResponse], error) {
+				return c.AddUserToGroup(ctx, r)
+			}),
+		destructive(pairRPC(a, "remove-user <group-id> <user-id>",
+			func(groupID, userID string) *pmv1.RemoveUserFromGroupRequest {
+				return &pmv1.RemoveUserFromGroupRequest{GroupId: groupID, UserId: userID}
+			},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.RemoveUserFromGroupRequest]) (*connect.Response[pmv1.RemoveUserFromGroupResponse], error) {
+				return c.RemoveUserFromGroup(ctx, r)
+			})),
+		scopedGrantRPC(a, "assign-role <group-id> <role-id>",
+			func(args []string, kind pmv1.RoleGrantScopeKind, scopeID string) *pmv1.AssignRoleToUserGroupRequest {
+				return &pmv1.AssignRoleToUserGroupRequest{GroupId: args[0], RoleId: args[1], ScopeKind: kind, ScopeId: scopeID}
+			},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.AssignRoleToUserGroupRequest]) (*connect.Response[pmv1.AssignRoleToUserGroupResponse], error) {
+				return c.AssignRoleToUserGroup(ctx, r)
+			}),
+		destructive(scopedGrantRPC(a, "revoke-role <group-id> <role-id>",
+			func(args []string, kind pmv1.RoleGrantScopeKind, scopeID string) *pmv1.RevokeRoleFromUserGroupRequest {
+				return &pmv1.RevokeRoleFromUserGroupRequest{GroupId: args[0], RoleId: args[1], ScopeKind: kind, ScopeId: scopeID}
+			},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.RevokeRoleFromUserGroupRequest]) (*connect.Response[pmv1.RevokeRoleFromUserGroupResponse], error) {
+				return c.RevokeRoleFromUserGroup(ctx, r)
+			})),
+	)
+	return command
+}
+
+func pairRPC[I, O any](a *app, use string, newRequest func(string, string) *I, call func(context.Context, powermanagev1connect.ControlServiceClient, *connect.Request[I]) (*connect.Response[O], error)) *cobra.Command {
+	return &cobra.Command{
+		Use: use, Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			response, err := callAuthenticated(cmd.Context(), a, newRequest(args[0], args[1]), call)
+			if err != nil {
+				return err
+			}
+			return writeRPCResponse(a, response.Msg)
+		},
+	}
+}
+
+// scopedGrantRPC builds a two-argument grant command with the contract's
+// paired-or-neither --scope-kind/--scope-id flags. The pairing is checked
+// locally so a half-scoped request never reaches control, where it would be
+// rejected anyway.
+func scopedGrantRPC[I, O any](a *app, use string, newRequest func([]string, pmv1.RoleGrantScopeKind, string) *I, call func(context.Context, powermanagev1connect.ControlServiceClient, *connect.Request[I]) (*connect.Response[O], error)) *cobra.Command {
+	var scopeKind, scopeID string
+	command := &cobra.Command{
+		Use: use, Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, err := parseScopeKind(scopeKind)
+			if err != nil {
+				return err
+			}
+			if (kind == pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_UNSPECIFIED) != (scopeID == "") {
+				return errors.New("--scope-kind and --scope-id must be set together")
+			}
+			response, err := callAuthenticated(cmd.Context(), a, newRequest(args, kind, scopeID), call)
+			if err != nil {
+				return err
+			}
+			return writeRPCResponse(a, response.Msg)
+		},
+	}
+	command.Flags().StringVar(&scopeKind, "scope-kind", "", "grant scope kind: device-group or user-group (default: global)")
+	command.Flags().StringVar(&scopeID, "scope-id", "", "ID of the group that scopes the grant")
+	return command
+}
+
+func parseScopeKind(raw string) (pmv1.RoleGrantScopeKind, error) {
+	switch raw {
+	case "":
+		return pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_UNSPECIFIED, nil
+	case "device-group":
+		return pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_DEVICE_GROUP, nil
+	case "user-group":
+		return pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_USER_GROUP, nil
+	default:
+		return 0, fmt.Errorf("scope kind %q must be device-group or user-group", raw)
+	}
+}
+
+func (a *app) canICommand() *cobra.Command {
+	var on, as string
+	command := &cobra.Command{
+		Use:   "can-i <permission>",
+		Short: "Simulate whether a user's effective grants allow a permission",
+		Long: "Evaluate a permission client-side from the subject's direct and inherited role grants.\n" +
+			"The result mirrors control's scope rules but is advisory; control remains the authority.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := parseAccessTarget(on)
+			if err != nil {
+				return err
+			}
+			query, err := a.loadAccessQuery(cmd.Context(), args[0], as, target)
+			if err != nil {
+				return err
+			}
+			decision := evaluateAccess(query)
+			if err := a.writeDocument(decision, func(w io.Writer) error { return writeAccessDecision(w, decision) }); err != nil {
+				return err
+			}
+			if !decision.Allowed {
+				return errPermissionNotGranted
+			}
+			return nil
+		},
+	}
+	command.Flags().StringVar(&on, "on", "", "target as device/<id> or user/<id> (default: no specific target)")
+	command.Flags().StringVar(&as, "as", "", "user ID to evaluate (default: the signed-in user)")
+	return command
+}
+
+// accessTarget names the resource a simulated check applies to. kind is
+// UNSPECIFIED for an untargeted check, which only a global grant satisfies.
+type accessTarget struct {
+	kind pmv1.PermissionTargetKind
+	id   string
+}
+
+func parseAccessTarget(raw string) (accessTarget, error) {
+	if raw == "" {
+		return accessTarget{}, nil
+	}
+	kind, id, ok := strings.Cut(raw, "/")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return accessTarget{}, fmt.Errorf("target %q must be device/<id> or user/<id>", raw)
+	}
+	switch kind {
+	case "device":
+		return accessTarget{kind: pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_DEVICE, id: id}, nil
+	case "user":
+		return accessTarget{kind: pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_USER, id: id}, nil
+	default:
+		return accessTarget{}, fmt.Errorf("target kind %q must be device or user", kind)
+	}
+}
+
+// accessGrant is one role grant reaching the subject, with the path it came
+// through ("direct" or the user group that carries it).
+type accessGrant struct {
+	grant *pmv1.RoleGrant
+	via   string
+}
+
+// accessQuery is everything evaluateAccess needs, fetched up front so the
+// evaluation itself is pure.
+type accessQuery struct {
+	permission   string
+	info         *pmv1.PermissionInfo
+	subject      string
+	grants       []accessGrant
+	target       accessTarget
+	targetGroups map[string]string // group ID -> name for the target's groups
+}
+
+type accessDecision struct {
+	Allowed    bool     `json:"allowed"`
+	Permission string   `json:"permission"`
+	Subject    string   `json:"subject"`
+	Reasons    []string `json:"reasons"`
+}
+
+func (a *app) loadAccessQuery(ctx context.Context, permission, as string, target accessTarget) (accessQuery, error) {
+	query := accessQuery{permission: permission, target: target}
+	permissions, err := callAuthenticated(ctx, a, &pmv1.ListPermissionsRequest{},
+		func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListPermissionsRequest]) (*connect.Response[pmv1.ListPermissionsResponse], error) {
+			return c.ListPermissions(ctx, r)
+		})
+	if err != nil {
+		return accessQuery{}, err
+	}
+	for _, info := range permissions.Msg.Permissions {
+		if info.Key == permission {
+			query.info = info
+			break
+		}
+	}
+	if query.info == nil {
+		return accessQuery{}, fmt.Errorf("unknown permission %q (see 'powermanage role permissions')", permission)
+	}
+	var user *pmv1.User
+	if as == "" {
+		response, err := callAuthenticated(ctx, a, &pmv1.GetCurrentUserRequest{},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetCurrentUserRequest]) (*connect.Response[pmv1.GetCurrentUserResponse], error) {
+				return c.GetCurrentUser(ctx, r)
+			})
+		if err != nil {
+			return accessQuery{}, err
+		}
+		user = response.Msg.User
+	} else {
+		response, err := callAuthenticated(ctx, a, &pmv1.GetUserRequest{Id: as},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.GetUserRequest]) (*connect.Response[pmv1.GetUserResponse], error) {
+				return c.GetUser(ctx, r)
+			})
+		if err != nil {
+			return accessQuery{}, err
+		}
+		user = response.Msg.User
+	}
+	if user == nil || user.Id == "" {
+		return accessQuery{}, errors.New("control returned no user")
+	}
+	query.subject = user.Email
+	if query.subject == "" {
+		query.subject = user.Id
+	}
+	for _, grant := range user.RoleGrants {
+		query.grants = append(query.grants, accessGrant{grant: grant, via: "direct"})
+	}
+	subjectGroups, err := a.userGroupsFor(ctx, user.Id)
+	if err != nil {
+		return accessQuery{}, err
+	}
+	for _, group := range subjectGroups {
+		for _, grant := range group.RoleGrants {
+			query.grants = append(query.grants, accessGrant{grant: grant, via: "user group " + displayName(group.Name, group.Id)})
+		}
+	}
+	query.targetGroups = map[string]string{}
+	switch target.kind {
+	case pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_DEVICE:
+		response, err := callAuthenticated(ctx, a, &pmv1.ListDeviceGroupsForDeviceRequest{DeviceId: target.id},
+			func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListDeviceGroupsForDeviceRequest]) (*connect.Response[pmv1.ListDeviceGroupsForDeviceResponse], error) {
+				return c.ListDeviceGroupsForDevice(ctx, r)
+			})
+		if err != nil {
+			return accessQuery{}, err
+		}
+		for _, group := range response.Msg.Groups {
+			query.targetGroups[group.Id] = group.Name
+		}
+	case pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_USER:
+		groups, err := a.userGroupsFor(ctx, target.id)
+		if err != nil {
+			return accessQuery{}, err
+		}
+		for _, group := range groups {
+			query.targetGroups[group.Id] = group.Name
+		}
+	}
+	return query, nil
+}
+
+func (a *app) userGroupsFor(ctx context.Context, userID string) ([]*pmv1.UserGroup, error) {
+	response, err := callAuthenticated(ctx, a, &pmv1.ListUserGroupsForUserRequest{UserId: userID},
+		func(ctx context.Context, c powermanagev1connect.ControlServiceClient, r *connect.Request[pmv1.ListUserGroupsForUserRequest]) (*connect.Response[pmv1.ListUserGroupsForUserResponse], error) {
+			return c.ListUserGroupsForUser(ctx, r)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return response.Msg.Groups, nil
+}
+
+// evaluateAccess applies control's grant rules to a loaded query. A global
+// grant allows every target. A scoped grant allows only a target of the
+// permission's declared kind that is a member of the scoping group, and only
+// when the scope kind matches that target kind — the same fail-closed pairing
+// the role-assignment handler enforces, so a permission with an UNSPECIFIED
+// target kind is never satisfied through a scope.
+func evaluateAccess(q accessQuery) accessDecision {
+	decision := accessDecision{Permission: q.permission, Subject: q.subject}
+	wantScope := scopeKindFor(q.info.GetTargetKind())
+	for _, g := range q.grants {
+		role := g.grant.GetRole()
+		if !slices.Contains(role.GetPermissions(), q.permission) {
+			continue
+		}
+		roleName := displayName(role.GetName(), role.GetId())
+		if g.grant.GetScopeKind() == pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_UNSPECIFIED {
+			decision.Allowed = true
+			decision.Reasons = append(decision.Reasons, fmt.Sprintf("granted globally by role %s (%s)", roleName, g.via))
+			continue
+		}
+		scope := scopeLabel(g.grant)
+		switch {
+		case wantScope == pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_UNSPECIFIED || g.grant.GetScopeKind() != wantScope:
+			decision.Reasons = append(decision.Reasons, fmt.Sprintf("ignored role %s (%s): %s does not apply to this permission", roleName, g.via, scope))
+		case q.target.kind == pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_UNSPECIFIED:
+			decision.Reasons = append(decision.Reasons, fmt.Sprintf("role %s (%s) grants it only within %s; pass --on to check a target", roleName, g.via, scope))
+		case q.target.kind != q.info.GetTargetKind():
+			decision.Reasons = append(decision.Reasons, fmt.Sprintf("role %s (%s) is scoped to %s, which cannot contain the target", roleName, g.via, scope))
+		default:
+			if _, member := q.targetGroups[g.grant.GetScopeId()]; member {
+				decision.Allowed = true
+				decision.Reasons = append(decision.Reasons, fmt.Sprintf("granted by role %s (%s) within %s", roleName, g.via, scope))
+			} else {
+				decision.Reasons = append(decision.Reasons, fmt.Sprintf("role %s (%s) is scoped to %s, which does not contain the target", roleName, g.via, scope))
+			}
+		}
+	}
+	if len(decision.Reasons) == 0 {
+		decision.Reasons = append(decision.Reasons, "no role reaching the subject contains this permission")
+	}
+	return decision
+}
+
+func scopeKindFor(kind pmv1.PermissionTargetKind) pmv1.RoleGrantScopeKind {
+	switch kind {
+	case pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_DEVICE:
+		return pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_DEVICE_GROUP
+	case pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_USER:
+		return pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_USER_GROUP
+	default:
+		return pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_UNSPECIFIED
+	}
+}
+
+func scopeLabel(grant *pmv1.RoleGrant) string {
+	kind := "device group"
+	if grant.GetScopeKind() == pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_USER_GROUP {
+		kind = "user group"
+	}
+	return kind + " " + displayName(grant.GetScopeName(), grant.GetScopeId())
+}
+
+func displayName(name, id string) string {
+	if name == "" {
+		return id
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+func writeAccessDecision(w io.Writer, decision accessDecision) error {
+	answer := "no"
+	if decision.Allowed {
+		answer = "yes"
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s\n", answer)
+	for _, reason := range decision.Reasons {
+		fmt.Fprintf(&out, "  %s\n", reason)
+	}
+	if _, err := io.WriteString(w, out.String()); err != nil {
+		return fmt.Errorf("write access decision: %w", err)
+	}
+	return nil
+}
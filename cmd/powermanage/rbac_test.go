@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pmv1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1/powermanagev1connect"
+)
+
+func TestEvaluateAccessAppliesGrantScopes(t *testing.T) {
+	deviceInfo := &pmv1.PermissionInfo{Key: "DispatchAction", TargetKind: pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_DEVICE}
+	orgInfo := &pmv1.PermissionInfo{Key: "CreateRole"}
+	operator := &pmv1.Role{Id: "role-operator", Name: "Operator", Permissions: []string{"DispatchAction"}}
+	admin := &pmv1.Role{Id: "role-admin", Name: "Admin", Permissions: []string{"CreateRole", "DispatchAction"}}
+	onDevice := accessTarget{kind: pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_DEVICE, id: "device-1"}
+	onUser := accessTarget{kind: pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_USER, id: "user-2"}
+	scoped := func(role *pmv1.Role, kind pmv1.RoleGrantScopeKind, id string) accessGrant {
+		return accessGrant{grant: &pmv1.RoleGrant{Role: role, ScopeKind: kind, ScopeId: id}, via: "direct"}
+	}
+	global := func(role *pmv1.Role) accessGrant {
+		return accessGrant{grant: &pmv1.RoleGrant{Role: role}, via: "user group \"ops\""}
+	}
+
+	for name, tc := range map[string]struct {
+		query accessQuery
+		want  bool
+	}{
+		"global grant allows any target": {
+			accessQuery{permission: "DispatchAction", info: deviceInfo, grants: []accessGrant{global(operator)}, target: onDevice}, true,
+		},
+		"global grant allows an untargeted check": {
+			accessQuery{permission: "CreateRole", info: orgInfo, grants: []accessGrant{global(admin)}}, true,
+		},
+		"scoped grant allows a member device": {
+			accessQuery{permission: "DispatchAction", info: deviceInfo,
+				grants: []accessGrant{scoped(operator, pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_DEVICE_GROUP, "kiosks")},
+				target: onDevice, targetGroups: map[string]string{"kiosks": "Kiosks"}}, true,
+		},
+		"scoped grant refuses a non-member device": {
+			accessQuery{permission: "DispatchAction", info: deviceInfo,
+				grants: []accessGrant{scoped(operator, pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_DEVICE_GROUP, "kiosks")},
+				target: onDevice, targetGroups: map[string]string{"laptops": "Laptops"}}, false,
+		},
+		"scoped grant refuses an untargeted check": {
+			accessQuery{permission: "DispatchAction", info: deviceInfo,
+				grants: []accessGrant{scoped(operator, pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_DEVICE_GROUP, "kiosks")}}, false,
+		},
+		"mismatched scope kind never applies": {
+			accessQuery{permission: "DispatchAction", info: deviceInfo,
+				grants: []accessGrant{scoped(operator, pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_USER_GROUP, "staff")},
+				target: onUser, targetGroups: map[string]string{"staff": "Staff"}}, false,
+		},
+		"unscopable permission is never satisfied through a scope": {
+			accessQuery{permission: "CreateRole", info: orgInfo,
+				grants: []accessGrant{scoped(admin, pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_DEVICE_GROUP, "kiosks")},
+				target: onDevice, targetGroups: map[string]string{"kiosks": "Kiosks"}}, false,
+		},
+		"role without the permission": {
+			accessQuery{permission: "CreateRole", info: orgInfo, grants: []accessGrant{global(operator)}}, false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			decision := evaluateAccess(tc.query)
+			assert.Equal(t, tc.want, decision.Allowed)
+			assert.NotEmpty(t, decision.Reasons)
+		})
+	}
+}
+
+func TestParseAccessTarget(t *testing.T) {
+	target, err := parseAccessTarget("device/01K0000000000000000000000")
+	require.NoError(t, err)
+	assert.Equal(t, pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_DEVICE, target.kind)
+	for _, rejected := range []string{"device", "device/", "group/1", "user/a/b"} {
+		_, err := parseAccessTarget(rejected)
+		assert.Error(t, err, rejected)
+	}
+}
+
+func TestCanIResolvesInheritedGrantsAgainstTargetGroups(t *testing.T) {
+	operator := &pmv1.Role{Id: "role-operator", Name: "Operator", Permissions: []string{"DispatchAction"}}
+	mux := http.NewServeMux()
+	mux.Handle(powermanagev1connect.ControlServiceListPermissionsProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceListPermissionsProcedure,
+			func(context.Context, *connect.Request[pmv1.ListPermissionsRequest]) (*connect.Response[pmv1.ListPermissionsResponse], error) {
+				return connect.NewResponse(&pmv1.ListPermissionsResponse{Permissions: []*pmv1.PermissionInfo{
+					{Key: "DispatchAction", TargetKind: pmv1.PermissionTargetKind_PERMISSION_TARGET_KIND_DEVICE},
+				}}), nil
+			}))
+	mux.Handle(powermanagev1connect.ControlServiceGetCurrentUserProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceGetCurrentUserProcedure,
+			func(context.Context, *connect.Request[pmv1.GetCurrentUserRequest]) (*connect.Response[pmv1.GetCurrentUserResponse], error) {
+				return connect.NewResponse(&pmv1.GetCurrentUserResponse{User: &pmv1.User{Id: "user-1", Email: "ops@example.com"}}), nil
+			}))
+	mux.Handle(powermanagev1connect.ControlServiceListUserGroupsForUserProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceListUserGroupsForUserProcedure,
+			func(_ context.Context, request *connect.Request[pmv1.ListUserGroupsForUserRequest]) (*connect.Response[pmv1.ListUserGroupsForUserResponse], error) {
+				assert.Equal(t, "user-1", request.Msg.UserId)
+				return connect.NewResponse(&pmv1.ListUserGroupsForUserResponse{Groups: []*pmv1.UserGroup{{
+					Id: "group-ops", Name: "ops",
+					RoleGrants: []*pmv1.RoleGrant{{
+						Role: operator, ScopeKind: pmv1.RoleGrantScopeKind_ROLE_GRANT_SCOPE_KIND_DEVICE_GROUP,
+						ScopeId: "group-kiosks", ScopeName: "Kiosks",
+					}},
+				}}}), nil
+			}))
+	deviceGroups := map[string][]*pmv1.DeviceGroup{
+		"device-kiosk":  {{Id: "group-kiosks", Name: "Kiosks"}},
+		"device-laptop": {{Id: "group-laptops", Name: "Laptops"}},
+	}
+	mux.Handle(powermanagev1connect.ControlServiceListDeviceGroupsForDeviceProcedure,
+		connect.NewUnaryHandler(powermanagev1connect.ControlServiceListDeviceGroupsForDeviceProcedure,
+			func(_ context.Context, request *connect.Request[pmv1.ListDeviceGroupsForDeviceRequest]) (*connect.Response[pmv1.ListDeviceGroupsForDeviceResponse], error) {
+				return connect.NewResponse(&pmv1.ListDeviceGroupsForDeviceResponse{Groups: deviceGroups[request.Msg.DeviceId]}), nil
+			}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	directory := filepath.Join(t.TempDir(), "powermanage")
+	a := &app{
+		stdin: strings.NewReader(""), stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{},
+		httpClient: server.Client(), now: time.Now,
+		configPath: filepath.Join(directory, "config.json"), sessionPath: filepath.Join(directory, "session.json"),
+	}
+	require.NoError(t, writeSession(a.sessionPath, sessionFile{
+		ServerURL: server.URL, AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	command := a.canICommand()
+	command.SetArgs([]string{"DispatchAction", "--on", "device/device-kiosk"})
+	require.NoError(t, command.ExecuteContext(t.Context()))
+	output := a.stdout.(*bytes.Buffer).String()
+	assert.True(t, strings.HasPrefix(output, "yes\n"), output)
+	assert.Contains(t, output, `user group "ops"`)
+
+	a.stdout.(*bytes.Buffer).Reset()
+	command = a.canICommand()
+	command.SetArgs([]string{"DispatchAction", "--on", "device/device-laptop"})
+	assert.ErrorIs(t, command.ExecuteContext(t.Context()), errPermissionNotGranted)
+	assert.True(t, strings.HasPrefix(a.stdout.(*bytes.Buffer).String(), "no\n"))
+
+	a.stdout.(*bytes.Buffer).Reset()
+	a.output = "json"
+	command = a.canICommand()
+	command.SetArgs([]string{"DispatchAction", "--on", "device/device-laptop"})
+	assert.ErrorIs(t, command.ExecuteContext(t.Context()), errPermissionNotGranted)
+	var decision accessDecision
+	require.NoError(t, json.Unmarshal(a.stdout.(*bytes.Buffer).Bytes(), &decision))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "DispatchAction", decision.Permission)
+	a.output = ""
+
+	command = a.canICommand()
+	command.SetArgs([]string{"ReadMinds"})
+	assert.ErrorContains(t, command.ExecuteContext(t.Context()), "unknown permission")
+}
+
+func TestScopedGrantRequiresPairedScopeFlags(t *testing.T) {
+	a := &app{stdin: strings.NewReader(""), stdout: &bytes.Buffer{}}
+	for _, args := range [][]string{
+		{"assign", "role", "user", "--scope-kind", "device-group"},
+		{"assign", "role", "user", "--scope-id", "group"},
+		{"assign", "role", "user", "--scope-kind", "tenant", "--scope-id", "group"},
+	} {
+		command := a.roleCommand()
+		command.SetArgs(args)
+		assert.Error(t, command.ExecuteContext(t.Context()), args)
+	}
+}
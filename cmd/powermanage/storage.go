@@ -29,7 +29,17 @@ const (
 	maxCredentialFileBytes    = 64 << 10
 )
 
+// configFile holds the named server contexts. Each context has its own session
+// file, so signing in to one never replaces another's credentials.
 type configFile struct {
+	CurrentContext string                   `json:"current_context,omitempty"`
+	Contexts       map[string]contextConfig `json:"contexts,omitempty"`
+	// LegacyServerURL is the single server of a config written before
+	// contexts. readConfig moves it into the default context.
+	LegacyServerURL string `json:"server_url,omitempty"`
+}
+
+type contextConfig struct {
 	ServerURL string `json:"server_url"`
 }
 
@@ -92,13 +102,66 @@ func writeProtoJSON(w io.Writer, message proto.Message) error {
 	return nil
 }
 
-func configPaths() (directory, configPath, sessionPath string, err error) {
+func configPaths() (directory, configPath, sessionDir string, err error) {
 	base, err := os.UserConfigDir()
 	if err != nil {
 		return "", "", "", fmt.Errorf("find user config directory: %w", err)
 	}
 	directory = filepath.Join(base, "powermanage")
-	return directory, filepath.Join(directory, "config.json"), filepath.Join(directory, "session.json"), nil
+	return directory, filepath.Join(directory, "config.json"), filepath.Join(directory, "sessions"), nil
+}
+
+// validateContextName keeps context names usable as session file names: a
+// lowercase letter or digit followed by at most 62 of [a-z0-9._-]. The leading
+// character rules out "." and ".." and hidden files.
+func validateContextName(name string) error {
+	if name == "" || len(name) > 63 {
+		return errors.New("context name must be 1-63 characters")
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case i > 0 && (r == '.' || r == '_' || r == '-'):
+		default:
+			return fmt.Errorf("context name %q must be lowercase letters, digits, '.', '_' or '-', starting with a letter or digit", name)
+		}
+	}
+	return nil
+}
+
+// readConfig reads the local context configuration. A missing file is an empty
+// configuration, not an error, so the first context can be added to it. A
+// config from before contexts, holding only a server URL, reads as that server
+// in the current "default" context and is stored that way on the next write.
+func readConfig(path string) (configFile, error) {
+	var config configFile
+	if err := readPrivateJSON(path, &config); err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return configFile{}, nil
+		}
+		return configFile{}, fmt.Errorf("read local config: %w", err)
+	}
+	if config.LegacyServerURL != "" {
+		if len(config.Contexts) > 0 || config.CurrentContext != "" {
+			return configFile{}, errors.New("local config has both a server_url and contexts")
+		}
+		config = configFile{
+			CurrentContext: defaultContextName,
+			Contexts:       map[string]contextConfig{defaultContextName: {ServerURL: config.LegacyServerURL}},
+		}
+	}
+	for name, entry := range config.Contexts {
+		if err := validateContextName(name); err != nil {
+			return configFile{}, fmt.Errorf("invalid stored context: %w", err)
+		}
+		if _, err := validateServerURL(entry.ServerURL); err != nil {
+			return configFile{}, fmt.Errorf("invalid stored server URL for context %q: %w", name, err)
+		}
+	}
+	if _, ok := config.Contexts[config.CurrentContext]; config.CurrentContext != "" && !ok {
+		return configFile{}, fmt.Errorf("current context %q is not configured", config.CurrentContext)
+	}
+	return config, nil
 }
 
 func writeSession(path string, session sessionFile) error {
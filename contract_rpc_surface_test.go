@@ -572,6 +572,9 @@ func TestContract_TargetShape(t *testing.T) {
 		{"AgentMessage", "manifest_result", protoreflect.MessageKind, "ManifestResult", false, "there is no result for the complete manifest"},
 		{"SealedValue", "version", protoreflect.Uint32Kind, "", false, "the sealed envelope is unversioned"},
 		{"SealedValue", "ciphertext", protoreflect.BytesKind, "", false, "the sealed envelope carries no ciphertext"},
+		{"SealedValue", "key_id", protoreflect.BytesKind, "", false, "a recipient cannot tell which key to open with across a rotation"},
+		{"Hello", "agent_sealing_public_key", protoreflect.BytesKind, "", false, "a rotated agent sealing key cannot be announced"},
+		{"Welcome", "control_sealing_public_key", protoreflect.BytesKind, "", false, "a rotated control sealing key cannot be announced"},
 		{"RegisterRequest", "agent_sealing_public_key", protoreflect.BytesKind, "", false, "control cannot seal a secret to this agent"},
 		{"RegisterResponse", "control_sealing_public_key", protoreflect.BytesKind, "", false, "the agent cannot seal a secret to control"},
 	} {
@@ -2,6 +2,7 @@
 package crypto
 
 import (
+	stdcrypto "crypto"
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/x509"
@@ -11,9 +12,11 @@ import (
 	"fmt"
 )
 
-// ErrUnsupportedKeyType is returned when a stored device key is not Ed25519.
-// The Control Server's CA refuses any other identity key type, so a renewal CSR
-// built from one could never be signed.
+// ErrUnsupportedKeyType is returned when a stored or externally held device key
+// is not Ed25519. The Control Server's CA refuses any other identity key type,
+// so a CSR built from one could never be signed. For the same reason there is
+// no option for P-256/P-384 keys or for DNS/URI SANs: device certificates
+// follow the CA's policy, not the enterprise PKI's.
 var ErrUnsupportedKeyType = errors.New("crypto: device identity key must be Ed25519")
 
 // GenerateCSR creates a new Ed25519 key pair and returns the CSR (PEM)
@@ -84,6 +87,24 @@ func GenerateCSRFromKey(hostname string, keyPEM []byte) (csrPEM []byte, err erro
 	if !ok {
 		return nil, fmt.Errorf("%w: got %T", ErrUnsupportedKeyType, parsed)
 	}
+	return GenerateCSRFromSigner(hostname, privateKey)
+}
+
+// GenerateCSRFromSigner creates a CSR signed by signer, for an Ed25519 device
+// key held outside the process (a PKCS#11 token, a platform keystore, a
+// signing agent) where no PKCS#8 PEM exists to pass to GenerateCSRFromKey.
+// signer's public key must be Ed25519, and it must sign the CSR as pure
+// Ed25519 (opts.HashFunc() == 0). The CSR has the same shape as GenerateCSR's:
+// the hostname as CN and no SANs.
+func GenerateCSRFromSigner(hostname string, signer stdcrypto.Signer) (csrPEM []byte, err error) {
+	if signer == nil {
+		return nil, errors.New("nil signer")
+	}
+	if pub, ok := signer.Public().(ed25519.PublicKey); !ok {
+		return nil, fmt.Errorf("%w: got %T", ErrUnsupportedKeyType, signer.Public())
+	} else if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: public key is %d bytes", ErrUnsupportedKeyType, len(pub))
+	}
 
 	// No SANs — see GenerateCSR for the rationale. Renewal CSRs
 	// follow the same shape as initial-enrolment CSRs.
@@ -93,7 +114,7 @@ func GenerateCSRFromKey(hostname string, keyPEM []byte) (csrPEM []byte, err erro
 		},
 	}
 
-	csrDER, err := x509.CreateCertificateRequest(rand.Reader, template, privateKey)
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
 	if err != nil {
 		return nil, fmt.Errorf("create CSR: %w", err)
 	}
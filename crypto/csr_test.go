@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	stdcrypto "crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
@@ -8,6 +9,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
+	"io"
 	"testing"
 )
 
@@ -254,3 +256,70 @@ func TestGenerateCSRFromKey_SameKeyProducesDifferentCSR(t *testing.T) {
 		t.Fatal("CSRs should have different CNs")
 	}
 }
+
+// externalSigner stands in for a key held outside the process: it exposes only
+// Public and Sign, never the private key, and counts the signatures it makes.
+type externalSigner struct {
+	key   ed25519.PrivateKey
+	signs int
+}
+
+func (s *externalSigner) Public() stdcrypto.PublicKey { return s.key.Public() }
+
+func (s *externalSigner) Sign(rand io.Reader, digest []byte, opts stdcrypto.SignerOpts) ([]byte, error) {
+	s.signs++
+	return s.key.Sign(rand, digest, opts)
+}
+
+func TestGenerateCSRFromSigner(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := &externalSigner{key: key}
+
+	csrPEM, err := GenerateCSRFromSigner("host-hsm", signer)
+	if err != nil {
+		t.Fatalf("GenerateCSRFromSigner: %v", err)
+	}
+	if signer.signs != 1 {
+		t.Fatalf("signer used %d times, want 1", signer.signs)
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseCertificateRequest: %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Fatalf("CSR signature check failed: %v", err)
+	}
+	if pub, ok := csr.PublicKey.(ed25519.PublicKey); !ok || !pub.Equal(key.Public()) {
+		t.Fatal("CSR public key does not match the signer's")
+	}
+	if csr.Subject.CommonName != "host-hsm" {
+		t.Fatalf("CN = %q, want host-hsm", csr.Subject.CommonName)
+	}
+	if len(csr.DNSNames)+len(csr.IPAddresses)+len(csr.EmailAddresses)+len(csr.URIs) != 0 {
+		t.Fatal("CSR must not carry SANs")
+	}
+}
+
+// An external signer holding a key type the CA refuses is rejected before it
+// is asked to sign anything.
+func TestGenerateCSRFromSigner_RejectsNonEd25519Signer(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	csrPEM, err := GenerateCSRFromSigner("host", key)
+	if !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Fatalf("err = %v, want ErrUnsupportedKeyType", err)
+	}
+	if csrPEM != nil {
+		t.Fatal("no CSR must be produced for a non-Ed25519 signer")
+	}
+	if _, err := GenerateCSRFromSigner("host", nil); err == nil {
+		t.Fatal("expected error for a nil signer")
+	}
+}
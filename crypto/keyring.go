@@ -0,0 +1,219 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sealing key rotation. A recipient holds a Keyring: one current X25519 key
+// that senders seal to, plus the keys it rotated away from, each retained
+// until the end of its overlap period. A sealed value carries the key id of
+// its recipient key (SealedValue.key_id), so the keyring opens it with
+// exactly that key and rotation never strands a value already in flight. A
+// value with no key id, from a sender that predates them, is tried with the
+// keys the keyring holds.
+//
+// Rotation, for either recipient:
+//
+//	1. Rotate(next, now+overlap): next becomes current, the old key is retained.
+//	2. Announce PublicKey() to the sender (Hello for the agent, Welcome for
+//	   control). The sender seals to it from then on.
+//	3. Prune(now) once the overlap has passed. Values still sealed to the
+//	   pruned key fail with ErrUnknownSealingKey and must be sealed again.
+//
+// The overlap must outlast the longest a sealed value can wait before it is
+// opened — a queued delivery, a value awaiting retry — because the recipient
+// cannot tell when the last one has arrived.
+
+// KeyIDLen is the length of a sealing key id.
+const KeyIDLen = 8
+
+// keyIDDomain separates sealing key fingerprints from any other SHA-256 of a
+// raw public key.
+const keyIDDomain = "power-manage-sealing-key-id:v1"
+
+// ErrUnknownSealingKey is returned when a sealed value names a key the keyring
+// does not hold: one it never had, or one pruned after its overlap.
+var ErrUnknownSealingKey = errors.New("crypto: sealed to a key this keyring does not hold")
+
+// SealingKeyID returns the key id of an X25519 public key: the first KeyIDLen
+// bytes of a domain-separated SHA-256 of its raw encoding. It identifies the
+// key, it does not authenticate it — the seal itself binds the recipient
+// public key.
+func SealingKeyID(pub *ecdh.PublicKey) []byte {
+	h := sha256.New()
+	h.Write([]byte(keyIDDomain))
+	h.Write(pub.Bytes())
+	return h.Sum(nil)[:KeyIDLen]
+}
+
+// RetainedKey is a previous recipient key the keyring still opens with,
+// until Until. Recipients persist these alongside the current key, encrypted
+// at rest like it, and pass them back to NewKeyring on restart.
+type RetainedKey struct {
+	Key   *ecdh.PrivateKey
+	Until time.Time
+}
+
+// Keyring opens sealed values with any key it holds and seals to its current
+// key. It is safe for concurrent use.
+type Keyring struct {
+	mu       sync.RWMutex
+	current  *ecdh.PrivateKey
+	retained []RetainedKey
+}
+
+// NewKeyring returns a keyring whose current key is current, also holding the
+// retained previous keys.
+func NewKeyring(current *ecdh.PrivateKey, retained ...RetainedKey) (*Keyring, error) {
+	if current == nil {
+		return nil, errors.New("crypto: nil current sealing key")
+	}
+	k := &Keyring{current: current}
+	for _, r := range retained {
+		if err := k.retain(r); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// PublicKey returns the current public key: the one to announce, and the one
+// Seal seals to.
+func (k *Keyring) PublicKey() *ecdh.PublicKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current.PublicKey()
+}
+
+// KeyID returns the key id of the current key.
+func (k *Keyring) KeyID() []byte {
+	return SealingKeyID(k.PublicKey())
+}
+
+// Retained returns the previous keys the keyring still holds, for
+// persistence.
+func (k *Keyring) Retained() []RetainedKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return append([]RetainedKey(nil), k.retained...)
+}
+
+// Rotate makes next the current key and retains the previous one until
+// until, the end of the overlap period.
+func (k *Keyring) Rotate(next *ecdh.PrivateKey, until time.Time) error {
+	if next == nil {
+		return errors.New("crypto: nil next sealing key")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.find(SealingKeyID(next.PublicKey())) != nil {
+		return errors.New("crypto: next sealing key is already in the keyring")
+	}
+	k.retained = append(k.retained, RetainedKey{Key: k.current, Until: until})
+	k.current = next
+	return nil
+}
+
+// Prune drops the retained keys whose overlap has ended at now and reports
+// how many it dropped.
+func (k *Keyring) Prune(now time.Time) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	kept := k.retained[:0]
+	for _, r := range k.retained {
+		if now.Before(r.Until) {
+			kept = append(kept, r)
+		}
+	}
+	dropped := len(k.retained) - len(kept)
+	clear(k.retained[len(kept):])
+	k.retained = kept
+	return dropped
+}
+
+// Seal seals plaintext to the current key and returns the key id to send with
+// it. Like SealToPublicKey, it requires a non-empty aad and info.
+func (k *Keyring) Seal(plaintext, aad []byte, info string) (keyID, sealed []byte, err error) {
+	pub := k.PublicKey()
+	sealed, err = SealToPublicKey(pub, plaintext, aad, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return SealingKeyID(pub), sealed, nil
+}
+
+// Open opens a value sealed to the key keyID names, with the same aad and
+// info it was sealed under. It returns ErrUnknownSealingKey when the keyring
+// does not hold that key; every other failure is OpenWithPrivateKey's.
+//
+// An empty keyID comes from a sender that predates key ids and sealed to the
+// only key it knew: Open tries the current key, then the retained ones, and
+// reports the current key's failure if none opens it.
+func (k *Keyring) Open(keyID, sealed, aad []byte, info string) ([]byte, error) {
+	if len(keyID) == 0 {
+		return k.openUnnamed(sealed, aad, info)
+	}
+	priv := k.lookup(keyID)
+	if priv == nil {
+		return nil, fmt.Errorf("%w: key id %x", ErrUnknownSealingKey, keyID)
+	}
+	return OpenWithPrivateKey(priv, sealed, aad, info)
+}
+
+func (k *Keyring) openUnnamed(sealed, aad []byte, info string) ([]byte, error) {
+	k.mu.RLock()
+	keys := make([]*ecdh.PrivateKey, 0, 1+len(k.retained))
+	keys = append(keys, k.current)
+	for i := len(k.retained) - 1; i >= 0; i-- {
+		keys = append(keys, k.retained[i].Key)
+	}
+	k.mu.RUnlock()
+
+	var first error
+	for _, priv := range keys {
+		pt, err := OpenWithPrivateKey(priv, sealed, aad, info)
+		if err == nil {
+			return pt, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return nil, first
+}
+
+func (k *Keyring) lookup(keyID []byte) *ecdh.PrivateKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.find(keyID)
+}
+
+func (k *Keyring) retain(r RetainedKey) error {
+	if r.Key == nil {
+		return errors.New("crypto: nil retained sealing key")
+	}
+	if k.find(SealingKeyID(r.Key.PublicKey())) != nil {
+		return errors.New("crypto: retained sealing key is already in the keyring")
+	}
+	k.retained = append(k.retained, r)
+	return nil
+}
+
+// find returns the key whose id is keyID, or nil. The caller holds mu.
+func (k *Keyring) find(keyID []byte) *ecdh.PrivateKey {
+	if bytes.Equal(SealingKeyID(k.current.PublicKey()), keyID) {
+		return k.current
+	}
+	for _, r := range k.retained {
+		if bytes.Equal(SealingKeyID(r.Key.PublicKey()), keyID) {
+			return r.Key
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+var keyringEpoch = time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestKeyring(t *testing.T) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(genRecipient(t))
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return k
+}
+
+// The key id is a stable, fixed-length fingerprint of the public key alone.
+func TestSealingKeyID_StableAndDistinct(t *testing.T) {
+	a, b := genRecipient(t), genRecipient(t)
+	id := SealingKeyID(a.PublicKey())
+	if len(id) != KeyIDLen {
+		t.Fatalf("key id length = %d, want %d", len(id), KeyIDLen)
+	}
+	if !bytes.Equal(id, SealingKeyID(a.PublicKey())) {
+		t.Error("key id is not stable for the same key")
+	}
+	if bytes.Equal(id, SealingKeyID(b.PublicKey())) {
+		t.Error("two keys share a key id")
+	}
+}
+
+func TestKeyring_SealOpenRoundTrip(t *testing.T) {
+	k := newTestKeyring(t)
+	aad := []byte("device|action|user")
+
+	keyID, sealed, err := k.Seal([]byte("s3cret"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !bytes.Equal(keyID, k.KeyID()) {
+		t.Errorf("Seal key id = %x, want the current key id %x", keyID, k.KeyID())
+	}
+	got, err := k.Open(keyID, sealed, aad, testInfo)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if string(got) != "s3cret" {
+		t.Errorf("Open = %q, want %q", got, "s3cret")
+	}
+}
+
+// A value sealed before a rotation still opens during the overlap, and a
+// value sealed after it goes to the new key.
+func TestKeyring_RotateKeepsOverlap(t *testing.T) {
+	k := newTestKeyring(t)
+	aad := []byte("aad")
+	oldID, oldSealed, err := k.Seal([]byte("before"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if err := k.Rotate(genRecipient(t), keyringEpoch.Add(time.Hour)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if bytes.Equal(k.KeyID(), oldID) {
+		t.Fatal("Rotate did not change the current key")
+	}
+	if got, err := k.Open(oldID, oldSealed, aad, testInfo); err != nil || string(got) != "before" {
+		t.Fatalf("Open with the retained key = %q, %v", got, err)
+	}
+
+	newID, newSealed, err := k.Seal([]byte("after"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if bytes.Equal(newID, oldID) {
+		t.Error("Seal after Rotate still names the old key")
+	}
+	if got, err := k.Open(newID, newSealed, aad, testInfo); err != nil || string(got) != "after" {
+		t.Fatalf("Open with the current key = %q, %v", got, err)
+	}
+	if n := len(k.Retained()); n != 1 {
+		t.Errorf("Retained() has %d keys, want 1", n)
+	}
+}
+
+// Prune drops a retained key exactly when its overlap ends, after which
+// values sealed to it fail with ErrUnknownSealingKey.
+func TestKeyring_PruneEndsOverlap(t *testing.T) {
+	k := newTestKeyring(t)
+	aad := []byte("aad")
+	oldID, oldSealed, err := k.Seal([]byte("before"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	until := keyringEpoch.Add(time.Hour)
+	if err := k.Rotate(genRecipient(t), until); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	if n := k.Prune(until.Add(-time.Nanosecond)); n != 0 {
+		t.Fatalf("Prune before the overlap ends dropped %d keys", n)
+	}
+	if _, err := k.Open(oldID, oldSealed, aad, testInfo); err != nil {
+		t.Fatalf("Open inside the overlap: %v", err)
+	}
+	if n := k.Prune(until); n != 1 {
+		t.Fatalf("Prune at the end of the overlap dropped %d keys, want 1", n)
+	}
+	if _, err := k.Open(oldID, oldSealed, aad, testInfo); !errors.Is(err, ErrUnknownSealingKey) {
+		t.Fatalf("Open after Prune = %v, want ErrUnknownSealingKey", err)
+	}
+}
+
+// A value relabelled with another held key's id names the wrong key and must
+// fail authentication rather than open under it.
+func TestKeyring_OpenRejectsRelabelledKeyID(t *testing.T) {
+	k := newTestKeyring(t)
+	aad := []byte("aad")
+	_, sealed, err := k.Seal([]byte("s3cret"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if err := k.Rotate(genRecipient(t), keyringEpoch.Add(time.Hour)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	got, err := k.Open(k.KeyID(), sealed, aad, testInfo)
+	if err == nil || errors.Is(err, ErrUnknownSealingKey) {
+		t.Fatalf("Open under a relabelled key id = %v, want an authentication failure", err)
+	}
+	if got != nil {
+		t.Errorf("failed Open returned plaintext %q", got)
+	}
+}
+
+func TestKeyring_OpenUnknownKeyID(t *testing.T) {
+	k := newTestKeyring(t)
+	other := newTestKeyring(t)
+	keyID, sealed, err := other.Seal([]byte("s3cret"), []byte("aad"), testInfo)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if _, err := k.Open(keyID, sealed, []byte("aad"), testInfo); !errors.Is(err, ErrUnknownSealingKey) {
+		t.Fatalf("Open = %v, want ErrUnknownSealingKey", err)
+	}
+}
+
+// A sender that predates key ids sends none; the value still opens, with
+// whichever key it was sealed to, including one retained after a rotation.
+func TestKeyring_OpenWithoutKeyID(t *testing.T) {
+	k := newTestKeyring(t)
+	aad := []byte("aad")
+	old, err := SealToPublicKey(k.PublicKey(), []byte("before"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("SealToPublicKey: %v", err)
+	}
+	if err := k.Rotate(genRecipient(t), keyringEpoch.Add(time.Hour)); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	cur, err := SealToPublicKey(k.PublicKey(), []byte("after"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("SealToPublicKey: %v", err)
+	}
+
+	for sealed, want := range map[string]string{string(old): "before", string(cur): "after"} {
+		got, err := k.Open(nil, []byte(sealed), aad, testInfo)
+		if err != nil || string(got) != want {
+			t.Errorf("Open(no key id) = %q, %v; want %q", got, err, want)
+		}
+	}
+
+	other, err := SealToPublicKey(genRecipient(t).PublicKey(), []byte("x"), aad, testInfo)
+	if err != nil {
+		t.Fatalf("SealToPublicKey: %v", err)
+	}
+	if got, err := k.Open(nil, other, aad, testInfo); err == nil || errors.Is(err, ErrUnknownSealingKey) || got != nil {
+		t.Errorf("Open(no key id) of a foreign value = %q, %v; want an authentication failure", got, err)
+	}
+}
+
+// Holding the same key twice would make Prune of one copy silently keep the
+// other, so NewKeyring and Rotate refuse it.
+func TestKeyring_RejectsDuplicateKeys(t *testing.T) {
+	current := genRecipient(t)
+	if _, err := NewKeyring(current, RetainedKey{Key: current, Until: keyringEpoch}); err == nil {
+		t.Error("NewKeyring accepted the current key as a retained key")
+	}
+	if _, err := NewKeyring(nil); err == nil {
+		t.Error("NewKeyring accepted a nil current key")
+	}
+
+	k, err := NewKeyring(current)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	if err := k.Rotate(current, keyringEpoch); err == nil {
+		t.Error("Rotate accepted the current key as the next key")
+	}
+	if err := k.Rotate(nil, keyringEpoch); err == nil {
+		t.Error("Rotate accepted a nil key")
+	}
+}
@@ -0,0 +1,310 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Streaming seal: the SealToPublicKey construction for payloads too large to
+// hold in memory or to fit SealedValue (secret files: keytabs, certificate
+// bundles, license files). The plaintext is cut into fixed-size segments, each
+// sealed on its own under a STREAM-style nonce (Hoang et al., "Online
+// Authenticated-Encryption and its Nonce-Reuse Misuse-Resistance"):
+//
+//	key      = HKDF-SHA256(X25519(eph, recipient), salt=eph.pub||recipient.pub, info||streamInfoSuffix)
+//	nonce_i  = 0(7) || i(4, big endian) || last(1)
+//	stream   = version(1) || eph.pub(32) || seg_0 || ... || seg_n
+//	seg_i    = AES-256-GCM(key, nonce_i, plaintext_i, aad)   [StreamSegmentSize+16, the last may be shorter]
+//
+// The key is fresh per stream (a fresh ephemeral key), so the counter alone
+// keeps nonces unique. The counter binds every segment to its position, so
+// reordering, dropping or duplicating a segment fails authentication; the
+// last flag marks the final segment, so cutting the stream at a segment
+// boundary, or appending to it, fails too. The suffix on info keeps stream
+// keys apart from whole-value seal keys under the same info.
+
+const (
+	// StreamSegmentSize is the plaintext size of every segment but the last.
+	StreamSegmentSize = 64 << 10
+	// StreamHeaderLen is the length of the stream header: the version byte and
+	// the ephemeral public key.
+	StreamHeaderLen = 1 + x25519KeyLen
+
+	streamVersion    = 1
+	streamInfoSuffix = "|stream:v1"
+	streamTagLen     = 16
+	// streamNoncePrefixLen is the zero prefix ahead of the counter and flag.
+	streamNoncePrefixLen = 7
+)
+
+// ErrStreamTruncated is returned when a sealed stream ends before its final
+// segment: a cut at a segment boundary, or a stream with no segments at all.
+var ErrStreamTruncated = errors.New("crypto: sealed stream is truncated")
+
+// SealStreamToPublicKey returns a writer that seals everything written to it
+// to recipient and writes the sealed stream to dst. Close seals the final
+// segment; a stream that is never closed does not open. Close does not close
+// dst. Like SealToPublicKey, it requires a non-empty aad — FieldSealContext
+// output for a classified field — and a non-empty info.
+func SealStreamToPublicKey(dst io.Writer, recipient *ecdh.PublicKey, aad []byte, info string) (io.WriteCloser, error) {
+	return sealStream(dst, recipient, aad, info, StreamSegmentSize)
+}
+
+// OpenStreamWithPrivateKey returns a reader of the plaintext of the sealed
+// stream read from src. Every segment is authenticated before any of its
+// plaintext is returned, but a stream is only known to be complete when Read
+// returns io.EOF: a truncated or tampered stream fails part-way, after the
+// earlier segments were delivered. Write the plaintext somewhere provisional
+// and commit it only at io.EOF.
+func OpenStreamWithPrivateKey(src io.Reader, priv *ecdh.PrivateKey, aad []byte, info string) (io.Reader, error) {
+	return openStream(src, priv, aad, info, StreamSegmentSize)
+}
+
+// SealStream is SealStreamToPublicKey to the keyring's current key, returning
+// the key id to send alongside the stream.
+func (k *Keyring) SealStream(dst io.Writer, aad []byte, info string) (keyID []byte, w io.WriteCloser, err error) {
+	pub := k.PublicKey()
+	w, err = SealStreamToPublicKey(dst, pub, aad, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return SealingKeyID(pub), w, nil
+}
+
+// OpenStream is OpenStreamWithPrivateKey with the key keyID names. It returns
+// ErrUnknownSealingKey when the keyring does not hold that key. A stream
+// cannot be retried with each key the way Open does, so an empty keyID opens
+// with the current key.
+func (k *Keyring) OpenStream(keyID []byte, src io.Reader, aad []byte, info string) (io.Reader, error) {
+	var priv *ecdh.PrivateKey
+	if len(keyID) == 0 {
+		k.mu.RLock()
+		priv = k.current
+		k.mu.RUnlock()
+	} else {
+		priv = k.lookup(keyID)
+	}
+	if priv == nil {
+		return nil, fmt.Errorf("%w: key id %x", ErrUnknownSealingKey, keyID)
+	}
+	return OpenStreamWithPrivateKey(src, priv, aad, info)
+}
+
+type streamWriter struct {
+	dst  io.Writer
+	aead cipher.AEAD
+	// aad is the caller's aad, cloned so reusing that buffer cannot change
+	// the AAD of segments not yet sealed.
+	aad     []byte
+	buf     []byte
+	segment int
+	counter uint32
+	err     error
+}
+
+func sealStream(dst io.Writer, recipient *ecdh.PublicKey, aad []byte, info string, segment int) (*streamWriter, error) {
+	if recipient == nil {
+		return nil, errors.New("crypto: nil recipient public key")
+	}
+	if len(aad) == 0 {
+		return nil, ErrAADRequired
+	}
+	if info == "" {
+		return nil, ErrInfoRequired
+	}
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: generate ephemeral key: %w", err)
+	}
+	key, err := deriveSealKey(eph, recipient, info+streamInfoSuffix)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte{streamVersion}, eph.PublicKey().Bytes()...)
+	if _, err := dst.Write(header); err != nil {
+		return nil, fmt.Errorf("crypto: write stream header: %w", err)
+	}
+	return &streamWriter{
+		dst:     dst,
+		aead:    aead,
+		aad:     bytes.Clone(aad),
+		buf:     make([]byte, 0, segment),
+		segment: segment,
+	}, nil
+}
+
+// Write buffers p and seals every segment known not to be the last: a full
+// segment is only sealed once more plaintext follows it.
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(p) > 0 {
+		if len(w.buf) == w.segment {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):w.segment], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close seals the buffered plaintext as the final segment. Later writes and
+// closes fail.
+func (w *streamWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.flush(true); err != nil {
+		return err
+	}
+	w.err = errors.New("crypto: sealed stream is closed")
+	return nil
+}
+
+func (w *streamWriter) flush(last bool) error {
+	nonce, err := streamNonce(w.counter, last)
+	if err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.dst.Write(w.aead.Seal(nil, nonce, w.buf, w.aad)); err != nil {
+		w.err = fmt.Errorf("crypto: write stream segment: %w", err)
+		return w.err
+	}
+	w.counter++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+type streamReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	aad     []byte
+	sealed  int
+	counter uint32
+	out     []byte
+	done    bool
+	err     error
+}
+
+func openStream(src io.Reader, priv *ecdh.PrivateKey, aad []byte, info string, segment int) (*streamReader, error) {
+	if priv == nil {
+		return nil, errors.New("crypto: nil private key")
+	}
+	if len(aad) == 0 {
+		return nil, ErrAADRequired
+	}
+	if info == "" {
+		return nil, ErrInfoRequired
+	}
+	header := make([]byte, StreamHeaderLen)
+	if _, err := io.ReadFull(src, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrStreamTruncated
+		}
+		return nil, fmt.Errorf("crypto: read stream header: %w", err)
+	}
+	if header[0] != streamVersion {
+		return nil, fmt.Errorf("crypto: unsupported sealed stream version %d", header[0])
+	}
+	ephPub, err := ecdh.X25519().NewPublicKey(header[1:])
+	if err != nil {
+		return nil, fmt.Errorf("crypto: parse ephemeral public key: %w", err)
+	}
+	key, err := deriveOpenKey(priv, ephPub, info+streamInfoSuffix)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed := segment + streamTagLen
+	// One byte past a full segment is how the reader tells a full final
+	// segment from one with more to follow.
+	return &streamReader{
+		src:    bufio.NewReaderSize(src, sealed+1),
+		aead:   aead,
+		aad:    bytes.Clone(aad),
+		sealed: sealed,
+	}, nil
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// next reads, authenticates and decrypts one segment into out.
+func (r *streamReader) next() error {
+	peek, err := r.src.Peek(r.sealed + 1)
+	last := false
+	switch {
+	case err == nil:
+		peek = peek[:r.sealed]
+	case errors.Is(err, io.EOF):
+		last = true
+	default:
+		return fmt.Errorf("crypto: read stream segment: %w", err)
+	}
+	if last && len(peek) < streamTagLen {
+		return ErrStreamTruncated
+	}
+	nonce, err := streamNonce(r.counter, last)
+	if err != nil {
+		return err
+	}
+	// A cut at a segment boundary leaves a non-final segment at the end; it
+	// was sealed with last unset, so opening it as the final one fails.
+	pt, err := r.aead.Open(nil, nonce, peek, r.aad)
+	if err != nil {
+		return fmt.Errorf("crypto: open stream segment %d: %w", r.counter, err)
+	}
+	if _, err := r.src.Discard(len(peek)); err != nil {
+		return fmt.Errorf("crypto: read stream segment: %w", err)
+	}
+	r.counter++
+	r.out = pt
+	r.done = last
+	return nil
+}
+
+func streamNonce(counter uint32, last bool) ([]byte, error) {
+	if counter == math.MaxUint32 && !last {
+		return nil, errors.New("crypto: sealed stream exceeds the segment limit")
+	}
+	nonce := make([]byte, streamNoncePrefixLen+5)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixLen:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce, nil
+}
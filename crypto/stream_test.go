@@ -0,0 +1,226 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/manchtools/power-manage-sdk/cryptotest"
+)
+
+// testSegment keeps multi-segment streams small; the construction does not
+// depend on the segment size.
+const testSegment = 64
+
+func sealTestStream(t *testing.T, recipient *ecdh.PublicKey, plaintext, aad []byte, segment int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := sealStream(&buf, recipient, aad, testInfo, segment)
+	if err != nil {
+		t.Fatalf("sealStream: %v", err)
+	}
+	// Odd-sized writes cross segment boundaries at every offset.
+	for rest := plaintext; len(rest) > 0; {
+		n := min(len(rest), 7)
+		if _, err := w.Write(rest[:n]); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		rest = rest[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func openTestStream(priv *ecdh.PrivateKey, sealed, aad []byte, info string, segment int) ([]byte, error) {
+	r, err := openStream(bytes.NewReader(sealed), priv, aad, info, segment)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(iotest.OneByteReader(r))
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("rand: %v", err)
+	}
+	return b
+}
+
+// Every length around a segment boundary round-trips, including the empty
+// stream and a stream ending on a full final segment.
+func TestSealStream_RoundTrip(t *testing.T) {
+	priv := genRecipient(t)
+	aad := []byte("device|action|file")
+	for _, n := range []int{0, 1, testSegment - 1, testSegment, testSegment + 1, 3 * testSegment, 3*testSegment + 5} {
+		plaintext := randomBytes(t, n)
+		sealed := sealTestStream(t, priv.PublicKey(), plaintext, aad, testSegment)
+		segments := max(1, (n+testSegment-1)/testSegment)
+		if want := StreamHeaderLen + n + segments*streamTagLen; len(sealed) != want {
+			t.Errorf("%d bytes: sealed length = %d, want %d", n, len(sealed), want)
+		}
+		got, err := openTestStream(priv, sealed, aad, testInfo, testSegment)
+		if err != nil {
+			t.Fatalf("%d bytes: open: %v", n, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("%d bytes: round-trip mismatch", n)
+		}
+	}
+}
+
+// The exported API with the real segment size, across several segments.
+func TestSealStreamToPublicKey_RoundTrip(t *testing.T) {
+	priv := genRecipient(t)
+	aad := []byte("device|action|file")
+	plaintext := randomBytes(t, 2*StreamSegmentSize+123)
+
+	var buf bytes.Buffer
+	w, err := SealStreamToPublicKey(&buf, priv.PublicKey(), aad, testInfo)
+	if err != nil {
+		t.Fatalf("SealStreamToPublicKey: %v", err)
+	}
+	if _, err := io.Copy(w, bytes.NewReader(plaintext)); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := w.Write([]byte("x")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+
+	r, err := OpenStreamWithPrivateKey(&buf, priv, aad, testInfo)
+	if err != nil {
+		t.Fatalf("OpenStreamWithPrivateKey: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Error("round-trip mismatch")
+	}
+}
+
+// Truncation at and inside segment boundaries, reordering, duplication,
+// extension and bit flips all fail to open.
+func TestOpenStream_RejectsTamper(t *testing.T) {
+	priv := genRecipient(t)
+	aad := []byte("device|action|file")
+	sealed := sealTestStream(t, priv.PublicKey(), randomBytes(t, 3*testSegment+10), aad, testSegment)
+
+	layout := cryptotest.StreamLayout{HeaderLen: StreamHeaderLen, SegmentLen: testSegment + streamTagLen}
+	cryptotest.RequireStreamTamperRejected(t, sealed, layout, func(tampered []byte) error {
+		_, err := openTestStream(priv, tampered, aad, testInfo, testSegment)
+		return err
+	})
+
+	// A stream ending on a full final segment is the case where the final
+	// flag alone tells it apart from a cut one.
+	full := sealTestStream(t, priv.PublicKey(), randomBytes(t, 3*testSegment), aad, testSegment)
+	cryptotest.RequireStreamTamperRejected(t, full, layout, func(tampered []byte) error {
+		_, err := openTestStream(priv, tampered, aad, testInfo, testSegment)
+		return err
+	})
+}
+
+func TestOpenStream_TruncatedReportsErrStreamTruncated(t *testing.T) {
+	priv := genRecipient(t)
+	aad := []byte("aad")
+	sealed := sealTestStream(t, priv.PublicKey(), []byte("s3cret"), aad, testSegment)
+
+	for _, cut := range []int{0, StreamHeaderLen - 1, StreamHeaderLen, StreamHeaderLen + streamTagLen - 1} {
+		if _, err := openTestStream(priv, sealed[:cut], aad, testInfo, testSegment); !errors.Is(err, ErrStreamTruncated) {
+			t.Errorf("cut at %d: err = %v, want ErrStreamTruncated", cut, err)
+		}
+	}
+}
+
+// A stream opens only under the key, aad and info it was sealed with, and a
+// whole-value seal under the same info derives a different key.
+func TestOpenStream_RejectsContextMismatch(t *testing.T) {
+	priv := genRecipient(t)
+	aad := []byte("device|action|file")
+	sealed := sealTestStream(t, priv.PublicKey(), []byte("s3cret"), aad, testSegment)
+
+	if _, err := openTestStream(genRecipient(t), sealed, aad, testInfo, testSegment); err == nil {
+		t.Error("opened under the wrong private key")
+	}
+	if _, err := openTestStream(priv, sealed, []byte("device|action|other"), testInfo, testSegment); err == nil {
+		t.Error("opened under the wrong aad")
+	}
+	if _, err := openTestStream(priv, sealed, aad, "power-manage-other:v1", testSegment); err == nil {
+		t.Error("opened under the wrong info")
+	}
+
+	ephPub, err := ecdh.X25519().NewPublicKey(sealed[1:StreamHeaderLen])
+	if err != nil {
+		t.Fatalf("parse ephemeral key: %v", err)
+	}
+	streamKey, err := deriveOpenKey(priv, ephPub, testInfo+streamInfoSuffix)
+	if err != nil {
+		t.Fatalf("derive stream key: %v", err)
+	}
+	sealKey, err := deriveOpenKey(priv, ephPub, testInfo)
+	if err != nil {
+		t.Fatalf("derive seal key: %v", err)
+	}
+	if bytes.Equal(streamKey, sealKey) {
+		t.Error("stream and whole-value seal derive the same key under one info")
+	}
+}
+
+func TestSealStream_RequiresContext(t *testing.T) {
+	priv := genRecipient(t)
+	if _, err := SealStreamToPublicKey(io.Discard, priv.PublicKey(), nil, testInfo); !errors.Is(err, ErrAADRequired) {
+		t.Errorf("empty aad: err = %v, want ErrAADRequired", err)
+	}
+	if _, err := SealStreamToPublicKey(io.Discard, priv.PublicKey(), []byte("aad"), ""); !errors.Is(err, ErrInfoRequired) {
+		t.Errorf("empty info: err = %v, want ErrInfoRequired", err)
+	}
+	if _, err := SealStreamToPublicKey(io.Discard, nil, []byte("aad"), testInfo); err == nil {
+		t.Error("nil recipient accepted")
+	}
+	if _, err := OpenStreamWithPrivateKey(bytes.NewReader(nil), priv, nil, testInfo); !errors.Is(err, ErrAADRequired) {
+		t.Errorf("open with empty aad: err = %v, want ErrAADRequired", err)
+	}
+}
+
+func TestKeyring_StreamRoundTrip(t *testing.T) {
+	k := newTestKeyring(t)
+	aad := []byte("aad")
+
+	var buf bytes.Buffer
+	keyID, w, err := k.SealStream(&buf, aad, testInfo)
+	if err != nil {
+		t.Fatalf("SealStream: %v", err)
+	}
+	if _, err := w.Write([]byte("s3cret")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := k.Rotate(genRecipient(t), keyringEpoch); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	r, err := k.OpenStream(keyID, bytes.NewReader(buf.Bytes()), aad, testInfo)
+	if err != nil {
+		t.Fatalf("OpenStream: %v", err)
+	}
+	if got, err := io.ReadAll(r); err != nil || string(got) != "s3cret" {
+		t.Fatalf("ReadAll = %q, %v", got, err)
+	}
+	if _, err := k.OpenStream(SealingKeyID(genRecipient(t).PublicKey()), bytes.NewReader(buf.Bytes()), aad, testInfo); !errors.Is(err, ErrUnknownSealingKey) {
+		t.Errorf("OpenStream with an unknown key id = %v, want ErrUnknownSealingKey", err)
+	}
+}
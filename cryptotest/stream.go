@@ -0,0 +1,93 @@
+package cryptotest
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// StreamLayout describes a segmented sealed stream: a fixed-length header
+// followed by sealed segments of SegmentLen bytes, the last of which may be
+// shorter. For crypto.SealStreamToPublicKey, HeaderLen is
+// crypto.StreamHeaderLen and SegmentLen is crypto.StreamSegmentSize plus the
+// 16-byte tag.
+type StreamLayout struct {
+	HeaderLen  int
+	SegmentLen int
+}
+
+// StreamTampering is one hostile rewrite of a valid sealed stream.
+type StreamTampering struct {
+	Name   string
+	Sealed []byte
+}
+
+// StreamTamperings returns every truncation, reordering, duplication,
+// extension and bit-flip of sealed that a segmented AEAD must reject. sealed
+// must hold at least three segments so that dropping and swapping reach a
+// middle segment as well as the ends.
+func StreamTamperings(t testing.TB, sealed []byte, layout StreamLayout) []StreamTampering {
+	t.Helper()
+	header, segments := splitStream(sealed, layout)
+	if len(segments) < 3 {
+		t.Fatalf("sealed stream has %d segments, want at least 3", len(segments))
+	}
+	join := func(segs ...[]byte) []byte {
+		return bytes.Join(append([][]byte{header}, segs...), nil)
+	}
+	without := func(i int) [][]byte {
+		out := append([][]byte(nil), segments[:i]...)
+		return append(out, segments[i+1:]...)
+	}
+	last := len(segments) - 1
+
+	var out []StreamTampering
+	add := func(name string, b []byte) { out = append(out, StreamTampering{Name: name, Sealed: b}) }
+
+	add("empty", nil)
+	add("header only", bytes.Clone(header))
+	add("short header", bytes.Clone(header[:len(header)-1]))
+	for i := 1; i <= last; i++ {
+		add(fmt.Sprintf("cut after segment %d", i-1), join(segments[:i]...))
+	}
+	add("cut inside the final segment", sealed[:len(sealed)-1])
+	for i := range segments {
+		add(fmt.Sprintf("drop segment %d", i), join(without(i)...))
+	}
+	swapped := append([][]byte(nil), segments...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	add("swap segments 0 and 1", join(swapped...))
+	swapped = append([][]byte(nil), segments...)
+	swapped[last-1], swapped[last] = swapped[last], swapped[last-1]
+	add("swap the final segment forward", join(swapped...))
+	add("duplicate segment 0", join(append([][]byte{segments[0]}, segments...)...))
+	add("repeat the final segment", join(append(append([][]byte(nil), segments...), segments[last])...))
+	add("trailing byte", append(bytes.Clone(sealed), 0))
+	for _, at := range []int{0, len(header) - 1, len(header), len(header) + layout.SegmentLen, len(sealed) - 1} {
+		flipped := bytes.Clone(sealed)
+		flipped[at] ^= 0x01
+		add(fmt.Sprintf("flip byte %d", at), flipped)
+	}
+	return out
+}
+
+// RequireStreamTamperRejected opens every StreamTamperings rewrite of sealed
+// with open and fails the test for each one that opens. open must read the
+// stream to its end and return any error it met on the way.
+func RequireStreamTamperRejected(t testing.TB, sealed []byte, layout StreamLayout, open func(sealed []byte) error) {
+	t.Helper()
+	for _, tc := range StreamTamperings(t, sealed, layout) {
+		if err := open(tc.Sealed); err == nil {
+			t.Errorf("%s: tampered stream opened", tc.Name)
+		}
+	}
+}
+
+func splitStream(sealed []byte, layout StreamLayout) (header []byte, segments [][]byte) {
+	header, rest := sealed[:layout.HeaderLen], sealed[layout.HeaderLen:]
+	for len(rest) > layout.SegmentLen {
+		segments = append(segments, rest[:layout.SegmentLen])
+		rest = rest[layout.SegmentLen:]
+	}
+	return header, append(segments, rest)
+}
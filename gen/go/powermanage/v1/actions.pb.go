@@ -53,7 +53,7 @@ const (
 	ActionType_ACTION_TYPE_SSH  ActionType = 700 // SSH access configuration
 	ActionType_ACTION_TYPE_SSHD ActionType = 701 // SSH daemon configuration
 	// Privilege management (800-899)
-	ActionType_ACTION_TYPE_ADMIN_POLICY ActionType = 800 // Administrative privilege policy (sudoers or doas)
+	ActionType_ACTION_TYPE_ADMIN_POLICY ActionType = 800 // Administrative privilege policy (sudoers, doas or polkit)
 	// Password management (900-999)
 	ActionType_ACTION_TYPE_LPS ActionType = 900 // Local Password Solution
 	// Encryption management (1000-1099)
@@ -201,13 +201,16 @@ func (ServiceUnitState) EnumDescriptor() ([]byte, []int) {
 
 // AdminAccessLevel defines the level of administrative access granted.
 // The server renders FULL/LIMITED into the concrete policy file format
-// for the selected PrivilegeBackend (sudoers or doas); CUSTOM carries
-// raw admin-authored config that must be valid syntax for the chosen
-// backend. TERMINAL_ADMIN_LIMITED and TERMINAL_ADMIN_FULL are used by
-// the server's TerminalAdmin reconciler — they route the agent to two
-// passwordless templates designed for pm-tty-* accounts (which have
-// no password to prompt for). Operator-authored AdminPolicy actions
-// should continue to use FULL/LIMITED/CUSTOM.
+// for the selected PrivilegeBackend (sudoers, doas or a polkit rule);
+// CUSTOM carries raw admin-authored config that must be valid syntax for
+// the chosen backend. The LIMITED levels are not available under RUN0:
+// polkit authorizes run0 to start a unit and never sees the command, so a
+// rule cannot limit which commands run. TERMINAL_ADMIN_LIMITED and
+// TERMINAL_ADMIN_FULL are used by the server's TerminalAdmin reconciler —
+// they route the agent to two passwordless templates designed for
+// pm-tty-* accounts (which have no password to prompt for).
+// Operator-authored AdminPolicy actions should continue to use
+// FULL/LIMITED/CUSTOM.
 type AdminAccessLevel int32
 
 const (
@@ -268,13 +271,15 @@ func (AdminAccessLevel) EnumDescriptor() ([]byte, []int) {
 
 // PrivilegeBackend selects which privilege-escalation tool the agent
 // uses, both for its own operations and for rendering admin policies.
-// The agent reads its configured backend at startup and passes it to
-// the SDK via exec.SetPrivilegeBackend.
+// The agent reads its configured backend at startup and builds its
+// exec.Runner with the matching exec.PrivilegeBackend.
 type PrivilegeBackend int32
 
 const (
-	PrivilegeBackend_PRIVILEGE_BACKEND_SUDO PrivilegeBackend = 0 // Default. Drops files into /etc/sudoers.d/.
-	PrivilegeBackend_PRIVILEGE_BACKEND_DOAS PrivilegeBackend = 1 // Drops files into /etc/doas.d/.
+	PrivilegeBackend_PRIVILEGE_BACKEND_SUDO   PrivilegeBackend = 0 // Default. Drops files into /etc/sudoers.d/.
+	PrivilegeBackend_PRIVILEGE_BACKEND_DOAS   PrivilegeBackend = 1 // Drops files into /etc/doas.d/.
+	PrivilegeBackend_PRIVILEGE_BACKEND_RUN0   PrivilegeBackend = 2 // systemd run0, authorized by polkit. Drops rules into /etc/polkit-1/rules.d/.
+	PrivilegeBackend_PRIVILEGE_BACKEND_PKEXEC PrivilegeBackend = 3 // pkexec, authorized by polkit. Drops rules into /etc/polkit-1/rules.d/.
 )
 
 // Enum value maps for PrivilegeBackend.
@@ -282,10 +287,14 @@ var (
 	PrivilegeBackend_name = map[int32]string{
 		0: "PRIVILEGE_BACKEND_SUDO",
 		1: "PRIVILEGE_BACKEND_DOAS",
+		2: "PRIVILEGE_BACKEND_RUN0",
+		3: "PRIVILEGE_BACKEND_PKEXEC",
 	}
 	PrivilegeBackend_value = map[string]int32{
-		"PRIVILEGE_BACKEND_SUDO": 0,
-		"PRIVILEGE_BACKEND_DOAS": 1,
+		"PRIVILEGE_BACKEND_SUDO":   0,
+		"PRIVILEGE_BACKEND_DOAS":   1,
+		"PRIVILEGE_BACKEND_RUN0":   2,
+		"PRIVILEGE_BACKEND_PKEXEC": 3,
 	}
 )
 
@@ -1132,7 +1141,13 @@ type ShellParams struct {
 	DetectionScript string `protobuf:"bytes,6,opt,name=detection_script,json=detectionScript,proto3" json:"detection_script,omitempty" validate:"omitempty,max=1048576"`
 	// When true, only the detection script runs (no remediation) and results are tracked as compliance checks
 	// @gotags: validate:"omitempty"
-	IsCompliance  bool `protobuf:"varint,7,opt,name=is_compliance,json=isCompliance,proto3" json:"is_compliance,omitempty" validate:"omitempty"`
+	IsCompliance bool `protobuf:"varint,7,opt,name=is_compliance,json=isCompliance,proto3" json:"is_compliance,omitempty" validate:"omitempty"`
+	// Runs the detection and execution scripts in a sandbox: a read-only root
+	// with only the declared writable paths, private namespaces, no network
+	// unless requested and a syscall deny-list. Unset runs them unconfined.
+	// Incompatible with run_as_root.
+	// @gotags: validate:"omitempty"
+	Sandbox       *ShellSandbox `protobuf:"bytes,8,opt,name=sandbox,proto3" json:"sandbox,omitempty" validate:"omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1216,6 +1231,13 @@ func (x *ShellParams) GetIsCompliance() bool {
 	return false
 }
 
+func (x *ShellParams) GetSandbox() *ShellSandbox {
+	if x != nil {
+		return x.Sandbox
+	}
+	return nil
+}
+
 // ServiceParams configures a systemd unit. unit_content is the verbatim unit
 // file written under /etc/systemd/system.
 type ServiceParams struct {
@@ -1307,7 +1329,14 @@ type FileParams struct {
 	// ABSENT: removes only the content block, not the entire file.
 	// Ownership and mode are still enforced.
 	// @gotags: validate:"omitempty"
-	ManagedBlock  bool `protobuf:"varint,6,opt,name=managed_block,json=managedBlock,proto3" json:"managed_block,omitempty" validate:"omitempty"`
+	ManagedBlock bool `protobuf:"varint,6,opt,name=managed_block,json=managedBlock,proto3" json:"managed_block,omitempty" validate:"omitempty"`
+	// Template mode: content is a text/template rendered against the device's
+	// facts (hostname, OS, addresses, and the labels in Manifest.device_labels)
+	// before it is written; see fs.Render for the subset and inventory.Facts
+	// for the fact names. A fact the device lacks fails the action rather
+	// than writing an empty value. Compare drift by the rendered digest.
+	// @gotags: validate:"omitempty"
+	Template      bool `protobuf:"varint,7,opt,name=template,proto3" json:"template,omitempty" validate:"omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1384,6 +1413,13 @@ func (x *FileParams) GetManagedBlock() bool {
 	return false
 }
 
+func (x *FileParams) GetTemplate() bool {
+	if x != nil {
+		return x.Template
+	}
+	return false
+}
+
 // DirectoryParams configures directory management.
 // Creates or removes directories with optional ownership and permissions.
 type DirectoryParams struct {
@@ -2562,10 +2598,11 @@ func (x *SshdParams) GetDirectives() []*SshdDirective {
 // AdminPolicyParams configures privilege-delegation policies.
 // Under PRIVILEGE_BACKEND_SUDO the action manages /etc/sudoers.d/
 // drop-ins; under PRIVILEGE_BACKEND_DOAS it manages /etc/doas.d/
-// drop-ins. Each action creates a Linux group pm-admin-{actionId}
-// and the corresponding policy file. Users specified in the users
-// list are added to the group. When removed, the group and policy
-// file are cleaned up.
+// drop-ins; under PRIVILEGE_BACKEND_RUN0 and PRIVILEGE_BACKEND_PKEXEC it
+// manages polkit rules in /etc/polkit-1/rules.d/. Each action creates a
+// Linux group pm-admin-{actionId} and the corresponding policy file.
+// Users specified in the users list are added to the group. When
+// removed, the group and policy file are cleaned up.
 type AdminPolicyParams struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Access level determines the policy template
@@ -2575,9 +2612,10 @@ type AdminPolicyParams struct {
 	// @gotags: validate:"required,min=1,dive,min=1,max=32"
 	Users []string `protobuf:"bytes,2,rep,name=users,proto3" json:"users,omitempty" validate:"required,min=1,dive,min=1,max=32"`
 	// Raw policy content (only used when access_level is CUSTOM). Must be
-	// valid syntax for the chosen backend — sudoers grammar for SUDO and
-	// doas.conf(5) grammar for DOAS. Use {group} as placeholder for the
-	// auto-generated group name. Required when access_level is CUSTOM (3).
+	// valid syntax for the chosen backend — sudoers grammar for SUDO,
+	// doas.conf(5) grammar for DOAS and polkit(8) JavaScript rules for RUN0
+	// and PKEXEC. Use {group} as placeholder for the auto-generated group
+	// name. Required when access_level is CUSTOM (3).
 	// @gotags: validate:"required_if=AccessLevel 3,max=65536"
 	CustomConfig string `protobuf:"bytes,3,opt,name=custom_config,json=customConfig,proto3" json:"custom_config,omitempty" validate:"required_if=AccessLevel 3,max=65536"`
 	// Privilege backend. Unset means PRIVILEGE_BACKEND_SUDO.
@@ -3011,9 +3049,13 @@ type ActionResult struct {
 	// @gotags: validate:"required,ulid"
 	DeliveryId string `protobuf:"bytes,11,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty" validate:"required,ulid"`
 	// @gotags: validate:"required,ulid"
-	OccurrenceId  string `protobuf:"bytes,12,opt,name=occurrence_id,json=occurrenceId,proto3" json:"occurrence_id,omitempty" validate:"required,ulid"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	OccurrenceId string `protobuf:"bytes,12,opt,name=occurrence_id,json=occurrenceId,proto3" json:"occurrence_id,omitempty" validate:"required,ulid"`
+	// The last commands the agent ran before the action failed, oldest first.
+	// Empty for an action that succeeded.
+	// @gotags: validate:"omitempty,max=64,dive"
+	RecentCommands []*CommandRecord `protobuf:"bytes,13,rep,name=recent_commands,json=recentCommands,proto3" json:"recent_commands,omitempty" validate:"omitempty,max=64,dive"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ActionResult) Reset() {
@@ -3130,6 +3172,13 @@ func (x *ActionResult) GetOccurrenceId() string {
 	return ""
 }
 
+func (x *ActionResult) GetRecentCommands() []*CommandRecord {
+	if x != nil {
+		return x.RecentCommands
+	}
+	return nil
+}
+
 // Per-architecture binary source with checksum.
 type AgentUpdateArch struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -3301,6 +3350,184 @@ func (x *AgentUpdateParams) GetAllowRedirect() bool {
 	return false
 }
 
+// ShellSandbox confines a ShellParams script. The zero value is the strictest
+// profile: everything read-only except a private /tmp, and no network.
+type ShellSandbox struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Absolute paths the script may write to, bound read-write into the
+	// otherwise read-only root. Each must be clean and not "/"; validate.Action
+	// checks that, since the tags cannot.
+	// @gotags: validate:"omitempty,max=64,dive,required,startswith=/,max=4096"
+	WritablePaths []string `protobuf:"bytes,1,rep,name=writable_paths,json=writablePaths,proto3" json:"writable_paths,omitempty" validate:"omitempty,max=64,dive,required,startswith=/,max=4096"`
+	// Gives the script the host's network. Default false: a private network
+	// namespace with only loopback.
+	// @gotags: validate:"omitempty"
+	Network       bool `protobuf:"varint,2,opt,name=network,proto3" json:"network,omitempty" validate:"omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShellSandbox) Reset() {
+	*x = ShellSandbox{}
+	mi := &file_powermanage_v1_actions_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShellSandbox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShellSandbox) ProtoMessage() {}
+
+func (x *ShellSandbox) ProtoReflect() protoreflect.Message {
+	mi := &file_powermanage_v1_actions_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShellSandbox.ProtoReflect.Descriptor instead.
+func (*ShellSandbox) Descriptor() ([]byte, []int) {
+	return file_powermanage_v1_actions_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ShellSandbox) GetWritablePaths() []string {
+	if x != nil {
+		return x.WritablePaths
+	}
+	return nil
+}
+
+func (x *ShellSandbox) GetNetwork() bool {
+	if x != nil {
+		return x.Network
+	}
+	return false
+}
+
+// CommandRecord is one command from the agent's audit trail. Stdin is never
+// recorded, and the value of an argument that names a credential (--password,
+// token=...) is redacted.
+type CommandRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @gotags: validate:"required,max=4096"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty" validate:"required,max=4096"`
+	// @gotags: validate:"omitempty,max=256,dive,max=4096"
+	Args []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty" validate:"omitempty,max=256,dive,max=4096"`
+	// How the command was escalated: "sudo", "doas", "run0", "pkexec" or
+	// "direct" (the agent is already root). Empty for a command that ran as
+	// the agent.
+	// @gotags: validate:"omitempty,oneof=sudo doas run0 pkexec direct"
+	Escalation string `protobuf:"bytes,3,opt,name=escalation,proto3" json:"escalation,omitempty" validate:"omitempty,oneof=sudo doas run0 pkexec direct"`
+	// The account the command ran as, when it dropped to one.
+	// @gotags: validate:"omitempty,max=256"
+	User string `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty" validate:"omitempty,max=256"`
+	// @gotags: validate:"omitempty"
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty" validate:"omitempty"`
+	// @gotags: validate:"omitempty,gte=0"
+	DurationMs int64 `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty" validate:"omitempty,gte=0"`
+	// @gotags: validate:"omitempty"
+	ExitCode int32 `protobuf:"varint,7,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty" validate:"omitempty"`
+	// Why the command could not run or finish (not found, escalation denied,
+	// timed out). Empty when it ran to an exit code.
+	// @gotags: validate:"omitempty,max=4096"
+	Error         string `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty" validate:"omitempty,max=4096"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandRecord) Reset() {
+	*x = CommandRecord{}
+	mi := &file_powermanage_v1_actions_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRecord) ProtoMessage() {}
+
+func (x *CommandRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_powermanage_v1_actions_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRecord.ProtoReflect.Descriptor instead.
+func (*CommandRecord) Descriptor() ([]byte, []int) {
+	return file_powermanage_v1_actions_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CommandRecord) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *CommandRecord) GetEscalation() string {
+	if x != nil {
+		return x.Escalation
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *CommandRecord) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *CommandRecord) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *CommandRecord) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 var File_powermanage_v1_actions_proto protoreflect.FileDescriptor
 
 const file_powermanage_v1_actions_proto_rawDesc = "" +
@@ -3356,7 +3583,7 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	"\x10AppInstallParams\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12'\n" +
 	"\x0fchecksum_sha256\x18\x02 \x01(\tR\x0echecksumSha256\x12!\n" +
-	"\finstall_path\x18\x03 \x01(\tR\vinstallPath\"\xf4\x02\n" +
+	"\finstall_path\x18\x03 \x01(\tR\vinstallPath\"\xac\x03\n" +
 	"\vShellParams\x12\x16\n" +
 	"\x06script\x18\x01 \x01(\tR\x06script\x12 \n" +
 	"\vinterpreter\x18\x02 \x01(\tR\vinterpreter\x12\x1e\n" +
@@ -3364,7 +3591,8 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	"\x11working_directory\x18\x04 \x01(\tR\x10workingDirectory\x12N\n" +
 	"\venvironment\x18\x05 \x03(\v2,.powermanage.v1.ShellParams.EnvironmentEntryR\venvironment\x12)\n" +
 	"\x10detection_script\x18\x06 \x01(\tR\x0fdetectionScript\x12#\n" +
-	"\ris_compliance\x18\a \x01(\bR\fisCompliance\x1a>\n" +
+	"\ris_compliance\x18\a \x01(\bR\fisCompliance\x126\n" +
+	"\asandbox\x18\b \x01(\v2\x1c.powermanage.v1.ShellSandboxR\asandbox\x1a>\n" +
 	"\x10EnvironmentEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xae\x01\n" +
@@ -3372,7 +3600,7 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	"\tunit_name\x18\x01 \x01(\tR\bunitName\x12E\n" +
 	"\rdesired_state\x18\x02 \x01(\x0e2 .powermanage.v1.ServiceUnitStateR\fdesiredState\x12\x16\n" +
 	"\x06enable\x18\x03 \x01(\bR\x06enable\x12!\n" +
-	"\funit_content\x18\x04 \x01(\tR\vunitContent\"\x9f\x01\n" +
+	"\funit_content\x18\x04 \x01(\tR\vunitContent\"\xbb\x01\n" +
 	"\n" +
 	"FileParams\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
@@ -3380,7 +3608,8 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	"\x05owner\x18\x03 \x01(\tR\x05owner\x12\x14\n" +
 	"\x05group\x18\x04 \x01(\tR\x05group\x12\x12\n" +
 	"\x04mode\x18\x05 \x01(\tR\x04mode\x12#\n" +
-	"\rmanaged_block\x18\x06 \x01(\bR\fmanagedBlock\"\x83\x01\n" +
+	"\rmanaged_block\x18\x06 \x01(\bR\fmanagedBlock\x12\x1a\n" +
+	"\btemplate\x18\a \x01(\bR\btemplate\"\x83\x01\n" +
 	"\x0fDirectoryParams\x12\x12\n" +
 	"\x04path\x18\x01 \x01(\tR\x04path\x12\x14\n" +
 	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x14\n" +
@@ -3508,7 +3737,7 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	"\fauto_connect\x18\b \x01(\bR\vautoConnect\x12\x16\n" +
 	"\x06hidden\x18\t \x01(\bR\x06hidden\x12\x1a\n" +
 	"\bpriority\x18\n" +
-	" \x01(\x05R\bpriority\"\xf8\x04\n" +
+	" \x01(\x05R\bpriority\"\xc0\x05\n" +
 	"\fActionResult\x125\n" +
 	"\taction_id\x18\x01 \x01(\v2\x18.powermanage.v1.ActionIdR\bactionId\x127\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x1f.powermanage.v1.ExecutionStatusR\x06status\x12\x14\n" +
@@ -3524,7 +3753,8 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	" \x01(\v2\x1d.powermanage.v1.CommandOutputR\x0fdetectionOutput\x12\x1f\n" +
 	"\vdelivery_id\x18\v \x01(\tR\n" +
 	"deliveryId\x12#\n" +
-	"\roccurrence_id\x18\f \x01(\tR\foccurrenceId\x1a;\n" +
+	"\roccurrence_id\x18\f \x01(\tR\foccurrenceId\x12F\n" +
+	"\x0frecent_commands\x18\r \x03(\v2\x1d.powermanage.v1.CommandRecordR\x0erecentCommands\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"|\n" +
@@ -3537,7 +3767,23 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	"\x05amd64\x18\x01 \x01(\v2\x1f.powermanage.v1.AgentUpdateArchR\x05amd64\x125\n" +
 	"\x05arm64\x18\x02 \x01(\v2\x1f.powermanage.v1.AgentUpdateArchR\x05arm64\x12'\n" +
 	"\x0fallow_downgrade\x18\x03 \x01(\bR\x0eallowDowngrade\x12%\n" +
-	"\x0eallow_redirect\x18\x04 \x01(\bR\rallowRedirect*\xea\x04\n" +
+	"\x0eallow_redirect\x18\x04 \x01(\bR\rallowRedirect\"O\n" +
+	"\fShellSandbox\x12%\n" +
+	"\x0ewritable_paths\x18\x01 \x03(\tR\rwritablePaths\x12\x18\n" +
+	"\anetwork\x18\x02 \x01(\bR\anetwork\"\xfa\x01\n" +
+	"\rCommandRecord\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12\x1e\n" +
+	"\n" +
+	"escalation\x18\x03 \x01(\tR\n" +
+	"escalation\x12\x12\n" +
+	"\x04user\x18\x04 \x01(\tR\x04user\x129\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x03R\n" +
+	"durationMs\x12\x1b\n" +
+	"\texit_code\x18\a \x01(\x05R\bexitCode\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error*\xea\x04\n" +
 	"\n" +
 	"ActionType\x12\x1b\n" +
 	"\x17ACTION_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
@@ -3575,10 +3821,12 @@ const file_powermanage_v1_actions_proto_rawDesc = "" +
 	"\x1aADMIN_ACCESS_LEVEL_LIMITED\x10\x02\x12\x1d\n" +
 	"\x19ADMIN_ACCESS_LEVEL_CUSTOM\x10\x03\x12-\n" +
 	")ADMIN_ACCESS_LEVEL_TERMINAL_ADMIN_LIMITED\x10\x04\x12*\n" +
-	"&ADMIN_ACCESS_LEVEL_TERMINAL_ADMIN_FULL\x10\x05*J\n" +
+	"&ADMIN_ACCESS_LEVEL_TERMINAL_ADMIN_FULL\x10\x05*\x84\x01\n" +
 	"\x10PrivilegeBackend\x12\x1a\n" +
 	"\x16PRIVILEGE_BACKEND_SUDO\x10\x00\x12\x1a\n" +
-	"\x16PRIVILEGE_BACKEND_DOAS\x10\x01*\x8f\x01\n" +
+	"\x16PRIVILEGE_BACKEND_DOAS\x10\x01\x12\x1a\n" +
+	"\x16PRIVILEGE_BACKEND_RUN0\x10\x02\x12\x1c\n" +
+	"\x18PRIVILEGE_BACKEND_PKEXEC\x10\x03*\x8f\x01\n" +
 	"\x15LpsPasswordComplexity\x12'\n" +
 	"#LPS_PASSWORD_COMPLEXITY_UNSPECIFIED\x10\x00\x12(\n" +
 	"$LPS_PASSWORD_COMPLEXITY_ALPHANUMERIC\x10\x01\x12#\n" +
@@ -3605,7 +3853,7 @@ func file_powermanage_v1_actions_proto_rawDescGZIP() []byte {
 }
 
 var file_powermanage_v1_actions_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
-var file_powermanage_v1_actions_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_powermanage_v1_actions_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_powermanage_v1_actions_proto_goTypes = []any{
 	(ActionType)(0),                   // 0: powermanage.v1.ActionType
 	(ServiceUnitState)(0),             // 1: powermanage.v1.ServiceUnitState
@@ -3641,19 +3889,21 @@ var file_powermanage_v1_actions_proto_goTypes = []any{
 	(*ActionResult)(nil),              // 31: powermanage.v1.ActionResult
 	(*AgentUpdateArch)(nil),           // 32: powermanage.v1.AgentUpdateArch
 	(*AgentUpdateParams)(nil),         // 33: powermanage.v1.AgentUpdateParams
-	nil,                               // 34: powermanage.v1.ShellParams.EnvironmentEntry
-	nil,                               // 35: powermanage.v1.ActionResult.MetadataEntry
-	(*ActionId)(nil),                  // 36: powermanage.v1.ActionId
-	(DesiredState)(0),                 // 37: powermanage.v1.DesiredState
-	(*SealedValue)(nil),               // 38: powermanage.v1.SealedValue
-	(ExecutionStatus)(0),              // 39: powermanage.v1.ExecutionStatus
-	(*CommandOutput)(nil),             // 40: powermanage.v1.CommandOutput
-	(*timestamppb.Timestamp)(nil),     // 41: google.protobuf.Timestamp
+	(*ShellSandbox)(nil),              // 34: powermanage.v1.ShellSandbox
+	(*CommandRecord)(nil),             // 35: powermanage.v1.CommandRecord
+	nil,                               // 36: powermanage.v1.ShellParams.EnvironmentEntry
+	nil,                               // 37: powermanage.v1.ActionResult.MetadataEntry
+	(*ActionId)(nil),                  // 38: powermanage.v1.ActionId
+	(DesiredState)(0),                 // 39: powermanage.v1.DesiredState
+	(*SealedValue)(nil),               // 40: powermanage.v1.SealedValue
+	(ExecutionStatus)(0),              // 41: powermanage.v1.ExecutionStatus
+	(*CommandOutput)(nil),             // 42: powermanage.v1.CommandOutput
+	(*timestamppb.Timestamp)(nil),     // 43: google.protobuf.Timestamp
 }
 var file_powermanage_v1_actions_proto_depIdxs = []int32{
-	36, // 0: powermanage.v1.Action.id:type_name -> powermanage.v1.ActionId
+	38, // 0: powermanage.v1.Action.id:type_name -> powermanage.v1.ActionId
 	0,  // 1: powermanage.v1.Action.type:type_name -> powermanage.v1.ActionType
-	37, // 2: powermanage.v1.Action.desired_state:type_name -> powermanage.v1.DesiredState
+	39, // 2: powermanage.v1.Action.desired_state:type_name -> powermanage.v1.DesiredState
 	8,  // 3: powermanage.v1.Action.schedule:type_name -> powermanage.v1.ActionSchedule
 	9,  // 4: powermanage.v1.Action.package:type_name -> powermanage.v1.PackageParams
 	10, // 5: powermanage.v1.Action.app:type_name -> powermanage.v1.AppInstallParams
@@ -3673,35 +3923,38 @@ var file_powermanage_v1_actions_proto_depIdxs = []int32{
 	29, // 19: powermanage.v1.Action.encryption:type_name -> powermanage.v1.EncryptionParams
 	30, // 20: powermanage.v1.Action.wifi:type_name -> powermanage.v1.WifiParams
 	33, // 21: powermanage.v1.Action.agent_update:type_name -> powermanage.v1.AgentUpdateParams
-	34, // 22: powermanage.v1.ShellParams.environment:type_name -> powermanage.v1.ShellParams.EnvironmentEntry
-	1,  // 23: powermanage.v1.ServiceParams.desired_state:type_name -> powermanage.v1.ServiceUnitState
-	18, // 24: powermanage.v1.RepositoryParams.apt:type_name -> powermanage.v1.AptRepository
-	19, // 25: powermanage.v1.RepositoryParams.dnf:type_name -> powermanage.v1.DnfRepository
-	20, // 26: powermanage.v1.RepositoryParams.pacman:type_name -> powermanage.v1.PacmanRepository
-	21, // 27: powermanage.v1.RepositoryParams.zypper:type_name -> powermanage.v1.ZypperRepository
-	25, // 28: powermanage.v1.SshdParams.directives:type_name -> powermanage.v1.SshdDirective
-	2,  // 29: powermanage.v1.AdminPolicyParams.access_level:type_name -> powermanage.v1.AdminAccessLevel
-	3,  // 30: powermanage.v1.AdminPolicyParams.backend:type_name -> powermanage.v1.PrivilegeBackend
-	4,  // 31: powermanage.v1.LpsParams.complexity:type_name -> powermanage.v1.LpsPasswordComplexity
-	38, // 32: powermanage.v1.EncryptionParams.preshared_key:type_name -> powermanage.v1.SealedValue
-	5,  // 33: powermanage.v1.EncryptionParams.device_bound_key_type:type_name -> powermanage.v1.EncryptionDeviceBoundKeyType
-	4,  // 34: powermanage.v1.EncryptionParams.user_passphrase_complexity:type_name -> powermanage.v1.LpsPasswordComplexity
-	6,  // 35: powermanage.v1.WifiParams.auth_type:type_name -> powermanage.v1.WifiAuthType
-	38, // 36: powermanage.v1.WifiParams.psk:type_name -> powermanage.v1.SealedValue
-	38, // 37: powermanage.v1.WifiParams.client_key:type_name -> powermanage.v1.SealedValue
-	36, // 38: powermanage.v1.ActionResult.action_id:type_name -> powermanage.v1.ActionId
-	39, // 39: powermanage.v1.ActionResult.status:type_name -> powermanage.v1.ExecutionStatus
-	40, // 40: powermanage.v1.ActionResult.output:type_name -> powermanage.v1.CommandOutput
-	41, // 41: powermanage.v1.ActionResult.completed_at:type_name -> google.protobuf.Timestamp
-	35, // 42: powermanage.v1.ActionResult.metadata:type_name -> powermanage.v1.ActionResult.MetadataEntry
-	40, // 43: powermanage.v1.ActionResult.detection_output:type_name -> powermanage.v1.CommandOutput
-	32, // 44: powermanage.v1.AgentUpdateParams.amd64:type_name -> powermanage.v1.AgentUpdateArch
-	32, // 45: powermanage.v1.AgentUpdateParams.arm64:type_name -> powermanage.v1.AgentUpdateArch
-	46, // [46:46] is the sub-list for method output_type
-	46, // [46:46] is the sub-list for method input_type
-	46, // [46:46] is the sub-list for extension type_name
-	46, // [46:46] is the sub-list for extension extendee
-	0,  // [0:46] is the sub-list for field type_name
+	36, // 22: powermanage.v1.ShellParams.environment:type_name -> powermanage.v1.ShellParams.EnvironmentEntry
+	34, // 23: powermanage.v1.ShellParams.sandbox:type_name -> powermanage.v1.ShellSandbox
+	1,  // 24: powermanage.v1.ServiceParams.desired_state:type_name -> powermanage.v1.ServiceUnitState
+	18, // 25: powermanage.v1.RepositoryParams.apt:type_name -> powermanage.v1.AptRepository
+	19, // 26: powermanage.v1.RepositoryParams.dnf:type_name -> powermanage.v1.DnfRepository
+	20, // 27: powermanage.v1.RepositoryParams.pacman:type_name -> powermanage.v1.PacmanRepository
+	21, // 28: powermanage.v1.RepositoryParams.zypper:type_name -> powermanage.v1.ZypperRepository
+	25, // 29: powermanage.v1.SshdParams.directives:type_name -> powermanage.v1.SshdDirective
+	2,  // 30: powermanage.v1.AdminPolicyParams.access_level:type_name -> powermanage.v1.AdminAccessLevel
+	3,  // 31: powermanage.v1.AdminPolicyParams.backend:type_name -> powermanage.v1.PrivilegeBackend
+	4,  // 32: powermanage.v1.LpsParams.complexity:type_name -> powermanage.v1.LpsPasswordComplexity
+	40, // 33: powermanage.v1.EncryptionParams.preshared_key:type_name -> powermanage.v1.SealedValue
+	5,  // 34: powermanage.v1.EncryptionParams.device_bound_key_type:type_name -> powermanage.v1.EncryptionDeviceBoundKeyType
+	4,  // 35: powermanage.v1.EncryptionParams.user_passphrase_complexity:type_name -> powermanage.v1.LpsPasswordComplexity
+	6,  // 36: powermanage.v1.WifiParams.auth_type:type_name -> powermanage.v1.WifiAuthType
+	40, // 37: powermanage.v1.WifiParams.psk:type_name -> powermanage.v1.SealedValue
+	40, // 38: powermanage.v1.WifiParams.client_key:type_name -> powermanage.v1.SealedValue
+	38, // 39: powermanage.v1.ActionResult.action_id:type_name -> powermanage.v1.ActionId
+	41, // 40: powermanage.v1.ActionResult.status:type_name -> powermanage.v1.ExecutionStatus
+	42, // 41: powermanage.v1.ActionResult.output:type_name -> powermanage.v1.CommandOutput
+	43, // 42: powermanage.v1.ActionResult.completed_at:type_name -> google.protobuf.Timestamp
+	37, // 43: powermanage.v1.ActionResult.metadata:type_name -> powermanage.v1.ActionResult.MetadataEntry
+	42, // 44: powermanage.v1.ActionResult.detection_output:type_name -> powermanage.v1.CommandOutput
+	35, // 45: powermanage.v1.ActionResult.recent_commands:type_name -> powermanage.v1.CommandRecord
+	32, // 46: powermanage.v1.AgentUpdateParams.amd64:type_name -> powermanage.v1.AgentUpdateArch
+	32, // 47: powermanage.v1.AgentUpdateParams.arm64:type_name -> powermanage.v1.AgentUpdateArch
+	43, // 48: powermanage.v1.CommandRecord.started_at:type_name -> google.protobuf.Timestamp
+	49, // [49:49] is the sub-list for method output_type
+	49, // [49:49] is the sub-list for method input_type
+	49, // [49:49] is the sub-list for extension type_name
+	49, // [49:49] is the sub-list for extension extendee
+	0,  // [0:49] is the sub-list for field type_name
 }
 
 func init() { file_powermanage_v1_actions_proto_init() }
@@ -3736,7 +3989,7 @@ func file_powermanage_v1_actions_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_powermanage_v1_actions_proto_rawDesc), len(file_powermanage_v1_actions_proto_rawDesc)),
 			NumEnums:      7,
-			NumMessages:   29,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
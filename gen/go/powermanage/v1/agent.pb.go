@@ -84,6 +84,9 @@ const (
 	SecurityAlertType_SECURITY_ALERT_TYPE_CREDENTIAL_TAMPERING SecurityAlertType = 2
 	// Invalid certificate presented
 	SecurityAlertType_SECURITY_ALERT_TYPE_INVALID_CERTIFICATE SecurityAlertType = 3
+	// A protected file (sudoers, sshd config, ...) drifted from its integrity
+	// baseline
+	SecurityAlertType_SECURITY_ALERT_TYPE_FILE_INTEGRITY SecurityAlertType = 4
 )
 
 // Enum value maps for SecurityAlertType.
@@ -93,12 +96,14 @@ var (
 		1: "SECURITY_ALERT_TYPE_SERVER_REASSIGNMENT_ATTEMPT",
 		2: "SECURITY_ALERT_TYPE_CREDENTIAL_TAMPERING",
 		3: "SECURITY_ALERT_TYPE_INVALID_CERTIFICATE",
+		4: "SECURITY_ALERT_TYPE_FILE_INTEGRITY",
 	}
 	SecurityAlertType_value = map[string]int32{
 		"SECURITY_ALERT_TYPE_UNSPECIFIED":                 0,
 		"SECURITY_ALERT_TYPE_SERVER_REASSIGNMENT_ATTEMPT": 1,
 		"SECURITY_ALERT_TYPE_CREDENTIAL_TAMPERING":        2,
 		"SECURITY_ALERT_TYPE_INVALID_CERTIFICATE":         3,
+		"SECURITY_ALERT_TYPE_FILE_INTEGRITY":              4,
 	}
 )
 
@@ -808,9 +813,17 @@ type Hello struct {
 	// @gotags: validate:"omitempty,max=4096"
 	AuthToken string `protobuf:"bytes,4,opt,name=auth_token,json=authToken,proto3" json:"auth_token,omitempty" validate:"omitempty,max=4096"`
 	// @gotags: validate:"omitempty,max=16"
-	Arch          string `protobuf:"bytes,5,opt,name=arch,proto3" json:"arch,omitempty" validate:"omitempty,max=16"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Arch string `protobuf:"bytes,5,opt,name=arch,proto3" json:"arch,omitempty" validate:"omitempty,max=16"`
+	// The agent's current X25519 recipient public key, raw 32-byte encoding.
+	// Control seals to the key the latest Hello announced: one that differs
+	// from the pinned key means the agent has rotated, and control re-pins to
+	// it. The stream's mTLS device identity authenticates the announcement.
+	// An agent that predates sealing keys sends none; control keeps
+	// sealing to the key it registered with.
+	// @gotags: validate:"omitempty,len=32"
+	AgentSealingPublicKey []byte `protobuf:"bytes,6,opt,name=agent_sealing_public_key,json=agentSealingPublicKey,proto3" json:"agent_sealing_public_key,omitempty" validate:"omitempty,len=32"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
 }
 
 func (x *Hello) Reset() {
@@ -878,6 +891,13 @@ func (x *Hello) GetArch() string {
 	return ""
 }
 
+func (x *Hello) GetAgentSealingPublicKey() []byte {
+	if x != nil {
+		return x.AgentSealingPublicKey
+	}
+	return nil
+}
+
 type Heartbeat struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @gotags: validate:"omitempty"
@@ -1358,8 +1378,16 @@ type Welcome struct {
 	// Base URL for browser-based device login (configurable, defaults to built-in PM web app).
 	// Used by PAM module to open the OIDC login page for display manager sessions.
 	DeviceLoginUrl string `protobuf:"bytes,3,opt,name=device_login_url,json=deviceLoginUrl,proto3" json:"device_login_url,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Control's current deployment sealing public key, raw 32-byte X25519
+	// encoding. One that differs from the key the agent pinned means control
+	// has rotated: the agent re-pins to it, since it arrived on a stream the
+	// pinned CA authenticated, and seals everything after to it.
+	// A control that predates sealing keys sends none; the agent keeps the
+	// key it pinned at registration.
+	// @gotags: validate:"omitempty,len=32"
+	ControlSealingPublicKey []byte `protobuf:"bytes,4,opt,name=control_sealing_public_key,json=controlSealingPublicKey,proto3" json:"control_sealing_public_key,omitempty" validate:"omitempty,len=32"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *Welcome) Reset() {
@@ -1413,6 +1441,13 @@ func (x *Welcome) GetDeviceLoginUrl() string {
 	return ""
 }
 
+func (x *Welcome) GetControlSealingPublicKey() []byte {
+	if x != nil {
+		return x.ControlSealingPublicKey
+	}
+	return nil
+}
+
 // ManifestProvenance is the bounded, structured record of where a manifest was
 // authored from: at most one Definition, one ActionSet and one Action, in that
 // order. It is a path, never a tree — the agent does not receive a recursive
@@ -1593,7 +1628,12 @@ type Manifest struct {
 	// one-shot delivery is also exempt from the agent's maintenance window: an
 	// operator dispatching "now" means now; only scheduled work defers.
 	// @gotags: validate:"omitempty"
-	OneShot       bool `protobuf:"varint,6,opt,name=one_shot,json=oneShot,proto3" json:"one_shot,omitempty" validate:"omitempty"`
+	OneShot bool `protobuf:"varint,6,opt,name=one_shot,json=oneShot,proto3" json:"one_shot,omitempty" validate:"omitempty"`
+	// The device's labels when the manifest was compiled, for file templates
+	// that read them as .labels. Carried per manifest so a template renders
+	// against the labels its delivery was built with.
+	// @gotags: validate:"omitempty,max=256,dive,keys,min=1,max=64,endkeys,max=256"
+	DeviceLabels  map[string]string `protobuf:"bytes,7,rep,name=device_labels,json=deviceLabels,proto3" json:"device_labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value" validate:"omitempty,max=256,dive,keys,min=1,max=64,endkeys,max=256"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1670,6 +1710,13 @@ func (x *Manifest) GetOneShot() bool {
 	return false
 }
 
+func (x *Manifest) GetDeviceLabels() map[string]string {
+	if x != nil {
+		return x.DeviceLabels
+	}
+	return nil
+}
+
 // Control -> agent: one attempt to hand a complete manifest to a device.
 //
 // The delivery row, with the whole manifest in it, is committed before this
@@ -3073,7 +3120,8 @@ type SyncState struct {
 	// groups reaching it through an assignment). Empty schedule = no
 	// gating; the agent dispatches non-instant actions any time. Empty
 	// is also the response when no groups carry a window. The agent
-	// evaluates this against time.Now().Local() at dispatch time.
+	// evaluates this against time.Now().Local() at dispatch time, or in
+	// the entry's or window's time_zone when one is set.
 	MaintenanceWindow *MaintenanceWindow `protobuf:"bytes,3,opt,name=maintenance_window,json=maintenanceWindow,proto3" json:"maintenance_window,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
@@ -3744,14 +3792,15 @@ const file_powermanage_v1_agent_proto_rawDesc = "" +
 	"\fexecution_id\x18\x01 \x01(\tR\vexecutionId\x128\n" +
 	"\x06stream\x18\x02 \x01(\x0e2 .powermanage.v1.OutputStreamTypeR\x06stream\x12\x12\n" +
 	"\x04data\x18\x03 \x01(\fR\x04data\x12\x1a\n" +
-	"\bsequence\x18\x04 \x01(\x03R\bsequence\"\xb2\x01\n" +
+	"\bsequence\x18\x04 \x01(\x03R\bsequence\"\xeb\x01\n" +
 	"\x05Hello\x125\n" +
 	"\tdevice_id\x18\x01 \x01(\v2\x18.powermanage.v1.DeviceIdR\bdeviceId\x12#\n" +
 	"\ragent_version\x18\x02 \x01(\tR\fagentVersion\x12\x1a\n" +
 	"\bhostname\x18\x03 \x01(\tR\bhostname\x12\x1d\n" +
 	"\n" +
 	"auth_token\x18\x04 \x01(\tR\tauthToken\x12\x12\n" +
-	"\x04arch\x18\x05 \x01(\tR\x04arch\"\xa9\x01\n" +
+	"\x04arch\x18\x05 \x01(\tR\x04arch\x127\n" +
+	"\x18agent_sealing_public_key\x18\x06 \x01(\fR\x15agentSealingPublicKey\"\xa9\x01\n" +
 	"\tHeartbeat\x121\n" +
 	"\x06uptime\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x06uptime\x12\x1f\n" +
 	"\vcpu_percent\x18\x02 \x01(\x02R\n" +
@@ -3786,11 +3835,12 @@ const file_powermanage_v1_agent_proto_rawDesc = "" +
 	"\x0eterminal_input\x18G \x01(\v2\x1d.powermanage.v1.TerminalInputH\x00R\rterminalInput\x12I\n" +
 	"\x0fterminal_resize\x18H \x01(\v2\x1e.powermanage.v1.TerminalResizeH\x00R\x0eterminalResize\x12C\n" +
 	"\rterminal_stop\x18I \x01(\v2\x1c.powermanage.v1.TerminalStopH\x00R\fterminalStopB\t\n" +
-	"\apayload\"\xa4\x01\n" +
+	"\apayload\"\xe1\x01\n" +
 	"\aWelcome\x12%\n" +
 	"\x0eserver_version\x18\x01 \x01(\tR\rserverVersion\x12H\n" +
 	"\x12heartbeat_interval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x11heartbeatInterval\x12(\n" +
-	"\x10device_login_url\x18\x03 \x01(\tR\x0edeviceLoginUrl\"z\n" +
+	"\x10device_login_url\x18\x03 \x01(\tR\x0edeviceLoginUrl\x12;\n" +
+	"\x1acontrol_sealing_public_key\x18\x04 \x01(\fR\x17controlSealingPublicKey\"z\n" +
 	"\x12ManifestProvenance\x12#\n" +
 	"\rdefinition_id\x18\x01 \x01(\tR\fdefinitionId\x12\"\n" +
 	"\raction_set_id\x18\x02 \x01(\tR\vactionSetId\x12\x1b\n" +
@@ -3799,7 +3849,7 @@ const file_powermanage_v1_agent_proto_rawDesc = "" +
 	"\roccurrence_id\x18\x01 \x01(\tR\foccurrenceId\x12.\n" +
 	"\x06action\x18\x02 \x01(\v2\x16.powermanage.v1.ActionR\x06action\x128\n" +
 	"\n" +
-	"on_failure\x18\x03 \x01(\x0e2\x19.powermanage.v1.OnFailureR\tonFailure\"\xd5\x02\n" +
+	"on_failure\x18\x03 \x01(\x0e2\x19.powermanage.v1.OnFailureR\tonFailure\"\xe7\x03\n" +
 	"\bManifest\x12\x1f\n" +
 	"\vmanifest_id\x18\x01 \x01(\tR\n" +
 	"manifestId\x12B\n" +
@@ -3809,7 +3859,11 @@ const file_powermanage_v1_agent_proto_rawDesc = "" +
 	"\bschedule\x18\x03 \x01(\v2\x1e.powermanage.v1.ActionScheduleR\bschedule\x12G\n" +
 	"\x12default_on_failure\x18\x04 \x01(\x0e2\x19.powermanage.v1.OnFailureR\x10defaultOnFailure\x12D\n" +
 	"\voccurrences\x18\x05 \x03(\v2\".powermanage.v1.ManifestOccurrenceR\voccurrences\x12\x19\n" +
-	"\bone_shot\x18\x06 \x01(\bR\aoneShot\"i\n" +
+	"\bone_shot\x18\x06 \x01(\bR\aoneShot\x12O\n" +
+	"\rdevice_labels\x18\a \x03(\v2*.powermanage.v1.Manifest.DeviceLabelsEntryR\fdeviceLabels\x1a?\n" +
+	"\x11DeviceLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"i\n" +
 	"\x10ManifestDelivery\x12\x1f\n" +
 	"\vdelivery_id\x18\x01 \x01(\tR\n" +
 	"deliveryId\x124\n" +
@@ -3958,12 +4012,13 @@ const file_powermanage_v1_agent_proto_rawDesc = "" +
 	"\x10OutputStreamType\x12\"\n" +
 	"\x1eOUTPUT_STREAM_TYPE_UNSPECIFIED\x10\x00\x12\x1d\n" +
 	"\x19OUTPUT_STREAM_TYPE_STDOUT\x10\x01\x12\x1d\n" +
-	"\x19OUTPUT_STREAM_TYPE_STDERR\x10\x02*\xc8\x01\n" +
+	"\x19OUTPUT_STREAM_TYPE_STDERR\x10\x02*\xf0\x01\n" +
 	"\x11SecurityAlertType\x12#\n" +
 	"\x1fSECURITY_ALERT_TYPE_UNSPECIFIED\x10\x00\x123\n" +
 	"/SECURITY_ALERT_TYPE_SERVER_REASSIGNMENT_ATTEMPT\x10\x01\x12,\n" +
 	"(SECURITY_ALERT_TYPE_CREDENTIAL_TAMPERING\x10\x02\x12+\n" +
-	"'SECURITY_ALERT_TYPE_INVALID_CERTIFICATE\x10\x03*9\n" +
+	"'SECURITY_ALERT_TYPE_INVALID_CERTIFICATE\x10\x03\x12&\n" +
+	"\"SECURITY_ALERT_TYPE_FILE_INTEGRITY\x10\x04*9\n" +
 	"\tOnFailure\x12\x17\n" +
 	"\x13ON_FAILURE_CONTINUE\x10\x00\x12\x13\n" +
 	"\x0fON_FAILURE_STOP\x10\x01*\xcc\x01\n" +
@@ -4001,7 +4056,7 @@ func file_powermanage_v1_agent_proto_rawDescGZIP() []byte {
 }
 
 var file_powermanage_v1_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_powermanage_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
+var file_powermanage_v1_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 45)
 var file_powermanage_v1_agent_proto_goTypes = []any{
 	(OutputStreamType)(0),             // 0: powermanage.v1.OutputStreamType
 	(SecurityAlertType)(0),            // 1: powermanage.v1.SecurityAlertType
@@ -4052,24 +4107,25 @@ var file_powermanage_v1_agent_proto_goTypes = []any{
 	(*TerminalOutput)(nil),            // 46: powermanage.v1.TerminalOutput
 	(*TerminalStateChange)(nil),       // 47: powermanage.v1.TerminalStateChange
 	nil,                               // 48: powermanage.v1.SecurityAlert.DetailsEntry
-	nil,                               // 49: powermanage.v1.OSQueryRow.DataEntry
-	(*ActionResult)(nil),              // 50: powermanage.v1.ActionResult
-	(*DeviceId)(nil),                  // 51: powermanage.v1.DeviceId
-	(*durationpb.Duration)(nil),       // 52: google.protobuf.Duration
-	(*Action)(nil),                    // 53: powermanage.v1.Action
-	(*ActionSchedule)(nil),            // 54: powermanage.v1.ActionSchedule
-	(ExecutionStatus)(0),              // 55: powermanage.v1.ExecutionStatus
-	(*timestamppb.Timestamp)(nil),     // 56: google.protobuf.Timestamp
-	(*SealedValue)(nil),               // 57: powermanage.v1.SealedValue
-	(RotationReason)(0),               // 58: powermanage.v1.RotationReason
-	(LpsPasswordComplexity)(0),        // 59: powermanage.v1.LpsPasswordComplexity
-	(*MaintenanceWindow)(nil),         // 60: powermanage.v1.MaintenanceWindow
+	nil,                               // 49: powermanage.v1.Manifest.DeviceLabelsEntry
+	nil,                               // 50: powermanage.v1.OSQueryRow.DataEntry
+	(*ActionResult)(nil),              // 51: powermanage.v1.ActionResult
+	(*DeviceId)(nil),                  // 52: powermanage.v1.DeviceId
+	(*durationpb.Duration)(nil),       // 53: google.protobuf.Duration
+	(*Action)(nil),                    // 54: powermanage.v1.Action
+	(*ActionSchedule)(nil),            // 55: powermanage.v1.ActionSchedule
+	(ExecutionStatus)(0),              // 56: powermanage.v1.ExecutionStatus
+	(*timestamppb.Timestamp)(nil),     // 57: google.protobuf.Timestamp
+	(*SealedValue)(nil),               // 58: powermanage.v1.SealedValue
+	(RotationReason)(0),               // 59: powermanage.v1.RotationReason
+	(LpsPasswordComplexity)(0),        // 60: powermanage.v1.LpsPasswordComplexity
+	(*MaintenanceWindow)(nil),         // 61: powermanage.v1.MaintenanceWindow
 }
 var file_powermanage_v1_agent_proto_depIdxs = []int32{
 	8,  // 0: powermanage.v1.AgentMessage.hello:type_name -> powermanage.v1.Hello
 	9,  // 1: powermanage.v1.AgentMessage.heartbeat:type_name -> powermanage.v1.Heartbeat
 	38, // 2: powermanage.v1.AgentMessage.sync_request:type_name -> powermanage.v1.SyncRequest
-	50, // 3: powermanage.v1.AgentMessage.action_result:type_name -> powermanage.v1.ActionResult
+	51, // 3: powermanage.v1.AgentMessage.action_result:type_name -> powermanage.v1.ActionResult
 	7,  // 4: powermanage.v1.AgentMessage.output_chunk:type_name -> powermanage.v1.OutputChunk
 	17, // 5: powermanage.v1.AgentMessage.delivery_receipt:type_name -> powermanage.v1.DeliveryReceipt
 	18, // 6: powermanage.v1.AgentMessage.manifest_result:type_name -> powermanage.v1.ManifestResult
@@ -4085,8 +4141,8 @@ var file_powermanage_v1_agent_proto_depIdxs = []int32{
 	46, // 16: powermanage.v1.AgentMessage.terminal_output:type_name -> powermanage.v1.TerminalOutput
 	47, // 17: powermanage.v1.AgentMessage.terminal_state_change:type_name -> powermanage.v1.TerminalStateChange
 	0,  // 18: powermanage.v1.OutputChunk.stream:type_name -> powermanage.v1.OutputStreamType
-	51, // 19: powermanage.v1.Hello.device_id:type_name -> powermanage.v1.DeviceId
-	52, // 20: powermanage.v1.Heartbeat.uptime:type_name -> google.protobuf.Duration
+	52, // 19: powermanage.v1.Hello.device_id:type_name -> powermanage.v1.DeviceId
+	53, // 20: powermanage.v1.Heartbeat.uptime:type_name -> google.protobuf.Duration
 	1,  // 21: powermanage.v1.SecurityAlert.type:type_name -> powermanage.v1.SecurityAlertType
 	48, // 22: powermanage.v1.SecurityAlert.details:type_name -> powermanage.v1.SecurityAlert.DetailsEntry
 	12, // 23: powermanage.v1.ServerMessage.welcome:type_name -> powermanage.v1.Welcome
@@ -4105,40 +4161,41 @@ var file_powermanage_v1_agent_proto_depIdxs = []int32{
 	43, // 36: powermanage.v1.ServerMessage.terminal_input:type_name -> powermanage.v1.TerminalInput
 	44, // 37: powermanage.v1.ServerMessage.terminal_resize:type_name -> powermanage.v1.TerminalResize
 	45, // 38: powermanage.v1.ServerMessage.terminal_stop:type_name -> powermanage.v1.TerminalStop
-	52, // 39: powermanage.v1.Welcome.heartbeat_interval:type_name -> google.protobuf.Duration
-	53, // 40: powermanage.v1.ManifestOccurrence.action:type_name -> powermanage.v1.Action
+	53, // 39: powermanage.v1.Welcome.heartbeat_interval:type_name -> google.protobuf.Duration
+	54, // 40: powermanage.v1.ManifestOccurrence.action:type_name -> powermanage.v1.Action
 	2,  // 41: powermanage.v1.ManifestOccurrence.on_failure:type_name -> powermanage.v1.OnFailure
 	13, // 42: powermanage.v1.Manifest.provenance:type_name -> powermanage.v1.ManifestProvenance
-	54, // 43: powermanage.v1.Manifest.schedule:type_name -> powermanage.v1.ActionSchedule
+	55, // 43: powermanage.v1.Manifest.schedule:type_name -> powermanage.v1.ActionSchedule
 	2,  // 44: powermanage.v1.Manifest.default_on_failure:type_name -> powermanage.v1.OnFailure
 	14, // 45: powermanage.v1.Manifest.occurrences:type_name -> powermanage.v1.ManifestOccurrence
-	15, // 46: powermanage.v1.ManifestDelivery.manifest:type_name -> powermanage.v1.Manifest
-	55, // 47: powermanage.v1.ManifestResult.status:type_name -> powermanage.v1.ExecutionStatus
-	56, // 48: powermanage.v1.ManifestResult.completed_at:type_name -> google.protobuf.Timestamp
-	21, // 49: powermanage.v1.OSQuery.where:type_name -> powermanage.v1.OSQueryCondition
-	3,  // 50: powermanage.v1.OSQueryCondition.op:type_name -> powermanage.v1.OSQueryOp
-	23, // 51: powermanage.v1.OSQueryResult.rows:type_name -> powermanage.v1.OSQueryRow
-	49, // 52: powermanage.v1.OSQueryRow.data:type_name -> powermanage.v1.OSQueryRow.DataEntry
-	25, // 53: powermanage.v1.DeviceInventory.tables:type_name -> powermanage.v1.InventoryTable
-	23, // 54: powermanage.v1.InventoryTable.rows:type_name -> powermanage.v1.OSQueryRow
-	57, // 55: powermanage.v1.GetLuksKeyResponse.passphrase:type_name -> powermanage.v1.SealedValue
-	57, // 56: powermanage.v1.StoreLuksKeyRequest.passphrase:type_name -> powermanage.v1.SealedValue
-	58, // 57: powermanage.v1.StoreLuksKeyRequest.rotation_reason:type_name -> powermanage.v1.RotationReason
-	57, // 58: powermanage.v1.LpsPasswordRotation.password:type_name -> powermanage.v1.SealedValue
-	58, // 59: powermanage.v1.LpsPasswordRotation.reason:type_name -> powermanage.v1.RotationReason
-	31, // 60: powermanage.v1.StoreLpsPasswordsRequest.rotations:type_name -> powermanage.v1.LpsPasswordRotation
-	59, // 61: powermanage.v1.ValidateLuksTokenResponse.complexity:type_name -> powermanage.v1.LpsPasswordComplexity
-	16, // 62: powermanage.v1.SyncState.deliveries:type_name -> powermanage.v1.ManifestDelivery
-	60, // 63: powermanage.v1.SyncState.maintenance_window:type_name -> powermanage.v1.MaintenanceWindow
-	4,  // 64: powermanage.v1.LogQuery.source:type_name -> powermanage.v1.LogSource
-	5,  // 65: powermanage.v1.TerminalStateChange.state:type_name -> powermanage.v1.TerminalSessionState
-	6,  // 66: powermanage.v1.AgentService.Stream:input_type -> powermanage.v1.AgentMessage
-	11, // 67: powermanage.v1.AgentService.Stream:output_type -> powermanage.v1.ServerMessage
-	67, // [67:68] is the sub-list for method output_type
-	66, // [66:67] is the sub-list for method input_type
-	66, // [66:66] is the sub-list for extension type_name
-	66, // [66:66] is the sub-list for extension extendee
-	0,  // [0:66] is the sub-list for field type_name
+	49, // 46: powermanage.v1.Manifest.device_labels:type_name -> powermanage.v1.Manifest.DeviceLabelsEntry
+	15, // 47: powermanage.v1.ManifestDelivery.manifest:type_name -> powermanage.v1.Manifest
+	56, // 48: powermanage.v1.ManifestResult.status:type_name -> powermanage.v1.ExecutionStatus
+	57, // 49: powermanage.v1.ManifestResult.completed_at:type_name -> google.protobuf.Timestamp
+	21, // 50: powermanage.v1.OSQuery.where:type_name -> powermanage.v1.OSQueryCondition
+	3,  // 51: powermanage.v1.OSQueryCondition.op:type_name -> powermanage.v1.OSQueryOp
+	23, // 52: powermanage.v1.OSQueryResult.rows:type_name -> powermanage.v1.OSQueryRow
+	50, // 53: powermanage.v1.OSQueryRow.data:type_name -> powermanage.v1.OSQueryRow.DataEntry
+	25, // 54: powermanage.v1.DeviceInventory.tables:type_name -> powermanage.v1.InventoryTable
+	23, // 55: powermanage.v1.InventoryTable.rows:type_name -> powermanage.v1.OSQueryRow
+	58, // 56: powermanage.v1.GetLuksKeyResponse.passphrase:type_name -> powermanage.v1.SealedValue
+	58, // 57: powermanage.v1.StoreLuksKeyRequest.passphrase:type_name -> powermanage.v1.SealedValue
+	59, // 58: powermanage.v1.StoreLuksKeyRequest.rotation_reason:type_name -> powermanage.v1.RotationReason
+	58, // 59: powermanage.v1.LpsPasswordRotation.password:type_name -> powermanage.v1.SealedValue
+	59, // 60: powermanage.v1.LpsPasswordRotation.reason:type_name -> powermanage.v1.RotationReason
+	31, // 61: powermanage.v1.StoreLpsPasswordsRequest.rotations:type_name -> powermanage.v1.LpsPasswordRotation
+	60, // 62: powermanage.v1.ValidateLuksTokenResponse.complexity:type_name -> powermanage.v1.LpsPasswordComplexity
+	16, // 63: powermanage.v1.SyncState.deliveries:type_name -> powermanage.v1.ManifestDelivery
+	61, // 64: powermanage.v1.SyncState.maintenance_window:type_name -> powermanage.v1.MaintenanceWindow
+	4,  // 65: powermanage.v1.LogQuery.source:type_name -> powermanage.v1.LogSource
+	5,  // 66: powermanage.v1.TerminalStateChange.state:type_name -> powermanage.v1.TerminalSessionState
+	6,  // 67: powermanage.v1.AgentService.Stream:input_type -> powermanage.v1.AgentMessage
+	11, // 68: powermanage.v1.AgentService.Stream:output_type -> powermanage.v1.ServerMessage
+	68, // [68:69] is the sub-list for method output_type
+	67, // [67:68] is the sub-list for method input_type
+	67, // [67:67] is the sub-list for extension type_name
+	67, // [67:67] is the sub-list for extension extendee
+	0,  // [0:67] is the sub-list for field type_name
 }
 
 func init() { file_powermanage_v1_agent_proto_init() }
@@ -4192,7 +4249,7 @@ func file_powermanage_v1_agent_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_powermanage_v1_agent_proto_rawDesc), len(file_powermanage_v1_agent_proto_rawDesc)),
 			NumEnums:      6,
-			NumMessages:   44,
+			NumMessages:   45,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -1124,19 +1124,34 @@ func (x *ErrorDetail) GetRequestId() string {
 	return ""
 }
 
-// MaintenanceWindow gates action dispatch by device-local wall-clock
-// time. A window is a positive allowlist: when the schedule is empty
-// the window is "always allowed" — the feature is opt-in and existing
-// groups carry an empty window with zero behavioural change.
+// MaintenanceWindow gates action dispatch by wall-clock time. A window
+// is a positive allowlist: when the schedule and extra_dates are both
+// empty the window is "always allowed" — the feature is opt-in and
+// existing groups carry an empty window with zero behavioural change.
+// blackout_dates still apply to such a window, so a change freeze
+// needs no schedule.
 //
-// Multiple entries combine as OR. The agent evaluates against
-// time.Now().Local() at dispatch time so "02:00 local" means 02:00
-// wherever the device runs; the server never tries to interpret the
-// device's timezone.
+// Multiple entries combine as OR, and extra_dates OR in one-off
+// openings. blackout_dates win over both. Without a time_zone the
+// agent evaluates against time.Now().Local() at dispatch time so
+// "02:00 local" means 02:00 wherever the device runs; with one, the
+// window means the same instants on every device in the fleet. The
+// server never tries to interpret the device's timezone.
 type MaintenanceWindow struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @gotags: validate:"omitempty,dive"
-	Schedule      []*MaintenanceWindowEntry `protobuf:"bytes,1,rep,name=schedule,proto3" json:"schedule,omitempty" validate:"omitempty,dive"`
+	Schedule []*MaintenanceWindowEntry `protobuf:"bytes,1,rep,name=schedule,proto3" json:"schedule,omitempty" validate:"omitempty,dive"`
+	// IANA time zone the schedule and dates are read in (e.g.
+	// "Europe/Berlin"). Empty means the device's local time.
+	// @gotags: validate:"omitempty,max=64"
+	TimeZone string `protobuf:"bytes,2,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty" validate:"omitempty,max=64"`
+	// Dates on which dispatch is denied regardless of schedule and
+	// extra_dates.
+	// @gotags: validate:"omitempty,dive"
+	BlackoutDates []*MaintenanceWindowDate `protobuf:"bytes,3,rep,name=blackout_dates,json=blackoutDates,proto3" json:"blackout_dates,omitempty" validate:"omitempty,dive"`
+	// Dates on which dispatch is allowed in addition to the schedule.
+	// @gotags: validate:"omitempty,dive"
+	ExtraDates    []*MaintenanceWindowDate `protobuf:"bytes,4,rep,name=extra_dates,json=extraDates,proto3" json:"extra_dates,omitempty" validate:"omitempty,dive"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1178,6 +1193,27 @@ func (x *MaintenanceWindow) GetSchedule() []*MaintenanceWindowEntry {
 	return nil
 }
 
+func (x *MaintenanceWindow) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *MaintenanceWindow) GetBlackoutDates() []*MaintenanceWindowDate {
+	if x != nil {
+		return x.BlackoutDates
+	}
+	return nil
+}
+
+func (x *MaintenanceWindow) GetExtraDates() []*MaintenanceWindowDate {
+	if x != nil {
+		return x.ExtraDates
+	}
+	return nil
+}
+
 // One entry in a MaintenanceWindow: a set of weekdays and a single
 // allowed clock range. `allow` uses 24-hour HH:MM-HH:MM in local time
 // (e.g. "22:00-06:00"). Crossing midnight is supported: when the
@@ -1189,7 +1225,12 @@ type MaintenanceWindowEntry struct {
 	// @gotags: validate:"required,min=1,max=7,dive,oneof=mon tue wed thu fri sat sun"
 	Days []string `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty" validate:"required,min=1,max=7,dive,oneof=mon tue wed thu fri sat sun"`
 	// @gotags: validate:"required,min=11,max=11"
-	Allow         string `protobuf:"bytes,2,opt,name=allow,proto3" json:"allow,omitempty" validate:"required,min=11,max=11"`
+	Allow string `protobuf:"bytes,2,opt,name=allow,proto3" json:"allow,omitempty" validate:"required,min=11,max=11"`
+	// Overrides the window's time_zone for this entry. The union of
+	// windows in different zones sets it so every entry keeps the zone
+	// it was written in.
+	// @gotags: validate:"omitempty,max=64"
+	TimeZone      string `protobuf:"bytes,3,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty" validate:"omitempty,max=64"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1238,6 +1279,82 @@ func (x *MaintenanceWindowEntry) GetAllow() string {
 	return ""
 }
 
+func (x *MaintenanceWindowEntry) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+// One dated exception in a MaintenanceWindow. `date` is a calendar
+// date as YYYY-MM-DD. `allow` narrows the exception to one HH:MM-HH:MM
+// range starting on that date and may cross midnight like an entry's;
+// empty covers the whole date.
+type MaintenanceWindowDate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// @gotags: validate:"required,len=10"
+	Date string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty" validate:"required,len=10"`
+	// @gotags: validate:"omitempty,min=11,max=11"
+	Allow string `protobuf:"bytes,2,opt,name=allow,proto3" json:"allow,omitempty" validate:"omitempty,min=11,max=11"`
+	// Overrides the window's time_zone for this date, as on
+	// MaintenanceWindowEntry.
+	// @gotags: validate:"omitempty,max=64"
+	TimeZone      string `protobuf:"bytes,3,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty" validate:"omitempty,max=64"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MaintenanceWindowDate) Reset() {
+	*x = MaintenanceWindowDate{}
+	mi := &file_powermanage_v1_common_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MaintenanceWindowDate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MaintenanceWindowDate) ProtoMessage() {}
+
+func (x *MaintenanceWindowDate) ProtoReflect() protoreflect.Message {
+	mi := &file_powermanage_v1_common_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MaintenanceWindowDate.ProtoReflect.Descriptor instead.
+func (*MaintenanceWindowDate) Descriptor() ([]byte, []int) {
+	return file_powermanage_v1_common_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MaintenanceWindowDate) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *MaintenanceWindowDate) GetAllow() string {
+	if x != nil {
+		return x.Allow
+	}
+	return ""
+}
+
+func (x *MaintenanceWindowDate) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
 // SealedValue is the wire form of a secret-classified field: a versioned,
 // opaque X25519 sealed envelope produced by the SDK's crypto.SealToPublicKey
 // (sdk/crypto/seal.go). Agent->control values seal to control's deployment
@@ -1245,6 +1362,13 @@ func (x *MaintenanceWindowEntry) GetAllow() string {
 // key. Both public keys are exchanged on Register and pinned with the endpoint
 // identity.
 //
+// Either side may rotate its key afterwards. The agent announces its current
+// key in every Hello and control in every Welcome, both on the mTLS stream the
+// pinned identity authenticates, and the sender seals to the announced key
+// from then on. The recipient keeps opening values sealed to its previous key
+// for an overlap period (crypto.Keyring), so values already in flight or
+// queued for delivery still open; key_id tells it which key to use.
+//
 // The AAD binds, in this order, the protocol version, the direction
 // (agent->control or control->agent), the fully-qualified message and field
 // name, the device ULID, and the one action, delivery or terminal session the
@@ -1270,14 +1394,23 @@ type SealedValue struct {
 	// (16). The floor is crypto.MinSealedLen — anything shorter cannot be a
 	// sealed blob, so it is refused at the boundary rather than at the sink.
 	// @gotags: validate:"required,min=61,max=1048576"
-	Ciphertext    []byte `protobuf:"bytes,2,opt,name=ciphertext,proto3" json:"ciphertext,omitempty" validate:"required,min=61,max=1048576"`
+	Ciphertext []byte `protobuf:"bytes,2,opt,name=ciphertext,proto3" json:"ciphertext,omitempty" validate:"required,min=61,max=1048576"`
+	// Fingerprint of the recipient public key the value is sealed to
+	// (crypto.SealingKeyID). It selects the private key from the recipient's
+	// keyring and needs no separate authentication: the recipient public key is
+	// bound into the seal's HKDF salt, so a relabelled value fails to open
+	// rather than opening under another key.
+	// Empty from a sender that predates key ids: the recipient tries the keys
+	// it holds (crypto.Keyring.Open).
+	// @gotags: validate:"omitempty,len=8"
+	KeyId         []byte `protobuf:"bytes,3,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty" validate:"omitempty,len=8"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SealedValue) Reset() {
 	*x = SealedValue{}
-	mi := &file_powermanage_v1_common_proto_msgTypes[5]
+	mi := &file_powermanage_v1_common_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1289,7 +1422,7 @@ func (x *SealedValue) String() string {
 func (*SealedValue) ProtoMessage() {}
 
 func (x *SealedValue) ProtoReflect() protoreflect.Message {
-	mi := &file_powermanage_v1_common_proto_msgTypes[5]
+	mi := &file_powermanage_v1_common_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1302,7 +1435,7 @@ func (x *SealedValue) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SealedValue.ProtoReflect.Descriptor instead.
 func (*SealedValue) Descriptor() ([]byte, []int) {
-	return file_powermanage_v1_common_proto_rawDescGZIP(), []int{5}
+	return file_powermanage_v1_common_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *SealedValue) GetVersion() uint32 {
@@ -1319,6 +1452,13 @@ func (x *SealedValue) GetCiphertext() []byte {
 	return nil
 }
 
+func (x *SealedValue) GetKeyId() []byte {
+	if x != nil {
+		return x.KeyId
+	}
+	return nil
+}
+
 // Output from command execution
 type CommandOutput struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -1334,7 +1474,7 @@ type CommandOutput struct {
 
 func (x *CommandOutput) Reset() {
 	*x = CommandOutput{}
-	mi := &file_powermanage_v1_common_proto_msgTypes[6]
+	mi := &file_powermanage_v1_common_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1346,7 +1486,7 @@ func (x *CommandOutput) String() string {
 func (*CommandOutput) ProtoMessage() {}
 
 func (x *CommandOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_powermanage_v1_common_proto_msgTypes[6]
+	mi := &file_powermanage_v1_common_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1359,7 +1499,7 @@ func (x *CommandOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CommandOutput.ProtoReflect.Descriptor instead.
 func (*CommandOutput) Descriptor() ([]byte, []int) {
-	return file_powermanage_v1_common_proto_rawDescGZIP(), []int{6}
+	return file_powermanage_v1_common_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CommandOutput) GetExitCode() int32 {
@@ -1395,17 +1535,27 @@ const file_powermanage_v1_common_proto_rawDesc = "" +
 	"\vErrorDetail\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1d\n" +
 	"\n" +
-	"request_id\x18\x02 \x01(\tR\trequestId\"W\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\"\x8a\x02\n" +
 	"\x11MaintenanceWindow\x12B\n" +
-	"\bschedule\x18\x01 \x03(\v2&.powermanage.v1.MaintenanceWindowEntryR\bschedule\"B\n" +
+	"\bschedule\x18\x01 \x03(\v2&.powermanage.v1.MaintenanceWindowEntryR\bschedule\x12\x1b\n" +
+	"\ttime_zone\x18\x02 \x01(\tR\btimeZone\x12L\n" +
+	"\x0eblackout_dates\x18\x03 \x03(\v2%.powermanage.v1.MaintenanceWindowDateR\rblackoutDates\x12F\n" +
+	"\vextra_dates\x18\x04 \x03(\v2%.powermanage.v1.MaintenanceWindowDateR\n" +
+	"extraDates\"_\n" +
 	"\x16MaintenanceWindowEntry\x12\x12\n" +
 	"\x04days\x18\x01 \x03(\tR\x04days\x12\x14\n" +
-	"\x05allow\x18\x02 \x01(\tR\x05allow\"G\n" +
+	"\x05allow\x18\x02 \x01(\tR\x05allow\x12\x1b\n" +
+	"\ttime_zone\x18\x03 \x01(\tR\btimeZone\"^\n" +
+	"\x15MaintenanceWindowDate\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x14\n" +
+	"\x05allow\x18\x02 \x01(\tR\x05allow\x12\x1b\n" +
+	"\ttime_zone\x18\x03 \x01(\tR\btimeZone\"^\n" +
 	"\vSealedValue\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\rR\aversion\x12\x1e\n" +
 	"\n" +
 	"ciphertext\x18\x02 \x01(\fR\n" +
-	"ciphertext\"\\\n" +
+	"ciphertext\x12\x15\n" +
+	"\x06key_id\x18\x03 \x01(\fR\x05keyId\"\\\n" +
 	"\rCommandOutput\x12\x1b\n" +
 	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x16\n" +
 	"\x06stdout\x18\x02 \x01(\tR\x06stdout\x12\x16\n" +
@@ -1529,7 +1679,7 @@ func file_powermanage_v1_common_proto_rawDescGZIP() []byte {
 }
 
 var file_powermanage_v1_common_proto_enumTypes = make([]protoimpl.EnumInfo, 15)
-var file_powermanage_v1_common_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_powermanage_v1_common_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_powermanage_v1_common_proto_goTypes = []any{
 	(ExecutionStatus)(0),           // 0: powermanage.v1.ExecutionStatus
 	(DesiredState)(0),              // 1: powermanage.v1.DesiredState
@@ -1551,16 +1701,19 @@ var file_powermanage_v1_common_proto_goTypes = []any{
 	(*ErrorDetail)(nil),            // 17: powermanage.v1.ErrorDetail
 	(*MaintenanceWindow)(nil),      // 18: powermanage.v1.MaintenanceWindow
 	(*MaintenanceWindowEntry)(nil), // 19: powermanage.v1.MaintenanceWindowEntry
-	(*SealedValue)(nil),            // 20: powermanage.v1.SealedValue
-	(*CommandOutput)(nil),          // 21: powermanage.v1.CommandOutput
+	(*MaintenanceWindowDate)(nil),  // 20: powermanage.v1.MaintenanceWindowDate
+	(*SealedValue)(nil),            // 21: powermanage.v1.SealedValue
+	(*CommandOutput)(nil),          // 22: powermanage.v1.CommandOutput
 }
 var file_powermanage_v1_common_proto_depIdxs = []int32{
 	19, // 0: powermanage.v1.MaintenanceWindow.schedule:type_name -> powermanage.v1.MaintenanceWindowEntry
-	1,  // [1:1] is the sub-list for method output_type
-	1,  // [1:1] is the sub-list for method input_type
-	1,  // [1:1] is the sub-list for extension type_name
-	1,  // [1:1] is the sub-list for extension extendee
-	0,  // [0:1] is the sub-list for field type_name
+	20, // 1: powermanage.v1.MaintenanceWindow.blackout_dates:type_name -> powermanage.v1.MaintenanceWindowDate
+	20, // 2: powermanage.v1.MaintenanceWindow.extra_dates:type_name -> powermanage.v1.MaintenanceWindowDate
+	3,  // [3:3] is the sub-list for method output_type
+	3,  // [3:3] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_powermanage_v1_common_proto_init() }
@@ -1574,7 +1727,7 @@ func file_powermanage_v1_common_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_powermanage_v1_common_proto_rawDesc), len(file_powermanage_v1_common_proto_rawDesc)),
 			NumEnums:      15,
-			NumMessages:   7,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
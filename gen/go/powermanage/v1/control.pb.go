@@ -9332,9 +9332,9 @@ func (x *SetDeviceGroupInventoryIntervalRequest) GetInventoryIntervalMinutes() i
 }
 
 // Replace the device group's maintenance window. Pass an empty
-// MaintenanceWindow (or omit the schedule) to clear it — that drops
-// the group's contribution to the device-side union, leaving the
-// device unconstrained by this group.
+// MaintenanceWindow (no schedule, extra dates or blackout dates) to
+// clear it — that drops the group's contribution to the device-side
+// union, leaving the device unconstrained by this group.
 type SetDeviceGroupMaintenanceWindowRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @gotags: validate:"required,ulid"
@@ -16217,7 +16217,7 @@ func (x *EvaluateDynamicUserGroupResponse) GetUsersRemoved() int32 {
 // Replace the user group's maintenance window. Same semantics as
 // SetDeviceGroupMaintenanceWindowRequest. The window contributes to a
 // device-side union for every device the user group reaches via an
-// assignment. Empty schedule = clear.
+// assignment. Empty window = clear.
 type SetUserGroupMaintenanceWindowRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @gotags: validate:"required,ulid"
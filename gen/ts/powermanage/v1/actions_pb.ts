@@ -14,7 +14,7 @@ import type { Message } from "@bufbuild/protobuf";
  * Describes the file powermanage/v1/actions.proto.
  */
 export const file_powermanage_v1_actions: GenFile = /*@__PURE__*/
-  fileDesc("Chxwb3dlcm1hbmFnZS92MS9hY3Rpb25zLnByb3RvEg5wb3dlcm1hbmFnZS52MSLVCAoGQWN0aW9uEiQKAmlkGAEgASgLMhgucG93ZXJtYW5hZ2UudjEuQWN0aW9uSWQSKAoEdHlwZRgCIAEoDjIaLnBvd2VybWFuYWdlLnYxLkFjdGlvblR5cGUSMwoNZGVzaXJlZF9zdGF0ZRgDIAEoDjIcLnBvd2VybWFuYWdlLnYxLkRlc2lyZWRTdGF0ZRIXCg90aW1lb3V0X3NlY29uZHMYBCABKAUSMAoIc2NoZWR1bGUYBSABKAsyHi5wb3dlcm1hbmFnZS52MS5BY3Rpb25TY2hlZHVsZRIwCgdwYWNrYWdlGAggASgLMh0ucG93ZXJtYW5hZ2UudjEuUGFja2FnZVBhcmFtc0gAEi8KA2FwcBgJIAEoCzIgLnBvd2VybWFuYWdlLnYxLkFwcEluc3RhbGxQYXJhbXNIABIsCgVzaGVsbBgKIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNoZWxsUGFyYW1zSAASMAoHc2VydmljZRgLIAEoCzIdLnBvd2VybWFuYWdlLnYxLlNlcnZpY2VQYXJhbXNIABIqCgRmaWxlGAwgASgLMhoucG93ZXJtYW5hZ2UudjEuRmlsZVBhcmFtc0gAEi4KBnVwZGF0ZRgNIAEoCzIcLnBvd2VybWFuYWdlLnYxLlVwZGF0ZVBhcmFtc0gAEjYKCnJlcG9zaXRvcnkYDiABKAsyIC5wb3dlcm1hbmFnZS52MS5SZXBvc2l0b3J5UGFyYW1zSAASMAoHZmxhdHBhaxgPIAEoCzIdLnBvd2VybWFuYWdlLnYxLkZsYXRwYWtQYXJhbXNIABI0CglkaXJlY3RvcnkYECABKAsyHy5wb3dlcm1hbmFnZS52MS5EaXJlY3RvcnlQYXJhbXNIABIqCgR1c2VyGBEgASgLMhoucG93ZXJtYW5hZ2UudjEuVXNlclBhcmFtc0gAEigKA3NzaBgSIAEoCzIZLnBvd2VybWFuYWdlLnYxLlNzaFBhcmFtc0gAEioKBHNzaGQYEyABKAsyGi5wb3dlcm1hbmFnZS52MS5Tc2hkUGFyYW1zSAASOQoMYWRtaW5fcG9saWN5GBQgASgLMiEucG93ZXJtYW5hZ2UudjEuQWRtaW5Qb2xpY3lQYXJhbXNIABIoCgNscHMYFSABKAsyGS5wb3dlcm1hbmFnZS52MS5McHNQYXJhbXNIABIsCgVncm91cBgWIAEoCzIbLnBvd2VybWFuYWdlLnYxLkdyb3VwUGFyYW1zSAASNgoKZW5jcnlwdGlvbhgXIAEoCzIgLnBvd2VybWFuYWdlLnYxLkVuY3J5cHRpb25QYXJhbXNIABIqCgR3aWZpGBggASgLMhoucG93ZXJtYW5hZ2UudjEuV2lmaVBhcmFtc0gAEjkKDGFnZW50X3VwZGF0ZRgZIAEoCzIhLnBvd2VybWFuYWdlLnYxLkFnZW50VXBkYXRlUGFyYW1zSABCCAoGcGFyYW1zImgKDkFjdGlvblNjaGVkdWxlEgwKBGNyb24YASABKAkSFgoOaW50ZXJ2YWxfaG91cnMYAiABKAUSFQoNcnVuX29uX2Fzc2lnbhgDIAEoCBIZChFza2lwX2lmX3VuY2hhbmdlZBgEIAEoCCKiAQoNUGFja2FnZVBhcmFtcxIMCgRuYW1lGAEgASgJEg8KB3ZlcnNpb24YAiABKAkSFwoPYWxsb3dfZG93bmdyYWRlGAMgASgIEgsKA3BpbhgEIAEoCBIQCghhcHRfbmFtZRgFIAEoCRIQCghkbmZfbmFtZRgGIAEoCRITCgtwYWNtYW5fbmFtZRgHIAEoCRITCgt6eXBwZXJfbmFtZRgIIAEoCSJOChBBcHBJbnN0YWxsUGFyYW1zEgsKA3VybBgBIAEoCRIXCg9jaGVja3N1bV9zaGEyNTYYAiABKAkSFAoMaW5zdGFsbF9wYXRoGAMgASgJIooCCgtTaGVsbFBhcmFtcxIOCgZzY3JpcHQYASABKAkSEwoLaW50ZXJwcmV0ZXIYAiABKAkSEwoLcnVuX2FzX3Jvb3QYAyABKAgSGQoRd29ya2luZ19kaXJlY3RvcnkYBCABKAkSQQoLZW52aXJvbm1lbnQYBSADKAsyLC5wb3dlcm1hbmFnZS52MS5TaGVsbFBhcmFtcy5FbnZpcm9ubWVudEVudHJ5EhgKEGRldGVjdGlvbl9zY3JpcHQYBiABKAkSFQoNaXNfY29tcGxpYW5jZRgHIAEoCBoyChBFbnZpcm9ubWVudEVudHJ5EgsKA2tleRgBIAEoCRINCgV2YWx1ZRgCIAEoCToCOAEigQEKDVNlcnZpY2VQYXJhbXMSEQoJdW5pdF9uYW1lGAEgASgJEjcKDWRlc2lyZWRfc3RhdGUYAiABKA4yIC5wb3dlcm1hbmFnZS52MS5TZXJ2aWNlVW5pdFN0YXRlEg4KBmVuYWJsZRgDIAEoCBIUCgx1bml0X2NvbnRlbnQYBCABKAkibgoKRmlsZVBhcmFtcxIMCgRwYXRoGAEgASgJEg8KB2NvbnRlbnQYAiABKAkSDQoFb3duZXIYAyABKAkSDQoFZ3JvdXAYBCABKAkSDAoEbW9kZRgFIAEoCRIVCg1tYW5hZ2VkX2Jsb2NrGAYgASgIIl4KD0RpcmVjdG9yeVBhcmFtcxIMCgRwYXRoGAEgASgJEg0KBW93bmVyGAIgASgJEg0KBWdyb3VwGAMgASgJEgwKBG1vZGUYBCABKAkSEQoJcmVjdXJzaXZlGAUgASgIIlUKDFVwZGF0ZVBhcmFtcxIVCg1zZWN1cml0eV9vbmx5GAEgASgIEhIKCmF1dG9yZW1vdmUYAiABKAgSGgoScmVib290X2lmX3JlcXVpcmVkGAMgASgIIlEKDUZsYXRwYWtQYXJhbXMSDgoGYXBwX2lkGAEgASgJEg4KBnJlbW90ZRgCIAEoCRITCgtzeXN0ZW1fd2lkZRgDIAEoCBILCgNwaW4YBCABKAgi3AEKEFJlcG9zaXRvcnlQYXJhbXMSDAoEbmFtZRgBIAEoCRIqCgNhcHQYAiABKAsyHS5wb3dlcm1hbmFnZS52MS5BcHRSZXBvc2l0b3J5EioKA2RuZhgDIAEoCzIdLnBvd2VybWFuYWdlLnYxLkRuZlJlcG9zaXRvcnkSMAoGcGFjbWFuGAQgASgLMiAucG93ZXJtYW5hZ2UudjEuUGFjbWFuUmVwb3NpdG9yeRIwCgZ6eXBwZXIYBSABKAsyIC5wb3dlcm1hbmFnZS52MS5aeXBwZXJSZXBvc2l0b3J5Ip0BCg1BcHRSZXBvc2l0b3J5EgsKA3VybBgBIAEoCRIUCgxkaXN0cmlidXRpb24YAiABKAkSEgoKY29tcG9uZW50cxgDIAMoCRITCgtncGdfa2V5X3VybBgEIAEoCRIPCgdncGdfa2V5GAUgASgJEg8KB3RydXN0ZWQYBiABKAgSDAoEYXJjaBgHIAEoCRIQCghkaXNhYmxlZBgIIAEoCCKTAQoNRG5mUmVwb3NpdG9yeRIPCgdiYXNldXJsGAEgASgJEhMKC2Rlc2NyaXB0aW9uGAIgASgJEg8KB2VuYWJsZWQYAyABKAgSEAoIZ3BnY2hlY2sYBCABKAgSDgoGZ3Bna2V5GAUgASgJEhcKD21vZHVsZV9ob3RmaXhlcxgGIAEoCBIQCghkaXNhYmxlZBgHIAEoCCJHChBQYWNtYW5SZXBvc2l0b3J5Eg4KBnNlcnZlchgBIAEoCRIRCglzaWdfbGV2ZWwYAiABKAkSEAoIZGlzYWJsZWQYAyABKAginAEKEFp5cHBlclJlcG9zaXRvcnkSCwoDdXJsGAEgASgJEhMKC2Rlc2NyaXB0aW9uGAIgASgJEg8KB2VuYWJsZWQYAyABKAgSEwoLYXV0b3JlZnJlc2gYBCABKAgSEAoIZ3BnY2hlY2sYBSABKAgSDgoGZ3Bna2V5GAYgASgJEgwKBHR5cGUYByABKAkSEAoIZGlzYWJsZWQYCCABKAgi/wEKClVzZXJQYXJhbXMSEAoIdXNlcm5hbWUYASABKAkSCwoDdWlkGAIgASgFEgsKA2dpZBgDIAEoBRIQCghob21lX2RpchgEIAEoCRINCgVzaGVsbBgFIAEoCRIbChNzc2hfYXV0aG9yaXplZF9rZXlzGAYgAygJEg8KB2NvbW1lbnQYByABKAkSEwoLc3lzdGVtX3VzZXIYCCABKAgSEwoLY3JlYXRlX2hvbWUYCSABKAgSEAoIZGlzYWJsZWQYCiABKAgSFQoNcHJpbWFyeV9ncm91cBgLIAEoCRIOCgZoaWRkZW4YDCABKAgSEwoLbm9fcGFzc3dvcmQYDSABKAgiTwoLR3JvdXBQYXJhbXMSDAoEbmFtZRgBIAEoCRIPCgdtZW1iZXJzGAIgAygJEgsKA2dpZBgDIAEoBRIUCgxzeXN0ZW1fZ3JvdXAYBCABKAgiSAoJU3NoUGFyYW1zEhQKDGFsbG93X3B1YmtleRgBIAEoCBIWCg5hbGxvd19wYXNzd29yZBgCIAEoCBINCgV1c2VycxgDIAMoCSIrCg1Tc2hkRGlyZWN0aXZlEgsKA2tleRgBIAEoCRINCgV2YWx1ZRgCIAEoCSJRCgpTc2hkUGFyYW1zEhAKCHByaW9yaXR5GAEgASgNEjEKCmRpcmVjdGl2ZXMYAiADKAsyHS5wb3dlcm1hbmFnZS52MS5Tc2hkRGlyZWN0aXZlIqQBChFBZG1pblBvbGljeVBhcmFtcxI2CgxhY2Nlc3NfbGV2ZWwYASABKA4yIC5wb3dlcm1hbmFnZS52MS5BZG1pbkFjY2Vzc0xldmVsEg0KBXVzZXJzGAIgAygJEhUKDWN1c3RvbV9jb25maWcYAyABKAkSMQoHYmFja2VuZBgEIAEoDjIgLnBvd2VybWFuYWdlLnYxLlByaXZpbGVnZUJhY2tlbmQirgEKCUxwc1BhcmFtcxIRCgl1c2VybmFtZXMYASADKAkSFwoPcGFzc3dvcmRfbGVuZ3RoGAIgASgFEjkKCmNvbXBsZXhpdHkYAyABKA4yJS5wb3dlcm1hbmFnZS52MS5McHNQYXNzd29yZENvbXBsZXhpdHkSHgoWcm90YXRpb25faW50ZXJ2YWxfZGF5cxgEIAEoBRIaChJncmFjZV9wZXJpb2RfaG91cnMYBSABKAUiugIKEEVuY3J5cHRpb25QYXJhbXMSNwoNcHJlc2hhcmVkX2tleRgBIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESHgoWcm90YXRpb25faW50ZXJ2YWxfZGF5cxgCIAEoBRIRCgltaW5fd29yZHMYAyABKAUSSwoVZGV2aWNlX2JvdW5kX2tleV90eXBlGAQgASgOMiwucG93ZXJtYW5hZ2UudjEuRW5jcnlwdGlvbkRldmljZUJvdW5kS2V5VHlwZRIiChp1c2VyX3Bhc3NwaHJhc2VfbWluX2xlbmd0aBgFIAEoBRJJChp1c2VyX3Bhc3NwaHJhc2VfY29tcGxleGl0eRgGIAEoDjIlLnBvd2VybWFuYWdlLnYxLkxwc1Bhc3N3b3JkQ29tcGxleGl0eSKgAgoKV2lmaVBhcmFtcxIMCgRzc2lkGAEgASgJEi8KCWF1dGhfdHlwZRgCIAEoDjIcLnBvd2VybWFuYWdlLnYxLldpZmlBdXRoVHlwZRItCgNwc2sYAyABKAsyGy5wb3dlcm1hbmFnZS52MS5TZWFsZWRWYWx1ZUIDgAEBEg8KB2NhX2NlcnQYBCABKAkSEwoLY2xpZW50X2NlcnQYBSABKAkSNAoKY2xpZW50X2tleRgGIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESEAoIaWRlbnRpdHkYByABKAkSFAoMYXV0b19jb25uZWN0GAggASgIEg4KBmhpZGRlbhgJIAEoCBIQCghwcmlvcml0eRgKIAEoBSLpAwoMQWN0aW9uUmVzdWx0EisKCWFjdGlvbl9pZBgBIAEoCzIYLnBvd2VybWFuYWdlLnYxLkFjdGlvbklkEi8KBnN0YXR1cxgCIAEoDjIfLnBvd2VybWFuYWdlLnYxLkV4ZWN1dGlvblN0YXR1cxINCgVlcnJvchgDIAEoCRItCgZvdXRwdXQYBCABKAsyHS5wb3dlcm1hbmFnZS52MS5Db21tYW5kT3V0cHV0EjAKDGNvbXBsZXRlZF9hdBgFIAEoCzIaLmdvb2dsZS5wcm90b2J1Zi5UaW1lc3RhbXASEwoLZHVyYXRpb25fbXMYBiABKAMSDwoHY2hhbmdlZBgHIAEoCBI8CghtZXRhZGF0YRgIIAMoCzIqLnBvd2VybWFuYWdlLnYxLkFjdGlvblJlc3VsdC5NZXRhZGF0YUVudHJ5EhEKCWNvbXBsaWFudBgJIAEoCBI3ChBkZXRlY3Rpb25fb3V0cHV0GAogASgLMh0ucG93ZXJtYW5hZ2UudjEuQ29tbWFuZE91dHB1dBITCgtkZWxpdmVyeV9pZBgLIAEoCRIVCg1vY2N1cnJlbmNlX2lkGAwgASgJGi8KDU1ldGFkYXRhRW50cnkSCwoDa2V5GAEgASgJEg0KBXZhbHVlGAIgASgJOgI4ASJUCg9BZ2VudFVwZGF0ZUFyY2gSEgoKYmluYXJ5X3VybBgBIAEoCRIUCgxjaGVja3N1bV91cmwYAiABKAkSFwoPZXhwZWN0ZWRfc2hhMjU2GAMgASgJIqQBChFBZ2VudFVwZGF0ZVBhcmFtcxIuCgVhbWQ2NBgBIAEoCzIfLnBvd2VybWFuYWdlLnYxLkFnZW50VXBkYXRlQXJjaBIuCgVhcm02NBgCIAEoCzIfLnBvd2VybWFuYWdlLnYxLkFnZW50VXBkYXRlQXJjaBIXCg9hbGxvd19kb3duZ3JhZGUYAyABKAgSFgoOYWxsb3dfcmVkaXJlY3QYBCABKAgq6gQKCkFjdGlvblR5cGUSGwoXQUNUSU9OX1RZUEVfVU5TUEVDSUZJRUQQABIXChNBQ1RJT05fVFlQRV9QQUNLQUdFEAESFgoSQUNUSU9OX1RZUEVfVVBEQVRFEAISGgoWQUNUSU9OX1RZUEVfUkVQT1NJVE9SWRADEhkKFUFDVElPTl9UWVBFX0FQUF9JTUFHRRBkEhMKD0FDVElPTl9UWVBFX0RFQhBlEhMKD0FDVElPTl9UWVBFX1JQTRBmEhcKE0FDVElPTl9UWVBFX0ZMQVRQQUsQZxIWChFBQ1RJT05fVFlQRV9TSEVMTBDIARIbChZBQ1RJT05fVFlQRV9TQ1JJUFRfUlVOEMkBEhgKE0FDVElPTl9UWVBFX1NFUlZJQ0UQrAISFQoQQUNUSU9OX1RZUEVfRklMRRCQAxIaChVBQ1RJT05fVFlQRV9ESVJFQ1RPUlkQkQMSFwoSQUNUSU9OX1RZUEVfUkVCT09UEPQDEhUKEEFDVElPTl9UWVBFX1NZTkMQ9QMSFQoQQUNUSU9OX1RZUEVfVVNFUhDYBBIWChFBQ1RJT05fVFlQRV9HUk9VUBDZBBIUCg9BQ1RJT05fVFlQRV9TU0gQvAUSFQoQQUNUSU9OX1RZUEVfU1NIRBC9BRIdChhBQ1RJT05fVFlQRV9BRE1JTl9QT0xJQ1kQoAYSFAoPQUNUSU9OX1RZUEVfTFBTEIQHEhsKFkFDVElPTl9UWVBFX0VOQ1JZUFRJT04Q6AcSFQoQQUNUSU9OX1RZUEVfV0lGSRDMCBIdChhBQ1RJT05fVFlQRV9BR0VOVF9VUERBVEUQsAkqmAEKEFNlcnZpY2VVbml0U3RhdGUSIgoeU0VSVklDRV9VTklUX1NUQVRFX1VOU1BFQ0lGSUVEEAASHgoaU0VSVklDRV9VTklUX1NUQVRFX1NUQVJURUQQARIeChpTRVJWSUNFX1VOSVRfU1RBVEVfU1RPUFBFRBACEiAKHFNFUlZJQ0VfVU5JVF9TVEFURV9SRVNUQVJURUQQAyrtAQoQQWRtaW5BY2Nlc3NMZXZlbBIiCh5BRE1JTl9BQ0NFU1NfTEVWRUxfVU5TUEVDSUZJRUQQABIbChdBRE1JTl9BQ0NFU1NfTEVWRUxfRlVMTBABEh4KGkFETUlOX0FDQ0VTU19MRVZFTF9MSU1JVEVEEAISHQoZQURNSU5fQUNDRVNTX0xFVkVMX0NVU1RPTRADEi0KKUFETUlOX0FDQ0VTU19MRVZFTF9URVJNSU5BTF9BRE1JTl9MSU1JVEVEEAQSKgomQURNSU5fQUNDRVNTX0xFVkVMX1RFUk1JTkFMX0FETUlOX0ZVTEwQBSpKChBQcml2aWxlZ2VCYWNrZW5kEhoKFlBSSVZJTEVHRV9CQUNLRU5EX1NVRE8QABIaChZQUklWSUxFR0VfQkFDS0VORF9ET0FTEAEqjwEKFUxwc1Bhc3N3b3JkQ29tcGxleGl0eRInCiNMUFNfUEFTU1dPUkRfQ09NUExFWElUWV9VTlNQRUNJRklFRBAAEigKJExQU19QQVNTV09SRF9DT01QTEVYSVRZX0FMUEhBTlVNRVJJQxABEiMKH0xQU19QQVNTV09SRF9DT01QTEVYSVRZX0NPTVBMRVgQAiqpAQocRW5jcnlwdGlvbkRldmljZUJvdW5kS2V5VHlwZRIpCiVFTkNSWVBUSU9OX0RFVklDRV9CT1VORF9LRVlfVFlQRV9OT05FEAASKAokRU5DUllQVElPTl9ERVZJQ0VfQk9VTkRfS0VZX1RZUEVfVFBNEAESNAowRU5DUllQVElPTl9ERVZJQ0VfQk9VTkRfS0VZX1RZUEVfVVNFUl9QQVNTUEhSQVNFEAIqYgoMV2lmaUF1dGhUeXBlEh4KGldJRklfQVVUSF9UWVBFX1VOU1BFQ0lGSUVEEAASFgoSV0lGSV9BVVRIX1RZUEVfUFNLEAESGgoWV0lGSV9BVVRIX1RZUEVfRUFQX1RMUxACQkxaSmdpdGh1Yi5jb20vbWFuY2h0b29scy9wb3dlci1tYW5hZ2Utc2RrL2dlbi9nby9wb3dlcm1hbmFnZS92MTtwb3dlcm1hbmFnZXYxYgZwcm90bzM", [file_google_protobuf_timestamp, file_powermanage_v1_common]);
+  fileDesc("Chxwb3dlcm1hbmFnZS92MS9hY3Rpb25zLnByb3RvEg5wb3dlcm1hbmFnZS52MSLVCAoGQWN0aW9uEiQKAmlkGAEgASgLMhgucG93ZXJtYW5hZ2UudjEuQWN0aW9uSWQSKAoEdHlwZRgCIAEoDjIaLnBvd2VybWFuYWdlLnYxLkFjdGlvblR5cGUSMwoNZGVzaXJlZF9zdGF0ZRgDIAEoDjIcLnBvd2VybWFuYWdlLnYxLkRlc2lyZWRTdGF0ZRIXCg90aW1lb3V0X3NlY29uZHMYBCABKAUSMAoIc2NoZWR1bGUYBSABKAsyHi5wb3dlcm1hbmFnZS52MS5BY3Rpb25TY2hlZHVsZRIwCgdwYWNrYWdlGAggASgLMh0ucG93ZXJtYW5hZ2UudjEuUGFja2FnZVBhcmFtc0gAEi8KA2FwcBgJIAEoCzIgLnBvd2VybWFuYWdlLnYxLkFwcEluc3RhbGxQYXJhbXNIABIsCgVzaGVsbBgKIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNoZWxsUGFyYW1zSAASMAoHc2VydmljZRgLIAEoCzIdLnBvd2VybWFuYWdlLnYxLlNlcnZpY2VQYXJhbXNIABIqCgRmaWxlGAwgASgLMhoucG93ZXJtYW5hZ2UudjEuRmlsZVBhcmFtc0gAEi4KBnVwZGF0ZRgNIAEoCzIcLnBvd2VybWFuYWdlLnYxLlVwZGF0ZVBhcmFtc0gAEjYKCnJlcG9zaXRvcnkYDiABKAsyIC5wb3dlcm1hbmFnZS52MS5SZXBvc2l0b3J5UGFyYW1zSAASMAoHZmxhdHBhaxgPIAEoCzIdLnBvd2VybWFuYWdlLnYxLkZsYXRwYWtQYXJhbXNIABI0CglkaXJlY3RvcnkYECABKAsyHy5wb3dlcm1hbmFnZS52MS5EaXJlY3RvcnlQYXJhbXNIABIqCgR1c2VyGBEgASgLMhoucG93ZXJtYW5hZ2UudjEuVXNlclBhcmFtc0gAEigKA3NzaBgSIAEoCzIZLnBvd2VybWFuYWdlLnYxLlNzaFBhcmFtc0gAEioKBHNzaGQYEyABKAsyGi5wb3dlcm1hbmFnZS52MS5Tc2hkUGFyYW1zSAASOQoMYWRtaW5fcG9saWN5GBQgASgLMiEucG93ZXJtYW5hZ2UudjEuQWRtaW5Qb2xpY3lQYXJhbXNIABIoCgNscHMYFSABKAsyGS5wb3dlcm1hbmFnZS52MS5McHNQYXJhbXNIABIsCgVncm91cBgWIAEoCzIbLnBvd2VybWFuYWdlLnYxLkdyb3VwUGFyYW1zSAASNgoKZW5jcnlwdGlvbhgXIAEoCzIgLnBvd2VybWFuYWdlLnYxLkVuY3J5cHRpb25QYXJhbXNIABIqCgR3aWZpGBggASgLMhoucG93ZXJtYW5hZ2UudjEuV2lmaVBhcmFtc0gAEjkKDGFnZW50X3VwZGF0ZRgZIAEoCzIhLnBvd2VybWFuYWdlLnYxLkFnZW50VXBkYXRlUGFyYW1zSABCCAoGcGFyYW1zImgKDkFjdGlvblNjaGVkdWxlEgwKBGNyb24YASABKAkSFgoOaW50ZXJ2YWxfaG91cnMYAiABKAUSFQoNcnVuX29uX2Fzc2lnbhgDIAEoCBIZChFza2lwX2lmX3VuY2hhbmdlZBgEIAEoCCKiAQoNUGFja2FnZVBhcmFtcxIMCgRuYW1lGAEgASgJEg8KB3ZlcnNpb24YAiABKAkSFwoPYWxsb3dfZG93bmdyYWRlGAMgASgIEgsKA3BpbhgEIAEoCBIQCghhcHRfbmFtZRgFIAEoCRIQCghkbmZfbmFtZRgGIAEoCRITCgtwYWNtYW5fbmFtZRgHIAEoCRITCgt6eXBwZXJfbmFtZRgIIAEoCSJOChBBcHBJbnN0YWxsUGFyYW1zEgsKA3VybBgBIAEoCRIXCg9jaGVja3N1bV9zaGEyNTYYAiABKAkSFAoMaW5zdGFsbF9wYXRoGAMgASgJIrkCCgtTaGVsbFBhcmFtcxIOCgZzY3JpcHQYASABKAkSEwoLaW50ZXJwcmV0ZXIYAiABKAkSEwoLcnVuX2FzX3Jvb3QYAyABKAgSGQoRd29ya2luZ19kaXJlY3RvcnkYBCABKAkSQQoLZW52aXJvbm1lbnQYBSADKAsyLC5wb3dlcm1hbmFnZS52MS5TaGVsbFBhcmFtcy5FbnZpcm9ubWVudEVudHJ5EhgKEGRldGVjdGlvbl9zY3JpcHQYBiABKAkSFQoNaXNfY29tcGxpYW5jZRgHIAEoCBItCgdzYW5kYm94GAggASgLMhwucG93ZXJtYW5hZ2UudjEuU2hlbGxTYW5kYm94GjIKEEVudmlyb25tZW50RW50cnkSCwoDa2V5GAEgASgJEg0KBXZhbHVlGAIgASgJOgI4ASKBAQoNU2VydmljZVBhcmFtcxIRCgl1bml0X25hbWUYASABKAkSNwoNZGVzaXJlZF9zdGF0ZRgCIAEoDjIgLnBvd2VybWFuYWdlLnYxLlNlcnZpY2VVbml0U3RhdGUSDgoGZW5hYmxlGAMgASgIEhQKDHVuaXRfY29udGVudBgEIAEoCSKAAQoKRmlsZVBhcmFtcxIMCgRwYXRoGAEgASgJEg8KB2NvbnRlbnQYAiABKAkSDQoFb3duZXIYAyABKAkSDQoFZ3JvdXAYBCABKAkSDAoEbW9kZRgFIAEoCRIVCg1tYW5hZ2VkX2Jsb2NrGAYgASgIEhAKCHRlbXBsYXRlGAcgASgIIl4KD0RpcmVjdG9yeVBhcmFtcxIMCgRwYXRoGAEgASgJEg0KBW93bmVyGAIgASgJEg0KBWdyb3VwGAMgASgJEgwKBG1vZGUYBCABKAkSEQoJcmVjdXJzaXZlGAUgASgIIlUKDFVwZGF0ZVBhcmFtcxIVCg1zZWN1cml0eV9vbmx5GAEgASgIEhIKCmF1dG9yZW1vdmUYAiABKAgSGgoScmVib290X2lmX3JlcXVpcmVkGAMgASgIIlEKDUZsYXRwYWtQYXJhbXMSDgoGYXBwX2lkGAEgASgJEg4KBnJlbW90ZRgCIAEoCRITCgtzeXN0ZW1fd2lkZRgDIAEoCBILCgNwaW4YBCABKAgi3AEKEFJlcG9zaXRvcnlQYXJhbXMSDAoEbmFtZRgBIAEoCRIqCgNhcHQYAiABKAsyHS5wb3dlcm1hbmFnZS52MS5BcHRSZXBvc2l0b3J5EioKA2RuZhgDIAEoCzIdLnBvd2VybWFuYWdlLnYxLkRuZlJlcG9zaXRvcnkSMAoGcGFjbWFuGAQgASgLMiAucG93ZXJtYW5hZ2UudjEuUGFjbWFuUmVwb3NpdG9yeRIwCgZ6eXBwZXIYBSABKAsyIC5wb3dlcm1hbmFnZS52MS5aeXBwZXJSZXBvc2l0b3J5Ip0BCg1BcHRSZXBvc2l0b3J5EgsKA3VybBgBIAEoCRIUCgxkaXN0cmlidXRpb24YAiABKAkSEgoKY29tcG9uZW50cxgDIAMoCRITCgtncGdfa2V5X3VybBgEIAEoCRIPCgdncGdfa2V5GAUgASgJEg8KB3RydXN0ZWQYBiABKAgSDAoEYXJjaBgHIAEoCRIQCghkaXNhYmxlZBgIIAEoCCKTAQoNRG5mUmVwb3NpdG9yeRIPCgdiYXNldXJsGAEgASgJEhMKC2Rlc2NyaXB0aW9uGAIgASgJEg8KB2VuYWJsZWQYAyABKAgSEAoIZ3BnY2hlY2sYBCABKAgSDgoGZ3Bna2V5GAUgASgJEhcKD21vZHVsZV9ob3RmaXhlcxgGIAEoCBIQCghkaXNhYmxlZBgHIAEoCCJHChBQYWNtYW5SZXBvc2l0b3J5Eg4KBnNlcnZlchgBIAEoCRIRCglzaWdfbGV2ZWwYAiABKAkSEAoIZGlzYWJsZWQYAyABKAginAEKEFp5cHBlclJlcG9zaXRvcnkSCwoDdXJsGAEgASgJEhMKC2Rlc2NyaXB0aW9uGAIgASgJEg8KB2VuYWJsZWQYAyABKAgSEwoLYXV0b3JlZnJlc2gYBCABKAgSEAoIZ3BnY2hlY2sYBSABKAgSDgoGZ3Bna2V5GAYgASgJEgwKBHR5cGUYByABKAkSEAoIZGlzYWJsZWQYCCABKAgi/wEKClVzZXJQYXJhbXMSEAoIdXNlcm5hbWUYASABKAkSCwoDdWlkGAIgASgFEgsKA2dpZBgDIAEoBRIQCghob21lX2RpchgEIAEoCRINCgVzaGVsbBgFIAEoCRIbChNzc2hfYXV0aG9yaXplZF9rZXlzGAYgAygJEg8KB2NvbW1lbnQYByABKAkSEwoLc3lzdGVtX3VzZXIYCCABKAgSEwoLY3JlYXRlX2hvbWUYCSABKAgSEAoIZGlzYWJsZWQYCiABKAgSFQoNcHJpbWFyeV9ncm91cBgLIAEoCRIOCgZoaWRkZW4YDCABKAgSEwoLbm9fcGFzc3dvcmQYDSABKAgiTwoLR3JvdXBQYXJhbXMSDAoEbmFtZRgBIAEoCRIPCgdtZW1iZXJzGAIgAygJEgsKA2dpZBgDIAEoBRIUCgxzeXN0ZW1fZ3JvdXAYBCABKAgiSAoJU3NoUGFyYW1zEhQKDGFsbG93X3B1YmtleRgBIAEoCBIWCg5hbGxvd19wYXNzd29yZBgCIAEoCBINCgV1c2VycxgDIAMoCSIrCg1Tc2hkRGlyZWN0aXZlEgsKA2tleRgBIAEoCRINCgV2YWx1ZRgCIAEoCSJRCgpTc2hkUGFyYW1zEhAKCHByaW9yaXR5GAEgASgNEjEKCmRpcmVjdGl2ZXMYAiADKAsyHS5wb3dlcm1hbmFnZS52MS5Tc2hkRGlyZWN0aXZlIqQBChFBZG1pblBvbGljeVBhcmFtcxI2CgxhY2Nlc3NfbGV2ZWwYASABKA4yIC5wb3dlcm1hbmFnZS52MS5BZG1pbkFjY2Vzc0xldmVsEg0KBXVzZXJzGAIgAygJEhUKDWN1c3RvbV9jb25maWcYAyABKAkSMQoHYmFja2VuZBgEIAEoDjIgLnBvd2VybWFuYWdlLnYxLlByaXZpbGVnZUJhY2tlbmQirgEKCUxwc1BhcmFtcxIRCgl1c2VybmFtZXMYASADKAkSFwoPcGFzc3dvcmRfbGVuZ3RoGAIgASgFEjkKCmNvbXBsZXhpdHkYAyABKA4yJS5wb3dlcm1hbmFnZS52MS5McHNQYXNzd29yZENvbXBsZXhpdHkSHgoWcm90YXRpb25faW50ZXJ2YWxfZGF5cxgEIAEoBRIaChJncmFjZV9wZXJpb2RfaG91cnMYBSABKAUiugIKEEVuY3J5cHRpb25QYXJhbXMSNwoNcHJlc2hhcmVkX2tleRgBIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESHgoWcm90YXRpb25faW50ZXJ2YWxfZGF5cxgCIAEoBRIRCgltaW5fd29yZHMYAyABKAUSSwoVZGV2aWNlX2JvdW5kX2tleV90eXBlGAQgASgOMiwucG93ZXJtYW5hZ2UudjEuRW5jcnlwdGlvbkRldmljZUJvdW5kS2V5VHlwZRIiChp1c2VyX3Bhc3NwaHJhc2VfbWluX2xlbmd0aBgFIAEoBRJJChp1c2VyX3Bhc3NwaHJhc2VfY29tcGxleGl0eRgGIAEoDjIlLnBvd2VybWFuYWdlLnYxLkxwc1Bhc3N3b3JkQ29tcGxleGl0eSKgAgoKV2lmaVBhcmFtcxIMCgRzc2lkGAEgASgJEi8KCWF1dGhfdHlwZRgCIAEoDjIcLnBvd2VybWFuYWdlLnYxLldpZmlBdXRoVHlwZRItCgNwc2sYAyABKAsyGy5wb3dlcm1hbmFnZS52MS5TZWFsZWRWYWx1ZUIDgAEBEg8KB2NhX2NlcnQYBCABKAkSEwoLY2xpZW50X2NlcnQYBSABKAkSNAoKY2xpZW50X2tleRgGIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESEAoIaWRlbnRpdHkYByABKAkSFAoMYXV0b19jb25uZWN0GAggASgIEg4KBmhpZGRlbhgJIAEoCBIQCghwcmlvcml0eRgKIAEoBSKhBAoMQWN0aW9uUmVzdWx0EisKCWFjdGlvbl9pZBgBIAEoCzIYLnBvd2VybWFuYWdlLnYxLkFjdGlvbklkEi8KBnN0YXR1cxgCIAEoDjIfLnBvd2VybWFuYWdlLnYxLkV4ZWN1dGlvblN0YXR1cxINCgVlcnJvchgDIAEoCRItCgZvdXRwdXQYBCABKAsyHS5wb3dlcm1hbmFnZS52MS5Db21tYW5kT3V0cHV0EjAKDGNvbXBsZXRlZF9hdBgFIAEoCzIaLmdvb2dsZS5wcm90b2J1Zi5UaW1lc3RhbXASEwoLZHVyYXRpb25fbXMYBiABKAMSDwoHY2hhbmdlZBgHIAEoCBI8CghtZXRhZGF0YRgIIAMoCzIqLnBvd2VybWFuYWdlLnYxLkFjdGlvblJlc3VsdC5NZXRhZGF0YUVudHJ5EhEKCWNvbXBsaWFudBgJIAEoCBI3ChBkZXRlY3Rpb25fb3V0cHV0GAogASgLMh0ucG93ZXJtYW5hZ2UudjEuQ29tbWFuZE91dHB1dBITCgtkZWxpdmVyeV9pZBgLIAEoCRIVCg1vY2N1cnJlbmNlX2lkGAwgASgJEjYKD3JlY2VudF9jb21tYW5kcxgNIAMoCzIdLnBvd2VybWFuYWdlLnYxLkNvbW1hbmRSZWNvcmQaLwoNTWV0YWRhdGFFbnRyeRILCgNrZXkYASABKAkSDQoFdmFsdWUYAiABKAk6AjgBIlQKD0FnZW50VXBkYXRlQXJjaBISCgpiaW5hcnlfdXJsGAEgASgJEhQKDGNoZWNrc3VtX3VybBgCIAEoCRIXCg9leHBlY3RlZF9zaGEyNTYYAyABKAkipAEKEUFnZW50VXBkYXRlUGFyYW1zEi4KBWFtZDY0GAEgASgLMh8ucG93ZXJtYW5hZ2UudjEuQWdlbnRVcGRhdGVBcmNoEi4KBWFybTY0GAIgASgLMh8ucG93ZXJtYW5hZ2UudjEuQWdlbnRVcGRhdGVBcmNoEhcKD2FsbG93X2Rvd25ncmFkZRgDIAEoCBIWCg5hbGxvd19yZWRpcmVjdBgEIAEoCCI3CgxTaGVsbFNhbmRib3gSFgoOd3JpdGFibGVfcGF0aHMYASADKAkSDwoHbmV0d29yaxgCIAEoCCK0AQoNQ29tbWFuZFJlY29yZBIMCgRuYW1lGAEgASgJEgwKBGFyZ3MYAiADKAkSEgoKZXNjYWxhdGlvbhgDIAEoCRIMCgR1c2VyGAQgASgJEi4KCnN0YXJ0ZWRfYXQYBSABKAsyGi5nb29nbGUucHJvdG9idWYuVGltZXN0YW1wEhMKC2R1cmF0aW9uX21zGAYgASgDEhEKCWV4aXRfY29kZRgHIAEoBRINCgVlcnJvchgIIAEoCSrqBAoKQWN0aW9uVHlwZRIbChdBQ1RJT05fVFlQRV9VTlNQRUNJRklFRBAAEhcKE0FDVElPTl9UWVBFX1BBQ0tBR0UQARIWChJBQ1RJT05fVFlQRV9VUERBVEUQAhIaChZBQ1RJT05fVFlQRV9SRVBPU0lUT1JZEAMSGQoVQUNUSU9OX1RZUEVfQVBQX0lNQUdFEGQSEwoPQUNUSU9OX1RZUEVfREVCEGUSEwoPQUNUSU9OX1RZUEVfUlBNEGYSFwoTQUNUSU9OX1RZUEVfRkxBVFBBSxBnEhYKEUFDVElPTl9UWVBFX1NIRUxMEMgBEhsKFkFDVElPTl9UWVBFX1NDUklQVF9SVU4QyQESGAoTQUNUSU9OX1RZUEVfU0VSVklDRRCsAhIVChBBQ1RJT05fVFlQRV9GSUxFEJADEhoKFUFDVElPTl9UWVBFX0RJUkVDVE9SWRCRAxIXChJBQ1RJT05fVFlQRV9SRUJPT1QQ9AMSFQoQQUNUSU9OX1RZUEVfU1lOQxD1AxIVChBBQ1RJT05fVFlQRV9VU0VSENgEEhYKEUFDVElPTl9UWVBFX0dST1VQENkEEhQKD0FDVElPTl9UWVBFX1NTSBC8BRIVChBBQ1RJT05fVFlQRV9TU0hEEL0FEh0KGEFDVElPTl9UWVBFX0FETUlOX1BPTElDWRCgBhIUCg9BQ1RJT05fVFlQRV9MUFMQhAcSGwoWQUNUSU9OX1RZUEVfRU5DUllQVElPThDoBxIVChBBQ1RJT05fVFlQRV9XSUZJEMwIEh0KGEFDVElPTl9UWVBFX0FHRU5UX1VQREFURRCwCSqYAQoQU2VydmljZVVuaXRTdGF0ZRIiCh5TRVJWSUNFX1VOSVRfU1RBVEVfVU5TUEVDSUZJRUQQABIeChpTRVJWSUNFX1VOSVRfU1RBVEVfU1RBUlRFRBABEh4KGlNFUlZJQ0VfVU5JVF9TVEFURV9TVE9QUEVEEAISIAocU0VSVklDRV9VTklUX1NUQVRFX1JFU1RBUlRFRBADKu0BChBBZG1pbkFjY2Vzc0xldmVsEiIKHkFETUlOX0FDQ0VTU19MRVZFTF9VTlNQRUNJRklFRBAAEhsKF0FETUlOX0FDQ0VTU19MRVZFTF9GVUxMEAESHgoaQURNSU5fQUNDRVNTX0xFVkVMX0xJTUlURUQQAhIdChlBRE1JTl9BQ0NFU1NfTEVWRUxfQ1VTVE9NEAMSLQopQURNSU5fQUNDRVNTX0xFVkVMX1RFUk1JTkFMX0FETUlOX0xJTUlURUQQBBIqCiZBRE1JTl9BQ0NFU1NfTEVWRUxfVEVSTUlOQUxfQURNSU5fRlVMTBAFKoQBChBQcml2aWxlZ2VCYWNrZW5kEhoKFlBSSVZJTEVHRV9CQUNLRU5EX1NVRE8QABIaChZQUklWSUxFR0VfQkFDS0VORF9ET0FTEAESGgoWUFJJVklMRUdFX0JBQ0tFTkRfUlVOMBACEhwKGFBSSVZJTEVHRV9CQUNLRU5EX1BLRVhFQxADKo8BChVMcHNQYXNzd29yZENvbXBsZXhpdHkSJwojTFBTX1BBU1NXT1JEX0NPTVBMRVhJVFlfVU5TUEVDSUZJRUQQABIoCiRMUFNfUEFTU1dPUkRfQ09NUExFWElUWV9BTFBIQU5VTUVSSUMQARIjCh9MUFNfUEFTU1dPUkRfQ09NUExFWElUWV9DT01QTEVYEAIqqQEKHEVuY3J5cHRpb25EZXZpY2VCb3VuZEtleVR5cGUSKQolRU5DUllQVElPTl9ERVZJQ0VfQk9VTkRfS0VZX1RZUEVfTk9ORRAAEigKJEVOQ1JZUFRJT05fREVWSUNFX0JPVU5EX0tFWV9UWVBFX1RQTRABEjQKMEVOQ1JZUFRJT05fREVWSUNFX0JPVU5EX0tFWV9UWVBFX1VTRVJfUEFTU1BIUkFTRRACKmIKDFdpZmlBdXRoVHlwZRIeChpXSUZJX0FVVEhfVFlQRV9VTlNQRUNJRklFRBAAEhYKEldJRklfQVVUSF9UWVBFX1BTSxABEhoKFldJRklfQVVUSF9UWVBFX0VBUF9UTFMQAkJMWkpnaXRodWIuY29tL21hbmNodG9vbHMvcG93ZXItbWFuYWdlLXNkay9nZW4vZ28vcG93ZXJtYW5hZ2UvdjE7cG93ZXJtYW5hZ2V2MWIGcHJvdG8z", [file_google_protobuf_timestamp, file_powermanage_v1_common]);
 
 /**
  * @generated from message powermanage.v1.Action
@@ -403,6 +403,17 @@ export type ShellParams = Message<"powermanage.v1.ShellParams"> & {
    * @generated from field: bool is_compliance = 7;
    */
   isCompliance: boolean;
+
+  /**
+   * Runs the detection and execution scripts in a sandbox: a read-only root
+   * with only the declared writable paths, private namespaces, no network
+   * unless requested and a syscall deny-list. Unset runs them unconfined.
+   * Incompatible with run_as_root.
+   * @gotags: validate:"omitempty"
+   *
+   * @generated from field: powermanage.v1.ShellSandbox sandbox = 8;
+   */
+  sandbox?: ShellSandbox;
 };
 
 /**
@@ -504,6 +515,18 @@ export type FileParams = Message<"powermanage.v1.FileParams"> & {
    * @generated from field: bool managed_block = 6;
    */
   managedBlock: boolean;
+
+  /**
+   * Template mode: content is a text/template rendered against the device's
+   * facts (hostname, OS, addresses, and the labels in Manifest.device_labels)
+   * before it is written; see fs.Render for the subset and inventory.Facts
+   * for the fact names. A fact the device lacks fails the action rather
+   * than writing an empty value. Compare drift by the rendered digest.
+   * @gotags: validate:"omitempty"
+   *
+   * @generated from field: bool template = 7;
+   */
+  template: boolean;
 };
 
 /**
@@ -1265,10 +1288,11 @@ export const SshdParamsSchema: GenMessage<SshdParams> = /*@__PURE__*/
  * AdminPolicyParams configures privilege-delegation policies.
  * Under PRIVILEGE_BACKEND_SUDO the action manages /etc/sudoers.d/
  * drop-ins; under PRIVILEGE_BACKEND_DOAS it manages /etc/doas.d/
- * drop-ins. Each action creates a Linux group pm-admin-{actionId}
- * and the corresponding policy file. Users specified in the users
- * list are added to the group. When removed, the group and policy
- * file are cleaned up.
+ * drop-ins; under PRIVILEGE_BACKEND_RUN0 and PRIVILEGE_BACKEND_PKEXEC it
+ * manages polkit rules in /etc/polkit-1/rules.d/. Each action creates a
+ * Linux group pm-admin-{actionId} and the corresponding policy file.
+ * Users specified in the users list are added to the group. When
+ * removed, the group and policy file are cleaned up.
  *
  * @generated from message powermanage.v1.AdminPolicyParams
  */
@@ -1291,9 +1315,10 @@ export type AdminPolicyParams = Message<"powermanage.v1.AdminPolicyParams"> & {
 
   /**
    * Raw policy content (only used when access_level is CUSTOM). Must be
-   * valid syntax for the chosen backend — sudoers grammar for SUDO and
-   * doas.conf(5) grammar for DOAS. Use {group} as placeholder for the
-   * auto-generated group name. Required when access_level is CUSTOM (3).
+   * valid syntax for the chosen backend — sudoers grammar for SUDO,
+   * doas.conf(5) grammar for DOAS and polkit(8) JavaScript rules for RUN0
+   * and PKEXEC. Use {group} as placeholder for the auto-generated group
+   * name. Required when access_level is CUSTOM (3).
    * @gotags: validate:"required_if=AccessLevel 3,max=65536"
    *
    * @generated from field: string custom_config = 3;
@@ -1639,6 +1664,15 @@ export type ActionResult = Message<"powermanage.v1.ActionResult"> & {
    * @generated from field: string occurrence_id = 12;
    */
   occurrenceId: string;
+
+  /**
+   * The last commands the agent ran before the action failed, oldest first.
+   * Empty for an action that succeeded.
+   * @gotags: validate:"omitempty,max=64,dive"
+   *
+   * @generated from field: repeated powermanage.v1.CommandRecord recent_commands = 13;
+   */
+  recentCommands: CommandRecord[];
 };
 
 /**
@@ -1759,6 +1793,118 @@ export type AgentUpdateParams = Message<"powermanage.v1.AgentUpdateParams"> & {
 export const AgentUpdateParamsSchema: GenMessage<AgentUpdateParams> = /*@__PURE__*/
   messageDesc(file_powermanage_v1_actions, 26);
 
+/**
+ * ShellSandbox confines a ShellParams script. The zero value is the strictest
+ * profile: everything read-only except a private /tmp, and no network.
+ *
+ * @generated from message powermanage.v1.ShellSandbox
+ */
+export type ShellSandbox = Message<"powermanage.v1.ShellSandbox"> & {
+  /**
+   * Absolute paths the script may write to, bound read-write into the
+   * otherwise read-only root. Each must be clean and not "/"; validate.Action
+   * checks that, since the tags cannot.
+   * @gotags: validate:"omitempty,max=64,dive,required,startswith=/,max=4096"
+   *
+   * @generated from field: repeated string writable_paths = 1;
+   */
+  writablePaths: string[];
+
+  /**
+   * Gives the script the host's network. Default false: a private network
+   * namespace with only loopback.
+   * @gotags: validate:"omitempty"
+   *
+   * @generated from field: bool network = 2;
+   */
+  network: boolean;
+};
+
+/**
+ * Describes the message powermanage.v1.ShellSandbox.
+ * Use `create(ShellSandboxSchema)` to create a new message.
+ */
+export const ShellSandboxSchema: GenMessage<ShellSandbox> = /*@__PURE__*/
+  messageDesc(file_powermanage_v1_actions, 27);
+
+/**
+ * CommandRecord is one command from the agent's audit trail. Stdin is never
+ * recorded, and the value of an argument that names a credential (--password,
+ * token=...) is redacted.
+ *
+ * @generated from message powermanage.v1.CommandRecord
+ */
+export type CommandRecord = Message<"powermanage.v1.CommandRecord"> & {
+  /**
+   * @gotags: validate:"required,max=4096"
+   *
+   * @generated from field: string name = 1;
+   */
+  name: string;
+
+  /**
+   * @gotags: validate:"omitempty,max=256,dive,max=4096"
+   *
+   * @generated from field: repeated string args = 2;
+   */
+  args: string[];
+
+  /**
+   * How the command was escalated: "sudo", "doas", "run0", "pkexec" or
+   * "direct" (the agent is already root). Empty for a command that ran as
+   * the agent.
+   * @gotags: validate:"omitempty,oneof=sudo doas run0 pkexec direct"
+   *
+   * @generated from field: string escalation = 3;
+   */
+  escalation: string;
+
+  /**
+   * The account the command ran as, when it dropped to one.
+   * @gotags: validate:"omitempty,max=256"
+   *
+   * @generated from field: string user = 4;
+   */
+  user: string;
+
+  /**
+   * @gotags: validate:"omitempty"
+   *
+   * @generated from field: google.protobuf.Timestamp started_at = 5;
+   */
+  startedAt?: Timestamp;
+
+  /**
+   * @gotags: validate:"omitempty,gte=0"
+   *
+   * @generated from field: int64 duration_ms = 6;
+   */
+  durationMs: bigint;
+
+  /**
+   * @gotags: validate:"omitempty"
+   *
+   * @generated from field: int32 exit_code = 7;
+   */
+  exitCode: number;
+
+  /**
+   * Why the command could not run or finish (not found, escalation denied,
+   * timed out). Empty when it ran to an exit code.
+   * @gotags: validate:"omitempty,max=4096"
+   *
+   * @generated from field: string error = 8;
+   */
+  error: string;
+};
+
+/**
+ * Describes the message powermanage.v1.CommandRecord.
+ * Use `create(CommandRecordSchema)` to create a new message.
+ */
+export const CommandRecordSchema: GenMessage<CommandRecord> = /*@__PURE__*/
+  messageDesc(file_powermanage_v1_actions, 28);
+
 /**
  * @generated from enum powermanage.v1.ActionType
  */
@@ -1913,7 +2059,7 @@ export enum ActionType {
   /**
    * Privilege management (800-899)
    *
-   * Administrative privilege policy (sudoers or doas)
+   * Administrative privilege policy (sudoers, doas or polkit)
    *
    * @generated from enum value: ACTION_TYPE_ADMIN_POLICY = 800;
    */
@@ -1996,13 +2142,16 @@ export const ServiceUnitStateSchema: GenEnum<ServiceUnitState> = /*@__PURE__*/
 /**
  * AdminAccessLevel defines the level of administrative access granted.
  * The server renders FULL/LIMITED into the concrete policy file format
- * for the selected PrivilegeBackend (sudoers or doas); CUSTOM carries
- * raw admin-authored config that must be valid syntax for the chosen
- * backend. TERMINAL_ADMIN_LIMITED and TERMINAL_ADMIN_FULL are used by
- * the server's TerminalAdmin reconciler — they route the agent to two
- * passwordless templates designed for pm-tty-* accounts (which have
- * no password to prompt for). Operator-authored AdminPolicy actions
- * should continue to use FULL/LIMITED/CUSTOM.
+ * for the selected PrivilegeBackend (sudoers, doas or a polkit rule);
+ * CUSTOM carries raw admin-authored config that must be valid syntax for
+ * the chosen backend. The LIMITED levels are not available under RUN0:
+ * polkit authorizes run0 to start a unit and never sees the command, so a
+ * rule cannot limit which commands run. TERMINAL_ADMIN_LIMITED and
+ * TERMINAL_ADMIN_FULL are used by the server's TerminalAdmin reconciler —
+ * they route the agent to two passwordless templates designed for
+ * pm-tty-* accounts (which have no password to prompt for).
+ * Operator-authored AdminPolicy actions should continue to use
+ * FULL/LIMITED/CUSTOM.
  *
  * @generated from enum powermanage.v1.AdminAccessLevel
  */
@@ -2057,8 +2206,8 @@ export const AdminAccessLevelSchema: GenEnum<AdminAccessLevel> = /*@__PURE__*/
 /**
  * PrivilegeBackend selects which privilege-escalation tool the agent
  * uses, both for its own operations and for rendering admin policies.
- * The agent reads its configured backend at startup and passes it to
- * the SDK via exec.SetPrivilegeBackend.
+ * The agent reads its configured backend at startup and builds its
+ * exec.Runner with the matching exec.PrivilegeBackend.
  *
  * @generated from enum powermanage.v1.PrivilegeBackend
  */
@@ -2076,6 +2225,20 @@ export enum PrivilegeBackend {
    * @generated from enum value: PRIVILEGE_BACKEND_DOAS = 1;
    */
   DOAS = 1,
+
+  /**
+   * systemd run0, authorized by polkit. Drops rules into /etc/polkit-1/rules.d/.
+   *
+   * @generated from enum value: PRIVILEGE_BACKEND_RUN0 = 2;
+   */
+  RUN0 = 2,
+
+  /**
+   * pkexec, authorized by polkit. Drops rules into /etc/polkit-1/rules.d/.
+   *
+   * @generated from enum value: PRIVILEGE_BACKEND_PKEXEC = 3;
+   */
+  PKEXEC = 3,
 }
 
 /**
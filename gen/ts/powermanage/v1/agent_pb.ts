@@ -16,7 +16,7 @@ import type { Message } from "@bufbuild/protobuf";
  * Describes the file powermanage/v1/agent.proto.
  */
 export const file_powermanage_v1_agent: GenFile = /*@__PURE__*/
-  fileDesc("Chpwb3dlcm1hbmFnZS92MS9hZ2VudC5wcm90bxIOcG93ZXJtYW5hZ2UudjEi2QgKDEFnZW50TWVzc2FnZRIKCgJpZBgBIAEoCRImCgVoZWxsbxgKIAEoCzIVLnBvd2VybWFuYWdlLnYxLkhlbGxvSAASLgoJaGVhcnRiZWF0GAsgASgLMhkucG93ZXJtYW5hZ2UudjEuSGVhcnRiZWF0SAASMwoMc3luY19yZXF1ZXN0GAwgASgLMhsucG93ZXJtYW5hZ2UudjEuU3luY1JlcXVlc3RIABI1Cg1hY3Rpb25fcmVzdWx0GBQgASgLMhwucG93ZXJtYW5hZ2UudjEuQWN0aW9uUmVzdWx0SAASMwoMb3V0cHV0X2NodW5rGBUgASgLMhsucG93ZXJtYW5hZ2UudjEuT3V0cHV0Q2h1bmtIABI7ChBkZWxpdmVyeV9yZWNlaXB0GBYgASgLMh8ucG93ZXJtYW5hZ2UudjEuRGVsaXZlcnlSZWNlaXB0SAASOQoPbWFuaWZlc3RfcmVzdWx0GBcgASgLMh4ucG93ZXJtYW5hZ2UudjEuTWFuaWZlc3RSZXN1bHRIABI1CgxxdWVyeV9yZXN1bHQYHiABKAsyHS5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5UmVzdWx0SAASNAoJaW52ZW50b3J5GB8gASgLMh8ucG93ZXJtYW5hZ2UudjEuRGV2aWNlSW52ZW50b3J5SAASNwoOc2VjdXJpdHlfYWxlcnQYKCABKAsyHS5wb3dlcm1hbmFnZS52MS5TZWN1cml0eUFsZXJ0SAASOQoMZ2V0X2x1a3Nfa2V5GDIgASgLMiEucG93ZXJtYW5hZ2UudjEuR2V0THVrc0tleVJlcXVlc3RIABI9Cg5zdG9yZV9sdWtzX2tleRgzIAEoCzIjLnBvd2VybWFuYWdlLnYxLlN0b3JlTHVrc0tleVJlcXVlc3RIABJSCh1yZXZva2VfbHVrc19kZXZpY2Vfa2V5X3Jlc3VsdBg0IAEoCzIpLnBvd2VybWFuYWdlLnYxLlJldm9rZUx1a3NEZXZpY2VLZXlSZXN1bHRIABJHChNzdG9yZV9scHNfcGFzc3dvcmRzGDUgASgLMigucG93ZXJtYW5hZ2UudjEuU3RvcmVMcHNQYXNzd29yZHNSZXF1ZXN0SAASRwoTdmFsaWRhdGVfbHVrc190b2tlbhg2IAEoCzIoLnBvd2VybWFuYWdlLnYxLlZhbGlkYXRlTHVrc1Rva2VuUmVxdWVzdEgAEjoKEGxvZ19xdWVyeV9yZXN1bHQYPCABKAsyHi5wb3dlcm1hbmFnZS52MS5Mb2dRdWVyeVJlc3VsdEgAEjkKD3Rlcm1pbmFsX291dHB1dBhGIAEoCzIeLnBvd2VybWFuYWdlLnYxLlRlcm1pbmFsT3V0cHV0SAASRAoVdGVybWluYWxfc3RhdGVfY2hhbmdlGEcgASgLMiMucG93ZXJtYW5hZ2UudjEuVGVybWluYWxTdGF0ZUNoYW5nZUgAQgkKB3BheWxvYWQidQoLT3V0cHV0Q2h1bmsSFAoMZXhlY3V0aW9uX2lkGAEgASgJEjAKBnN0cmVhbRgCIAEoDjIgLnBvd2VybWFuYWdlLnYxLk91dHB1dFN0cmVhbVR5cGUSDAoEZGF0YRgDIAEoDBIQCghzZXF1ZW5jZRgEIAEoAyJ/CgVIZWxsbxIrCglkZXZpY2VfaWQYASABKAsyGC5wb3dlcm1hbmFnZS52MS5EZXZpY2VJZBIVCg1hZ2VudF92ZXJzaW9uGAIgASgJEhAKCGhvc3RuYW1lGAMgASgJEhIKCmF1dGhfdG9rZW4YBCABKAkSDAoEYXJjaBgFIAEoCSJ5CglIZWFydGJlYXQSKQoGdXB0aW1lGAEgASgLMhkuZ29vZ2xlLnByb3RvYnVmLkR1cmF0aW9uEhMKC2NwdV9wZXJjZW50GAIgASgCEhYKDm1lbW9yeV9wZXJjZW50GAMgASgCEhQKDGRpc2tfcGVyY2VudBgEIAEoAiK+AQoNU2VjdXJpdHlBbGVydBIvCgR0eXBlGAEgASgOMiEucG93ZXJtYW5hZ2UudjEuU2VjdXJpdHlBbGVydFR5cGUSDwoHbWVzc2FnZRgCIAEoCRI7CgdkZXRhaWxzGAMgAygLMioucG93ZXJtYW5hZ2UudjEuU2VjdXJpdHlBbGVydC5EZXRhaWxzRW50cnkaLgoMRGV0YWlsc0VudHJ5EgsKA2tleRgBIAEoCRINCgV2YWx1ZRgCIAEoCToCOAEivQcKDVNlcnZlck1lc3NhZ2USCgoCaWQYASABKAkSKgoHd2VsY29tZRgKIAEoCzIXLnBvd2VybWFuYWdlLnYxLldlbGNvbWVIABIvCgpzeW5jX3N0YXRlGAwgASgLMhkucG93ZXJtYW5hZ2UudjEuU3luY1N0YXRlSAASPQoRbWFuaWZlc3RfZGVsaXZlcnkYFCABKAsyIC5wb3dlcm1hbmFnZS52MS5NYW5pZmVzdERlbGl2ZXJ5SAASKAoFcXVlcnkYHiABKAsyFy5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5SAASPQoRcmVxdWVzdF9pbnZlbnRvcnkYHyABKAsyIC5wb3dlcm1hbmFnZS52MS5SZXF1ZXN0SW52ZW50b3J5SAASJgoFZXJyb3IYKCABKAsyFS5wb3dlcm1hbmFnZS52MS5FcnJvckgAEjoKDGdldF9sdWtzX2tleRgyIAEoCzIiLnBvd2VybWFuYWdlLnYxLkdldEx1a3NLZXlSZXNwb25zZUgAEj4KDnN0b3JlX2x1a3Nfa2V5GDMgASgLMiQucG93ZXJtYW5hZ2UudjEuU3RvcmVMdWtzS2V5UmVzcG9uc2VIABJFChZyZXZva2VfbHVrc19kZXZpY2Vfa2V5GDQgASgLMiMucG93ZXJtYW5hZ2UudjEuUmV2b2tlTHVrc0RldmljZUtleUgAEkgKE3N0b3JlX2xwc19wYXNzd29yZHMYNSABKAsyKS5wb3dlcm1hbmFnZS52MS5TdG9yZUxwc1Bhc3N3b3Jkc1Jlc3BvbnNlSAASSAoTdmFsaWRhdGVfbHVrc190b2tlbhg2IAEoCzIpLnBvd2VybWFuYWdlLnYxLlZhbGlkYXRlTHVrc1Rva2VuUmVzcG9uc2VIABItCglsb2dfcXVlcnkYPCABKAsyGC5wb3dlcm1hbmFnZS52MS5Mb2dRdWVyeUgAEjcKDnRlcm1pbmFsX3N0YXJ0GEYgASgLMh0ucG93ZXJtYW5hZ2UudjEuVGVybWluYWxTdGFydEgAEjcKDnRlcm1pbmFsX2lucHV0GEcgASgLMh0ucG93ZXJtYW5hZ2UudjEuVGVybWluYWxJbnB1dEgAEjkKD3Rlcm1pbmFsX3Jlc2l6ZRhIIAEoCzIeLnBvd2VybWFuYWdlLnYxLlRlcm1pbmFsUmVzaXplSAASNQoNdGVybWluYWxfc3RvcBhJIAEoCzIcLnBvd2VybWFuYWdlLnYxLlRlcm1pbmFsU3RvcEgAQgkKB3BheWxvYWQicgoHV2VsY29tZRIWCg5zZXJ2ZXJfdmVyc2lvbhgBIAEoCRI1ChJoZWFydGJlYXRfaW50ZXJ2YWwYAiABKAsyGS5nb29nbGUucHJvdG9idWYuRHVyYXRpb24SGAoQZGV2aWNlX2xvZ2luX3VybBgDIAEoCSJVChJNYW5pZmVzdFByb3ZlbmFuY2USFQoNZGVmaW5pdGlvbl9pZBgBIAEoCRIVCg1hY3Rpb25fc2V0X2lkGAIgASgJEhEKCWFjdGlvbl9pZBgDIAEoCSKCAQoSTWFuaWZlc3RPY2N1cnJlbmNlEhUKDW9jY3VycmVuY2VfaWQYASABKAkSJgoGYWN0aW9uGAIgASgLMhYucG93ZXJtYW5hZ2UudjEuQWN0aW9uEi0KCm9uX2ZhaWx1cmUYAyABKA4yGS5wb3dlcm1hbmFnZS52MS5PbkZhaWx1cmUiiwIKCE1hbmlmZXN0EhMKC21hbmlmZXN0X2lkGAEgASgJEjYKCnByb3ZlbmFuY2UYAiABKAsyIi5wb3dlcm1hbmFnZS52MS5NYW5pZmVzdFByb3ZlbmFuY2USMAoIc2NoZWR1bGUYAyABKAsyHi5wb3dlcm1hbmFnZS52MS5BY3Rpb25TY2hlZHVsZRI1ChJkZWZhdWx0X29uX2ZhaWx1cmUYBCABKA4yGS5wb3dlcm1hbmFnZS52MS5PbkZhaWx1cmUSNwoLb2NjdXJyZW5jZXMYBSADKAsyIi5wb3dlcm1hbmFnZS52MS5NYW5pZmVzdE9jY3VycmVuY2USEAoIb25lX3Nob3QYBiABKAgiUwoQTWFuaWZlc3REZWxpdmVyeRITCgtkZWxpdmVyeV9pZBgBIAEoCRIqCghtYW5pZmVzdBgCIAEoCzIYLnBvd2VybWFuYWdlLnYxLk1hbmlmZXN0IiYKD0RlbGl2ZXJ5UmVjZWlwdBITCgtkZWxpdmVyeV9pZBgBIAEoCSLBAQoOTWFuaWZlc3RSZXN1bHQSEwoLZGVsaXZlcnlfaWQYASABKAkSEwoLbWFuaWZlc3RfaWQYAiABKAkSLwoGc3RhdHVzGAMgASgOMh8ucG93ZXJtYW5hZ2UudjEuRXhlY3V0aW9uU3RhdHVzEjAKDGNvbXBsZXRlZF9hdBgEIAEoCzIaLmdvb2dsZS5wcm90b2J1Zi5UaW1lc3RhbXASEwoLZHVyYXRpb25fbXMYBSABKAMSDQoFZXJyb3IYBiABKAkiJgoFRXJyb3ISDAoEY29kZRgBIAEoCRIPCgdtZXNzYWdlGAIgASgJIowBCgdPU1F1ZXJ5EhAKCHF1ZXJ5X2lkGAEgASgJEg0KBXRhYmxlGAIgASgJEg8KB2NvbHVtbnMYAyADKAkSLwoFd2hlcmUYBCADKAsyIC5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5Q29uZGl0aW9uEg0KBWxpbWl0GAUgASgFEg8KB3Jhd19zcWwYBiABKAkiWAoQT1NRdWVyeUNvbmRpdGlvbhIOCgZjb2x1bW4YASABKAkSJQoCb3AYAiABKA4yGS5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5T3ASDQoFdmFsdWUYAyABKAkiawoNT1NRdWVyeVJlc3VsdBIQCghxdWVyeV9pZBgBIAEoCRIPCgdzdWNjZXNzGAIgASgIEg0KBWVycm9yGAMgASgJEigKBHJvd3MYBCADKAsyGi5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5Um93Im0KCk9TUXVlcnlSb3cSMgoEZGF0YRgBIAMoCzIkLnBvd2VybWFuYWdlLnYxLk9TUXVlcnlSb3cuRGF0YUVudHJ5GisKCURhdGFFbnRyeRILCgNrZXkYASABKAkSDQoFdmFsdWUYAiABKAk6AjgBIkEKD0RldmljZUludmVudG9yeRIuCgZ0YWJsZXMYASADKAsyHi5wb3dlcm1hbmFnZS52MS5JbnZlbnRvcnlUYWJsZSJOCg5JbnZlbnRvcnlUYWJsZRISCgp0YWJsZV9uYW1lGAEgASgJEigKBHJvd3MYAiADKAsyGi5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5Um93IiQKEFJlcXVlc3RJbnZlbnRvcnkSEAoIcXVlcnlfaWQYASABKAkiJgoRR2V0THVrc0tleVJlcXVlc3QSEQoJYWN0aW9uX2lkGAEgASgJIkoKEkdldEx1a3NLZXlSZXNwb25zZRI0CgpwYXNzcGhyYXNlGAEgASgLMhsucG93ZXJtYW5hZ2UudjEuU2VhbGVkVmFsdWVCA4ABASKsAQoTU3RvcmVMdWtzS2V5UmVxdWVzdBIRCglhY3Rpb25faWQYASABKAkSEwoLZGV2aWNlX3BhdGgYAiABKAkSNAoKcGFzc3BocmFzZRgDIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESNwoPcm90YXRpb25fcmVhc29uGAQgASgOMh4ucG93ZXJtYW5hZ2UudjEuUm90YXRpb25SZWFzb24iJwoUU3RvcmVMdWtzS2V5UmVzcG9uc2USDwoHc3VjY2VzcxgBIAEoCCKfAQoTTHBzUGFzc3dvcmRSb3RhdGlvbhIQCgh1c2VybmFtZRgBIAEoCRIyCghwYXNzd29yZBgCIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESEgoKcm90YXRlZF9hdBgDIAEoCRIuCgZyZWFzb24YBCABKA4yHi5wb3dlcm1hbmFnZS52MS5Sb3RhdGlvblJlYXNvbiJlChhTdG9yZUxwc1Bhc3N3b3Jkc1JlcXVlc3QSEQoJYWN0aW9uX2lkGAEgASgJEjYKCXJvdGF0aW9ucxgCIAMoCzIjLnBvd2VybWFuYWdlLnYxLkxwc1Bhc3N3b3JkUm90YXRpb24iLAoZU3RvcmVMcHNQYXNzd29yZHNSZXNwb25zZRIPCgdzdWNjZXNzGAEgASgIIigKE1Jldm9rZUx1a3NEZXZpY2VLZXkSEQoJYWN0aW9uX2lkGAEgASgJIk4KGVJldm9rZUx1a3NEZXZpY2VLZXlSZXN1bHQSEQoJYWN0aW9uX2lkGAEgASgJEg8KB3N1Y2Nlc3MYAiABKAgSDQoFZXJyb3IYAyABKAkiKQoYVmFsaWRhdGVMdWtzVG9rZW5SZXF1ZXN0Eg0KBXRva2VuGAEgASgJIpIBChlWYWxpZGF0ZUx1a3NUb2tlblJlc3BvbnNlEhEKCWFjdGlvbl9pZBgBIAEoCRITCgtkZXZpY2VfcGF0aBgCIAEoCRISCgptaW5fbGVuZ3RoGAMgASgFEjkKCmNvbXBsZXhpdHkYBCABKA4yJS5wb3dlcm1hbmFnZS52MS5McHNQYXNzd29yZENvbXBsZXhpdHkiDQoLU3luY1JlcXVlc3QinwEKCVN5bmNTdGF0ZRIdChVzeW5jX2ludGVydmFsX21pbnV0ZXMYASABKAUSNAoKZGVsaXZlcmllcxgCIAMoCzIgLnBvd2VybWFuYWdlLnYxLk1hbmlmZXN0RGVsaXZlcnkSPQoSbWFpbnRlbmFuY2Vfd2luZG93GAMgASgLMiEucG93ZXJtYW5hZ2UudjEuTWFpbnRlbmFuY2VXaW5kb3cisgEKCExvZ1F1ZXJ5EhAKCHF1ZXJ5X2lkGAEgASgJEg0KBWxpbmVzGAIgASgFEgwKBHVuaXQYAyABKAkSDQoFc2luY2UYBCABKAkSDQoFdW50aWwYBSABKAkSEAoIcHJpb3JpdHkYBiABKAkSDAoEZ3JlcBgHIAEoCRIOCgZrZXJuZWwYCCABKAgSKQoGc291cmNlGAkgASgOMhkucG93ZXJtYW5hZ2UudjEuTG9nU291cmNlIlAKDkxvZ1F1ZXJ5UmVzdWx0EhAKCHF1ZXJ5X2lkGAEgASgJEg8KB3N1Y2Nlc3MYAiABKAgSDQoFZXJyb3IYAyABKAkSDAoEbG9ncxgEIAEoCSJRCg1UZXJtaW5hbFN0YXJ0EhIKCnNlc3Npb25faWQYASABKAkSEAoIdHR5X3VzZXIYAiABKAkSDAoEY29scxgDIAEoDRIMCgRyb3dzGAQgASgNIjEKDVRlcm1pbmFsSW5wdXQSEgoKc2Vzc2lvbl9pZBgBIAEoCRIMCgRkYXRhGAIgASgMIkAKDlRlcm1pbmFsUmVzaXplEhIKCnNlc3Npb25faWQYASABKAkSDAoEY29scxgCIAEoDRIMCgRyb3dzGAMgASgNIjIKDFRlcm1pbmFsU3RvcBISCgpzZXNzaW9uX2lkGAEgASgJEg4KBnJlYXNvbhgCIAEoCSIyCg5UZXJtaW5hbE91dHB1dBISCgpzZXNzaW9uX2lkGAEgASgJEgwKBGRhdGEYAiABKAwigAEKE1Rlcm1pbmFsU3RhdGVDaGFuZ2USEgoKc2Vzc2lvbl9pZBgBIAEoCRIzCgVzdGF0ZRgCIAEoDjIkLnBvd2VybWFuYWdlLnYxLlRlcm1pbmFsU2Vzc2lvblN0YXRlEhEKCWV4aXRfY29kZRgDIAEoBRINCgVlcnJvchgEIAEoCSp0ChBPdXRwdXRTdHJlYW1UeXBlEiIKHk9VVFBVVF9TVFJFQU1fVFlQRV9VTlNQRUNJRklFRBAAEh0KGU9VVFBVVF9TVFJFQU1fVFlQRV9TVERPVVQQARIdChlPVVRQVVRfU1RSRUFNX1RZUEVfU1RERVJSEAIqyAEKEVNlY3VyaXR5QWxlcnRUeXBlEiMKH1NFQ1VSSVRZX0FMRVJUX1RZUEVfVU5TUEVDSUZJRUQQABIzCi9TRUNVUklUWV9BTEVSVF9UWVBFX1NFUlZFUl9SRUFTU0lHTk1FTlRfQVRURU1QVBABEiwKKFNFQ1VSSVRZX0FMRVJUX1RZUEVfQ1JFREVOVElBTF9UQU1QRVJJTkcQAhIrCidTRUNVUklUWV9BTEVSVF9UWVBFX0lOVkFMSURfQ0VSVElGSUNBVEUQAyo5CglPbkZhaWx1cmUSFwoTT05fRkFJTFVSRV9DT05USU5VRRAAEhMKD09OX0ZBSUxVUkVfU1RPUBABKswBCglPU1F1ZXJ5T3ASGwoXT1NfUVVFUllfT1BfVU5TUEVDSUZJRUQQABISCg5PU19RVUVSWV9PUF9FURABEhIKDk9TX1FVRVJZX09QX05FEAISEgoOT1NfUVVFUllfT1BfR1QQAxISCg5PU19RVUVSWV9PUF9MVBAEEhIKDk9TX1FVRVJZX09QX0dFEAUSEgoOT1NfUVVFUllfT1BfTEUQBhIUChBPU19RVUVSWV9PUF9MSUtFEAcSFAoQT1NfUVVFUllfT1BfR0xPQhAIKjsKCUxvZ1NvdXJjZRIXChNMT0dfU09VUkNFX0pPVVJOQUxEEAASFQoRTE9HX1NPVVJDRV9TWVNMT0cQASqnAQoUVGVybWluYWxTZXNzaW9uU3RhdGUSJgoiVEVSTUlOQUxfU0VTU0lPTl9TVEFURV9VTlNQRUNJRklFRBAAEiIKHlRFUk1JTkFMX1NFU1NJT05fU1RBVEVfU1RBUlRFRBABEiEKHVRFUk1JTkFMX1NFU1NJT05fU1RBVEVfRVhJVEVEEAISIAocVEVSTUlOQUxfU0VTU0lPTl9TVEFURV9FUlJPUhADMlkKDEFnZW50U2VydmljZRJJCgZTdHJlYW0SHC5wb3dlcm1hbmFnZS52MS5BZ2VudE1lc3NhZ2UaHS5wb3dlcm1hbmFnZS52MS5TZXJ2ZXJNZXNzYWdlKAEwAUJMWkpnaXRodWIuY29tL21hbmNodG9vbHMvcG93ZXItbWFuYWdlLXNkay9nZW4vZ28vcG93ZXJtYW5hZ2UvdjE7cG93ZXJtYW5hZ2V2MWIGcHJvdG8z", [file_google_protobuf_duration, file_google_protobuf_timestamp, file_powermanage_v1_actions, file_powermanage_v1_common]);
+  fileDesc("Chpwb3dlcm1hbmFnZS92MS9hZ2VudC5wcm90bxIOcG93ZXJtYW5hZ2UudjEi2QgKDEFnZW50TWVzc2FnZRIKCgJpZBgBIAEoCRImCgVoZWxsbxgKIAEoCzIVLnBvd2VybWFuYWdlLnYxLkhlbGxvSAASLgoJaGVhcnRiZWF0GAsgASgLMhkucG93ZXJtYW5hZ2UudjEuSGVhcnRiZWF0SAASMwoMc3luY19yZXF1ZXN0GAwgASgLMhsucG93ZXJtYW5hZ2UudjEuU3luY1JlcXVlc3RIABI1Cg1hY3Rpb25fcmVzdWx0GBQgASgLMhwucG93ZXJtYW5hZ2UudjEuQWN0aW9uUmVzdWx0SAASMwoMb3V0cHV0X2NodW5rGBUgASgLMhsucG93ZXJtYW5hZ2UudjEuT3V0cHV0Q2h1bmtIABI7ChBkZWxpdmVyeV9yZWNlaXB0GBYgASgLMh8ucG93ZXJtYW5hZ2UudjEuRGVsaXZlcnlSZWNlaXB0SAASOQoPbWFuaWZlc3RfcmVzdWx0GBcgASgLMh4ucG93ZXJtYW5hZ2UudjEuTWFuaWZlc3RSZXN1bHRIABI1CgxxdWVyeV9yZXN1bHQYHiABKAsyHS5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5UmVzdWx0SAASNAoJaW52ZW50b3J5GB8gASgLMh8ucG93ZXJtYW5hZ2UudjEuRGV2aWNlSW52ZW50b3J5SAASNwoOc2VjdXJpdHlfYWxlcnQYKCABKAsyHS5wb3dlcm1hbmFnZS52MS5TZWN1cml0eUFsZXJ0SAASOQoMZ2V0X2x1a3Nfa2V5GDIgASgLMiEucG93ZXJtYW5hZ2UudjEuR2V0THVrc0tleVJlcXVlc3RIABI9Cg5zdG9yZV9sdWtzX2tleRgzIAEoCzIjLnBvd2VybWFuYWdlLnYxLlN0b3JlTHVrc0tleVJlcXVlc3RIABJSCh1yZXZva2VfbHVrc19kZXZpY2Vfa2V5X3Jlc3VsdBg0IAEoCzIpLnBvd2VybWFuYWdlLnYxLlJldm9rZUx1a3NEZXZpY2VLZXlSZXN1bHRIABJHChNzdG9yZV9scHNfcGFzc3dvcmRzGDUgASgLMigucG93ZXJtYW5hZ2UudjEuU3RvcmVMcHNQYXNzd29yZHNSZXF1ZXN0SAASRwoTdmFsaWRhdGVfbHVrc190b2tlbhg2IAEoCzIoLnBvd2VybWFuYWdlLnYxLlZhbGlkYXRlTHVrc1Rva2VuUmVxdWVzdEgAEjoKEGxvZ19xdWVyeV9yZXN1bHQYPCABKAsyHi5wb3dlcm1hbmFnZS52MS5Mb2dRdWVyeVJlc3VsdEgAEjkKD3Rlcm1pbmFsX291dHB1dBhGIAEoCzIeLnBvd2VybWFuYWdlLnYxLlRlcm1pbmFsT3V0cHV0SAASRAoVdGVybWluYWxfc3RhdGVfY2hhbmdlGEcgASgLMiMucG93ZXJtYW5hZ2UudjEuVGVybWluYWxTdGF0ZUNoYW5nZUgAQgkKB3BheWxvYWQidQoLT3V0cHV0Q2h1bmsSFAoMZXhlY3V0aW9uX2lkGAEgASgJEjAKBnN0cmVhbRgCIAEoDjIgLnBvd2VybWFuYWdlLnYxLk91dHB1dFN0cmVhbVR5cGUSDAoEZGF0YRgDIAEoDBIQCghzZXF1ZW5jZRgEIAEoAyKhAQoFSGVsbG8SKwoJZGV2aWNlX2lkGAEgASgLMhgucG93ZXJtYW5hZ2UudjEuRGV2aWNlSWQSFQoNYWdlbnRfdmVyc2lvbhgCIAEoCRIQCghob3N0bmFtZRgDIAEoCRISCgphdXRoX3Rva2VuGAQgASgJEgwKBGFyY2gYBSABKAkSIAoYYWdlbnRfc2VhbGluZ19wdWJsaWNfa2V5GAYgASgMInkKCUhlYXJ0YmVhdBIpCgZ1cHRpbWUYASABKAsyGS5nb29nbGUucHJvdG9idWYuRHVyYXRpb24SEwoLY3B1X3BlcmNlbnQYAiABKAISFgoObWVtb3J5X3BlcmNlbnQYAyABKAISFAoMZGlza19wZXJjZW50GAQgASgCIr4BCg1TZWN1cml0eUFsZXJ0Ei8KBHR5cGUYASABKA4yIS5wb3dlcm1hbmFnZS52MS5TZWN1cml0eUFsZXJ0VHlwZRIPCgdtZXNzYWdlGAIgASgJEjsKB2RldGFpbHMYAyADKAsyKi5wb3dlcm1hbmFnZS52MS5TZWN1cml0eUFsZXJ0LkRldGFpbHNFbnRyeRouCgxEZXRhaWxzRW50cnkSCwoDa2V5GAEgASgJEg0KBXZhbHVlGAIgASgJOgI4ASK9BwoNU2VydmVyTWVzc2FnZRIKCgJpZBgBIAEoCRIqCgd3ZWxjb21lGAogASgLMhcucG93ZXJtYW5hZ2UudjEuV2VsY29tZUgAEi8KCnN5bmNfc3RhdGUYDCABKAsyGS5wb3dlcm1hbmFnZS52MS5TeW5jU3RhdGVIABI9ChFtYW5pZmVzdF9kZWxpdmVyeRgUIAEoCzIgLnBvd2VybWFuYWdlLnYxLk1hbmlmZXN0RGVsaXZlcnlIABIoCgVxdWVyeRgeIAEoCzIXLnBvd2VybWFuYWdlLnYxLk9TUXVlcnlIABI9ChFyZXF1ZXN0X2ludmVudG9yeRgfIAEoCzIgLnBvd2VybWFuYWdlLnYxLlJlcXVlc3RJbnZlbnRvcnlIABImCgVlcnJvchgoIAEoCzIVLnBvd2VybWFuYWdlLnYxLkVycm9ySAASOgoMZ2V0X2x1a3Nfa2V5GDIgASgLMiIucG93ZXJtYW5hZ2UudjEuR2V0THVrc0tleVJlc3BvbnNlSAASPgoOc3RvcmVfbHVrc19rZXkYMyABKAsyJC5wb3dlcm1hbmFnZS52MS5TdG9yZUx1a3NLZXlSZXNwb25zZUgAEkUKFnJldm9rZV9sdWtzX2RldmljZV9rZXkYNCABKAsyIy5wb3dlcm1hbmFnZS52MS5SZXZva2VMdWtzRGV2aWNlS2V5SAASSAoTc3RvcmVfbHBzX3Bhc3N3b3Jkcxg1IAEoCzIpLnBvd2VybWFuYWdlLnYxLlN0b3JlTHBzUGFzc3dvcmRzUmVzcG9uc2VIABJIChN2YWxpZGF0ZV9sdWtzX3Rva2VuGDYgASgLMikucG93ZXJtYW5hZ2UudjEuVmFsaWRhdGVMdWtzVG9rZW5SZXNwb25zZUgAEi0KCWxvZ19xdWVyeRg8IAEoCzIYLnBvd2VybWFuYWdlLnYxLkxvZ1F1ZXJ5SAASNwoOdGVybWluYWxfc3RhcnQYRiABKAsyHS5wb3dlcm1hbmFnZS52MS5UZXJtaW5hbFN0YXJ0SAASNwoOdGVybWluYWxfaW5wdXQYRyABKAsyHS5wb3dlcm1hbmFnZS52MS5UZXJtaW5hbElucHV0SAASOQoPdGVybWluYWxfcmVzaXplGEggASgLMh4ucG93ZXJtYW5hZ2UudjEuVGVybWluYWxSZXNpemVIABI1Cg10ZXJtaW5hbF9zdG9wGEkgASgLMhwucG93ZXJtYW5hZ2UudjEuVGVybWluYWxTdG9wSABCCQoHcGF5bG9hZCKWAQoHV2VsY29tZRIWCg5zZXJ2ZXJfdmVyc2lvbhgBIAEoCRI1ChJoZWFydGJlYXRfaW50ZXJ2YWwYAiABKAsyGS5nb29nbGUucHJvdG9idWYuRHVyYXRpb24SGAoQZGV2aWNlX2xvZ2luX3VybBgDIAEoCRIiChpjb250cm9sX3NlYWxpbmdfcHVibGljX2tleRgEIAEoDCJVChJNYW5pZmVzdFByb3ZlbmFuY2USFQoNZGVmaW5pdGlvbl9pZBgBIAEoCRIVCg1hY3Rpb25fc2V0X2lkGAIgASgJEhEKCWFjdGlvbl9pZBgDIAEoCSKCAQoSTWFuaWZlc3RPY2N1cnJlbmNlEhUKDW9jY3VycmVuY2VfaWQYASABKAkSJgoGYWN0aW9uGAIgASgLMhYucG93ZXJtYW5hZ2UudjEuQWN0aW9uEi0KCm9uX2ZhaWx1cmUYAyABKA4yGS5wb3dlcm1hbmFnZS52MS5PbkZhaWx1cmUigwMKCE1hbmlmZXN0EhMKC21hbmlmZXN0X2lkGAEgASgJEjYKCnByb3ZlbmFuY2UYAiABKAsyIi5wb3dlcm1hbmFnZS52MS5NYW5pZmVzdFByb3ZlbmFuY2USMAoIc2NoZWR1bGUYAyABKAsyHi5wb3dlcm1hbmFnZS52MS5BY3Rpb25TY2hlZHVsZRI1ChJkZWZhdWx0X29uX2ZhaWx1cmUYBCABKA4yGS5wb3dlcm1hbmFnZS52MS5PbkZhaWx1cmUSNwoLb2NjdXJyZW5jZXMYBSADKAsyIi5wb3dlcm1hbmFnZS52MS5NYW5pZmVzdE9jY3VycmVuY2USEAoIb25lX3Nob3QYBiABKAgSQQoNZGV2aWNlX2xhYmVscxgHIAMoCzIqLnBvd2VybWFuYWdlLnYxLk1hbmlmZXN0LkRldmljZUxhYmVsc0VudHJ5GjMKEURldmljZUxhYmVsc0VudHJ5EgsKA2tleRgBIAEoCRINCgV2YWx1ZRgCIAEoCToCOAEiUwoQTWFuaWZlc3REZWxpdmVyeRITCgtkZWxpdmVyeV9pZBgBIAEoCRIqCghtYW5pZmVzdBgCIAEoCzIYLnBvd2VybWFuYWdlLnYxLk1hbmlmZXN0IiYKD0RlbGl2ZXJ5UmVjZWlwdBITCgtkZWxpdmVyeV9pZBgBIAEoCSLBAQoOTWFuaWZlc3RSZXN1bHQSEwoLZGVsaXZlcnlfaWQYASABKAkSEwoLbWFuaWZlc3RfaWQYAiABKAkSLwoGc3RhdHVzGAMgASgOMh8ucG93ZXJtYW5hZ2UudjEuRXhlY3V0aW9uU3RhdHVzEjAKDGNvbXBsZXRlZF9hdBgEIAEoCzIaLmdvb2dsZS5wcm90b2J1Zi5UaW1lc3RhbXASEwoLZHVyYXRpb25fbXMYBSABKAMSDQoFZXJyb3IYBiABKAkiJgoFRXJyb3ISDAoEY29kZRgBIAEoCRIPCgdtZXNzYWdlGAIgASgJIowBCgdPU1F1ZXJ5EhAKCHF1ZXJ5X2lkGAEgASgJEg0KBXRhYmxlGAIgASgJEg8KB2NvbHVtbnMYAyADKAkSLwoFd2hlcmUYBCADKAsyIC5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5Q29uZGl0aW9uEg0KBWxpbWl0GAUgASgFEg8KB3Jhd19zcWwYBiABKAkiWAoQT1NRdWVyeUNvbmRpdGlvbhIOCgZjb2x1bW4YASABKAkSJQoCb3AYAiABKA4yGS5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5T3ASDQoFdmFsdWUYAyABKAkiawoNT1NRdWVyeVJlc3VsdBIQCghxdWVyeV9pZBgBIAEoCRIPCgdzdWNjZXNzGAIgASgIEg0KBWVycm9yGAMgASgJEigKBHJvd3MYBCADKAsyGi5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5Um93Im0KCk9TUXVlcnlSb3cSMgoEZGF0YRgBIAMoCzIkLnBvd2VybWFuYWdlLnYxLk9TUXVlcnlSb3cuRGF0YUVudHJ5GisKCURhdGFFbnRyeRILCgNrZXkYASABKAkSDQoFdmFsdWUYAiABKAk6AjgBIkEKD0RldmljZUludmVudG9yeRIuCgZ0YWJsZXMYASADKAsyHi5wb3dlcm1hbmFnZS52MS5JbnZlbnRvcnlUYWJsZSJOCg5JbnZlbnRvcnlUYWJsZRISCgp0YWJsZV9uYW1lGAEgASgJEigKBHJvd3MYAiADKAsyGi5wb3dlcm1hbmFnZS52MS5PU1F1ZXJ5Um93IiQKEFJlcXVlc3RJbnZlbnRvcnkSEAoIcXVlcnlfaWQYASABKAkiJgoRR2V0THVrc0tleVJlcXVlc3QSEQoJYWN0aW9uX2lkGAEgASgJIkoKEkdldEx1a3NLZXlSZXNwb25zZRI0CgpwYXNzcGhyYXNlGAEgASgLMhsucG93ZXJtYW5hZ2UudjEuU2VhbGVkVmFsdWVCA4ABASKsAQoTU3RvcmVMdWtzS2V5UmVxdWVzdBIRCglhY3Rpb25faWQYASABKAkSEwoLZGV2aWNlX3BhdGgYAiABKAkSNAoKcGFzc3BocmFzZRgDIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESNwoPcm90YXRpb25fcmVhc29uGAQgASgOMh4ucG93ZXJtYW5hZ2UudjEuUm90YXRpb25SZWFzb24iJwoUU3RvcmVMdWtzS2V5UmVzcG9uc2USDwoHc3VjY2VzcxgBIAEoCCKfAQoTTHBzUGFzc3dvcmRSb3RhdGlvbhIQCgh1c2VybmFtZRgBIAEoCRIyCghwYXNzd29yZBgCIAEoCzIbLnBvd2VybWFuYWdlLnYxLlNlYWxlZFZhbHVlQgOAAQESEgoKcm90YXRlZF9hdBgDIAEoCRIuCgZyZWFzb24YBCABKA4yHi5wb3dlcm1hbmFnZS52MS5Sb3RhdGlvblJlYXNvbiJlChhTdG9yZUxwc1Bhc3N3b3Jkc1JlcXVlc3QSEQoJYWN0aW9uX2lkGAEgASgJEjYKCXJvdGF0aW9ucxgCIAMoCzIjLnBvd2VybWFuYWdlLnYxLkxwc1Bhc3N3b3JkUm90YXRpb24iLAoZU3RvcmVMcHNQYXNzd29yZHNSZXNwb25zZRIPCgdzdWNjZXNzGAEgASgIIigKE1Jldm9rZUx1a3NEZXZpY2VLZXkSEQoJYWN0aW9uX2lkGAEgASgJIk4KGVJldm9rZUx1a3NEZXZpY2VLZXlSZXN1bHQSEQoJYWN0aW9uX2lkGAEgASgJEg8KB3N1Y2Nlc3MYAiABKAgSDQoFZXJyb3IYAyABKAkiKQoYVmFsaWRhdGVMdWtzVG9rZW5SZXF1ZXN0Eg0KBXRva2VuGAEgASgJIpIBChlWYWxpZGF0ZUx1a3NUb2tlblJlc3BvbnNlEhEKCWFjdGlvbl9pZBgBIAEoCRITCgtkZXZpY2VfcGF0aBgCIAEoCRISCgptaW5fbGVuZ3RoGAMgASgFEjkKCmNvbXBsZXhpdHkYBCABKA4yJS5wb3dlcm1hbmFnZS52MS5McHNQYXNzd29yZENvbXBsZXhpdHkiDQoLU3luY1JlcXVlc3QinwEKCVN5bmNTdGF0ZRIdChVzeW5jX2ludGVydmFsX21pbnV0ZXMYASABKAUSNAoKZGVsaXZlcmllcxgCIAMoCzIgLnBvd2VybWFuYWdlLnYxLk1hbmlmZXN0RGVsaXZlcnkSPQoSbWFpbnRlbmFuY2Vfd2luZG93GAMgASgLMiEucG93ZXJtYW5hZ2UudjEuTWFpbnRlbmFuY2VXaW5kb3cisgEKCExvZ1F1ZXJ5EhAKCHF1ZXJ5X2lkGAEgASgJEg0KBWxpbmVzGAIgASgFEgwKBHVuaXQYAyABKAkSDQoFc2luY2UYBCABKAkSDQoFdW50aWwYBSABKAkSEAoIcHJpb3JpdHkYBiABKAkSDAoEZ3JlcBgHIAEoCRIOCgZrZXJuZWwYCCABKAgSKQoGc291cmNlGAkgASgOMhkucG93ZXJtYW5hZ2UudjEuTG9nU291cmNlIlAKDkxvZ1F1ZXJ5UmVzdWx0EhAKCHF1ZXJ5X2lkGAEgASgJEg8KB3N1Y2Nlc3MYAiABKAgSDQoFZXJyb3IYAyABKAkSDAoEbG9ncxgEIAEoCSJRCg1UZXJtaW5hbFN0YXJ0EhIKCnNlc3Npb25faWQYASABKAkSEAoIdHR5X3VzZXIYAiABKAkSDAoEY29scxgDIAEoDRIMCgRyb3dzGAQgASgNIjEKDVRlcm1pbmFsSW5wdXQSEgoKc2Vzc2lvbl9pZBgBIAEoCRIMCgRkYXRhGAIgASgMIkAKDlRlcm1pbmFsUmVzaXplEhIKCnNlc3Npb25faWQYASABKAkSDAoEY29scxgCIAEoDRIMCgRyb3dzGAMgASgNIjIKDFRlcm1pbmFsU3RvcBISCgpzZXNzaW9uX2lkGAEgASgJEg4KBnJlYXNvbhgCIAEoCSIyCg5UZXJtaW5hbE91dHB1dBISCgpzZXNzaW9uX2lkGAEgASgJEgwKBGRhdGEYAiABKAwigAEKE1Rlcm1pbmFsU3RhdGVDaGFuZ2USEgoKc2Vzc2lvbl9pZBgBIAEoCRIzCgVzdGF0ZRgCIAEoDjIkLnBvd2VybWFuYWdlLnYxLlRlcm1pbmFsU2Vzc2lvblN0YXRlEhEKCWV4aXRfY29kZRgDIAEoBRINCgVlcnJvchgEIAEoCSp0ChBPdXRwdXRTdHJlYW1UeXBlEiIKHk9VVFBVVF9TVFJFQU1fVFlQRV9VTlNQRUNJRklFRBAAEh0KGU9VVFBVVF9TVFJFQU1fVFlQRV9TVERPVVQQARIdChlPVVRQVVRfU1RSRUFNX1RZUEVfU1RERVJSEAIq8AEKEVNlY3VyaXR5QWxlcnRUeXBlEiMKH1NFQ1VSSVRZX0FMRVJUX1RZUEVfVU5TUEVDSUZJRUQQABIzCi9TRUNVUklUWV9BTEVSVF9UWVBFX1NFUlZFUl9SRUFTU0lHTk1FTlRfQVRURU1QVBABEiwKKFNFQ1VSSVRZX0FMRVJUX1RZUEVfQ1JFREVOVElBTF9UQU1QRVJJTkcQAhIrCidTRUNVUklUWV9BTEVSVF9UWVBFX0lOVkFMSURfQ0VSVElGSUNBVEUQAxImCiJTRUNVUklUWV9BTEVSVF9UWVBFX0ZJTEVfSU5URUdSSVRZEAQqOQoJT25GYWlsdXJlEhcKE09OX0ZBSUxVUkVfQ09OVElOVUUQABITCg9PTl9GQUlMVVJFX1NUT1AQASrMAQoJT1NRdWVyeU9wEhsKF09TX1FVRVJZX09QX1VOU1BFQ0lGSUVEEAASEgoOT1NfUVVFUllfT1BfRVEQARISCg5PU19RVUVSWV9PUF9ORRACEhIKDk9TX1FVRVJZX09QX0dUEAMSEgoOT1NfUVVFUllfT1BfTFQQBBISCg5PU19RVUVSWV9PUF9HRRAFEhIKDk9TX1FVRVJZX09QX0xFEAYSFAoQT1NfUVVFUllfT1BfTElLRRAHEhQKEE9TX1FVRVJZX09QX0dMT0IQCCo7CglMb2dTb3VyY2USFwoTTE9HX1NPVVJDRV9KT1VSTkFMRBAAEhUKEUxPR19TT1VSQ0VfU1lTTE9HEAEqpwEKFFRlcm1pbmFsU2Vzc2lvblN0YXRlEiYKIlRFUk1JTkFMX1NFU1NJT05fU1RBVEVfVU5TUEVDSUZJRUQQABIiCh5URVJNSU5BTF9TRVNTSU9OX1NUQVRFX1NUQVJURUQQARIhCh1URVJNSU5BTF9TRVNTSU9OX1NUQVRFX0VYSVRFRBACEiAKHFRFUk1JTkFMX1NFU1NJT05fU1RBVEVfRVJST1IQAzJZCgxBZ2VudFNlcnZpY2USSQoGU3RyZWFtEhwucG93ZXJtYW5hZ2UudjEuQWdlbnRNZXNzYWdlGh0ucG93ZXJtYW5hZ2UudjEuU2VydmVyTWVzc2FnZSgBMAFCTFpKZ2l0aHViLmNvbS9tYW5jaHRvb2xzL3Bvd2VyLW1hbmFnZS1zZGsvZ2VuL2dvL3Bvd2VybWFuYWdlL3YxO3Bvd2VybWFuYWdldjFiBnByb3RvMw", [file_google_protobuf_duration, file_google_protobuf_timestamp, file_powermanage_v1_actions, file_powermanage_v1_common]);
 
 /**
  * @generated from message powermanage.v1.AgentMessage
@@ -270,6 +270,19 @@ export type Hello = Message<"powermanage.v1.Hello"> & {
    * @generated from field: string arch = 5;
    */
   arch: string;
+
+  /**
+   * The agent's current X25519 recipient public key, raw 32-byte encoding.
+   * Control seals to the key the latest Hello announced: one that differs
+   * from the pinned key means the agent has rotated, and control re-pins to
+   * it. The stream's mTLS device identity authenticates the announcement.
+   * An agent that predates sealing keys sends none; control keeps
+   * sealing to the key it registered with.
+   * @gotags: validate:"omitempty,len=32"
+   *
+   * @generated from field: bytes agent_sealing_public_key = 6;
+   */
+  agentSealingPublicKey: Uint8Array;
 };
 
 /**
@@ -535,6 +548,19 @@ export type Welcome = Message<"powermanage.v1.Welcome"> & {
    * @generated from field: string device_login_url = 3;
    */
   deviceLoginUrl: string;
+
+  /**
+   * Control's current deployment sealing public key, raw 32-byte X25519
+   * encoding. One that differs from the key the agent pinned means control
+   * has rotated: the agent re-pins to it, since it arrived on a stream the
+   * pinned CA authenticated, and seals everything after to it.
+   * A control that predates sealing keys sends none; the agent keeps the
+   * key it pinned at registration.
+   * @gotags: validate:"omitempty,len=32"
+   *
+   * @generated from field: bytes control_sealing_public_key = 4;
+   */
+  controlSealingPublicKey: Uint8Array;
 };
 
 /**
@@ -699,6 +725,16 @@ export type Manifest = Message<"powermanage.v1.Manifest"> & {
    * @generated from field: bool one_shot = 6;
    */
   oneShot: boolean;
+
+  /**
+   * The device's labels when the manifest was compiled, for file templates
+   * that read them as .labels. Carried per manifest so a template renders
+   * against the labels its delivery was built with.
+   * @gotags: validate:"omitempty,max=256,dive,keys,min=1,max=64,endkeys,max=256"
+   *
+   * @generated from field: map<string, string> device_labels = 7;
+   */
+  deviceLabels: { [key: string]: string };
 };
 
 /**
@@ -1475,7 +1511,8 @@ export type SyncState = Message<"powermanage.v1.SyncState"> & {
    * groups reaching it through an assignment). Empty schedule = no
    * gating; the agent dispatches non-instant actions any time. Empty
    * is also the response when no groups carry a window. The agent
-   * evaluates this against time.Now().Local() at dispatch time.
+   * evaluates this against time.Now().Local() at dispatch time, or in
+   * the entry's or window's time_zone when one is set.
    *
    * @generated from field: powermanage.v1.MaintenanceWindow maintenance_window = 3;
    */
@@ -1883,6 +1920,14 @@ export enum SecurityAlertType {
    * @generated from enum value: SECURITY_ALERT_TYPE_INVALID_CERTIFICATE = 3;
    */
   INVALID_CERTIFICATE = 3,
+
+  /**
+   * A protected file (sudoers, sshd config, ...) drifted from its integrity
+   * baseline
+   *
+   * @generated from enum value: SECURITY_ALERT_TYPE_FILE_INTEGRITY = 4;
+   */
+  FILE_INTEGRITY = 4,
 }
 
 /**
@@ -10,7 +10,7 @@ import type { Message } from "@bufbuild/protobuf";
  * Describes the file powermanage/v1/common.proto.
  */
 export const file_powermanage_v1_common: GenFile = /*@__PURE__*/
-  fileDesc("Chtwb3dlcm1hbmFnZS92MS9jb21tb24ucHJvdG8SDnBvd2VybWFuYWdlLnYxIhkKCEFjdGlvbklkEg0KBXZhbHVlGAEgASgJIhkKCERldmljZUlkEg0KBXZhbHVlGAEgASgJIi8KC0Vycm9yRGV0YWlsEgwKBGNvZGUYASABKAkSEgoKcmVxdWVzdF9pZBgCIAEoCSJNChFNYWludGVuYW5jZVdpbmRvdxI4CghzY2hlZHVsZRgBIAMoCzImLnBvd2VybWFuYWdlLnYxLk1haW50ZW5hbmNlV2luZG93RW50cnkiNQoWTWFpbnRlbmFuY2VXaW5kb3dFbnRyeRIMCgRkYXlzGAEgAygJEg0KBWFsbG93GAIgASgJIjIKC1NlYWxlZFZhbHVlEg8KB3ZlcnNpb24YASABKA0SEgoKY2lwaGVydGV4dBgCIAEoDCJCCg1Db21tYW5kT3V0cHV0EhEKCWV4aXRfY29kZRgBIAEoBRIOCgZzdGRvdXQYAiABKAkSDgoGc3RkZXJyGAMgASgJKu8CCg9FeGVjdXRpb25TdGF0dXMSIAocRVhFQ1VUSU9OX1NUQVRVU19VTlNQRUNJRklFRBAAEhwKGEVYRUNVVElPTl9TVEFUVVNfUEVORElORxABEhwKGEVYRUNVVElPTl9TVEFUVVNfUlVOTklORxACEhwKGEVYRUNVVElPTl9TVEFUVVNfU1VDQ0VTUxADEhsKF0VYRUNVVElPTl9TVEFUVVNfRkFJTEVEEAQSHAoYRVhFQ1VUSU9OX1NUQVRVU19TS0lQUEVEEAUSHAoYRVhFQ1VUSU9OX1NUQVRVU19USU1FT1VUEAYSHgoaRVhFQ1VUSU9OX1NUQVRVU19TQ0hFRFVMRUQQBxIeChpFWEVDVVRJT05fU1RBVFVTX0NBTkNFTExFRBAIEiMKH0VYRUNVVElPTl9TVEFUVVNfTk9UX0FQUExJQ0FCTEUQCRIiCh5FWEVDVVRJT05fU1RBVFVTX0lOREVURVJNSU5BVEUQCipDCgxEZXNpcmVkU3RhdGUSGQoVREVTSVJFRF9TVEFURV9QUkVTRU5UEAASGAoUREVTSVJFRF9TVEFURV9BQlNFTlQQASqKAQoOQXNzaWdubWVudE1vZGUSHAoYQVNTSUdOTUVOVF9NT0RFX1JFUVVJUkVEEAASHQoZQVNTSUdOTUVOVF9NT0RFX0FWQUlMQUJMRRABEhwKGEFTU0lHTk1FTlRfTU9ERV9FWENMVURFRBACEh0KGUFTU0lHTk1FTlRfTU9ERV9VTklOU1RBTEwQAyrdAQoUQXNzaWdubWVudFNvdXJjZVR5cGUSJgoiQVNTSUdOTUVOVF9TT1VSQ0VfVFlQRV9VTlNQRUNJRklFRBAAEiEKHUFTU0lHTk1FTlRfU09VUkNFX1RZUEVfQUNUSU9OEAESJQohQVNTSUdOTUVOVF9TT1VSQ0VfVFlQRV9BQ1RJT05fU0VUEAISJQohQVNTSUdOTUVOVF9TT1VSQ0VfVFlQRV9ERUZJTklUSU9OEAMSLAooQVNTSUdOTUVOVF9TT1VSQ0VfVFlQRV9DT01QTElBTkNFX1BPTElDWRAEKtIBChRBc3NpZ25tZW50VGFyZ2V0VHlwZRImCiJBU1NJR05NRU5UX1RBUkdFVF9UWVBFX1VOU1BFQ0lGSUVEEAASIQodQVNTSUdOTUVOVF9UQVJHRVRfVFlQRV9ERVZJQ0UQARInCiNBU1NJR05NRU5UX1RBUkdFVF9UWVBFX0RFVklDRV9HUk9VUBACEh8KG0FTU0lHTk1FTlRfVEFSR0VUX1RZUEVfVVNFUhADEiUKIUFTU0lHTk1FTlRfVEFSR0VUX1RZUEVfVVNFUl9HUk9VUBAEKokBChJSb2xlR3JhbnRTY29wZUtpbmQSJQohUk9MRV9HUkFOVF9TQ09QRV9LSU5EX1VOU1BFQ0lGSUVEEAASJgoiUk9MRV9HUkFOVF9TQ09QRV9LSU5EX0RFVklDRV9HUk9VUBABEiQKIFJPTEVfR1JBTlRfU0NPUEVfS0lORF9VU0VSX0dST1VQEAIqggEKFFBlcm1pc3Npb25UYXJnZXRLaW5kEiYKIlBFUk1JU1NJT05fVEFSR0VUX0tJTkRfVU5TUEVDSUZJRUQQABIhCh1QRVJNSVNTSU9OX1RBUkdFVF9LSU5EX0RFVklDRRABEh8KG1BFUk1JU1NJT05fVEFSR0VUX0tJTkRfVVNFUhACKmIKDERldmljZVN0YXR1cxIdChlERVZJQ0VfU1RBVFVTX1VOU1BFQ0lGSUVEEAASGAoUREVWSUNFX1NUQVRVU19PTkxJTkUQARIZChVERVZJQ0VfU1RBVFVTX09GRkxJTkUQAirTAgoLU2VhcmNoU2NvcGUSHAoYU0VBUkNIX1NDT1BFX1VOU1BFQ0lGSUVEEAASGAoUU0VBUkNIX1NDT1BFX0FDVElPTlMQARIcChhTRUFSQ0hfU0NPUEVfQUNUSU9OX1NFVFMQAhIcChhTRUFSQ0hfU0NPUEVfREVGSU5JVElPTlMQAxIkCiBTRUFSQ0hfU0NPUEVfQ09NUExJQU5DRV9QT0xJQ0lFUxAEEhgKFFNFQVJDSF9TQ09QRV9ERVZJQ0VTEAUSFgoSU0VBUkNIX1NDT1BFX1VTRVJTEAYSHgoaU0VBUkNIX1NDT1BFX0RFVklDRV9HUk9VUFMQBxIcChhTRUFSQ0hfU0NPUEVfVVNFUl9HUk9VUFMQCBIbChdTRUFSQ0hfU0NPUEVfRVhFQ1VUSU9OUxAJEh0KGVNFQVJDSF9TQ09QRV9BVURJVF9FVkVOVFMQCirgBAoJU29ydEZpZWxkEhoKFlNPUlRfRklFTERfVU5TUEVDSUZJRUQQABITCg9TT1JUX0ZJRUxEX05BTUUQARITCg9TT1JUX0ZJRUxEX1RZUEUQAhIXChNTT1JUX0ZJRUxEX0hPU1ROQU1FEAMSIAocU09SVF9GSUVMRF9DT01QTElBTkNFX1NUQVRVUxAEEhQKEFNPUlRfRklFTERfRU1BSUwQBRIbChdTT1JUX0ZJRUxEX0RJU1BMQVlfTkFNRRAGEhcKE1NPUlRfRklFTERfRElTQUJMRUQQBxIbChdTT1JUX0ZJRUxEX01FTUJFUl9DT1VOVBAIEhUKEVNPUlRfRklFTERfU1RBVFVTEAkSGgoWU09SVF9GSUVMRF9BQ1RJT05fVFlQRRAKEh4KGlNPUlRfRklFTERfREVWSUNFX0hPU1ROQU1FEAsSGQoVU09SVF9GSUVMRF9BQ1RPUl9UWVBFEAwSGgoWU09SVF9GSUVMRF9TVFJFQU1fVFlQRRANEhkKFVNPUlRfRklFTERfRVZFTlRfVFlQRRAOEhkKFVNPUlRfRklFTERfUlVMRV9DT1VOVBAPEhwKGFNPUlRfRklFTERfTEFTVF9MT0dJTl9BVBAQEhkKFVNPUlRfRklFTERfQ1JFQVRFRF9BVBAREhkKFVNPUlRfRklFTERfVVBEQVRFRF9BVBASEhsKF1NPUlRfRklFTERfTEFTVF9TRUVOX0FUEBMSHAoYU09SVF9GSUVMRF9SRUdJU1RFUkVEX0FUEBQSGgoWU09SVF9GSUVMRF9PQ0NVUlJFRF9BVBAVKmAKDVNvcnREaXJlY3Rpb24SHgoaU09SVF9ESVJFQ1RJT05fVU5TUEVDSUZJRUQQABIWChJTT1JUX0RJUkVDVElPTl9BU0MQARIXChNTT1JUX0RJUkVDVElPTl9ERVNDEAIqXwoUSWRlbnRpdHlQcm92aWRlclR5cGUSJgoiSURFTlRJVFlfUFJPVklERVJfVFlQRV9VTlNQRUNJRklFRBAAEh8KG0lERU5USVRZX1BST1ZJREVSX1RZUEVfT0lEQxABKo0BCg5Sb3RhdGlvblJlYXNvbhIfChtST1RBVElPTl9SRUFTT05fVU5TUEVDSUZJRUQQABIbChdST1RBVElPTl9SRUFTT05fSU5JVElBTBABEh0KGVJPVEFUSU9OX1JFQVNPTl9TQ0hFRFVMRUQQAhIeChpST1RBVElPTl9SRUFTT05fQVVUSF9HUkFDRRADKs0BChRMdWtzUmV2b2NhdGlvblN0YXR1cxImCiJMVUtTX1JFVk9DQVRJT05fU1RBVFVTX1VOU1BFQ0lGSUVEEAASHwobTFVLU19SRVZPQ0FUSU9OX1NUQVRVU19OT05FEAESJQohTFVLU19SRVZPQ0FUSU9OX1NUQVRVU19ESVNQQVRDSEVEEAISIgoeTFVLU19SRVZPQ0FUSU9OX1NUQVRVU19TVUNDRVNTEAMSIQodTFVLU19SRVZPQ0FUSU9OX1NUQVRVU19GQUlMRUQQBCqeAQoQQ29tcGxpYW5jZVN0YXR1cxIdChlDT01QTElBTkNFX1NUQVRVU19VTktOT1dOEAASHwobQ09NUExJQU5DRV9TVEFUVVNfQ09NUExJQU5UEAESIwofQ09NUExJQU5DRV9TVEFUVVNfTk9OX0NPTVBMSUFOVBACEiUKIUNPTVBMSUFOQ0VfU1RBVFVTX0lOX0dSQUNFX1BFUklPRBADQkxaSmdpdGh1Yi5jb20vbWFuY2h0b29scy9wb3dlci1tYW5hZ2Utc2RrL2dlbi9nby9wb3dlcm1hbmFnZS92MTtwb3dlcm1hbmFnZXYxYgZwcm90bzM");
+  fileDesc("Chtwb3dlcm1hbmFnZS92MS9jb21tb24ucHJvdG8SDnBvd2VybWFuYWdlLnYxIhkKCEFjdGlvbklkEg0KBXZhbHVlGAEgASgJIhkKCERldmljZUlkEg0KBXZhbHVlGAEgASgJIi8KC0Vycm9yRGV0YWlsEgwKBGNvZGUYASABKAkSEgoKcmVxdWVzdF9pZBgCIAEoCSLbAQoRTWFpbnRlbmFuY2VXaW5kb3cSOAoIc2NoZWR1bGUYASADKAsyJi5wb3dlcm1hbmFnZS52MS5NYWludGVuYW5jZVdpbmRvd0VudHJ5EhEKCXRpbWVfem9uZRgCIAEoCRI9Cg5ibGFja291dF9kYXRlcxgDIAMoCzIlLnBvd2VybWFuYWdlLnYxLk1haW50ZW5hbmNlV2luZG93RGF0ZRI6CgtleHRyYV9kYXRlcxgEIAMoCzIlLnBvd2VybWFuYWdlLnYxLk1haW50ZW5hbmNlV2luZG93RGF0ZSJIChZNYWludGVuYW5jZVdpbmRvd0VudHJ5EgwKBGRheXMYASADKAkSDQoFYWxsb3cYAiABKAkSEQoJdGltZV96b25lGAMgASgJIkcKFU1haW50ZW5hbmNlV2luZG93RGF0ZRIMCgRkYXRlGAEgASgJEg0KBWFsbG93GAIgASgJEhEKCXRpbWVfem9uZRgDIAEoCSJCCgtTZWFsZWRWYWx1ZRIPCgd2ZXJzaW9uGAEgASgNEhIKCmNpcGhlcnRleHQYAiABKAwSDgoGa2V5X2lkGAMgASgMIkIKDUNvbW1hbmRPdXRwdXQSEQoJZXhpdF9jb2RlGAEgASgFEg4KBnN0ZG91dBgCIAEoCRIOCgZzdGRlcnIYAyABKAkq7wIKD0V4ZWN1dGlvblN0YXR1cxIgChxFWEVDVVRJT05fU1RBVFVTX1VOU1BFQ0lGSUVEEAASHAoYRVhFQ1VUSU9OX1NUQVRVU19QRU5ESU5HEAESHAoYRVhFQ1VUSU9OX1NUQVRVU19SVU5OSU5HEAISHAoYRVhFQ1VUSU9OX1NUQVRVU19TVUNDRVNTEAMSGwoXRVhFQ1VUSU9OX1NUQVRVU19GQUlMRUQQBBIcChhFWEVDVVRJT05fU1RBVFVTX1NLSVBQRUQQBRIcChhFWEVDVVRJT05fU1RBVFVTX1RJTUVPVVQQBhIeChpFWEVDVVRJT05fU1RBVFVTX1NDSEVEVUxFRBAHEh4KGkVYRUNVVElPTl9TVEFUVVNfQ0FOQ0VMTEVEEAgSIwofRVhFQ1VUSU9OX1NUQVRVU19OT1RfQVBQTElDQUJMRRAJEiIKHkVYRUNVVElPTl9TVEFUVVNfSU5ERVRFUk1JTkFURRAKKkMKDERlc2lyZWRTdGF0ZRIZChVERVNJUkVEX1NUQVRFX1BSRVNFTlQQABIYChRERVNJUkVEX1NUQVRFX0FCU0VOVBABKooBCg5Bc3NpZ25tZW50TW9kZRIcChhBU1NJR05NRU5UX01PREVfUkVRVUlSRUQQABIdChlBU1NJR05NRU5UX01PREVfQVZBSUxBQkxFEAESHAoYQVNTSUdOTUVOVF9NT0RFX0VYQ0xVREVEEAISHQoZQVNTSUdOTUVOVF9NT0RFX1VOSU5TVEFMTBADKt0BChRBc3NpZ25tZW50U291cmNlVHlwZRImCiJBU1NJR05NRU5UX1NPVVJDRV9UWVBFX1VOU1BFQ0lGSUVEEAASIQodQVNTSUdOTUVOVF9TT1VSQ0VfVFlQRV9BQ1RJT04QARIlCiFBU1NJR05NRU5UX1NPVVJDRV9UWVBFX0FDVElPTl9TRVQQAhIlCiFBU1NJR05NRU5UX1NPVVJDRV9UWVBFX0RFRklOSVRJT04QAxIsCihBU1NJR05NRU5UX1NPVVJDRV9UWVBFX0NPTVBMSUFOQ0VfUE9MSUNZEAQq0gEKFEFzc2lnbm1lbnRUYXJnZXRUeXBlEiYKIkFTU0lHTk1FTlRfVEFSR0VUX1RZUEVfVU5TUEVDSUZJRUQQABIhCh1BU1NJR05NRU5UX1RBUkdFVF9UWVBFX0RFVklDRRABEicKI0FTU0lHTk1FTlRfVEFSR0VUX1RZUEVfREVWSUNFX0dST1VQEAISHwobQVNTSUdOTUVOVF9UQVJHRVRfVFlQRV9VU0VSEAMSJQohQVNTSUdOTUVOVF9UQVJHRVRfVFlQRV9VU0VSX0dST1VQEAQqiQEKElJvbGVHcmFudFNjb3BlS2luZBIlCiFST0xFX0dSQU5UX1NDT1BFX0tJTkRfVU5TUEVDSUZJRUQQABImCiJST0xFX0dSQU5UX1NDT1BFX0tJTkRfREVWSUNFX0dST1VQEAESJAogUk9MRV9HUkFOVF9TQ09QRV9LSU5EX1VTRVJfR1JPVVAQAiqCAQoUUGVybWlzc2lvblRhcmdldEtpbmQSJgoiUEVSTUlTU0lPTl9UQVJHRVRfS0lORF9VTlNQRUNJRklFRBAAEiEKHVBFUk1JU1NJT05fVEFSR0VUX0tJTkRfREVWSUNFEAESHwobUEVSTUlTU0lPTl9UQVJHRVRfS0lORF9VU0VSEAIqYgoMRGV2aWNlU3RhdHVzEh0KGURFVklDRV9TVEFUVVNfVU5TUEVDSUZJRUQQABIYChRERVZJQ0VfU1RBVFVTX09OTElORRABEhkKFURFVklDRV9TVEFUVVNfT0ZGTElORRACKtMCCgtTZWFyY2hTY29wZRIcChhTRUFSQ0hfU0NPUEVfVU5TUEVDSUZJRUQQABIYChRTRUFSQ0hfU0NPUEVfQUNUSU9OUxABEhwKGFNFQVJDSF9TQ09QRV9BQ1RJT05fU0VUUxACEhwKGFNFQVJDSF9TQ09QRV9ERUZJTklUSU9OUxADEiQKIFNFQVJDSF9TQ09QRV9DT01QTElBTkNFX1BPTElDSUVTEAQSGAoUU0VBUkNIX1NDT1BFX0RFVklDRVMQBRIWChJTRUFSQ0hfU0NPUEVfVVNFUlMQBhIeChpTRUFSQ0hfU0NPUEVfREVWSUNFX0dST1VQUxAHEhwKGFNFQVJDSF9TQ09QRV9VU0VSX0dST1VQUxAIEhsKF1NFQVJDSF9TQ09QRV9FWEVDVVRJT05TEAkSHQoZU0VBUkNIX1NDT1BFX0FVRElUX0VWRU5UUxAKKuAECglTb3J0RmllbGQSGgoWU09SVF9GSUVMRF9VTlNQRUNJRklFRBAAEhMKD1NPUlRfRklFTERfTkFNRRABEhMKD1NPUlRfRklFTERfVFlQRRACEhcKE1NPUlRfRklFTERfSE9TVE5BTUUQAxIgChxTT1JUX0ZJRUxEX0NPTVBMSUFOQ0VfU1RBVFVTEAQSFAoQU09SVF9GSUVMRF9FTUFJTBAFEhsKF1NPUlRfRklFTERfRElTUExBWV9OQU1FEAYSFwoTU09SVF9GSUVMRF9ESVNBQkxFRBAHEhsKF1NPUlRfRklFTERfTUVNQkVSX0NPVU5UEAgSFQoRU09SVF9GSUVMRF9TVEFUVVMQCRIaChZTT1JUX0ZJRUxEX0FDVElPTl9UWVBFEAoSHgoaU09SVF9GSUVMRF9ERVZJQ0VfSE9TVE5BTUUQCxIZChVTT1JUX0ZJRUxEX0FDVE9SX1RZUEUQDBIaChZTT1JUX0ZJRUxEX1NUUkVBTV9UWVBFEA0SGQoVU09SVF9GSUVMRF9FVkVOVF9UWVBFEA4SGQoVU09SVF9GSUVMRF9SVUxFX0NPVU5UEA8SHAoYU09SVF9GSUVMRF9MQVNUX0xPR0lOX0FUEBASGQoVU09SVF9GSUVMRF9DUkVBVEVEX0FUEBESGQoVU09SVF9GSUVMRF9VUERBVEVEX0FUEBISGwoXU09SVF9GSUVMRF9MQVNUX1NFRU5fQVQQExIcChhTT1JUX0ZJRUxEX1JFR0lTVEVSRURfQVQQFBIaChZTT1JUX0ZJRUxEX09DQ1VSUkVEX0FUEBUqYAoNU29ydERpcmVjdGlvbhIeChpTT1JUX0RJUkVDVElPTl9VTlNQRUNJRklFRBAAEhYKElNPUlRfRElSRUNUSU9OX0FTQxABEhcKE1NPUlRfRElSRUNUSU9OX0RFU0MQAipfChRJZGVudGl0eVByb3ZpZGVyVHlwZRImCiJJREVOVElUWV9QUk9WSURFUl9UWVBFX1VOU1BFQ0lGSUVEEAASHwobSURFTlRJVFlfUFJPVklERVJfVFlQRV9PSURDEAEqjQEKDlJvdGF0aW9uUmVhc29uEh8KG1JPVEFUSU9OX1JFQVNPTl9VTlNQRUNJRklFRBAAEhsKF1JPVEFUSU9OX1JFQVNPTl9JTklUSUFMEAESHQoZUk9UQVRJT05fUkVBU09OX1NDSEVEVUxFRBACEh4KGlJPVEFUSU9OX1JFQVNPTl9BVVRIX0dSQUNFEAMqzQEKFEx1a3NSZXZvY2F0aW9uU3RhdHVzEiYKIkxVS1NfUkVWT0NBVElPTl9TVEFUVVNfVU5TUEVDSUZJRUQQABIfChtMVUtTX1JFVk9DQVRJT05fU1RBVFVTX05PTkUQARIlCiFMVUtTX1JFVk9DQVRJT05fU1RBVFVTX0RJU1BBVENIRUQQAhIiCh5MVUtTX1JFVk9DQVRJT05fU1RBVFVTX1NVQ0NFU1MQAxIhCh1MVUtTX1JFVk9DQVRJT05fU1RBVFVTX0ZBSUxFRBAEKp4BChBDb21wbGlhbmNlU3RhdHVzEh0KGUNPTVBMSUFOQ0VfU1RBVFVTX1VOS05PV04QABIfChtDT01QTElBTkNFX1NUQVRVU19DT01QTElBTlQQARIjCh9DT01QTElBTkNFX1NUQVRVU19OT05fQ09NUExJQU5UEAISJQohQ09NUExJQU5DRV9TVEFUVVNfSU5fR1JBQ0VfUEVSSU9EEANCTFpKZ2l0aHViLmNvbS9tYW5jaHRvb2xzL3Bvd2VyLW1hbmFnZS1zZGsvZ2VuL2dvL3Bvd2VybWFuYWdlL3YxO3Bvd2VybWFuYWdldjFiBnByb3RvMw");
 
 /**
  * Unique identifier for an action instance
@@ -91,15 +91,19 @@ export const ErrorDetailSchema: GenMessage<ErrorDetail> = /*@__PURE__*/
   messageDesc(file_powermanage_v1_common, 2);
 
 /**
- * MaintenanceWindow gates action dispatch by device-local wall-clock
- * time. A window is a positive allowlist: when the schedule is empty
- * the window is "always allowed" — the feature is opt-in and existing
- * groups carry an empty window with zero behavioural change.
+ * MaintenanceWindow gates action dispatch by wall-clock time. A window
+ * is a positive allowlist: when the schedule and extra_dates are both
+ * empty the window is "always allowed" — the feature is opt-in and
+ * existing groups carry an empty window with zero behavioural change.
+ * blackout_dates still apply to such a window, so a change freeze
+ * needs no schedule.
  *
- * Multiple entries combine as OR. The agent evaluates against
- * time.Now().Local() at dispatch time so "02:00 local" means 02:00
- * wherever the device runs; the server never tries to interpret the
- * device's timezone.
+ * Multiple entries combine as OR, and extra_dates OR in one-off
+ * openings. blackout_dates win over both. Without a time_zone the
+ * agent evaluates against time.Now().Local() at dispatch time so
+ * "02:00 local" means 02:00 wherever the device runs; with one, the
+ * window means the same instants on every device in the fleet. The
+ * server never tries to interpret the device's timezone.
  *
  * @generated from message powermanage.v1.MaintenanceWindow
  */
@@ -110,6 +114,32 @@ export type MaintenanceWindow = Message<"powermanage.v1.MaintenanceWindow"> & {
    * @generated from field: repeated powermanage.v1.MaintenanceWindowEntry schedule = 1;
    */
   schedule: MaintenanceWindowEntry[];
+
+  /**
+   * IANA time zone the schedule and dates are read in (e.g.
+   * "Europe/Berlin"). Empty means the device's local time.
+   * @gotags: validate:"omitempty,max=64"
+   *
+   * @generated from field: string time_zone = 2;
+   */
+  timeZone: string;
+
+  /**
+   * Dates on which dispatch is denied regardless of schedule and
+   * extra_dates.
+   * @gotags: validate:"omitempty,dive"
+   *
+   * @generated from field: repeated powermanage.v1.MaintenanceWindowDate blackout_dates = 3;
+   */
+  blackoutDates: MaintenanceWindowDate[];
+
+  /**
+   * Dates on which dispatch is allowed in addition to the schedule.
+   * @gotags: validate:"omitempty,dive"
+   *
+   * @generated from field: repeated powermanage.v1.MaintenanceWindowDate extra_dates = 4;
+   */
+  extraDates: MaintenanceWindowDate[];
 };
 
 /**
@@ -143,6 +173,16 @@ export type MaintenanceWindowEntry = Message<"powermanage.v1.MaintenanceWindowEn
    * @generated from field: string allow = 2;
    */
   allow: string;
+
+  /**
+   * Overrides the window's time_zone for this entry. The union of
+   * windows in different zones sets it so every entry keeps the zone
+   * it was written in.
+   * @gotags: validate:"omitempty,max=64"
+   *
+   * @generated from field: string time_zone = 3;
+   */
+  timeZone: string;
 };
 
 /**
@@ -152,6 +192,46 @@ export type MaintenanceWindowEntry = Message<"powermanage.v1.MaintenanceWindowEn
 export const MaintenanceWindowEntrySchema: GenMessage<MaintenanceWindowEntry> = /*@__PURE__*/
   messageDesc(file_powermanage_v1_common, 4);
 
+/**
+ * One dated exception in a MaintenanceWindow. `date` is a calendar
+ * date as YYYY-MM-DD. `allow` narrows the exception to one HH:MM-HH:MM
+ * range starting on that date and may cross midnight like an entry's;
+ * empty covers the whole date.
+ *
+ * @generated from message powermanage.v1.MaintenanceWindowDate
+ */
+export type MaintenanceWindowDate = Message<"powermanage.v1.MaintenanceWindowDate"> & {
+  /**
+   * @gotags: validate:"required,len=10"
+   *
+   * @generated from field: string date = 1;
+   */
+  date: string;
+
+  /**
+   * @gotags: validate:"omitempty,min=11,max=11"
+   *
+   * @generated from field: string allow = 2;
+   */
+  allow: string;
+
+  /**
+   * Overrides the window's time_zone for this date, as on
+   * MaintenanceWindowEntry.
+   * @gotags: validate:"omitempty,max=64"
+   *
+   * @generated from field: string time_zone = 3;
+   */
+  timeZone: string;
+};
+
+/**
+ * Describes the message powermanage.v1.MaintenanceWindowDate.
+ * Use `create(MaintenanceWindowDateSchema)` to create a new message.
+ */
+export const MaintenanceWindowDateSchema: GenMessage<MaintenanceWindowDate> = /*@__PURE__*/
+  messageDesc(file_powermanage_v1_common, 5);
+
 /**
  * SealedValue is the wire form of a secret-classified field: a versioned,
  * opaque X25519 sealed envelope produced by the SDK's crypto.SealToPublicKey
@@ -160,6 +240,13 @@ export const MaintenanceWindowEntrySchema: GenMessage<MaintenanceWindowEntry> =
  * key. Both public keys are exchanged on Register and pinned with the endpoint
  * identity.
  *
+ * Either side may rotate its key afterwards. The agent announces its current
+ * key in every Hello and control in every Welcome, both on the mTLS stream the
+ * pinned identity authenticates, and the sender seals to the announced key
+ * from then on. The recipient keeps opening values sealed to its previous key
+ * for an overlap period (crypto.Keyring), so values already in flight or
+ * queued for delivery still open; key_id tells it which key to use.
+ *
  * The AAD binds, in this order, the protocol version, the direction
  * (agent->control or control->agent), the fully-qualified message and field
  * name, the device ULID, and the one action, delivery or terminal session the
@@ -197,6 +284,20 @@ export type SealedValue = Message<"powermanage.v1.SealedValue"> & {
    * @generated from field: bytes ciphertext = 2;
    */
   ciphertext: Uint8Array;
+
+  /**
+   * Fingerprint of the recipient public key the value is sealed to
+   * (crypto.SealingKeyID). It selects the private key from the recipient's
+   * keyring and needs no separate authentication: the recipient public key is
+   * bound into the seal's HKDF salt, so a relabelled value fails to open
+   * rather than opening under another key.
+   * Empty from a sender that predates key ids: the recipient tries the keys
+   * it holds (crypto.Keyring.Open).
+   * @gotags: validate:"omitempty,len=8"
+   *
+   * @generated from field: bytes key_id = 3;
+   */
+  keyId: Uint8Array;
 };
 
 /**
@@ -204,7 +305,7 @@ export type SealedValue = Message<"powermanage.v1.SealedValue"> & {
  * Use `create(SealedValueSchema)` to create a new message.
  */
 export const SealedValueSchema: GenMessage<SealedValue> = /*@__PURE__*/
-  messageDesc(file_powermanage_v1_common, 5);
+  messageDesc(file_powermanage_v1_common, 6);
 
 /**
  * Output from command execution
@@ -239,7 +340,7 @@ export type CommandOutput = Message<"powermanage.v1.CommandOutput"> & {
  * Use `create(CommandOutputSchema)` to create a new message.
  */
 export const CommandOutputSchema: GenMessage<CommandOutput> = /*@__PURE__*/
-  messageDesc(file_powermanage_v1_common, 6);
+  messageDesc(file_powermanage_v1_common, 7);
 
 /**
  * Execution status for any action
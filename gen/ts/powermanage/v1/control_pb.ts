@@ -4524,9 +4524,9 @@ export const SetDeviceGroupInventoryIntervalRequestSchema: GenMessage<SetDeviceG
 
 /**
  * Replace the device group's maintenance window. Pass an empty
- * MaintenanceWindow (or omit the schedule) to clear it — that drops
- * the group's contribution to the device-side union, leaving the
- * device unconstrained by this group.
+ * MaintenanceWindow (no schedule, extra dates or blackout dates) to
+ * clear it — that drops the group's contribution to the device-side
+ * union, leaving the device unconstrained by this group.
  *
  * @generated from message powermanage.v1.SetDeviceGroupMaintenanceWindowRequest
  */
@@ -7874,7 +7874,7 @@ export const EvaluateDynamicUserGroupResponseSchema: GenMessage<EvaluateDynamicU
  * Replace the user group's maintenance window. Same semantics as
  * SetDeviceGroupMaintenanceWindowRequest. The window contributes to a
  * device-side union for every device the user group reaches via an
- * assignment. Empty schedule = clear.
+ * assignment. Empty window = clear.
  *
  * @generated from message powermanage.v1.SetUserGroupMaintenanceWindowRequest
  */
@@ -0,0 +1,219 @@
+package logging
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+)
+
+// DefaultJournalSocket is where journald listens for native-protocol entries.
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+// DefaultJournalFieldPrefix is prepended to every attribute-derived journal
+// field, so an attribute can never overwrite a journald field (MESSAGE,
+// PRIORITY, ...) and "device_id" lands as PM_DEVICE_ID.
+const DefaultJournalFieldPrefix = "PM_"
+
+// maxJournalFieldName is journald's limit on a field name.
+const maxJournalFieldName = 64
+
+// JournalConfig configures a JournalHandler.
+type JournalConfig struct {
+	// SocketPath is the journald native socket; "" means DefaultJournalSocket.
+	SocketPath string
+	// Identifier is SYSLOG_IDENTIFIER; "" means the executable's base name.
+	Identifier string
+	// FieldPrefix is prepended to attribute fields; "" means
+	// DefaultJournalFieldPrefix. It must start with an uppercase letter and
+	// hold only uppercase letters, digits and underscores.
+	FieldPrefix string
+}
+
+// JournalHandler is a slog.Handler that writes each record to journald over
+// its native protocol, so the journal keeps the record structured: the slog
+// level maps to PRIORITY, the call site to CODE_FILE/CODE_LINE/CODE_FUNC, and
+// every attribute becomes its own field (device_id → PM_DEVICE_ID, a group
+// "wifi" holding "ssid" → PM_WIFI_SSID), filterable with journalctl.
+type JournalHandler struct {
+	conn   *net.UnixConn
+	level  slog.Leveler
+	ident  string
+	prefix string // FieldPrefix plus any open groups, e.g. "PM_WIFI_"
+	fields []byte // fields encoded by WithAttrs
+}
+
+// NewJournalHandler connects to journald. level is read on every record, so a
+// *slog.LevelVar changes the level at runtime; nil means slog.LevelInfo.
+func NewJournalHandler(level slog.Leveler, cfg JournalConfig) (*JournalHandler, error) {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	if cfg.SocketPath == "" {
+		cfg.SocketPath = DefaultJournalSocket
+	}
+	if cfg.Identifier == "" {
+		cfg.Identifier = filepath.Base(os.Args[0])
+	}
+	if cfg.FieldPrefix == "" {
+		cfg.FieldPrefix = DefaultJournalFieldPrefix
+	}
+	if !validFieldPrefix(cfg.FieldPrefix) {
+		return nil, fmt.Errorf("journal field prefix %q must match [A-Z][A-Z0-9_]*", cfg.FieldPrefix)
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: cfg.SocketPath, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("connect to journald at %s: %w", cfg.SocketPath, err)
+	}
+	return &JournalHandler{conn: conn, level: level, ident: cfg.Identifier, prefix: cfg.FieldPrefix}, nil
+}
+
+// Enabled reports whether level is at or above the handler's level.
+func (h *JournalHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle sends the record as one journal entry.
+func (h *JournalHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := make([]byte, 0, 512)
+	buf = appendJournalField(buf, "MESSAGE", r.Message)
+	buf = appendJournalField(buf, "PRIORITY", strconv.Itoa(journalPriority(r.Level)))
+	buf = appendJournalField(buf, "SYSLOG_IDENTIFIER", h.ident)
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		buf = appendJournalField(buf, "CODE_FILE", frame.File)
+		buf = appendJournalField(buf, "CODE_LINE", strconv.Itoa(frame.Line))
+		buf = appendJournalField(buf, "CODE_FUNC", frame.Function)
+	}
+	buf = append(buf, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		buf = appendJournalAttr(buf, h.prefix, a)
+		return true
+	})
+	return h.send(buf)
+}
+
+// WithAttrs encodes attrs once, under the open groups.
+func (h *JournalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.fields = append([]byte(nil), h.fields...)
+	for _, a := range attrs {
+		c.fields = appendJournalAttr(c.fields, h.prefix, a)
+	}
+	return &c
+}
+
+// WithGroup nests later attributes' fields under name.
+func (h *JournalHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.prefix = h.prefix + journalFieldName(name) + "_"
+	return &c
+}
+
+// Close closes the journald connection. Handlers derived with WithAttrs or
+// WithGroup share it.
+func (h *JournalHandler) Close() error { return h.conn.Close() }
+
+func (h *JournalHandler) send(entry []byte) error {
+	_, err := h.conn.Write(entry)
+	if err == nil {
+		return nil
+	}
+	// An entry larger than the socket's datagram limit goes over a sealed
+	// memfd instead, the way sd_journal_send does.
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return sendJournalFile(h.conn, entry)
+	}
+	return fmt.Errorf("write journal entry: %w", err)
+}
+
+// journalPriority maps a slog level to a syslog priority: error 3, warning 4,
+// info 6, debug 7. Levels between them round down to the more severe one.
+func journalPriority(l slog.Level) int {
+	switch {
+	case l >= slog.LevelError:
+		return 3
+	case l >= slog.LevelWarn:
+		return 4
+	case l >= slog.LevelInfo:
+		return 6
+	default:
+		return 7
+	}
+}
+
+func appendJournalAttr(buf []byte, prefix string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		group := prefix
+		if a.Key != "" {
+			group += journalFieldName(a.Key) + "_"
+		}
+		for _, g := range a.Value.Group() {
+			buf = appendJournalAttr(buf, group, g)
+		}
+		return buf
+	}
+	if a.Key == "" {
+		return buf
+	}
+	name := prefix + journalFieldName(a.Key)
+	if len(name) > maxJournalFieldName {
+		name = name[:maxJournalFieldName]
+	}
+	return appendJournalField(buf, name, a.Value.String())
+}
+
+// appendJournalField encodes one field. A value with a newline uses the
+// binary form: the name, a newline, the little-endian 64-bit length, the
+// value.
+func appendJournalField(buf []byte, name, value string) []byte {
+	buf = append(buf, name...)
+	if strings.ContainsRune(value, '\n') {
+		buf = append(buf, '\n')
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(len(value)))
+	} else {
+		buf = append(buf, '=')
+	}
+	buf = append(buf, value...)
+	return append(buf, '\n')
+}
+
+// journalFieldName upper-cases key and replaces every byte journald does not
+// allow in a field name with an underscore.
+func journalFieldName(key string) string {
+	var b strings.Builder
+	b.Grow(len(key))
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		i += size
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func validFieldPrefix(p string) bool {
+	if p == "" || p[0] < 'A' || p[0] > 'Z' || len(p) >= maxJournalFieldName {
+		return false
+	}
+	return journalFieldName(p) == p
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package logging
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// sendJournalFile passes entry to journald as a sealed memfd, for entries too
+// large for one datagram. journald only reads a memfd once it is sealed
+// against further writes.
+func sendJournalFile(conn *net.UnixConn, entry []byte) error {
+	fd, err := unix.MemfdCreate("pm-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return fmt.Errorf("create journal memfd: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), "pm-journal")
+	defer f.Close()
+	if _, err := f.Write(entry); err != nil {
+		return fmt.Errorf("write journal memfd: %w", err)
+	}
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		return fmt.Errorf("seal journal memfd: %w", err)
+	}
+	// net refuses WriteMsgUnix on a connected datagram socket, so the
+	// descriptor goes out through the raw socket.
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("send journal memfd: %w", err)
+	}
+	var sendErr error
+	err = raw.Write(func(s uintptr) bool {
+		sendErr = unix.Sendmsg(int(s), nil, unix.UnixRights(int(f.Fd())), nil, 0)
+		return sendErr != unix.EAGAIN
+	})
+	if err == nil {
+		err = sendErr
+	}
+	if err != nil {
+		return fmt.Errorf("send journal memfd: %w", err)
+	}
+	return nil
+}
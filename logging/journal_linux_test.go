@@ -0,0 +1,50 @@
+//go:build linux
+
+package logging
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// An entry larger than one datagram arrives as a sealed memfd.
+func TestJournalHandler_LargeEntryUsesMemfd(t *testing.T) {
+	logger, conn := newTestJournal(t, nil)
+	big := strings.Repeat("x", 4<<20)
+	logger.Info("large", "output", big)
+
+	oob := make([]byte, unix.CmsgSpace(4))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, oobn, _, _, err := conn.ReadMsgUnix(make([]byte, 1), oob)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("control messages = %v, %v", msgs, err)
+	}
+	fds, err := unix.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("unix rights = %v, %v", fds, err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "memfd")
+	defer f.Close()
+
+	seals, err := unix.FcntlInt(f.Fd(), unix.F_GET_SEALS, 0)
+	if err != nil || seals&unix.F_SEAL_WRITE == 0 {
+		t.Errorf("memfd seals = %#x, %v; want F_SEAL_WRITE", seals, err)
+	}
+	// journald reads the memfd from the start; the shared offset is at its end.
+	entry, err := io.ReadAll(io.NewSectionReader(f, 0, 1<<30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parseJournalEntry(t, entry)["PM_OUTPUT"]; got != big {
+		t.Errorf("PM_OUTPUT has %d bytes, want %d", len(got), len(big))
+	}
+}
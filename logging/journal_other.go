@@ -0,0 +1,15 @@
+//go:build !linux
+
+package logging
+
+import (
+	"fmt"
+	"net"
+)
+
+// sendJournalFile on non-Linux builds: journald and memfd are Linux-only, so an
+// entry too large for one datagram is an error. Non-Linux builds exist for
+// `go test` portability only.
+func sendJournalFile(_ *net.UnixConn, entry []byte) error {
+	return fmt.Errorf("journal entry of %d bytes exceeds the datagram limit", len(entry))
+}
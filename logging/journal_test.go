@@ -0,0 +1,162 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// journalListener stands in for journald's native socket. The directory is
+// short-lived and short-named: unix socket paths are limited to 108 bytes.
+func journalListener(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "pmj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "s")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	buf := make([]byte, 1<<16)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read entry: %v", err)
+	}
+	return parseJournalEntry(t, buf[:n])
+}
+
+// parseJournalEntry decodes the native protocol, including the binary form
+// used for values with newlines.
+func parseJournalEntry(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(b) > 0 {
+		nl := bytes.IndexByte(b, '\n')
+		if nl < 0 {
+			t.Fatalf("unterminated field %q", b)
+		}
+		line := b[:nl]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = string(line[eq+1:])
+			b = b[nl+1:]
+			continue
+		}
+		rest := b[nl+1:]
+		size := binary.LittleEndian.Uint64(rest[:8])
+		fields[string(line)] = string(rest[8 : 8+size])
+		b = rest[8+size+1:]
+	}
+	return fields
+}
+
+func newTestJournal(t *testing.T, level slog.Leveler) (*slog.Logger, *net.UnixConn) {
+	t.Helper()
+	conn, path := journalListener(t)
+	h, err := NewJournalHandler(level, JournalConfig{SocketPath: path, Identifier: "pm-agent"})
+	if err != nil {
+		t.Fatalf("NewJournalHandler: %v", err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return slog.New(h), conn
+}
+
+func TestJournalHandler_Fields(t *testing.T) {
+	logger, conn := newTestJournal(t, nil)
+	logger.With("device_id", "dev-1").WithGroup("wifi").Warn("connect failed", "ssid", "corp", slog.Group("eap", "method", "tls"))
+
+	e := readJournalEntry(t, conn)
+	want := map[string]string{
+		"MESSAGE":            "connect failed",
+		"PRIORITY":           "4",
+		"SYSLOG_IDENTIFIER":  "pm-agent",
+		"PM_DEVICE_ID":       "dev-1",
+		"PM_WIFI_SSID":       "corp",
+		"PM_WIFI_EAP_METHOD": "tls",
+	}
+	for k, v := range want {
+		if e[k] != v {
+			t.Errorf("%s = %q, want %q (entry %v)", k, e[k], v, e)
+		}
+	}
+	if !strings.HasSuffix(e["CODE_FILE"], "journal_test.go") || e["CODE_LINE"] == "" || !strings.Contains(e["CODE_FUNC"], "TestJournalHandler_Fields") {
+		t.Errorf("call site = %s:%s %s", e["CODE_FILE"], e["CODE_LINE"], e["CODE_FUNC"])
+	}
+}
+
+func TestJournalHandler_Priority(t *testing.T) {
+	logger, conn := newTestJournal(t, slog.LevelDebug)
+	for _, tt := range []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug, "7"},
+		{slog.LevelInfo, "6"},
+		{slog.LevelWarn, "4"},
+		{slog.LevelError, "3"},
+		{slog.LevelError + 4, "3"},
+	} {
+		logger.Log(t.Context(), tt.level, "m")
+		if got := readJournalEntry(t, conn)["PRIORITY"]; got != tt.want {
+			t.Errorf("%v: PRIORITY = %s, want %s", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestJournalHandler_MultilineValue(t *testing.T) {
+	logger, conn := newTestJournal(t, nil)
+	logger.Info("script failed", "stderr", "line one\nline=two\n")
+	if got := readJournalEntry(t, conn)["PM_STDERR"]; got != "line one\nline=two\n" {
+		t.Errorf("PM_STDERR = %q", got)
+	}
+}
+
+func TestJournalHandler_RuntimeLevel(t *testing.T) {
+	var level slog.LevelVar
+	logger, conn := newTestJournal(t, &level)
+	if logger.Enabled(t.Context(), slog.LevelDebug) {
+		t.Fatal("debug enabled at info")
+	}
+	level.Set(slog.LevelDebug)
+	logger.Debug("now visible")
+	if got := readJournalEntry(t, conn)["MESSAGE"]; got != "now visible" {
+		t.Errorf("MESSAGE = %q", got)
+	}
+}
+
+func TestNewJournalHandler_Errors(t *testing.T) {
+	_, path := journalListener(t)
+	if _, err := NewJournalHandler(nil, JournalConfig{SocketPath: path, FieldPrefix: "pm_"}); err == nil {
+		t.Error("lowercase field prefix accepted")
+	}
+	if _, err := NewJournalHandler(nil, JournalConfig{SocketPath: path + "-missing"}); err == nil {
+		t.Error("missing socket accepted")
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	for in, want := range map[string]string{
+		"device_id": "DEVICE_ID",
+		"Action-ID": "ACTION_ID",
+		"näme.x":    "N_ME_X",
+	} {
+		if got := journalFieldName(in); got != want {
+			t.Errorf("journalFieldName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package logging
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"os"
 )
 
 // ParseLevel converts a level string to slog.Level.
@@ -23,16 +26,118 @@ func ParseLevel(level string) slog.Level {
 }
 
 // SetupLogger creates a new slog.Logger with the given level, format, and output.
-// Format "json" produces JSON output; anything else produces text output.
+// Format "json" produces JSON output; anything else produces text output. The
+// handler is wrapped in a RedactingHandler with DefaultRedactKeys.
 func SetupLogger(level, format string, output io.Writer) *slog.Logger {
 	opts := &slog.HandlerOptions{Level: ParseLevel(level)}
+	return slog.New(NewRedactingHandler(formatHandler(format, output, opts), nil))
+}
+
+// Output selects where a Logger writes.
+type Output string
+
+const (
+	// OutputStream writes formatted records to Config.Writer, or os.Stderr.
+	OutputStream Output = "stream"
+	// OutputJournal sends structured entries to journald; Config.Format is
+	// ignored.
+	OutputJournal Output = "journald"
+	// OutputFile writes formatted records to a RotatingFile.
+	OutputFile Output = "file"
+)
+
+// Config configures a Logger.
+type Config struct {
+	// Level is the initial level, as accepted by ParseLevel.
+	Level string
+	// Format is "json" or text, as for SetupLogger.
+	Format string
+	// Output selects the destination; "" means OutputStream.
+	Output Output
+	// Writer is the OutputStream destination; nil means os.Stderr.
+	Writer io.Writer
+	// File configures OutputFile.
+	File FileConfig
+	// Journal configures OutputJournal.
+	Journal JournalConfig
+	// RedactKeys replaces DefaultRedactKeys, as for NewRedactingHandler.
+	RedactKeys []string
+}
 
+// Logger is a slog.Logger whose level can change at runtime and which owns
+// its output. Like SetupLogger's, its handler is wrapped in a
+// RedactingHandler.
+type Logger struct {
+	*slog.Logger
+	level  *slog.LevelVar
+	closer io.Closer
+}
+
+// Open builds a Logger for cfg. Close it to release a journald connection or
+// log file.
+func Open(cfg Config) (*Logger, error) {
+	level := new(slog.LevelVar)
+	level.Set(ParseLevel(cfg.Level))
+	opts := &slog.HandlerOptions{Level: level}
+
+	l := &Logger{level: level}
 	var handler slog.Handler
-	if format == "json" {
-		handler = slog.NewJSONHandler(output, opts)
-	} else {
-		handler = slog.NewTextHandler(output, opts)
+	switch cfg.Output {
+	case "", OutputStream:
+		w := cfg.Writer
+		if w == nil {
+			w = os.Stderr
+		}
+		handler = formatHandler(cfg.Format, w, opts)
+	case OutputFile:
+		f, err := OpenRotatingFile(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		l.closer = f
+		handler = formatHandler(cfg.Format, f, opts)
+	case OutputJournal:
+		j, err := NewJournalHandler(level, cfg.Journal)
+		if err != nil {
+			return nil, err
+		}
+		l.closer = j
+		handler = j
+	default:
+		return nil, fmt.Errorf("unknown log output %q", cfg.Output)
 	}
+	l.Logger = slog.New(NewRedactingHandler(handler, cfg.RedactKeys))
+	return l, nil
+}
+
+// SetLevel changes the level of l and every logger derived from it.
+func (l *Logger) SetLevel(level slog.Level) { l.level.Set(level) }
+
+// Level reports the current level.
+func (l *Logger) Level() slog.Level { return l.level.Level() }
+
+// Close releases the output. Records logged afterwards are dropped with an
+// error from the handler.
+func (l *Logger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
 
-	return slog.New(handler)
+// Rotate rotates an OutputFile logger's file now; other outputs have nothing
+// to rotate.
+func (l *Logger) Rotate() error {
+	f, ok := l.closer.(*RotatingFile)
+	if !ok {
+		return errors.New("log output does not rotate")
+	}
+	return f.Rotate()
+}
+
+func formatHandler(format string, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
 }
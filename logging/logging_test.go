@@ -3,6 +3,8 @@ package logging
 import (
 	"bytes"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -71,3 +73,72 @@ func TestSetupLogger_LevelFiltering(t *testing.T) {
 		t.Error("warn message should not be filtered at warn level")
 	}
 }
+
+func TestOpen_SetLevelAtRuntime(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := Open(Config{Level: "info", Format: "json", Writer: &buf})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer logger.Close()
+
+	derived := logger.With("device_id", "dev-1")
+	derived.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("debug logged at info: %s", buf.String())
+	}
+	logger.SetLevel(slog.LevelDebug)
+	if logger.Level() != slog.LevelDebug {
+		t.Errorf("Level() = %v", logger.Level())
+	}
+	derived.Debug("visible", "password", "hunter2")
+	if !strings.Contains(buf.String(), `"msg":"visible"`) || strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("output = %s, want the debug record, redacted", buf.String())
+	}
+}
+
+func TestOpen_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.log")
+	logger, err := Open(Config{Output: OutputFile, File: FileConfig{Path: path}})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	logger.Info("to file")
+	if err := logger.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	matches, _ := filepath.Glob(path + ".*")
+	if len(matches) != 1 {
+		t.Fatalf("backups = %v", matches)
+	}
+	if b, _ := os.ReadFile(matches[0]); !strings.Contains(string(b), "msg=\"to file\"") {
+		t.Errorf("backup = %q", b)
+	}
+}
+
+func TestOpen_Journal(t *testing.T) {
+	conn, path := journalListener(t)
+	logger, err := Open(Config{Level: "warn", Output: OutputJournal, Journal: JournalConfig{SocketPath: path}})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer logger.Close()
+	logger.Info("hidden")
+	logger.Warn("shown", "psk", "hunter2")
+	e := readJournalEntry(t, conn)
+	if e["MESSAGE"] != "shown" || e["PM_PSK"] != Redacted {
+		t.Errorf("entry = %v", e)
+	}
+	if err := logger.Rotate(); err == nil {
+		t.Error("Rotate on a journal logger succeeded")
+	}
+}
+
+func TestOpen_UnknownOutput(t *testing.T) {
+	if _, err := Open(Config{Output: "syslog"}); err == nil {
+		t.Error("unknown output accepted")
+	}
+}
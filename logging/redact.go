@@ -0,0 +1,197 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Redacted is what a redacted value is logged as. It matches the rendering of
+// exec.Secret, so a secret reads the same however it reached the log.
+const Redacted = "[REDACTED]"
+
+// sealedValueName is the envelope every secret field travels in. It is
+// matched by name so this package does not depend on the generated contract.
+const sealedValueName protoreflect.FullName = "powermanage.v1.SealedValue"
+
+// DefaultRedactKeys are the attribute-key patterns SetupLogger redacts. A key
+// matches when it contains a pattern, ignoring case, so "password" also covers
+// "new_password" and "PasswordHash".
+var DefaultRedactKeys = []string{
+	"password", "passwd", "passphrase", "secret", "token", "psk",
+	"private_key", "client_key", "preshared_key", "api_key", "authorization", "cookie",
+}
+
+// revealer is exec.Secret's shape: a value whose plaintext is only reachable
+// through Reveal. Matching the method rather than the type keeps logging free
+// of a dependency on sys/exec and covers any type that follows the same
+// contract.
+type revealer interface{ Reveal() string }
+
+// RedactingHandler wraps a slog.Handler and redacts, before the wrapped
+// handler sees them:
+//
+//   - values with a Reveal method (exec.Secret);
+//   - protobuf messages: a SealedValue whole, and every field marked
+//     debug_redact or named like a secret, anywhere in the message;
+//   - attributes whose key matches one of the configured patterns, at any
+//     group depth.
+//
+// It is a net under the call sites, not a substitute for keeping plaintext out
+// of log calls: a secret formatted into the message or an error string is
+// beyond its reach.
+type RedactingHandler struct {
+	next slog.Handler
+	keys []string
+}
+
+// NewRedactingHandler wraps next. keys are the attribute-key patterns to
+// redact; nil means DefaultRedactKeys, and an empty non-nil slice redacts by
+// value type only.
+func NewRedactingHandler(next slog.Handler, keys []string) *RedactingHandler {
+	if keys == nil {
+		keys = DefaultRedactKeys
+	}
+	lower := make([]string, len(keys))
+	for i, k := range keys {
+		lower[i] = strings.ToLower(k)
+	}
+	return &RedactingHandler{next: next, keys: lower}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the record's attributes and passes it on.
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs redacts attrs once, up front, and returns a handler over the
+// wrapped handler's WithAttrs.
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.attr(a)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted), keys: h.keys}
+}
+
+// WithGroup returns a handler over the wrapped handler's WithGroup.
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+func (h *RedactingHandler) attr(a slog.Attr) slog.Attr {
+	if h.sensitiveKey(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, g := range group {
+			redacted[i] = h.attr(g)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		a.Value = h.value(a.Value.Any())
+	}
+	return a
+}
+
+func (h *RedactingHandler) value(v any) slog.Value {
+	switch v := v.(type) {
+	case revealer:
+		return slog.StringValue(Redacted)
+	case proto.Message:
+		m := v.ProtoReflect()
+		if !m.IsValid() {
+			return slog.AnyValue(v)
+		}
+		if m.Descriptor().FullName() == sealedValueName {
+			return slog.StringValue(Redacted)
+		}
+		return slog.AnyValue(h.message(m))
+	}
+	return slog.AnyValue(v)
+}
+
+// message renders m as a map keyed by field name, so JSON and text handlers
+// both print it, with the sensitive fields replaced by Redacted.
+func (h *RedactingHandler) message(m protoreflect.Message) map[string]any {
+	out := make(map[string]any)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		if h.sensitiveField(fd) {
+			out[name] = Redacted
+			return true
+		}
+		switch {
+		case fd.IsList():
+			list := v.List()
+			items := make([]any, list.Len())
+			for i := range items {
+				items[i] = h.single(fd, list.Get(i))
+			}
+			out[name] = items
+		case fd.IsMap():
+			entries := make(map[string]any, v.Map().Len())
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				entries[k.String()] = h.single(fd.MapValue(), mv)
+				return true
+			})
+			out[name] = entries
+		default:
+			out[name] = h.single(fd, v)
+		}
+		return true
+	})
+	return out
+}
+
+func (h *RedactingHandler) single(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if fd.Message().FullName() == sealedValueName {
+			return Redacted
+		}
+		return h.message(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
+
+func (h *RedactingHandler) sensitiveField(fd protoreflect.FieldDescriptor) bool {
+	if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDebugRedact() {
+		return true
+	}
+	return h.sensitiveKey(string(fd.Name()))
+}
+
+func (h *RedactingHandler) sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range h.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
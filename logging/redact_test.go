@@ -0,0 +1,140 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	pm "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/sys/exec"
+)
+
+const plaintext = "hunter2-plaintext"
+
+func jsonRedactLogger(buf *bytes.Buffer, keys []string) *slog.Logger {
+	return slog.New(NewRedactingHandler(slog.NewJSONHandler(buf, nil), keys))
+}
+
+// decode parses one JSON log line so assertions target fields, not substrings
+// of the whole line.
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	return line
+}
+
+func assertNoPlaintext(t *testing.T, buf *bytes.Buffer) {
+	t.Helper()
+	if strings.Contains(buf.String(), plaintext) {
+		t.Fatalf("plaintext leaked: %s", buf.String())
+	}
+}
+
+func TestRedactingHandler_Secret(t *testing.T) {
+	secret, err := exec.NewSecret(plaintext)
+	if err != nil {
+		t.Fatalf("NewSecret: %v", err)
+	}
+	for _, format := range []string{"json", "text"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			SetupLogger("info", format, &buf).Info("rotated", "credential", secret)
+			assertNoPlaintext(t, &buf)
+			if !strings.Contains(buf.String(), Redacted) {
+				t.Errorf("expected %s, got: %s", Redacted, buf.String())
+			}
+		})
+	}
+}
+
+func TestRedactingHandler_KeyPatterns(t *testing.T) {
+	var buf bytes.Buffer
+	logger := jsonRedactLogger(&buf, nil).With("API_Token", plaintext)
+	logger.Info("login",
+		"user", "alice",
+		"new_password", plaintext,
+		slog.Group("wifi", "ssid", "corp", "psk", plaintext),
+	)
+	assertNoPlaintext(t, &buf)
+
+	line := decode(t, &buf)
+	if line["user"] != "alice" {
+		t.Errorf("user = %v, want alice (non-secret keys pass through)", line["user"])
+	}
+	if line["new_password"] != Redacted || line["API_Token"] != Redacted {
+		t.Errorf("secret-named keys not redacted: %v", line)
+	}
+	wifi, _ := line["wifi"].(map[string]any)
+	if wifi["ssid"] != "corp" || wifi["psk"] != Redacted {
+		t.Errorf("wifi group = %v, want ssid kept and psk redacted", wifi)
+	}
+}
+
+func TestRedactingHandler_CustomKeys(t *testing.T) {
+	var buf bytes.Buffer
+	jsonRedactLogger(&buf, []string{"Serial"}).Info("enrolled", "device_serial", plaintext, "password", "kept")
+	assertNoPlaintext(t, &buf)
+	if line := decode(t, &buf); line["password"] != "kept" {
+		t.Errorf("password = %v: custom keys replace the defaults", line["password"])
+	}
+}
+
+func TestRedactingHandler_ProtoMessages(t *testing.T) {
+	sealed := &pm.SealedValue{Version: 1, Ciphertext: []byte(plaintext)}
+	wifi := &pm.WifiParams{
+		Ssid:       "corp",
+		AuthType:   pm.WifiAuthType_WIFI_AUTH_TYPE_EAP_TLS,
+		ClientCert: "cert",
+		ClientKey:  sealed,
+		Identity:   "alice@corp.example",
+	}
+	hello := &pm.Hello{Hostname: "host-1", AuthToken: plaintext}
+
+	var buf bytes.Buffer
+	jsonRedactLogger(&buf, nil).Info("action", "sealed", sealed, "wifi", wifi, "hello", hello)
+	assertNoPlaintext(t, &buf)
+
+	line := decode(t, &buf)
+	if line["sealed"] != Redacted {
+		t.Errorf("SealedValue = %v, want %s", line["sealed"], Redacted)
+	}
+	w, _ := line["wifi"].(map[string]any)
+	if w["ssid"] != "corp" || w["auth_type"] != "WIFI_AUTH_TYPE_EAP_TLS" || w["client_key"] != Redacted {
+		t.Errorf("wifi = %v, want ssid and enum kept, client_key redacted", w)
+	}
+	h, _ := line["hello"].(map[string]any)
+	if h["hostname"] != "host-1" || h["auth_token"] != Redacted {
+		t.Errorf("hello = %v, want auth_token redacted by key pattern", h)
+	}
+}
+
+// A secret field is redacted by its descriptor alone, with no key patterns
+// configured.
+func TestRedactingHandler_DebugRedactField(t *testing.T) {
+	params := &pm.EncryptionParams{PresharedKey: &pm.SealedValue{Version: 1, Ciphertext: []byte(plaintext)}, RotationIntervalDays: 30}
+
+	var buf bytes.Buffer
+	jsonRedactLogger(&buf, []string{}).Info("encryption", "params", params)
+	assertNoPlaintext(t, &buf)
+	p, _ := decode(t, &buf)["params"].(map[string]any)
+	if p["preshared_key"] != Redacted || p["rotation_interval_days"] != float64(30) {
+		t.Errorf("params = %v", p)
+	}
+}
+
+type lazySecret struct{}
+
+func (lazySecret) LogValue() slog.Value { return slog.StringValue(plaintext) }
+
+// A secret-named key is redacted before its LogValuer is resolved, so the
+// plaintext is never even produced.
+func TestRedactingHandler_LogValuer(t *testing.T) {
+	var buf bytes.Buffer
+	jsonRedactLogger(&buf, nil).Info("lazy", "passphrase", lazySecret{})
+	assertNoPlaintext(t, &buf)
+}
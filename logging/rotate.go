@@ -0,0 +1,287 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxFileSize is the size at which a RotatingFile rotates when
+// FileConfig.MaxSize is zero.
+const DefaultMaxFileSize = 50 << 20
+
+// backupTimeLayout names rotated files. It sorts chronologically as a string
+// and has no characters that need quoting in a shell.
+const backupTimeLayout = "20060102T150405.000000000Z"
+
+// FileConfig configures a RotatingFile.
+type FileConfig struct {
+	// Path is the live log file. Rotated files sit beside it as
+	// <Path>.<UTC timestamp>, with .gz when compressed.
+	Path string
+	// MaxSize rotates the file before a write would take it past this many
+	// bytes; 0 means DefaultMaxFileSize.
+	MaxSize int64
+	// MaxAge rotates the live file once it has been open this long, and
+	// deletes rotated files older than this; 0 disables both.
+	MaxAge time.Duration
+	// MaxBackups keeps at most this many rotated files; 0 keeps all of them
+	// (subject to MaxAge).
+	MaxBackups int
+	// Compress gzips rotated files, in the background.
+	Compress bool
+	// Mode is the permission of new files; 0 means 0o640.
+	Mode os.FileMode
+	// Now is the clock; nil means time.Now.
+	Now func() time.Time
+}
+
+// RotatingFile is an io.WriteCloser over a log file that rotates by size and
+// age. It is safe for concurrent use. Writes are never split: a record that
+// would cross MaxSize goes entirely into the next file.
+type RotatingFile struct {
+	cfg FileConfig
+
+	mu     sync.Mutex
+	f      *os.File
+	closed bool
+	// size and opened are what due measures against MaxSize and MaxAge.
+	size   int64
+	opened time.Time
+
+	// post serialises compression and pruning, which run after a rotation
+	// returns; wg lets Close wait for them.
+	post sync.Mutex
+	wg   sync.WaitGroup
+}
+
+// OpenRotatingFile opens cfg.Path for appending, creating it and its
+// directory if needed.
+func OpenRotatingFile(cfg FileConfig) (*RotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("rotating log file: path is required")
+	}
+	if cfg.MaxSize < 0 || cfg.MaxAge < 0 || cfg.MaxBackups < 0 {
+		return nil, errors.New("rotating log file: limits must not be negative")
+	}
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = DefaultMaxFileSize
+	}
+	if cfg.Mode == 0 {
+		cfg.Mode = 0o640
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	w := &RotatingFile{cfg: cfg}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p, rotating first when p would take the file past MaxSize or
+// the file has been open for MaxAge. A failed rotation does not lose p: the
+// live file is reopened, p goes there, and rotation is tried again once the
+// file has grown by another MaxSize or been open another MaxAge.
+func (w *RotatingFile) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ready(); err != nil {
+		return 0, err
+	}
+	if w.due(int64(len(p))) {
+		if err := w.rotate(); err != nil && w.f == nil {
+			return 0, err
+		}
+	}
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("write %s: %w", w.cfg.Path, err)
+	}
+	return n, nil
+}
+
+// Rotate rotates now, whatever the size and age — for a SIGHUP handler or an
+// operator-requested rotation. On failure the writer keeps appending to the
+// live file, as Write does.
+func (w *RotatingFile) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.ready(); err != nil {
+		return err
+	}
+	return w.rotate()
+}
+
+// Close closes the live file and waits for pending compression and pruning.
+func (w *RotatingFile) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.f != nil {
+		err = w.f.Close()
+		w.f = nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	w.wg.Wait()
+	return err
+}
+
+// ready reopens the live file if a failed rotation left it closed. The caller
+// holds mu.
+func (w *RotatingFile) ready() error {
+	if w.closed {
+		return os.ErrClosed
+	}
+	if w.f == nil {
+		return w.open()
+	}
+	return nil
+}
+
+func (w *RotatingFile) due(next int64) bool {
+	if w.size > 0 && w.size+next > w.cfg.MaxSize {
+		return true
+	}
+	return w.cfg.MaxAge > 0 && w.size > 0 && w.cfg.Now().Sub(w.opened) >= w.cfg.MaxAge
+}
+
+func (w *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.cfg.Path), 0o750); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	f, err := os.OpenFile(w.cfg.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, w.cfg.Mode)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", w.cfg.Path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat %s: %w", w.cfg.Path, err)
+	}
+	w.f, w.size, w.opened = f, info.Size(), w.cfg.Now()
+	return nil
+}
+
+// rotate renames the live file to a timestamped backup, opens a fresh one, and
+// hands compression and pruning to the background. The caller holds mu. When
+// the file cannot be closed or renamed, rotate reopens it where it is and
+// returns the error; w.f is nil afterwards only if no file could be opened,
+// and the next Write tries again.
+func (w *RotatingFile) rotate() error {
+	err := w.f.Close()
+	w.f = nil
+	if err != nil {
+		return w.resume(fmt.Errorf("close %s: %w", w.cfg.Path, err))
+	}
+	backup := w.cfg.Path + "." + w.cfg.Now().UTC().Format(backupTimeLayout)
+	if err := os.Rename(w.cfg.Path, backup); err != nil {
+		return w.resume(fmt.Errorf("rotate %s: %w", w.cfg.Path, err))
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.post.Lock()
+		defer w.post.Unlock()
+		if w.cfg.Compress {
+			// A failed compression leaves the uncompressed backup in place;
+			// it is still pruned like any other.
+			_ = compressFile(backup)
+		}
+		w.prune()
+	}()
+	return nil
+}
+
+// resume reopens the live file after a failed rotation and returns cause. It
+// counts size and age from here, so the next attempt waits for another
+// MaxSize or MaxAge instead of coming on every write.
+func (w *RotatingFile) resume(cause error) error {
+	if err := w.open(); err != nil {
+		return errors.Join(cause, err)
+	}
+	w.size = 0
+	return cause
+}
+
+// prune deletes rotated files beyond MaxBackups or older than MaxAge.
+func (w *RotatingFile) prune() {
+	if w.cfg.MaxBackups == 0 && w.cfg.MaxAge == 0 {
+		return
+	}
+	dir, base := filepath.Dir(w.cfg.Path), filepath.Base(w.cfg.Path)+"."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type backup struct {
+		name string
+		at   time.Time
+	}
+	var backups []backup
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), base)
+		if !ok || e.IsDir() {
+			continue
+		}
+		at, err := time.Parse(backupTimeLayout, strings.TrimSuffix(stamp, ".gz"))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{e.Name(), at})
+	}
+	slices.SortFunc(backups, func(a, b backup) int { return b.at.Compare(a.at) })
+	now := w.cfg.Now()
+	for i, b := range backups {
+		if (w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups) || (w.cfg.MaxAge > 0 && now.Sub(b.at) > w.cfg.MaxAge) {
+			_ = os.Remove(filepath.Join(dir, b.name))
+		}
+	}
+}
+
+// compressFile gzips path to path.gz and removes path once the compressed copy
+// is complete.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + ".gz")
+		}
+	}()
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,214 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock advances one second on every reading, so each rotation gets a
+// distinct backup name.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(time.Second)
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(matches)
+	return matches
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "agent.log")
+	w, err := OpenRotatingFile(FileConfig{Path: path, MaxSize: 10, Now: newClock().now})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	for _, rec := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddddddddddddddd\n"} {
+		if _, err := io.WriteString(w, rec); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := backups(t, path)
+	if len(got) != 2 {
+		t.Fatalf("backups = %v, want 2", got)
+	}
+	// Records are never split across files; an oversized record gets a file
+	// of its own.
+	if readFile(t, got[0]) != "aaaa\nbbbb\n" || readFile(t, got[1]) != "cccc\n" {
+		t.Errorf("backups hold %q and %q", readFile(t, got[0]), readFile(t, got[1]))
+	}
+	if live := readFile(t, path); live != "dddddddddddddddd\n" {
+		t.Errorf("live file = %q", live)
+	}
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	clock := newClock()
+	path := filepath.Join(t.TempDir(), "agent.log")
+	w, err := OpenRotatingFile(FileConfig{Path: path, MaxAge: time.Hour, Now: clock.now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	io.WriteString(w, "old\n")
+	clock.advance(time.Hour)
+	io.WriteString(w, "new\n")
+
+	if got := backups(t, path); len(got) != 1 || readFile(t, got[0]) != "old\n" {
+		t.Errorf("backups = %v", got)
+	}
+}
+
+func TestRotatingFile_CompressesAndPrunes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.log")
+	w, err := OpenRotatingFile(FileConfig{Path: path, MaxSize: 1 << 20, MaxBackups: 2, Compress: true, Now: newClock().now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 4 {
+		io.WriteString(w, strings.Repeat(string(rune('a'+i)), 8)+"\n")
+		if err := w.Rotate(); err != nil {
+			t.Fatalf("Rotate: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := backups(t, path)
+	if len(got) != 2 {
+		t.Fatalf("backups = %v, want the 2 newest", got)
+	}
+	for i, name := range got {
+		if !strings.HasSuffix(name, ".gz") {
+			t.Fatalf("%s is not compressed", name)
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(zr)
+		f.Close()
+		if want := strings.Repeat(string(rune('c'+i)), 8) + "\n"; string(b) != want {
+			t.Errorf("%s = %q, want %q", name, b, want)
+		}
+	}
+}
+
+func TestRotatingFile_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.log")
+	if err := os.WriteFile(path, []byte("12345678\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	w, err := OpenRotatingFile(FileConfig{Path: path, MaxSize: 12, Now: newClock().now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(w, "next\n")
+	w.Close()
+	// The existing size counts towards MaxSize.
+	if got := backups(t, path); len(got) != 1 || readFile(t, got[0]) != "12345678\n" {
+		t.Errorf("backups = %v", got)
+	}
+}
+
+func TestRotatingFile_Closed(t *testing.T) {
+	w, err := OpenRotatingFile(FileConfig{Path: filepath.Join(t.TempDir(), "agent.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if _, err := w.Write([]byte("x")); err != os.ErrClosed {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+	if _, err := OpenRotatingFile(FileConfig{}); err == nil {
+		t.Error("empty path accepted")
+	}
+}
+
+func TestRotatingFile_KeepsWritingWhenRotationFails(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "agent.log")
+	w, err := OpenRotatingFile(FileConfig{Path: path, MaxSize: 10, Now: func() time.Time { return at }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// A directory where the backup should go makes the rename fail.
+	if err := os.MkdirAll(filepath.Join(path+"."+at.Format(backupTimeLayout), "x"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rec := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n"} {
+		if _, err := io.WriteString(w, rec); err != nil {
+			t.Fatalf("write %q: %v", rec, err)
+		}
+	}
+	if err := w.Rotate(); err == nil {
+		t.Error("Rotate succeeded onto a directory")
+	}
+	if live := readFile(t, path); live != "aaaa\nbbbb\ncccc\ndddd\n" {
+		t.Fatalf("live file = %q, want every record", live)
+	}
+
+	// Once the rename can succeed, the next threshold rotates.
+	at = at.Add(time.Second)
+	for _, rec := range []string{"eeee\n", "ffff\n", "gggg\n"} {
+		if _, err := io.WriteString(w, rec); err != nil {
+			t.Fatalf("write %q: %v", rec, err)
+		}
+	}
+	backup := path + "." + at.Format(backupTimeLayout)
+	if got := readFile(t, backup); got != "aaaa\nbbbb\ncccc\ndddd\neeee\nffff\n" {
+		t.Errorf("backup = %q", got)
+	}
+	if live := readFile(t, path); live != "gggg\n" {
+		t.Errorf("live file = %q", live)
+	}
+}
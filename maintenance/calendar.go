@@ -0,0 +1,301 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"time"
+
+	powermanagev1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+)
+
+// searchHorizon bounds NextOpen and NextClose. A schedule repeats weekly, so
+// a boundary that does not occur within a week plus the longest
+// midnight-crossing tail never occurs at all. Dated exceptions extend it; see
+// searchHorizonFor.
+const searchHorizon = 8 * 24 * time.Hour
+
+// MaxSearchHorizon caps how far past the time passed in NextOpen and
+// NextClose search. A dated exception further out than that, such as a
+// blackout in year 9999, fails with ErrBeyondHorizon rather than being
+// searched day by day or silently ignored.
+const MaxSearchHorizon = 3 * 366 * 24 * time.Hour
+
+// ErrBeyondHorizon is returned by NextOpen and NextClose for a window with a
+// dated exception more than MaxSearchHorizon after the time passed in.
+var ErrBeyondHorizon = errors.New("maintenance window date is beyond the search horizon")
+
+// Interval is a half-open span [Start, End) during which a window allows
+// dispatch. Start and End are in the location of the time the interval was
+// computed from, whatever zone the window itself is read in.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NextOpen returns the first moment at or after t that the window allows:
+// t itself when IsAllowed(w, t). It reports false when the window never
+// opens, which only happens when every entry and extra date is malformed or
+// in a zone that does not load. A window that is not allowed at t and has a
+// date past MaxSearchHorizon fails with ErrBeyondHorizon.
+//
+// Like IsAllowed, NextOpen evaluates wall-clock time in the window's zone,
+// or t's location when it has none, so a window edge that falls into a DST
+// gap opens at the first wall-clock minute after the gap.
+func NextOpen(w *powermanagev1.MaintenanceWindow, t time.Time) (time.Time, bool, error) {
+	if IsAllowed(w, t) {
+		return t, true, nil
+	}
+	horizon, err := searchHorizonFor(w, t)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	for interval := range Intervals(w, t, horizon) {
+		return interval.Start, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// NextClose returns the first moment at or after t that the window denies:
+// t itself when !IsAllowed(w, t). It reports false when the window never
+// closes — an unconstrained window, or a schedule that covers the whole week
+// and has no blackout dates left. Like NextOpen, it fails with
+// ErrBeyondHorizon rather than search past MaxSearchHorizon.
+func NextClose(w *powermanagev1.MaintenanceWindow, t time.Time) (time.Time, bool, error) {
+	if !IsAllowed(w, t) {
+		return t, true, nil
+	}
+	horizon, err := searchHorizonFor(w, t)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	end := t.Add(horizon)
+	for interval := range Intervals(w, t, horizon) {
+		if !interval.End.Before(end) {
+			return time.Time{}, false, nil
+		}
+		return interval.End, true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// Intervals yields the allowed intervals that overlap [from, from+horizon),
+// in order, clipped to that range and with touching intervals merged. An
+// unconstrained window yields the whole range.
+//
+// Intervals agrees with IsAllowed at every instant, including across DST
+// transitions in each piece's zone: an entry edge in a spring-forward gap
+// moves to the end of the gap, and a fall-back hour that repeats a wall-clock
+// time inside an entry is allowed on both passes, exactly as IsAllowed
+// evaluates it. Entries and dates without a zone use from's location.
+func Intervals(w *powermanagev1.MaintenanceWindow, from time.Time, horizon time.Duration) iter.Seq[Interval] {
+	return func(yield func(Interval) bool) {
+		if horizon <= 0 {
+			return
+		}
+		for _, interval := range windowIntervals(w, from, from.Add(horizon)) {
+			if !yield(interval) {
+				return
+			}
+		}
+	}
+}
+
+// searchHorizonFor extends searchHorizon past the window's last dated
+// exception, after which the schedule repeats weekly again. Three days of
+// slack cover a range crossing midnight under the widest UTC offsets. A date
+// that would take the horizon past MaxSearchHorizon is an error; comparing
+// dates before subtracting keeps year 9999 from overflowing a Duration.
+func searchHorizonFor(w *powermanagev1.MaintenanceWindow, t time.Time) (time.Duration, error) {
+	horizon := searchHorizon
+	limit := t.Add(MaxSearchHorizon - searchHorizon)
+	for _, dates := range [][]*powermanagev1.MaintenanceWindowDate{w.GetBlackoutDates(), w.GetExtraDates()} {
+		for _, d := range dates {
+			date, err := time.Parse(dateLayout, d.GetDate())
+			if err != nil {
+				continue
+			}
+			last := date.AddDate(0, 0, 3)
+			if last.After(limit) {
+				return 0, fmt.Errorf("%w: %s is more than %d days after %s", ErrBeyondHorizon, d.GetDate(), MaxSearchHorizon/(24*time.Hour), t.Format(time.DateOnly))
+			}
+			if until := last.Sub(t) + searchHorizon; until > horizon {
+				horizon = until
+			}
+		}
+	}
+	return horizon, nil
+}
+
+// windowIntervals lists the allowed intervals in [from, to): the entries and
+// extra dates, or the whole range for an unconstrained window, minus the
+// blackout dates. Each piece is laid out in its own zone; pieces whose zone
+// does not load fail closed, as IsAllowed does.
+func windowIntervals(w *powermanagev1.MaintenanceWindow, from, to time.Time) []Interval {
+	output := from.Location()
+	var allowed []Interval
+	if unconstrained(w) {
+		allowed = []Interval{{Start: from, End: to}}
+	}
+	for _, e := range w.GetSchedule() {
+		if location, ok := zoneFor(w, e.GetTimeZone(), output); ok && e != nil {
+			allowed = append(allowed, zonedIntervals(location, output, from, to, func(wallFrom, wallTo time.Time) []Interval {
+				return entryWallIntervals(e, wallFrom, wallTo)
+			})...)
+		}
+	}
+	for _, d := range w.GetExtraDates() {
+		if location, ok := zoneFor(w, d.GetTimeZone(), output); ok && d != nil {
+			allowed = append(allowed, zonedIntervals(location, output, from, to, func(time.Time, time.Time) []Interval {
+				return dateWallIntervals(d)
+			})...)
+		}
+	}
+	var denied []Interval
+	for _, d := range w.GetBlackoutDates() {
+		location, ok := zoneFor(w, d.GetTimeZone(), output)
+		if !ok {
+			return nil
+		}
+		if d != nil {
+			denied = append(denied, zonedIntervals(location, output, from, to, func(time.Time, time.Time) []Interval {
+				return dateWallIntervals(d)
+			})...)
+		}
+	}
+	return subtractIntervals(mergeIntervals(allowed), mergeIntervals(denied))
+}
+
+// zonedIntervals splits [from, to) at location's UTC-offset changes. Within
+// one offset, wall-clock time is a fixed shift of absolute time, so the wall
+// intervals returned by wall map back to absolute time by undoing the shift.
+// Wall-clock times are represented as UTC times carrying the wall clock's
+// fields, which keeps the day arithmetic free of DST. The result is in output.
+func zonedIntervals(location, output *time.Location, from, to time.Time, wall func(wallFrom, wallTo time.Time) []Interval) []Interval {
+	var out []Interval
+	for cursor := from; cursor.Before(to); {
+		local := cursor.In(location)
+		_, offsetSeconds := local.Zone()
+		offset := time.Duration(offsetSeconds) * time.Second
+		_, zoneEnd := local.ZoneBounds()
+		segmentEnd := to
+		if !zoneEnd.IsZero() && zoneEnd.Before(to) {
+			segmentEnd = zoneEnd
+		}
+		wallFrom, wallTo := wallClock(cursor, offset), wallClock(segmentEnd, offset)
+		for _, interval := range wall(wallFrom, wallTo) {
+			start, end := maxTime(interval.Start, wallFrom), minTime(interval.End, wallTo)
+			if !start.Before(end) {
+				continue
+			}
+			out = append(out, Interval{
+				Start: start.Add(-offset).In(output),
+				End:   end.Add(-offset).In(output),
+			})
+		}
+		cursor = segmentEnd
+	}
+	return out
+}
+
+// wallClock returns t's wall clock under offset as a UTC time.
+func wallClock(t time.Time, offset time.Duration) time.Time {
+	return t.UTC().Add(offset)
+}
+
+// entryWallIntervals lists an entry's wall-clock intervals, in the UTC
+// representation wallClock uses, for every day that can overlap
+// [wallFrom, wallTo). The day before wallFrom is included for the tail of a
+// midnight-crossing entry.
+func entryWallIntervals(e *powermanagev1.MaintenanceWindowEntry, wallFrom, wallTo time.Time) []Interval {
+	startMin, endMin, err := parseRange(e.Allow)
+	if err != nil {
+		// Fail closed, as entryAllows does.
+		return nil
+	}
+	var out []Interval
+	day := time.Date(wallFrom.Year(), wallFrom.Month(), wallFrom.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	for ; day.Before(wallTo); day = day.AddDate(0, 0, 1) {
+		if !entryListsDay(e, weekdayTokens[day.Weekday()]) {
+			continue
+		}
+		start := day.Add(time.Duration(startMin) * time.Minute)
+		end := day.Add(time.Duration(endMin) * time.Minute)
+		if startMin > endMin {
+			end = end.AddDate(0, 0, 1)
+		}
+		out = append(out, Interval{Start: start, End: end})
+	}
+	return out
+}
+
+// dateWallIntervals returns a dated exception's single wall-clock interval in
+// the UTC representation wallClock uses.
+func dateWallIntervals(d *powermanagev1.MaintenanceWindowDate) []Interval {
+	startMin, endMin, ok := dateRange(d)
+	date, err := time.Parse(dateLayout, d.Date)
+	if !ok || err != nil {
+		return nil
+	}
+	start := date.Add(time.Duration(startMin) * time.Minute)
+	end := date.Add(time.Duration(endMin) * time.Minute)
+	if startMin > endMin {
+		end = end.AddDate(0, 0, 1)
+	}
+	return []Interval{{Start: start, End: end}}
+}
+
+// subtractIntervals removes denied from allowed. Both must be sorted and
+// merged.
+func subtractIntervals(allowed, denied []Interval) []Interval {
+	var out []Interval
+	for _, interval := range allowed {
+		start := interval.Start
+		for _, d := range denied {
+			if !d.Start.Before(interval.End) {
+				break
+			}
+			if !d.End.After(start) {
+				continue
+			}
+			if d.Start.After(start) {
+				out = append(out, Interval{Start: start, End: d.Start})
+			}
+			start = maxTime(start, d.End)
+		}
+		if start.Before(interval.End) {
+			out = append(out, Interval{Start: start, End: interval.End})
+		}
+	}
+	return out
+}
+
+func mergeIntervals(intervals []Interval) []Interval {
+	slices.SortFunc(intervals, func(a, b Interval) int { return a.Start.Compare(b.Start) })
+	var out []Interval
+	for _, interval := range intervals {
+		if n := len(out); n > 0 && !interval.Start.After(out[n-1].End) {
+			if interval.End.After(out[n-1].End) {
+				out[n-1].End = interval.End
+			}
+			continue
+		}
+		out = append(out, interval)
+	}
+	return out
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,195 @@
+package maintenance_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	powermanagev1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/maintenance"
+)
+
+func nightlyWindow() *powermanagev1.MaintenanceWindow {
+	return &powermanagev1.MaintenanceWindow{Schedule: []*powermanagev1.MaintenanceWindowEntry{
+		{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Allow: "22:00-06:00"},
+		{Days: []string{"sat"}, Allow: "02:30-04:00"},
+	}}
+}
+
+func nextOpen(t *testing.T, w *powermanagev1.MaintenanceWindow, at time.Time) (time.Time, bool) {
+	t.Helper()
+	got, ok, err := maintenance.NextOpen(w, at)
+	if err != nil {
+		t.Fatalf("NextOpen: %v", err)
+	}
+	return got, ok
+}
+
+func nextClose(t *testing.T, w *powermanagev1.MaintenanceWindow, at time.Time) (time.Time, bool) {
+	t.Helper()
+	got, ok, err := maintenance.NextClose(w, at)
+	if err != nil {
+		t.Fatalf("NextClose: %v", err)
+	}
+	return got, ok
+}
+
+func TestNextOpenAndNextClose(t *testing.T) {
+	w := nightlyWindow()
+	// Wednesday 2026-05-06 12:00 UTC.
+	noon := time.Date(2026, time.May, 6, 12, 0, 0, 0, time.UTC)
+	open, ok := nextOpen(t, w, noon)
+	if !ok || !open.Equal(time.Date(2026, time.May, 6, 22, 0, 0, 0, time.UTC)) {
+		t.Fatalf("NextOpen = %v, %v; want Wed 22:00", open, ok)
+	}
+	closing, ok := nextClose(t, w, open)
+	if !ok || !closing.Equal(time.Date(2026, time.May, 7, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("NextClose = %v, %v; want Thu 06:00", closing, ok)
+	}
+	if got, ok := nextClose(t, w, noon); !ok || !got.Equal(noon) {
+		t.Fatalf("NextClose outside the window = %v, %v; want t itself", got, ok)
+	}
+
+	// At 01:00 on Saturday the Friday entry's tail is open, and the
+	// Saturday 02:30 entry lies inside it, so the window closes at 06:00.
+	saturday := time.Date(2026, time.May, 9, 1, 0, 0, 0, time.UTC)
+	if got, ok := nextClose(t, w, saturday); !ok || !got.Equal(time.Date(2026, time.May, 9, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("NextClose in the Friday tail = %v, %v; want Sat 06:00", got, ok)
+	}
+
+	if _, ok := nextClose(t, nil, noon); ok {
+		t.Fatalf("an empty window never closes")
+	}
+	if got, ok := nextOpen(t, nil, noon); !ok || !got.Equal(noon) {
+		t.Fatalf("an empty window is open now")
+	}
+	broken := &powermanagev1.MaintenanceWindow{Schedule: []*powermanagev1.MaintenanceWindowEntry{
+		{Days: []string{"mon"}, Allow: "nonsense"},
+	}}
+	if _, ok := nextOpen(t, broken, noon); ok {
+		t.Fatalf("a window of malformed entries never opens")
+	}
+}
+
+func TestNextCloseOfAlwaysOpenSchedule(t *testing.T) {
+	all := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	w := &powermanagev1.MaintenanceWindow{Schedule: []*powermanagev1.MaintenanceWindowEntry{
+		{Days: all, Allow: "00:00-12:00"},
+		{Days: all, Allow: "12:00-00:00"},
+	}}
+	if _, ok := nextClose(t, w, time.Date(2026, time.May, 6, 12, 0, 0, 0, time.UTC)); ok {
+		t.Fatalf("a schedule covering every minute never closes")
+	}
+}
+
+// A dated exception far in the future fails with ErrBeyondHorizon instead of
+// a day-by-day search to it, and one inside the cap is still searched past.
+func TestNextOpenCapsTheSearchHorizon(t *testing.T) {
+	now := time.Date(2026, time.May, 6, 12, 0, 0, 0, time.UTC)
+	w := nightlyWindow()
+	w.BlackoutDates = []*powermanagev1.MaintenanceWindowDate{{Date: "9999-12-31"}}
+	if _, _, err := maintenance.NextOpen(w, now); !errors.Is(err, maintenance.ErrBeyondHorizon) {
+		t.Fatalf("NextOpen with a year-9999 blackout = %v, want ErrBeyondHorizon", err)
+	}
+	evening := time.Date(2026, time.May, 6, 23, 0, 0, 0, time.UTC)
+	if _, _, err := maintenance.NextClose(w, evening); !errors.Is(err, maintenance.ErrBeyondHorizon) {
+		t.Fatalf("NextClose with a year-9999 blackout = %v, want ErrBeyondHorizon", err)
+	}
+	// Answered without a search, so the far date does not matter.
+	if got, ok, err := maintenance.NextOpen(w, evening); err != nil || !ok || !got.Equal(evening) {
+		t.Fatalf("NextOpen inside the window = %v, %v, %v; want t itself", got, ok, err)
+	}
+
+	// Only an extra date two years out opens the window; it is inside the
+	// cap and found.
+	extra := &powermanagev1.MaintenanceWindow{ExtraDates: []*powermanagev1.MaintenanceWindowDate{{Date: "2028-05-06", Allow: "10:00-11:00"}}}
+	if got, ok := nextOpen(t, extra, now); !ok || !got.Equal(time.Date(2028, time.May, 6, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("NextOpen = %v, %v; want the extra date", got, ok)
+	}
+}
+
+func TestIntervalsMatchIsAllowedAcrossDST(t *testing.T) {
+	w := &powermanagev1.MaintenanceWindow{Schedule: []*powermanagev1.MaintenanceWindowEntry{
+		// 02:30 falls in the spring-forward gap and repeats at fall-back in
+		// both zones below.
+		{Days: []string{"sat", "sun"}, Allow: "02:30-03:30"},
+		{Days: []string{"sat"}, Allow: "23:00-01:45"},
+		{Days: []string{"sun"}, Allow: "01:15-02:15"},
+	}}
+	for _, tc := range []struct {
+		zone  string
+		start time.Time
+	}{
+		{"Europe/Berlin", time.Date(2026, time.March, 27, 0, 0, 0, 0, time.UTC)},
+		{"Europe/Berlin", time.Date(2026, time.October, 23, 0, 0, 0, 0, time.UTC)},
+		{"America/New_York", time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC)},
+		{"America/New_York", time.Date(2026, time.October, 30, 0, 0, 0, 0, time.UTC)},
+	} {
+		location, err := time.LoadLocation(tc.zone)
+		if err != nil {
+			t.Skipf("time zone data unavailable: %v", err)
+		}
+		from := tc.start.In(location)
+		horizon := 4 * 24 * time.Hour
+		var intervals []maintenance.Interval
+		for interval := range maintenance.Intervals(w, from, horizon) {
+			intervals = append(intervals, interval)
+		}
+		if len(intervals) == 0 {
+			t.Fatalf("%s %v: no intervals", tc.zone, tc.start)
+		}
+		for i, interval := range intervals {
+			if !interval.Start.Before(interval.End) {
+				t.Fatalf("%s: interval %d is empty: %v", tc.zone, i, interval)
+			}
+			if i > 0 && !intervals[i-1].End.Before(interval.Start) {
+				t.Fatalf("%s: intervals %d and %d touch or overlap", tc.zone, i-1, i)
+			}
+		}
+		for at := from; at.Before(from.Add(horizon)); at = at.Add(time.Minute) {
+			inside := false
+			for _, interval := range intervals {
+				if !at.Before(interval.Start) && at.Before(interval.End) {
+					inside = true
+					break
+				}
+			}
+			if inside != maintenance.IsAllowed(w, at) {
+				t.Fatalf("%s at %v: Intervals says %v, IsAllowed says %v", tc.zone, at, inside, !inside)
+			}
+		}
+	}
+}
+
+func TestNextOpenSkipsSpringForwardGap(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	w := &powermanagev1.MaintenanceWindow{Schedule: []*powermanagev1.MaintenanceWindowEntry{
+		{Days: []string{"sun"}, Allow: "02:30-04:00"},
+	}}
+	// 2026-03-29: clocks jump from 02:00 CET to 03:00 CEST, so 02:30 never
+	// happens and the window opens when the wall clock reads 03:00.
+	open, ok := nextOpen(t, w, time.Date(2026, time.March, 29, 0, 0, 0, 0, location))
+	want := time.Date(2026, time.March, 29, 3, 0, 0, 0, location)
+	if !ok || !open.Equal(want) {
+		t.Fatalf("NextOpen = %v, %v; want %v", open, ok, want)
+	}
+	if closing, ok := nextClose(t, w, open); !ok || closing.Sub(open) != time.Hour {
+		t.Fatalf("NextClose = %v, %v; want one hour after opening", closing, ok)
+	}
+}
+
+func TestIntervalsStopsWhenYieldReturnsFalse(t *testing.T) {
+	count := 0
+	for range maintenance.Intervals(nightlyWindow(), time.Date(2026, time.May, 4, 0, 0, 0, 0, time.UTC), 14*24*time.Hour) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Fatalf("iterated %d intervals, want 2", count)
+	}
+}
@@ -0,0 +1,233 @@
+package maintenance_test
+
+// Seeded property tests for zones, dated exceptions and Union. Each iteration
+// builds random windows around a random anchor in 2026 (both DST transitions
+// of the zones below fall in range) and checks the invariants IsAllowed,
+// Union and Intervals must hold for every input. A failure prints the seed
+// and iteration; PM_MAINTENANCE_SEED replays it.
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	powermanagev1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/maintenance"
+)
+
+const defaultPropertySeed = 0x3a1d_2026
+
+var propertyZones = []string{"", "UTC", "Europe/Berlin", "America/New_York", "Asia/Kolkata", "Pacific/Chatham"}
+
+func propertySeed(t *testing.T) int64 {
+	if v := os.Getenv("PM_MAINTENANCE_SEED"); v != "" {
+		s, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			t.Fatalf("PM_MAINTENANCE_SEED=%q is not an int64: %v", v, err)
+		}
+		return s
+	}
+	return defaultPropertySeed
+}
+
+type generator struct {
+	r      *rand.Rand
+	anchor time.Time
+}
+
+func newGenerator(r *rand.Rand) *generator {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return &generator{r: r, anchor: start.Add(time.Duration(r.Intn(360*24)) * time.Hour)}
+}
+
+func (g *generator) zone() string { return propertyZones[g.r.Intn(len(propertyZones))] }
+
+// clockRange picks half-hour edges so ranges regularly straddle DST
+// transitions, which happen on the hour in every zone above.
+func (g *generator) clockRange() string {
+	start := g.r.Intn(48) * 30
+	end := g.r.Intn(48) * 30
+	for end == start {
+		end = g.r.Intn(48) * 30
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", start/60, start%60, end/60, end%60)
+}
+
+func (g *generator) dates() []*powermanagev1.MaintenanceWindowDate {
+	var out []*powermanagev1.MaintenanceWindowDate
+	for range g.r.Intn(3) {
+		d := &powermanagev1.MaintenanceWindowDate{Date: g.anchor.AddDate(0, 0, g.r.Intn(5)-2).Format("2006-01-02")}
+		if g.r.Intn(3) > 0 {
+			d.Allow = g.clockRange()
+		}
+		if g.r.Intn(4) == 0 {
+			d.TimeZone = g.zone()
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func (g *generator) window() *powermanagev1.MaintenanceWindow {
+	w := &powermanagev1.MaintenanceWindow{TimeZone: g.zone(), BlackoutDates: g.dates(), ExtraDates: g.dates()}
+	days := []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+	for range g.r.Intn(4) {
+		e := &powermanagev1.MaintenanceWindowEntry{Allow: g.clockRange()}
+		for _, d := range days {
+			if g.r.Intn(3) == 0 {
+				e.Days = append(e.Days, d)
+			}
+		}
+		if len(e.Days) == 0 {
+			e.Days = []string{days[g.r.Intn(len(days))]}
+		}
+		if g.r.Intn(4) == 0 {
+			e.TimeZone = g.zone()
+		}
+		w.Schedule = append(w.Schedule, e)
+	}
+	return w
+}
+
+// moment picks a minute within four days of the anchor, in a random zone.
+func (g *generator) moment() time.Time {
+	name := g.zone()
+	if name == "" {
+		name = "Asia/Tokyo"
+	}
+	location, _ := time.LoadLocation(name)
+	return g.anchor.Add(time.Duration(g.r.Intn(8*24*60)-4*24*60) * time.Minute).In(location)
+}
+
+// allowlist and blackouts split a window into the two halves IsAllowed
+// combines.
+func allowlist(w *powermanagev1.MaintenanceWindow) *powermanagev1.MaintenanceWindow {
+	return &powermanagev1.MaintenanceWindow{TimeZone: w.TimeZone, Schedule: w.Schedule, ExtraDates: w.ExtraDates}
+}
+
+func blackouts(w *powermanagev1.MaintenanceWindow) *powermanagev1.MaintenanceWindow {
+	return &powermanagev1.MaintenanceWindow{TimeZone: w.TimeZone, BlackoutDates: w.BlackoutDates}
+}
+
+func TestWindowProperties(t *testing.T) {
+	seed := propertySeed(t)
+	r := rand.New(rand.NewSource(seed))
+	for i := range 400 {
+		g := newGenerator(r)
+		windows := make([]*powermanagev1.MaintenanceWindow, 1+r.Intn(3))
+		for j := range windows {
+			windows[j] = g.window()
+		}
+		if r.Intn(8) == 0 {
+			windows[r.Intn(len(windows))] = nil
+		}
+		for _, w := range windows {
+			if err := maintenance.Validate(w); err != nil {
+				t.Fatalf("seed %d iteration %d: generated an invalid window: %v", seed, i, err)
+			}
+		}
+		union := maintenance.Union(windows...)
+		if err := maintenance.Validate(union); err != nil {
+			t.Fatalf("seed %d iteration %d: union is invalid: %v", seed, i, err)
+		}
+		reversed := make([]*powermanagev1.MaintenanceWindow, len(windows))
+		for j, w := range windows {
+			reversed[len(windows)-1-j] = w
+		}
+		unionReversed := maintenance.Union(reversed...)
+
+		for range 40 {
+			at := g.moment()
+			fail := func(format string, args ...any) {
+				t.Helper()
+				t.Fatalf("seed %d iteration %d at %v: %s", seed, i, at, fmt.Sprintf(format, args...))
+			}
+			allowed, denied := false, false
+			for _, w := range windows {
+				if w == nil {
+					allowed = true
+					continue
+				}
+				// P1: blackouts subtract from the allowlist and nothing else.
+				base, open := maintenance.IsAllowed(allowlist(w), at), maintenance.IsAllowed(blackouts(w), at)
+				if got := maintenance.IsAllowed(w, at); got != (base && open) {
+					fail("IsAllowed = %v, allowlist %v, outside blackouts %v", got, base, open)
+				}
+				// P2: a window zone reads the same wall clock as passing the
+				// time already converted into that zone.
+				if w.TimeZone != "" {
+					location, _ := time.LoadLocation(w.TimeZone)
+					unzoned := &powermanagev1.MaintenanceWindow{Schedule: w.Schedule, BlackoutDates: w.BlackoutDates, ExtraDates: w.ExtraDates}
+					if maintenance.IsAllowed(w, at) != maintenance.IsAllowed(unzoned, at.In(location)) && !hasOwnZones(w) {
+						fail("window zone %s disagrees with converting the time", w.TimeZone)
+					}
+				}
+				allowed = allowed || base
+				denied = denied || !open
+			}
+			// P3: the union ORs allowlists and ORs blackouts, whatever the
+			// zones, and does not depend on input order.
+			if got := maintenance.IsAllowed(union, at); got != (allowed && !denied) {
+				fail("union = %v, any allowlist %v, any blackout %v", got, allowed, denied)
+			}
+			if maintenance.IsAllowed(unionReversed, at) != maintenance.IsAllowed(union, at) {
+				fail("union depends on input order")
+			}
+			// P4: NextOpen and NextClose land on a minute edge of the window.
+			if open, ok := nextOpen(t, union, at); ok {
+				if !maintenance.IsAllowed(union, open) || (!open.Equal(at) && maintenance.IsAllowed(union, open.Add(-time.Minute))) {
+					fail("NextOpen = %v is not an opening edge", open)
+				}
+			}
+			if closing, ok := nextClose(t, union, at); ok {
+				if maintenance.IsAllowed(union, closing) || (!closing.Equal(at) && !maintenance.IsAllowed(union, closing.Add(-time.Minute))) {
+					fail("NextClose = %v is not a closing edge", closing)
+				}
+			}
+		}
+		checkIntervals(t, seed, i, union, g.moment().Truncate(time.Minute))
+	}
+}
+
+func hasOwnZones(w *powermanagev1.MaintenanceWindow) bool {
+	for _, e := range w.Schedule {
+		if e.TimeZone != "" {
+			return true
+		}
+	}
+	for _, dates := range [][]*powermanagev1.MaintenanceWindowDate{w.BlackoutDates, w.ExtraDates} {
+		for _, d := range dates {
+			if d.TimeZone != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkIntervals is P5: Intervals agrees with IsAllowed at every minute of a
+// three-day horizon.
+func checkIntervals(t *testing.T, seed int64, iteration int, w *powermanagev1.MaintenanceWindow, from time.Time) {
+	t.Helper()
+	horizon := 3 * 24 * time.Hour
+	var intervals []maintenance.Interval
+	for interval := range maintenance.Intervals(w, from, horizon) {
+		if n := len(intervals); !interval.Start.Before(interval.End) || (n > 0 && !intervals[n-1].End.Before(interval.Start)) {
+			t.Fatalf("seed %d iteration %d: interval %v is empty, touches or overlaps its predecessor", seed, iteration, interval)
+		}
+		intervals = append(intervals, interval)
+	}
+	next := 0
+	for at := from; at.Before(from.Add(horizon)); at = at.Add(time.Minute) {
+		for next < len(intervals) && !at.Before(intervals[next].End) {
+			next++
+		}
+		inside := next < len(intervals) && !at.Before(intervals[next].Start)
+		if inside != maintenance.IsAllowed(w, at) {
+			t.Fatalf("seed %d iteration %d at %v: Intervals says %v, IsAllowed says %v", seed, iteration, at, inside, !inside)
+		}
+	}
+}
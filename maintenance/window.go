@@ -6,30 +6,43 @@
 //
 // Window semantics:
 //
-//   - Empty schedule = "always allowed". The feature is opt-in and a
-//     group with no window contributes nothing to the device-side
-//     gate.
+//   - Empty schedule and no extra dates = "always allowed". The feature
+//     is opt-in and a group with no window contributes nothing to the
+//     device-side gate.
 //   - Each entry is (weekdays, allow-range). The allow-range uses
 //     24-hour HH:MM-HH:MM. start > end means the range crosses
 //     midnight and continues into the next weekday.
 //   - Multiple entries combine as OR within a single window: any
-//     matching entry allows the moment.
-//   - Union across windows applies the same OR. The device is allowed
-//     when *any* of its reaching groups allows the moment. If any
-//     reaching group is empty, the union collapses to "always
-//     allowed" — empty already means unconstrained, so adding it to
-//     the union cannot tighten the result.
+//     matching entry allows the moment. Extra dates OR in the same way.
+//   - Blackout dates deny the moment whatever the schedule and extra
+//     dates say, including in an otherwise always-allowed window.
+//   - A window's time zone, or an entry's or date's own, fixes the
+//     wall clock it is read in. Without one, the wall clock is that of
+//     the time being evaluated.
+//   - Union across windows ORs the schedules and extra dates: the
+//     device is allowed when *any* of its reaching groups allows the
+//     moment. If any reaching group has no schedule and no extra dates,
+//     that part collapses to "always allowed" — empty already means
+//     unconstrained, so adding it to the union cannot tighten the
+//     result. Blackout dates are the exception: a blackout in any
+//     reaching group denies the moment, so a change freeze on one
+//     group cannot be reopened by another.
 //
-// All evaluation runs against time.Time.Local at the agent. The
-// server never computes IsAllowed — the device is the only authority
-// on its own wall-clock.
+// Without a time zone, evaluation runs against time.Time.Local at the
+// agent. The server never computes IsAllowed — the device is the only
+// authority on its own wall-clock.
 package maintenance
 
 import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
+	// The zone database is embedded so a window's time zone resolves the
+	// same on every agent and on the server that validated it, whether or
+	// not the host ships tzdata.
+	_ "time/tzdata"
 
 	powermanagev1 "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
 )
@@ -38,6 +51,9 @@ import (
 // into it directly when matching entries.
 var weekdayTokens = [7]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
 
+// dateLayout is the wire format of MaintenanceWindowDate.date.
+const dateLayout = "2006-01-02"
+
 // ErrInvalidEntry is returned by Validate when the schedule contains
 // a malformed entry. Callers wrap this in a Connect-RPC
 // CodeInvalidArgument so the web UI can surface a precise message.
@@ -49,52 +65,203 @@ func Validate(w *powermanagev1.MaintenanceWindow) error {
 	if w == nil {
 		return nil
 	}
+	if err := validateZone(w.GetTimeZone()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEntry, err)
+	}
 	for i, e := range w.GetSchedule() {
 		if err := validateEntry(e); err != nil {
 			return fmt.Errorf("%w: entry %d: %v", ErrInvalidEntry, i, err)
 		}
 	}
+	for i, d := range w.GetBlackoutDates() {
+		if err := validateDate(d); err != nil {
+			return fmt.Errorf("%w: blackout date %d: %v", ErrInvalidEntry, i, err)
+		}
+	}
+	for i, d := range w.GetExtraDates() {
+		if err := validateDate(d); err != nil {
+			return fmt.Errorf("%w: extra date %d: %v", ErrInvalidEntry, i, err)
+		}
+	}
 	return nil
 }
 
 // IsAllowed reports whether the moment t falls inside the window. A
-// nil window or empty schedule allows every moment. The check uses
-// t.Weekday() and t.Hour()/t.Minute() — callers that want
-// device-local semantics must pass an already-Local'd time.
+// nil window, or one with no schedule, extra dates or blackout dates,
+// allows every moment. Entries and dates without a time zone are read
+// from t's own wall clock — callers that want device-local semantics
+// must pass an already-Local'd time.
 func IsAllowed(w *powermanagev1.MaintenanceWindow, t time.Time) bool {
-	if w == nil || len(w.GetSchedule()) == 0 {
+	if w == nil {
+		return true
+	}
+	for _, d := range w.GetBlackoutDates() {
+		at, ok := inZone(t, w, d.GetTimeZone())
+		// A blackout whose zone does not resolve denies: the operator
+		// asked for a freeze, and an unreadable freeze is still one.
+		if !ok || dateCovers(d, at) {
+			return false
+		}
+	}
+	if unconstrained(w) {
 		return true
 	}
 	for _, e := range w.GetSchedule() {
-		if entryAllows(e, t) {
+		if at, ok := inZone(t, w, e.GetTimeZone()); ok && entryAllows(e, at) {
+			return true
+		}
+	}
+	for _, d := range w.GetExtraDates() {
+		if at, ok := inZone(t, w, d.GetTimeZone()); ok && dateCovers(d, at) {
 			return true
 		}
 	}
 	return false
 }
 
-// Union combines windows the most-permissive way: if any input is
-// empty (nil or zero schedule), the result is empty (= always
-// allowed). Otherwise the result is the concatenation of every
-// input's entries — IsAllowed already ORs entries within a single
-// window, so concatenation gives OR across windows for free.
+// Unconstrained reports whether the window allows every moment: it is nil
+// or carries no schedule, extra dates or blackout dates.
+func Unconstrained(w *powermanagev1.MaintenanceWindow) bool {
+	return unconstrained(w) && len(w.GetBlackoutDates()) == 0
+}
+
+// Union combines windows the most-permissive way, except for blackouts.
+// If any input is nil or has no schedule and no extra dates, the result
+// has none either (= always allowed). Otherwise the result carries every input's
+// entries and extra dates — IsAllowed already ORs them within a single
+// window, so concatenation gives OR across windows for free. The result
+// carries every input's blackout dates too, so a blackout in any input
+// denies the union.
 //
-// The returned window aliases input entry pointers; callers that
-// mutate the result must clone first. Server-side resolvers don't
+// When every input shares one time zone the result keeps it. When they
+// differ, the result has no window zone and each entry and date carries
+// the zone of the window it came from, so IsAllowed evaluates every
+// piece exactly as its own window would have.
+//
+// Entries and dates are aliased when no zone has to be pinned; callers
+// that mutate the result must clone first. Server-side resolvers don't
 // mutate, so the alias is safe in practice.
 func Union(windows ...*powermanagev1.MaintenanceWindow) *powermanagev1.MaintenanceWindow {
+	out := &powermanagev1.MaintenanceWindow{}
+	pin := false
 	for _, w := range windows {
-		if w == nil || len(w.GetSchedule()) == 0 {
-			return &powermanagev1.MaintenanceWindow{}
+		if w.GetTimeZone() != windows[0].GetTimeZone() {
+			pin = true
+			break
 		}
 	}
-	out := &powermanagev1.MaintenanceWindow{}
+	if !pin && len(windows) > 0 {
+		out.TimeZone = windows[0].GetTimeZone()
+	}
+	open := false
 	for _, w := range windows {
-		out.Schedule = append(out.Schedule, w.GetSchedule()...)
+		if unconstrained(w) {
+			open = true
+		}
+		out.BlackoutDates = append(out.BlackoutDates, pinDates(w.GetBlackoutDates(), w.GetTimeZone(), pin)...)
+	}
+	if open {
+		return out
+	}
+	for _, w := range windows {
+		out.Schedule = append(out.Schedule, pinEntries(w.GetSchedule(), w.GetTimeZone(), pin)...)
+		out.ExtraDates = append(out.ExtraDates, pinDates(w.GetExtraDates(), w.GetTimeZone(), pin)...)
+	}
+	return out
+}
+
+// unconstrained reports whether the window's allowlist is empty, which
+// allows every moment its blackout dates do not deny.
+func unconstrained(w *powermanagev1.MaintenanceWindow) bool {
+	return len(w.GetSchedule()) == 0 && len(w.GetExtraDates()) == 0
+}
+
+func pinEntries(entries []*powermanagev1.MaintenanceWindowEntry, zone string, pin bool) []*powermanagev1.MaintenanceWindowEntry {
+	if !pin || zone == "" {
+		return entries
+	}
+	out := make([]*powermanagev1.MaintenanceWindowEntry, 0, len(entries))
+	for _, e := range entries {
+		if e != nil && e.TimeZone == "" {
+			e = &powermanagev1.MaintenanceWindowEntry{Days: e.Days, Allow: e.Allow, TimeZone: zone}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func pinDates(dates []*powermanagev1.MaintenanceWindowDate, zone string, pin bool) []*powermanagev1.MaintenanceWindowDate {
+	if !pin || zone == "" {
+		return dates
+	}
+	out := make([]*powermanagev1.MaintenanceWindowDate, 0, len(dates))
+	for _, d := range dates {
+		if d != nil && d.TimeZone == "" {
+			d = &powermanagev1.MaintenanceWindowDate{Date: d.Date, Allow: d.Allow, TimeZone: zone}
+		}
+		out = append(out, d)
 	}
 	return out
 }
 
+// zoneFor resolves the location a piece of w is read in: its own zone,
+// else the window's, else fallback. It reports false when the named zone
+// does not load.
+func zoneFor(w *powermanagev1.MaintenanceWindow, own string, fallback *time.Location) (*time.Location, bool) {
+	name := own
+	if name == "" {
+		name = w.GetTimeZone()
+	}
+	if name == "" {
+		return fallback, true
+	}
+	location, err := loadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return location, true
+}
+
+func inZone(t time.Time, w *powermanagev1.MaintenanceWindow, own string) (time.Time, bool) {
+	location, ok := zoneFor(w, own, t.Location())
+	if !ok {
+		return time.Time{}, false
+	}
+	return t.In(location), true
+}
+
+// locations caches loaded zones: IsAllowed runs on every scheduler tick
+// and time.LoadLocation parses the zone file on each call.
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if cached, ok := locations.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// validateZone accepts an empty zone or an IANA zone name. "Local" is
+// refused: it names whatever zone the evaluating process runs in, which
+// is what an empty zone already means.
+func validateZone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if name == "Local" {
+		return errors.New(`time zone "Local" is implicit; leave the zone empty`)
+	}
+	if _, err := loadLocation(name); err != nil {
+		return fmt.Errorf("time zone %q: %v", name, err)
+	}
+	return nil
+}
+
 // validateEntry checks a single MaintenanceWindowEntry for shape.
 // Returns the underlying parse error so Validate can wrap it with
 // the entry index for a useful operator-facing message.
@@ -118,7 +285,23 @@ func validateEntry(e *powermanagev1.MaintenanceWindowEntry) error {
 	if _, _, err := parseRange(e.Allow); err != nil {
 		return err
 	}
-	return nil
+	return validateZone(e.TimeZone)
+}
+
+// validateDate checks a single MaintenanceWindowDate for shape.
+func validateDate(d *powermanagev1.MaintenanceWindowDate) error {
+	if d == nil {
+		return errors.New("nil date")
+	}
+	if _, err := time.Parse(dateLayout, d.Date); err != nil {
+		return fmt.Errorf("date %q must be YYYY-MM-DD", d.Date)
+	}
+	if d.Allow != "" {
+		if _, _, err := parseRange(d.Allow); err != nil {
+			return err
+		}
+	}
+	return validateZone(d.TimeZone)
 }
 
 // entryAllows checks t against one (days, range) entry. Crosses
@@ -165,6 +348,42 @@ func entryAllows(e *powermanagev1.MaintenanceWindowEntry, t time.Time) bool {
 	return false
 }
 
+// dateCovers checks t's wall clock against one dated exception. An
+// exception without a range covers its whole date; a range that crosses
+// midnight continues into the next date.
+func dateCovers(d *powermanagev1.MaintenanceWindowDate, t time.Time) bool {
+	if d == nil {
+		return false
+	}
+	startMin, endMin, ok := dateRange(d)
+	if !ok {
+		return false
+	}
+	date, err := time.Parse(dateLayout, d.Date)
+	if err != nil {
+		return false
+	}
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	tMin := t.Hour()*60 + t.Minute()
+	switch {
+	case today.Equal(date):
+		return tMin >= startMin && (startMin > endMin || tMin < endMin)
+	case today.Equal(date.AddDate(0, 0, 1)):
+		return startMin > endMin && tMin < endMin
+	}
+	return false
+}
+
+// dateRange returns a dated exception's range in minutes of day. An empty
+// allow spans the whole date.
+func dateRange(d *powermanagev1.MaintenanceWindowDate) (int, int, bool) {
+	if d.Allow == "" {
+		return 0, 24 * 60, true
+	}
+	startMin, endMin, err := parseRange(d.Allow)
+	return startMin, endMin, err == nil
+}
+
 func entryListsDay(e *powermanagev1.MaintenanceWindowEntry, day string) bool {
 	for _, d := range e.Days {
 		if d == day {
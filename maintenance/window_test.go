@@ -96,6 +96,35 @@ func TestValidate(t *testing.T) {
 			}},
 			true,
 		},
+		{
+			"zone and dates",
+			&powermanagev1.MaintenanceWindow{
+				TimeZone:      "Europe/Berlin",
+				Schedule:      []*powermanagev1.MaintenanceWindowEntry{{Days: []string{"sat"}, Allow: "02:00-04:00", TimeZone: "UTC"}},
+				BlackoutDates: []*powermanagev1.MaintenanceWindowDate{{Date: "2026-12-24"}},
+				ExtraDates:    []*powermanagev1.MaintenanceWindowDate{{Date: "2026-12-27", Allow: "22:00-02:00", TimeZone: "America/New_York"}},
+			},
+			false,
+		},
+		{"unknown zone", &powermanagev1.MaintenanceWindow{TimeZone: "Mars/Olympus_Mons"}, true},
+		{"Local zone", &powermanagev1.MaintenanceWindow{TimeZone: "Local"}, true},
+		{
+			"unknown entry zone",
+			&powermanagev1.MaintenanceWindow{Schedule: []*powermanagev1.MaintenanceWindowEntry{
+				{Days: []string{"mon"}, Allow: "09:00-17:00", TimeZone: "Nowhere"},
+			}},
+			true,
+		},
+		{
+			"bad blackout date",
+			&powermanagev1.MaintenanceWindow{BlackoutDates: []*powermanagev1.MaintenanceWindowDate{{Date: "2026-02-30"}}},
+			true,
+		},
+		{
+			"bad extra date range",
+			&powermanagev1.MaintenanceWindow{ExtraDates: []*powermanagev1.MaintenanceWindowDate{{Date: "2026-02-03", Allow: "09:00-09:00"}}},
+			true,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -222,3 +251,118 @@ func TestIsAllowedNilOrEmpty(t *testing.T) {
 		t.Fatalf("empty schedule must allow")
 	}
 }
+
+func TestIsAllowedInWindowZone(t *testing.T) {
+	w := &powermanagev1.MaintenanceWindow{
+		TimeZone: "Asia/Tokyo",
+		Schedule: []*powermanagev1.MaintenanceWindowEntry{{Days: []string{"mon"}, Allow: "02:00-04:00"}},
+	}
+	// Monday 02:30 in Tokyo is Sunday 17:30 UTC and 19:30 in Berlin: the
+	// device's own zone no longer matters.
+	at := time.Date(2026, time.May, 3, 17, 30, 0, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maintenance.IsAllowed(w, at) || !maintenance.IsAllowed(w, at.In(berlin)) {
+		t.Fatalf("want allowed at Mon 02:30 Tokyo whatever the caller's zone")
+	}
+	if maintenance.IsAllowed(w, time.Date(2026, time.May, 4, 2, 30, 0, 0, time.UTC)) {
+		t.Fatalf("want denied at Mon 02:30 UTC, which is 11:30 in Tokyo")
+	}
+	// An entry's own zone overrides the window's.
+	w.Schedule[0].TimeZone = "UTC"
+	if !maintenance.IsAllowed(w, time.Date(2026, time.May, 4, 2, 30, 0, 0, time.UTC)) {
+		t.Fatalf("want the entry's UTC zone to win over the window's")
+	}
+	// A zone that does not load fails closed.
+	w.Schedule[0].TimeZone = "Nowhere"
+	if maintenance.IsAllowed(w, time.Date(2026, time.May, 4, 2, 30, 0, 0, time.UTC)) {
+		t.Fatalf("an unloadable zone must never allow")
+	}
+}
+
+func TestBlackoutAndExtraDates(t *testing.T) {
+	w := &powermanagev1.MaintenanceWindow{
+		Schedule: []*powermanagev1.MaintenanceWindowEntry{
+			{Days: []string{"thu", "fri"}, Allow: "22:00-06:00"},
+		},
+		// Thursday 2026-12-24, overnight into Friday.
+		BlackoutDates: []*powermanagev1.MaintenanceWindowDate{{Date: "2026-12-24", Allow: "20:00-04:00"}},
+		// Sunday 2026-12-27, all day.
+		ExtraDates: []*powermanagev1.MaintenanceWindowDate{{Date: "2026-12-27"}},
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.December, day, hour, minute, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{at(24, 23, 0), false}, // schedule allows, blackout denies
+		{at(25, 3, 59), false}, // the blackout's midnight-crossing tail
+		{at(25, 4, 0), true},   // tail ends; Thursday's entry still runs
+		{at(25, 22, 0), true},  // Friday is not blacked out
+		{at(27, 12, 0), true},  // extra date on a Sunday
+		{at(28, 0, 0), false},  // extra date ends at midnight
+		{at(31, 23, 0), true},  // the schedule repeats as usual
+	} {
+		if got := maintenance.IsAllowed(w, tc.at); got != tc.want {
+			t.Fatalf("IsAllowed at %v = %v, want %v", tc.at, got, tc.want)
+		}
+	}
+
+	// Blackouts apply even without an allowlist: a freeze-only window.
+	freeze := &powermanagev1.MaintenanceWindow{BlackoutDates: w.BlackoutDates}
+	if maintenance.IsAllowed(freeze, at(24, 23, 0)) || !maintenance.IsAllowed(freeze, at(23, 12, 0)) {
+		t.Fatalf("a freeze-only window must deny its dates and allow the rest")
+	}
+	if maintenance.Unconstrained(freeze) || !maintenance.Unconstrained(&powermanagev1.MaintenanceWindow{}) {
+		t.Fatalf("Unconstrained must account for blackout dates")
+	}
+	// Extra dates alone make a window that only opens on those dates.
+	oneOff := &powermanagev1.MaintenanceWindow{ExtraDates: w.ExtraDates}
+	if maintenance.IsAllowed(oneOff, at(26, 12, 0)) || !maintenance.IsAllowed(oneOff, at(27, 12, 0)) {
+		t.Fatalf("an extra-dates-only window must open on its dates only")
+	}
+}
+
+func TestUnionPinsZonesAndKeepsBlackouts(t *testing.T) {
+	tokyo := &powermanagev1.MaintenanceWindow{
+		TimeZone: "Asia/Tokyo",
+		Schedule: []*powermanagev1.MaintenanceWindowEntry{{Days: []string{"mon"}, Allow: "02:00-04:00"}},
+	}
+	local := &powermanagev1.MaintenanceWindow{
+		Schedule:      []*powermanagev1.MaintenanceWindowEntry{{Days: []string{"sat"}, Allow: "08:00-12:00"}},
+		BlackoutDates: []*powermanagev1.MaintenanceWindowDate{{Date: "2026-05-09"}},
+	}
+	got := maintenance.Union(tokyo, local)
+	if got.GetTimeZone() != "" || got.GetSchedule()[0].GetTimeZone() != "Asia/Tokyo" || got.GetSchedule()[1].GetTimeZone() != "" {
+		t.Fatalf("differing zones must be pinned per entry, got %v", got)
+	}
+	if tokyo.Schedule[0].TimeZone != "" {
+		t.Fatalf("Union must not mutate its inputs")
+	}
+	if !maintenance.IsAllowed(got, time.Date(2026, time.May, 3, 17, 30, 0, 0, time.UTC)) {
+		t.Fatalf("the Tokyo entry must still be read in Tokyo")
+	}
+	if maintenance.IsAllowed(got, time.Date(2026, time.May, 9, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("a blackout in one window must deny the union")
+	}
+
+	same := maintenance.Union(tokyo, tokyo)
+	if same.GetTimeZone() != "Asia/Tokyo" || same.GetSchedule()[0].GetTimeZone() != "" {
+		t.Fatalf("a shared zone must stay on the window, got %v", same)
+	}
+
+	// An unconstrained input still collapses the allowlist, but not the
+	// blackouts of the others.
+	collapsed := maintenance.Union(local, nil)
+	if len(collapsed.GetSchedule()) != 0 || len(collapsed.GetBlackoutDates()) != 1 {
+		t.Fatalf("want no entries and one blackout, got %v", collapsed)
+	}
+	if !maintenance.IsAllowed(collapsed, time.Date(2026, time.May, 8, 14, 0, 0, 0, time.UTC)) ||
+		maintenance.IsAllowed(collapsed, time.Date(2026, time.May, 9, 14, 0, 0, 0, time.UTC)) {
+		t.Fatalf("the collapsed union must allow everything but the blackout")
+	}
+}
@@ -17,9 +17,10 @@ import (
 // English form regardless of the host locale. A non-zero exit is reported in
 // Result.ExitCode (NOT as an error) — read callers branch on specific codes (e.g.
 // dnf check-update's 100, dpkg -s's 1) — so the returned error is non-nil only
-// when the command could not be executed at all.
+// when the command could not be executed at all. Reads are ReadOnly, so a
+// dry-run Runner still answers them from the host.
 func runRead(ctx context.Context, r pmexec.Runner, name string, args ...string) (pmexec.Result, error) {
-	return r.Run(ctx, pmexec.Command{Name: name, Args: args})
+	return r.Run(ctx, pmexec.Command{Name: name, Args: args, ReadOnly: true})
 }
 
 // probe runs an unprivileged read whose non-zero exit is a benign domain signal
@@ -22,7 +22,7 @@ func (m *clamavManager) Scan(ctx context.Context, path string) (ScanResult, erro
 		return ScanResult{}, err
 	}
 	args := append([]string{"-r", "--no-summary", "--infected"}, exec.SeparatePositionals(nil, path)...)
-	res, err := m.r.Run(ctx, exec.Command{Name: "clamscan", Args: args, Escalate: true})
+	res, err := m.r.Run(ctx, exec.Command{Name: "clamscan", Args: args, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return ScanResult{}, fmt.Errorf("antivirus: run clamscan: %w", err)
 	}
@@ -47,7 +47,7 @@ func (m *clamavManager) UpdateSignatures(ctx context.Context) error {
 // Version parses `clamscan --version` (unprivileged), e.g.
 // "ClamAV 1.0.1/27000/Wed Jun 18 09:00:00 2025".
 func (m *clamavManager) Version(ctx context.Context) (Version, error) {
-	res, err := m.r.Run(ctx, exec.Command{Name: "clamscan", Args: []string{"--version"}})
+	res, err := m.r.Run(ctx, exec.Command{Name: "clamscan", Args: []string{"--version"}, ReadOnly: true})
 	if err != nil {
 		return Version{}, fmt.Errorf("antivirus: run clamscan --version: %w", err)
 	}
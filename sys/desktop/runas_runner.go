@@ -87,5 +87,6 @@ func (ra *runAsRunner) wrap(c pmexec.Command) (pmexec.Command, error) {
 		Dir:      c.Dir, // run in the caller's working dir (default: the user's home)
 		Stdin:    c.Stdin,
 		Escalate: false, // runuser from root IS the privilege drop
+		ReadOnly: c.ReadOnly,
 	}, nil
 }
@@ -107,7 +107,7 @@ func (m *manager) ActiveSessions(ctx context.Context) ([]Session, error) {
 // that AREN'T one of those two patterns get surfaced as an actual error so
 // genuine permission/IO faults still page operators.
 func (m *manager) listSessionIDs(ctx context.Context) ([]string, error) {
-	res, err := m.r.Run(ctx, pmexec.Command{Name: loginctlPath, Args: []string{"list-sessions", "--no-legend"}})
+	res, err := m.r.Run(ctx, pmexec.Command{Name: loginctlPath, Args: []string{"list-sessions", "--no-legend"}, ReadOnly: true})
 	if err != nil {
 		return nil, fmt.Errorf("loginctl list-sessions: %w", err)
 	}
@@ -144,7 +144,7 @@ func (m *manager) loadSession(ctx context.Context, id string) (Session, bool, er
 		"--property=Type",
 		"--property=Active",
 		"--property=Remote",
-	}})
+	}, ReadOnly: true})
 	if err != nil {
 		return Session{}, false, fmt.Errorf("loginctl show-session: %w", err)
 	}
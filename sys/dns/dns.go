@@ -139,7 +139,7 @@ func runPriv(ctx context.Context, r exec.Runner, name string, args ...string) er
 // runRead runs an unprivileged query through the Runner and returns its stdout,
 // mapping a non-zero exit (or exec failure) into an error.
 func runRead(ctx context.Context, r exec.Runner, name string, args ...string) (string, error) {
-	res, err := r.Run(ctx, exec.Command{Name: name, Args: args})
+	res, err := r.Run(ctx, exec.Command{Name: name, Args: args, ReadOnly: true})
 	if err != nil {
 		return "", err
 	}
@@ -24,7 +24,7 @@ type lsblkDevice struct {
 // DetectAllVolumes returns every LUKS volume on the system (lsblk -J; read-only,
 // unprivileged).
 func (l *luks) DetectAllVolumes(ctx context.Context) ([]Volume, error) {
-	res, err := l.r.Run(ctx, exec.Command{Name: "lsblk", Args: []string{"-J", "-o", "NAME,TYPE,FSTYPE,MOUNTPOINT"}})
+	res, err := l.r.Run(ctx, exec.Command{Name: "lsblk", Args: []string{"-J", "-o", "NAME,TYPE,FSTYPE,MOUNTPOINT"}, ReadOnly: true})
 	if err != nil {
 		return nil, fmt.Errorf("lsblk: %w", err)
 	}
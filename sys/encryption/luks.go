@@ -51,7 +51,7 @@ func (l *luks) IsEncrypted(ctx context.Context, dev string) (bool, error) {
 	if err := validateDevicePath(dev); err != nil {
 		return false, err
 	}
-	res, err := l.r.Run(ctx, exec.Command{Name: "cryptsetup", Args: []string{"isLuks", dev}, Escalate: true})
+	res, err := l.r.Run(ctx, exec.Command{Name: "cryptsetup", Args: []string{"isLuks", dev}, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return false, fmt.Errorf("cryptsetup isLuks: %w", err)
 	}
@@ -162,6 +162,7 @@ func (l *luks) VerifyPassphrase(ctx context.Context, dev string, p exec.Secret)
 		Name:     "cryptsetup",
 		Args:     []string{"open", "--test-passphrase", dev, "--key-file", keyFile.path, "--batch-mode"},
 		Escalate: true,
+		ReadOnly: true,
 	})
 	if err != nil {
 		return false, fmt.Errorf("cryptsetup test-passphrase: %w", err)
@@ -0,0 +1,172 @@
+package exec
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// credentialKeys are the name patterns whose argument values RedactArgs
+// hides. They are logging.DefaultRedactKeys, repeated here because logging
+// is kept free of this package and its tests import it.
+var credentialKeys = []string{
+	"password", "passwd", "passphrase", "secret", "token", "psk",
+	"private_key", "client_key", "preshared_key", "api_key", "authorization", "cookie",
+}
+
+// AuditRecord is one command as the Audit middleware saw it. It never holds
+// stdin, where credentials travel (a Secret is never put in argv), and the
+// value of any argument named like a credential is redacted, see RedactArgs.
+type AuditRecord struct {
+	Name     string
+	Args     []string
+	Dir      string
+	User     string
+	Escalate bool
+	// Backend is the Runner's privilege backend; it is how the command was
+	// escalated when Escalate is set.
+	Backend  PrivilegeBackend
+	ReadOnly bool
+	// Stdin reports whether the command had stdin. Its content is never
+	// recorded.
+	Stdin    bool
+	Start    time.Time
+	Duration time.Duration
+	ExitCode int
+	// Err is why the command could not run or finish; nil when it ran to an
+	// exit code.
+	Err error
+}
+
+// Escalation names how the command was escalated: "sudo", "doas", "run0",
+// "pkexec" or "direct" (the agent is already root), or "" when it ran as the
+// agent.
+func (r AuditRecord) Escalation() string {
+	if !r.Escalate {
+		return ""
+	}
+	if tool := escalationTool(r.Backend); tool != "" {
+		return tool
+	}
+	return "direct"
+}
+
+// LogValue renders the record as a group of attributes, so
+// slog.Any("command", rec) logs it structured.
+func (r AuditRecord) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("name", r.Name),
+		slog.Any("args", r.Args),
+	}
+	if e := r.Escalation(); e != "" {
+		attrs = append(attrs, slog.String("escalation", e))
+	}
+	if r.User != "" {
+		attrs = append(attrs, slog.String("user", r.User))
+	}
+	if r.Dir != "" {
+		attrs = append(attrs, slog.String("dir", r.Dir))
+	}
+	attrs = append(attrs,
+		slog.Int("exit_code", r.ExitCode),
+		slog.Duration("duration", r.Duration),
+	)
+	if r.Err != nil {
+		attrs = append(attrs, slog.String("error", r.Err.Error()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// AuditSink receives a record after each command finishes, on the calling
+// goroutine. A sink must be safe for concurrent use when the Runner is.
+type AuditSink func(ctx context.Context, rec AuditRecord)
+
+// Audit records every command that passes through it, after it finishes, to
+// each sink. Place it outside a Timeouts middleware to record the timeout,
+// and outside a DryRunner to record the plan rather than real runs.
+func Audit(sinks ...AuditSink) Middleware {
+	return func(next Runner) Runner {
+		backend := next.Backend()
+		return Intercept(func(ctx context.Context, c Command, call CallFunc) (Result, error) {
+			start := time.Now()
+			res, err := call(ctx, c)
+			rec := AuditRecord{
+				Name:     c.Name,
+				Args:     RedactArgs(c.Args),
+				Dir:      c.Dir,
+				User:     c.User,
+				Escalate: c.Escalate,
+				Backend:  backend,
+				ReadOnly: c.ReadOnly,
+				Stdin:    c.Stdin != nil,
+				Start:    start,
+				Duration: time.Since(start),
+				ExitCode: res.ExitCode,
+				Err:      err,
+			}
+			for _, s := range sinks {
+				s(ctx, rec)
+			}
+			return res, err
+		})(next)
+	}
+}
+
+// LogAudit is an AuditSink that logs each record to l at debug level, or at
+// warn when the command failed or could not run.
+func LogAudit(l *slog.Logger) AuditSink {
+	return func(ctx context.Context, rec AuditRecord) {
+		level := slog.LevelDebug
+		if rec.Err != nil || rec.ExitCode != 0 {
+			level = slog.LevelWarn
+		}
+		l.LogAttrs(ctx, level, "command", slog.Any("command", rec))
+	}
+}
+
+// RedactArgs returns a copy of args with credential values replaced by
+// "[REDACTED]", as a Secret renders. An argument is a credential when its
+// name matches a logging.DefaultRedactKeys pattern: the value of
+// "--password=x" or "token=x", and the argument after a bare "--password".
+// Short flags ("-p x") carry no name and are kept; the SDK never puts a
+// Secret in argv, so this is a net under callers outside it.
+func RedactArgs(args []string) []string {
+	redacted := Secret{}.String()
+	out := make([]string, len(args))
+	redactNext := false
+	for i, a := range args {
+		switch {
+		case redactNext:
+			out[i] = redacted
+			redactNext = false
+		case strings.Contains(a, "="):
+			k, _, _ := strings.Cut(a, "=")
+			if credentialName(k) {
+				out[i] = k + "=" + redacted
+			} else {
+				out[i] = a
+			}
+		default:
+			out[i] = a
+			redactNext = strings.HasPrefix(a, "--") && credentialName(a)
+		}
+	}
+	return out
+}
+
+// credentialName reports whether a flag or key name matches a redact
+// pattern, ignoring case, leading dashes and the separator style.
+func credentialName(k string) bool {
+	k = strings.ToLower(strings.TrimLeft(k, "-"))
+	k = strings.NewReplacer("-", "_", ".", "_").Replace(k)
+	if k == "" {
+		return false
+	}
+	for _, p := range credentialKeys {
+		if strings.Contains(k, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package exec_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/manchtools/power-manage-sdk/logging"
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+func TestRedactArgs(t *testing.T) {
+	for _, tt := range []struct {
+		in, want []string
+	}{
+		{[]string{"install", "-y", "vim"}, []string{"install", "-y", "vim"}},
+		{[]string{"--password=hunter2", "--user=alice"}, []string{"--password=[REDACTED]", "--user=alice"}},
+		{[]string{"login", "--api-key", "k3y", "next"}, []string{"login", "--api-key", "[REDACTED]", "next"}},
+		{[]string{"set", "Client.Private-Key=pem"}, []string{"set", "Client.Private-Key=[REDACTED]"}},
+		{[]string{"-p", "hunter2", "--", "token"}, []string{"-p", "hunter2", "--", "token"}},
+	} {
+		got := pmexec.RedactArgs(tt.in)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("RedactArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	in := []string{"--token=x"}
+	pmexec.RedactArgs(in)
+	if in[0] != "--token=x" {
+		t.Error("RedactArgs modified its input")
+	}
+}
+
+// exec keeps its own copy of the log redaction patterns; every one of them
+// must still redact an argument.
+func TestRedactArgs_CoversLogPatterns(t *testing.T) {
+	for _, k := range logging.DefaultRedactKeys {
+		if got := pmexec.RedactArgs([]string{"--" + k + "=v"})[0]; got != "--"+k+"="+logging.Redacted {
+			t.Errorf("RedactArgs(--%s=v) = %q", k, got)
+		}
+	}
+}
+
+func TestAudit_Records(t *testing.T) {
+	base := exectest.New(pmexec.Sudo)
+	base.Push(pmexec.Result{ExitCode: 100, Stdout: "ignored"}, nil)
+	base.Push(pmexec.Result{}, pmexec.ErrEscalationDenied)
+	var recs []pmexec.AuditRecord
+	r := pmexec.Chain(base, pmexec.Audit(func(_ context.Context, rec pmexec.AuditRecord) {
+		recs = append(recs, rec)
+	}))
+
+	res, err := r.Run(t.Context(), pmexec.Command{
+		Name: "dnf", Args: []string{"check-update"}, Escalate: true, ReadOnly: true,
+	})
+	if err != nil || res.ExitCode != 100 {
+		t.Fatalf("Run = %+v, %v; want the base's result unchanged", res, err)
+	}
+	if _, err := r.Run(t.Context(), pmexec.Command{
+		Name: "chpasswd", Args: []string{"--secret-file=/x"}, Stdin: strings.NewReader("alice:pw"), Escalate: true,
+	}); !errors.Is(err, pmexec.ErrEscalationDenied) {
+		t.Fatalf("err = %v", err)
+	}
+
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2", len(recs))
+	}
+	a, b := recs[0], recs[1]
+	if a.Name != "dnf" || a.ExitCode != 100 || a.Err != nil || !a.ReadOnly || a.Escalation() != "sudo" || a.Start.IsZero() {
+		t.Errorf("first record = %+v", a)
+	}
+	if !b.Stdin || !errors.Is(b.Err, pmexec.ErrEscalationDenied) || b.Args[0] != "--secret-file=[REDACTED]" {
+		t.Errorf("second record = %+v", b)
+	}
+}
+
+func TestAuditRecord_Escalation(t *testing.T) {
+	for _, tt := range []struct {
+		rec  pmexec.AuditRecord
+		want string
+	}{
+		{pmexec.AuditRecord{Backend: pmexec.Sudo}, ""},
+		{pmexec.AuditRecord{Backend: pmexec.Doas, Escalate: true}, "doas"},
+		{pmexec.AuditRecord{Backend: pmexec.Direct, Escalate: true}, "direct"},
+		{pmexec.AuditRecord{Backend: pmexec.Run0, Escalate: true}, "run0"},
+		{pmexec.AuditRecord{Backend: pmexec.Pkexec, Escalate: true}, "pkexec"},
+	} {
+		if got := tt.rec.Escalation(); got != tt.want {
+			t.Errorf("Escalation(%+v) = %q, want %q", tt.rec, got, tt.want)
+		}
+	}
+}
+
+// A clean command logs at debug, a failed one at warn, structured.
+func TestLogAudit(t *testing.T) {
+	var buf bytes.Buffer
+	sink := pmexec.LogAudit(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	sink(t.Context(), pmexec.AuditRecord{Name: "systemctl", Args: []string{"restart", "sshd"}, Escalate: true, Backend: pmexec.Sudo})
+	sink(t.Context(), pmexec.AuditRecord{Name: "useradd", ExitCode: 9})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log = %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "level=DEBUG") || !strings.Contains(lines[0], "command.name=systemctl") ||
+		!strings.Contains(lines[0], "command.escalation=sudo") {
+		t.Errorf("clean command logged as %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "level=WARN") || !strings.Contains(lines[1], "command.exit_code=9") {
+		t.Errorf("failed command logged as %q", lines[1])
+	}
+}
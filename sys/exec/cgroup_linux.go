@@ -0,0 +1,318 @@
+//go:build linux
+
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroupFS is the cgroup v2 mount, and procSelfCgroup the file naming this
+// process's cgroup. Package vars (not consts) so tests can point them at a
+// temp dir.
+var (
+	cgroupFS       = "/sys/fs/cgroup"
+	procSelfCgroup = "/proc/self/cgroup"
+)
+
+// agentLeaf is the leaf cgroup this process's own cgroup members are moved
+// into, the first time a controller has to be enabled for child cgroups.
+// cgroup v2 only lets a non-root cgroup delegate controllers to children when
+// it has no processes of its own (the "no internal processes" rule), so the
+// agent steps aside into a sibling of the per-command cgroups — the same
+// layout systemd asks of a service with Delegate=yes.
+const agentLeaf = "pm-agent"
+
+// cpuPeriod is the cpu.max period in microseconds (the kernel default).
+const cpuPeriod = 100_000
+
+// cgroupDrainWait bounds how long remove waits for a killed cgroup to empty
+// before it gives up on rmdir. A var so tests can shorten it.
+var cgroupDrainWait = 2 * time.Second
+
+// cgroup is the transient cgroup one limited Command runs in.
+type cgroup struct {
+	path string
+	dir  *os.File // held open for CgroupFD
+}
+
+// newCgroup creates a cgroup for one command under the process's delegated
+// parent and writes l into it. Any failure is ErrResourceLimitsUnavailable (or
+// ErrInvalidResourceLimits for an IO device that is not a block device) and
+// leaves nothing behind.
+func newCgroup(l ResourceLimits) (*cgroup, error) {
+	parent, err := delegatedParent(l.controllers())
+	if err != nil {
+		return nil, err
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrResourceLimitsUnavailable, err)
+	}
+	path := filepath.Join(parent, "pm-exec-"+hex.EncodeToString(suffix))
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return nil, fmt.Errorf("%w: create cgroup: %v", ErrResourceLimitsUnavailable, err)
+	}
+	if err := writeLimits(path, l); err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+	dir, err := os.Open(path)
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("%w: open cgroup: %v", ErrResourceLimitsUnavailable, err)
+	}
+	return &cgroup{path: path, dir: dir}, nil
+}
+
+// delegatedParent returns the cgroup the per-command cgroups are created in —
+// this process's own cgroup, or its parent once the process has moved into
+// agentLeaf — with every controller in need enabled for its children. It is
+// idempotent and keeps no state, so concurrent Runners agree on the layout.
+func delegatedParent(need []string) (string, error) {
+	own, err := ownCgroup()
+	if err != nil {
+		return "", err
+	}
+	parent := filepath.Join(cgroupFS, own)
+	if filepath.Base(own) == agentLeaf {
+		parent = filepath.Dir(parent)
+	}
+	available, err := readFields(filepath.Join(parent, "cgroup.controllers"))
+	if err != nil {
+		return "", fmt.Errorf("%w: cgroup v2 not available at %s: %v", ErrResourceLimitsUnavailable, parent, err)
+	}
+	enabled, err := readFields(filepath.Join(parent, "cgroup.subtree_control"))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrResourceLimitsUnavailable, err)
+	}
+	var missing []string
+	for _, c := range need {
+		if !available[c] {
+			return "", fmt.Errorf("%w: the %s controller is not delegated to %s", ErrResourceLimitsUnavailable, c, parent)
+		}
+		if !enabled[c] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) == 0 {
+		return parent, nil
+	}
+	err = enableControllers(parent, missing)
+	if errors.Is(err, unix.EBUSY) {
+		if err = moveMembers(parent, filepath.Join(parent, agentLeaf)); err == nil {
+			err = enableControllers(parent, missing)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: enable %s in %s: %v", ErrResourceLimitsUnavailable, strings.Join(missing, ","), parent, err)
+	}
+	return parent, nil
+}
+
+// ownCgroup reads this process's cgroup v2 path from the "0::" line.
+func ownCgroup() (string, error) {
+	b, err := os.ReadFile(procSelfCgroup)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrResourceLimitsUnavailable, err)
+	}
+	for line := range strings.SplitSeq(string(b), "\n") {
+		if p, ok := strings.CutPrefix(line, "0::"); ok {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no cgroup v2 entry in %s", ErrResourceLimitsUnavailable, procSelfCgroup)
+}
+
+func enableControllers(parent string, cs []string) error {
+	var b strings.Builder
+	for i, c := range cs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString("+" + c)
+	}
+	return writeCgroupFile(filepath.Join(parent, "cgroup.subtree_control"), b.String())
+}
+
+// moveMembers moves every process in parent into leaf, creating it.
+func moveMembers(parent, leaf string) error {
+	if err := os.Mkdir(leaf, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+		return err
+	}
+	b, err := os.ReadFile(filepath.Join(parent, "cgroup.procs"))
+	if err != nil {
+		return err
+	}
+	for pid := range strings.FieldsSeq(string(b)) {
+		err := writeCgroupFile(filepath.Join(leaf, "cgroup.procs"), pid)
+		if err != nil && !errors.Is(err, unix.ESRCH) { // a process that exited meanwhile
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLimits writes l into the cgroup at path.
+func writeLimits(path string, l ResourceLimits) error {
+	var files [][2]string
+	if l.CPU > 0 {
+		files = append(files, [2]string{"cpu.max", fmt.Sprintf("%d %d", int64(l.CPU*cpuPeriod), cpuPeriod)})
+	}
+	if l.Memory > 0 {
+		files = append(files,
+			[2]string{"memory.max", strconv.FormatInt(l.Memory, 10)},
+			// Kill the whole command on OOM, not one arbitrary process of it.
+			[2]string{"memory.oom.group", "1"},
+		)
+		// Without swap disabled, a command over its limit pages out instead
+		// of being OOM-killed. memory.swap.max is absent on a kernel built
+		// without swap accounting, where there is nothing to disable.
+		if _, err := os.Stat(filepath.Join(path, "memory.swap.max")); err == nil {
+			files = append(files, [2]string{"memory.swap.max", "0"})
+		}
+	}
+	if l.Tasks > 0 {
+		files = append(files, [2]string{"pids.max", strconv.Itoa(l.Tasks)})
+	}
+	for _, d := range l.IO {
+		line, err := ioMaxLine(d)
+		if err != nil {
+			return err
+		}
+		files = append(files, [2]string{"io.max", line})
+	}
+	for _, f := range files {
+		if err := writeCgroupFile(filepath.Join(path, f[0]), f[1]); err != nil {
+			return fmt.Errorf("%w: set %s: %v", ErrResourceLimitsUnavailable, f[0], err)
+		}
+	}
+	return nil
+}
+
+// ioMaxLine renders one io.max line, "MAJ:MIN rbps=… wbps=…".
+func ioMaxLine(d IODeviceLimit) (string, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(d.Device, &st); err != nil {
+		return "", fmt.Errorf("%w: IO device %s: %v", ErrInvalidResourceLimits, d.Device, err)
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFBLK {
+		return "", fmt.Errorf("%w: IO device %s is not a block device", ErrInvalidResourceLimits, d.Device)
+	}
+	line := fmt.Sprintf("%d:%d", unix.Major(st.Rdev), unix.Minor(st.Rdev))
+	for _, kv := range []struct {
+		key string
+		v   int64
+	}{{"rbps", d.ReadBPS}, {"wbps", d.WriteBPS}, {"riops", d.ReadIOPS}, {"wiops", d.WriteIOPS}} {
+		if kv.v > 0 {
+			line += fmt.Sprintf(" %s=%d", kv.key, kv.v)
+		}
+	}
+	return line, nil
+}
+
+// beforeExec starts the child directly inside the cgroup (clone3
+// CLONE_INTO_CGROUP), so not even its first instruction runs unlimited. nil
+// for an unlimited command.
+func (cg *cgroup) beforeExec() func(*exec.Cmd) {
+	if cg == nil {
+		return nil
+	}
+	return func(c *exec.Cmd) {
+		// go-cmd has already set Setpgid; keep it.
+		if c.SysProcAttr == nil {
+			c.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		c.SysProcAttr.UseCgroupFD = true
+		c.SysProcAttr.CgroupFD = int(cg.dir.Fd())
+	}
+}
+
+// oomKilled reports whether the kernel OOM-killed anything in the cgroup.
+func (cg *cgroup) oomKilled() bool {
+	if cg == nil {
+		return false
+	}
+	return parseOOMKills(filepath.Join(cg.path, "memory.events")) > 0
+}
+
+// parseOOMKills sums oom_kill and oom_group_kill from a memory.events file;
+// 0 when it is absent (no memory limit, so no memory controller).
+func parseOOMKills(path string) int64 {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	var n int64
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		key, val, _ := strings.Cut(sc.Text(), " ")
+		if key == "oom_kill" || key == "oom_group_kill" {
+			v, _ := strconv.ParseInt(val, 10, 64)
+			n += v
+		}
+	}
+	return n
+}
+
+// remove kills whatever is still in the cgroup — a child that daemonized out
+// of the process group, or one left by a cancelled command — and removes it.
+// Best-effort: a cgroup that cannot be removed is left for the next boot
+// rather than failing a command that already finished.
+func (cg *cgroup) remove() {
+	if cg == nil {
+		return
+	}
+	defer cg.dir.Close()
+	if err := os.Remove(cg.path); err == nil {
+		return
+	}
+	_ = writeCgroupFile(filepath.Join(cg.path, "cgroup.kill"), "1")
+	deadline := time.Now().Add(cgroupDrainWait)
+	for {
+		if err := os.Remove(cg.path); err == nil || time.Now().After(deadline) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// readFields reads a space-separated cgroup list file into a set.
+func readFields(path string) (map[string]bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	for f := range strings.FieldsSeq(string(b)) {
+		set[f] = true
+	}
+	return set, nil
+}
+
+// writeCgroupFile writes one value to an existing cgroup control file. It
+// never creates the file: a missing control file means the controller is off.
+func writeCgroupFile(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
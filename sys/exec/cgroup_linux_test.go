@@ -0,0 +1,183 @@
+//go:build linux
+
+package exec
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCgroupFS points cgroupFS and procSelfCgroup at a temp tree in which this
+// process lives in /agent.slice/agent.service, with controllers available and
+// subtree_control initially empty. The real kernel semantics (EBUSY, clone3
+// into the cgroup, OOM) are exercised by the container test; this pins the
+// file protocol.
+func fakeCgroupFS(t *testing.T, own, controllers string) string {
+	t.Helper()
+	root := t.TempDir()
+	oldFS, oldProc := cgroupFS, procSelfCgroup
+	t.Cleanup(func() { cgroupFS, procSelfCgroup = oldFS, oldProc })
+	cgroupFS = root
+	procSelfCgroup = filepath.Join(root, "self-cgroup")
+	writeTestFile(t, procSelfCgroup, "0::"+own+"\n")
+
+	dir := filepath.Join(root, "agent.slice", "agent.service")
+	if err := os.MkdirAll(filepath.Join(dir, agentLeaf), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "cgroup.controllers"), controllers+"\n")
+	writeTestFile(t, filepath.Join(dir, "cgroup.subtree_control"), "")
+	return dir
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestDelegatedParent_EnablesMissingControllers(t *testing.T) {
+	dir := fakeCgroupFS(t, "/agent.slice/agent.service", "cpu io memory pids")
+	got, err := delegatedParent([]string{"memory", "pids"})
+	if err != nil {
+		t.Fatalf("delegatedParent: %v", err)
+	}
+	if got != dir {
+		t.Errorf("parent = %s, want %s", got, dir)
+	}
+	if sc := readTestFile(t, filepath.Join(dir, "cgroup.subtree_control")); sc != "+memory +pids" {
+		t.Errorf("subtree_control = %q, want %q", sc, "+memory +pids")
+	}
+}
+
+// Once the process has stepped into agentLeaf, the per-command cgroups are its
+// siblings, not its children.
+func TestDelegatedParent_FromAgentLeaf(t *testing.T) {
+	dir := fakeCgroupFS(t, "/agent.slice/agent.service/"+agentLeaf, "memory")
+	writeTestFile(t, filepath.Join(dir, "cgroup.subtree_control"), "memory\n")
+	got, err := delegatedParent([]string{"memory"})
+	if err != nil || got != dir {
+		t.Errorf("delegatedParent = %s, %v; want %s", got, err, dir)
+	}
+}
+
+func TestDelegatedParent_UndelegatedControllerFailsClosed(t *testing.T) {
+	fakeCgroupFS(t, "/agent.slice/agent.service", "memory pids")
+	if _, err := delegatedParent([]string{"memory", "io"}); !errors.Is(err, ErrResourceLimitsUnavailable) {
+		t.Errorf("err = %v, want ErrResourceLimitsUnavailable", err)
+	}
+}
+
+func TestDelegatedParent_NoCgroupV2(t *testing.T) {
+	fakeCgroupFS(t, "/agent.slice/agent.service", "memory")
+	writeTestFile(t, procSelfCgroup, "12:memory:/agent.slice\n")
+	if _, err := delegatedParent(nil); !errors.Is(err, ErrResourceLimitsUnavailable) {
+		t.Errorf("err = %v, want ErrResourceLimitsUnavailable", err)
+	}
+}
+
+func TestWriteLimits(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"cpu.max", "memory.max", "memory.oom.group", "memory.swap.max", "pids.max"} {
+		writeTestFile(t, filepath.Join(dir, f), "max\n")
+	}
+	if err := writeLimits(dir, ResourceLimits{CPU: 1.5, Memory: 64 << 20, Tasks: 16}); err != nil {
+		t.Fatalf("writeLimits: %v", err)
+	}
+	for f, want := range map[string]string{
+		"cpu.max":          "150000 100000",
+		"memory.max":       "67108864",
+		"memory.oom.group": "1",
+		"memory.swap.max":  "0",
+		"pids.max":         "16",
+	} {
+		if got := readTestFile(t, filepath.Join(dir, f)); got != want {
+			t.Errorf("%s = %q, want %q", f, got, want)
+		}
+	}
+}
+
+// A control file that does not exist means the controller is off; it is never
+// created as a plain file.
+func TestWriteLimits_MissingControllerFailsClosed(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeLimits(dir, ResourceLimits{Tasks: 4}); !errors.Is(err, ErrResourceLimitsUnavailable) {
+		t.Errorf("err = %v, want ErrResourceLimitsUnavailable", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pids.max")); !os.IsNotExist(err) {
+		t.Error("writeLimits created pids.max")
+	}
+}
+
+func TestIOMaxLine_RejectsNonBlockDevice(t *testing.T) {
+	if _, err := ioMaxLine(IODeviceLimit{Device: "/dev/null", ReadBPS: 1}); !errors.Is(err, ErrInvalidResourceLimits) {
+		t.Errorf("/dev/null (a char device): err = %v, want ErrInvalidResourceLimits", err)
+	}
+}
+
+func TestParseOOMKills(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.events")
+	writeTestFile(t, path, "low 0\nhigh 0\nmax 12\noom 3\noom_kill 1\noom_group_kill 1\n")
+	if got := parseOOMKills(path); got != 2 {
+		t.Errorf("parseOOMKills = %d, want 2", got)
+	}
+	if got := parseOOMKills(path + ".missing"); got != 0 {
+		t.Errorf("parseOOMKills(missing) = %d, want 0", got)
+	}
+}
+
+// A limited command fails closed when no cgroup can be created: the child is
+// never spawned unlimited.
+func TestRunner_LimitsUnavailableFailsClosed(t *testing.T) {
+	fakeCgroupFS(t, "/agent.slice/agent.service", "cpu")
+	marker := filepath.Join(t.TempDir(), "ran")
+	_, err := directRunner(t).Run(t.Context(), Command{
+		Name:   "sh",
+		Args:   []string{"-c", "touch " + marker},
+		Limits: &ResourceLimits{Memory: 64 << 20},
+	})
+	if !errors.Is(err, ErrResourceLimitsUnavailable) || !strings.Contains(err.Error(), "memory") {
+		t.Fatalf("err = %v, want ErrResourceLimitsUnavailable naming memory", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("the command ran despite its limits being unavailable")
+	}
+}
+
+// The cgroup's SIGKILL reaches the Runner as go-cmd's "signal: killed" error;
+// memory.events still makes it an ErrOOMKilled.
+func TestRunnerOutcome_OOMKillIsNotABareSignal(t *testing.T) {
+	res, runErr := runStreamingWithStdin(t.Context(), "sh", []string{"-c", "kill -9 $$"}, nil, nil, "", nil, nil)
+	if runErr == nil {
+		t.Fatal("a SIGKILLed child returned no error")
+	}
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "memory.events"), "oom 1\noom_kill 0\noom_group_kill 1\n")
+
+	_, err := (&runner{backend: Direct}).outcome(Command{Name: "sh"}, &cgroup{path: dir}, *res, runErr)
+	var ce *CommandError
+	if !errors.Is(err, ErrOOMKilled) || !errors.As(err, &ce) || ce.Name != "sh" {
+		t.Fatalf("err = %v, want a *CommandError wrapping ErrOOMKilled", err)
+	}
+	if !errors.Is(err, runErr) {
+		t.Errorf("err = %v, want it to keep %v", err, runErr)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "memory.events"), "oom 0\noom_kill 0\n")
+	if _, err := (&runner{backend: Direct}).outcome(Command{Name: "sh"}, &cgroup{path: dir}, *res, runErr); err != runErr {
+		t.Errorf("without an OOM kill err = %v, want the run error %v", err, runErr)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package exec
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// cgroup is a stub on non-Linux builds: cgroup v2 is Linux-only, so a limited
+// Command fails closed. Non-Linux builds exist for `go test` portability only.
+type cgroup struct{}
+
+func newCgroup(ResourceLimits) (*cgroup, error) {
+	return nil, fmt.Errorf("%w: cgroup v2 is Linux-only", ErrResourceLimitsUnavailable)
+}
+
+func (*cgroup) beforeExec() func(*exec.Cmd) { return nil }
+
+func (*cgroup) oomKilled() bool { return false }
+
+func (*cgroup) remove() {}
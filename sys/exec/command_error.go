@@ -16,12 +16,13 @@ var (
 
 	// docref: begin escalation-sentinels
 	// ErrEscalationUnavailable is returned when the chosen escalation tool
-	// (sudo/doas) is not installed on this host.
+	// (sudo/doas/run0/pkexec) is not installed on this host.
 	ErrEscalationUnavailable = errors.New("escalation tool not installed")
 
 	// ErrEscalationDenied is returned when `sudo -n` / `doas -n` would need a
-	// password (no NOPASSWD rule) — the agent never has a terminal to type one,
-	// so this fails closed rather than hanging.
+	// password (no NOPASSWD rule), or polkit does not authorize run0/pkexec
+	// without one — the agent never has a terminal to type one, so this fails
+	// closed rather than hanging.
 	ErrEscalationDenied = errors.New("escalation requires a password")
 	// docref: end escalation-sentinels
 
@@ -53,10 +54,14 @@ type CommandError struct {
 }
 
 func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("%s: exit %d", e.Name, e.ExitCode)
 	if s := strings.TrimSpace(e.Stderr); s != "" {
-		return fmt.Sprintf("%s: exit %d: %s", e.Name, e.ExitCode, s)
+		msg += ": " + s
 	}
-	return fmt.Sprintf("%s: exit %d", e.Name, e.ExitCode)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
 }
 
 func (e *CommandError) Unwrap() error { return e.Err }
@@ -6,23 +6,22 @@ import (
 )
 
 // Detect lists the privilege-escalation backends usable on THIS host (Decision
-// 6/7): Sudo if `sudo` is on PATH, Doas if `doas` is on PATH. It LISTS — it
-// never picks and never constructs a Runner; the consumer reads the list, picks
-// one explicitly, and passes it to NewRunner. Direct (run as the current
-// process, no wrapper) needs no detection and is never returned. A host with
-// neither tool returns an empty slice — a valid result for a root-only consumer
-// that will use Direct.
+// 6/7): Sudo, Doas, Run0 and Pkexec for each of `sudo`, `doas`, `run0` and
+// `pkexec` on PATH. It LISTS — it never picks and never constructs a Runner;
+// the consumer reads the list, picks one explicitly, and passes it to
+// NewRunner. Direct (run as the current process, no wrapper) needs no
+// detection and is never returned. A host with none of the tools returns an
+// empty slice — a valid result for a root-only consumer that will use Direct.
 //
 // The ctx is accepted for signature uniformity with the capability Detect
 // functions (which stat marker files); the present probe is a pure PATH lookup.
 func Detect(ctx context.Context) []PrivilegeBackend {
 	_ = ctx
 	var out []PrivilegeBackend
-	if _, err := exec.LookPath("sudo"); err == nil {
-		out = append(out, Sudo)
-	}
-	if _, err := exec.LookPath("doas"); err == nil {
-		out = append(out, Doas)
+	for _, b := range []PrivilegeBackend{Sudo, Doas, Run0, Pkexec} {
+		if _, err := exec.LookPath(escalationTool(b)); err == nil {
+			out = append(out, b)
+		}
 	}
 	return out
 }
@@ -8,14 +8,14 @@ import (
 // Detect LISTS the escalation backends usable on THIS host (Decision 6/7): it
 // lists, it never picks, and it never constructs a Runner. Direct needs no
 // detection (it is "run as the current process"), so it is never returned.
-func TestDetect_ListsOnlyInstalledSudoDoasNeverDirect(t *testing.T) {
+func TestDetect_ListsOnlyInstalledToolsNeverDirect(t *testing.T) {
 	got := Detect(t.Context())
 
 	for _, b := range got {
 		if b == Direct {
-			t.Errorf("Detect returned Direct; it must list only escalation tools (Sudo/Doas)")
+			t.Errorf("Detect returned Direct; it must list only escalation tools")
 		}
-		if b != Sudo && b != Doas {
+		if b != Sudo && b != Doas && b != Run0 && b != Pkexec {
 			t.Errorf("Detect returned unexpected backend %d", b)
 		}
 	}
@@ -30,12 +30,10 @@ func TestDetect_ListsOnlyInstalledSudoDoasNeverDirect(t *testing.T) {
 	// Cross-check against the host: presence in the list must agree with
 	// whether the tool is actually on PATH (the expectation is derived from
 	// the host independently of Detect's own probe).
-	_, sudoErr := osexec.LookPath("sudo")
-	if (sudoErr == nil) != seen[Sudo] {
-		t.Errorf("Detect Sudo presence = %v, but sudo on PATH = %v", seen[Sudo], sudoErr == nil)
-	}
-	_, doasErr := osexec.LookPath("doas")
-	if (doasErr == nil) != seen[Doas] {
-		t.Errorf("Detect Doas presence = %v, but doas on PATH = %v", seen[Doas], doasErr == nil)
+	for b, tool := range map[PrivilegeBackend]string{Sudo: "sudo", Doas: "doas", Run0: "run0", Pkexec: "pkexec"} {
+		_, err := osexec.LookPath(tool)
+		if (err == nil) != seen[b] {
+			t.Errorf("Detect %s presence = %v, but %s on PATH = %v", tool, seen[b], tool, err == nil)
+		}
 	}
 }
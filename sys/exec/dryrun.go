@@ -0,0 +1,190 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DryRunner is a Runner that plans instead of acting, for previewing what a
+// manifest would change. A ReadOnly command runs through the base Runner, so
+// every query a capability makes is answered by the real system. Every other
+// command, escalated or not, is checked like a real Runner would
+// (ValidateCommand), recorded as a PlanStep and answered with a clean success
+// — exit 0, no output — without running. A capability therefore walks its
+// usual path and the plan holds every side effect it would have had.
+//
+// A command that is not marked ReadOnly is captured even if it only reads:
+// the classification fails closed, so a dry run never changes the host.
+// Safe for concurrent use.
+type DryRunner struct {
+	base  Runner
+	mu    sync.Mutex
+	steps []PlanStep
+}
+
+var _ Runner = (*DryRunner)(nil)
+
+// NewDryRunner wraps base, which answers the ReadOnly commands. A nil base is
+// rejected with ErrRunnerRequired.
+func NewDryRunner(base Runner) (*DryRunner, error) {
+	if base == nil {
+		return nil, fmt.Errorf("exec: %w", ErrRunnerRequired)
+	}
+	return &DryRunner{base: base}, nil
+}
+
+// Backend reports the base Runner's backend, so capabilities build the same
+// Commands they would for real.
+func (d *DryRunner) Backend() PrivilegeBackend { return d.base.Backend() }
+
+func (d *DryRunner) Run(ctx context.Context, c Command) (Result, error) {
+	if c.ReadOnly {
+		return d.base.Run(ctx, c)
+	}
+	return Result{}, d.capture(ctx, c)
+}
+
+func (d *DryRunner) Stream(ctx context.Context, c Command, onLine OutputCallback) (Result, error) {
+	if c.ReadOnly {
+		return d.base.Stream(ctx, c, onLine)
+	}
+	return Result{}, d.capture(ctx, c)
+}
+
+// capture records c as a step after the gates a real Runner applies first, so
+// the plan only holds commands that would have been started.
+func (d *DryRunner) capture(ctx context.Context, c Command) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.Name == "" {
+		return fmt.Errorf("exec: command name is required")
+	}
+	if err := ValidateCommand(c); err != nil {
+		return err
+	}
+	step := PlanStep{
+		Name:     c.Name,
+		Args:     append([]string(nil), c.Args...),
+		Dir:      c.Dir,
+		User:     c.User,
+		Escalate: c.Escalate,
+	}
+	if c.Stdin != nil {
+		n, err := io.Copy(io.Discard, c.Stdin)
+		if err != nil {
+			return fmt.Errorf("exec: dry run: read stdin of %s: %w", c.Name, err)
+		}
+		step.StdinBytes = n
+	}
+	d.record(step)
+	return nil
+}
+
+func (d *DryRunner) record(s PlanStep) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.steps = append(d.steps, s)
+}
+
+// Plan returns the steps captured so far, in order.
+func (d *DryRunner) Plan() Plan {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return Plan{Steps: append([]PlanStep(nil), d.steps...)}
+}
+
+// Planned reports whether r is a *DryRunner, or middleware over one (see
+// Chain), recording change in its plan if so. A capability that changes the
+// host without a command — fs's fd-safe write path, network's keyfiles —
+// calls it first and skips the change when it returns true, so a dry run
+// neither performs nor loses it. With any other Runner it records nothing
+// and returns false.
+func Planned(r Runner, change string) bool {
+	for r != nil {
+		if d, ok := r.(*DryRunner); ok {
+			d.record(PlanStep{Change: change})
+			return true
+		}
+		u, ok := r.(interface{ Unwrap() Runner })
+		if !ok {
+			return false
+		}
+		r = u.Unwrap()
+	}
+	return false
+}
+
+// Plan is what a dry run would have done, in order. The executor and every
+// capability built on a DryRunner produce one.
+type Plan struct {
+	Steps []PlanStep
+}
+
+// Empty reports whether the dry run would change nothing.
+func (p Plan) Empty() bool { return len(p.Steps) == 0 }
+
+// String renders one step per line.
+func (p Plan) String() string {
+	lines := make([]string, len(p.Steps))
+	for i, s := range p.Steps {
+		lines[i] = s.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PlanStep is one captured side effect: either a command that was not run
+// (Name set) or an in-process Change a capability skipped.
+type PlanStep struct {
+	Name     string
+	Args     []string
+	Dir      string
+	User     string
+	Escalate bool
+	// StdinBytes is how much the command would have read on stdin. The
+	// content is drained but not kept: it may be a secret (a password piped
+	// to chpasswd, a LUKS key).
+	StdinBytes int64
+	// Change describes an in-process change, e.g. "write /etc/hosts".
+	Change string
+}
+
+// String renders the step as a shell-like line: the escalation marker, the
+// command and its quoted arguments, then where, as whom and with how much
+// stdin it would have run.
+func (s PlanStep) String() string {
+	if s.Name == "" {
+		return s.Change
+	}
+	var b strings.Builder
+	if s.Escalate {
+		b.WriteString("[escalate] ")
+	}
+	b.WriteString(shellQuote(s.Name))
+	for _, a := range s.Args {
+		b.WriteString(" " + shellQuote(a))
+	}
+	if s.User != "" {
+		b.WriteString(" (as " + s.User + ")")
+	}
+	if s.Dir != "" {
+		b.WriteString(" (in " + s.Dir + ")")
+	}
+	if s.StdinBytes > 0 {
+		b.WriteString(" (" + strconv.FormatInt(s.StdinBytes, 10) + " bytes on stdin)")
+	}
+	return b.String()
+}
+
+// shellQuote quotes a for display when it is empty or holds anything but
+// the characters a shell word never needs quoting for.
+func shellQuote(a string) string {
+	if a != "" && strings.Trim(a, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=+./:,@%") == "" {
+		return a
+	}
+	return "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+}
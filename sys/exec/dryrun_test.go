@@ -0,0 +1,114 @@
+package exec_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+func TestNewDryRunner_RejectsNilBase(t *testing.T) {
+	if _, err := pmexec.NewDryRunner(nil); !errors.Is(err, pmexec.ErrRunnerRequired) {
+		t.Fatalf("err = %v, want ErrRunnerRequired", err)
+	}
+}
+
+// Reads reach the host; everything else is planned and never reaches it.
+func TestDryRunner_ForwardsReadsCapturesMutations(t *testing.T) {
+	base := exectest.New(pmexec.Sudo)
+	base.Push(pmexec.Result{Stdout: "active\n"}, nil)
+	d, err := pmexec.NewDryRunner(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Backend() != pmexec.Sudo {
+		t.Errorf("Backend = %v, want the base's", d.Backend())
+	}
+
+	res, err := d.Run(t.Context(), pmexec.Command{Name: "systemctl", Args: []string{"is-active", "sshd"}, Escalate: true, ReadOnly: true})
+	if err != nil || res.Stdout != "active\n" {
+		t.Fatalf("read = %+v, %v; want the base's answer", res, err)
+	}
+	res, err = d.Run(t.Context(), pmexec.Command{Name: "systemctl", Args: []string{"restart", "sshd"}, Escalate: true})
+	if err != nil || res.ExitCode != 0 || res.Stdout != "" {
+		t.Fatalf("mutation = %+v, %v; want a clean empty success", res, err)
+	}
+	if _, err := d.Stream(t.Context(), pmexec.Command{Name: "apt-get", Args: []string{"install", "-y", "vim"}}, func(pmexec.StreamType, string, int64) {}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	if calls := base.Calls(); len(calls) != 1 || calls[0].Args[0] != "is-active" {
+		t.Errorf("base saw %+v, want only the read", calls)
+	}
+	want := "[escalate] systemctl restart sshd\napt-get install -y vim"
+	if got := d.Plan().String(); got != want {
+		t.Errorf("plan =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// Stdin is drained so a pipe writer never blocks, but only its size is kept.
+func TestDryRunner_StdinSizeOnly(t *testing.T) {
+	d, _ := pmexec.NewDryRunner(exectest.New(0))
+	if _, err := d.Run(t.Context(), pmexec.Command{Name: "chpasswd", Stdin: strings.NewReader("alice:hunter2"), Escalate: true}); err != nil {
+		t.Fatal(err)
+	}
+	steps := d.Plan().Steps
+	if len(steps) != 1 || steps[0].StdinBytes != 13 {
+		t.Fatalf("steps = %+v", steps)
+	}
+	if s := d.Plan().String(); strings.Contains(s, "hunter2") || !strings.Contains(s, "(13 bytes on stdin)") {
+		t.Errorf("plan = %q", s)
+	}
+}
+
+// A captured command passes the same gates a real Runner applies, so the plan
+// never holds a command that would have been refused.
+func TestDryRunner_RejectsWhatARunnerWould(t *testing.T) {
+	d, _ := pmexec.NewDryRunner(exectest.New(0))
+	if _, err := d.Run(t.Context(), pmexec.Command{Name: "true", Env: []string{"LD_PRELOAD=/tmp/x.so"}}); !errors.Is(err, pmexec.ErrBlockedEnvVar) {
+		t.Errorf("err = %v, want ErrBlockedEnvVar", err)
+	}
+	if _, err := d.Run(t.Context(), pmexec.Command{}); err == nil {
+		t.Error("empty name accepted")
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := d.Run(ctx, pmexec.Command{Name: "true"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if !d.Plan().Empty() {
+		t.Errorf("plan = %q, want nothing recorded", d.Plan())
+	}
+}
+
+func TestPlanned(t *testing.T) {
+	if pmexec.Planned(exectest.New(0), "write /etc/hosts") {
+		t.Error("Planned on a real runner = true")
+	}
+	d, _ := pmexec.NewDryRunner(exectest.New(0))
+	if !pmexec.Planned(d, "write /etc/hosts (12 bytes)") {
+		t.Fatal("Planned on a DryRunner = false")
+	}
+	if got := d.Plan().String(); got != "write /etc/hosts (12 bytes)" {
+		t.Errorf("plan = %q", got)
+	}
+}
+
+func TestPlanStep_String(t *testing.T) {
+	for _, tt := range []struct {
+		step pmexec.PlanStep
+		want string
+	}{
+		{pmexec.PlanStep{Name: "useradd", Args: []string{"-m", "alice"}, Escalate: true}, "[escalate] useradd -m alice"},
+		{pmexec.PlanStep{Name: "sh", Args: []string{"-c", "echo 'hi' > f", ""}}, `sh -c 'echo '\''hi'\'' > f' ''`},
+		{pmexec.PlanStep{Name: "make", User: "alice", Dir: "/srv/app"}, "make (as alice) (in /srv/app)"},
+		{pmexec.PlanStep{Change: "remove directory /opt/app"}, "remove directory /opt/app"},
+	} {
+		if got := tt.step.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package exec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The polkit backends. They follow Direct so the values of the original
+// backends stay stable.
+const (
+	// Run0 escalates via systemd's `run0 --no-ask-password` (systemd 256 and
+	// later), authorized by polkit. The command runs as a transient service
+	// of PID 1 in a clean environment, outside the agent's cgroup, so a
+	// Command with Limits cannot escalate through it.
+	Run0 PrivilegeBackend = Direct + 1 + iota
+	// Pkexec escalates via `pkexec --disable-internal-agent`, authorized by
+	// polkit. pkexec clears the environment and starts in root's home, so
+	// the command runs under env(1), which restores both.
+	Pkexec
+)
+
+// polkitTool is the wrapper binary of a polkit backend, or "" for any other
+// backend.
+func polkitTool(b PrivilegeBackend) string {
+	switch b {
+	case Run0:
+		return "run0"
+	case Pkexec:
+		return "pkexec"
+	default:
+		return ""
+	}
+}
+
+// forwardedEnv is what an escalated command needs carried across a wrapper
+// that clears the environment: the ChildPath, the caller's validated Env,
+// then forcedEnv, which still wins. Nothing else of the agent's
+// environment crosses, as with sudo's env_reset. The entries end up on the
+// wrapper's command line, visible in the process list like any argument.
+func forwardedEnv(c Command) []string {
+	var env []string
+	if c.ChildPath != "" {
+		env = append(env, "PATH="+c.ChildPath)
+	}
+	env = append(env, c.Env...)
+	return append(env, forcedEnv...)
+}
+
+// wrapPolkit turns an escalated command into its run0 or pkexec invocation.
+// Pure: envTool is the resolved absolute env(1), used by pkexec only. The
+// command's absolute path always follows "--", so neither wrapper nor env
+// can read it, or an argument, as an option.
+func wrapPolkit(b PrivilegeBackend, envTool, absPath string, c Command) (string, []string, error) {
+	env := forwardedEnv(c)
+	var argv []string
+	switch b {
+	case Run0:
+		argv = []string{"--no-ask-password"}
+		if c.Dir != "" {
+			argv = append(argv, "--chdir="+c.Dir)
+		}
+		for _, e := range env {
+			argv = append(argv, "--setenv="+e)
+		}
+		argv = append(argv, "--", absPath)
+	case Pkexec:
+		// env reads every leading NAME=VALUE operand as an assignment, so a
+		// path containing = would never run.
+		if strings.Contains(absPath, "=") {
+			return "", nil, fmt.Errorf("%w: pkexec cannot run %s: the path contains '='", ErrEscalationUnavailable, absPath)
+		}
+		argv = []string{"--disable-internal-agent", envTool}
+		if c.Dir != "" {
+			argv = append(argv, "-C", c.Dir)
+		}
+		argv = append(argv, "--")
+		argv = append(argv, env...)
+		argv = append(argv, absPath)
+	default:
+		return "", nil, fmt.Errorf("%w: %d is not a polkit backend", ErrUnknownBackend, int(b))
+	}
+	return polkitTool(b), append(argv, c.Args...), nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -77,27 +79,18 @@ func TestRunner_EscalatedRunSucceeds(t *testing.T) {
 }
 
 // Detect lists exactly the escalation tools present on PATH — covered with fake
-// sudo + doas so both branches run deterministically (a real host may lack doas).
+// sudo, doas, run0 and pkexec so every branch runs deterministically (a real
+// host rarely has all four).
 func TestDetect_ListsFakeToolsOnPath(t *testing.T) {
 	dir := t.TempDir()
-	writeExecScript(t, dir, "sudo", "#!/bin/sh\n")
-	writeExecScript(t, dir, "doas", "#!/bin/sh\n")
+	for _, tool := range []string{"sudo", "doas", "run0", "pkexec"} {
+		writeExecScript(t, dir, tool, "#!/bin/sh\n")
+	}
 	t.Setenv("PATH", dir)
 
 	got := Detect(context.Background())
-	var hasSudo, hasDoas bool
-	for _, b := range got {
-		switch b {
-		case Sudo:
-			hasSudo = true
-		case Doas:
-			hasDoas = true
-		default:
-			t.Errorf("Detect returned unexpected backend %d", b)
-		}
-	}
-	if !hasSudo || !hasDoas {
-		t.Errorf("Detect = %v, want both Sudo and Doas", got)
+	if want := []PrivilegeBackend{Sudo, Doas, Run0, Pkexec}; !slices.Equal(got, want) {
+		t.Errorf("Detect = %v, want %v", got, want)
 	}
 }
 
@@ -106,7 +99,7 @@ func TestDetect_ListsFakeToolsOnPath(t *testing.T) {
 func TestDetect_EmptyWhenNoToolsOnPath(t *testing.T) {
 	t.Setenv("PATH", t.TempDir()) // empty dir
 	if got := Detect(context.Background()); len(got) != 0 {
-		t.Errorf("Detect = %v, want empty on a host with no sudo/doas", got)
+		t.Errorf("Detect = %v, want empty on a host with no escalation tool", got)
 	}
 }
 
@@ -117,3 +110,101 @@ func TestCommandError_ErrorWithoutStderr(t *testing.T) {
 		t.Errorf("Error() = %q, want %q", got, want)
 	}
 }
+
+// run0 and pkexec clear the environment, so wrapPolkit carries the forced
+// locale, the caller's Env and the ChildPath across, and always puts the
+// command after "--".
+func TestWrapPolkit(t *testing.T) {
+	c := Command{Name: "useradd", Args: []string{"-m", "deploy"}, Dir: "/srv", Env: []string{"FOO=bar"}, ChildPath: "/usr/bin"}
+	const abs = "/usr/sbin/useradd"
+	tests := []struct {
+		backend  PrivilegeBackend
+		wantName string
+		wantArgv []string
+	}{
+		{Run0, "run0", []string{
+			"--no-ask-password", "--chdir=/srv",
+			"--setenv=PATH=/usr/bin", "--setenv=FOO=bar", "--setenv=LC_ALL=C", "--setenv=LANG=C", "--setenv=NO_COLOR=1",
+			"--", abs, "-m", "deploy",
+		}},
+		{Pkexec, "pkexec", []string{
+			"--disable-internal-agent", "/usr/bin/env", "-C", "/srv", "--",
+			"PATH=/usr/bin", "FOO=bar", "LC_ALL=C", "LANG=C", "NO_COLOR=1",
+			abs, "-m", "deploy",
+		}},
+	}
+	for _, tc := range tests {
+		name, argv, err := wrapPolkit(tc.backend, "/usr/bin/env", abs, c)
+		if err != nil {
+			t.Fatalf("backend=%d: %v", tc.backend, err)
+		}
+		if name != tc.wantName || !slices.Equal(argv, tc.wantArgv) {
+			t.Errorf("backend=%d: %s %q, want %s %q", tc.backend, name, argv, tc.wantName, tc.wantArgv)
+		}
+	}
+}
+
+func TestWrapPolkit_Rejects(t *testing.T) {
+	if _, _, err := wrapPolkit(Pkexec, "/usr/bin/env", "/opt/a=b/tool", Command{Name: "tool"}); !errors.Is(err, ErrEscalationUnavailable) {
+		t.Errorf("pkexec with '=' in the path: err = %v, want ErrEscalationUnavailable", err)
+	}
+	if _, _, err := wrapPolkit(Sudo, "", "/bin/true", Command{Name: "true"}); !errors.Is(err, ErrUnknownBackend) {
+		t.Errorf("sudo through wrapPolkit: err = %v, want ErrUnknownBackend", err)
+	}
+}
+
+// A fake run0 that runs its command proves the round trip: the environment
+// and the working directory arrive through the flags alone.
+func TestRunner_Run0CarriesEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	writeExecScript(t, dir, "run0", `#!/bin/sh
+cd_to=
+set_env=
+while [ "$1" != "--" ]; do
+	case "$1" in
+	--chdir=*) cd_to=${1#--chdir=} ;;
+	--setenv=*) set_env="$set_env ${1#--setenv=}" ;;
+	esac
+	shift
+done
+shift
+cd "$cd_to" && exec env -i $set_env "$@"
+`)
+	writeExecScript(t, dir, "payload", "#!/bin/sh\necho \"$FOO $LC_ALL $(pwd)\"\n")
+	t.Setenv("PATH", dir+":/usr/bin:/bin")
+
+	r, err := NewRunner(Run0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	res, err := r.Run(context.Background(), Command{Name: "payload", Escalate: true, Env: []string{"FOO=bar"}, Dir: work})
+	if err != nil {
+		t.Fatalf("Run err = %v", err)
+	}
+	if got, want := strings.TrimSpace(res.Stdout), "bar C "+work; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+}
+
+// run0 starts the command outside the agent's cgroup, so a limit could never
+// apply; the Runner refuses before starting anything.
+func TestRunner_Run0RejectsLimits(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	writeExecScript(t, dir, "run0", "#!/bin/sh\ntouch "+marker+"\n")
+	writeExecScript(t, dir, "payload", "#!/bin/sh\nexit 0\n")
+	t.Setenv("PATH", dir)
+
+	r, err := NewRunner(Run0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Run(context.Background(), Command{Name: "payload", Escalate: true, Limits: &ResourceLimits{Tasks: 8}})
+	if !errors.Is(err, ErrResourceLimitsUnavailable) {
+		t.Errorf("err = %v, want ErrResourceLimitsUnavailable", err)
+	}
+	if _, statErr := os.Stat(marker); statErr == nil {
+		t.Error("run0 was started despite the rejection")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os/exec"
 	"strings"
 	"sync/atomic"
 	"syscall"
@@ -95,6 +96,28 @@ func ValidateCommandEnv(env []string) error {
 	return nil
 }
 
+// ValidateCommand applies every check on a Command that needs no host, in the
+// order the real Runner applies them before it spawns anything:
+// ValidateCommandEnv, ResourceLimits.Validate, the User/Group shape
+// (ErrInvalidRunAs — a Group without a User, or a User with Escalate), and
+// Sandbox.Validate plus ErrInvalidSandbox for a Sandbox with Escalate. Like
+// ValidateCommandEnv it is exported so exectest.FakeRunner rejects exactly
+// what a real Runner rejects.
+func ValidateCommand(c Command) error {
+	if err := ValidateCommandEnv(c.Env); err != nil {
+		return err
+	}
+	if c.Limits != nil {
+		if err := c.Limits.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateRunAs(c); err != nil {
+		return err
+	}
+	return validateSandbox(c)
+}
+
 // composeEnv builds the child environment: a leading PATH=childPath (when
 // childPath is non-empty) followed by the caller's already-validated env
 // vars. PATH cannot appear in envVars (it is blocklisted), so the
@@ -115,11 +138,16 @@ func composeEnv(childPath string, envVars []string) []string {
 // exit code is in Result; the returned error is non-nil only on failure to
 // execute or ctx cancellation). The injected Runner builds on it. A nil env
 // inherits the parent environment fully; a non-nil env (even empty) replaces it.
-func runStreamingWithStdin(ctx context.Context, name string, args []string, stdin io.Reader, env []string, dir string, callback OutputCallback) (*Result, error) {
+// beforeExec hooks adjust the os/exec.Cmd just before it starts (the Runner
+// uses them to start the child inside a resource-limit cgroup, with a
+// run-as user's credentials and with a sandbox's syscall filter); nil hooks
+// are skipped.
+func runStreamingWithStdin(ctx context.Context, name string, args []string, stdin io.Reader, env []string, dir string, beforeExec []func(*exec.Cmd), callback OutputCallback) (*Result, error) {
 	c := cmd.NewCmdOptions(cmd.Options{
 		Buffered:       false,
 		Streaming:      true,
 		LineBufferSize: 4 * MaxOutputBytes,
+		BeforeExec:     beforeExec,
 	}, name, args...)
 
 	if dir != "" {
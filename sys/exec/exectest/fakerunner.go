@@ -93,11 +93,13 @@ func (f *FakeRunner) pop() (exec.Result, error) {
 // scripted result — mirroring the real Runner, so a capability's ctx handling
 // can be unit-tested faithfully.
 func (f *FakeRunner) Run(ctx context.Context, c exec.Command) (exec.Result, error) {
-	// Apply the same env gate the real Runner enforces, BEFORE recording: a
-	// Command carrying a blocked/reserved/malformed env var is rejected and never
-	// recorded or "run", so a capability that builds an adversarial environment
-	// fails identically against the fake and a real Runner.
-	if err := exec.ValidateCommandEnv(c.Env); err != nil {
+	// Apply the same host-free gates the real Runner enforces, BEFORE
+	// recording: a Command carrying a blocked/reserved/malformed env var, a
+	// malformed ResourceLimits or Sandbox, or a contradictory User/Group is
+	// rejected and never recorded or "run", so a capability that builds an
+	// adversarial Command fails identically against the fake and a real
+	// Runner.
+	if err := exec.ValidateCommand(c); err != nil {
 		return exec.Result{}, err
 	}
 	f.record(ctx, c)
@@ -112,7 +114,7 @@ func (f *FakeRunner) Run(ctx context.Context, c exec.Command) (exec.Result, erro
 // be exercised without a real process. A cancelled context short-circuits as in
 // Run (no scripted result consumed, no replay).
 func (f *FakeRunner) Stream(ctx context.Context, c exec.Command, onLine exec.OutputCallback) (exec.Result, error) {
-	if err := exec.ValidateCommandEnv(c.Env); err != nil {
+	if err := exec.ValidateCommand(c); err != nil {
 		return exec.Result{}, err
 	}
 	f.record(ctx, c)
@@ -82,6 +82,17 @@ func TestFakeRunner_RejectsBlockedEnvLikeRealRunner(t *testing.T) {
 	}
 }
 
+func TestFakeRunner_RejectsInvalidLimitsLikeRealRunner(t *testing.T) {
+	f := exectest.New(exec.Direct)
+	_, err := f.Run(context.Background(), exec.Command{Name: "true", Limits: &exec.ResourceLimits{Memory: -1}})
+	if !errors.Is(err, exec.ErrInvalidResourceLimits) {
+		t.Fatalf("FakeRunner.Run error = %v, want ErrInvalidResourceLimits", err)
+	}
+	if calls := f.Calls(); len(calls) != 0 {
+		t.Fatalf("FakeRunner recorded %d invalid command(s)", len(calls))
+	}
+}
+
 func TestFakeRunner_BackendReported(t *testing.T) {
 	if got := exectest.New(exec.Sudo).Backend(); got != exec.Sudo {
 		t.Errorf("Backend() = %d, want Sudo", got)
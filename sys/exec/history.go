@@ -0,0 +1,138 @@
+package exec
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	pb "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// History sizes. MaxHistorySize is the most ActionResult.recent_commands
+// accepts.
+const (
+	DefaultHistorySize = 16
+	MaxHistorySize     = 64
+)
+
+// Limits of a CommandRecord, from its validate tags.
+const (
+	maxRecordArgs   = 256
+	maxRecordString = 4096
+)
+
+// History is a ring buffer of the most recent AuditRecords. Its Record
+// method is an AuditSink:
+//
+//	h := exec.NewHistory(exec.DefaultHistorySize)
+//	r = exec.Chain(r, exec.Audit(h.Record))
+//
+// An executor that runs one action at a time calls Reset before each action
+// and Attach on its result, so a failed action reports the commands that led
+// up to the failure. Safe for concurrent use.
+type History struct {
+	mu   sync.Mutex
+	buf  []AuditRecord
+	next int
+	full bool
+}
+
+// NewHistory returns a History that keeps the last size records. A size
+// outside 1..MaxHistorySize is clamped to it, and 0 means
+// DefaultHistorySize.
+func NewHistory(size int) *History {
+	switch {
+	case size == 0:
+		size = DefaultHistorySize
+	case size < 1:
+		size = 1
+	case size > MaxHistorySize:
+		size = MaxHistorySize
+	}
+	return &History{buf: make([]AuditRecord, size)}
+}
+
+// Record appends rec, dropping the oldest record once the buffer is full.
+func (h *History) Record(_ context.Context, rec AuditRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buf[h.next] = rec
+	h.next = (h.next + 1) % len(h.buf)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Recent returns the buffered records, oldest first.
+func (h *History) Recent() []AuditRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]AuditRecord(nil), h.buf[:h.next]...)
+	}
+	return append(append([]AuditRecord(nil), h.buf[h.next:]...), h.buf[:h.next]...)
+}
+
+// Reset drops every buffered record.
+func (h *History) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clear(h.buf)
+	h.next, h.full = 0, false
+}
+
+// Attach sets res.RecentCommands to the buffered records when res reports a
+// failure (EXECUTION_STATUS_FAILED or _TIMEOUT). Any other result is left
+// unchanged.
+func (h *History) Attach(res *pb.ActionResult) {
+	switch res.GetStatus() {
+	case pb.ExecutionStatus_EXECUTION_STATUS_FAILED, pb.ExecutionStatus_EXECUTION_STATUS_TIMEOUT:
+	default:
+		return
+	}
+	recs := h.Recent()
+	out := make([]*pb.CommandRecord, len(recs))
+	for i, r := range recs {
+		out[i] = r.Proto()
+	}
+	res.RecentCommands = out
+}
+
+// Proto converts r to its wire form, cut to the CommandRecord limits: at
+// most 256 arguments, strings of at most 4096 bytes, valid UTF-8.
+func (r AuditRecord) Proto() *pb.CommandRecord {
+	args := r.Args
+	if len(args) > maxRecordArgs {
+		args = args[:maxRecordArgs]
+	}
+	rec := &pb.CommandRecord{
+		Name:       recordString(r.Name),
+		Args:       make([]string, len(args)),
+		Escalation: r.Escalation(),
+		User:       recordString(r.User),
+		DurationMs: r.Duration.Milliseconds(),
+		ExitCode:   int32(r.ExitCode),
+	}
+	for i, a := range args {
+		rec.Args[i] = recordString(a)
+	}
+	if !r.Start.IsZero() {
+		rec.StartedAt = timestamppb.New(r.Start)
+	}
+	if r.Err != nil {
+		rec.Error = recordString(r.Err.Error())
+	}
+	return rec
+}
+
+// recordString makes s fit a CommandRecord string field: proto3 strings must
+// be valid UTF-8, and an argument can be anything.
+func recordString(s string) string {
+	s = strings.ToValidUTF8(s, "�")
+	if len(s) <= maxRecordString {
+		return s
+	}
+	// Cutting can split the last rune; dropping its bytes keeps it valid.
+	return strings.ToValidUTF8(s[:maxRecordString], "")
+}
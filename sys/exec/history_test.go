@@ -0,0 +1,110 @@
+package exec_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+func names(recs []pmexec.AuditRecord) string {
+	s := make([]string, len(recs))
+	for i, r := range recs {
+		s[i] = r.Name
+	}
+	return strings.Join(s, " ")
+}
+
+func TestHistory_Ring(t *testing.T) {
+	h := pmexec.NewHistory(3)
+	for _, n := range []string{"a", "b"} {
+		h.Record(t.Context(), pmexec.AuditRecord{Name: n})
+	}
+	if got := names(h.Recent()); got != "a b" {
+		t.Errorf("Recent = %q", got)
+	}
+	for _, n := range []string{"c", "d", "e"} {
+		h.Record(t.Context(), pmexec.AuditRecord{Name: n})
+	}
+	if got := names(h.Recent()); got != "c d e" {
+		t.Errorf("Recent after wrap = %q, want the last three, oldest first", got)
+	}
+	h.Reset()
+	if got := h.Recent(); len(got) != 0 {
+		t.Errorf("Recent after Reset = %q", names(got))
+	}
+}
+
+func TestNewHistory_ClampsSize(t *testing.T) {
+	for size, want := range map[int]int{0: pmexec.DefaultHistorySize, -5: 1, 1000: pmexec.MaxHistorySize} {
+		h := pmexec.NewHistory(size)
+		for range 2 * pmexec.MaxHistorySize {
+			h.Record(t.Context(), pmexec.AuditRecord{Name: "x"})
+		}
+		if got := len(h.Recent()); got != want {
+			t.Errorf("NewHistory(%d) keeps %d, want %d", size, got, want)
+		}
+	}
+}
+
+// The executor's flow: audit into a History, attach it to a failed result.
+func TestHistory_AttachOnFailure(t *testing.T) {
+	base := exectest.New(pmexec.Sudo)
+	base.Push(pmexec.Result{}, nil)
+	base.Push(pmexec.Result{ExitCode: 1}, nil)
+	h := pmexec.NewHistory(0)
+	r := pmexec.Chain(base, pmexec.Audit(h.Record))
+	_, _ = r.Run(t.Context(), pmexec.Command{Name: "apt-get", Args: []string{"update"}, Escalate: true})
+	_, _ = r.Run(t.Context(), pmexec.Command{Name: "apt-get", Args: []string{"install", "vim"}, Escalate: true})
+
+	ok := &pb.ActionResult{Status: pb.ExecutionStatus_EXECUTION_STATUS_SUCCESS}
+	h.Attach(ok)
+	if len(ok.RecentCommands) != 0 {
+		t.Errorf("a successful result got %d commands", len(ok.RecentCommands))
+	}
+	failed := &pb.ActionResult{Status: pb.ExecutionStatus_EXECUTION_STATUS_FAILED}
+	h.Attach(failed)
+	if len(failed.RecentCommands) != 2 {
+		t.Fatalf("attached %d commands, want 2", len(failed.RecentCommands))
+	}
+	last := failed.RecentCommands[1]
+	if last.Name != "apt-get" || last.Args[0] != "install" || last.ExitCode != 1 || last.Escalation != "sudo" || last.StartedAt == nil {
+		t.Errorf("last command = %v", last)
+	}
+}
+
+// Proto output always fits the CommandRecord validate limits.
+func TestAuditRecord_Proto(t *testing.T) {
+	args := make([]string, 300)
+	for i := range args {
+		args[i] = "x"
+	}
+	args[0] = strings.Repeat("é", 3000) // 6000 bytes, cut mid-rune
+	args[1] = "bad\xffutf8"
+	rec := pmexec.AuditRecord{
+		Name: "tool", Args: args, User: "alice",
+		Start: time.Unix(1700000000, 0), Duration: 1500 * time.Millisecond,
+		ExitCode: -1, Err: errors.New("signal: killed"),
+	}
+	p := rec.Proto()
+	if len(p.Args) != 256 {
+		t.Errorf("len(args) = %d, want 256", len(p.Args))
+	}
+	if len(p.Args[0]) > 4096 || !utf8.ValidString(p.Args[0]) {
+		t.Errorf("long arg: %d bytes, valid=%v", len(p.Args[0]), utf8.ValidString(p.Args[0]))
+	}
+	if !utf8.ValidString(p.Args[1]) {
+		t.Errorf("arg %q is not valid UTF-8", p.Args[1])
+	}
+	if p.Escalation != "" || p.User != "alice" || p.DurationMs != 1500 || p.ExitCode != -1 || p.Error != "signal: killed" || p.StartedAt.AsTime().Unix() != 1700000000 {
+		t.Errorf("record = %v", p)
+	}
+	if p := (pmexec.AuditRecord{Name: "x"}).Proto(); p.StartedAt != nil {
+		t.Error("a zero Start became a timestamp")
+	}
+}
@@ -0,0 +1,106 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// Resource-limit sentinels, errors.Is-matchable like the construction and
+// escalation sentinels in command_error.go.
+var (
+	// ErrInvalidResourceLimits is returned when Command.Limits holds a
+	// negative, out-of-range or incomplete value. It is rejected before any
+	// cgroup is created or child spawned.
+	ErrInvalidResourceLimits = errors.New("invalid resource limits")
+
+	// ErrResourceLimitsUnavailable is returned when Command.Limits is set but
+	// this process cannot create a limited cgroup: no cgroup v2, a controller
+	// that is not delegated to the process's cgroup, or no write access to it.
+	// The Runner fails closed — a limited command never runs unlimited.
+	ErrResourceLimitsUnavailable = errors.New("resource limits unavailable")
+
+	// ErrOOMKilled is the cause carried by the *CommandError the Runner returns
+	// when the kernel OOM-killed a command that hit its Limits.Memory. The exit
+	// code alone (a SIGKILL) cannot tell an OOM kill from any other kill.
+	ErrOOMKilled = errors.New("killed by the OOM killer at its memory limit")
+)
+
+// minCPU is the smallest CPU limit: cgroup v2 refuses a cpu.max quota below
+// 1ms per 100ms period.
+const minCPU = 0.01
+
+// ResourceLimits caps what a Command and every process it starts may consume.
+// The Runner enforces them by starting the child directly inside a transient
+// cgroup v2 subtree created for that one command and removed after it; a
+// process that daemonizes is still inside it and is killed with it. Zero
+// fields are unlimited.
+type ResourceLimits struct {
+	// CPU is the CPU time allowed, in CPUs: 0.5 is half of one CPU, 2 is two
+	// full CPUs (cpu.max). At least 0.01.
+	CPU float64
+	// Memory is the memory limit in bytes (memory.max). Swap is disabled for
+	// the cgroup, so exceeding it OOM-kills the whole command, reported as
+	// ErrOOMKilled.
+	Memory int64
+	// Tasks is the maximum number of processes and threads (pids.max); a fork
+	// past it fails inside the child.
+	Tasks int
+	// IO caps read/write throughput per block device (io.max).
+	IO []IODeviceLimit
+}
+
+// IODeviceLimit caps IO to one block device. Zero rates are unlimited, but at
+// least one must be set.
+type IODeviceLimit struct {
+	Device    string // absolute path of the block device, e.g. /dev/nvme0n1
+	ReadBPS   int64  // bytes per second
+	WriteBPS  int64
+	ReadIOPS  int64 // operations per second
+	WriteIOPS int64
+}
+
+// Validate reports a malformed limit with ErrInvalidResourceLimits. It is pure:
+// whether a Device exists is only known when the cgroup is created. The Runner
+// and exectest.FakeRunner apply the same check.
+func (l ResourceLimits) Validate() error {
+	if l.CPU < 0 || (l.CPU > 0 && l.CPU < minCPU) {
+		return fmt.Errorf("%w: CPU %v must be 0 (unlimited) or at least %v", ErrInvalidResourceLimits, l.CPU, minCPU)
+	}
+	if l.Memory < 0 {
+		return fmt.Errorf("%w: Memory %d is negative", ErrInvalidResourceLimits, l.Memory)
+	}
+	if l.Tasks < 0 {
+		return fmt.Errorf("%w: Tasks %d is negative", ErrInvalidResourceLimits, l.Tasks)
+	}
+	for _, d := range l.IO {
+		if !filepath.IsAbs(d.Device) {
+			return fmt.Errorf("%w: IO device %q must be an absolute path", ErrInvalidResourceLimits, d.Device)
+		}
+		if d.ReadBPS < 0 || d.WriteBPS < 0 || d.ReadIOPS < 0 || d.WriteIOPS < 0 {
+			return fmt.Errorf("%w: IO rates for %s must not be negative", ErrInvalidResourceLimits, d.Device)
+		}
+		if d.ReadBPS == 0 && d.WriteBPS == 0 && d.ReadIOPS == 0 && d.WriteIOPS == 0 {
+			return fmt.Errorf("%w: IO limit for %s sets no rate", ErrInvalidResourceLimits, d.Device)
+		}
+	}
+	return nil
+}
+
+// controllers lists the cgroup v2 controllers l needs.
+func (l ResourceLimits) controllers() []string {
+	var cs []string
+	if l.CPU > 0 {
+		cs = append(cs, "cpu")
+	}
+	if l.Memory > 0 {
+		cs = append(cs, "memory")
+	}
+	if l.Tasks > 0 {
+		cs = append(cs, "pids")
+	}
+	if len(l.IO) > 0 {
+		cs = append(cs, "io")
+	}
+	return cs
+}
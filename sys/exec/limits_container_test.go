@@ -0,0 +1,122 @@
+//go:build container
+
+// Container-based real-execution tests for Command.Limits. The unit tests pin
+// the cgroup file protocol against a fake tree; these run real children under a
+// real cgroup v2 hierarchy, so the kernel's side — the no-internal-processes
+// rule that makes the Runner step into its agent leaf, clone3 straight into the
+// cgroup, the OOM kill and its memory.events record — is exercised for real.
+//
+// Runs in the container-tests lane as root with a writable cgroup v2 mount
+// (docker run --privileged) → Direct runner.
+package exec_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+)
+
+func limitsRunner(t *testing.T) pmexec.Runner {
+	t.Helper()
+	if os.Geteuid() != 0 {
+		t.Skip("not root; cgroup delegation not exercisable")
+	}
+	if _, err := os.Stat("/sys/fs/cgroup/cgroup.controllers"); err != nil {
+		t.Skip("no cgroup v2 mount at /sys/fs/cgroup")
+	}
+	r, err := pmexec.NewRunner(pmexec.Direct)
+	if err != nil {
+		t.Fatalf("NewRunner(Direct): %v", err)
+	}
+	return r
+}
+
+// ownCgroupDir is the cgroup the child reports, as a path under the mount.
+const ownCgroupDir = `/sys/fs/cgroup$(sed -n 's/^0:://p' /proc/self/cgroup)`
+
+// A command over its memory limit is OOM-killed, and the Runner reports that
+// as a *CommandError wrapping ErrOOMKilled rather than a bare SIGKILL exit.
+func TestLimits_MemoryOOMKill_Container(t *testing.T) {
+	_, err := limitsRunner(t).Run(t.Context(), pmexec.Command{
+		Name:   "sh",
+		Args:   []string{"-c", "head -c 512M /dev/zero | tail"}, // tail buffers the newline-free input
+		Limits: &pmexec.ResourceLimits{Memory: 32 << 20},
+	})
+	if !errors.Is(err, pmexec.ErrOOMKilled) {
+		t.Fatalf("err = %v, want ErrOOMKilled", err)
+	}
+	var ce *pmexec.CommandError
+	if !errors.As(err, &ce) || ce.Name != "sh" {
+		t.Errorf("err = %#v, want a *CommandError for sh", err)
+	}
+}
+
+// The same command under a generous limit is not misreported as an OOM kill.
+func TestLimits_WithinMemoryLimit_Container(t *testing.T) {
+	res, err := limitsRunner(t).Run(t.Context(), pmexec.Command{
+		Name:   "sh",
+		Args:   []string{"-c", "head -c 1M /dev/zero | wc -c"},
+		Limits: &pmexec.ResourceLimits{Memory: 64 << 20},
+	})
+	if err != nil || strings.TrimSpace(res.Stdout) != "1048576" {
+		t.Fatalf("Run = %+v, %v", res, err)
+	}
+}
+
+func TestLimits_TasksCapForks_Container(t *testing.T) {
+	res, err := limitsRunner(t).Run(t.Context(), pmexec.Command{
+		Name:   "sh",
+		Args:   []string{"-c", "for i in 1 2 3 4 5 6 7 8; do sleep 1 & done; wait"},
+		Limits: &pmexec.ResourceLimits{Tasks: 4},
+	})
+	if err != nil {
+		t.Fatalf("Run err = %v", err)
+	}
+	if !strings.Contains(strings.ToLower(res.Stderr), "fork") {
+		t.Errorf("stderr = %q, want a fork failure past pids.max", res.Stderr)
+	}
+}
+
+// The child starts inside its own cgroup with the limits already written.
+func TestLimits_ChildRunsInLimitedCgroup_Container(t *testing.T) {
+	res, err := limitsRunner(t).Run(t.Context(), pmexec.Command{
+		Name:   "sh",
+		Args:   []string{"-c", "cat " + ownCgroupDir + "/cpu.max " + ownCgroupDir + "/pids.max"},
+		Limits: &pmexec.ResourceLimits{CPU: 0.5, Tasks: 8},
+	})
+	if err != nil {
+		t.Fatalf("Run err = %v", err)
+	}
+	if got := strings.Fields(res.Stdout); strings.Join(got, " ") != "50000 100000 8" {
+		t.Errorf("cpu.max/pids.max = %q, want 50000 100000 / 8", res.Stdout)
+	}
+}
+
+// A child that daemonizes out of the process group is still in the cgroup, and
+// is killed with it; the cgroup itself is removed.
+func TestLimits_CleansUpDaemonizedChild_Container(t *testing.T) {
+	res, err := limitsRunner(t).Run(t.Context(), pmexec.Command{
+		Name:   "sh",
+		Args:   []string{"-c", "setsid sleep 300 </dev/null >/dev/null 2>&1 & echo $!; echo " + ownCgroupDir},
+		Limits: &pmexec.ResourceLimits{Tasks: 8},
+	})
+	if err != nil {
+		t.Fatalf("Run err = %v", err)
+	}
+	lines := strings.Fields(res.Stdout)
+	if len(lines) != 2 {
+		t.Fatalf("stdout = %q, want pid and cgroup", res.Stdout)
+	}
+	pid, _ := strconv.Atoi(lines[0])
+	if stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat")); err == nil && !strings.Contains(string(stat), ") Z ") {
+		t.Errorf("daemonized child %d survived: %s", pid, stat)
+	}
+	if _, err := os.Stat(lines[1]); !os.IsNotExist(err) {
+		t.Errorf("cgroup %s not removed: %v", lines[1], err)
+	}
+}
@@ -0,0 +1,69 @@
+package exec
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// ResourceLimits.Validate is the pure gate both the real Runner and the fake
+// apply before any cgroup exists: negative or sub-minimum values and an IO
+// entry that limits nothing are rejected with ErrInvalidResourceLimits.
+func TestResourceLimits_Validate(t *testing.T) {
+	valid := []ResourceLimits{
+		{},
+		{CPU: 0.01},
+		{CPU: 2, Memory: 64 << 20, Tasks: 32},
+		{IO: []IODeviceLimit{{Device: "/dev/sda", WriteBPS: 10 << 20}}},
+	}
+	for _, l := range valid {
+		if err := l.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v, want nil", l, err)
+		}
+	}
+	invalid := []ResourceLimits{
+		{CPU: -1},
+		{CPU: 0.001},
+		{Memory: -1},
+		{Tasks: -1},
+		{IO: []IODeviceLimit{{Device: "sda", ReadBPS: 1}}},
+		{IO: []IODeviceLimit{{Device: "/dev/sda", ReadIOPS: -1}}},
+		{IO: []IODeviceLimit{{Device: "/dev/sda"}}},
+	}
+	for _, l := range invalid {
+		if err := l.Validate(); !errors.Is(err, ErrInvalidResourceLimits) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidResourceLimits", l, err)
+		}
+	}
+}
+
+func TestResourceLimits_Controllers(t *testing.T) {
+	got := ResourceLimits{CPU: 1, Memory: 1, Tasks: 1, IO: []IODeviceLimit{{}}}.controllers()
+	if want := []string{"cpu", "memory", "pids", "io"}; !slices.Equal(got, want) {
+		t.Errorf("controllers = %v, want %v", got, want)
+	}
+	if got := (ResourceLimits{Memory: 1}).controllers(); !slices.Equal(got, []string{"memory"}) {
+		t.Errorf("controllers = %v, want only memory", got)
+	}
+}
+
+// An invalid limit is rejected before the child is spawned.
+func TestRunner_InvalidLimitsRejectedBeforeExec(t *testing.T) {
+	r := directRunner(t)
+	_, err := r.Run(t.Context(), Command{Name: "true", Limits: &ResourceLimits{Tasks: -1}})
+	if !errors.Is(err, ErrInvalidResourceLimits) {
+		t.Fatalf("Run err = %v, want ErrInvalidResourceLimits", err)
+	}
+}
+
+// An OOM kill surfaces as a *CommandError wrapping ErrOOMKilled, whose message
+// says so.
+func TestCommandError_OOMKilled(t *testing.T) {
+	var err error = &CommandError{Name: "osqueryi", ExitCode: -1, Err: ErrOOMKilled}
+	if !errors.Is(err, ErrOOMKilled) {
+		t.Error("errors.Is(err, ErrOOMKilled) = false")
+	}
+	if got, want := err.Error(), "osqueryi: exit -1: killed by the OOM killer at its memory limit"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"time"
+)
+
+// Middleware decorates a Runner: it returns a Runner that does something
+// around each command and hands it on to next. Chain composes them.
+type Middleware func(next Runner) Runner
+
+// Chain wraps r in mw. The first middleware is the outermost: it sees each
+// command first and its result last. A nil middleware is skipped. A nil r
+// stays nil, so a capability constructor still rejects it with
+// ErrRunnerRequired.
+func Chain(r Runner, mw ...Middleware) Runner {
+	if r == nil {
+		return nil
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		if mw[i] != nil {
+			r = mw[i](r)
+		}
+	}
+	return r
+}
+
+// CallFunc runs a command: the next Runner's Run, or its Stream with the
+// caller's callback already bound.
+type CallFunc func(ctx context.Context, c Command) (Result, error)
+
+// InterceptFunc is the body of a middleware built with Intercept. It may
+// change ctx or c, call next any number of times (or not at all), and
+// inspect or replace the result.
+type InterceptFunc func(ctx context.Context, c Command, next CallFunc) (Result, error)
+
+// Intercept turns fn into a Middleware that applies it to Run and Stream
+// alike. Backend is the next Runner's.
+func Intercept(fn InterceptFunc) Middleware {
+	return func(next Runner) Runner {
+		return &interceptor{next: next, fn: fn}
+	}
+}
+
+type interceptor struct {
+	next Runner
+	fn   InterceptFunc
+}
+
+func (i *interceptor) Backend() PrivilegeBackend { return i.next.Backend() }
+
+func (i *interceptor) Run(ctx context.Context, c Command) (Result, error) {
+	return i.fn(ctx, c, i.next.Run)
+}
+
+func (i *interceptor) Stream(ctx context.Context, c Command, onLine OutputCallback) (Result, error) {
+	return i.fn(ctx, c, func(ctx context.Context, c Command) (Result, error) {
+		return i.next.Stream(ctx, c, onLine)
+	})
+}
+
+// Unwrap returns the Runner this middleware wraps, so Planned finds a
+// DryRunner under a chain.
+func (i *interceptor) Unwrap() Runner { return i.next }
+
+// Timeouts bounds each command by the limit for its binary, keyed by base
+// name ("apt-get", not "/usr/bin/apt-get"). A binary with no entry, or a
+// non-positive one, runs under the caller's context unchanged, and a sooner
+// deadline on the caller's context still wins. A command cut off by its
+// limit returns an error matching context.DeadlineExceeded that names the
+// limit.
+func Timeouts(limits map[string]time.Duration) Middleware {
+	limits = maps.Clone(limits)
+	return Intercept(func(ctx context.Context, c Command, next CallFunc) (Result, error) {
+		d := limits[filepath.Base(c.Name)]
+		if d <= 0 {
+			return next(ctx, c)
+		}
+		tctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		res, err := next(tctx, c)
+		if err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return res, fmt.Errorf("exec: %s timed out after %s: %w", c.Name, d, err)
+		}
+		return res, err
+	})
+}
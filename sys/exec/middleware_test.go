@@ -0,0 +1,109 @@
+package exec_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+// tag is a middleware that appends name to the command's args on the way in
+// and to its stdout on the way out, to make the order visible.
+func tag(name string) pmexec.Middleware {
+	return pmexec.Intercept(func(ctx context.Context, c pmexec.Command, next pmexec.CallFunc) (pmexec.Result, error) {
+		c.Args = append(slices.Clone(c.Args), name)
+		res, err := next(ctx, c)
+		res.Stdout += name
+		return res, err
+	})
+}
+
+func TestChain_Order(t *testing.T) {
+	base := exectest.New(pmexec.Doas)
+	r := pmexec.Chain(base, tag("outer"), nil, tag("inner"))
+	res, err := r.Run(t.Context(), pmexec.Command{Name: "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base.Calls()[0].Args; !slices.Equal(got, []string{"outer", "inner"}) {
+		t.Errorf("args at the base = %q, want outer first", got)
+	}
+	if res.Stdout != "innerouter" {
+		t.Errorf("stdout = %q, want the outer middleware to see the result last", res.Stdout)
+	}
+	if r.Backend() != pmexec.Doas {
+		t.Errorf("Backend = %v, want the base's", r.Backend())
+	}
+}
+
+// Stream goes through the same middleware with the caller's callback intact.
+func TestChain_Stream(t *testing.T) {
+	base := exectest.New(0)
+	base.Push(pmexec.Result{Stdout: "line\n"}, nil)
+	r := pmexec.Chain(base, tag("mw"))
+	var lines []string
+	res, err := r.Stream(t.Context(), pmexec.Command{Name: "true"}, func(_ pmexec.StreamType, line string, _ int64) {
+		lines = append(lines, line)
+	})
+	if err != nil || res.Stdout != "line\nmw" {
+		t.Fatalf("Stream = %+v, %v", res, err)
+	}
+	if len(lines) != 1 || lines[0] != "line\n" {
+		t.Errorf("callback saw %q", lines)
+	}
+}
+
+func TestChain_NilRunnerStaysNil(t *testing.T) {
+	if r := pmexec.Chain(nil, tag("mw")); r != nil {
+		t.Errorf("Chain(nil) = %v, want nil", r)
+	}
+}
+
+// A dry run still sees in-process changes when it sits under middleware.
+func TestPlanned_ThroughChain(t *testing.T) {
+	d, _ := pmexec.NewDryRunner(exectest.New(0))
+	r := pmexec.Chain(d, tag("a"), tag("b"))
+	if !pmexec.Planned(r, "write /etc/motd") {
+		t.Fatal("Planned through a chain = false")
+	}
+	if got := d.Plan().String(); got != "write /etc/motd" {
+		t.Errorf("plan = %q", got)
+	}
+	if pmexec.Planned(pmexec.Chain(exectest.New(0), tag("a")), "x") {
+		t.Error("Planned without a DryRunner = true")
+	}
+}
+
+// blockingRunner runs every command until its context ends.
+type blockingRunner struct{ exectest.FakeRunner }
+
+func (b *blockingRunner) Run(ctx context.Context, _ pmexec.Command) (pmexec.Result, error) {
+	<-ctx.Done()
+	return pmexec.Result{ExitCode: -1}, ctx.Err()
+}
+
+func TestTimeouts(t *testing.T) {
+	r := pmexec.Chain(&blockingRunner{}, pmexec.Timeouts(map[string]time.Duration{
+		"apt-get": 20 * time.Millisecond,
+		"dnf":     0,
+	}))
+
+	_, err := r.Run(t.Context(), pmexec.Command{Name: "/usr/bin/apt-get", Args: []string{"update"}})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out after 20ms") {
+		t.Errorf("err = %v, want a named DeadlineExceeded", err)
+	}
+
+	// No limit: the caller's context alone bounds it, and its error is not
+	// dressed up as a per-binary timeout.
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	_, err = r.Run(ctx, pmexec.Command{Name: "dnf"})
+	if !errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "timed out after") {
+		t.Errorf("err = %v, want the caller's own deadline", err)
+	}
+}
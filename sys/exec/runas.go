@@ -0,0 +1,186 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Run-as sentinels, errors.Is-matchable like the escalation sentinels.
+var (
+	// ErrInvalidRunAs is returned for a Command whose User/Group cannot mean
+	// a privilege drop: a Group without a User, a User together with
+	// Escalate, or a User that resolves to root (use Escalate for that).
+	ErrInvalidRunAs = errors.New("invalid run-as user")
+
+	// ErrUnknownUser is returned when Command.User or Command.Group does not
+	// resolve to an account or group on this host.
+	ErrUnknownUser = errors.New("unknown user or group")
+
+	// ErrRunAsUnavailable is returned when Command.User is set but the agent
+	// is not running as root, so it cannot change credentials. The Runner
+	// fails closed rather than running the command as the agent.
+	ErrRunAsUnavailable = errors.New("run-as requires the agent to run as root")
+)
+
+// Test seams for the host-dependent run-as inputs. Production never reassigns
+// them.
+var (
+	geteuid     = os.Geteuid
+	lookupUser  = user.Lookup
+	lookupUID   = user.LookupId
+	lookupGroup = user.LookupGroup
+	lookupGID   = user.LookupGroupId
+	userGroups  = (*user.User).GroupIds
+)
+
+// runAs is a resolved Command.User/Group: the credentials the child starts
+// with and the account facts its login environment is built from.
+type runAs struct {
+	name   string
+	uid    uint32
+	gid    uint32
+	groups []uint32
+	home   string
+}
+
+// validateRunAs is the pure shape check on Command.User/Group.
+func validateRunAs(c Command) error {
+	if c.Group != "" && c.User == "" {
+		return fmt.Errorf("%w: Group %q needs a User", ErrInvalidRunAs, c.Group)
+	}
+	if c.User != "" && c.Escalate {
+		return fmt.Errorf("%w: User and Escalate are mutually exclusive", ErrInvalidRunAs)
+	}
+	return nil
+}
+
+// resolveRunAs looks up c.User and c.Group. User and Group accept a name or a
+// numeric id; a numeric User must still have a passwd entry, which supplies
+// the home directory and supplementary groups. Without a Group the child runs
+// with the user's primary group. The supplementary groups are always the
+// user's own, so nothing of the agent's group membership leaks into the child.
+func resolveRunAs(c Command) (*runAs, error) {
+	if geteuid() != 0 {
+		return nil, fmt.Errorf("%w: cannot run %s as %s", ErrRunAsUnavailable, c.Name, c.User)
+	}
+	u, err := lookupUser(c.User)
+	if err != nil && isNumeric(c.User) {
+		u, err = lookupUID(c.User)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: user %q: %v", ErrUnknownUser, c.User, err)
+	}
+	uid, err := parseID(u.Uid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user %q has uid %q", ErrUnknownUser, c.User, u.Uid)
+	}
+	if uid == 0 {
+		return nil, fmt.Errorf("%w: %q is root; use Escalate", ErrInvalidRunAs, c.User)
+	}
+	gidStr := u.Gid
+	if c.Group != "" {
+		g, err := lookupGroup(c.Group)
+		if err != nil && isNumeric(c.Group) {
+			g, err = lookupGID(c.Group)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: group %q: %v", ErrUnknownUser, c.Group, err)
+		}
+		gidStr = g.Gid
+	}
+	gid, err := parseID(gidStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: group id %q", ErrUnknownUser, gidStr)
+	}
+	ids, err := userGroups(u)
+	if err != nil {
+		return nil, fmt.Errorf("%w: groups of %q: %v", ErrUnknownUser, c.User, err)
+	}
+	groups := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		g, err := parseID(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: group id %q of %q", ErrUnknownUser, id, c.User)
+		}
+		groups = append(groups, g)
+	}
+	return &runAs{name: u.Username, uid: uid, gid: gid, groups: groups, home: u.HomeDir}, nil
+}
+
+// env is the clean, login-like environment of a run-as child. Nothing is
+// inherited from the agent: PATH is c.ChildPath or the user's default, then
+// the account variables a session would set, then the caller's validated Env
+// (which may override all but PATH).
+func (a *runAs) env(c Command) []string {
+	path := c.ChildPath
+	if path == "" {
+		path = a.defaultPath()
+	}
+	runtimeDir := "/run/user/" + strconv.FormatUint(uint64(a.uid), 10)
+	env := []string{
+		"PATH=" + path,
+		"HOME=" + a.home,
+		"USER=" + a.name,
+		"LOGNAME=" + a.name,
+		"XDG_RUNTIME_DIR=" + runtimeDir,
+		"DBUS_SESSION_BUS_ADDRESS=unix:path=" + runtimeDir + "/bus",
+	}
+	return append(env, c.Env...)
+}
+
+// workDir is where a run-as child starts without a Command.Dir: the user's
+// home, or / for an account without one (nobody's /nonexistent), as login
+// does. Never the agent's working directory.
+func (a *runAs) workDir() string {
+	if fi, err := os.Stat(a.home); err == nil && fi.IsDir() {
+		return a.home
+	}
+	return "/"
+}
+
+// defaultPath is the user's PATH: their own bin dirs first, then the system
+// ones. It is never derived from the agent's (root's) PATH.
+func (a *runAs) defaultPath() string {
+	return strings.Join([]string{
+		a.home + "/.local/bin",
+		a.home + "/bin",
+		"/usr/local/bin",
+		"/usr/bin",
+		"/bin",
+		"/usr/local/sbin",
+		"/usr/sbin",
+		"/sbin",
+	}, ":")
+}
+
+// beforeExec sets the child's credentials; the forked child applies
+// setgroups, setgid and setuid, in that order, before it execs. nil for a
+// command that runs as the agent.
+func (a *runAs) beforeExec() func(*exec.Cmd) {
+	if a == nil {
+		return nil
+	}
+	return func(c *exec.Cmd) {
+		// go-cmd has already set Setpgid; keep it.
+		if c.SysProcAttr == nil {
+			c.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		c.SysProcAttr.Credential = &syscall.Credential{Uid: a.uid, Gid: a.gid, Groups: a.groups}
+	}
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 32)
+	return err == nil
+}
+
+func parseID(s string) (uint32, error) {
+	n, err := strconv.ParseUint(s, 10, 32)
+	return uint32(n), err
+}
@@ -0,0 +1,113 @@
+//go:build container
+
+// Container-based real-execution tests for Command.User/Group. The unit tests
+// pin resolution and the env against a fake passwd; these create real accounts
+// and check, from inside the child, the credentials the kernel actually gave it.
+//
+// Runs in the container-tests lane (root) → Direct runner.
+package exec_test
+
+import (
+	"errors"
+	"os"
+	osexec "os/exec"
+	"strings"
+	"testing"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+)
+
+// runAsAccount creates a user with a home and a supplementary group, removed
+// again when the test ends.
+func runAsAccount(t *testing.T) pmexec.Runner {
+	t.Helper()
+	if os.Geteuid() != 0 {
+		t.Skip("not root; credential changes not exercisable")
+	}
+	for _, c := range [][]string{
+		{"groupadd", "pm-runas-extra"},
+		{"useradd", "-m", "-G", "pm-runas-extra", "pm-runas"},
+	} {
+		if out, err := osexec.Command(c[0], c[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("%v: %v: %s", c, err, out)
+		}
+	}
+	t.Cleanup(func() {
+		_ = osexec.Command("userdel", "-r", "pm-runas").Run()
+		_ = osexec.Command("groupdel", "pm-runas-extra").Run()
+	})
+	r, err := pmexec.NewRunner(pmexec.Direct)
+	if err != nil {
+		t.Fatalf("NewRunner(Direct): %v", err)
+	}
+	return r
+}
+
+func TestRunAs_CredentialsAndLoginEnv_Container(t *testing.T) {
+	r := runAsAccount(t)
+	t.Setenv("PM_AGENT_ONLY", "must-not-leak")
+	res, err := r.Run(t.Context(), pmexec.Command{
+		Name: "sh",
+		Args: []string{"-c", `id -un; id -Gn; pwd; echo "$HOME $USER $XDG_RUNTIME_DIR"; echo "agent=${PM_AGENT_ONLY:-}"`},
+		User: "pm-runas",
+	})
+	if err != nil || res.ExitCode != 0 {
+		t.Fatalf("Run = %+v, %v", res, err)
+	}
+	lines := strings.Split(strings.TrimSpace(res.Stdout), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("stdout = %q", res.Stdout)
+	}
+	if lines[0] != "pm-runas" {
+		t.Errorf("user = %q", lines[0])
+	}
+	if !strings.Contains(" "+lines[1]+" ", " pm-runas-extra ") || strings.Contains(" "+lines[1]+" ", " root ") {
+		t.Errorf("groups = %q, want pm-runas-extra and none of root's", lines[1])
+	}
+	if lines[2] != "/home/pm-runas" {
+		t.Errorf("cwd = %q, want the user's home", lines[2])
+	}
+	uid := strings.TrimSpace(mustOutput(t, "id", "-u", "pm-runas"))
+	if want := "/home/pm-runas pm-runas /run/user/" + uid; lines[3] != want {
+		t.Errorf("env = %q, want %q", lines[3], want)
+	}
+	if lines[4] != "agent=" {
+		t.Errorf("%s: the agent's environment leaked into the run-as child", lines[4])
+	}
+}
+
+func TestRunAs_GroupOverride_Container(t *testing.T) {
+	r := runAsAccount(t)
+	res, err := r.Run(t.Context(), pmexec.Command{Name: "id", Args: []string{"-gn"}, User: "pm-runas", Group: "pm-runas-extra"})
+	if err != nil || strings.TrimSpace(res.Stdout) != "pm-runas-extra" {
+		t.Fatalf("Run = %+v, %v; want primary group pm-runas-extra", res, err)
+	}
+}
+
+// The drop is real: the child cannot read a root-only file.
+func TestRunAs_CannotReadRootOnlyFile_Container(t *testing.T) {
+	r := runAsAccount(t)
+	res, err := r.Run(t.Context(), pmexec.Command{Name: "cat", Args: []string{"/etc/shadow"}, User: "pm-runas"})
+	if err != nil {
+		t.Fatalf("Run err = %v", err)
+	}
+	if res.ExitCode == 0 {
+		t.Error("run-as child read /etc/shadow")
+	}
+}
+
+func TestRunAs_UnknownUser_Container(t *testing.T) {
+	r := runAsAccount(t)
+	if _, err := r.Run(t.Context(), pmexec.Command{Name: "id", User: "pm-runas-missing"}); !errors.Is(err, pmexec.ErrUnknownUser) {
+		t.Errorf("err = %v, want ErrUnknownUser", err)
+	}
+}
+
+func mustOutput(t *testing.T, name string, args ...string) string {
+	t.Helper()
+	out, err := osexec.Command(name, args...).Output()
+	if err != nil {
+		t.Fatalf("%s %v: %v", name, args, err)
+	}
+	return string(out)
+}
@@ -0,0 +1,176 @@
+package exec
+
+import (
+	"errors"
+	"os/user"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeAccounts replaces the run-as seams with a host-independent passwd/group
+// view: alice (1000, primary group 1000, also in wheel 10) and root, with the
+// agent running as euid.
+func fakeAccounts(t *testing.T, euid int) {
+	t.Helper()
+	users := map[string]*user.User{
+		"alice": {Uid: "1000", Gid: "1000", Username: "alice", HomeDir: "/home/alice"},
+		"root":  {Uid: "0", Gid: "0", Username: "root", HomeDir: "/root"},
+	}
+	groups := map[string]*user.Group{"alice": {Gid: "1000", Name: "alice"}, "wheel": {Gid: "10", Name: "wheel"}}
+	oldEUID, oldUser, oldUID, oldGroup, oldGID, oldGroups := geteuid, lookupUser, lookupUID, lookupGroup, lookupGID, userGroups
+	t.Cleanup(func() {
+		geteuid, lookupUser, lookupUID, lookupGroup, lookupGID, userGroups = oldEUID, oldUser, oldUID, oldGroup, oldGID, oldGroups
+	})
+	geteuid = func() int { return euid }
+	lookupUser = func(name string) (*user.User, error) {
+		if u, ok := users[name]; ok {
+			return u, nil
+		}
+		return nil, user.UnknownUserError(name)
+	}
+	lookupUID = func(uid string) (*user.User, error) {
+		for _, u := range users {
+			if u.Uid == uid {
+				return u, nil
+			}
+		}
+		return nil, user.UnknownUserError(uid)
+	}
+	lookupGroup = func(name string) (*user.Group, error) {
+		if g, ok := groups[name]; ok {
+			return g, nil
+		}
+		return nil, user.UnknownGroupError(name)
+	}
+	lookupGID = func(gid string) (*user.Group, error) {
+		for _, g := range groups {
+			if g.Gid == gid {
+				return g, nil
+			}
+		}
+		return nil, user.UnknownGroupIdError(gid)
+	}
+	userGroups = func(u *user.User) ([]string, error) {
+		if u.Username == "alice" {
+			return []string{"1000", "10"}, nil
+		}
+		return []string{u.Gid}, nil
+	}
+}
+
+func TestResolveRunAs(t *testing.T) {
+	fakeAccounts(t, 0)
+	for _, tt := range []struct {
+		name      string
+		c         Command
+		wantGID   uint32
+		wantGroup []uint32
+	}{
+		{"by name", Command{Name: "id", User: "alice"}, 1000, []uint32{1000, 10}},
+		{"by uid", Command{Name: "id", User: "1000"}, 1000, []uint32{1000, 10}},
+		{"group override", Command{Name: "id", User: "alice", Group: "wheel"}, 10, []uint32{1000, 10}},
+		{"numeric group", Command{Name: "id", User: "alice", Group: "10"}, 10, []uint32{1000, 10}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			as, err := resolveRunAs(tt.c)
+			if err != nil {
+				t.Fatalf("resolveRunAs: %v", err)
+			}
+			if as.uid != 1000 || as.gid != tt.wantGID || !slices.Equal(as.groups, tt.wantGroup) || as.home != "/home/alice" {
+				t.Errorf("runAs = %+v", as)
+			}
+		})
+	}
+}
+
+func TestResolveRunAs_Rejects(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		euid int
+		c    Command
+		want error
+	}{
+		{"agent not root", 1000, Command{Name: "id", User: "alice"}, ErrRunAsUnavailable},
+		{"unknown user", 0, Command{Name: "id", User: "mallory"}, ErrUnknownUser},
+		{"uid without passwd entry", 0, Command{Name: "id", User: "4242"}, ErrUnknownUser},
+		{"unknown group", 0, Command{Name: "id", User: "alice", Group: "nogroup"}, ErrUnknownUser},
+		{"root is not a drop", 0, Command{Name: "id", User: "root"}, ErrInvalidRunAs},
+		{"uid 0 is not a drop", 0, Command{Name: "id", User: "0"}, ErrInvalidRunAs},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAccounts(t, tt.euid)
+			if _, err := resolveRunAs(tt.c); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// The shape checks are pure and shared with the fake through ValidateCommand.
+func TestValidateCommand_RunAsShape(t *testing.T) {
+	for _, c := range []Command{
+		{Name: "id", Group: "wheel"},
+		{Name: "id", User: "alice", Escalate: true},
+	} {
+		if err := ValidateCommand(c); !errors.Is(err, ErrInvalidRunAs) {
+			t.Errorf("ValidateCommand(%+v) = %v, want ErrInvalidRunAs", c, err)
+		}
+	}
+	if err := ValidateCommand(Command{Name: "id", User: "alice", Group: "wheel"}); err != nil {
+		t.Errorf("ValidateCommand(valid run-as) = %v", err)
+	}
+}
+
+// A run-as child gets a login-like env built from the account, with nothing
+// inherited from the agent; the forced locale still wins.
+func TestBuildChildEnv_RunAs(t *testing.T) {
+	fakeAccounts(t, 0)
+	t.Setenv("AGENT_SECRET_CONTEXT", "leak")
+	c := Command{Name: "dconf", User: "alice", Env: []string{"DCONF_PROFILE=user"}}
+	as, err := resolveRunAs(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := buildChildEnv(c, as)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"PATH=/home/alice/.local/bin:/home/alice/bin:/usr/local/bin:/usr/bin:/bin:/usr/local/sbin:/usr/sbin:/sbin",
+		"HOME=/home/alice",
+		"USER=alice",
+		"LOGNAME=alice",
+		"XDG_RUNTIME_DIR=/run/user/1000",
+		"DBUS_SESSION_BUS_ADDRESS=unix:path=/run/user/1000/bus",
+		"DCONF_PROFILE=user",
+	}
+	want = append(want, forcedEnv...)
+	if !slices.Equal(env, want) {
+		t.Errorf("env =\n%s\nwant\n%s", strings.Join(env, "\n"), strings.Join(want, "\n"))
+	}
+
+	c.ChildPath = "/opt/tool/bin"
+	env, _ = buildChildEnv(c, as)
+	if env[0] != "PATH=/opt/tool/bin" {
+		t.Errorf("PATH = %q, want ChildPath to win", env[0])
+	}
+}
+
+// The env hijack gate applies to run-as commands like any other.
+func TestRunner_RunAsScreensHijackEnv(t *testing.T) {
+	fakeAccounts(t, 0)
+	_, err := directRunner(t).Run(t.Context(), Command{Name: "true", User: "alice", Env: []string{"LD_PRELOAD=/tmp/x.so"}})
+	if !errors.Is(err, ErrBlockedEnvVar) {
+		t.Fatalf("err = %v, want ErrBlockedEnvVar", err)
+	}
+}
+
+// A non-root agent refuses a run-as command rather than running it as itself.
+func TestRunner_RunAsFailsClosedWithoutRoot(t *testing.T) {
+	fakeAccounts(t, 1000)
+	res, err := directRunner(t).Run(t.Context(), Command{Name: "id", Args: []string{"-u"}, User: "alice"})
+	if !errors.Is(err, ErrRunAsUnavailable) || res.Stdout != "" {
+		t.Fatalf("Run = %+v, %v; want ErrRunAsUnavailable and no output", res, err)
+	}
+}
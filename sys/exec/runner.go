@@ -30,7 +30,7 @@ const (
 // Command describes one execution. The zero value is invalid — Name is
 // required. The capability layer fills this in and sets Escalate per operation;
 // it is escalation-method-agnostic. The Runner alone turns Escalate into the
-// concrete sudo/doas/bare invocation.
+// concrete sudo/doas/run0/pkexec/bare invocation.
 //
 // There is no locale knob: the Runner ALWAYS forces a deterministic environment
 // (LC_ALL=C, LANG=C, NO_COLOR=1) on every command so the SDK's parsing of tool
@@ -44,6 +44,25 @@ type Command struct {
 	Stdin     io.Reader // "" = no stdin
 	ChildPath string    // explicit, isolating child PATH; "" = inherit/sanitized
 	Escalate  bool      // run through the privilege backend
+	ReadOnly  bool      // only reads host state; a DryRunner runs it, see DryRunner
+	// User runs the command as this account (name or uid) instead of as the
+	// agent, which must be root: the child starts with the user's uid, gid
+	// and supplementary groups, in a clean login-like environment (PATH,
+	// HOME, USER, LOGNAME, XDG_RUNTIME_DIR, DBUS_SESSION_BUS_ADDRESS, then
+	// Env) that inherits nothing from the agent, and in the user's home (or
+	// / without one) unless Dir is set. Mutually exclusive with Escalate.
+	User string
+	// Group overrides User's primary group (name or gid); it requires User.
+	Group string
+	// Limits caps the command's CPU, memory, task count and IO; nil =
+	// unlimited. A command OOM-killed at Limits.Memory returns a
+	// *CommandError wrapping ErrOOMKilled. See ResourceLimits.
+	Limits *ResourceLimits
+	// Sandbox runs the command confined: a read-only root with only the
+	// declared writable paths, private namespaces, no network unless
+	// requested and a syscall deny-list; nil = unconfined. Mutually
+	// exclusive with Escalate. See Sandbox.
+	Sandbox *Sandbox
 }
 
 // Runner abstracts command execution + privilege escalation. It is injected
@@ -70,7 +89,7 @@ type runner struct{ backend PrivilegeBackend }
 // value and any unimplemented backend are rejected with ErrUnknownBackend.
 func NewRunner(b PrivilegeBackend) (Runner, error) {
 	switch b {
-	case Sudo, Doas, Direct:
+	case Sudo, Doas, Direct, Run0, Pkexec:
 		return &runner{backend: b}, nil
 	default:
 		return nil, fmt.Errorf("%w: %d", ErrUnknownBackend, int(b))
@@ -98,12 +117,27 @@ func (r *runner) exec(ctx context.Context, c Command, onLine OutputCallback) (Re
 	if c.Name == "" {
 		return Result{}, fmt.Errorf("exec: command name is required")
 	}
-	// Compose + validate the child env FIRST, so a blocked env var (LD_PRELOAD,
-	// PATH, …) is rejected before the child is ever spawned.
-	env, err := buildChildEnv(c)
+	// Apply the pure gates FIRST, so a blocked env var (LD_PRELOAD, PATH, …),
+	// a malformed limit or sandbox, or a contradictory run-as is rejected
+	// before anything touches the host.
+	if err := ValidateCommand(c); err != nil {
+		return Result{}, err
+	}
+	var as *runAs
+	if c.User != "" {
+		var err error
+		if as, err = resolveRunAs(c); err != nil {
+			return Result{}, err
+		}
+	}
+	env, err := buildChildEnv(c, as)
 	if err != nil {
 		return Result{}, err
 	}
+	dir := c.Dir
+	if dir == "" && as != nil {
+		dir = as.workDir()
+	}
 	// Resolve to an absolute path so it matches sudoers/doas.conf rules and is
 	// deterministic regardless of the child's PATH.
 	absPath, err := resolveAbsolute(c.Name)
@@ -120,16 +154,71 @@ func (r *runner) exec(ctx context.Context, c Command, onLine OutputCallback) (Re
 		}
 	}
 	name, argv := wrapEscalation(r.backend, c.Escalate, absPath, c.Args)
+	if c.Escalate && polkitTool(r.backend) != "" {
+		// run0 starts the command outside the agent's cgroup, where a limit
+		// set here would not reach it.
+		if c.Limits != nil && r.backend == Run0 {
+			return Result{}, fmt.Errorf("%w: run0 runs %s outside the agent's cgroup", ErrResourceLimitsUnavailable, c.Name)
+		}
+		var envTool string
+		if r.backend == Pkexec {
+			if envTool, err = resolveAbsolute("env"); err != nil {
+				return Result{}, fmt.Errorf("%w: env not found for pkexec", ErrEscalationUnavailable)
+			}
+		}
+		if name, argv, err = wrapPolkit(r.backend, envTool, absPath, c); err != nil {
+			return Result{}, err
+		}
+	}
+
+	// A sandboxed command runs under bwrap, which execs it confined. Like a
+	// limit, a sandbox that cannot be built means no command.
+	var sb *sandbox
+	if c.Sandbox != nil {
+		if sb, err = newSandbox(*c.Sandbox); err != nil {
+			return Result{}, err
+		}
+		defer sb.close()
+		name, argv = sb.wrap(name, argv)
+	}
+
+	// A limited command runs in its own transient cgroup, created last so
+	// every earlier rejection leaves nothing behind. It fails closed: no
+	// cgroup, no command.
+	var cg *cgroup
+	if c.Limits != nil {
+		if cg, err = newCgroup(*c.Limits); err != nil {
+			return Result{}, err
+		}
+		defer cg.remove()
+	}
 
-	res, runErr := runStreamingWithStdin(ctx, name, argv, c.Stdin, env, c.Dir, onLine)
+	hooks := []func(*exec.Cmd){cg.beforeExec(), as.beforeExec(), sb.beforeExec()}
+	res, runErr := runStreamingWithStdin(ctx, name, argv, c.Stdin, env, dir, hooks, onLine)
 	result := Result{}
 	if res != nil {
 		result = *res
 	}
+	return r.outcome(c, cg, result, runErr)
+}
+
+// outcome classifies a finished command: an OOM kill, a failed run, or a
+// wrapper's refusal to escalate.
+func (r *runner) outcome(c Command, cg *cgroup, result Result, runErr error) (Result, error) {
+	// An OOM kill is a SIGKILL like any other by its exit status, and go-cmd
+	// reports it as "signal: killed"; only the cgroup's memory.events tells
+	// them apart, so it is checked before runErr.
+	if cg.oomKilled() {
+		oom := ErrOOMKilled
+		if runErr != nil {
+			oom = fmt.Errorf("%w: %w", ErrOOMKilled, runErr)
+		}
+		return result, &CommandError{Name: c.Name, ExitCode: result.ExitCode, Stderr: result.Stderr, Err: oom}
+	}
 	if runErr != nil {
 		return result, runErr
 	}
-	// A sudo/doas -n auth refusal is an escalation failure, distinct from the
+	// A wrapper's auth refusal is an escalation failure, distinct from the
 	// wrapped command's own non-zero exit.
 	if c.Escalate {
 		if denied := detectEscalationDenied(r.backend, result); denied != nil {
@@ -161,17 +250,18 @@ func escalationTool(b PrivilegeBackend) string {
 	case Doas:
 		return "doas"
 	default:
-		return ""
+		return polkitTool(b)
 	}
 }
 
 // wrapEscalation turns (backend, escalate, absolute-path, args) into the final
 // (name, argv). Pure: no I/O. When escalation is requested and the backend uses
 // a wrapper, the wrapper runs with -n (never prompt) and the resolved absolute
-// path. The caller's args slice is never aliased or mutated.
+// path. The polkit backends carry the environment across and are wrapped by
+// wrapPolkit instead. The caller's args slice is never aliased or mutated.
 func wrapEscalation(b PrivilegeBackend, escalate bool, absPath string, args []string) (string, []string) {
 	tool := escalationTool(b)
-	if !escalate || tool == "" { // bare invocation (no escalation, or Direct)
+	if !escalate || tool == "" || polkitTool(b) != "" { // bare invocation (no escalation, Direct, or left to wrapPolkit)
 		return absPath, append([]string(nil), args...)
 	}
 	argv := make([]string, 0, len(args)+2)
@@ -181,10 +271,10 @@ func wrapEscalation(b PrivilegeBackend, escalate bool, absPath string, args []st
 }
 
 // detectEscalationDenied recognises a sudo/doas -n refusal (a password would be
-// required) and turns it into ErrEscalationDenied — distinct from the wrapped
-// command's own non-zero exit. Pure: it inspects only the Result, and matches
-// the wrappers' own diagnostic strings so a genuine command failure is never
-// misclassified.
+// required) or a polkit refusal under run0/pkexec, and turns it into
+// ErrEscalationDenied — distinct from the wrapped command's own non-zero exit.
+// Pure: it inspects only the Result, and matches the wrappers' own diagnostic
+// strings so a genuine command failure is never misclassified.
 func detectEscalationDenied(b PrivilegeBackend, res Result) error {
 	if res.ExitCode == 0 {
 		return nil
@@ -202,6 +292,17 @@ func detectEscalationDenied(b PrivilegeBackend, res Result) error {
 			strings.Contains(s, "Authentication failed") {
 			return fmt.Errorf("%w: %s", ErrEscalationDenied, strings.TrimSpace(s))
 		}
+	case Run0:
+		if strings.Contains(s, "Interactive authentication required") ||
+			(strings.Contains(s, "Failed to start transient service unit") && strings.Contains(s, "Access denied")) {
+			return fmt.Errorf("%w: %s", ErrEscalationDenied, strings.TrimSpace(s))
+		}
+	case Pkexec:
+		// "Not authorized", "Request dismissed", "No authentication agent
+		// found": every polkit refusal.
+		if strings.Contains(s, "Error executing command as another user") {
+			return fmt.Errorf("%w: %s", ErrEscalationDenied, strings.TrimSpace(s))
+		}
 	}
 	return nil
 }
@@ -219,10 +320,12 @@ var forcedEnv = []string{"LC_ALL=C", "LANG=C", "NO_COLOR=1"}
 // imposes forcedEnv (non-overridable). The hijack blocklist (LD_PRELOAD, PATH,
 // BASH_ENV, …) plus the forced-locale/NO_COLOR names are enforced on Command.Env;
 // a curated PATH goes through ChildPath, which REPLACES (never augments) the
-// parent env — the isolation the per-user runuser fan-out needs. The default
-// (no ChildPath, no Env) inherits the parent fully; in every case forcedEnv is
-// appended last so the deterministic vars always win.
-func buildChildEnv(c Command) ([]string, error) {
+// parent env — the isolation the per-user runuser fan-out needs. A run-as
+// command (as non-nil) gets the user's login-like env, likewise replacing the
+// parent's. The default (no ChildPath, no Env, no User) inherits the parent
+// fully; in every case forcedEnv is appended last so the deterministic vars
+// always win.
+func buildChildEnv(c Command, as *runAs) ([]string, error) {
 	// Identical gate the FakeRunner applies (ValidateCommandEnv): KEY=VALUE,
 	// hijack-blocklist, and the forced-locale/NO_COLOR reserved names a consumer
 	// may not set via Env.
@@ -230,6 +333,9 @@ func buildChildEnv(c Command) ([]string, error) {
 		return nil, err
 	}
 	switch {
+	case as != nil:
+		// Login-like env of the target user; nothing of the agent's.
+		return append(as.env(c), forcedEnv...), nil
 	case c.ChildPath != "":
 		// Curated, isolating env (runuser fan-out): PATH + caller Env + forced.
 		return append(composeEnv(c.ChildPath, c.Env), forcedEnv...), nil
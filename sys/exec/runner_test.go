@@ -27,7 +27,7 @@ func TestNewRunner_RejectsZeroAndUnknown(t *testing.T) {
 }
 
 func TestNewRunner_AcceptsImplementedBackends(t *testing.T) {
-	for _, b := range []PrivilegeBackend{Sudo, Doas, Direct} {
+	for _, b := range []PrivilegeBackend{Sudo, Doas, Direct, Run0, Pkexec} {
 		r, err := NewRunner(b)
 		if err != nil {
 			t.Fatalf("NewRunner(%d) err = %v, want nil", b, err)
@@ -58,6 +58,8 @@ func TestWrapEscalation(t *testing.T) {
 		{Doas, true, "doas", []string{"-n", abs, "-m", "deploy"}},
 		{Sudo, false, abs, []string{"-m", "deploy"}}, // no escalation requested → bare
 		{Doas, false, abs, []string{"-m", "deploy"}},
+		{Run0, true, abs, []string{"-m", "deploy"}}, // left to wrapPolkit
+		{Pkexec, true, abs, []string{"-m", "deploy"}},
 	}
 	for _, tc := range tests {
 		name, argv := wrapEscalation(tc.backend, tc.escalate, abs, args)
@@ -135,7 +137,7 @@ func TestBuildChildEnv_DefaultBranchDropsHijackVars(t *testing.T) {
 	t.Setenv("PM_BENIGN_TEST_VAR", "keep-me") // a normal var that must survive
 	t.Setenv("LANG", "de_DE.UTF-8")           // reserved; forced LANG=C must win
 
-	env, err := buildChildEnv(Command{}) // default branch: inherit parent
+	env, err := buildChildEnv(Command{}, nil) // default branch: inherit parent
 	if err != nil {
 		t.Fatalf("buildChildEnv: %v", err)
 	}
@@ -318,6 +320,11 @@ func TestDetectEscalationDenied(t *testing.T) {
 		{"sudo password required", Sudo, Result{ExitCode: 1, Stderr: "sudo: a password is required"}, true},
 		{"sudo terminal required", Sudo, Result{ExitCode: 1, Stderr: "sudo: a terminal is required to read the password"}, true},
 		{"doas auth failure", Doas, Result{ExitCode: 1, Stderr: "doas: Authorization required"}, true},
+		{"run0 without a polkit grant", Run0, Result{ExitCode: 1, Stderr: "Failed to start transient service unit: Interactive authentication required."}, true},
+		{"run0 access denied", Run0, Result{ExitCode: 1, Stderr: "Failed to start transient service unit: Access denied"}, true},
+		{"run0 command failure not denied", Run0, Result{ExitCode: 1, Stderr: "useradd: user already exists"}, false},
+		{"pkexec not authorized", Pkexec, Result{ExitCode: 127, Stderr: "Error executing command as another user: Not authorized"}, true},
+		{"pkexec command failure not denied", Pkexec, Result{ExitCode: 1, Stderr: "Access denied"}, false},
 		{"direct never denied", Direct, Result{ExitCode: 1, Stderr: "anything"}, false},
 		{"clean exit not denied", Sudo, Result{ExitCode: 0}, false},
 		{"genuine command failure not denied", Sudo, Result{ExitCode: 1, Stderr: "useradd: user already exists"}, false},
@@ -0,0 +1,132 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Sandbox sentinels, errors.Is-matchable like the escalation sentinels.
+var (
+	// ErrInvalidSandbox is returned for a Command whose Sandbox cannot be
+	// honoured as written: a writable path that is relative, unclean, / or
+	// missing, or a Sandbox together with Escalate.
+	ErrInvalidSandbox = errors.New("invalid sandbox")
+
+	// ErrSandboxUnavailable is returned when Command.Sandbox is set but this
+	// host cannot build it: bubblewrap (bwrap) is not installed, or the
+	// architecture has no syscall filter. The Runner fails closed — a
+	// sandboxed command never runs unconfined.
+	ErrSandboxUnavailable = errors.New("sandbox unavailable")
+)
+
+// sandboxTool is the bubblewrap binary the sandbox is built with.
+const sandboxTool = "bwrap"
+
+// Sandbox confines a Command. The Runner starts it under bubblewrap in new
+// user, mount, PID, IPC, UTS and cgroup namespaces, with every capability
+// dropped and a seccomp filter that denies the syscalls a confined script has
+// no business making (mounting, loading kernel modules or BPF, tracing other
+// processes, entering namespaces, changing the clock, rebooting). The whole
+// host filesystem is visible but read-only, with a private /tmp, a minimal
+// /dev and a fresh /proc. The zero value is the strictest profile.
+type Sandbox struct {
+	// WritablePaths are absolute paths bound read-write into the read-only
+	// root. Each must exist when the command starts.
+	WritablePaths []string
+	// Network keeps the host's network. Without it the command gets a
+	// private network namespace with only loopback.
+	Network bool
+}
+
+// Validate reports whether s is well-formed, without touching the host.
+func (s Sandbox) Validate() error {
+	for _, p := range s.WritablePaths {
+		if !filepath.IsAbs(p) || filepath.Clean(p) != p {
+			return fmt.Errorf("%w: writable path %q must be absolute and clean", ErrInvalidSandbox, p)
+		}
+		if p == "/" {
+			return fmt.Errorf("%w: writable path / would undo the read-only root", ErrInvalidSandbox)
+		}
+	}
+	return nil
+}
+
+// validateSandbox is the pure shape check on Command.Sandbox.
+func validateSandbox(c Command) error {
+	if c.Sandbox == nil {
+		return nil
+	}
+	if c.Escalate {
+		return fmt.Errorf("%w: Sandbox and Escalate are mutually exclusive", ErrInvalidSandbox)
+	}
+	return c.Sandbox.Validate()
+}
+
+// sandbox is a prepared Command.Sandbox: the resolved bwrap and the seccomp
+// filter it reads from the child's fd 3.
+type sandbox struct {
+	bwrap  string
+	cfg    Sandbox
+	filter *os.File
+}
+
+// newSandbox resolves bwrap, checks the writable paths exist and builds the
+// syscall filter. The caller must close the result.
+func newSandbox(s Sandbox) (*sandbox, error) {
+	bwrap, err := resolveAbsolute(sandboxTool)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s not installed", ErrSandboxUnavailable, sandboxTool)
+	}
+	for _, p := range s.WritablePaths {
+		if _, err := os.Stat(p); err != nil {
+			return nil, fmt.Errorf("%w: writable path: %v", ErrInvalidSandbox, err)
+		}
+	}
+	filter, err := seccompFilterFile()
+	if err != nil {
+		return nil, err
+	}
+	return &sandbox{bwrap: bwrap, cfg: s, filter: filter}, nil
+}
+
+// wrap turns the command's (name, argv) into the bwrap invocation that runs
+// it confined.
+func (s *sandbox) wrap(name string, argv []string) (string, []string) {
+	return s.bwrap, bwrapArgs(s.cfg, name, argv)
+}
+
+// bwrapArgs is the bwrap argv for running name with argv under cfg. Pure: no
+// I/O. The filter is read from fd 3, where beforeExec puts it; bwrap loads it
+// last, after its own namespace and mount setup.
+func bwrapArgs(cfg Sandbox, name string, argv []string) []string {
+	args := []string{"--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp"}
+	for _, p := range cfg.WritablePaths {
+		args = append(args, "--bind", p, p)
+	}
+	args = append(args, "--unshare-user", "--unshare-pid", "--unshare-ipc", "--unshare-uts", "--unshare-cgroup")
+	if !cfg.Network {
+		args = append(args, "--unshare-net")
+	}
+	args = append(args, "--die-with-parent", "--new-session", "--cap-drop", "ALL", "--seccomp", "3", "--", name)
+	return append(args, argv...)
+}
+
+// beforeExec hands the filter to the child as fd 3. nil for an unconfined
+// command.
+func (s *sandbox) beforeExec() func(*exec.Cmd) {
+	if s == nil {
+		return nil
+	}
+	return func(c *exec.Cmd) {
+		c.ExtraFiles = []*os.File{s.filter}
+	}
+}
+
+func (s *sandbox) close() {
+	if s != nil {
+		_ = s.filter.Close()
+	}
+}
@@ -0,0 +1,125 @@
+//go:build container
+
+// Container-based real-execution tests for Command.Sandbox. The unit tests pin
+// the bwrap argv and the filter's verdicts; these run real children under real
+// bubblewrap, so the namespaces, the read-only root and the kernel's seccomp
+// enforcement are checked from inside the sandbox.
+//
+// Runs in the container-tests lane as root with --privileged (docker's own
+// seccomp profile otherwise refuses the user namespace bwrap creates) →
+// Direct runner.
+package exec_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+)
+
+func sandboxRunner(t *testing.T) pmexec.Runner {
+	t.Helper()
+	r, err := pmexec.NewRunner(pmexec.Direct)
+	if err != nil {
+		t.Fatalf("NewRunner(Direct): %v", err)
+	}
+	return r
+}
+
+func runSandboxed(t *testing.T, sb pmexec.Sandbox, script string) pmexec.Result {
+	t.Helper()
+	res, err := sandboxRunner(t).Run(t.Context(), pmexec.Command{Name: "sh", Args: []string{"-c", script}, Sandbox: &sb})
+	if err != nil {
+		t.Fatalf("Run err = %v", err)
+	}
+	return res
+}
+
+// The root is read-only except the declared writable paths and a private /tmp
+// that vanishes with the command.
+func TestSandbox_ReadOnlyRootAndWritablePaths_Container(t *testing.T) {
+	dir := t.TempDir()
+	res := runSandboxed(t, pmexec.Sandbox{WritablePaths: []string{dir}},
+		`touch /etc/pm-sandbox-probe 2>/dev/null && echo etc-writable; echo ok > `+dir+`/out; echo scratch > /tmp/pm-sandbox-scratch`)
+	if strings.Contains(res.Stdout, "etc-writable") {
+		t.Error("the sandbox wrote to /etc")
+	}
+	if _, err := os.Stat("/etc/pm-sandbox-probe"); err == nil {
+		os.Remove("/etc/pm-sandbox-probe")
+		t.Error("/etc/pm-sandbox-probe exists on the host")
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "out")); err != nil || string(b) != "ok\n" {
+		t.Errorf("writable path: %q, %v; stderr %q", b, err, res.Stderr)
+	}
+	if _, err := os.Stat("/tmp/pm-sandbox-scratch"); err == nil {
+		t.Error("the sandbox's /tmp is the host's")
+	}
+}
+
+// Without Network the command sees only loopback; with it, the host's
+// interfaces.
+func TestSandbox_Network_Container(t *testing.T) {
+	ifaces := func(sb pmexec.Sandbox) []string {
+		res := runSandboxed(t, sb, `sed -n 's/^ *\([^:]*\):.*/\1/p' /proc/net/dev`)
+		return strings.Fields(res.Stdout)
+	}
+	if got := ifaces(pmexec.Sandbox{}); strings.Join(got, " ") != "lo" {
+		t.Errorf("interfaces without Network = %q, want only lo", got)
+	}
+	if got := ifaces(pmexec.Sandbox{Network: true}); len(got) < 2 {
+		t.Errorf("interfaces with Network = %q, want the host's", got)
+	}
+}
+
+// The command has no capabilities, cannot see the agent's processes, and the
+// filter denies what the namespaces alone would allow.
+func TestSandbox_Confinement_Container(t *testing.T) {
+	res := runSandboxed(t, pmexec.Sandbox{},
+		`grep '^CapEff' /proc/self/status; test -e /proc/`+strconv.Itoa(os.Getpid())+` && echo agent-visible; unshare -U true && echo unshared; echo done`)
+	if !strings.Contains(res.Stdout, "CapEff:\t0000000000000000") {
+		t.Errorf("capabilities not dropped: %q", res.Stdout)
+	}
+	if strings.Contains(res.Stdout, "agent-visible") {
+		t.Error("the agent's pid is visible inside the sandbox")
+	}
+	if strings.Contains(res.Stdout, "unshared") || !strings.Contains(res.Stderr, "Operation not permitted") {
+		t.Errorf("unshare was not denied: stdout %q stderr %q", res.Stdout, res.Stderr)
+	}
+	if !strings.Contains(res.Stdout, "done") {
+		t.Errorf("script did not finish: %q %q", res.Stdout, res.Stderr)
+	}
+}
+
+// clone with a CLONE_NEW* flag is the other way into a namespace besides
+// unshare; perl's syscall makes the raw call, as a compiled tool would. A
+// plain fork through the same call still works.
+func TestSandbox_CloneNamespace_Container(t *testing.T) {
+	nr, ok := map[string]int{"amd64": 56, "arm64": 220}[runtime.GOARCH]
+	if !ok {
+		t.Skipf("no clone syscall number for %s", runtime.GOARCH)
+	}
+	const cloneNewUser, sigchld = 0x10000000, 17
+	clone := func(flags int) string {
+		return `$p = syscall(` + strconv.Itoa(nr) + `, ` + strconv.Itoa(flags) + `, 0, 0, 0, 0); exit 0 if $p == 0; ` +
+			`print $p > 0 ? "cloned\n" : "$!\n";`
+	}
+	res := runSandboxed(t, pmexec.Sandbox{}, `perl -e '$| = 1; `+clone(cloneNewUser|sigchld)+`'; perl -e '`+clone(sigchld)+`'`)
+	if got := strings.Split(res.Stdout, "\n"); len(got) < 2 || got[0] != "Operation not permitted" || got[1] != "cloned" {
+		t.Errorf("clone(CLONE_NEWUSER), then a plain clone: stdout %q stderr %q", res.Stdout, res.Stderr)
+	}
+}
+
+func TestSandbox_MissingWritablePath_Container(t *testing.T) {
+	_, err := sandboxRunner(t).Run(t.Context(), pmexec.Command{
+		Name:    "true",
+		Sandbox: &pmexec.Sandbox{WritablePaths: []string{"/nonexistent/pm-sandbox"}},
+	})
+	if !errors.Is(err, pmexec.ErrInvalidSandbox) {
+		t.Errorf("err = %v, want ErrInvalidSandbox", err)
+	}
+}
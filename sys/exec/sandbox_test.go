@@ -0,0 +1,75 @@
+package exec
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSandbox_Validate(t *testing.T) {
+	for _, s := range []Sandbox{{}, {Network: true}, {WritablePaths: []string{"/var/lib/app", "/srv"}}} {
+		if err := s.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v, want nil", s, err)
+		}
+	}
+	for _, p := range []string{"var/lib/app", "/var/lib/../../etc", "/srv/", "/", ""} {
+		if err := (Sandbox{WritablePaths: []string{p}}).Validate(); !errors.Is(err, ErrInvalidSandbox) {
+			t.Errorf("Validate(writable %q) = %v, want ErrInvalidSandbox", p, err)
+		}
+	}
+}
+
+func TestValidateCommand_SandboxShape(t *testing.T) {
+	if err := ValidateCommand(Command{Name: "sh", Escalate: true, Sandbox: &Sandbox{}}); !errors.Is(err, ErrInvalidSandbox) {
+		t.Errorf("Sandbox+Escalate: err = %v, want ErrInvalidSandbox", err)
+	}
+	if err := ValidateCommand(Command{Name: "sh", User: "alice", Sandbox: &Sandbox{}}); err != nil {
+		t.Errorf("Sandbox+User: err = %v, want nil", err)
+	}
+}
+
+// The strictest profile: read-only root, private /tmp, every namespace
+// including the network, no capabilities, and the filter on fd 3, with the
+// command after the -- so none of its args can be read as a bwrap option.
+func TestBwrapArgs(t *testing.T) {
+	got := bwrapArgs(Sandbox{}, "/bin/sh", []string{"-c", "--bind / /"})
+	want := []string{
+		"--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp",
+		"--unshare-user", "--unshare-pid", "--unshare-ipc", "--unshare-uts", "--unshare-cgroup", "--unshare-net",
+		"--die-with-parent", "--new-session", "--cap-drop", "ALL", "--seccomp", "3",
+		"--", "/bin/sh", "-c", "--bind / /",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("bwrapArgs =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestBwrapArgs_WritablePathsAndNetwork(t *testing.T) {
+	got := strings.Join(bwrapArgs(Sandbox{WritablePaths: []string{"/srv/a", "/srv/b"}, Network: true}, "/bin/true", nil), " ")
+	if !strings.Contains(got, "--tmpfs /tmp --bind /srv/a /srv/a --bind /srv/b /srv/b --unshare-user") {
+		t.Errorf("writable paths not bound after the read-only root: %s", got)
+	}
+	if strings.Contains(got, "--unshare-net") {
+		t.Errorf("Network: true still unshares the network: %s", got)
+	}
+}
+
+// A sandboxed command fails closed without bwrap: it never runs unconfined.
+func TestRunner_SandboxUnavailableFailsClosed(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "ran")
+	_, err := directRunner(t).Run(t.Context(), Command{
+		Name:    "/bin/sh",
+		Args:    []string{"-c", "touch " + marker},
+		Sandbox: &Sandbox{},
+	})
+	if !errors.Is(err, ErrSandboxUnavailable) {
+		t.Fatalf("err = %v, want ErrSandboxUnavailable", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("the command ran despite its sandbox being unavailable")
+	}
+}
@@ -0,0 +1,124 @@
+//go:build linux && (amd64 || arm64)
+
+package exec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// deniedSyscalls fail with EPERM inside a Sandbox. It is a deny-list, not an
+// allow-list: scripts run arbitrary tools, so everything else stays allowed
+// and the namespaces and dropped capabilities do the rest. These are the
+// calls that reach past the sandbox — into the kernel (modules, BPF, kexec),
+// into other processes (ptrace, process_vm_*), into new or other namespaces
+// and mounts, or around the filter itself (io_uring executes operations
+// without passing through seccomp). clone is filtered on its flags instead,
+// and clone3 refused outright; see seccompProgram.
+var deniedSyscalls = append([]uint32{
+	unix.SYS_MOUNT, unix.SYS_UMOUNT2, unix.SYS_PIVOT_ROOT, unix.SYS_CHROOT,
+	unix.SYS_OPEN_TREE, unix.SYS_MOVE_MOUNT, unix.SYS_FSOPEN, unix.SYS_FSCONFIG,
+	unix.SYS_FSMOUNT, unix.SYS_FSPICK, unix.SYS_MOUNT_SETATTR,
+	unix.SYS_UNSHARE, unix.SYS_SETNS,
+	unix.SYS_INIT_MODULE, unix.SYS_FINIT_MODULE, unix.SYS_DELETE_MODULE,
+	unix.SYS_KEXEC_LOAD, unix.SYS_KEXEC_FILE_LOAD, unix.SYS_REBOOT,
+	unix.SYS_BPF, unix.SYS_PERF_EVENT_OPEN, unix.SYS_USERFAULTFD,
+	unix.SYS_PTRACE, unix.SYS_PROCESS_VM_READV, unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_KEYCTL, unix.SYS_ADD_KEY, unix.SYS_REQUEST_KEY,
+	unix.SYS_SWAPON, unix.SYS_SWAPOFF, unix.SYS_ACCT, unix.SYS_QUOTACTL, unix.SYS_SYSLOG,
+	unix.SYS_SETTIMEOFDAY, unix.SYS_CLOCK_SETTIME, unix.SYS_ADJTIMEX, unix.SYS_CLOCK_ADJTIME,
+	unix.SYS_OPEN_BY_HANDLE_AT, unix.SYS_NAME_TO_HANDLE_AT,
+	unix.SYS_IO_URING_SETUP, unix.SYS_IO_URING_ENTER, unix.SYS_IO_URING_REGISTER,
+}, archDeniedSyscalls...)
+
+// Offsets into struct seccomp_data. seccompDataArg0 is the low half of the
+// first argument; both supported architectures are little-endian.
+const (
+	seccompDataNr   = 0
+	seccompDataArch = 4
+	seccompDataArg0 = 16
+)
+
+// cloneNamespaceFlags are the CLONE_NEW* flags clone accepts. CLONE_NEWTIME
+// is left out: clone reads those bits as the exit signal, and only unshare
+// and clone3 can create a time namespace.
+const cloneNamespaceFlags = unix.CLONE_NEWNS | unix.CLONE_NEWCGROUP | unix.CLONE_NEWUTS |
+	unix.CLONE_NEWIPC | unix.CLONE_NEWUSER | unix.CLONE_NEWPID | unix.CLONE_NEWNET
+
+// seccompProgram is the classic-BPF filter bwrap installs: kill a syscall
+// made through a foreign ABI (whose numbers mean different calls), EPERM the
+// denied ones and a clone with any cloneNamespaceFlags, allow the rest.
+// clone3 passes its flags in memory the filter cannot read, so it fails with
+// ENOSYS, as under Docker's default profile; libc then falls back to clone.
+// Encoded as the kernel's struct sock_filter array in host byte order, the
+// format bwrap --seccomp reads.
+func seccompProgram() []byte {
+	type insn struct {
+		code   uint16
+		jt, jf uint8
+		k      uint32
+	}
+	prog := []insn{
+		{code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, k: seccompDataArch},
+		{code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, jt: 1, k: auditArch},
+		{code: unix.BPF_RET | unix.BPF_K, k: unix.SECCOMP_RET_KILL_PROCESS},
+		{code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, k: seccompDataNr},
+	}
+	// Every check below jumps forward to one of the three verdicts at the
+	// end: the denied calls, clone3, then clone and its flags.
+	checks := len(deniedSyscalls) + 4
+	if x32SyscallBit != 0 {
+		checks++
+	}
+	allow := len(prog) + checks
+	deny, enosys := allow+1, allow+2
+	jump := func(to int) uint8 { return uint8(to - len(prog) - 1) }
+	if x32SyscallBit != 0 {
+		prog = append(prog, insn{code: unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K, jt: jump(deny), k: x32SyscallBit})
+	}
+	for _, nr := range deniedSyscalls {
+		prog = append(prog, insn{code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, jt: jump(deny), k: nr})
+	}
+	prog = append(prog, insn{code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, jt: jump(enosys), k: unix.SYS_CLONE3})
+	prog = append(prog, insn{code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, jf: jump(allow), k: unix.SYS_CLONE})
+	prog = append(prog, insn{code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, k: seccompDataArg0})
+	prog = append(prog, insn{code: unix.BPF_JMP | unix.BPF_JSET | unix.BPF_K, jt: jump(deny), jf: jump(allow), k: cloneNamespaceFlags})
+	prog = append(prog,
+		insn{code: unix.BPF_RET | unix.BPF_K, k: unix.SECCOMP_RET_ALLOW},
+		insn{code: unix.BPF_RET | unix.BPF_K, k: unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)},
+		insn{code: unix.BPF_RET | unix.BPF_K, k: unix.SECCOMP_RET_ERRNO | uint32(unix.ENOSYS)},
+	)
+	out := make([]byte, 0, 8*len(prog))
+	for _, in := range prog {
+		out = binary.NativeEndian.AppendUint16(out, in.code)
+		out = append(out, in.jt, in.jf)
+		out = binary.NativeEndian.AppendUint32(out, in.k)
+	}
+	return out
+}
+
+// seccompFilterFile returns the filter in a sealed memfd positioned at its
+// start, ready to hand to bwrap as an inherited fd.
+func seccompFilterFile() (*os.File, error) {
+	fd, err := unix.MemfdCreate("pm-sandbox-seccomp", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return nil, fmt.Errorf("%w: memfd: %v", ErrSandboxUnavailable, err)
+	}
+	f := os.NewFile(uintptr(fd), "pm-sandbox-seccomp")
+	if _, err := f.Write(seccompProgram()); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w: write filter: %v", ErrSandboxUnavailable, err)
+	}
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SEAL|unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w: seal filter: %v", ErrSandboxUnavailable, err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w: rewind filter: %v", ErrSandboxUnavailable, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,14 @@
+package exec
+
+import "golang.org/x/sys/unix"
+
+// auditArch is the seccomp_data.arch of a native syscall.
+const auditArch = unix.AUDIT_ARCH_X86_64
+
+// x32SyscallBit marks an x32-ABI syscall, which shares AUDIT_ARCH_X86_64 but
+// numbers calls differently; the filter denies all of them.
+const x32SyscallBit = 0x40000000
+
+// archDeniedSyscalls are the amd64-only additions to deniedSyscalls: direct
+// I/O port access.
+var archDeniedSyscalls = []uint32{unix.SYS_IOPL, unix.SYS_IOPERM}
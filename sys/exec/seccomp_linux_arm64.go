@@ -0,0 +1,12 @@
+package exec
+
+import "golang.org/x/sys/unix"
+
+// auditArch is the seccomp_data.arch of a native syscall.
+const auditArch = unix.AUDIT_ARCH_AARCH64
+
+// x32SyscallBit is zero: arm64 has no second ABI sharing its audit arch.
+const x32SyscallBit = 0
+
+// archDeniedSyscalls is empty: arm64 has no arch-only additions.
+var archDeniedSyscalls []uint32
@@ -0,0 +1,119 @@
+//go:build linux && (amd64 || arm64)
+
+package exec
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// runFilter evaluates the classic-BPF subset seccompProgram emits against one
+// syscall, as the kernel would. The container test runs the filter for real
+// under bwrap; this pins the verdict for every denied call on any host.
+func runFilter(t *testing.T, prog []byte, arch, nr uint32, arg0 uint64) uint32 {
+	t.Helper()
+	if len(prog)%8 != 0 || len(prog)/8 > 4096 {
+		t.Fatalf("program is %d bytes", len(prog))
+	}
+	var acc uint32
+	for pc := 0; pc < len(prog)/8; pc++ {
+		in := prog[pc*8:]
+		code, jt, jf, k := binary.NativeEndian.Uint16(in), in[2], in[3], binary.NativeEndian.Uint32(in[4:])
+		switch code {
+		case unix.BPF_LD | unix.BPF_W | unix.BPF_ABS:
+			switch k {
+			case seccompDataNr:
+				acc = nr
+			case seccompDataArch:
+				acc = arch
+			case seccompDataArg0:
+				acc = uint32(arg0)
+			default:
+				t.Fatalf("pc %d: load of seccomp_data offset %d", pc, k)
+			}
+		case unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K, unix.BPF_JMP | unix.BPF_JSET | unix.BPF_K:
+			hit := acc == k
+			switch code & 0xf0 {
+			case unix.BPF_JGE:
+				hit = acc >= k
+			case unix.BPF_JSET:
+				hit = acc&k != 0
+			}
+			if hit {
+				pc += int(jt)
+			} else {
+				pc += int(jf)
+			}
+		case unix.BPF_RET | unix.BPF_K:
+			return k
+		default:
+			t.Fatalf("pc %d: unexpected opcode %#x", pc, code)
+		}
+	}
+	t.Fatal("program fell off the end")
+	return 0
+}
+
+func TestSeccompProgram(t *testing.T) {
+	prog := seccompProgram()
+	eperm := uint32(unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM))
+	for _, nr := range deniedSyscalls {
+		if got := runFilter(t, prog, auditArch, nr, 0); got != eperm {
+			t.Errorf("syscall %d: verdict %#x, want EPERM", nr, got)
+		}
+	}
+	for _, nr := range []uint32{unix.SYS_READ, unix.SYS_WRITE, unix.SYS_EXECVE, unix.SYS_GETPID} {
+		if got := runFilter(t, prog, auditArch, nr, 0); got != unix.SECCOMP_RET_ALLOW {
+			t.Errorf("syscall %d: verdict %#x, want allow", nr, got)
+		}
+	}
+	if got := runFilter(t, prog, unix.AUDIT_ARCH_I386, unix.SYS_GETPID, 0); got != unix.SECCOMP_RET_KILL_PROCESS {
+		t.Errorf("foreign-ABI syscall: verdict %#x, want kill", got)
+	}
+	if x32SyscallBit != 0 {
+		if got := runFilter(t, prog, auditArch, x32SyscallBit|unix.SYS_GETPID, 0); got != eperm {
+			t.Errorf("x32 syscall: verdict %#x, want EPERM", got)
+		}
+	}
+}
+
+// clone is allowed for threads and forks, and denied when it asks for a new
+// namespace; clone3, whose flags the filter cannot read, is ENOSYS.
+func TestSeccompProgram_Clone(t *testing.T) {
+	prog := seccompProgram()
+	eperm := uint32(unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM))
+	thread := uint64(unix.CLONE_VM | unix.CLONE_FS | unix.CLONE_FILES | unix.CLONE_SIGHAND | unix.CLONE_THREAD | unix.CLONE_SYSVSEM)
+	for _, flags := range []uint64{uint64(unix.SIGCHLD), thread, unix.CLONE_VM | unix.CLONE_VFORK | uint64(unix.SIGCHLD)} {
+		if got := runFilter(t, prog, auditArch, unix.SYS_CLONE, flags); got != unix.SECCOMP_RET_ALLOW {
+			t.Errorf("clone(%#x): verdict %#x, want allow", flags, got)
+		}
+	}
+	for _, ns := range []uint64{unix.CLONE_NEWUSER, unix.CLONE_NEWNS, unix.CLONE_NEWNET, unix.CLONE_NEWPID, unix.CLONE_NEWUTS, unix.CLONE_NEWIPC, unix.CLONE_NEWCGROUP} {
+		if got := runFilter(t, prog, auditArch, unix.SYS_CLONE, ns|uint64(unix.SIGCHLD)); got != eperm {
+			t.Errorf("clone(%#x): verdict %#x, want EPERM", ns, got)
+		}
+	}
+	if got := runFilter(t, prog, auditArch, unix.SYS_CLONE3, 0); got != unix.SECCOMP_RET_ERRNO|uint32(unix.ENOSYS) {
+		t.Errorf("clone3: verdict %#x, want ENOSYS", got)
+	}
+}
+
+// bwrap reads the filter from an inherited fd: it must start at offset 0 and
+// hold exactly the program.
+func TestSeccompFilterFile(t *testing.T) {
+	f, err := seccompFilterFile()
+	if err != nil {
+		t.Fatalf("seccompFilterFile: %v", err)
+	}
+	defer f.Close()
+	buf := make([]byte, 1<<16)
+	n, _ := f.Read(buf)
+	if want := seccompProgram(); string(buf[:n]) != string(want) {
+		t.Errorf("filter file holds %d bytes, want the %d-byte program", n, len(want))
+	}
+	if _, err := f.Write([]byte{0}); err == nil {
+		t.Error("filter file is writable after sealing")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux || !(amd64 || arm64)
+
+package exec
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// seccompFilterFile fails closed where the filter has no syscall table: a
+// Sandbox is Linux-only, and its filter is written for amd64 and arm64.
+func seccompFilterFile() (*os.File, error) {
+	return nil, fmt.Errorf("%w: no syscall filter for %s/%s", ErrSandboxUnavailable, runtime.GOOS, runtime.GOARCH)
+}
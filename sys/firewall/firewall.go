@@ -294,6 +294,12 @@ func (c cmd) run(ctx context.Context, name string, args ...string) (exec.Result,
 	return c.exec(ctx, exec.Command{Name: name, Args: args, Escalate: true})
 }
 
+// read is run for a query (list, status, default zone), so a dry run still
+// answers it from the host.
+func (c cmd) read(ctx context.Context, name string, args ...string) (exec.Result, error) {
+	return c.exec(ctx, exec.Command{Name: name, Args: args, Escalate: true, ReadOnly: true})
+}
+
 func (c cmd) runStdin(ctx context.Context, stdin, name string, args ...string) (exec.Result, error) {
 	return c.exec(ctx, exec.Command{Name: name, Args: args, Stdin: strings.NewReader(stdin), Escalate: true})
 }
@@ -132,7 +132,7 @@ func (f *firewalld) RemoveRule(ctx context.Context, id string) error {
 // distinguish idempotency no-ops from real failures without parsing error
 // messages.
 func (f *firewalld) firewalldServiceIsEnabled(ctx context.Context, zone, svc string) (bool, error) {
-	res, err := f.run(ctx, "firewall-cmd",
+	res, err := f.read(ctx, "firewall-cmd",
 		"--permanent", "--zone="+zone, "--list-services",
 	)
 	if err != nil {
@@ -154,7 +154,7 @@ func (f *firewalld) List(ctx context.Context) ([]Rule, error) {
 	if err != nil {
 		return nil, err
 	}
-	res, err := f.run(ctx, "firewall-cmd",
+	res, err := f.read(ctx, "firewall-cmd",
 		"--permanent", "--zone="+zone, "--list-services",
 	)
 	if err != nil {
@@ -271,7 +271,7 @@ func firewalldReadServiceRule(namespace, id string) (Rule, bool) {
 // operators changing the default zone at runtime should see the new
 // answer on the next Apply.
 func (f *firewalld) firewalldDefaultZone(ctx context.Context) (string, error) {
-	res, err := f.run(ctx, "firewall-cmd", "--get-default-zone")
+	res, err := f.read(ctx, "firewall-cmd", "--get-default-zone")
 	if err != nil {
 		return "", fmt.Errorf("firewall-cmd --get-default-zone: %w", err)
 	}
@@ -121,7 +121,7 @@ func (n *nftables) List(ctx context.Context) ([]Rule, error) {
 // every other op here. A non-zero exit (table missing) surfaces as an error the
 // callers translate into "no managed rules".
 func (n *nftables) nftListJSON(ctx context.Context) ([]byte, error) {
-	res, err := n.run(ctx, "nft", "-j", "list", "table", nftFamily, nftTableName(n.ns))
+	res, err := n.read(ctx, "nft", "-j", "list", "table", nftFamily, nftTableName(n.ns))
 	if err != nil {
 		return nil, fmt.Errorf("nft list table: %w", err)
 	}
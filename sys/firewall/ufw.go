@@ -112,7 +112,7 @@ func (u *ufw) List(ctx context.Context) ([]Rule, error) {
 // because ufw insists on root for status even though the kernel state is
 // world-readable.
 func (u *ufw) ufwStatusNumbered(ctx context.Context) (string, error) {
-	res, err := u.run(ctx, "ufw", "status", "numbered")
+	res, err := u.read(ctx, "ufw", "status", "numbered")
 	if err != nil {
 		return "", fmt.Errorf("ufw status numbered: %w", err)
 	}
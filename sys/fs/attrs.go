@@ -0,0 +1,380 @@
+package fs
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+)
+
+// ErrInvalidAttributes is returned for Attributes that cannot be applied: a
+// malformed ACL entry, xattr name or SELinux context, a default ACL on a
+// file, or both SELinuxContext and RestoreContext.
+var ErrInvalidAttributes = errors.New("invalid file attributes")
+
+// Attributes is a file's security metadata beyond mode and ownership. The
+// zero value changes nothing. WriteFile, Mkdir, Copy and CopyTree apply the
+// set fields after the content, mode and ownership, in the order ACL,
+// DefaultACL, Xattrs, SELinux label, and the immutable/append-only flags
+// last, since those block every later change.
+type Attributes struct {
+	// ACL holds POSIX access ACL entries in setfacl form ("user:alice:rw-",
+	// "g:staff:r-x", "mask::rwx"). It replaces the path's extended entries;
+	// the owner, group and other entries stay governed by the mode. A
+	// non-nil empty slice strips the extended entries.
+	ACL []string
+	// DefaultACL is a directory's default ACL, inherited by what is created
+	// in it, in the same form without a "default:" prefix. It replaces the
+	// existing default ACL; a non-nil empty slice removes it. Files cannot
+	// carry one, so WriteFile and Copy reject it.
+	DefaultACL []string
+	// Xattrs sets extended attributes in the user., trusted. and security.
+	// namespaces. Other attributes on the path are left alone. The SELinux
+	// label is set through SELinuxContext, not security.selinux.
+	Xattrs map[string][]byte
+	// SELinuxContext labels the path (chcon), e.g.
+	// "system_u:object_r:httpd_config_t:s0".
+	SELinuxContext string
+	// RestoreContext resets the label to the policy default (restorecon -F).
+	// It is a no-op on a host without restorecon, which has no SELinux policy
+	// to restore from.
+	RestoreContext bool
+	// Immutable and AppendOnly set the i and a inode flags (chattr). When
+	// either is requested, both flags are first cleared from an existing
+	// target so it can be replaced.
+	Immutable, AppendOnly bool
+}
+
+func (a Attributes) flagged() bool { return a.Immutable || a.AppendOnly }
+
+var (
+	aclEntryRe  = regexp.MustCompile(`^(u|user|g|group|m|mask|o|other):([A-Za-z0-9_][A-Za-z0-9_.-]*\$?)?:[rwxX-]{1,4}$`)
+	xattrNameRe = regexp.MustCompile(`^(user|trusted|security)\.[A-Za-z0-9_.@+-]+$`)
+	seContextRe = regexp.MustCompile(`^[A-Za-z0-9_]+:[A-Za-z0-9_]+:[A-Za-z0-9_]+(:[A-Za-z0-9_.,:-]+)?$`)
+)
+
+const selinuxXattr = "security.selinux"
+
+// validateAttributes checks a before anything runs. dir reports whether the
+// target is a directory, which a default ACL requires.
+func validateAttributes(a Attributes, dir bool) error {
+	for _, e := range a.ACL {
+		if err := validateACLEntry(e); err != nil {
+			return err
+		}
+	}
+	if a.DefaultACL != nil && !dir {
+		return fmt.Errorf("%w: a default ACL applies to directories only", ErrInvalidAttributes)
+	}
+	for _, e := range a.DefaultACL {
+		if err := validateACLEntry(e); err != nil {
+			return err
+		}
+	}
+	for name := range a.Xattrs {
+		if !xattrNameRe.MatchString(name) {
+			return fmt.Errorf("%w: xattr name %q", ErrInvalidAttributes, name)
+		}
+		if name == selinuxXattr {
+			return fmt.Errorf("%w: set %s through SELinuxContext", ErrInvalidAttributes, selinuxXattr)
+		}
+	}
+	if a.SELinuxContext != "" {
+		if a.RestoreContext {
+			return fmt.Errorf("%w: SELinuxContext and RestoreContext are exclusive", ErrInvalidAttributes)
+		}
+		if !seContextRe.MatchString(a.SELinuxContext) {
+			return fmt.Errorf("%w: SELinux context %q", ErrInvalidAttributes, a.SELinuxContext)
+		}
+	}
+	return nil
+}
+
+func validateACLEntry(e string) error {
+	m := aclEntryRe.FindStringSubmatch(e)
+	if m == nil {
+		return fmt.Errorf("%w: ACL entry %q", ErrInvalidAttributes, e)
+	}
+	if tag := m[1]; (tag == "m" || tag == "mask" || tag == "o" || tag == "other") && m[2] != "" {
+		return fmt.Errorf("%w: ACL entry %q: %s takes no qualifier", ErrInvalidAttributes, e, tag)
+	}
+	return nil
+}
+
+// clearFlags drops the immutable and append-only flags from an existing path
+// about to be replaced or changed, when a asks for either. A missing path is
+// not an error.
+func (m *manager) clearFlags(ctx context.Context, path string, a Attributes) error {
+	if !a.flagged() {
+		return nil
+	}
+	res, err := m.runPriv(ctx, "chattr", "-i", "-a", "--", path)
+	if err != nil {
+		return err
+	}
+	// chattr reports ENOENT as "chattr: No such file or directory while
+	// trying to stat <path>", the reason before the path.
+	if res.ExitCode != 0 && strings.HasPrefix(strings.TrimSpace(res.Stderr), "chattr: No such file or directory while trying to stat") {
+		return nil
+	}
+	return cmdError("chattr", res)
+}
+
+// applyAttributes sets a on path. tree extends the SELinux label to
+// everything under path (CopyTree); the rest applies to path itself.
+func (m *manager) applyAttributes(ctx context.Context, path string, a Attributes, tree bool) error {
+	if a.ACL != nil {
+		args := []string{"-b"}
+		if len(a.ACL) > 0 {
+			args = append(args, "-m", strings.Join(a.ACL, ","))
+		}
+		if err := m.runChecked(ctx, "setfacl", append(args, "--", path)...); err != nil {
+			return fmt.Errorf("set ACL on %s: %w", path, err)
+		}
+	}
+	if a.DefaultACL != nil {
+		args := []string{"-k"}
+		if len(a.DefaultACL) > 0 {
+			args = append(args, "-d", "-m", strings.Join(a.DefaultACL, ","))
+		}
+		if err := m.runChecked(ctx, "setfacl", append(args, "--", path)...); err != nil {
+			return fmt.Errorf("set default ACL on %s: %w", path, err)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(a.Xattrs)) {
+		args := []string{"-h", "-n", name}
+		if v := a.Xattrs[name]; len(v) > 0 {
+			args = append(args, "-v", "0x"+hex.EncodeToString(v))
+		}
+		if err := m.runChecked(ctx, "setfattr", append(args, "--", path)...); err != nil {
+			return fmt.Errorf("set xattr %s on %s: %w", name, path, err)
+		}
+	}
+	if err := m.applyLabel(ctx, path, a, tree); err != nil {
+		return err
+	}
+	if a.flagged() {
+		var args []string
+		if a.Immutable {
+			args = append(args, "+i")
+		}
+		if a.AppendOnly {
+			args = append(args, "+a")
+		}
+		if err := m.runChecked(ctx, "chattr", append(args, "--", path)...); err != nil {
+			return fmt.Errorf("set flags on %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (m *manager) applyLabel(ctx context.Context, path string, a Attributes, tree bool) error {
+	var rec []string
+	if tree {
+		rec = []string{"-R"}
+	}
+	if a.SELinuxContext != "" {
+		args := append(append(rec, "-h", "--"), a.SELinuxContext, path)
+		if err := m.runChecked(ctx, "chcon", args...); err != nil {
+			return fmt.Errorf("set SELinux context on %s: %w", path, err)
+		}
+	}
+	if a.RestoreContext {
+		err := m.runChecked(ctx, "restorecon", append(rec, "-F", "--", path)...)
+		if errors.Is(err, pmexec.ErrBackendUnavailable) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("restore SELinux context on %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ReadAttributes reads back what Attributes can set, for drift checks: the
+// extended ACL entries (with the mask) in canonical long form, the default
+// ACL of a directory, every xattr other than the SELinux label and the ACLs
+// themselves, the label, and the immutable and append-only flags. A tool
+// that is not installed, or a filesystem without ACL or flag support,
+// leaves its fields empty. A symlink carries none of these but its xattrs.
+func (m *manager) ReadAttributes(ctx context.Context, path string) (Attributes, error) {
+	info, err := m.Stat(ctx, path)
+	if err != nil {
+		return Attributes{}, err
+	}
+	var a Attributes
+	for name, v := range info.Xattrs {
+		switch {
+		case name == selinuxXattr:
+			a.SELinuxContext = strings.TrimRight(string(v), "\x00")
+		case strings.HasPrefix(name, "system.posix_acl_"):
+		default:
+			if a.Xattrs == nil {
+				a.Xattrs = map[string][]byte{}
+			}
+			a.Xattrs[name] = v
+		}
+	}
+	if info.Mode&os.ModeSymlink != 0 {
+		return a, nil
+	}
+
+	res, err := m.runPrivRead(ctx, "getfacl", "-c", "-E", "-p", "--", path)
+	switch {
+	case errors.Is(err, pmexec.ErrBackendUnavailable):
+	case err != nil:
+		return Attributes{}, err
+	case res.ExitCode == 0:
+		a.ACL, a.DefaultACL = parseGetfacl(res.Stdout)
+	case !unsupportedStderr(res.Stderr):
+		return Attributes{}, fmt.Errorf("getfacl %s: %w", path, cmdError("getfacl", res))
+	}
+
+	res, err = m.runPrivRead(ctx, "lsattr", "-d", "--", path)
+	switch {
+	case errors.Is(err, pmexec.ErrBackendUnavailable):
+	case err != nil:
+		return Attributes{}, err
+	case res.ExitCode == 0:
+		if f := strings.Fields(res.Stdout); len(f) > 0 {
+			a.Immutable = strings.ContainsRune(f[0], 'i')
+			a.AppendOnly = strings.ContainsRune(f[0], 'a')
+		}
+	case !unsupportedStderr(res.Stderr):
+		return Attributes{}, fmt.Errorf("lsattr %s: %w", path, cmdError("lsattr", res))
+	}
+	return a, nil
+}
+
+// unsupportedStderr reports whether a getfacl/lsattr failure is the
+// filesystem lacking the feature rather than a real error.
+func unsupportedStderr(stderr string) bool {
+	return strings.Contains(stderr, "Operation not supported") ||
+		strings.Contains(stderr, "Inappropriate ioctl")
+}
+
+// parseGetfacl parses `getfacl -c -E` output into the extended access
+// entries and the default entries, in canonical form. The base user::,
+// group:: and other:: entries mirror the mode and are dropped from the
+// access ACL; the default ACL keeps them, since they are part of it.
+func parseGetfacl(out string) (acl, def []string) {
+	for _, line := range strings.Split(out, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "default:"); ok {
+			def = append(def, canonicalACLEntry(rest))
+			continue
+		}
+		if e := canonicalACLEntry(line); !isBaseEntry(e) {
+			acl = append(acl, e)
+		}
+	}
+	return acl, def
+}
+
+// canonicalACLEntry expands a setfacl entry to getfacl's form: long tag
+// names and a three-character rwx permission field.
+func canonicalACLEntry(e string) string {
+	parts := strings.SplitN(e, ":", 3)
+	if len(parts) != 3 {
+		return e
+	}
+	switch parts[0] {
+	case "u":
+		parts[0] = "user"
+	case "g":
+		parts[0] = "group"
+	case "m":
+		parts[0] = "mask"
+	case "o":
+		parts[0] = "other"
+	}
+	perm := []byte("---")
+	for i, c := range "rwx" {
+		if strings.ContainsRune(parts[2], c) || (c == 'x' && strings.ContainsRune(parts[2], 'X')) {
+			perm[i] = byte(c)
+		}
+	}
+	parts[2] = string(perm)
+	return strings.Join(parts, ":")
+}
+
+// Drift compares actual — as ReadAttributes returns it — with a as
+// requested, and names what differs: "acl", "default_acl", "xattrs",
+// "selinux", "immutable", "append_only". Only what a sets is compared; a
+// mask the kernel computed is ignored unless a names one. RestoreContext is
+// not checked, since the policy default is not known here.
+func (a Attributes) Drift(actual Attributes) []string {
+	var drift []string
+	if a.ACL != nil && !sameACL(a.ACL, actual.ACL) {
+		drift = append(drift, "acl")
+	}
+	if a.DefaultACL != nil && !sameACL(a.DefaultACL, namedEntries(actual.DefaultACL)) {
+		drift = append(drift, "default_acl")
+	}
+	for name, v := range a.Xattrs {
+		if got, ok := actual.Xattrs[name]; !ok || string(got) != string(v) {
+			drift = append(drift, "xattrs")
+			break
+		}
+	}
+	if a.SELinuxContext != "" && a.SELinuxContext != actual.SELinuxContext {
+		drift = append(drift, "selinux")
+	}
+	if a.Immutable && !actual.Immutable {
+		drift = append(drift, "immutable")
+	}
+	if a.AppendOnly && !actual.AppendOnly {
+		drift = append(drift, "append_only")
+	}
+	return drift
+}
+
+// sameACL compares requested entries with read-back ones, both canonical.
+func sameACL(want, got []string) bool {
+	w := make([]string, 0, len(want))
+	hasMask := false
+	for _, e := range want {
+		e = canonicalACLEntry(e)
+		hasMask = hasMask || strings.HasPrefix(e, "mask:")
+		w = append(w, e)
+	}
+	g := make([]string, 0, len(got))
+	for _, e := range got {
+		if !hasMask && strings.HasPrefix(e, "mask:") {
+			continue
+		}
+		g = append(g, e)
+	}
+	slices.Sort(w)
+	slices.Sort(g)
+	return slices.Equal(w, g)
+}
+
+// namedEntries drops the base entries setfacl fills into a default ACL
+// from the access ACL, leaving what a caller would have asked for.
+func namedEntries(def []string) []string {
+	var out []string
+	for _, e := range def {
+		if !isBaseEntry(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// isBaseEntry reports whether a canonical entry is the owner, owning group
+// or other entry, the ones the mode bits mirror.
+func isBaseEntry(e string) bool {
+	return strings.HasPrefix(e, "user::") || strings.HasPrefix(e, "group::") || strings.HasPrefix(e, "other::")
+}
@@ -0,0 +1,219 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+func TestWriteFile_Attributes_Escalated(t *testing.T) {
+	f, m := sudoMgr(t)
+	err := m.WriteFile(context.Background(), "/etc/app.conf", []byte("x"), WriteOptions{
+		Mode: 0o640,
+		Attributes: Attributes{
+			ACL:            []string{"u:alice:rw-", "g:staff:r"},
+			Xattrs:         map[string][]byte{"user.owner": []byte("ops"), "user.flag": nil},
+			SELinuxContext: "system_u:object_r:etc_t:s0",
+			Immutable:      true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range f.Calls() {
+		if c.Name == "sh" {
+			got = append(got, "<write>")
+			continue
+		}
+		got = append(got, argv(c))
+	}
+	want := []string{
+		"chattr -i -a -- /etc/app.conf",
+		"<write>",
+		"setfacl -b -m u:alice:rw-,g:staff:r -- /etc/app.conf",
+		"setfattr -h -n user.flag -- /etc/app.conf",
+		"setfattr -h -n user.owner -v 0x6f7073 -- /etc/app.conf",
+		"chcon -h -- system_u:object_r:etc_t:s0 /etc/app.conf",
+		"chattr +i -- /etc/app.conf",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("commands =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWriteFile_Attributes_ClearFlagsOnNewFile(t *testing.T) {
+	f, m := sudoMgr(t)
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "chattr: No such file or directory while trying to stat /etc/new"}, nil)
+	if err := m.WriteFile(context.Background(), "/etc/new", []byte("x"), WriteOptions{Attributes: Attributes{AppendOnly: true}}); err != nil {
+		t.Fatalf("a missing target must not fail the flag clear: %v", err)
+	}
+	if got := argv(f.Calls()[2]); got != "chattr +a -- /etc/new" {
+		t.Errorf("last command = %q", got)
+	}
+}
+
+func TestWriteFile_Attributes_Direct(t *testing.T) {
+	f := exectest.New(pmexec.Direct)
+	m := mustManager(t, f)
+	path := filepath.Join(t.TempDir(), "f")
+	if err := m.WriteFile(context.Background(), path, []byte("hi"), WriteOptions{Attributes: Attributes{RestoreContext: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "hi" {
+		t.Errorf("content = %q", b)
+	}
+	if names := callNames(f.Calls()); !slices.Equal(names, []string{"restorecon"}) {
+		t.Errorf("commands = %v, want only restorecon after the fd-safe write", names)
+	}
+}
+
+func TestMkdir_Attributes(t *testing.T) {
+	f, m := sudoMgr(t)
+	f.Push(pmexec.Result{}, nil) // mkdir
+	f.Push(pmexec.Result{}, nil) // setfacl -b
+	f.Push(pmexec.Result{}, nil) // setfacl -k
+	f.Push(pmexec.Result{}, fmt.Errorf("%w: command not found: restorecon", pmexec.ErrBackendUnavailable))
+	err := m.Mkdir(context.Background(), "/srv/share", MkdirOptions{
+		Attributes: Attributes{
+			ACL:            []string{},
+			DefaultACL:     []string{"group:staff:rwx"},
+			RestoreContext: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range f.Calls() {
+		got = append(got, argv(c))
+	}
+	want := []string{
+		"mkdir -- /srv/share",
+		"setfacl -b -- /srv/share",
+		"setfacl -k -d -m group:staff:rwx -- /srv/share",
+		"restorecon -F -- /srv/share",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("commands =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCopyTree_Attributes_LabelsTheTree(t *testing.T) {
+	f, m := sudoMgr(t)
+	err := m.CopyTree(context.Background(), "/etc/skel", "/home/alice", WriteOptions{
+		Attributes: Attributes{SELinuxContext: "unconfined_u:object_r:user_home_dir_t:s0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := argv(f.Calls()[1]); got != "chcon -R -h -- unconfined_u:object_r:user_home_dir_t:s0 /home/alice" {
+		t.Errorf("chcon argv = %q", got)
+	}
+}
+
+func TestAttributes_Rejected(t *testing.T) {
+	for name, a := range map[string]Attributes{
+		"bad ACL tag":         {ACL: []string{"x:alice:rw"}},
+		"bad ACL perms":       {ACL: []string{"u:alice:rwz"}},
+		"mask qualifier":      {ACL: []string{"m:alice:rw"}},
+		"comma smuggling":     {ACL: []string{"u:alice:rw,u:bob:rw"}},
+		"default on file":     {DefaultACL: []string{"u:alice:rw"}},
+		"xattr namespace":     {Xattrs: map[string][]byte{"system.posix_acl_access": nil}},
+		"selinux as xattr":    {Xattrs: map[string][]byte{"security.selinux": []byte("x")}},
+		"bad context":         {SELinuxContext: "not a context"},
+		"context and restore": {SELinuxContext: "u:r:t", RestoreContext: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, m := sudoMgr(t)
+			err := m.WriteFile(context.Background(), "/etc/x", nil, WriteOptions{Attributes: a})
+			if !errors.Is(err, ErrInvalidAttributes) {
+				t.Fatalf("err = %v, want ErrInvalidAttributes", err)
+			}
+			if n := len(f.Calls()); n != 0 {
+				t.Errorf("ran %d commands before rejecting", n)
+			}
+		})
+	}
+	_, m := sudoMgr(t)
+	if err := m.Copy(context.Background(), "/a", "/b", WriteOptions{Attributes: Attributes{DefaultACL: []string{}}}); !errors.Is(err, ErrInvalidAttributes) {
+		t.Errorf("Copy with a default ACL: err = %v", err)
+	}
+}
+
+func TestReadAttributes_Escalated(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "41ed 4096 1 root staff\n"}, nil)
+	f.Push(pmexec.Result{Stdout: "# file: /srv/share\n" +
+		"security.selinux=0x753a723a7400\n" +
+		"system.posix_acl_access=0x0200\n" +
+		"user.owner=\"ops\"\n"}, nil)
+	f.Push(pmexec.Result{Stdout: "user::rwx\nuser:alice:rw-\t#effective:r--\ngroup::r-x\nmask::r-x\nother::r-x\n" +
+		"default:user::rwx\ndefault:group:staff:rwx\ndefault:group::r-x\ndefault:mask::rwx\ndefault:other::r-x\n"}, nil)
+	f.Push(pmexec.Result{Stdout: "----ia--------e------- /srv/share\n"}, nil)
+	m := mustManager(t, f)
+
+	a, err := m.ReadAttributes(context.Background(), "/srv/share")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(a.ACL, []string{"user:alice:rw-", "mask::r-x"}) {
+		t.Errorf("ACL = %q", a.ACL)
+	}
+	if len(a.DefaultACL) != 5 || a.DefaultACL[1] != "group:staff:rwx" {
+		t.Errorf("DefaultACL = %q", a.DefaultACL)
+	}
+	if a.SELinuxContext != "u:r:t" || len(a.Xattrs) != 1 || string(a.Xattrs["user.owner"]) != "ops" {
+		t.Errorf("label = %q, xattrs = %q", a.SELinuxContext, a.Xattrs)
+	}
+	if !a.Immutable || !a.AppendOnly {
+		t.Errorf("flags = i:%v a:%v, want both", a.Immutable, a.AppendOnly)
+	}
+	calls := f.Calls()
+	if got := argv(calls[2]); got != "getfacl -c -E -p -- /srv/share" {
+		t.Errorf("getfacl argv = %q", got)
+	}
+	if got := argv(calls[3]); got != "lsattr -d -- /srv/share" {
+		t.Errorf("lsattr argv = %q", got)
+	}
+
+	want := Attributes{
+		ACL:            []string{"u:alice:rw"},
+		DefaultACL:     []string{"g:staff:rwx"},
+		Xattrs:         map[string][]byte{"user.owner": []byte("ops")},
+		SELinuxContext: "u:r:t",
+		Immutable:      true,
+	}
+	if d := want.Drift(a); len(d) != 0 {
+		t.Errorf("Drift = %v, want none", d)
+	}
+	want.ACL = []string{"u:alice:rw", "mask::rwx"}
+	want.Xattrs["user.owner"] = []byte("dev")
+	want.SELinuxContext = "u:r:other_t"
+	if d := want.Drift(a); !slices.Equal(d, []string{"acl", "xattrs", "selinux"}) {
+		t.Errorf("Drift = %v", d)
+	}
+}
+
+func TestReadAttributes_Unsupported(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "81a4 1 1 root root\n"}, nil)
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{}, fmt.Errorf("%w: command not found: getfacl", pmexec.ErrBackendUnavailable))
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "lsattr: Operation not supported While reading flags on /run/f"}, nil)
+	m := mustManager(t, f)
+	a, err := m.ReadAttributes(context.Background(), "/run/f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ACL != nil || a.Immutable || a.Xattrs != nil {
+		t.Errorf("ReadAttributes = %+v, want empty", a)
+	}
+}
@@ -0,0 +1,161 @@
+package fs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// DefaultBackupDir is where versioned backups are kept unless the Manager
+// is built WithBackupDir. Each file's versions sit in a 0700 directory
+// named by the SHA-256 of its path, one file per version.
+const DefaultBackupDir = "/var/lib/power-manage/backups"
+
+// versionLayout names a version by when it was taken; the names sort in
+// time order.
+const versionLayout = "20060102T150405.000000000Z"
+
+var versionRe = regexp.MustCompile(`^\d{8}T\d{6}\.\d{9}Z$`)
+
+// ErrBackupNotFound is returned by Restore for a version that path does not
+// have.
+var ErrBackupNotFound = errors.New("backup version not found")
+
+// BackupPolicy turns on versioned backups for a WriteFile call and bounds
+// how many are kept. The zero value keeps no versions.
+type BackupPolicy struct {
+	// Keep is the most versions kept per file, newest first. Zero means no
+	// count limit.
+	Keep int
+	// MaxAge prunes versions older than this. Zero means no age limit. The
+	// newest version is kept whatever its age.
+	MaxAge time.Duration
+}
+
+func (p BackupPolicy) enabled() bool { return p.Keep > 0 || p.MaxAge > 0 }
+
+// Backup is one kept version of a file.
+type Backup struct {
+	// Version identifies the backup to Restore.
+	Version string
+	// Taken is when the version was saved, just before the write that
+	// replaced it.
+	Taken time.Time
+	Size  int64
+}
+
+// versionDir is where path's versions live.
+func (m *manager) versionDir(path string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(path)))
+	return joinPath(m.backupDir, hex.EncodeToString(sum[:]))
+}
+
+// saveVersion copies path's current content, mode, ownership and times
+// (cp -p) into a new version. A missing path saves nothing.
+func (m *manager) saveVersion(ctx context.Context, path string) error {
+	if _, err := m.Stat(ctx, path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("back up %s: %w", path, err)
+	}
+	dir := m.versionDir(path)
+	if err := m.Mkdir(ctx, dir, MkdirOptions{Mode: 0o700, Recursive: true}); err != nil {
+		return fmt.Errorf("back up %s: %w", path, err)
+	}
+	version := m.now().UTC().Format(versionLayout)
+	if err := m.runChecked(ctx, "cp", "-p", "--", path, joinPath(dir, version)); err != nil {
+		return fmt.Errorf("back up %s: %w", path, err)
+	}
+	return nil
+}
+
+// pruneVersions removes path's versions beyond the policy.
+func (m *manager) pruneVersions(ctx context.Context, path string, p BackupPolicy) error {
+	backups, err := m.ListBackups(ctx, path)
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	for i, b := range backups {
+		overCount := p.Keep > 0 && i >= p.Keep
+		tooOld := p.MaxAge > 0 && i > 0 && now.Sub(b.Taken) > p.MaxAge
+		if !overCount && !tooOld {
+			continue
+		}
+		if err := m.Remove(ctx, joinPath(m.versionDir(path), b.Version)); err != nil {
+			return fmt.Errorf("prune backup %s of %s: %w", b.Version, path, err)
+		}
+	}
+	return nil
+}
+
+// ListBackups returns path's versions, newest first.
+func (m *manager) ListBackups(ctx context.Context, path string) ([]Backup, error) {
+	if err := ValidatePath(path); err != nil {
+		return nil, err
+	}
+	dir := m.versionDir(path)
+	entries, err := m.ReadDir(ctx, dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list backups of %s: %w", path, err)
+	}
+	var backups []Backup
+	for _, e := range entries {
+		if e.IsDir || !versionRe.MatchString(e.Name) {
+			continue
+		}
+		taken, err := time.Parse(versionLayout, e.Name)
+		if err != nil {
+			continue
+		}
+		info, err := m.Stat(ctx, joinPath(dir, e.Name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // pruned while listing
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list backups of %s: %w", path, err)
+		}
+		backups = append(backups, Backup{Version: e.Name, Taken: taken, Size: info.Size})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Version > backups[j].Version })
+	return backups, nil
+}
+
+// Restore copies version back over path (copyFile: WriteFile on the Direct
+// backend, cp as root otherwise), with the mode, owner and group the version
+// was saved with. The content it replaces is kept as a new version first, so
+// a restore can itself be undone; nothing is pruned.
+func (m *manager) Restore(ctx context.Context, path, version string) error {
+	if err := ValidatePath(path); err != nil {
+		return err
+	}
+	if !versionRe.MatchString(version) {
+		return fmt.Errorf("%w: %q", ErrBackupNotFound, version)
+	}
+	src := joinPath(m.versionDir(path), version)
+	info, err := m.Stat(ctx, src)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s of %s", ErrBackupNotFound, version, path)
+	}
+	if err != nil {
+		return fmt.Errorf("restore %s: %w", path, err)
+	}
+	if err := m.saveVersion(ctx, path); err != nil {
+		return err
+	}
+	mode := info.Mode & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	if err := m.copyFile(ctx, src, path, mode, info.Owner, info.Group); err != nil {
+		return fmt.Errorf("restore %s: %w", path, err)
+	}
+	return nil
+}
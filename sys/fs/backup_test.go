@@ -0,0 +1,193 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+// backupManager is a Manager over f keeping versions in dir, with its clock
+// fixed at now.
+func backupManager(t *testing.T, f *exectest.FakeRunner, dir string, now time.Time) *manager {
+	t.Helper()
+	m, err := New(f, WithBackupDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm := m.(*manager)
+	mm.now = func() time.Time { return now }
+	return mm
+}
+
+func TestWriteFile_Versions_Escalated(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	f := exectest.New(pmexec.Sudo)
+	m := backupManager(t, f, "/var/backups/pm", now)
+	dir := m.versionDir("/etc/app.conf")
+
+	f.Push(pmexec.Result{Stdout: "81a4 3 1 root root\n"}, nil) // stat target
+	f.Push(pmexec.Result{}, nil)                               // getfattr
+	f.Push(pmexec.Result{}, nil)                               // mkdir
+	f.Push(pmexec.Result{}, nil)                               // chmod
+	f.Push(pmexec.Result{}, nil)                               // cp -p
+	f.Push(pmexec.Result{}, nil)                               // write
+	f.Push(pmexec.Result{Stdout: "f/20261018T120000.000000000Z\nf/20261001T000000.000000000Z\nf/20261017T000000.000000000Z\nf/notes\n"}, nil)
+	for range 3 {
+		f.Push(pmexec.Result{Stdout: "8180 3 1 root root\n"}, nil)
+		f.Push(pmexec.Result{}, nil)
+	}
+	err := m.WriteFile(context.Background(), "/etc/app.conf", []byte("new"), WriteOptions{Versions: BackupPolicy{Keep: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range f.Calls() {
+		switch c.Name {
+		case "mkdir", "cp", "rm":
+			got = append(got, argv(c))
+		}
+	}
+	want := []string{
+		"mkdir -p -- " + dir,
+		"cp -p -- /etc/app.conf " + dir + "/20261018T120000.000000000Z",
+		"rm -f -- " + dir + "/20261001T000000.000000000Z",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("commands =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWriteFile_Versions_NewFileSavesNothing(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	m := backupManager(t, f, "/var/backups/pm", time.Now())
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "stat: cannot statx '/etc/new': No such file or directory"}, nil)
+	f.Push(pmexec.Result{}, nil) // write
+	// No versions directory to prune.
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "find: '" + m.versionDir("/etc/new") + "/': No such file or directory"}, nil)
+	if err := m.WriteFile(context.Background(), "/etc/new", []byte("x"), WriteOptions{Versions: BackupPolicy{MaxAge: time.Hour}}); err != nil {
+		t.Fatal(err)
+	}
+	if names := callNames(f.Calls()); !slices.Equal(names, []string{"stat", "sh", "find"}) {
+		t.Errorf("commands = %v", names)
+	}
+}
+
+func TestPruneVersions_MaxAgeKeepsNewest(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	backups := t.TempDir()
+	f := exectest.New(pmexec.Direct)
+	m := backupManager(t, f, backups, now)
+	dir := m.versionDir("/etc/app.conf")
+	for _, v := range []string{"20260101T000000.000000000Z", "20260201T000000.000000000Z"} {
+		writeTree(t, dir, map[string]string{v: v})
+	}
+	if err := m.pruneVersions(context.Background(), "/etc/app.conf", BackupPolicy{MaxAge: 24 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range f.Calls() {
+		got = append(got, argv(c))
+	}
+	if want := []string{"rm -f -- " + dir + "/20260101T000000.000000000Z"}; !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q (the newest survives MaxAge)", got, want)
+	}
+}
+
+func TestListBackupsAndRestore_Direct(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	backups, target := t.TempDir(), filepath.Join(t.TempDir(), "app.conf")
+	f := exectest.New(pmexec.Direct)
+	m := backupManager(t, f, backups, now)
+	dir := m.versionDir(target)
+	writeTree(t, dir, map[string]string{
+		"20261017T090000.000000000Z": "good\n",
+		"20261018T090000.000000000Z": "bad\n",
+		"unrelated":                  "",
+	})
+	if err := os.Chmod(filepath.Join(dir, "20261017T090000.000000000Z"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("worse\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := m.ListBackups(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Version != "20261018T090000.000000000Z" || list[1].Size != 5 ||
+		!list[1].Taken.Equal(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("ListBackups = %+v", list)
+	}
+
+	if err := m.Restore(context.Background(), target, list[1].Version); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(target)
+	info, _ := os.Stat(target)
+	if string(b) != "good\n" || info.Mode().Perm() != 0o600 {
+		t.Errorf("restored %q mode %v, want the version's content and 0600", b, info.Mode().Perm())
+	}
+	if got := argv(f.Calls()[len(f.Calls())-1]); got != "cp -p -- "+target+" "+dir+"/20261018T120000.000000000Z" {
+		t.Errorf("the replaced content was not kept: last command %q", got)
+	}
+}
+
+// Escalated, root copies the version back itself instead of reading it
+// through the Runner, which would cap and line-normalize it.
+func TestRestore_Escalated(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	f := exectest.New(pmexec.Sudo)
+	m := backupManager(t, f, "/var/backups/pm", now)
+	dir := m.versionDir("/etc/app.conf")
+	const version = "20261017T090000.000000000Z"
+
+	f.Push(pmexec.Result{Stdout: "8180 2097152 1 root adm\n"}, nil) // stat version
+	f.Push(pmexec.Result{}, nil)                                    // getfattr
+	f.Push(pmexec.Result{Stdout: "81a4 3 1 root root\n"}, nil)      // stat target
+	f.Push(pmexec.Result{}, nil)                                    // getfattr
+	f.Push(pmexec.Result{}, nil)                                    // mkdir
+	f.Push(pmexec.Result{}, nil)                                    // chmod
+	f.Push(pmexec.Result{}, nil)                                    // cp -p
+	f.Push(pmexec.Result{}, nil)                                    // copy back
+	if err := m.Restore(context.Background(), "/etc/app.conf", version); err != nil {
+		t.Fatal(err)
+	}
+	calls := f.Calls()
+	last := calls[len(calls)-1]
+	want := []string{"-c", escalatedCopyScript, "sh", dir + "/" + version, "/etc/app.conf", "0600", "root:adm"}
+	if last.Name != "sh" || !slices.Equal(last.Args, want) {
+		t.Errorf("restore = %q, want sh %q", argv(last), want)
+	}
+	for _, c := range calls {
+		if c.Name == "cat" {
+			t.Errorf("read %q back through the Runner", argv(c))
+		}
+	}
+}
+
+func TestRestore_UnknownVersion(t *testing.T) {
+	f := exectest.New(pmexec.Direct)
+	m := backupManager(t, f, t.TempDir(), time.Now())
+	for _, v := range []string{"../../etc/shadow", "20261018T090000.000000000Z"} {
+		if err := m.Restore(context.Background(), "/etc/app.conf", v); !errors.Is(err, ErrBackupNotFound) {
+			t.Errorf("Restore(%q) err = %v, want ErrBackupNotFound", v, err)
+		}
+	}
+	if n := len(f.Calls()); n != 0 {
+		t.Errorf("ran %d commands", n)
+	}
+}
+
+func TestNew_RejectsRelativeBackupDir(t *testing.T) {
+	if _, err := New(exectest.New(pmexec.Direct), WithBackupDir("backups")); err == nil {
+		t.Error("New accepted a relative backup dir")
+	}
+}
@@ -0,0 +1,252 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigFormat selects how EditConfig reads and writes a config file.
+type ConfigFormat int
+
+const (
+	// FormatINI is sectioned key=value: /etc/gdm/custom.conf,
+	// /etc/dnf/dnf.conf. "#" and ";" start a comment line.
+	FormatINI ConfigFormat = iota + 1
+	// FormatKeyValue is flat key=value with "#" comments, such as sysctl.d
+	// drop-ins. Values are written as given; for a file a shell sources, use
+	// FormatShell.
+	FormatKeyValue
+	// FormatJSON is a JSON document whose top level is an object.
+	FormatJSON
+	// FormatYAML is a single YAML document whose top level is a block
+	// mapping. Sequences, flow collections and multi-line scalars are kept
+	// as they are but can only be replaced or removed as a whole.
+	FormatYAML
+	// FormatTOML is TOML. Arrays of tables ([[x]]) are kept as they are but
+	// cannot be edited.
+	FormatTOML
+	// FormatShell is FormatKeyValue for a file a shell sources, such as
+	// /etc/default/grub. A value on a KEY=value line is shell-quoted,
+	// keeping the quotes of the value it replaces.
+	FormatShell
+)
+
+// String names the format.
+func (f ConfigFormat) String() string {
+	switch f {
+	case FormatINI:
+		return "ini"
+	case FormatKeyValue:
+		return "key=value"
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	case FormatTOML:
+		return "toml"
+	case FormatShell:
+		return "shell"
+	default:
+		return fmt.Sprintf("ConfigFormat(%d)", int(f))
+	}
+}
+
+// ErrConfigSyntax is returned when a config file does not parse as its
+// format, or uses a construct EditConfig cannot edit around. The file is
+// left untouched.
+var ErrConfigSyntax = errors.New("config file does not parse")
+
+// ErrInvalidConfigEdit is returned for an edit that cannot apply: an empty
+// or malformed path, a value of an unsupported type or with a line break
+// where the format has none, or a path that runs through a scalar.
+var ErrInvalidConfigEdit = errors.New("invalid config edit")
+
+// ConfigEdit sets or removes one key, or removes one section.
+type ConfigEdit struct {
+	// Path locates the key, one element per level: [section, key] for INI
+	// ("" is the section before the first header), [key] for key=value and
+	// shell, [table..., key] for TOML, and the nested object keys for JSON
+	// and YAML. With Remove, a shorter path removes a whole INI section,
+	// TOML table or JSON/YAML object.
+	Path []string
+	// Value is what Path is set to: a string, bool, int, int64 or float64.
+	// INI, key=value and shell write it as text; JSON, YAML and TOML encode
+	// it as its type. Missing parents are created.
+	Value any
+	// Remove deletes Path instead of setting it. Removing what is not there
+	// changes nothing.
+	Remove bool
+}
+
+// EditConfigData applies edits, in order, to data in the given format and
+// returns the result and whether it differs from data. Only the lines an
+// edit touches change: comments, ordering and formatting elsewhere are kept
+// byte for byte. Empty data is an empty file of the format.
+func EditConfigData(format ConfigFormat, data []byte, edits []ConfigEdit) ([]byte, bool, error) {
+	var edit func([]byte, ConfigEdit) ([]byte, error)
+	switch format {
+	case FormatINI:
+		edit = func(b []byte, e ConfigEdit) ([]byte, error) { return editLines(iniDialect, b, e) }
+	case FormatKeyValue:
+		edit = func(b []byte, e ConfigEdit) ([]byte, error) { return editLines(kvDialect, b, e) }
+	case FormatShell:
+		edit = func(b []byte, e ConfigEdit) ([]byte, error) { return editLines(shellDialect, b, e) }
+	case FormatTOML:
+		edit = func(b []byte, e ConfigEdit) ([]byte, error) { return editLines(tomlDialect, b, e) }
+	case FormatJSON:
+		edit = editJSON
+	case FormatYAML:
+		edit = editYAML
+	default:
+		return nil, false, fmt.Errorf("%w: unknown format %d", ErrInvalidConfigEdit, int(format))
+	}
+	out := data
+	for i, e := range edits {
+		if len(e.Path) == 0 {
+			return nil, false, fmt.Errorf("%w: edit %d: empty path", ErrInvalidConfigEdit, i)
+		}
+		var err error
+		if out, err = edit(out, e); err != nil {
+			return nil, false, fmt.Errorf("edit %s: %w", strings.Join(e.Path, "."), err)
+		}
+	}
+	return out, !bytes.Equal(out, data), nil
+}
+
+// EditConfig applies edits to the config file at path and, when anything
+// changed, writes the result back through WriteFile. A missing file is
+// created. A zero opts.Mode keeps the file's current mode, and empty
+// opts.Owner and opts.Group keep its ownership.
+func (m *manager) EditConfig(ctx context.Context, path string, format ConfigFormat, edits []ConfigEdit, opts WriteOptions) (bool, error) {
+	data, err := m.ReadFile(ctx, path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	out, changed, err := EditConfigData(format, data, edits)
+	if err != nil {
+		return false, fmt.Errorf("%s %s: %w", format, path, err)
+	}
+	if !changed {
+		return false, nil
+	}
+	if exists && (opts.Mode == 0 || (opts.Owner == "" && opts.Group == "")) {
+		mode, owner, group, err := m.fileAttrs(ctx, path)
+		if err != nil {
+			return false, err
+		}
+		if opts.Mode == 0 {
+			opts.Mode = mode
+		}
+		if opts.Owner == "" && opts.Group == "" {
+			opts.Owner, opts.Group = owner, group
+		}
+	}
+	if err := m.WriteFile(ctx, path, out, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fileAttrs returns the permission bits and owner/group names of path.
+func (m *manager) fileAttrs(ctx context.Context, path string) (os.FileMode, string, string, error) {
+	if m.direct() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0, "", "", fmt.Errorf("stat %s: %w", path, err)
+		}
+		owner, group := GetOwnership(path)
+		return info.Mode().Perm(), owner, group, nil
+	}
+	res, err := m.runPrivRead(ctx, "stat", "-c", "%a %U %G", "--", path)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("stat %s: %w", path, err)
+	}
+	if cerr := cmdError("stat", res); cerr != nil {
+		return 0, "", "", fmt.Errorf("stat %s: %w", path, cerr)
+	}
+	f := strings.Fields(res.Stdout)
+	if len(f) != 3 {
+		return 0, "", "", fmt.Errorf("stat %s: unexpected output %q", path, res.Stdout)
+	}
+	mode, err := strconv.ParseUint(f[0], 8, 32)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("stat %s: mode %q: %w", path, f[0], err)
+	}
+	// stat prints UNKNOWN for an id with no name; leave that one as it is.
+	owner, group := f[1], f[2]
+	if owner == "UNKNOWN" {
+		owner = ""
+	}
+	if group == "UNKNOWN" {
+		group = ""
+	}
+	return os.FileMode(mode).Perm(), owner, group, nil
+}
+
+// configScalar checks an edit's value and returns it as int64, float64,
+// bool or string, the four kinds the encoders handle.
+func configScalar(v any) (any, error) {
+	switch v := v.(type) {
+	case string, bool, int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("%w: %v is not a finite number", ErrInvalidConfigEdit, v)
+		}
+		return v, nil
+	case nil:
+		return nil, fmt.Errorf("%w: no value", ErrInvalidConfigEdit)
+	default:
+		return nil, fmt.Errorf("%w: unsupported value type %T", ErrInvalidConfigEdit, v)
+	}
+}
+
+// formatFloat renders f so it reads back as a float, not an integer.
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// splitLines splits data into lines that keep their "\n", so joining them
+// restores data exactly. A final line without "\n" is kept as it is.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// joinLines joins lines back into a file.
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, ""))
+}
+
+// insertLines inserts ins before lines[at]. When at is past a final line
+// without a newline, that line gets one.
+func insertLines(lines []string, at int, ins ...string) []string {
+	if at > 0 && at == len(lines) && !strings.HasSuffix(lines[at-1], "\n") {
+		lines[at-1] += "\n"
+	}
+	out := make([]string, 0, len(lines)+len(ins))
+	out = append(out, lines[:at]...)
+	out = append(out, ins...)
+	return append(out, lines[at:]...)
+}
+
+// indentOf returns the leading spaces and tabs of line.
+func indentOf(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
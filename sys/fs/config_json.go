@@ -0,0 +1,261 @@
+package fs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// jsonValue is where a value sits in the document. Objects also record
+// their members, in order.
+type jsonValue struct {
+	start, end int
+	object     bool
+	members    []jsonMember
+}
+
+type jsonMember struct {
+	key      string
+	keyStart int
+	keyEnd   int
+	value    jsonValue
+}
+
+// last returns the index of the last member named key, which is the one a
+// JSON reader keeps, or -1.
+func (v jsonValue) last(key string) int {
+	for i, m := range slices.Backward(v.members) {
+		if m.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// editJSON applies one edit to a JSON document by splicing the bytes of the
+// values it touches, so everything else keeps its formatting.
+func editJSON(data []byte, e ConfigEdit) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		if e.Remove {
+			return data, nil
+		}
+		data = []byte("{}\n")
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%w: not valid JSON", ErrConfigSyntax)
+	}
+	root, _ := parseJSONValue(data, 0)
+	if !root.object {
+		return nil, fmt.Errorf("%w: the top level is not an object", ErrConfigSyntax)
+	}
+
+	obj := root
+	for depth, key := range e.Path {
+		i := obj.last(key)
+		if depth == len(e.Path)-1 {
+			if e.Remove {
+				if i < 0 {
+					return data, nil
+				}
+				// Remove duplicates one at a time: each removal moves the
+				// members after it.
+				return editJSON(removeJSONMember(data, obj, i), e)
+			}
+			v, err := configScalar(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			if i >= 0 {
+				m := obj.members[i]
+				enc, err := encodeJSON(v, "", "")
+				if err != nil {
+					return nil, err
+				}
+				return splice(data, m.value.start, m.value.end, enc), nil
+			}
+			return insertJSONMember(data, root, obj, key, v)
+		}
+		if i < 0 {
+			if e.Remove {
+				return data, nil
+			}
+			v, err := configScalar(e.Value)
+			if err != nil {
+				return nil, err
+			}
+			// Build the missing objects down to the key as one new member.
+			var nested any = v
+			for _, k := range slices.Backward(e.Path[depth+1:]) {
+				nested = map[string]any{k: nested}
+			}
+			return insertJSONMember(data, root, obj, key, nested)
+		}
+		next := obj.members[i].value
+		if !next.object {
+			if e.Remove {
+				return data, nil
+			}
+			return nil, fmt.Errorf("%w: %s is not an object", ErrInvalidConfigEdit, strings.Join(e.Path[:depth+1], "."))
+		}
+		obj = next
+	}
+	return data, nil
+}
+
+// insertJSONMember adds key: v as obj's last member. It copies the spacing
+// of obj's first member, or for an empty object indents one level past the
+// line the object opens on.
+func insertJSONMember(data []byte, root, obj jsonValue, key string, v any) ([]byte, error) {
+	colon, unit, multiline := jsonStyle(data, root)
+	var lead string
+	if len(obj.members) > 0 {
+		lead = string(data[obj.start+1 : obj.members[0].keyStart])
+	} else if multiline {
+		lead = "\n" + lineIndent(data, obj.start) + unit
+	}
+	indent := lead[strings.LastIndexByte(lead, '\n')+1:]
+	if !multiline {
+		indent, unit = "", ""
+	}
+	k, err := encodeJSON(key, "", "")
+	if err != nil {
+		return nil, err
+	}
+	val, err := encodeJSON(v, indent, unit)
+	if err != nil {
+		return nil, err
+	}
+	member := k + colon + val
+	if len(obj.members) > 0 {
+		at := obj.members[len(obj.members)-1].value.end
+		return splice(data, at, at, ","+lead+member), nil
+	}
+	closing := ""
+	if multiline {
+		closing = "\n" + lineIndent(data, obj.start)
+	}
+	return splice(data, obj.start, obj.end, "{"+lead+member+closing+"}"), nil
+}
+
+// jsonStyle reads the document's style from its top-level object: what
+// follows a key up to its value, the indent unit, and whether members go on
+// lines of their own. An empty document is indented by two spaces.
+func jsonStyle(data []byte, root jsonValue) (colon, unit string, multiline bool) {
+	colon, unit, multiline = ": ", "  ", true
+	if len(root.members) == 0 {
+		return colon, unit, multiline
+	}
+	first := root.members[0]
+	colon = string(data[first.keyEnd:first.value.start])
+	lead := string(data[root.start+1 : first.keyStart])
+	nl := strings.LastIndexByte(lead, '\n')
+	if nl < 0 {
+		return colon, "", false
+	}
+	if u := strings.TrimPrefix(lead[nl+1:], lineIndent(data, root.start)); u != "" {
+		unit = u
+	}
+	return colon, unit, true
+}
+
+// removeJSONMember removes obj's i'th member with the comma that joined it
+// to its neighbours.
+func removeJSONMember(data []byte, obj jsonValue, i int) []byte {
+	ms := obj.members
+	switch {
+	case i+1 < len(ms):
+		return splice(data, ms[i].keyStart, ms[i+1].keyStart, "")
+	case i > 0:
+		return splice(data, ms[i-1].value.end, ms[i].value.end, "")
+	default:
+		return splice(data, obj.start+1, obj.end-1, "")
+	}
+}
+
+// encodeJSON encodes v without escaping <, > and &, indented by unit with
+// every line after the first starting with prefix.
+func encodeJSON(v any, prefix, unit string) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, unit)
+	if err := enc.Encode(v); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidConfigEdit, err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// lineIndent returns the indentation of the line holding data[at].
+func lineIndent(data []byte, at int) string {
+	start := bytes.LastIndexByte(data[:at], '\n') + 1
+	return indentOf(string(data[start:at]))
+}
+
+func splice(data []byte, from, to int, s string) []byte {
+	out := make([]byte, 0, len(data)-(to-from)+len(s))
+	out = append(out, data[:from]...)
+	out = append(out, s...)
+	return append(out, data[to:]...)
+}
+
+// parseJSONValue records the span of the value at or after data[i], which
+// must be valid JSON, and returns it with the offset just past it.
+func parseJSONValue(data []byte, i int) (jsonValue, int) {
+	i = skipJSONSpace(data, i)
+	v := jsonValue{start: i}
+	switch data[i] {
+	case '{':
+		v.object = true
+		i = skipJSONSpace(data, i+1)
+		for data[i] != '}' {
+			if data[i] == ',' {
+				i = skipJSONSpace(data, i+1)
+			}
+			m := jsonMember{keyStart: i}
+			m.keyEnd = skipJSONString(data, i)
+			_ = json.Unmarshal(data[m.keyStart:m.keyEnd], &m.key)
+			i = skipJSONSpace(data, m.keyEnd) + 1 // the colon
+			m.value, i = parseJSONValue(data, i)
+			v.members = append(v.members, m)
+			i = skipJSONSpace(data, i)
+		}
+		i++
+	case '[':
+		i = skipJSONSpace(data, i+1)
+		for data[i] != ']' {
+			if data[i] == ',' {
+				i++
+			}
+			_, i = parseJSONValue(data, i)
+			i = skipJSONSpace(data, i)
+		}
+		i++
+	case '"':
+		i = skipJSONString(data, i)
+	default:
+		for i < len(data) && !strings.ContainsRune(",}] \t\r\n", rune(data[i])) {
+			i++
+		}
+	}
+	v.end = i
+	return v, i
+}
+
+// skipJSONString returns the offset just past the string at data[i].
+func skipJSONString(data []byte, i int) int {
+	for i++; data[i] != '"'; i++ {
+		if data[i] == '\\' {
+			i++
+		}
+	}
+	return i + 1
+}
+
+func skipJSONSpace(data []byte, i int) int {
+	for i < len(data) && strings.ContainsRune(" \t\r\n", rune(data[i])) {
+		i++
+	}
+	return i
+}
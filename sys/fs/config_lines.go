@@ -0,0 +1,667 @@
+package fs
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// lineDialect is one of the line-oriented formats: INI, key=value, shell and TOML.
+type lineDialect struct {
+	name string
+	// sections reports whether "[name]" headers group the keys.
+	sections bool
+	// toml selects TOML's dotted keys and tables, typed values and
+	// multi-line strings and arrays.
+	toml bool
+	// comments are the prefixes of a comment line.
+	comments string
+	// sep separates a new key from its value when the file has no key to
+	// copy the spacing from.
+	sep string
+	// shell marks a dialect whose files a shell may source. A line written
+	// KEY=value, with no space around "=", gets its value quoted by
+	// shellValue.
+	shell bool
+}
+
+var (
+	iniDialect   = lineDialect{name: "ini", sections: true, comments: "#;", sep: "="}
+	kvDialect    = lineDialect{name: "key=value", comments: "#", sep: "="}
+	shellDialect = lineDialect{name: "shell", comments: "#", sep: "=", shell: true}
+	tomlDialect  = lineDialect{name: "toml", sections: true, toml: true, comments: "#", sep: " = "}
+)
+
+type lineKind int
+
+const (
+	lineOther  lineKind = iota // blank or comment
+	lineHeader                 // [section] or [[array]]
+	lineKey                    // key = value
+)
+
+// cfgLine is a parsed line. A TOML value may span lines; its key line
+// records where it ends and the lines in between are not entries.
+type cfgLine struct {
+	kind lineKind
+	// table is the header's own path, or the table a key is in.
+	table []string
+	// array marks a [[header]], and any key under one.
+	array bool
+	// key is the key as written: one element, or TOML's dotted segments.
+	key []string
+	// prefix is the line up to the value, suffix what follows the value on
+	// its last line (a TOML comment and the newline).
+	prefix, suffix string
+	// end is the index of the value's last line.
+	end int
+}
+
+// path is a key's full path: its table, then its key.
+func (l cfgLine) path() []string {
+	return append(slices.Clone(l.table), l.key...)
+}
+
+// parseLines parses data into lines and their entries. Index i of the
+// entries describes lines[i]; the lines inside a multi-line value are
+// lineOther.
+func parseLines(d lineDialect, data []byte) ([]string, []cfgLine, error) {
+	lines := splitLines(data)
+	entries := make([]cfgLine, len(lines))
+	var table []string
+	array := false
+	for i := 0; i < len(lines); i++ {
+		t := strings.TrimSpace(lines[i])
+		switch {
+		case t == "" || strings.ContainsRune(d.comments, rune(t[0])):
+			continue
+		case t[0] == '[':
+			if !d.sections {
+				return nil, nil, fmt.Errorf("%w: line %d: %s has no sections", ErrConfigSyntax, i+1, d.name)
+			}
+			name, isArray, err := parseHeader(d, t)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: line %d: %v", ErrConfigSyntax, i+1, err)
+			}
+			table, array = name, isArray
+			entries[i] = cfgLine{kind: lineHeader, table: name, array: isArray}
+		default:
+			e, err := parseKeyLine(d, lines, i)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: line %d: %v", ErrConfigSyntax, i+1, err)
+			}
+			e.table, e.array = table, array
+			entries[i] = e
+			i = e.end
+		}
+	}
+	return lines, entries, nil
+}
+
+// parseHeader parses a trimmed "[name]" line.
+func parseHeader(d lineDialect, t string) ([]string, bool, error) {
+	if !d.toml {
+		if !strings.HasSuffix(t, "]") || len(t) < 3 {
+			return nil, false, fmt.Errorf("malformed section header %q", t)
+		}
+		return []string{strings.TrimSpace(t[1 : len(t)-1])}, false, nil
+	}
+	array := strings.HasPrefix(t, "[[")
+	open, closing := "[", "]"
+	if array {
+		open, closing = "[[", "]]"
+	}
+	segs, rest, err := parseTOMLKey(t[len(open):], closing[0])
+	if err != nil {
+		return nil, false, err
+	}
+	if !strings.HasPrefix(rest, closing) {
+		return nil, false, fmt.Errorf("malformed table header %q", t)
+	}
+	if tail := strings.TrimSpace(rest[len(closing):]); tail != "" && tail[0] != '#' {
+		return nil, false, fmt.Errorf("text after table header %q", t)
+	}
+	return segs, array, nil
+}
+
+// parseKeyLine parses the key line lines[i] and, for TOML, finds the end of
+// its value.
+func parseKeyLine(d lineDialect, lines []string, i int) (cfgLine, error) {
+	line := lines[i]
+	body := strings.TrimRight(line, "\r\n")
+	nl := line[len(body):]
+	if !d.toml {
+		k, _, found := strings.Cut(body, "=")
+		key := strings.TrimSpace(k)
+		if !found && d.sections && key != "" {
+			// An INI flag with no value, like my.cnf's skip-name-resolve.
+			// Setting it gives it one.
+			return cfgLine{kind: lineKey, key: []string{key}, prefix: body + d.sep, suffix: nl, end: i}, nil
+		}
+		if !found || key == "" {
+			return cfgLine{}, fmt.Errorf("expected key=value, got %q", strings.TrimSpace(body))
+		}
+		p := len(k) + 1
+		for p < len(body) && (body[p] == ' ' || body[p] == '\t') {
+			p++
+		}
+		return cfgLine{kind: lineKey, key: []string{key}, prefix: body[:p], suffix: nl, end: i}, nil
+	}
+
+	lead := indentOf(body)
+	segs, rest, err := parseTOMLKey(body[len(lead):], '=')
+	if err != nil {
+		return cfgLine{}, err
+	}
+	if !strings.HasPrefix(rest, "=") {
+		return cfgLine{}, fmt.Errorf("expected key = value, got %q", strings.TrimSpace(body))
+	}
+	p := len(body) - len(rest) + 1
+	for p < len(body) && (body[p] == ' ' || body[p] == '\t') {
+		p++
+	}
+	endLine, endOff, err := scanTOMLValue(lines, i, p)
+	if err != nil {
+		return cfgLine{}, err
+	}
+	last := lines[endLine]
+	suffix := last[endOff:]
+	if tail := strings.TrimSpace(suffix); tail != "" && tail[0] != '#' {
+		return cfgLine{}, fmt.Errorf("text after value: %q", tail)
+	}
+	return cfgLine{kind: lineKey, key: segs, prefix: body[:p], suffix: suffix, end: endLine}, nil
+}
+
+// parseTOMLKey parses a dotted TOML key from s up to the stop byte, and
+// returns its segments and the rest of s from the stop byte on.
+func parseTOMLKey(s string, stop byte) ([]string, string, error) {
+	var segs []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return nil, "", fmt.Errorf("missing key")
+		}
+		var seg string
+		switch s[0] {
+		case '"':
+			end := scanBasicString(s, 1)
+			if end < 0 {
+				return nil, "", fmt.Errorf("unterminated quoted key")
+			}
+			v, err := strconv.Unquote(s[:end])
+			if err != nil {
+				return nil, "", fmt.Errorf("quoted key %s: %v", s[:end], err)
+			}
+			seg, s = v, s[end:]
+		case '\'':
+			end := strings.IndexByte(s[1:], '\'')
+			if end < 0 {
+				return nil, "", fmt.Errorf("unterminated quoted key")
+			}
+			seg, s = s[1:end+1], s[end+2:]
+		default:
+			n := 0
+			for n < len(s) && isBareKeyByte(s[n]) {
+				n++
+			}
+			if n == 0 {
+				return nil, "", fmt.Errorf("invalid key at %q", s)
+			}
+			seg, s = s[:n], s[n:]
+		}
+		segs = append(segs, seg)
+		s = strings.TrimLeft(s, " \t")
+		if s == "" || s[0] == stop {
+			return segs, s, nil
+		}
+		if s[0] != '.' {
+			return nil, "", fmt.Errorf("invalid key at %q", s)
+		}
+		s = s[1:]
+	}
+}
+
+func isBareKeyByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// scanBasicString returns the index just past the closing quote of the
+// basic string whose content starts at s[from], or -1.
+func scanBasicString(s string, from int) int {
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// scanTOMLValue finds the end of the value starting at lines[i][p]: the
+// line it ends on and the offset just past it.
+func scanTOMLValue(lines []string, i, p int) (int, int, error) {
+	s := lines[i]
+	if p >= len(strings.TrimRight(s, "\r\n")) {
+		return 0, 0, fmt.Errorf("missing value")
+	}
+	switch {
+	case strings.HasPrefix(s[p:], `"""`), strings.HasPrefix(s[p:], `'''`):
+		delim := s[p : p+3]
+		for l, off := i, p+3; l < len(lines); l, off = l+1, 0 {
+			line := lines[l]
+			for j := off; j+3 <= len(line); j++ {
+				if delim == `"""` && line[j] == '\\' {
+					j++
+					continue
+				}
+				if line[j:j+3] == delim {
+					// Up to two more quotes belong to the string.
+					end := j + 3
+					for n := 0; n < 2 && end < len(line) && line[end] == delim[0]; n++ {
+						end++
+					}
+					return l, end, nil
+				}
+			}
+		}
+		return 0, 0, fmt.Errorf("unterminated multi-line string")
+	case s[p] == '"':
+		end := scanBasicString(s, p+1)
+		if end < 0 {
+			return 0, 0, fmt.Errorf("unterminated string")
+		}
+		return i, end, nil
+	case s[p] == '\'':
+		end := strings.IndexByte(s[p+1:], '\'')
+		if end < 0 {
+			return 0, 0, fmt.Errorf("unterminated string")
+		}
+		return i, p + end + 2, nil
+	case s[p] == '[' || s[p] == '{':
+		depth := 0
+		for l, off := i, p; l < len(lines); l, off = l+1, 0 {
+			line := lines[l]
+			for j := off; j < len(line); j++ {
+				switch c := line[j]; c {
+				case '[', '{':
+					depth++
+				case ']', '}':
+					depth--
+					if depth == 0 {
+						return l, j + 1, nil
+					}
+				case '#':
+					j = len(line)
+				case '"', '\'':
+					el, ej, err := scanTOMLValue(lines, l, j)
+					if err != nil {
+						return 0, 0, err
+					}
+					l, line, j = el, lines[el], ej-1
+				}
+			}
+		}
+		return 0, 0, fmt.Errorf("unterminated array or inline table")
+	default:
+		end := len(strings.TrimRight(s, "\r\n"))
+		if h := strings.IndexByte(s[p:end], '#'); h >= 0 {
+			end = p + h
+		}
+		end = p + len(strings.TrimRight(s[p:end], " \t"))
+		return i, end, nil
+	}
+}
+
+// editLines applies one edit to an INI, key=value, shell or TOML file.
+func editLines(d lineDialect, data []byte, e ConfigEdit) ([]byte, error) {
+	if err := checkLinePath(d, e); err != nil {
+		return nil, err
+	}
+	lines, entries, err := parseLines(d, data)
+	if err != nil {
+		return nil, err
+	}
+	if e.Remove {
+		return joinLines(removeLines(d, lines, entries, e.Path)), nil
+	}
+	v, err := configScalar(e.Value)
+	if err != nil {
+		return nil, err
+	}
+	val, err := encodeLineValue(d, v)
+	if err != nil {
+		return nil, err
+	}
+	return joinLines(setLine(d, lines, entries, e.Path, val)), nil
+}
+
+// checkLinePath checks an edit's path against the dialect's shape.
+func checkLinePath(d lineDialect, e ConfigEdit) error {
+	p := e.Path
+	switch {
+	case d.toml:
+		if slices.Contains(p, "") {
+			return fmt.Errorf("%w: empty TOML key", ErrInvalidConfigEdit)
+		}
+		return nil
+	case d.sections:
+		if len(p) > 2 || (len(p) == 1 && !e.Remove) {
+			return fmt.Errorf("%w: an INI path is [section, key]", ErrInvalidConfigEdit)
+		}
+		if strings.ContainsAny(p[0], "]\r\n") || p[0] != strings.TrimSpace(p[0]) {
+			return fmt.Errorf("%w: invalid section name %q", ErrInvalidConfigEdit, p[0])
+		}
+	default:
+		if len(p) != 1 {
+			return fmt.Errorf("%w: a %s path is [key]", ErrInvalidConfigEdit, d.name)
+		}
+	}
+	key := p[len(p)-1]
+	if len(p) == 1 && d.sections {
+		return nil
+	}
+	if key == "" || key != strings.TrimSpace(key) || strings.ContainsAny(key, "=\r\n") ||
+		strings.ContainsRune(d.comments+"[", rune(key[0])) {
+		return fmt.Errorf("%w: invalid key %q", ErrInvalidConfigEdit, key)
+	}
+	return nil
+}
+
+// encodeLineValue renders a value for the dialect: plain text for INI and
+// key=value, a typed TOML value for TOML.
+func encodeLineValue(d lineDialect, v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		if d.toml {
+			return tomlString(v), nil
+		}
+		if strings.ContainsAny(v, "\r\n") {
+			return "", fmt.Errorf("%w: %s values cannot span lines", ErrInvalidConfigEdit, d.name)
+		}
+		return v, nil
+	case float64:
+		return formatFloat(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlKey renders key segments, quoting any that are not bare.
+func tomlKey(segs []string) string {
+	out := make([]string, len(segs))
+	for i, s := range segs {
+		out[i] = s
+		for j := 0; j < len(s); j++ {
+			if !isBareKeyByte(s[j]) {
+				out[i] = tomlString(s)
+				break
+			}
+		}
+	}
+	return strings.Join(out, ".")
+}
+
+// keyPath is an edit path as the dialect matches it against a key line.
+func keyPath(d lineDialect, path []string) []string {
+	if !d.sections {
+		return []string{"", path[0]}
+	}
+	return path
+}
+
+// linePath is a key line's path in the same form: INI's section then key,
+// key=value's "" then key, TOML's table then dotted key.
+func linePath(d lineDialect, l cfgLine) []string {
+	if !d.sections {
+		return []string{"", l.key[0]}
+	}
+	if !d.toml {
+		t := ""
+		if len(l.table) > 0 {
+			t = l.table[0]
+		}
+		return []string{t, l.key[0]}
+	}
+	return l.path()
+}
+
+// setLine sets path to the encoded val: in place when the key exists,
+// dropping any later duplicate, or in its section when it does not.
+func setLine(d lineDialect, lines []string, entries []cfgLine, path []string, val string) []string {
+	want := keyPath(d, path)
+	var matches []int
+	for i, l := range entries {
+		if l.kind == lineKey && !l.array && slices.Equal(linePath(d, l), want) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) > 0 {
+		for _, i := range slices.Backward(matches[1:]) {
+			lines = slices.Delete(lines, i, entries[i].end+1)
+		}
+		first := entries[matches[0]]
+		if d.shell && isShellAssignment(first.prefix) {
+			old := strings.TrimRight(lines[matches[0]], "\r\n")[len(first.prefix):]
+			val = shellValue(val, old)
+		}
+		newLine := first.prefix + val + first.suffix
+		if first.end == len(lines)-1 && !strings.HasSuffix(newLine, "\n") && strings.HasSuffix(lines[first.end], "\n") {
+			newLine += "\n"
+		}
+		return slices.Replace(lines, matches[0], first.end+1, newLine)
+	}
+
+	table, key := want[:len(want)-1], want[len(want)-1]
+	if d.toml {
+		table, key = path[:len(path)-1], path[len(path)-1]
+	}
+	sep := d.sep
+	for _, l := range entries {
+		if l.kind == lineKey {
+			keyText := strings.TrimRight(strings.TrimLeft(l.prefix, " \t"), " \t=")
+			sep = strings.TrimPrefix(strings.TrimLeft(l.prefix, " \t"), keyText)
+			break
+		}
+	}
+	render := func(k []string) string {
+		if d.toml {
+			return tomlKey(k) + sep + val + "\n"
+		}
+		if d.shell && sep == "=" {
+			return k[0] + sep + shellValue(val, "") + "\n"
+		}
+		return k[0] + sep + val + "\n"
+	}
+
+	// The table or section is there: add the key after its last key.
+	if at, ok := sectionEnd(d, lines, entries, table); ok {
+		return insertLines(lines, at, render([]string{key}))
+	}
+	// TOML: a table defined only through dotted keys takes the key as one
+	// more dotted key beside them; a [header] for it would redefine it.
+	if d.toml {
+		for i := len(entries) - 1; i >= 0; i-- {
+			l := entries[i]
+			if l.kind == lineKey && !l.array && len(l.table) < len(table) &&
+				slices.Equal(l.table, table[:len(l.table)]) && hasPrefix(l.path(), table) {
+				return insertLines(lines, l.end+1, render(append(slices.Clone(table[len(l.table):]), key)))
+			}
+		}
+	}
+	var header string
+	if d.toml {
+		header = "[" + tomlKey(table) + "]\n"
+	} else {
+		header = "[" + table[0] + "]\n"
+	}
+	var add []string
+	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+		add = append(add, "\n")
+	}
+	return insertLines(lines, len(lines), append(add, header, render([]string{key}))...)
+}
+
+// isShellAssignment reports whether a key line's prefix reads like a shell
+// variable assignment, KEY=, rather than sysctl's "key = ".
+func isShellAssignment(prefix string) bool {
+	k, ok := strings.CutSuffix(prefix, "=")
+	return ok && k != "" && !strings.ContainsAny(k[len(k)-1:], " \t")
+}
+
+// shellEscaper escapes what stays special inside double quotes.
+var shellEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// shellValue renders val for a file a shell may source, in the quotes of
+// old, the value it replaces. An unquoted val with whitespace or a shell
+// metacharacter gets double quotes, so setting GRUB_CMDLINE_LINUX to
+// "quiet splash" never leaves a bare splash for the shell to run.
+func shellValue(val, old string) string {
+	switch {
+	case strings.HasPrefix(old, "'"):
+		return "'" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+	case strings.HasPrefix(old, `"`), strings.IndexFunc(val, needsShellQuote) >= 0:
+		return `"` + shellEscaper.Replace(val) + `"`
+	}
+	return val
+}
+
+// needsShellQuote reports whether r cannot appear in an unquoted shell word.
+func needsShellQuote(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		strings.ContainsRune("_@%+=:,./-", r))
+}
+
+// sectionEnd returns where a new key of the table goes: after the table's
+// last key, or right after its header. The root table (no name, or INI's
+// "") always exists; its keys go before the first header.
+func sectionEnd(d lineDialect, lines []string, entries []cfgLine, table []string) (int, bool) {
+	root := len(table) == 0 || (!d.toml && table[0] == "")
+	start := -1
+	if root {
+		start = 0
+	} else {
+		for i, l := range entries {
+			if l.kind == lineHeader && !l.array && slices.Equal(l.table, table) {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return 0, false
+		}
+	}
+	at := start
+	for i := start; i < len(entries); i++ {
+		l := entries[i]
+		if l.kind == lineHeader {
+			break
+		}
+		if l.kind == lineKey {
+			at = l.end + 1
+		}
+	}
+	if root && at == 0 {
+		// No root keys yet: go before the first header, above the blank
+		// lines that separate it.
+		at = len(entries)
+		for i, l := range entries {
+			if l.kind == lineHeader {
+				at = i
+				break
+			}
+		}
+		if at < len(entries) {
+			for at > 0 && strings.TrimSpace(lines[at-1]) == "" {
+				at--
+			}
+		}
+	}
+	return at, true
+}
+
+// removeLines removes the key at path, or a whole section or table: INI
+// [section], or for TOML every table, sub-table and dotted key under path.
+func removeLines(d lineDialect, lines []string, entries []cfgLine, path []string) []string {
+	section := d.sections && (len(path) == 1 || d.toml)
+	want := keyPath(d, path)
+	type span struct{ from, to int }
+	var drop []span
+	for i := 0; i < len(entries); i++ {
+		l := entries[i]
+		switch {
+		case l.kind == lineKey && l.array:
+			// Keys of an array of tables are never edited.
+		case l.kind == lineKey && slices.Equal(linePath(d, l), want):
+			drop = append(drop, span{i, l.end})
+		case l.kind == lineKey && d.toml && hasPrefix(l.path(), path) && !slices.Equal(l.table, path) && !hasPrefix(l.table, path):
+			// A dotted key under path, written from a parent table.
+			drop = append(drop, span{i, l.end})
+		case l.kind == lineHeader && section && (d.toml && hasPrefix(l.table, path) || !d.toml && l.table[0] == path[0]):
+			end := i
+			for j := i + 1; j < len(entries) && entries[j].kind != lineHeader; j++ {
+				if entries[j].kind == lineKey {
+					end = entries[j].end
+				}
+			}
+			drop = append(drop, span{i, end})
+			i = end
+		}
+	}
+	if !d.toml && d.sections && len(path) == 1 && path[0] == "" {
+		// The root section has no header; removing it drops its keys.
+		drop = drop[:0]
+		for i, l := range entries {
+			if l.kind == lineHeader {
+				break
+			}
+			if l.kind == lineKey {
+				drop = append(drop, span{i, l.end})
+			}
+		}
+	}
+	for _, s := range slices.Backward(drop) {
+		lines = slices.Delete(lines, s.from, s.to+1)
+		// Keep one blank line where a blank line now meets another or
+		// the end of the file.
+		if s.from > 0 && strings.TrimSpace(lines[s.from-1]) == "" &&
+			(s.from == len(lines) || strings.TrimSpace(lines[s.from]) == "") {
+			lines = slices.Delete(lines, s.from-1, s.from)
+		}
+	}
+	return lines
+}
+
+// hasPrefix reports whether p starts with prefix.
+func hasPrefix(p, prefix []string) bool {
+	return len(p) >= len(prefix) && slices.Equal(p[:len(prefix)], prefix)
+}
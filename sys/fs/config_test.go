@@ -0,0 +1,353 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+func cfgSet(v any, path ...string) ConfigEdit { return ConfigEdit{Path: path, Value: v} }
+func cfgUnset(path ...string) ConfigEdit      { return ConfigEdit{Path: path, Remove: true} }
+
+type configCase struct {
+	name      string
+	in        string
+	edits     []ConfigEdit
+	want      string
+	unchanged bool
+}
+
+func runConfigCases(t *testing.T, format ConfigFormat, cases []configCase) {
+	t.Helper()
+	for _, tt := range cases {
+		got, changed, err := EditConfigData(format, []byte(tt.in), tt.edits)
+		if err != nil {
+			t.Errorf("%s %s: %v", format, tt.name, err)
+			continue
+		}
+		want := tt.want
+		if tt.unchanged {
+			want = tt.in
+		}
+		if string(got) != want {
+			t.Errorf("%s %s:\ngot:\n%s\nwant:\n%s", format, tt.name, got, want)
+		}
+		if changed == tt.unchanged {
+			t.Errorf("%s %s: changed = %v, want %v", format, tt.name, changed, !tt.unchanged)
+		}
+	}
+}
+
+func TestEditConfigData_INI(t *testing.T) {
+	const gdm = "# GDM configuration\n[daemon]\n# Uncomment to force Xorg\n#WaylandEnable=false\nAutomaticLoginEnable = true\n\n[security]\n\n[debug]\nEnable=false\n"
+	runConfigCases(t, FormatINI, []configCase{
+		{name: "replace in place keeps spacing and comments",
+			in: gdm, edits: []ConfigEdit{cfgSet(false, "daemon", "AutomaticLoginEnable")},
+			want: strings.Replace(gdm, "AutomaticLoginEnable = true", "AutomaticLoginEnable = false", 1)},
+		{name: "add after the section's last key",
+			in: gdm, edits: []ConfigEdit{cfgSet("false", "daemon", "WaylandEnable")},
+			want: strings.Replace(gdm, "AutomaticLoginEnable = true\n", "AutomaticLoginEnable = true\nWaylandEnable = false\n", 1)},
+		{name: "add to an empty section",
+			in: gdm, edits: []ConfigEdit{cfgSet(1, "security", "DisallowTCP")},
+			want: strings.Replace(gdm, "[security]\n", "[security]\nDisallowTCP = 1\n", 1)},
+		{name: "add a new section at the end",
+			in: "[a]\nx=1", edits: []ConfigEdit{cfgSet("y", "b", "k")},
+			want: "[a]\nx=1\n\n[b]\nk=y\n"},
+		{name: "root key goes before the first header",
+			in: "# top\n\n[main]\ngpgcheck=1\n", edits: []ConfigEdit{cfgSet("v", "", "k")},
+			want: "# top\nk=v\n\n[main]\ngpgcheck=1\n"},
+		{name: "a duplicate key collapses to one",
+			in: "[s]\nk=1\nj=2\nk=3\n", edits: []ConfigEdit{cfgSet("4", "s", "k")},
+			want: "[s]\nk=4\nj=2\n"},
+		{name: "a value-less flag gets a value",
+			in: "[mysqld]\nskip-name-resolve\n", edits: []ConfigEdit{cfgSet("1", "mysqld", "skip-name-resolve")},
+			want: "[mysqld]\nskip-name-resolve=1\n"},
+		{name: "remove a key",
+			in: gdm, edits: []ConfigEdit{cfgUnset("debug", "Enable")},
+			want: strings.Replace(gdm, "[debug]\nEnable=false\n", "[debug]\n", 1)},
+		{name: "remove a section",
+			in: gdm, edits: []ConfigEdit{cfgUnset("security")},
+			want: strings.Replace(gdm, "[security]\n\n", "", 1)},
+		{name: "setting the current value changes nothing",
+			in: gdm, edits: []ConfigEdit{cfgSet("true", "daemon", "AutomaticLoginEnable")}, unchanged: true},
+		{name: "removing what is not there changes nothing",
+			in: gdm, edits: []ConfigEdit{cfgUnset("daemon", "Missing"), cfgUnset("nosuch")}, unchanged: true},
+	})
+}
+
+func TestEditConfigData_KeyValue(t *testing.T) {
+	const conf = "# sysctl\nvm.swappiness=10\nkernel.core_pattern=core\n"
+	runConfigCases(t, FormatKeyValue, []configCase{
+		{name: "replace",
+			in: conf, edits: []ConfigEdit{cfgSet(30, "vm.swappiness")},
+			want: strings.Replace(conf, "=10", "=30", 1)},
+		{name: "values are written as given",
+			in: conf, edits: []ConfigEdit{cfgSet("|/usr/lib/systemd/systemd-coredump %P", "kernel.core_pattern")},
+			want: strings.Replace(conf, "=core", "=|/usr/lib/systemd/systemd-coredump %P", 1)},
+		{name: "append",
+			in: conf, edits: []ConfigEdit{cfgSet(1, "net.ipv4.ip_forward")},
+			want: conf + "net.ipv4.ip_forward=1\n"},
+		{name: "copies the spacing of existing keys",
+			in: "vm.swappiness = 10\n", edits: []ConfigEdit{cfgSet(1, "net.ipv4.ip_forward")},
+			want: "vm.swappiness = 10\nnet.ipv4.ip_forward = 1\n"},
+		{name: "into an empty file",
+			in: "", edits: []ConfigEdit{cfgSet(1, "a")}, want: "a=1\n"},
+		{name: "remove",
+			in: conf, edits: []ConfigEdit{cfgUnset("vm.swappiness")},
+			want: strings.Replace(conf, "vm.swappiness=10\n", "", 1)},
+		{name: "no-op",
+			in: conf, edits: []ConfigEdit{cfgSet(10, "vm.swappiness")}, unchanged: true},
+	})
+}
+
+func TestEditConfigData_Shell(t *testing.T) {
+	const grub = "# If you change this file, run 'update-grub'\nGRUB_DEFAULT=0\nGRUB_TIMEOUT=5\nGRUB_CMDLINE_LINUX_DEFAULT=\"quiet splash\"\n"
+	runConfigCases(t, FormatShell, []configCase{
+		{name: "replace keeps the quotes",
+			in: grub, edits: []ConfigEdit{cfgSet("quiet", "GRUB_CMDLINE_LINUX_DEFAULT")},
+			want: strings.Replace(grub, `"quiet splash"`, `"quiet"`, 1)},
+		{name: "append",
+			in: grub, edits: []ConfigEdit{cfgSet("true", "GRUB_DISABLE_OS_PROBER")},
+			want: grub + "GRUB_DISABLE_OS_PROBER=true\n"},
+		{name: "remove",
+			in: grub, edits: []ConfigEdit{cfgUnset("GRUB_TIMEOUT")},
+			want: strings.Replace(grub, "GRUB_TIMEOUT=5\n", "", 1)},
+		{name: "no-op",
+			in: grub, edits: []ConfigEdit{cfgSet(5, "GRUB_TIMEOUT")}, unchanged: true},
+		{name: "no-op on a quoted value",
+			in: grub, edits: []ConfigEdit{cfgSet("quiet splash", "GRUB_CMDLINE_LINUX_DEFAULT")}, unchanged: true},
+		{name: "whitespace gets quoted",
+			in: "GRUB_CMDLINE_LINUX=quiet\n", edits: []ConfigEdit{cfgSet("quiet splash", "GRUB_CMDLINE_LINUX")},
+			want: "GRUB_CMDLINE_LINUX=\"quiet splash\"\n"},
+		{name: "metacharacters are escaped in double quotes",
+			in: "GRUB_CMDLINE_LINUX=\"quiet\"\n", edits: []ConfigEdit{cfgSet("a=\"$(reboot)\" `id` \\", "GRUB_CMDLINE_LINUX")},
+			want: "GRUB_CMDLINE_LINUX=\"a=\\\"\\$(reboot)\\\" \\`id\\` \\\\\"\n"},
+		{name: "single quotes stay single",
+			in: "GRUB_DISTRIBUTOR='Debian'\n", edits: []ConfigEdit{cfgSet("Power Manage's", "GRUB_DISTRIBUTOR")},
+			want: "GRUB_DISTRIBUTOR='Power Manage'\\''s'\n"},
+		{name: "a new key with whitespace is quoted",
+			in: grub, edits: []ConfigEdit{cfgSet("console serial", "GRUB_TERMINAL")},
+			want: grub + "GRUB_TERMINAL=\"console serial\"\n"},
+		{name: "a new key in an empty file is quoted",
+			in: "", edits: []ConfigEdit{cfgSet("a b", "X")}, want: "X=\"a b\"\n"},
+	})
+}
+
+func TestEditConfigData_TOML(t *testing.T) {
+	const conf = `# containerd
+version = 2 # schema
+
+[plugins."io.containerd.grpc.v1.cri"]
+  sandbox_image = "pause:3.8"
+  packages = [
+    "a", # first
+    "b",
+  ]
+
+[[mirrors]]
+name = "x"
+
+[server]
+port = 80
+`
+	runConfigCases(t, FormatTOML, []configCase{
+		{name: "replace keeps the inline comment",
+			in: conf, edits: []ConfigEdit{cfgSet(3, "version")},
+			want: strings.Replace(conf, "version = 2 #", "version = 3 #", 1)},
+		{name: "replace under a quoted table",
+			in: conf, edits: []ConfigEdit{cfgSet("pause:3.9", "plugins", "io.containerd.grpc.v1.cri", "sandbox_image")},
+			want: strings.Replace(conf, "pause:3.8", "pause:3.9", 1)},
+		{name: "replace a multi-line array as a whole",
+			in: conf, edits: []ConfigEdit{cfgSet("c", "plugins", "io.containerd.grpc.v1.cri", "packages")},
+			want: strings.Replace(conf, "packages = [\n    \"a\", # first\n    \"b\",\n  ]", `packages = "c"`, 1)},
+		{name: "add to a table",
+			in: conf, edits: []ConfigEdit{cfgSet(true, "server", "tls")},
+			want: conf + "tls = true\n"},
+		{name: "add a new table, quoting what is not a bare key",
+			in: conf, edits: []ConfigEdit{cfgSet(1.5, "a b", "ratio")},
+			want: conf + "\n[\"a b\"]\nratio = 1.5\n"},
+		{name: "a table made of dotted keys gets another dotted key",
+			in: "owner.name = \"x\"\n", edits: []ConfigEdit{cfgSet("y", "owner", "email")},
+			want: "owner.name = \"x\"\nowner.email = \"y\"\n"},
+		{name: "an array of tables is never matched",
+			in: conf, edits: []ConfigEdit{cfgUnset("mirrors", "name")}, unchanged: true},
+		{name: "remove a table",
+			in: conf, edits: []ConfigEdit{cfgUnset("server")},
+			want: strings.TrimSuffix(conf, "\n[server]\nport = 80\n")},
+		{name: "string escaping",
+			in: "", edits: []ConfigEdit{cfgSet("a\"b\n", "k")}, want: "k = \"a\\\"b\\n\"\n"},
+		{name: "no-op",
+			in: conf, edits: []ConfigEdit{cfgSet(80, "server", "port")}, unchanged: true},
+	})
+}
+
+func TestEditConfigData_JSON(t *testing.T) {
+	const daemon = "{\n    \"log-driver\": \"json-file\",\n    \"log-opts\": {\n        \"max-size\": \"10m\"\n    }\n}\n"
+	runConfigCases(t, FormatJSON, []configCase{
+		{name: "replace",
+			in: daemon, edits: []ConfigEdit{cfgSet("local", "log-driver")},
+			want: strings.Replace(daemon, `"json-file"`, `"local"`, 1)},
+		{name: "add to a nested object with its indentation",
+			in: daemon, edits: []ConfigEdit{cfgSet("3", "log-opts", "max-file")},
+			want: strings.Replace(daemon, "\"10m\"\n", "\"10m\",\n        \"max-file\": \"3\"\n", 1)},
+		{name: "create missing parents",
+			in: daemon, edits: []ConfigEdit{cfgSet(true, "features", "buildkit")},
+			want: strings.Replace(daemon, "    }\n}", "    },\n    \"features\": {\n        \"buildkit\": true\n    }\n}", 1)},
+		{name: "remove the last member",
+			in: daemon, edits: []ConfigEdit{cfgUnset("log-opts")},
+			want: "{\n    \"log-driver\": \"json-file\"\n}\n"},
+		{name: "remove the first member",
+			in: daemon, edits: []ConfigEdit{cfgUnset("log-driver")},
+			want: "{\n    \"log-opts\": {\n        \"max-size\": \"10m\"\n    }\n}\n"},
+		{name: "remove duplicates",
+			in: `{"a":1,"a":2,"b":3}`, edits: []ConfigEdit{cfgUnset("a")}, want: `{"b":3}`},
+		{name: "compact documents stay compact",
+			in: `{"a":1}`, edits: []ConfigEdit{cfgSet(2.5, "b")}, want: `{"a":1,"b":2.5}`},
+		{name: "into an empty file",
+			in: "", edits: []ConfigEdit{cfgSet("<x>", "a")}, want: "{\n  \"a\": \"<x>\"\n}\n"},
+		{name: "no-op",
+			in: daemon, edits: []ConfigEdit{cfgSet("10m", "log-opts", "max-size")}, unchanged: true},
+	})
+}
+
+func TestEditConfigData_YAML(t *testing.T) {
+	const netplan = `---
+# managed
+network:
+  version: 2 # keep
+  ethernets:
+    eth0:
+      dhcp4: true
+      addresses:
+      - 10.0.0.5/24
+
+# trailing comment
+`
+	runConfigCases(t, FormatYAML, []configCase{
+		{name: "replace keeps the comment",
+			in: netplan, edits: []ConfigEdit{cfgSet(3, "network", "version")},
+			want: strings.Replace(netplan, "version: 2 #", "version: 3 #", 1)},
+		{name: "add under a nested mapping",
+			in: netplan, edits: []ConfigEdit{cfgSet("systemd-networkd", "network", "renderer")},
+			want: strings.Replace(netplan, "10.0.0.5/24\n", "10.0.0.5/24\n  renderer: systemd-networkd\n", 1)},
+		{name: "create missing parents",
+			in: netplan, edits: []ConfigEdit{cfgSet(false, "network", "ethernets", "eth1", "dhcp4")},
+			want: strings.Replace(netplan, "10.0.0.5/24\n", "10.0.0.5/24\n    eth1:\n      dhcp4: false\n", 1)},
+		{name: "replace a sequence as a whole",
+			in: netplan, edits: []ConfigEdit{cfgSet("10.0.0.6/24", "network", "ethernets", "eth0", "addresses")},
+			want: strings.Replace(netplan, "addresses:\n      - 10.0.0.5/24", `addresses: "10.0.0.6/24"`, 1)},
+		{name: "remove a block",
+			in: netplan, edits: []ConfigEdit{cfgUnset("network", "ethernets")},
+			want: strings.Replace(netplan, "  ethernets:\n    eth0:\n      dhcp4: true\n      addresses:\n      - 10.0.0.5/24\n", "", 1)},
+		{name: "quote what would read back as another type",
+			in: "", edits: []ConfigEdit{cfgSet("yes", "a"), cfgSet("1.0", "b"), cfgSet("plain text", "c")},
+			want: "a: \"yes\"\nb: \"1.0\"\nc: plain text\n"},
+		{name: "no-op",
+			in: netplan, edits: []ConfigEdit{cfgSet(true, "network", "ethernets", "eth0", "dhcp4")}, unchanged: true},
+	})
+}
+
+func TestEditConfigData_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		format ConfigFormat
+		in     string
+		edit   ConfigEdit
+		want   error
+	}{
+		{"kv section", FormatKeyValue, "[x]\n", cfgSet(1, "a"), ErrConfigSyntax},
+		{"kv line without =", FormatKeyValue, "a=1\njunk\n", cfgSet(1, "a"), ErrConfigSyntax},
+		{"ini header", FormatINI, "[open\n", cfgSet(1, "s", "k"), ErrConfigSyntax},
+		{"toml unterminated", FormatTOML, "a = \"x\n", cfgSet(1, "a"), ErrConfigSyntax},
+		{"invalid json", FormatJSON, "{\"a\":}", cfgSet(1, "a"), ErrConfigSyntax},
+		{"json array", FormatJSON, "[1]", cfgSet(1, "a"), ErrConfigSyntax},
+		{"yaml documents", FormatYAML, "a: 1\n---\nb: 2\n", cfgSet(1, "a"), ErrConfigSyntax},
+		{"yaml sequence", FormatYAML, "- a\n", cfgSet(1, "a"), ErrConfigSyntax},
+		{"empty path", FormatINI, "", ConfigEdit{Value: 1}, ErrInvalidConfigEdit},
+		{"ini path length", FormatINI, "", cfgSet(1, "k"), ErrInvalidConfigEdit},
+		{"ini key with =", FormatINI, "", cfgSet(1, "s", "a=b"), ErrInvalidConfigEdit},
+		{"newline in kv value", FormatKeyValue, "", cfgSet("a\nb", "k"), ErrInvalidConfigEdit},
+		{"unsupported value", FormatJSON, "{}", cfgSet([]string{"x"}, "k"), ErrInvalidConfigEdit},
+		{"json through a scalar", FormatJSON, `{"a":1}`, cfgSet(1, "a", "b"), ErrInvalidConfigEdit},
+		{"yaml through a scalar", FormatYAML, "a: 1\n", cfgSet(1, "a", "b"), ErrInvalidConfigEdit},
+		{"unknown format", ConfigFormat(0), "", cfgSet(1, "a"), ErrInvalidConfigEdit},
+	} {
+		if _, _, err := EditConfigData(tt.format, []byte(tt.in), []ConfigEdit{tt.edit}); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestEditConfig_Direct_KeepsModeAndSkipsNoOp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.conf")
+	if err := os.WriteFile(path, []byte("[daemon]\nA=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := directManager(t)
+	ctx := context.Background()
+
+	changed, err := m.EditConfig(ctx, path, FormatINI, []ConfigEdit{cfgSet("2", "daemon", "A")}, WriteOptions{})
+	if err != nil || !changed {
+		t.Fatalf("EditConfig = (%v, %v), want (true, nil)", changed, err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != "[daemon]\nA=2\n" {
+		t.Errorf("content = %q", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %o, want the file's 0600 kept", info.Mode().Perm())
+	}
+
+	before, _ := os.Stat(path)
+	changed, err = m.EditConfig(ctx, path, FormatINI, []ConfigEdit{cfgSet("2", "daemon", "A")}, WriteOptions{})
+	if err != nil || changed {
+		t.Fatalf("second EditConfig = (%v, %v), want (false, nil)", changed, err)
+	}
+	if after, _ := os.Stat(path); !os.SameFile(before, after) {
+		t.Error("a no-op edit replaced the file")
+	}
+}
+
+func TestEditConfig_Escalated_StatsThenWrites(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "{\"a\": 1}\n"}, nil)
+	f.Push(pmexec.Result{Stdout: "640 root staff\n"}, nil)
+	m := mustManager(t, f)
+
+	changed, err := m.EditConfig(context.Background(), "/etc/app.json", FormatJSON, []ConfigEdit{cfgSet(2, "a")}, WriteOptions{})
+	if err != nil || !changed {
+		t.Fatalf("EditConfig = (%v, %v), want (true, nil)", changed, err)
+	}
+	calls := f.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("ran %v, want cat, stat, sh", callNames(calls))
+	}
+	if got := argv(calls[1]); got != "stat -c %a %U %G -- /etc/app.json" {
+		t.Errorf("stat argv = %q", got)
+	}
+	w := calls[2]
+	if w.Args[4] != "0640" || w.Args[5] != "root:staff" {
+		t.Errorf("write mode/owner = %q %q, want the file's 0640 root:staff", w.Args[4], w.Args[5])
+	}
+	if body := stdinOf(t, w); body != "{\"a\": 2}\n" {
+		t.Errorf("written = %q", body)
+	}
+}
+
+func TestEditConfig_SyntaxErrorLeavesFileAlone(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "not json"}, nil)
+	m := mustManager(t, f)
+	if _, err := m.EditConfig(context.Background(), "/etc/app.json", FormatJSON, []ConfigEdit{cfgSet(1, "a")}, WriteOptions{}); !errors.Is(err, ErrConfigSyntax) {
+		t.Fatalf("err = %v, want ErrConfigSyntax", err)
+	}
+	if n := len(f.Calls()); n != 1 {
+		t.Errorf("ran %d commands, want only the read", n)
+	}
+}
@@ -0,0 +1,354 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// yamlKey is one key of a block mapping. Its block is the key line and the
+// lines below it that are indented further, or that are "- " items at its
+// own indentation.
+type yamlKey struct {
+	key    string
+	indent int
+	// line is the key line; end is one past the block's last content line,
+	// so comments after it stay with whatever follows.
+	line, end int
+	// colon is the offset of the colon after the key.
+	colon int
+	// valStart and valEnd bound the value on the key line, without a
+	// trailing comment. They are equal when the value is on lines below.
+	valStart, valEnd int
+}
+
+// yamlDoc is a YAML file split into lines, with which lines hold content.
+type yamlDoc struct {
+	lines []string
+	// indent is a content line's indentation, or -1 for a blank or comment
+	// line.
+	indent []int
+	// body is the first line of the document after an opening "---".
+	body int
+}
+
+func parseYAMLDoc(data []byte) (*yamlDoc, error) {
+	d := &yamlDoc{lines: splitLines(data)}
+	d.indent = make([]int, len(d.lines))
+	seen := false
+	for i, line := range d.lines {
+		t := strings.TrimSpace(line)
+		if t == "" || t[0] == '#' {
+			d.indent[i] = -1
+			continue
+		}
+		lead := indentOf(line)
+		if strings.ContainsRune(lead, '\t') {
+			return nil, fmt.Errorf("%w: line %d: tab in indentation", ErrConfigSyntax, i+1)
+		}
+		d.indent[i] = len(lead)
+		if isYAMLDocMarker(line) {
+			if seen {
+				return nil, fmt.Errorf("%w: line %d: only a single YAML document can be edited", ErrConfigSyntax, i+1)
+			}
+			d.indent[i], d.body = -1, i+1
+		}
+		seen = true
+	}
+	return d, nil
+}
+
+func isYAMLDocMarker(line string) bool {
+	for _, m := range []string{"---", "..."} {
+		if rest, ok := strings.CutPrefix(line, m); ok {
+			if t := strings.TrimSpace(rest); t == "" || t[0] == '#' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keys parses the block mapping in lines [from, to): its keys and their
+// indentation, which is -1 when the range holds no content.
+func (d *yamlDoc) keys(from, to int) ([]yamlKey, int, error) {
+	var keys []yamlKey
+	indent := -1
+	for i := from; i < to; i++ {
+		in := d.indent[i]
+		if in < 0 {
+			continue
+		}
+		if indent < 0 {
+			indent = in
+		}
+		rest := d.lines[i][in:]
+		switch {
+		case in < indent:
+			return nil, 0, fmt.Errorf("%w: line %d: inconsistent indentation", ErrConfigSyntax, i+1)
+		case in > indent || rest[0] == '-' && (len(rest) == 1 || strings.ContainsRune(" \t\r\n", rune(rest[1]))):
+			if len(keys) == 0 {
+				return nil, 0, fmt.Errorf("%w: line %d: not a block mapping", ErrConfigSyntax, i+1)
+			}
+			keys[len(keys)-1].end = i + 1
+		default:
+			k, err := parseYAMLKey(d.lines[i], in)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%w: line %d: %v", ErrConfigSyntax, i+1, err)
+			}
+			k.line, k.end = i, i+1
+			keys = append(keys, k)
+		}
+	}
+	return keys, indent, nil
+}
+
+// parseYAMLKey parses "key: value # comment" starting at line[in].
+func parseYAMLKey(line string, in int) (yamlKey, error) {
+	k := yamlKey{indent: in}
+	s := strings.TrimRight(line, "\r\n")
+	p := in
+	switch s[p] {
+	case '"':
+		end := scanBasicString(s, p+1)
+		if end < 0 {
+			return k, fmt.Errorf("unterminated quoted key")
+		}
+		if err := json.Unmarshal([]byte(s[p:end]), &k.key); err != nil {
+			return k, fmt.Errorf("quoted key %s: %v", s[p:end], err)
+		}
+		p = end
+	case '\'':
+		end := scanSingleQuoted(s, p+1)
+		if end < 0 {
+			return k, fmt.Errorf("unterminated quoted key")
+		}
+		k.key = strings.ReplaceAll(s[p+1:end-1], "''", "'")
+		p = end
+	default:
+		if strings.ContainsRune("[{&*!|>%@`?", rune(s[p])) {
+			return k, fmt.Errorf("unsupported construct %q", strings.TrimSpace(s))
+		}
+		for ; p < len(s); p++ {
+			if s[p] == ':' && (p+1 == len(s) || s[p+1] == ' ' || s[p+1] == '\t') {
+				break
+			}
+		}
+		k.key = strings.TrimSpace(s[in:p])
+	}
+	if p >= len(s) || s[p] != ':' || p+1 < len(s) && s[p+1] != ' ' && s[p+1] != '\t' {
+		return k, fmt.Errorf("expected key: value, got %q", strings.TrimSpace(s))
+	}
+	k.colon = p
+	p++
+	for p < len(s) && (s[p] == ' ' || s[p] == '\t') {
+		p++
+	}
+	k.valStart, k.valEnd = p, yamlValueEnd(s, p)
+	return k, nil
+}
+
+// scanSingleQuoted returns the index just past the closing quote of the
+// single-quoted YAML string whose content starts at s[from], or -1.
+func scanSingleQuoted(s string, from int) int {
+	for i := from; i < len(s); i++ {
+		if s[i] == '\'' {
+			if i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// yamlValueEnd returns where the value starting at s[p] ends: before a
+// trailing comment and the spaces ahead of it.
+func yamlValueEnd(s string, p int) int {
+	if p >= len(s) || s[p] == '#' {
+		return p
+	}
+	end := len(s)
+	switch s[p] {
+	case '"':
+		if q := scanBasicString(s, p+1); q > 0 {
+			end = q
+		}
+	case '\'':
+		if q := scanSingleQuoted(s, p+1); q > 0 {
+			end = q
+		}
+	default:
+		if h := strings.Index(s[p:], " #"); h >= 0 {
+			end = p + h
+		}
+		if h := strings.Index(s[p:end], "\t#"); h >= 0 {
+			end = p + h
+		}
+	}
+	return p + len(strings.TrimRight(s[p:end], " \t"))
+}
+
+// unit guesses the document's indentation step from its least indented
+// nested line, defaulting to two spaces.
+func (d *yamlDoc) unit() int {
+	unit := 0
+	for _, in := range d.indent {
+		if in > 0 && (unit == 0 || in < unit) {
+			unit = in
+		}
+	}
+	if unit == 0 {
+		return 2
+	}
+	return unit
+}
+
+// editYAML applies one edit to a YAML document whose top level is a block
+// mapping, rewriting only the lines of the key it touches.
+func editYAML(data []byte, e ConfigEdit) ([]byte, error) {
+	d, err := parseYAMLDoc(data)
+	if err != nil {
+		return nil, err
+	}
+	lines := d.lines
+	from, to, parent := d.body, len(lines), -1
+	for depth, key := range e.Path {
+		keys, indent, err := d.keys(from, to)
+		if err != nil {
+			return nil, err
+		}
+		// New keys go after the parent's last content line, or at the end
+		// of an empty parent.
+		at := to
+		for i := from; i < to; i++ {
+			if d.indent[i] >= 0 {
+				at = i + 1
+			}
+		}
+		if indent < 0 {
+			indent = 0
+			if parent >= 0 {
+				indent = parent + d.unit()
+			}
+		}
+		var found []yamlKey
+		for _, k := range keys {
+			if k.key == key {
+				found = append(found, k)
+			}
+		}
+		last := depth == len(e.Path)-1
+
+		if e.Remove {
+			if len(found) == 0 {
+				return data, nil
+			}
+			if !last {
+				k := found[len(found)-1]
+				if k.valEnd > k.valStart || yamlIsSequence(d, k) {
+					return data, nil
+				}
+				from, to, parent = k.line+1, k.end, k.indent
+				continue
+			}
+			for _, k := range slices.Backward(found) {
+				lines = slices.Delete(lines, k.line, k.end)
+			}
+			return joinLines(lines), nil
+		}
+
+		v, err := configScalar(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			// Write the rest of the path as nested keys.
+			var add []string
+			for i, k := range e.Path[depth:] {
+				pad := strings.Repeat(" ", indent+i*d.unit())
+				if i == len(e.Path[depth:])-1 {
+					add = append(add, pad+yamlScalar(k)+": "+yamlValue(v)+"\n")
+				} else {
+					add = append(add, pad+yamlScalar(k)+":\n")
+				}
+			}
+			return joinLines(insertLines(lines, at, add...)), nil
+		}
+		k := found[len(found)-1]
+		if !last {
+			if k.valEnd > k.valStart || yamlIsSequence(d, k) {
+				return nil, fmt.Errorf("%w: %s is not a mapping", ErrInvalidConfigEdit, strings.Join(e.Path[:depth+1], "."))
+			}
+			from, to, parent = k.line+1, k.end, k.indent
+			continue
+		}
+		line := lines[k.line]
+		if k.end == k.line+1 && k.valEnd > k.valStart {
+			// An inline value: swap it and keep any comment after it.
+			lines[k.line] = line[:k.valStart] + yamlValue(v) + line[k.valEnd:]
+		} else {
+			// A block below the key, or a block scalar or flow collection
+			// that starts on its line: the whole block goes.
+			body := strings.TrimRight(line, "\r\n")
+			lines = slices.Replace(lines, k.line, k.end, body[:k.colon+1]+" "+yamlValue(v)+line[len(body):])
+		}
+		return joinLines(lines), nil
+	}
+	return data, nil
+}
+
+// yamlIsSequence reports whether k's block is a sequence.
+func yamlIsSequence(d *yamlDoc, k yamlKey) bool {
+	for i := k.line + 1; i < k.end; i++ {
+		if d.indent[i] >= 0 {
+			return d.lines[i][d.indent[i]] == '-'
+		}
+	}
+	return false
+}
+
+// yamlValue renders a scalar edit value.
+func yamlValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return yamlScalar(v)
+	case float64:
+		return formatFloat(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// yamlScalar writes s plain when YAML reads it back as the same string, and
+// double-quoted otherwise.
+func yamlScalar(s string) string {
+	if yamlPlainSafe(s) {
+		return s
+	}
+	q, _ := json.Marshal(s)
+	return string(q)
+}
+
+func yamlPlainSafe(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`.+0123456789~", rune(s[0])) {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err != nil
+}
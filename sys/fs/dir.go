@@ -4,15 +4,21 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
 )
 
-// Mkdir creates a directory per opts. opts.Recursive adds -p; opts.Mode and
-// opts.Owner/Group, when set, are applied after creation. A zero opts.Mode
-// leaves mkdir's default (mode minus umask) in place.
+// Mkdir creates a directory per opts. opts.Recursive adds -p; opts.Mode,
+// opts.Owner/Group and opts.Attributes, when set, are applied after
+// creation. A zero opts.Mode leaves mkdir's default (mode minus umask) in
+// place.
 func (m *manager) Mkdir(ctx context.Context, path string, opts MkdirOptions) error {
 	if err := ValidatePath(path); err != nil {
 		return err
 	}
+	if err := validateAttributes(opts.Attributes, true); err != nil {
+		return err
+	}
 	args := make([]string, 0, 3)
 	if opts.Recursive {
 		args = append(args, "-p")
@@ -21,6 +27,10 @@ func (m *manager) Mkdir(ctx context.Context, path string, opts MkdirOptions) err
 	if err := m.runChecked(ctx, "mkdir", args...); err != nil {
 		return err
 	}
+	// mkdir -p succeeds on an existing directory, which may be flagged.
+	if err := m.clearFlags(ctx, path, opts.Attributes); err != nil {
+		return err
+	}
 	if opts.Mode != 0 {
 		if err := m.SetMode(ctx, path, opts.Mode); err != nil {
 			return err
@@ -31,7 +41,7 @@ func (m *manager) Mkdir(ctx context.Context, path string, opts MkdirOptions) err
 			return err
 		}
 	}
-	return nil
+	return m.applyAttributes(ctx, path, opts.Attributes, false)
 }
 
 // Remove deletes a single file (rm -f) and returns any error. The `--`
@@ -72,6 +82,9 @@ func (m *manager) RemoveDir(ctx context.Context, path string) error {
 		return fmt.Errorf("refusing to remove protected path: %s", clean)
 	}
 	if m.direct() {
+		if pmexec.Planned(m.r, "remove directory "+clean) {
+			return nil
+		}
 		return removeDirSecure(ctx, clean)
 	}
 	return m.runChecked(ctx, "rm", "-rf", "--", clean)
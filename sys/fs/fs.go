@@ -30,7 +30,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
 )
@@ -78,6 +80,13 @@ type WriteOptions struct {
 	// does not yet exist). The copy is taken crash-safely — the destination is
 	// never left absent — which the agent's self-update relies on.
 	Backup string
+	// Versions, when enabled, keeps the content being replaced as a new
+	// version under the Manager's backup directory, for ListBackups and
+	// Restore, and prunes older versions per the policy.
+	Versions BackupPolicy
+	// Attributes sets ACLs, xattrs, the SELinux label and inode flags on the
+	// written file. A DefaultACL is rejected; files cannot carry one.
+	Attributes
 }
 
 // MkdirOptions configures a Manager.Mkdir call.
@@ -89,6 +98,9 @@ type MkdirOptions struct {
 	Owner, Group string
 	// Recursive creates parent directories as needed (mkdir -p).
 	Recursive bool
+	// Attributes sets ACLs (including the default ACL new entries inherit),
+	// xattrs, the SELinux label and inode flags on the directory.
+	Attributes
 }
 
 // Manager is the privileged filesystem surface. Every method takes a context so
@@ -100,6 +112,13 @@ type Manager interface {
 	// "absent" from "present but empty"; opt into absent-as-empty with
 	// errors.Is(err, fs.ErrNotExist). A present empty file returns (nil, nil).
 	ReadFile(ctx context.Context, path string) ([]byte, error)
+	// ReadFileExact is ReadFile with the content byte for byte and no size
+	// limit on either backend; the escalated ReadFile normalizes line endings
+	// and stops at exec.MaxOutputBytes.
+	ReadFileExact(ctx context.Context, path string) ([]byte, error)
+	// HashFile returns the hex SHA-256 of path's content, as ContentDigest
+	// would, without reading it back and with no size limit.
+	HashFile(ctx context.Context, path string) (string, error)
 	// ReadDir lists the immediate entries of a directory (no recursion). A
 	// missing directory yields a wrapped fs.ErrNotExist (the same explicit-
 	// absence contract as ReadFile); a non-directory target is an error too,
@@ -108,6 +127,17 @@ type Manager interface {
 	// WriteFile writes data to path atomically. When the Runner's backend is
 	// Direct the write is also symlink-safe (fd-anchored); see the package doc.
 	WriteFile(ctx context.Context, path string, data []byte, opts WriteOptions) error
+	// EditConfig sets or removes individual keys of a config file in the given
+	// format, keeping its comments and ordering, and writes it back through
+	// WriteFile only when something changed. It reports whether it did.
+	EditConfig(ctx context.Context, path string, format ConfigFormat, edits []ConfigEdit, opts WriteOptions) (bool, error)
+	// Stat returns path's type, mode, ownership, size, modification time and
+	// extended attributes, without following a final symlink. A missing path
+	// yields a wrapped fs.ErrNotExist, as ReadFile does.
+	Stat(ctx context.Context, path string) (FileInfo, error)
+	// ReadAttributes reads back the ACLs, xattrs, SELinux label and inode
+	// flags Attributes sets, for comparing with Attributes.Drift.
+	ReadAttributes(ctx context.Context, path string) (Attributes, error)
 	// Exists reports whether path exists. The probe runs through the privilege
 	// backend so it can see paths in directories the caller cannot traverse
 	// (e.g. /etc/sudoers.d, mode 0750). A runner/ctx failure is returned as an
@@ -121,12 +151,25 @@ type Manager interface {
 	// or under a security-relevant system prefix (deny-by-default) and, on the
 	// Direct backend, never follows a symlink (fd-anchored recursive delete).
 	RemoveDir(ctx context.Context, path string) error
-	// Copy copies src to dst and applies opts (mode/ownership) to dst.
+	// ListBackups returns the versions WriteFile kept of path under a
+	// BackupPolicy, newest first. A path with none returns an empty list.
+	ListBackups(ctx context.Context, path string) ([]Backup, error)
+	// Restore puts version back at path with the content, mode and ownership
+	// it had, keeping what it replaces as a new version.
+	Restore(ctx context.Context, path, version string) error
+	// Copy copies src to dst and applies opts (mode/ownership/attributes) to
+	// dst.
 	Copy(ctx context.Context, src, dst string, opts WriteOptions) error
 	// CopyTree recursively copies the tree at src to dst (cp -a), merging into
 	// dst rather than nesting under it. A non-zero opts.Mode chmods the dst root;
-	// opts.Owner/Group, if set, are applied recursively.
+	// opts.Owner/Group, if set, are applied recursively; opts.Attributes apply
+	// to the dst root, except an SELinux label, which covers the tree.
 	CopyTree(ctx context.Context, src, dst string, opts WriteOptions) error
+	// SyncTree makes dst a copy of src, rewriting only files whose size or
+	// SHA-256 differ, correcting mode and ownership, and — with opts.Delete,
+	// in a tree carrying SyncMarker — removing what src lacks. It returns
+	// what changed.
+	SyncTree(ctx context.Context, src, dst string, opts SyncOptions) (SyncSummary, error)
 	// SetMode sets the file mode (chmod).
 	SetMode(ctx context.Context, path string, mode os.FileMode) error
 	// SetOwnership sets the file owner and group (chown). Either may be empty;
@@ -148,16 +191,36 @@ type Manager interface {
 // manager is the single Manager implementation; the privilege strategy is the
 // Runner's, so there is no per-backend type.
 type manager struct {
-	r pmexec.Runner
+	r         pmexec.Runner
+	backupDir string
+	now       func() time.Time
+}
+
+// Option configures a Manager at construction.
+type Option func(*manager)
+
+// WithBackupDir overrides where versioned backups are kept (default
+// DefaultBackupDir).
+func WithBackupDir(dir string) Option {
+	return func(m *manager) { m.backupDir = dir }
 }
 
 // New builds a filesystem Manager driven by runner. A nil runner is rejected
 // (fail-closed). New is pure — it does not probe the host.
-func New(runner pmexec.Runner) (Manager, error) {
+func New(runner pmexec.Runner, opts ...Option) (Manager, error) {
 	if runner == nil {
 		return nil, fmt.Errorf("fs: %w", pmexec.ErrRunnerRequired)
 	}
-	return &manager{r: runner}, nil
+	m := &manager{r: runner, backupDir: DefaultBackupDir, now: time.Now}
+	for _, opt := range opts {
+		if opt != nil { // tolerate a nil option rather than panicking the agent
+			opt(m)
+		}
+	}
+	if err := ValidatePath(m.backupDir); err != nil || !filepath.IsAbs(m.backupDir) {
+		return nil, fmt.Errorf("fs: backup dir %q must be an absolute path", m.backupDir)
+	}
+	return m, nil
 }
 
 // direct reports whether the Runner runs as root with no escalation wrapper, in
@@ -176,6 +239,12 @@ func (m *manager) runPriv(ctx context.Context, name string, args ...string) (pme
 	return m.r.Run(ctx, pmexec.Command{Name: name, Args: args, Escalate: true})
 }
 
+// runPrivRead is runPriv for a command that only reads (cat, test, find), so a
+// dry run still answers it from the host.
+func (m *manager) runPrivRead(ctx context.Context, name string, args ...string) (pmexec.Result, error) {
+	return m.r.Run(ctx, pmexec.Command{Name: name, Args: args, Escalate: true, ReadOnly: true})
+}
+
 // runPrivStdin is runPriv with stdin (the tee write path).
 func (m *manager) runPrivStdin(ctx context.Context, stdin string, name string, args ...string) (pmexec.Result, error) {
 	var in *strings.Reader
@@ -192,7 +261,7 @@ func (m *manager) runPrivStdin(ctx context.Context, stdin string, name string, a
 // runQuery runs an unprivileged read (findmnt) through the Runner. The Runner
 // forces the C locale, keeping the output parse locale-stable.
 func (m *manager) runQuery(ctx context.Context, name string, args ...string) (pmexec.Result, error) {
-	return m.r.Run(ctx, pmexec.Command{Name: name, Args: args})
+	return m.r.Run(ctx, pmexec.Command{Name: name, Args: args, ReadOnly: true})
 }
 
 // cmdError turns a completed command's Result into a typed error when its exit
@@ -328,6 +328,90 @@ func TestCopyTree(t *testing.T) {
 	}
 }
 
+func TestSyncTree(t *testing.T) {
+	ctx := context.Background()
+	m := intManager(t)
+	src := tmpPath(t, "syncsrc")
+	dst := tmpPath(t, "syncdst")
+	defer func() { _ = m.RemoveDir(ctx, src); _ = m.RemoveDir(ctx, dst) }()
+
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for path, body := range map[string]string{
+		filepath.Join(src, "a.conf"):        "a\n",
+		filepath.Join(src, "sub", "b.conf"): "b\n",
+	} {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := fs.SyncOptions{FileMode: 0o640, Owner: "root", Group: "root", Delete: true}
+	sum, err := m.SyncTree(ctx, src, dst, opts)
+	if err != nil {
+		t.Fatalf("SyncTree: %v", err)
+	}
+	if len(sum.Created) != 4 { // dst, a.conf, sub, sub/b.conf
+		t.Errorf("first sync created %q, want 4 entries", sum.Created)
+	}
+	if mode := statMode(t, filepath.Join(dst, "sub", "b.conf")); mode != 0o640 {
+		t.Errorf("b.conf mode = %v, want 0640", mode)
+	}
+
+	// A second sync finds nothing to do; removing a source file deletes it.
+	if sum, err = m.SyncTree(ctx, src, dst, opts); err != nil || sum.Changed() {
+		t.Fatalf("re-sync: changed=%v err=%v, want a no-op", sum.Changed(), err)
+	}
+	if err := os.Remove(filepath.Join(src, "a.conf")); err != nil {
+		t.Fatal(err)
+	}
+	if sum, err = m.SyncTree(ctx, src, dst, opts); err != nil || len(sum.Deleted) != 1 {
+		t.Fatalf("sync after removal: deleted=%q err=%v", sum.Deleted, err)
+	}
+	if ok, _ := m.Exists(ctx, filepath.Join(dst, "a.conf")); ok {
+		t.Error("a.conf survived a deleting sync")
+	}
+}
+
+func TestWriteFileVersionsAndRestore(t *testing.T) {
+	ctx := context.Background()
+	backups := tmpPath(t, "backups")
+	r, err := exec.NewRunner(exec.Sudo)
+	if os.Geteuid() == 0 {
+		r, err = exec.NewRunner(exec.Direct)
+	}
+	if err != nil {
+		t.Fatalf("NewRunner: %v", err)
+	}
+	m, err := fs.New(r, fs.WithBackupDir(backups))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := tmpPath(t, "versioned")
+	defer func() { cleanup(t, m, path); _ = m.RemoveDir(ctx, backups) }()
+
+	policy := fs.BackupPolicy{Keep: 2}
+	for _, body := range []string{"v1\n", "v2\n", "v3\n", "v4\n"} {
+		if err := m.WriteFile(ctx, path, []byte(body), fs.WriteOptions{Versions: policy}); err != nil {
+			t.Fatalf("WriteFile(%q): %v", body, err)
+		}
+	}
+	list, err := m.ListBackups(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("ListBackups = %+v, want the 2 newest (Keep: 2)", list)
+	}
+	if err := m.Restore(ctx, path, list[1].Version); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got, _ := m.ReadFile(ctx, path); string(got) != "v2\n" {
+		t.Errorf("after Restore = %q, want v2", got)
+	}
+}
+
 func TestListMounts_Integration(t *testing.T) {
 	mounts, err := intManager(t).ListMounts(context.Background())
 	if err != nil {
@@ -1,7 +1,9 @@
 package fs
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"io"
 	"os"
@@ -307,6 +309,68 @@ func TestReadFile_RunnerErrorPropagatesAndValidatesPath(t *testing.T) {
 	}
 }
 
+// ReadFileExact streams base64 from root and decodes it, so a CR, a missing
+// final newline and content past exec.MaxOutputBytes all come back intact.
+func TestReadFileExact_Escalated(t *testing.T) {
+	body := append([]byte("a\r\nb\x00"), strings.Repeat("x", pmexec.MaxOutputBytes)...)
+	enc := base64.StdEncoding.EncodeToString(body)
+	var lines strings.Builder
+	for len(enc) > 76 {
+		lines.WriteString(enc[:76] + "\n")
+		enc = enc[76:]
+	}
+	lines.WriteString(enc + "\n")
+
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: lines.String()}, nil)
+	got, err := mustManager(t, f).ReadFileExact(context.Background(), "/var/lib/app/state.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("ReadFileExact returned %d bytes, want the %d-byte file unchanged", len(got), len(body))
+	}
+	c := f.Calls()[0]
+	if argv(c) != "base64 -- /var/lib/app/state.json" || !c.Escalate || !c.ReadOnly {
+		t.Errorf("command = %q (escalate %v, read-only %v)", argv(c), c.Escalate, c.ReadOnly)
+	}
+}
+
+func TestReadFileExact_MissingAndEmpty(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "base64: /x: No such file or directory"}, nil)
+	f.Push(pmexec.Result{}, nil)
+	m := mustManager(t, f)
+	if got, err := m.ReadFileExact(context.Background(), "/x"); !errors.Is(err, os.ErrNotExist) || got != nil {
+		t.Errorf("ReadFileExact(missing) = (%q, %v), want (nil, ErrNotExist)", got, err)
+	}
+	if got, err := m.ReadFileExact(context.Background(), "/empty"); err != nil || got != nil {
+		t.Errorf("ReadFileExact(empty) = (%q, %v), want (nil, nil)", got, err)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := mustManager(t, exectest.New(pmexec.Direct)).HashFile(context.Background(), path); err != nil || got != sum {
+		t.Errorf("Direct HashFile = %q, %v; want %s", got, err, sum)
+	}
+
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: sum + "  /etc/big\n"}, nil)
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "sha256sum: /gone: No such file or directory"}, nil)
+	m := mustManager(t, f)
+	if got, err := m.HashFile(context.Background(), "/etc/big"); err != nil || got != sum || got != ContentDigest([]byte("hello")) {
+		t.Errorf("escalated HashFile = %q, %v; want %s", got, err, sum)
+	}
+	if _, err := m.HashFile(context.Background(), "/gone"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("HashFile(missing) err = %v, want ErrNotExist", err)
+	}
+}
+
 func TestExists(t *testing.T) {
 	t.Run("present", func(t *testing.T) {
 		f := exectest.New(pmexec.Sudo) // unscripted → exit 0
@@ -923,3 +987,36 @@ func TestRunChecked_RunnerErrorPropagates(t *testing.T) {
 		t.Fatalf("err = %v, want ErrEscalationUnavailable", err)
 	}
 }
+
+// --- dry run ----------------------------------------------------------------
+
+// The Direct paths change the host in-process, not through the Runner, so a
+// dry run must plan them explicitly and leave the disk alone.
+func TestDirect_DryRunPlansWithoutTouchingDisk(t *testing.T) {
+	d, err := pmexec.NewDryRunner(exectest.New(pmexec.Direct))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "app.conf")
+	if err := m.WriteFile(context.Background(), target, []byte("body\n"), WriteOptions{}); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := os.Lstat(target); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("dry-run WriteFile created %s (err = %v)", target, err)
+	}
+	if err := m.RemoveDir(context.Background(), dir); err != nil {
+		t.Fatalf("RemoveDir: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("dry-run RemoveDir removed %s: %v", dir, err)
+	}
+	want := "write " + target + " (5 bytes)\nremove directory " + dir
+	if got := d.Plan().String(); got != want {
+		t.Errorf("plan =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -4,8 +4,6 @@ package fs
 
 import (
 	"os"
-	"os/user"
-	"strconv"
 	"syscall"
 )
 
@@ -23,11 +21,5 @@ func GetOwnership(path string) (owner, group string) {
 	if !ok {
 		return "", ""
 	}
-	if u, lookupErr := user.LookupId(strconv.FormatUint(uint64(st.Uid), 10)); lookupErr == nil {
-		owner = u.Username
-	}
-	if g, lookupErr := user.LookupGroupId(strconv.FormatUint(uint64(st.Gid), 10)); lookupErr == nil {
-		group = g.Name
-	}
-	return owner, group
+	return idNames(st.Uid, st.Gid)
 }
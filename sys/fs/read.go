@@ -2,8 +2,15 @@ package fs
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
 )
 
 // ReadFile reads path's contents.
@@ -40,7 +47,7 @@ func (m *manager) ReadFile(ctx context.Context, path string) ([]byte, error) {
 		}
 		return b, nil
 	}
-	res, err := m.runPriv(ctx, "cat", "--", path)
+	res, err := m.runPrivRead(ctx, "cat", "--", path)
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +82,86 @@ func (m *manager) Exists(ctx context.Context, path string) (bool, error) {
 		}
 		return true, nil
 	}
-	res, err := m.runPriv(ctx, "test", "-e", path)
+	res, err := m.runPrivRead(ctx, "test", "-e", path)
 	if err != nil {
 		return false, err
 	}
 	return res.ExitCode == 0, nil
 }
+
+// HashFile returns the hex SHA-256 of path's content, the form of
+// ContentDigest. The Direct backend hashes the file as it reads it; the
+// escalated one runs sha256sum as root. Neither reads the content back
+// through the Runner, so no size limit applies. A missing path yields a
+// wrapped os.ErrNotExist, as ReadFile does.
+func (m *manager) HashFile(ctx context.Context, path string) (string, error) {
+	if err := ValidatePath(path); err != nil {
+		return "", err
+	}
+	if m.direct() {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("hash %s: %w", path, err)
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", fmt.Errorf("hash %s: %w", path, err)
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	res, err := m.runPrivRead(ctx, "sha256sum", "--", path)
+	if err != nil {
+		return "", err
+	}
+	if res.ExitCode != 0 && isENOENTStderr(res.Stderr) {
+		return "", fmt.Errorf("hash %s: %w", path, os.ErrNotExist)
+	}
+	if cerr := cmdError("sha256sum", res); cerr != nil {
+		return "", fmt.Errorf("hash %s: %w", path, cerr)
+	}
+	// A name sha256sum has to escape prefixes the line with a backslash.
+	f := strings.Fields(strings.TrimPrefix(res.Stdout, `\`))
+	if len(f) == 0 || len(f[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("hash %s: unexpected sha256sum output %q", path, res.Stdout)
+	}
+	return f[0], nil
+}
+
+// ReadFileExact is ReadFile for content that must come back byte for byte
+// at any size. The escalated ReadFile captures cat's output line by line and
+// caps it at exec.MaxOutputBytes; here root base64-encodes the file and the
+// lines are decoded as they stream in, so neither applies. On the Direct
+// backend it is ReadFile.
+func (m *manager) ReadFileExact(ctx context.Context, path string) ([]byte, error) {
+	if err := ValidatePath(path); err != nil {
+		return nil, err
+	}
+	if m.direct() {
+		return m.ReadFile(ctx, path)
+	}
+	var enc strings.Builder
+	res, err := m.r.Stream(ctx, pmexec.Command{Name: "base64", Args: []string{"--", path}, Escalate: true, ReadOnly: true},
+		func(stream pmexec.StreamType, line string, _ int64) {
+			if stream == pmexec.StreamStdout {
+				enc.WriteString(strings.TrimSpace(line))
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	if res.ExitCode != 0 {
+		if isENOENTStderr(res.Stderr) {
+			return nil, fmt.Errorf("read %s: %w", path, os.ErrNotExist)
+		}
+		return nil, cmdError("base64", res)
+	}
+	data, err := base64.StdEncoding.DecodeString(enc.String())
+	if err != nil {
+		return nil, fmt.Errorf("read %s: decode base64: %w", path, err)
+	}
+	if len(data) == 0 {
+		return nil, nil // present but empty
+	}
+	return data, nil
+}
@@ -73,7 +73,7 @@ func readDirDirect(path string) ([]DirEntry, error) {
 // escalated path enforce the same "non-directory target is an error, never an
 // empty listing" contract the Direct (os.ReadDir) path already honours.
 func (m *manager) readDirEscalated(ctx context.Context, path string) ([]DirEntry, error) {
-	res, err := m.runPriv(ctx, "find", path+"/", "-maxdepth", "1", "-mindepth", "1", "-printf", `%y/%f\n`)
+	res, err := m.runPrivRead(ctx, "find", path+"/", "-maxdepth", "1", "-mindepth", "1", "-printf", `%y/%f\n`)
 	if err != nil {
 		return nil, err
 	}
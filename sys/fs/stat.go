@@ -0,0 +1,179 @@
+package fs
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+)
+
+// FileInfo is what Stat reports about a path. A symlink is described, not
+// followed.
+type FileInfo struct {
+	// Mode carries the file type and permission bits, including setuid,
+	// setgid and sticky.
+	Mode os.FileMode
+	// Owner and Group are names; an id with no name is left empty.
+	Owner, Group string
+	Size         int64
+	// ModTime is the last content modification. The escalated backend reads
+	// it to the second.
+	ModTime time.Time
+	// Xattrs maps each extended attribute (security.selinux, user.*, ...) to
+	// its value. It is nil when the path has none, and on the escalated
+	// backend when getfattr is not installed.
+	Xattrs map[string][]byte
+}
+
+// Stat returns path's metadata without following a final symlink. A missing
+// path yields a wrapped fs.ErrNotExist, as ReadFile does.
+func (m *manager) Stat(ctx context.Context, path string) (FileInfo, error) {
+	if err := ValidatePath(path); err != nil {
+		return FileInfo{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return FileInfo{}, err
+	}
+	if m.direct() {
+		return statDirect(path)
+	}
+	return m.statEscalated(ctx, path)
+}
+
+// statEscalated runs stat(1), and getfattr(1) for the extended attributes,
+// through the privilege backend.
+func (m *manager) statEscalated(ctx context.Context, path string) (FileInfo, error) {
+	res, err := m.runPrivRead(ctx, "stat", "-c", "%f %s %Y %U %G", "--", path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if res.ExitCode != 0 {
+		if isENOENTStderr(res.Stderr) {
+			return FileInfo{}, fmt.Errorf("stat %s: %w", path, os.ErrNotExist)
+		}
+		return FileInfo{}, cmdError("stat", res)
+	}
+	info, err := parseStat(res.Stdout)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	res, err = m.runPrivRead(ctx, "getfattr", "-h", "-d", "-m", "-", "-e", "hex", "--absolute-names", "--", path)
+	if errors.Is(err, pmexec.ErrBackendUnavailable) {
+		return info, nil
+	}
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if cerr := cmdError("getfattr", res); cerr != nil {
+		return FileInfo{}, fmt.Errorf("getfattr %s: %w", path, cerr)
+	}
+	if info.Xattrs, err = parseGetfattr(res.Stdout); err != nil {
+		return FileInfo{}, fmt.Errorf("getfattr %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// parseStat parses `stat -c '%f %s %Y %U %G'`: the raw mode in hex, size,
+// mtime and owner names. stat prints UNKNOWN for an id with no name.
+func parseStat(out string) (FileInfo, error) {
+	f := strings.Fields(out)
+	if len(f) != 5 {
+		return FileInfo{}, fmt.Errorf("unexpected stat output %q", out)
+	}
+	raw, err := strconv.ParseUint(f[0], 16, 32)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("mode %q: %w", f[0], err)
+	}
+	size, err := strconv.ParseInt(f[1], 10, 64)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("size %q: %w", f[1], err)
+	}
+	mtime, err := strconv.ParseInt(f[2], 10, 64)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("mtime %q: %w", f[2], err)
+	}
+	info := FileInfo{Mode: unixMode(uint32(raw)), Size: size, ModTime: time.Unix(mtime, 0), Owner: f[3], Group: f[4]}
+	if info.Owner == "UNKNOWN" {
+		info.Owner = ""
+	}
+	if info.Group == "UNKNOWN" {
+		info.Group = ""
+	}
+	return info, nil
+}
+
+// unixMode converts a st_mode to an os.FileMode.
+func unixMode(raw uint32) os.FileMode {
+	mode := os.FileMode(raw & 0o777)
+	switch raw & 0o170000 {
+	case 0o040000:
+		mode |= os.ModeDir
+	case 0o120000:
+		mode |= os.ModeSymlink
+	case 0o010000:
+		mode |= os.ModeNamedPipe
+	case 0o140000:
+		mode |= os.ModeSocket
+	case 0o020000:
+		mode |= os.ModeDevice | os.ModeCharDevice
+	case 0o060000:
+		mode |= os.ModeDevice
+	}
+	if raw&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if raw&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if raw&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// parseGetfattr parses `getfattr -d -m - -e hex` output:
+//
+//	# file: /etc/ssh/sshd_config
+//	security.selinux=0x73797374656d5f75...
+//
+// A value is hex (0x), base64 (0s) or a quoted string; a name alone has an
+// empty value.
+func parseGetfattr(out string) (map[string][]byte, error) {
+	var attrs map[string][]byte
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, val, _ := strings.Cut(line, "=")
+		var b []byte
+		var err error
+		switch {
+		case strings.HasPrefix(val, "0x"):
+			b, err = hex.DecodeString(val[2:])
+		case strings.HasPrefix(val, "0s"):
+			b, err = base64.StdEncoding.DecodeString(val[2:])
+		case strings.HasPrefix(val, `"`):
+			var s string
+			s, err = strconv.Unquote(val)
+			b = []byte(s)
+		default:
+			b = []byte(val)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", name, err)
+		}
+		if attrs == nil {
+			attrs = map[string][]byte{}
+		}
+		attrs[name] = b
+	}
+	return attrs, nil
+}
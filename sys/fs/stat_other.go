@@ -0,0 +1,15 @@
+//go:build !unix
+
+package fs
+
+import "os"
+
+// statDirect reports what os.Lstat knows; ownership and extended attributes
+// are unix-only.
+func statDirect(path string) (FileInfo, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	return FileInfo{Mode: fi.Mode(), Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
@@ -0,0 +1,117 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+func TestStat_Direct(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "f")
+	if err := os.WriteFile(path, []byte("hello"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(path, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	m := directManager(t)
+
+	info, err := m.Stat(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode != 0o640 || info.Size != 5 || info.ModTime.IsZero() {
+		t.Errorf("Stat = %+v, want a 0640 regular file of 5 bytes", info)
+	}
+	if me, err := osUser(); err == nil && (info.Owner != me.owner || info.Group != me.group) {
+		t.Errorf("owner = %s:%s, want %s:%s", info.Owner, info.Group, me.owner, me.group)
+	}
+
+	link, err := m.Stat(context.Background(), filepath.Join(dir, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Mode&os.ModeSymlink == 0 {
+		t.Errorf("link mode = %v, want the symlink itself, not its target", link.Mode)
+	}
+
+	if _, err := m.Stat(context.Background(), filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat(missing) err = %v, want ErrNotExist", err)
+	}
+}
+
+func TestStat_Escalated(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "81a0 12 1700000000 root ssh_keys\n"}, nil)
+	f.Push(pmexec.Result{Stdout: "# file: /etc/ssh/k\nsecurity.selinux=0x6f6b00\nuser.note=\"hi\"\n\n"}, nil)
+	m := mustManager(t, f)
+
+	info, err := m.Stat(context.Background(), "/etc/ssh/k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FileInfo{Mode: 0o640, Owner: "root", Group: "ssh_keys", Size: 12, ModTime: time.Unix(1700000000, 0)}
+	if info.Mode != want.Mode || info.Owner != want.Owner || info.Group != want.Group ||
+		info.Size != want.Size || !info.ModTime.Equal(want.ModTime) {
+		t.Errorf("Stat = %+v, want %+v", info, want)
+	}
+	if string(info.Xattrs["security.selinux"]) != "ok\x00" || string(info.Xattrs["user.note"]) != "hi" {
+		t.Errorf("xattrs = %q", info.Xattrs)
+	}
+	calls := f.Calls()
+	if got := argv(calls[0]); got != "stat -c %f %s %Y %U %G -- /etc/ssh/k" {
+		t.Errorf("stat argv = %q", got)
+	}
+	if got := argv(calls[1]); got != "getfattr -h -d -m - -e hex --absolute-names -- /etc/ssh/k" {
+		t.Errorf("getfattr argv = %q", got)
+	}
+}
+
+func TestStat_Escalated_WithoutGetfattr(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "41ed 4096 1 root UNKNOWN\n"}, nil)
+	f.Push(pmexec.Result{}, fmt.Errorf("%w: command not found: getfattr", pmexec.ErrBackendUnavailable))
+	m := mustManager(t, f)
+
+	info, err := m.Stat(context.Background(), "/etc/ssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode != os.ModeDir|0o755 || info.Group != "" || info.Xattrs != nil {
+		t.Errorf("Stat = %+v, want a 0755 dir, no group name, no xattrs", info)
+	}
+}
+
+func TestStat_Escalated_Missing(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "stat: cannot statx '/x': No such file or directory"}, nil)
+	m := mustManager(t, f)
+	if _, err := m.Stat(context.Background(), "/x"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("err = %v, want ErrNotExist", err)
+	}
+	if n := len(f.Calls()); n != 1 {
+		t.Errorf("ran %d commands, want only stat", n)
+	}
+}
+
+func TestUnixMode(t *testing.T) {
+	for raw, want := range map[uint32]os.FileMode{
+		0o100644: 0o644,
+		0o104755: os.ModeSetuid | 0o755,
+		0o041777: os.ModeDir | os.ModeSticky | 0o777,
+		0o120777: os.ModeSymlink | 0o777,
+		0o020620: os.ModeDevice | os.ModeCharDevice | 0o620,
+	} {
+		if got := unixMode(raw); got != want {
+			t.Errorf("unixMode(%o) = %v, want %v", raw, got, want)
+		}
+	}
+}
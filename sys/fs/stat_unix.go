@@ -0,0 +1,39 @@
+//go:build unix
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// statDirect is the root path: lstat(2) and the xattr syscalls.
+func statDirect(path string) (FileInfo, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return FileInfo{}, err // os.Lstat wraps os.ErrNotExist for a missing path
+	}
+	info := FileInfo{Mode: fi.Mode(), Size: fi.Size(), ModTime: fi.ModTime()}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		info.Owner, info.Group = idNames(st.Uid, st.Gid)
+	}
+	if info.Xattrs, err = listXattrs(path); err != nil {
+		return FileInfo{}, fmt.Errorf("xattrs %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// idNames resolves a uid and gid against the user and group database; an id
+// with no name is left empty.
+func idNames(uid, gid uint32) (owner, group string) {
+	if u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10)); err == nil {
+		owner = u.Username
+	}
+	if g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10)); err == nil {
+		group = g.Name
+	}
+	return owner, group
+}
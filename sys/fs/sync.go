@@ -0,0 +1,420 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+)
+
+// SyncMarker is the file SyncTree keeps at the root of a tree it deletes
+// from. Its presence is what makes a destination managed.
+const SyncMarker = ".power-manage-sync"
+
+// ErrUnmanagedTree is returned by SyncTree with Delete set when the
+// destination already holds entries but no SyncMarker: it was not created
+// by a deleting sync, so nothing in it is removed.
+var ErrUnmanagedTree = errors.New("sync destination is not managed")
+
+// SyncOptions configures a Manager.SyncTree call.
+type SyncOptions struct {
+	// FileMode and DirMode, when non-zero, are enforced on every synced file
+	// and directory. Zero keeps the source entry's mode.
+	FileMode, DirMode os.FileMode
+	// Owner and Group, when either is set, are enforced on every synced
+	// entry. Both empty keeps the source entry's owner and group.
+	Owner, Group string
+	// Delete removes destination entries the source does not have. It only
+	// acts on a tree that carries SyncMarker, and marks an empty or new
+	// destination on the first sync.
+	Delete bool
+}
+
+// SyncSummary is what a SyncTree call changed, as destination paths sorted
+// within each list. An entry whose content matched but whose mode or
+// ownership was corrected counts as updated.
+type SyncSummary struct {
+	Created, Updated, Deleted []string
+	// Skipped lists source entries that are neither regular files nor
+	// directories (symlinks, devices, sockets); they are not synced.
+	Skipped   []string
+	Unchanged int
+}
+
+// Changed reports whether the sync created, updated or deleted anything.
+func (s SyncSummary) Changed() bool {
+	return len(s.Created)+len(s.Updated)+len(s.Deleted) > 0
+}
+
+// String renders one "created|updated|deleted|skipped <path>" line per
+// entry, then a count line.
+func (s SyncSummary) String() string {
+	var b strings.Builder
+	for _, l := range []struct {
+		verb  string
+		paths []string
+	}{{"created", s.Created}, {"updated", s.Updated}, {"deleted", s.Deleted}, {"skipped", s.Skipped}} {
+		for _, p := range l.paths {
+			fmt.Fprintf(&b, "%s %s\n", l.verb, p)
+		}
+	}
+	fmt.Fprintf(&b, "%d created, %d updated, %d deleted, %d unchanged\n",
+		len(s.Created), len(s.Updated), len(s.Deleted), s.Unchanged)
+	return b.String()
+}
+
+// Output is the summary as ActionResult output, cut to the size its
+// stdout field accepts.
+func (s SyncSummary) Output() *pb.CommandOutput {
+	out := s.String()
+	if len(out) > pmexec.MaxOutputBytes {
+		out = strings.ToValidUTF8(out[:pmexec.MaxOutputBytes], "")
+	}
+	return &pb.CommandOutput{Stdout: out}
+}
+
+type syncKind int
+
+const (
+	syncMkdir syncKind = iota
+	syncWrite
+	syncMeta
+	syncRemove
+)
+
+// syncOp is one planned change to the destination.
+type syncOp struct {
+	kind     syncKind
+	dst, src string
+	// replace removes a destination entry of the wrong type first.
+	replace      bool
+	mode         os.FileMode
+	owner, group string
+	setMode      bool
+	setOwner     bool
+}
+
+// SyncTree makes dst a copy of src, comparing by size and then SHA-256 so
+// only changed files are rewritten, each through an atomic, symlink-safe
+// replace (copyFile). Everything is read and compared before the first
+// change, so a bad source (a setuid file without a FileMode override) fails
+// with the destination untouched. Symlinks are never
+// followed: a source symlink is skipped, a destination one is replaced.
+func (m *manager) SyncTree(ctx context.Context, src, dst string, opts SyncOptions) (SyncSummary, error) {
+	var sum SyncSummary
+	if err := ValidatePath(src); err != nil {
+		return sum, err
+	}
+	if err := ValidatePath(dst); err != nil {
+		return sum, err
+	}
+	if err := validateMode(opts.FileMode); err != nil {
+		return sum, err
+	}
+	if err := validateMode(opts.DirMode); err != nil {
+		return sum, err
+	}
+	src, dst = filepath.Clean(src), filepath.Clean(dst)
+	if opts.Delete && IsProtectedPath(dst) {
+		return sum, fmt.Errorf("%w: %s", ErrProtectedTarget, dst)
+	}
+
+	srcInfo, err := m.Stat(ctx, src)
+	if err != nil {
+		return sum, fmt.Errorf("sync %s: %w", src, err)
+	}
+	if !srcInfo.Mode.IsDir() {
+		return sum, fmt.Errorf("sync %s: not a directory", src)
+	}
+	p := &syncPlan{m: m, opts: opts, sum: &sum}
+	mark, err := p.root(ctx, srcInfo, src, dst)
+	if err != nil {
+		return SyncSummary{}, err
+	}
+	if err := p.apply(ctx); err != nil {
+		return sum, err
+	}
+	if mark {
+		if err := m.WriteFile(ctx, filepath.Join(dst, SyncMarker), []byte("managed by power-manage SyncTree\n"), WriteOptions{}); err != nil {
+			return sum, err
+		}
+	}
+	for _, l := range [][]string{sum.Created, sum.Updated, sum.Deleted, sum.Skipped} {
+		sort.Strings(l)
+	}
+	return sum, nil
+}
+
+type syncPlan struct {
+	m    *manager
+	opts SyncOptions
+	sum  *SyncSummary
+	ops  []syncOp
+}
+
+// root plans the destination root and reports whether the marker still has
+// to be written.
+func (p *syncPlan) root(ctx context.Context, srcInfo FileInfo, src, dst string) (bool, error) {
+	dstInfo, exists, err := p.stat(ctx, dst)
+	if err != nil {
+		return false, err
+	}
+	mark := false
+	if p.opts.Delete && exists && dstInfo.Mode.IsDir() {
+		if _, err := p.m.Stat(ctx, filepath.Join(dst, SyncMarker)); errors.Is(err, os.ErrNotExist) {
+			entries, err := p.m.ReadDir(ctx, dst)
+			if err != nil {
+				return false, fmt.Errorf("sync %s: %w", dst, err)
+			}
+			if len(entries) > 0 {
+				return false, fmt.Errorf("%w: %s has no %s", ErrUnmanagedTree, dst, SyncMarker)
+			}
+			mark = true
+		} else if err != nil {
+			return false, fmt.Errorf("sync %s: %w", dst, err)
+		}
+	} else if p.opts.Delete {
+		mark = true
+	}
+	return mark, p.dir(ctx, srcInfo, src, dst, dstInfo, exists, true)
+}
+
+// stat is Stat with absence as a result rather than an error.
+func (p *syncPlan) stat(ctx context.Context, path string) (FileInfo, bool, error) {
+	info, err := p.m.Stat(ctx, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return FileInfo{}, false, nil
+	}
+	if err != nil {
+		return FileInfo{}, false, fmt.Errorf("sync %s: %w", path, err)
+	}
+	return info, true, nil
+}
+
+// dir plans the directory dst from src, then its entries.
+func (p *syncPlan) dir(ctx context.Context, srcInfo FileInfo, src, dst string, dstInfo FileInfo, exists, root bool) error {
+	op, err := p.meta(syncMkdir, srcInfo, p.opts.DirMode, src, dst)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !exists:
+		p.ops = append(p.ops, op)
+		p.sum.Created = append(p.sum.Created, dst)
+	case !dstInfo.Mode.IsDir():
+		op.replace = true
+		p.ops = append(p.ops, op)
+		p.sum.Updated = append(p.sum.Updated, dst)
+		exists = false
+	default:
+		p.fixMeta(op, dstInfo)
+	}
+
+	srcEntries, err := p.m.ReadDir(ctx, src)
+	if err != nil {
+		return fmt.Errorf("sync %s: %w", src, err)
+	}
+	seen := map[string]bool{}
+	sort.Slice(srcEntries, func(i, j int) bool { return srcEntries[i].Name < srcEntries[j].Name })
+	for _, e := range srcEntries {
+		if root && e.Name == SyncMarker {
+			continue
+		}
+		seen[e.Name] = true
+		if err := p.entry(ctx, joinPath(src, e.Name), joinPath(dst, e.Name), exists); err != nil {
+			return err
+		}
+	}
+	if !exists || !p.opts.Delete {
+		return nil
+	}
+	dstEntries, err := p.m.ReadDir(ctx, dst)
+	if err != nil {
+		return fmt.Errorf("sync %s: %w", dst, err)
+	}
+	for _, e := range dstEntries {
+		if seen[e.Name] || (root && e.Name == SyncMarker) {
+			continue
+		}
+		path := joinPath(dst, e.Name)
+		p.ops = append(p.ops, syncOp{kind: syncRemove, dst: path})
+		p.sum.Deleted = append(p.sum.Deleted, path)
+	}
+	return nil
+}
+
+// entry plans one source entry. parentExists is false when the destination
+// directory is itself still to be created, so nothing under it can exist.
+func (p *syncPlan) entry(ctx context.Context, src, dst string, parentExists bool) error {
+	srcInfo, err := p.m.Stat(ctx, src)
+	if err != nil {
+		return fmt.Errorf("sync %s: %w", src, err)
+	}
+	var dstInfo FileInfo
+	exists := false
+	if parentExists {
+		if dstInfo, exists, err = p.stat(ctx, dst); err != nil {
+			return err
+		}
+	}
+	switch {
+	case srcInfo.Mode.IsDir():
+		return p.dir(ctx, srcInfo, src, dst, dstInfo, exists, false)
+	case srcInfo.Mode.IsRegular():
+		return p.file(ctx, srcInfo, src, dst, dstInfo, exists)
+	default:
+		p.sum.Skipped = append(p.sum.Skipped, src)
+		return nil
+	}
+}
+
+func (p *syncPlan) file(ctx context.Context, srcInfo FileInfo, src, dst string, dstInfo FileInfo, exists bool) error {
+	op, err := p.meta(syncWrite, srcInfo, p.opts.FileMode, src, dst)
+	if err != nil {
+		return err
+	}
+	same := false
+	if exists && dstInfo.Mode.IsRegular() && dstInfo.Size == srcInfo.Size {
+		a, err := p.m.HashFile(ctx, src)
+		if err != nil {
+			return err
+		}
+		b, err := p.m.HashFile(ctx, dst)
+		if err != nil {
+			return err
+		}
+		same = a == b
+	}
+	if same {
+		p.fixMeta(op, dstInfo)
+		return nil
+	}
+	op.replace = exists && !dstInfo.Mode.IsRegular()
+	p.ops = append(p.ops, op)
+	if exists {
+		p.sum.Updated = append(p.sum.Updated, dst)
+	} else {
+		p.sum.Created = append(p.sum.Created, dst)
+	}
+	return nil
+}
+
+// meta builds an op carrying the mode and ownership dst should end up with:
+// the enforced ones, or the source entry's. A source mode with setuid or
+// setgid is refused here, before anything changes, as validateMode would
+// refuse it later.
+func (p *syncPlan) meta(kind syncKind, srcInfo FileInfo, enforced os.FileMode, src, dst string) (syncOp, error) {
+	op := syncOp{kind: kind, src: src, dst: dst, mode: enforced, setMode: true}
+	if op.mode == 0 {
+		op.mode = srcInfo.Mode & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	}
+	op.owner, op.group = p.opts.Owner, p.opts.Group
+	if op.owner == "" && op.group == "" {
+		op.owner, op.group = srcInfo.Owner, srcInfo.Group
+	}
+	op.setOwner = op.owner != "" || op.group != ""
+	if err := validateMode(op.mode); err != nil {
+		return syncOp{}, fmt.Errorf("sync %s: %w", src, err)
+	}
+	return op, nil
+}
+
+// fixMeta queues a metadata correction for an existing entry whose mode or
+// ownership differs from op's, or counts it unchanged.
+func (p *syncPlan) fixMeta(op syncOp, have FileInfo) {
+	haveMode := have.Mode & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	op.setMode = haveMode != op.mode
+	op.setOwner = (op.owner != "" && op.owner != have.Owner) || (op.group != "" && op.group != have.Group)
+	if !op.setMode && !op.setOwner {
+		p.sum.Unchanged++
+		return
+	}
+	op.kind = syncMeta
+	p.ops = append(p.ops, op)
+	p.sum.Updated = append(p.sum.Updated, op.dst)
+}
+
+// apply performs the planned ops in order: each directory before its
+// entries, deletions after the entries they sit beside.
+func (p *syncPlan) apply(ctx context.Context) error {
+	for _, op := range p.ops {
+		if op.replace || op.kind == syncRemove {
+			if err := p.m.removeEntry(ctx, op.dst); err != nil {
+				return fmt.Errorf("sync %s: %w", op.dst, err)
+			}
+		}
+		var err error
+		switch op.kind {
+		case syncMkdir:
+			err = p.m.Mkdir(ctx, op.dst, MkdirOptions{Mode: op.mode, Owner: op.owner, Group: op.group, Recursive: true})
+		case syncWrite:
+			err = p.m.copyFile(ctx, op.src, op.dst, op.mode, op.owner, op.group)
+		case syncMeta:
+			if op.setMode {
+				err = p.m.SetMode(ctx, op.dst, op.mode)
+			}
+			if err == nil && op.setOwner {
+				err = p.m.SetOwnership(ctx, op.dst, op.owner, op.group)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("sync %s: %w", op.dst, err)
+		}
+	}
+	return nil
+}
+
+// copyFile replaces dst with a copy of src. On the Direct backend the content
+// goes through WriteFile's fd-safe replace; escalated, root copies it with cp,
+// since reading it back through the Runner is capped and not byte-exact.
+func (m *manager) copyFile(ctx context.Context, src, dst string, mode os.FileMode, owner, group string) error {
+	if !m.direct() {
+		return m.copyEscalated(ctx, src, dst, mode, owner, group)
+	}
+	data, err := m.ReadFile(ctx, src)
+	if err != nil {
+		return err
+	}
+	return m.WriteFile(ctx, dst, data, WriteOptions{Mode: mode, Owner: owner, Group: group})
+}
+
+// removeEntry deletes path without following symlinks: a non-directory
+// with rm, a directory bottom-up with rm and rmdir. Unlike RemoveDir it is
+// not limited to paths outside the protected prefixes, since SyncTree only
+// deletes inside a tree carrying its marker.
+func (m *manager) removeEntry(ctx context.Context, path string) error {
+	info, err := m.Stat(ctx, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.Mode.IsDir() {
+		return m.Remove(ctx, path)
+	}
+	entries, err := m.ReadDir(ctx, path)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := m.removeEntry(ctx, joinPath(path, e.Name)); err != nil {
+			return err
+		}
+	}
+	return m.runChecked(ctx, "rmdir", "--", path)
+}
+
+// joinPath appends name to a clean directory path.
+func joinPath(dir, name string) string {
+	if dir == "/" {
+		return "/" + name
+	}
+	return dir + "/" + name
+}
@@ -0,0 +1,230 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, body := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSyncTree_Direct(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTree(t, src, map[string]string{"same": "a\n", "changed": "new\n", "added": "x\n", "sub/f": "f\n", "chmod": "c\n"})
+	writeTree(t, dst, map[string]string{"same": "a\n", "changed": "old\n", "sub/f": "f\n", "chmod": "c\n", "extra": "e\n", SyncMarker: ""})
+	if err := os.Chmod(filepath.Join(dst, "chmod"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("same", filepath.Join(src, "link")); err != nil {
+		t.Fatal(err)
+	}
+	f := exectest.New(pmexec.Direct)
+	m := mustManager(t, f)
+
+	sum, err := m.SyncTree(context.Background(), src, dst, SyncOptions{Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{dst + "/added"}; !slices.Equal(sum.Created, want) {
+		t.Errorf("Created = %q, want %q", sum.Created, want)
+	}
+	if want := []string{dst + "/changed", dst + "/chmod"}; !slices.Equal(sum.Updated, want) {
+		t.Errorf("Updated = %q, want %q", sum.Updated, want)
+	}
+	if want := []string{dst + "/extra"}; !slices.Equal(sum.Deleted, want) {
+		t.Errorf("Deleted = %q, want %q", sum.Deleted, want)
+	}
+	if want := []string{src + "/link"}; !slices.Equal(sum.Skipped, want) {
+		t.Errorf("Skipped = %q, want %q", sum.Skipped, want)
+	}
+	// The root, sub/ and sub/f, and same.
+	if sum.Unchanged != 4 || !sum.Changed() {
+		t.Errorf("Unchanged = %d, Changed = %v", sum.Unchanged, sum.Changed())
+	}
+	for rel, want := range map[string]string{"changed": "new\n", "added": "x\n"} {
+		if b, _ := os.ReadFile(filepath.Join(dst, rel)); string(b) != want {
+			t.Errorf("%s = %q, want %q", rel, b, want)
+		}
+	}
+	var got []string
+	for _, c := range f.Calls() {
+		got = append(got, argv(c))
+	}
+	want := []string{"chmod 0644 -- " + dst + "/chmod", "rm -f -- " + dst + "/extra"}
+	if !slices.Equal(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+	if out := sum.Output().GetStdout(); !strings.Contains(out, "deleted "+dst+"/extra\n") ||
+		!strings.HasSuffix(out, "1 created, 2 updated, 1 deleted, 4 unchanged\n") {
+		t.Errorf("Output = %q", out)
+	}
+}
+
+func TestSyncTree_DeleteNeedsMarker(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTree(t, src, map[string]string{"a": "a\n"})
+	writeTree(t, dst, map[string]string{"mine": "keep\n"})
+	f := exectest.New(pmexec.Direct)
+	m := mustManager(t, f)
+
+	if _, err := m.SyncTree(context.Background(), src, dst, SyncOptions{Delete: true}); !errors.Is(err, ErrUnmanagedTree) {
+		t.Fatalf("err = %v, want ErrUnmanagedTree", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a refused sync still wrote into dst")
+	}
+
+	// Without Delete the same tree syncs and dst-only files stay.
+	sum, err := m.SyncTree(context.Background(), src, dst, SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sum.Deleted) != 0 || len(sum.Created) != 1 {
+		t.Errorf("summary = %+v", sum)
+	}
+	if _, err := os.Stat(filepath.Join(dst, SyncMarker)); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a non-deleting sync marked the tree")
+	}
+}
+
+func TestSyncTree_MarksEmptyDestination(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	writeTree(t, src, map[string]string{"a": "a\n"})
+	m := mustManager(t, exectest.New(pmexec.Direct))
+	if _, err := m.SyncTree(context.Background(), src, dst, SyncOptions{Delete: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, SyncMarker)); err != nil {
+		t.Errorf("marker: %v", err)
+	}
+}
+
+func TestSyncTree_RefusesBeforeChanging(t *testing.T) {
+	t.Run("setuid source", func(t *testing.T) {
+		src, dst := t.TempDir(), t.TempDir()
+		writeTree(t, src, map[string]string{"a": "a\n", "b": "b\n"})
+		if err := os.Chmod(filepath.Join(src, "b"), 0o755|os.ModeSetuid); err != nil {
+			t.Fatal(err)
+		}
+		m := mustManager(t, exectest.New(pmexec.Direct))
+		if _, err := m.SyncTree(context.Background(), src, dst, SyncOptions{}); !errors.Is(err, ErrUnsafeMode) {
+			t.Fatalf("err = %v, want ErrUnsafeMode", err)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "a")); !errors.Is(err, os.ErrNotExist) {
+			t.Error("a was written before the plan failed")
+		}
+	})
+
+}
+
+// Escalated, root copies the file itself: reading it back through the Runner
+// would be capped and not byte-exact.
+func TestSyncTree_EscalatedCopiesWithCp(t *testing.T) {
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "41ed 4096 1 root root\n"}, nil) // stat /src
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{Stdout: "41ed 4096 1 root root\n"}, nil) // stat /etc
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{Stdout: "f/big\n"}, nil)                   // find /src
+	f.Push(pmexec.Result{Stdout: "81a4 2097152 1 root adm\n"}, nil) // stat /src/big
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{ExitCode: 1, Stderr: "stat: cannot statx '/etc/big': No such file or directory"}, nil)
+	f.Push(pmexec.Result{}, nil) // copy
+	m := mustManager(t, f)
+
+	got, err := m.SyncTree(context.Background(), "/src", "/etc", SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/etc/big"}; !slices.Equal(got.Created, want) {
+		t.Errorf("Created = %q, want %q", got.Created, want)
+	}
+	calls := f.Calls()
+	last := calls[len(calls)-1]
+	want := []string{"-c", escalatedCopyScript, "sh", "/src/big", "/etc/big", "0644", "root:adm"}
+	if last.Name != "sh" || !slices.Equal(last.Args, want) || !last.Escalate {
+		t.Errorf("copy = %q, want sh %q", argv(last), want)
+	}
+	for _, c := range calls {
+		if c.Name == "cat" {
+			t.Errorf("read %q back through the Runner", argv(c))
+		}
+	}
+}
+
+// The copy script itself keeps every byte: CRLF, no final newline, NULs and
+// more than the Runner's output limit.
+func TestEscalatedCopyScript_ByteExact(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the escalated copy script targets GNU cp")
+	}
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	body := append([]byte("a\r\nb\x00"), bytes.Repeat([]byte{0xff, '\n'}, pmexec.MaxOutputBytes)...)
+	if err := os.WriteFile(src, body, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := osexec.Command("sh", "-c", escalatedCopyScript, "sh", src, dst, "0640", "").CombinedOutput(); err != nil {
+		t.Fatalf("copy script: %v: %s", err, out)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("copy is %d bytes, want the %d-byte source unchanged", len(got), len(body))
+	}
+	if info, err := os.Stat(dst); err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("copy mode = %v, %v; want 0640", info.Mode(), err)
+	}
+}
+
+func TestSyncTree_EscalatedHashesWithSha256sum(t *testing.T) {
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	f := exectest.New(pmexec.Sudo)
+	f.Push(pmexec.Result{Stdout: "41ed 4096 1 root root\n"}, nil) // stat /src
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{Stdout: "41ed 4096 1 root root\n"}, nil) // stat /dst
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{Stdout: "f/a\n"}, nil)                // find /src
+	f.Push(pmexec.Result{Stdout: "81a4 5 1 root root\n"}, nil) // stat /src/a
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{Stdout: "81a4 5 2 root root\n"}, nil) // stat /dst/a
+	f.Push(pmexec.Result{}, nil)
+	f.Push(pmexec.Result{Stdout: sum + "  /src/a\n"}, nil)
+	f.Push(pmexec.Result{Stdout: sum + "  /dst/a\n"}, nil)
+	m := mustManager(t, f)
+
+	got, err := m.SyncTree(context.Background(), "/src", "/dst", SyncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Changed() || got.Unchanged != 2 {
+		t.Errorf("summary = %+v, want everything unchanged", got)
+	}
+	calls := f.Calls()
+	if a := argv(calls[len(calls)-1]); a != "sha256sum -- /dst/a" {
+		t.Errorf("last command = %q", a)
+	}
+}
@@ -0,0 +1,408 @@
+package fs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// MaxRenderedSize bounds a rendered template, the same 10 MiB a FileParams
+// content may hold verbatim.
+const MaxRenderedSize = 10 << 20
+
+// maxRangeDepth is how deeply ranges may nest, and maxRangeIterations how
+// many range bodies a render may run at most, counted against the largest
+// collection in the facts. Together they keep a few bytes of template from
+// looping over the labels for minutes.
+const (
+	maxRangeDepth      = 2
+	maxRangeIterations = 1 << 17
+)
+
+// ErrInvalidTemplate is returned by Render for content that does not parse,
+// or that uses anything outside the template subset: another function than
+// templateFuncs and the comparison/formatting builtins, a nested template,
+// a range over anything but a fact, ranges nested deeper than two, or more
+// range iterations than the facts allow.
+var ErrInvalidTemplate = errors.New("invalid file template")
+
+// ErrMissingFact is returned by Render when the template reads a fact the
+// device does not have. A missing label is an error, never an empty string
+// written into a config file.
+var ErrMissingFact = errors.New("template reads a missing fact")
+
+// Facts are what a file template reads: {{.hostname}}, {{.os.id}},
+// {{.labels.role}}, or {{label "team-name"}} and {{fact .interfaces "br-lan"}}
+// for a key that is not an identifier. Values are strings, string slices
+// and maps of them, never numbers: a template ranges over facts, and a range
+// over a number loops that many times. inventory.Facts builds them for the local device.
+type Facts map[string]any
+
+// Rendered is a rendered file template.
+type Rendered struct {
+	Content []byte
+	// Digest is the hex sha256 of Content. It changes exactly when the
+	// rendered file does, so comparing it to ContentDigest of the file on
+	// disk detects drift without keeping the template's output around.
+	Digest string
+}
+
+// templateFuncs are the functions a template may call beyond the builtins
+// in allowedBuiltins. None of them reaches outside the facts. Those that
+// build a string charge it to b before returning it, so a chain of them
+// fails once it has built MaxRenderedSize bytes instead of growing a string
+// the output could never hold. print, printf and println replace the
+// builtins of the same name for that reason. label and fact stand in for
+// the index builtin, which missingkey=error does not reach: a key they do
+// not find is ErrMissingFact, not an empty string.
+func templateFuncs(b *funcBudget, facts Facts) template.FuncMap {
+	return template.FuncMap{
+		"label": func(key string) (any, error) {
+			labels, ok := facts["labels"]
+			if !ok {
+				return nil, fmt.Errorf("%w: no labels", ErrMissingFact)
+			}
+			return lookupFact(labels, key)
+		},
+		"fact":      lookupFact,
+		"lower":     func(s string) (string, error) { return b.keep(strings.ToLower(s)) },
+		"upper":     func(s string) (string, error) { return b.keep(strings.ToUpper(s)) },
+		"trim":      strings.TrimSpace,
+		"hasPrefix": strings.HasPrefix,
+		"hasSuffix": strings.HasSuffix,
+		"replace": func(s, old, new string) (string, error) {
+			if old == "" {
+				// ReplaceAll would insert new between every rune.
+				return "", fmt.Errorf("%w: replace with an empty old string", ErrInvalidTemplate)
+			}
+			n := len(s)
+			if grow := len(new) - len(old); grow > 0 {
+				count := strings.Count(s, old)
+				if count > 0 && grow > (b.left-n)/count {
+					return "", errRenderTooLarge
+				}
+				n += count * grow
+			}
+			if err := b.charge(n); err != nil {
+				return "", err
+			}
+			return strings.ReplaceAll(s, old, new), nil
+		},
+		"join": func(elems []string, sep string) (string, error) {
+			n := len(sep) * max(len(elems)-1, 0)
+			for _, e := range elems {
+				n += len(e)
+			}
+			if err := b.charge(n); err != nil {
+				return "", err
+			}
+			return strings.Join(elems, sep), nil
+		},
+		"print":   func(args ...any) (string, error) { return b.keep(fmt.Sprint(args...)) },
+		"println": func(args ...any) (string, error) { return b.keep(fmt.Sprintln(args...)) },
+		"printf": func(format string, args ...any) (string, error) {
+			// A width or precision pads the result; charge the padding
+			// before fmt allocates it.
+			if err := b.charge(formatPadding(format)); err != nil {
+				return "", err
+			}
+			return b.keep(fmt.Sprintf(format, args...))
+		},
+	}
+}
+
+// templateFuncNames are the names templateFuncs defines, for checkNode.
+var templateFuncNames = func() map[string]bool {
+	names := map[string]bool{}
+	for name := range templateFuncs(nil, nil) {
+		names[name] = true
+	}
+	return names
+}()
+
+// allowedBuiltins are the text/template builtins a template may call. call
+// is left out: facts hold no functions, and a template must not call any.
+// index is left out for label and fact.
+var allowedBuiltins = map[string]bool{
+	"and": true, "or": true, "not": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+	"len": true, "slice": true,
+	"print": true, "printf": true, "println": true,
+}
+
+// Render renders content, a text/template, against facts. The subset has
+// no file, exec or network access; a missing fact fails with ErrMissingFact
+// rather than rendering as "<no value>". The output is bounded by
+// MaxRenderedSize.
+func Render(content string, facts Facts) (Rendered, error) {
+	budget := &funcBudget{left: MaxRenderedSize}
+	t, err := template.New("file").Funcs(templateFuncs(budget, facts)).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return Rendered{}, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if len(t.Templates()) > 1 {
+		return Rendered{}, fmt.Errorf("%w: define and block are not supported", ErrInvalidTemplate)
+	}
+	if t.Tree != nil {
+		var loops rangeLoops
+		if err := loops.checkNode(t.Tree.Root); err != nil {
+			return Rendered{}, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		if n := loops.iterations(largestCollection(reflect.ValueOf(facts))); n > maxRangeIterations {
+			return Rendered{}, fmt.Errorf("%w: ranges could run %d times over these facts, more than %d", ErrInvalidTemplate, n, maxRangeIterations)
+		}
+	}
+	out := &cappedBuffer{max: MaxRenderedSize}
+	if err := t.Execute(out, map[string]any(facts)); err != nil {
+		if errors.Is(err, errRenderTooLarge) {
+			return Rendered{}, fmt.Errorf("%w: output or function results exceed %d bytes", ErrInvalidTemplate, MaxRenderedSize)
+		}
+		// text/template reports a missing map key in its message alone.
+		if strings.Contains(err.Error(), "map has no entry for key") {
+			return Rendered{}, fmt.Errorf("%w: %v", ErrMissingFact, err)
+		}
+		return Rendered{}, fmt.Errorf("render template: %w", err)
+	}
+	return Rendered{Content: out.Bytes(), Digest: ContentDigest(out.Bytes())}, nil
+}
+
+// lookupFact is the strict index behind label and fact: a string key of a
+// map or an int index of a list, where a key the map lacks or an index past
+// the end is ErrMissingFact.
+func lookupFact(from any, key any) (any, error) {
+	v := reflect.ValueOf(from)
+	switch k := key.(type) {
+	case string:
+		if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+			e := v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))
+			if !e.IsValid() {
+				return nil, fmt.Errorf("%w: no key %q", ErrMissingFact, k)
+			}
+			return e.Interface(), nil
+		}
+	case int:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			if k < 0 || k >= v.Len() {
+				return nil, fmt.Errorf("%w: no item %d of %d", ErrMissingFact, k, v.Len())
+			}
+			return v.Index(k).Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: fact reads %v from %T", ErrInvalidTemplate, key, from)
+}
+
+// ContentDigest is the hex sha256 of data, the form of Rendered.Digest.
+func ContentDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// rangeLoops records the ranges checkNode finds, by how deeply each nests.
+type rangeLoops struct {
+	depth  int
+	depths []int
+}
+
+// checkNode walks a parsed template and rejects what the subset leaves out.
+func (l *rangeLoops) checkNode(n parse.Node) error {
+	switch n := n.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Nodes {
+			if err := l.checkNode(c); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return l.checkNode(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			if err := l.checkNode(c); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			if err := l.checkNode(a); err != nil {
+				return err
+			}
+		}
+	case *parse.IdentifierNode:
+		if !templateFuncNames[n.Ident] && !allowedBuiltins[n.Ident] {
+			return fmt.Errorf("function %q is not available", n.Ident)
+		}
+	case *parse.IfNode:
+		return l.checkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return l.checkBranch(&n.BranchNode)
+	case *parse.ChainNode:
+		return l.checkNode(n.Node)
+	case *parse.RangeNode:
+		if !rangesOverFact(n.Pipe) {
+			return errors.New("range must iterate a fact, such as .labels")
+		}
+		if l.depth == maxRangeDepth {
+			return fmt.Errorf("ranges nest deeper than %d", maxRangeDepth)
+		}
+		l.depth++
+		l.depths = append(l.depths, l.depth)
+		err := l.checkBranch(&n.BranchNode)
+		l.depth--
+		return err
+	case *parse.TemplateNode:
+		return fmt.Errorf("template %q: nested templates are not supported", n.Name)
+	}
+	return nil
+}
+
+// rangesOverFact reports whether a range pipeline is a bare field: .x,
+// $.x or $v.x. text/template also ranges over an integer, so a computed or
+// literal bound would let a few bytes of template spin for as long as they
+// like; facts hold no integers, so a field bounds the loop by the facts.
+func rangesOverFact(p *parse.PipeNode) bool {
+	if len(p.Cmds) != 1 || len(p.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch a := p.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return len(a.Ident) > 1
+	}
+	return false
+}
+
+func (l *rangeLoops) checkBranch(b *parse.BranchNode) error {
+	for _, n := range []parse.Node{b.Pipe, b.List, b.ElseList} {
+		if err := l.checkNode(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterations bounds how many range bodies run when no collection in the
+// facts holds more than size elements: a range at depth d runs at most
+// size^d times.
+func (l *rangeLoops) iterations(size int) int {
+	total := 0
+	for _, d := range l.depths {
+		n := 1
+		for range d {
+			n = min(n*max(size, 1), maxRangeIterations+1)
+		}
+		total = min(total+n, maxRangeIterations+1)
+	}
+	return total
+}
+
+// largestCollection is the length of the longest map or slice in v.
+func largestCollection(v reflect.Value) int {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+	n := 0
+	switch v.Kind() {
+	case reflect.Map:
+		n = v.Len()
+		for it := v.MapRange(); it.Next(); {
+			n = max(n, largestCollection(it.Value()))
+		}
+	case reflect.Slice, reflect.Array:
+		n = v.Len()
+		for i := range v.Len() {
+			n = max(n, largestCollection(v.Index(i)))
+		}
+	}
+	return n
+}
+
+// fmtMaxWidth is the largest width or precision fmt honours.
+const fmtMaxWidth = 1e6
+
+// formatPadding is the most padding the widths and precisions in a printf
+// format can add.
+func formatPadding(format string) int {
+	pad := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+	spec:
+		for i++; i < len(format); i++ {
+			switch c := format[i]; {
+			case c == '[':
+				// An argument index, not a width.
+				for i < len(format) && format[i] != ']' {
+					i++
+				}
+			case c == '*':
+				pad += fmtMaxWidth
+			case c >= '1' && c <= '9':
+				n := 0
+				for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+					n = min(n*10+int(format[i]-'0'), fmtMaxWidth)
+				}
+				pad += n
+				i--
+			case strings.IndexByte("+-# 0.", c) < 0:
+				break spec // the verb
+			}
+		}
+	}
+	return pad
+}
+
+var errRenderTooLarge = errors.New("rendered output too large")
+
+// funcBudget is how many bytes template functions may still build in one
+// render.
+type funcBudget struct {
+	left int
+}
+
+func (b *funcBudget) charge(n int) error {
+	if n > b.left {
+		return errRenderTooLarge
+	}
+	b.left -= n
+	return nil
+}
+
+// keep charges s, already built, and returns it.
+func (b *funcBudget) keep(s string) (string, error) {
+	if err := b.charge(len(s)); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// cappedBuffer fails a write that would grow it past max, which stops the
+// template mid-execution.
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, errRenderTooLarge
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,129 @@
+package fs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFacts() Facts {
+	return Facts{
+		"hostname": "web01",
+		"os":       map[string]any{"id": "debian", "version_id": "12"},
+		"labels":   map[string]string{"role": "web", "team-name": "ops"},
+		"interfaces": map[string]any{
+			"eth0": map[string]any{"ipv4": []string{"10.0.0.5"}},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, tt := range []struct {
+		name, tmpl, want string
+	}{
+		{"plain content", "listen 80;\n", "listen 80;\n"},
+		{"facts", "server_name {{.hostname}}.{{.labels.role}};", "server_name web01.web;"},
+		{"label for a dashed key", `{{label "team-name" | upper}}`, "OPS"},
+		{"fact", `{{fact .os "version_id"}} {{fact .interfaces.eth0.ipv4 0}}`, "12 10.0.0.5"},
+		{"conditional", `{{if eq .os.id "debian"}}apt{{else}}dnf{{end}}`, "apt"},
+		{"range over a fact", `{{range $name, $i := .interfaces}}{{$name}}={{join $i.ipv4 ","}}{{end}}`, "eth0=10.0.0.5"},
+		{"range over a variable's field", `{{with .interfaces.eth0}}{{range $.labels}}{{.}} {{end}}{{end}}`, "web ops "},
+	} {
+		r, err := Render(tt.tmpl, testFacts())
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if string(r.Content) != tt.want {
+			t.Errorf("%s: rendered %q, want %q", tt.name, r.Content, tt.want)
+		}
+		if r.Digest != ContentDigest([]byte(tt.want)) {
+			t.Errorf("%s: digest %s does not match the content", tt.name, r.Digest)
+		}
+	}
+}
+
+func TestRender_MissingFact(t *testing.T) {
+	for _, tmpl := range []string{
+		"{{.labels.datacenter}}", "{{.domain}}", "{{.os.codename}}",
+		`{{label "data-center"}}`, `{{fact .labels "data-center"}}`, `{{fact .interfaces.eth0.ipv4 1}}`,
+	} {
+		if _, err := Render(tmpl, testFacts()); !errors.Is(err, ErrMissingFact) {
+			t.Errorf("Render(%q) err = %v, want ErrMissingFact", tmpl, err)
+		}
+	}
+	if _, err := Render(`{{label "role"}}`, Facts{"hostname": "web01"}); !errors.Is(err, ErrMissingFact) {
+		t.Errorf("label without labels: err = %v, want ErrMissingFact", err)
+	}
+}
+
+func TestRender_RejectsOutsideTheSubset(t *testing.T) {
+	for _, tmpl := range []string{
+		"{{.hostname",
+		`{{call .hostname}}`,
+		`{{html .hostname}}`,
+		`{{index .labels "team-name"}}`,
+		`{{fact .hostname "x"}}`,
+		`{{define "x"}}x{{end}}{{template "x"}}`,
+		`{{block "x" .}}x{{end}}`,
+		`{{range 1000000000000}}{{end}}`,
+		`{{range (len .labels)}}{{end}}`,
+		`{{$n := 1000000000000}}{{range $n}}{{end}}`,
+		`{{if true}}{{printf "%s" (call .x)}}{{end}}`,
+	} {
+		if _, err := Render(tmpl, testFacts()); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Render(%q) err = %v, want ErrInvalidTemplate", tmpl, err)
+		}
+	}
+}
+
+func TestRender_OutputBounded(t *testing.T) {
+	facts := Facts{"big": strings.Repeat("x", 1<<20), "list": make([]string, 11)}
+	if _, err := Render("{{range .list}}{{$.big}}{{end}}", facts); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("err = %v, want ErrInvalidTemplate for output past MaxRenderedSize", err)
+	}
+}
+
+func TestRender_RangeLimits(t *testing.T) {
+	labels := map[string]string{}
+	for i := range 256 {
+		labels[strings.Repeat("k", i+1)] = "v"
+	}
+	facts := Facts{"labels": labels}
+	if _, err := Render(`{{range .labels}}{{range $.labels}}{{.}}{{end}}{{end}}`, facts); err != nil {
+		t.Errorf("two nested ranges over 256 labels: %v", err)
+	}
+
+	facts["list"] = make([]string, 1000)
+	start := time.Now()
+	for _, tmpl := range []string{
+		`{{range .labels}}{{range $.labels}}{{range $.labels}}{{end}}{{end}}{{end}}`,
+		`{{range .list}}{{range $.list}}{{end}}{{end}}`,
+	} {
+		if _, err := Render(tmpl, facts); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Render(%q) err = %v, want ErrInvalidTemplate", tmpl, err)
+		}
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("rejecting took %v", d)
+	}
+}
+
+func TestRender_FunctionsBounded(t *testing.T) {
+	facts := Facts{"h": strings.Repeat("w", 1000), "l": make([]string, 200)}
+	for _, tmpl := range []string{
+		`{{replace .h "" "x"}}`,
+		`{{replace (replace (replace (replace .h "w" "wwwwwwwwww") "w" "wwwwwwwwww") "w" "wwwwwwwwww") "w" "wwwwwwwwww"}}`,
+		`{{range .l}}{{len (replace (replace $.h "w" "wwwwwwwwww") "w" "wwwwwwwwww")}}{{end}}`,
+		`{{printf "%999999d%999999d%999999d%999999d%999999d%999999d%999999d%999999d%999999d%999999d%999999d" 1 1 1 1 1 1 1 1 1 1 1}}`,
+	} {
+		if _, err := Render(tmpl, facts); !errors.Is(err, ErrInvalidTemplate) {
+			t.Errorf("Render(%.40q) err = %v, want ErrInvalidTemplate", tmpl, err)
+		}
+	}
+	r, err := Render(`{{replace .h "w" "ab" | len}} {{printf "%5s|%-3d" "x" 7}}`, facts)
+	if err != nil || string(r.Content) != "2000     x|7  " {
+		t.Errorf("Render = %q, %v", r.Content, err)
+	}
+}
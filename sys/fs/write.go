@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	pmexec "github.com/manchtools/power-manage-sdk/sys/exec"
 )
 
-// WriteFile writes data to path atomically, applying opts (mode/ownership, and
-// an optional backup of the prior contents).
+// WriteFile writes data to path atomically, applying opts (mode/ownership, an
+// optional backup of the prior contents, and any Attributes once the new file
+// is in place). With opts.Versions enabled, the prior contents are also kept
+// as a version for Restore, and versions beyond the policy are pruned after
+// the write.
 //
 // When the Runner's backend is Direct — the deployed root agent — the write
 // takes the TOCTOU-safe, fd-anchored path: a random-suffix same-directory temp
@@ -44,10 +49,37 @@ func (m *manager) WriteFile(ctx context.Context, path string, data []byte, opts
 			return fmt.Errorf("%w: backup path must differ from the target path", ErrInvalidPath)
 		}
 	}
+	if err := validateAttributes(opts.Attributes, false); err != nil {
+		return err
+	}
+	if opts.Versions.Keep < 0 || opts.Versions.MaxAge < 0 {
+		return fmt.Errorf("write file %s: backup policy limits must not be negative", path)
+	}
+	if opts.Versions.enabled() {
+		if err := m.saveVersion(ctx, path); err != nil {
+			return err
+		}
+	}
+	if err := m.clearFlags(ctx, path, opts.Attributes); err != nil {
+		return fmt.Errorf("write file %s: %w", path, err)
+	}
 	if m.direct() {
-		return writeDirect(path, data, opts)
+		// The fd-safe path makes no command for a dry run to capture.
+		if !pmexec.Planned(m.r, fmt.Sprintf("write %s (%d bytes)", path, len(data))) {
+			if err := writeDirect(path, data, opts); err != nil {
+				return err
+			}
+		}
+	} else if err := m.writeEscalated(ctx, path, data, opts); err != nil {
+		return err
 	}
-	return m.writeEscalated(ctx, path, data, opts)
+	if err := m.applyAttributes(ctx, path, opts.Attributes, false); err != nil {
+		return err
+	}
+	if opts.Versions.enabled() {
+		return m.pruneVersions(ctx, path, opts.Versions)
+	}
+	return nil
 }
 
 // writeDirect is the fd-based, symlink-safe path (WS6 #2). It runs the syscalls
@@ -128,6 +160,39 @@ mv -T -- "$tmp" "$target"
 trap - EXIT
 `
 
+// copyEscalated is writeEscalated with the content copied from src by root
+// rather than passed on stdin: cp reads the file itself, so the copy is
+// byte-exact and not bound by the Runner's output limit. The same parent
+// check, mktemp and `mv -T` keep it atomic and symlink-safe.
+func (m *manager) copyEscalated(ctx context.Context, src, dst string, mode os.FileMode, owner, group string) error {
+	if err := escalatedParentSafe(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	if err := m.runChecked(ctx, "sh", "-c", escalatedCopyScript,
+		"sh", src, dst, modeArg(mode), Ownership(owner, group)); err != nil {
+		return fmt.Errorf("copy %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
+
+// escalatedCopyScript is escalatedWriteScript filling the temp with
+// `cp --preserve=mode,ownership` from a source file. Positional args:
+// $1=source, $2=target, $3=chmod mode, $4=chown owner (":group" form, "" to
+// keep the source's).
+const escalatedCopyScript = `set -eu
+src=$1; target=$2; mode=$3; owner=$4
+dir=$(dirname -- "$target")
+tmp=$(mktemp "$dir/.pm-XXXXXXXXXX")
+trap 'rm -f -- "$tmp"' EXIT
+cp --preserve=mode,ownership -- "$src" "$tmp"
+chmod "$mode" -- "$tmp"
+if [ -n "$owner" ]; then
+	chown "$owner" -- "$tmp"
+fi
+mv -T -- "$tmp" "$target"
+trap - EXIT
+`
+
 // runChecked runs an escalated command and folds a runner error and a non-zero
 // exit into a single error (nil only on a clean exit).
 func (m *manager) runChecked(ctx context.Context, name string, args ...string) error {
@@ -152,6 +217,12 @@ func (m *manager) Copy(ctx context.Context, src, dst string, opts WriteOptions)
 	if err := validateMode(opts.Mode); err != nil {
 		return err
 	}
+	if err := validateAttributes(opts.Attributes, false); err != nil {
+		return err
+	}
+	if err := m.clearFlags(ctx, dst, opts.Attributes); err != nil {
+		return fmt.Errorf("copy file: %w", err)
+	}
 	if err := m.runChecked(ctx, "cp", "--", src, dst); err != nil {
 		return fmt.Errorf("copy file: %w", err)
 	}
@@ -165,7 +236,7 @@ func (m *manager) Copy(ctx context.Context, src, dst string, opts WriteOptions)
 			return err
 		}
 	}
-	return nil
+	return m.applyAttributes(ctx, dst, opts.Attributes, false)
 }
 
 // CopyTree recursively copies the tree at src to dst, preserving mode, ownership,
@@ -181,7 +252,10 @@ func (m *manager) Copy(ctx context.Context, src, dst string, opts WriteOptions)
 // only (not recursively — the per-file modes from the archive copy are kept),
 // while Owner/Group, if set, are applied RECURSIVELY (the common intent when
 // re-homing a tree copied as root — e.g. skel → a user's home). Both are skipped
-// when unset, leaving the archive-preserved metadata.
+// when unset, leaving the archive-preserved metadata. opts.Attributes apply to
+// the destination root only (a DefaultACL there is inherited by what is created
+// later, not pushed onto the copied entries), except an SELinux label, which is
+// set or restored over the whole tree (chcon -R / restorecon -R).
 func (m *manager) CopyTree(ctx context.Context, src, dst string, opts WriteOptions) error {
 	if err := ValidatePath(src); err != nil {
 		return err
@@ -192,6 +266,12 @@ func (m *manager) CopyTree(ctx context.Context, src, dst string, opts WriteOptio
 	if err := validateMode(opts.Mode); err != nil {
 		return err
 	}
+	if err := validateAttributes(opts.Attributes, true); err != nil {
+		return err
+	}
+	if err := m.clearFlags(ctx, dst, opts.Attributes); err != nil {
+		return fmt.Errorf("copy tree: %w", err)
+	}
 	if err := m.runChecked(ctx, "cp", "-a", "-T", "--", src, dst); err != nil {
 		return fmt.Errorf("copy tree: %w", err)
 	}
@@ -205,7 +285,7 @@ func (m *manager) CopyTree(ctx context.Context, src, dst string, opts WriteOptio
 			return err
 		}
 	}
-	return nil
+	return m.applyAttributes(ctx, dst, opts.Attributes, true)
 }
 
 // SetMode sets the file mode (chmod). The mode is applied exactly as given
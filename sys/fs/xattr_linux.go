@@ -0,0 +1,40 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// listXattrs reads every extended attribute of path, without following a
+// final symlink. A filesystem without xattr support has none.
+func listXattrs(path string) (map[string][]byte, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if errors.Is(err, unix.ENOTSUP) {
+		return nil, nil
+	}
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if size, err = unix.Llistxattr(path, buf); err != nil {
+		return nil, err
+	}
+	attrs := map[string][]byte{}
+	for _, name := range bytes.Split(bytes.TrimRight(buf[:size], "\x00"), []byte{0}) {
+		n, err := unix.Lgetxattr(path, string(name), nil)
+		if errors.Is(err, unix.ENODATA) {
+			continue // removed since the list was read
+		}
+		if err != nil {
+			return nil, err
+		}
+		val := make([]byte, n)
+		if n, err = unix.Lgetxattr(path, string(name), val); err != nil {
+			return nil, err
+		}
+		attrs[string(name)] = val[:n]
+	}
+	return attrs, nil
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package fs
+
+// listXattrs is Linux-only; elsewhere a path reports no extended attributes.
+func listXattrs(string) (map[string][]byte, error) { return nil, nil }
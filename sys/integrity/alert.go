@@ -0,0 +1,112 @@
+package integrity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+)
+
+// DefaultProtected are the paths whose drift is a security event: who may
+// become root, and who may log in.
+var DefaultProtected = []string{
+	"/etc/sudoers",
+	"/etc/sudoers.d",
+	"/etc/doas.conf",
+	"/etc/polkit-1/rules.d",
+	"/etc/ssh",
+	"/etc/pam.d",
+	"/etc/passwd",
+	"/etc/shadow",
+	"/etc/group",
+}
+
+// Limits of a SecurityAlert, from its validate tags.
+const (
+	maxAlertMessage = 1024
+	maxAlertDetail  = 1024
+)
+
+// Alert returns a SECURITY_ALERT_TYPE_FILE_INTEGRITY alert for the changes
+// at or under a protected path, or nil when none are. Its details list the
+// added, removed and modified paths, cut short to fit the alert's limits.
+func (r Report) Alert(protected []string) *pb.SecurityAlert {
+	byKind := map[ChangeKind][]string{}
+	n := 0
+	for _, c := range r.Changes {
+		if !underAny(c.Path, protected) {
+			continue
+		}
+		desc := c.Path
+		if c.Kind == Modified {
+			desc += " (" + strings.Join(c.Fields, ",") + ")"
+		}
+		byKind[c.Kind] = append(byKind[c.Kind], desc)
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	details := map[string]string{
+		"baseline_taken": r.Baseline.Format(time.RFC3339),
+		"scanned":        r.Scanned.Format(time.RFC3339),
+	}
+	var summary []string
+	for _, k := range []ChangeKind{Added, Removed, Modified} {
+		if paths := byKind[k]; len(paths) > 0 {
+			details[k.String()] = joinCapped(paths, maxAlertDetail)
+			summary = append(summary, fmt.Sprintf("%d %s", len(paths), k))
+		}
+	}
+	msg := fmt.Sprintf("%d protected file(s) drifted from the integrity baseline: %s", n, strings.Join(summary, ", "))
+	return &pb.SecurityAlert{
+		Type:    pb.SecurityAlertType_SECURITY_ALERT_TYPE_FILE_INTEGRITY,
+		Message: capString(msg, maxAlertMessage),
+		Details: details,
+	}
+}
+
+// underAny reports whether p is one of prefixes or below one.
+func underAny(p string, prefixes []string) bool {
+	for _, pre := range prefixes {
+		if p == pre || strings.HasPrefix(p, strings.TrimSuffix(pre, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// joinCapped joins items with ", ", ending in "+N more" instead of going
+// past max bytes.
+func joinCapped(items []string, max int) string {
+	out := ""
+	for i, it := range items {
+		next := it
+		if i > 0 {
+			next = out + ", " + it
+		}
+		tail := ""
+		if rest := len(items) - i - 1; rest > 0 {
+			tail = fmt.Sprintf(" +%d more", rest)
+		}
+		if len(next)+len(tail) > max {
+			return capString(strings.TrimSpace(fmt.Sprintf("%s +%d more", out, len(items)-i)), max)
+		}
+		out = next
+	}
+	return out
+}
+
+// capString cuts s to at most max bytes without splitting a UTF-8 sequence.
+func capString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
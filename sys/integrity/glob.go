@@ -0,0 +1,69 @@
+package integrity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/manchtools/power-manage-sdk/sys/fs"
+)
+
+// validatePattern checks that p is an absolute, clean path whose segments
+// are valid globs.
+func validatePattern(p string) error {
+	if err := fs.ValidatePath(p); err != nil {
+		return fmt.Errorf("%w: %q: %v", ErrInvalidPattern, p, err)
+	}
+	if !strings.HasPrefix(p, "/") || path.Clean(p) != p {
+		return fmt.Errorf("%w: %q is not an absolute, clean path", ErrInvalidPattern, p)
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Errorf("%w: %q: %v", ErrInvalidPattern, p, err)
+	}
+	return nil
+}
+
+func hasMeta(s string) bool { return strings.ContainsAny(s, `*?[\`) }
+
+// expand resolves a pattern to the existing paths it matches, one segment at
+// a time through ReadDir. A pattern without glob characters is returned as
+// it is.
+func (m *monitor) expand(ctx context.Context, pattern string) ([]string, error) {
+	if !hasMeta(pattern) {
+		return []string{pattern}, nil
+	}
+	dirs := []string{"/"}
+	segs := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	for i, seg := range segs {
+		last := i == len(segs)-1
+		var next []string
+		for _, dir := range dirs {
+			if !hasMeta(seg) {
+				next = append(next, joinPath(dir, seg))
+				continue
+			}
+			entries, err := m.fsm.ReadDir(ctx, dir)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("integrity: expand %s: %w", pattern, err)
+			}
+			for _, e := range entries {
+				// Only directories lead further; a symlink is never
+				// followed.
+				if ok, _ := path.Match(seg, e.Name); ok && (last || e.IsDir) {
+					next = append(next, joinPath(dir, e.Name))
+				}
+			}
+			if len(next) > MaxEntries {
+				return nil, fmt.Errorf("%w: %s matches more than %d", ErrTooManyEntries, pattern, MaxEntries)
+			}
+		}
+		dirs = next
+	}
+	return dirs, nil
+}
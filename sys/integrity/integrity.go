@@ -0,0 +1,358 @@
+// Package integrity detects drift in managed files: it records a baseline of
+// their content hash, mode, ownership, extended attributes and modification
+// time, and reports what was added, removed or modified since.
+//
+//	r, _ := exec.NewRunner(exec.Direct)
+//	store, _ := integrity.NewFileStore(r, integrity.DefaultBaselinePath)
+//	m, err := integrity.New(r, integrity.Config{
+//	    Paths: []string{"/etc/ssh", "/etc/sudoers", "/etc/sudoers.d/*"},
+//	    Store: store,
+//	})
+//	if err != nil { ... }
+//	if _, err := m.Establish(ctx); err != nil { ... }
+//	err = m.Run(ctx, time.Hour, func(ctx context.Context, rep integrity.Report) {
+//	    if alert := rep.Alert(integrity.DefaultProtected); alert != nil {
+//	        _ = client.SendSecurityAlert(ctx, alert)
+//	    }
+//	})
+//
+// Every read goes through fs.Manager, so the scan sees root-only paths such as
+// /etc/sudoers.d on either privilege backend.
+package integrity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/fs"
+)
+
+// MaxEntries bounds how many paths one scan records, so a pattern that
+// matches far more than intended fails instead of walking the whole disk.
+const MaxEntries = 50000
+
+// ErrNoBaseline is returned by Check and Run, and by Store.Load, before a
+// baseline has been saved.
+var ErrNoBaseline = errors.New("integrity: no baseline")
+
+// ErrInvalidPattern is returned for a path pattern that is not absolute and
+// clean, or not a valid glob.
+var ErrInvalidPattern = errors.New("integrity: invalid path pattern")
+
+// ErrTooManyEntries is returned when the patterns match more than MaxEntries
+// paths.
+var ErrTooManyEntries = errors.New("integrity: too many paths")
+
+// Entry is the recorded state of one path.
+type Entry struct {
+	Mode    os.FileMode `json:"mode"`
+	Owner   string      `json:"owner,omitempty"`
+	Group   string      `json:"group,omitempty"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mtime"`
+	// SHA256 is the hex content hash of a regular file.
+	SHA256 string            `json:"sha256,omitempty"`
+	Xattrs map[string][]byte `json:"xattrs,omitempty"`
+}
+
+// Baseline is the state of every path the patterns matched, keyed by path.
+type Baseline struct {
+	Taken   time.Time        `json:"taken"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// ChangeKind is how a path differs from the baseline.
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota + 1
+	Removed
+	Modified
+)
+
+// String names the kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// Change is one path that differs from the baseline.
+type Change struct {
+	Path string
+	Kind ChangeKind
+	// Fields names what differs in a Modified entry, in this order: "type",
+	// "content", "mode", "owner", "group", "xattrs", "mtime".
+	Fields []string
+	// Before is nil for Added, After for Removed.
+	Before, After *Entry
+}
+
+// Report is the result of comparing a scan to the baseline.
+type Report struct {
+	Baseline time.Time // when the baseline was taken
+	Scanned  time.Time
+	Changes  []Change // sorted by path; empty when nothing drifted
+}
+
+// Diff compares two baselines and returns the changes from before to after,
+// sorted by path.
+func Diff(before, after *Baseline) []Change {
+	var changes []Change
+	for path, b := range before.Entries {
+		a, ok := after.Entries[path]
+		if !ok {
+			changes = append(changes, Change{Path: path, Kind: Removed, Before: &b})
+			continue
+		}
+		if fields := diffFields(b, a); len(fields) > 0 {
+			changes = append(changes, Change{Path: path, Kind: Modified, Fields: fields, Before: &b, After: &a})
+		}
+	}
+	for path, a := range after.Entries {
+		if _, ok := before.Entries[path]; !ok {
+			changes = append(changes, Change{Path: path, Kind: Added, After: &a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func diffFields(b, a Entry) []string {
+	var fields []string
+	if b.Mode.Type() != a.Mode.Type() {
+		fields = append(fields, "type")
+	}
+	if b.SHA256 != a.SHA256 || b.Size != a.Size {
+		fields = append(fields, "content")
+	}
+	if b.Mode.Perm()|b.Mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) !=
+		a.Mode.Perm()|a.Mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) {
+		fields = append(fields, "mode")
+	}
+	if b.Owner != a.Owner {
+		fields = append(fields, "owner")
+	}
+	if b.Group != a.Group {
+		fields = append(fields, "group")
+	}
+	if !maps.EqualFunc(b.Xattrs, a.Xattrs, func(x, y []byte) bool { return string(x) == string(y) }) {
+		fields = append(fields, "xattrs")
+	}
+	if !b.ModTime.Equal(a.ModTime) {
+		fields = append(fields, "mtime")
+	}
+	return fields
+}
+
+// Config configures a Monitor.
+type Config struct {
+	// Paths are absolute paths or glob patterns (path.Match syntax, per
+	// segment). A matched directory is walked recursively; symlinks are
+	// recorded, never followed. A path that does not exist is simply absent
+	// from the baseline, so creating it later reports it as added.
+	Paths []string
+	// Store keeps the baseline between scans and restarts.
+	Store Store
+}
+
+// Monitor builds baselines and checks the files against them.
+type Monitor interface {
+	// Scan records the current state of every matched path, without
+	// touching the stored baseline.
+	Scan(ctx context.Context) (*Baseline, error)
+	// Establish scans and saves the result as the baseline, replacing any
+	// earlier one. Call it after an intended change to accept it.
+	Establish(ctx context.Context) (*Baseline, error)
+	// Check scans and compares the result to the stored baseline.
+	Check(ctx context.Context) (Report, error)
+	// Run calls Check every interval until ctx is done, and calls report
+	// whenever the drift differs from what it last reported (a change that
+	// stays in place is reported once). A failed scan ends Run with its
+	// error; ctx ending returns nil.
+	Run(ctx context.Context, interval time.Duration, report func(context.Context, Report)) error
+}
+
+// fsManager is the narrow slice of fs.Manager a scan reads through.
+type fsManager interface {
+	HashFile(ctx context.Context, path string) (string, error)
+	ReadDir(ctx context.Context, path string) ([]fs.DirEntry, error)
+	Stat(ctx context.Context, path string) (fs.FileInfo, error)
+}
+
+type monitor struct {
+	fsm   fsManager
+	paths []string
+	store Store
+	now   func() time.Time
+}
+
+// New builds a Monitor that reads through an fs.Manager over runner. It
+// validates the patterns; nothing is scanned until a method is called.
+func New(runner exec.Runner, cfg Config) (Monitor, error) {
+	if runner == nil {
+		return nil, fmt.Errorf("integrity: %w", exec.ErrRunnerRequired)
+	}
+	if cfg.Store == nil {
+		return nil, errors.New("integrity: a Store is required")
+	}
+	if len(cfg.Paths) == 0 {
+		return nil, fmt.Errorf("%w: no paths", ErrInvalidPattern)
+	}
+	for _, p := range cfg.Paths {
+		if err := validatePattern(p); err != nil {
+			return nil, err
+		}
+	}
+	fsm, err := fs.New(runner)
+	if err != nil {
+		return nil, fmt.Errorf("integrity: %w", err)
+	}
+	return &monitor{fsm: fsm, paths: slices.Clone(cfg.Paths), store: cfg.Store, now: time.Now}, nil
+}
+
+func (m *monitor) Scan(ctx context.Context) (*Baseline, error) {
+	b := &Baseline{Taken: m.now().UTC(), Entries: map[string]Entry{}}
+	for _, pattern := range m.paths {
+		paths, err := m.expand(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			if err := m.walk(ctx, p, b.Entries); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// walk records path and, for a directory, everything under it.
+func (m *monitor) walk(ctx context.Context, path string, into map[string]Entry) error {
+	if _, done := into[path]; done {
+		return nil
+	}
+	if len(into) >= MaxEntries {
+		return fmt.Errorf("%w: more than %d", ErrTooManyEntries, MaxEntries)
+	}
+	e, err := m.entry(ctx, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // absent, or removed while the scan ran
+	}
+	if err != nil {
+		return err
+	}
+	into[path] = e
+	if !e.Mode.IsDir() {
+		return nil
+	}
+	children, err := m.fsm.ReadDir(ctx, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("integrity: %w", err)
+	}
+	for _, c := range children {
+		if err := m.walk(ctx, joinPath(path, c.Name), into); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entry stats path and hashes it when it is a regular file. HashFile reads
+// nothing back through the Runner, so a file of any size is hashed whole.
+func (m *monitor) entry(ctx context.Context, path string) (Entry, error) {
+	info, err := m.fsm.Stat(ctx, path)
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{Mode: info.Mode, Owner: info.Owner, Group: info.Group, Size: info.Size,
+		ModTime: info.ModTime.UTC(), Xattrs: info.Xattrs}
+	if info.Mode.IsRegular() {
+		if e.SHA256, err = m.fsm.HashFile(ctx, path); err != nil {
+			return Entry{}, err
+		}
+	}
+	return e, nil
+}
+
+func (m *monitor) Establish(ctx context.Context) (*Baseline, error) {
+	b, err := m.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.store.Save(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (m *monitor) Check(ctx context.Context) (Report, error) {
+	base, err := m.store.Load(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	now, err := m.Scan(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	return Report{Baseline: base.Taken, Scanned: now.Taken, Changes: Diff(base, now)}, nil
+}
+
+func (m *monitor) Run(ctx context.Context, interval time.Duration, report func(context.Context, Report)) error {
+	if interval <= 0 {
+		return errors.New("integrity: interval must be positive")
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	var last []Change
+	for {
+		rep, err := m.Check(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !sameDrift(last, rep.Changes) {
+			report(ctx, rep)
+			last = rep.Changes
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// sameDrift reports whether two scans found the same changes. The scan
+// times inside them do not matter.
+func sameDrift(a, b []Change) bool {
+	return len(a) == len(b) && (len(a) == 0 || reflect.DeepEqual(a, b))
+}
+
+// joinPath appends name to an absolute directory path.
+func joinPath(dir, name string) string {
+	if dir == "/" {
+		return "/" + name
+	}
+	return dir + "/" + name
+}
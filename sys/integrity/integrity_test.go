@@ -0,0 +1,370 @@
+package integrity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+	"github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+)
+
+// memStore keeps the baseline in memory and counts loads.
+type memStore struct {
+	mu     sync.Mutex
+	b      *Baseline
+	loads  int
+	onLoad func(n int)
+}
+
+func (s *memStore) Load(context.Context) (*Baseline, error) {
+	s.mu.Lock()
+	s.loads++
+	n, b := s.loads, s.b
+	s.mu.Unlock()
+	if s.onLoad != nil {
+		s.onLoad(n)
+	}
+	if b == nil {
+		return nil, ErrNoBaseline
+	}
+	return b, nil
+}
+
+func (s *memStore) Save(_ context.Context, b *Baseline) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.b = b
+	return nil
+}
+
+func writeFile(t *testing.T, path, body string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newMonitor builds a Monitor over the Direct backend, which reads the temp
+// tree itself without the Runner.
+func newMonitor(t *testing.T, store Store, paths ...string) Monitor {
+	t.Helper()
+	m, err := New(exectest.New(exec.Direct), Config{Paths: paths, Store: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func kinds(changes []Change) map[string]string {
+	out := map[string]string{}
+	for _, c := range changes {
+		out[c.Path] = c.Kind.String()
+		if c.Kind == Modified {
+			out[c.Path] += ":" + strings.Join(c.Fields, ",")
+		}
+	}
+	return out
+}
+
+func TestMonitor_ReportsDrift(t *testing.T) {
+	root := t.TempDir()
+	sshd := filepath.Join(root, "ssh", "sshd_config")
+	key := filepath.Join(root, "ssh", "ssh_host_key")
+	writeFile(t, sshd, "PermitRootLogin no\n", 0o644)
+	writeFile(t, key, "secret", 0o600)
+	writeFile(t, filepath.Join(root, "other"), "x", 0o644)
+
+	ctx := context.Background()
+	store := &memStore{}
+	m := newMonitor(t, store, filepath.Join(root, "ssh"), filepath.Join(root, "missing"))
+	if _, err := m.Check(ctx); !errors.Is(err, ErrNoBaseline) {
+		t.Fatalf("Check before Establish err = %v, want ErrNoBaseline", err)
+	}
+	base, err := m.Establish(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(base.Entries) != 3 {
+		t.Errorf("baseline has %d entries, want the dir and its 2 files: %v", len(base.Entries), base.Entries)
+	}
+	if sum := sha256.Sum256([]byte("PermitRootLogin no\n")); base.Entries[sshd].SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("sshd_config hash = %q, want the content's sha256", base.Entries[sshd].SHA256)
+	}
+	rep, err := m.Check(ctx)
+	if err != nil || len(rep.Changes) != 0 {
+		t.Fatalf("Check right after Establish = (%v, %v), want no changes", rep.Changes, err)
+	}
+
+	mtime := base.Entries[sshd].ModTime
+	writeFile(t, sshd, "PermitRootLogin yes\n", 0o644)
+	if err := os.Chtimes(sshd, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(key, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "ssh", "sshd_config.d", "evil.conf"), "x", 0o644)
+	writeFile(t, filepath.Join(root, "missing"), "now here", 0o644)
+	if err := os.Remove(filepath.Join(root, "other")); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err = m.Check(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := kinds(rep.Changes)
+	// A new entry in a directory changes the directory's own mtime.
+	if dir := filepath.Join(root, "ssh"); !strings.HasPrefix(got[dir], "modified:") {
+		t.Errorf("%s: %q, want modified", dir, got[dir])
+	}
+	delete(got, filepath.Join(root, "ssh"))
+	want := map[string]string{
+		sshd: "modified:content",
+		key:  "modified:mode",
+		filepath.Join(root, "ssh", "sshd_config.d"):              "added",
+		filepath.Join(root, "ssh", "sshd_config.d", "evil.conf"): "added",
+		filepath.Join(root, "missing"):                           "added",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v\nwant      %v", got, want)
+	}
+	for i := 1; i < len(rep.Changes); i++ {
+		if rep.Changes[i-1].Path > rep.Changes[i].Path {
+			t.Errorf("changes not sorted by path: %v", got)
+		}
+	}
+}
+
+// Escalated, a file of any size is hashed by sha256sum as root rather than
+// read back through the Runner, which would stop at exec.MaxOutputBytes.
+func TestMonitor_EscalatedHashesWithSha256sum(t *testing.T) {
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	r := exectest.New(exec.Sudo)
+	r.Push(exec.Result{Stdout: "81a4 4194304 1 root root\n"}, nil) // stat
+	r.Push(exec.Result{}, nil)                                     // getfattr
+	r.Push(exec.Result{Stdout: sum + "  /var/lib/big\n"}, nil)
+	m, err := New(r, Config{Paths: []string{"/var/lib/big"}, Store: &memStore{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Entries["/var/lib/big"].SHA256; got != sum {
+		t.Errorf("hash = %q, want %s", got, sum)
+	}
+	for _, c := range r.Calls() {
+		if c.Name == "cat" {
+			t.Errorf("read the file back: %v", c.Args)
+		}
+	}
+}
+
+func TestMonitor_GlobPatterns(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "sudoers.d", "10-admins"), "x", 0o440)
+	writeFile(t, filepath.Join(root, "sudoers.d", "README"), "x", 0o440)
+	writeFile(t, filepath.Join(root, "a", "conf.d", "1.conf"), "x", 0o644)
+	writeFile(t, filepath.Join(root, "b", "conf.d", "2.conf"), "x", 0o644)
+	writeFile(t, filepath.Join(root, "b", "conf.d", "2.txt"), "x", 0o644)
+
+	m := newMonitor(t, &memStore{}, filepath.Join(root, "sudoers.d", "[0-9]*"), filepath.Join(root, "*", "conf.d", "*.conf"))
+	b, err := m.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for p := range b.Entries {
+		paths = append(paths, strings.TrimPrefix(p, root))
+	}
+	want := []string{"/a/conf.d/1.conf", "/b/conf.d/2.conf", "/sudoers.d/10-admins"}
+	slices.Sort(paths)
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("matched %v, want %v", paths, want)
+	}
+}
+
+func TestMonitor_Run_ReportsEachDriftOnce(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "f")
+	writeFile(t, path, "a", 0o644)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &memStore{}
+	m := newMonitor(t, store, path)
+	if _, err := m.Establish(ctx); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "b", 0o644)
+	store.onLoad = func(n int) {
+		if n == 5 {
+			cancel()
+		}
+	}
+
+	var reports []Report
+	err := m.Run(ctx, time.Millisecond, func(_ context.Context, r Report) { reports = append(reports, r) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || len(reports[0].Changes) != 1 || reports[0].Changes[0].Kind != Modified {
+		t.Errorf("reports = %+v, want one report of the modification", reports)
+	}
+}
+
+func TestMonitor_Run_NeedsBaseline(t *testing.T) {
+	m := newMonitor(t, &memStore{}, "/etc/hostname")
+	if err := m.Run(context.Background(), time.Second, func(context.Context, Report) {}); !errors.Is(err, ErrNoBaseline) {
+		t.Errorf("err = %v, want ErrNoBaseline", err)
+	}
+}
+
+func TestNew_Rejects(t *testing.T) {
+	r := exectest.New(exec.Direct)
+	for _, tt := range []struct {
+		name   string
+		runner exec.Runner
+		cfg    Config
+		want   error
+	}{
+		{"nil runner", nil, Config{Paths: []string{"/etc"}, Store: &memStore{}}, exec.ErrRunnerRequired},
+		{"no paths", r, Config{Store: &memStore{}}, ErrInvalidPattern},
+		{"relative", r, Config{Paths: []string{"etc/ssh"}, Store: &memStore{}}, ErrInvalidPattern},
+		{"unclean", r, Config{Paths: []string{"/etc/../root"}, Store: &memStore{}}, ErrInvalidPattern},
+		{"bad glob", r, Config{Paths: []string{"/etc/[a"}, Store: &memStore{}}, ErrInvalidPattern},
+	} {
+		if _, err := New(tt.runner, tt.cfg); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := New(r, Config{Paths: []string{"/etc"}}); err == nil {
+		t.Error("a nil Store was accepted")
+	}
+}
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	r := exectest.New(exec.Direct)
+	s, err := NewFileStore(r, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := s.Load(ctx); !errors.Is(err, ErrNoBaseline) {
+		t.Fatalf("Load before Save err = %v, want ErrNoBaseline", err)
+	}
+	b := &Baseline{Taken: time.Unix(1700000000, 0).UTC(), Entries: map[string]Entry{
+		"/etc/ssh/sshd_config": {Mode: 0o600, Owner: "root", Size: 3, ModTime: time.Unix(1, 0).UTC(),
+			SHA256: "ab", Xattrs: map[string][]byte{"security.selinux": {0, 1, 0xff}}},
+	}}
+	if err := s.Save(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("Load = %+v, want %+v", got, b)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("baseline mode = %o, want 0600", info.Mode().Perm())
+	}
+}
+
+// The baseline is one JSON line that outgrows the Runner's output limit, so
+// the escalated store loads it with ReadFileExact.
+func TestFileStore_LoadEscalated(t *testing.T) {
+	b := &Baseline{Taken: time.Unix(1700000000, 0).UTC(), Entries: map[string]Entry{}}
+	for i := range 20000 {
+		b.Entries[fmt.Sprintf("/etc/many/%05d", i)] = Entry{Mode: 0o644, Size: 1, ModTime: time.Unix(1, 0).UTC(), SHA256: strings.Repeat("a", 64)}
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) <= exec.MaxOutputBytes {
+		t.Fatalf("baseline is %d bytes; the test needs more than %d", len(data), exec.MaxOutputBytes)
+	}
+	r := exectest.New(exec.Sudo)
+	r.Push(exec.Result{Stdout: base64.StdEncoding.EncodeToString(data) + "\n"}, nil)
+	s, err := NewFileStore(r, DefaultBaselinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("Load returned %d entries, want the %d saved", len(got.Entries), len(b.Entries))
+	}
+	if c := r.Calls()[0]; c.Name != "base64" {
+		t.Errorf("Load ran %s %v, want base64", c.Name, c.Args)
+	}
+}
+
+func TestReport_Alert(t *testing.T) {
+	rep := Report{
+		Baseline: time.Unix(0, 0).UTC(),
+		Scanned:  time.Unix(60, 0).UTC(),
+		Changes: []Change{
+			{Path: "/etc/hosts", Kind: Modified, Fields: []string{"content"}},
+			{Path: "/etc/ssh/sshd_config", Kind: Modified, Fields: []string{"content", "mtime"}},
+			{Path: "/etc/sudoers.d/evil", Kind: Added},
+			{Path: "/etc/sshd", Kind: Removed},
+		},
+	}
+	a := rep.Alert([]string{"/etc/ssh", "/etc/sudoers.d/"})
+	if a == nil {
+		t.Fatal("Alert = nil, want an alert")
+	}
+	if a.GetType() != pb.SecurityAlertType_SECURITY_ALERT_TYPE_FILE_INTEGRITY {
+		t.Errorf("type = %v", a.GetType())
+	}
+	if a.GetDetails()["modified"] != "/etc/ssh/sshd_config (content,mtime)" || a.GetDetails()["added"] != "/etc/sudoers.d/evil" {
+		t.Errorf("details = %v", a.GetDetails())
+	}
+	if _, ok := a.GetDetails()["removed"]; ok {
+		t.Error("/etc/sshd is not under /etc/ssh but was reported")
+	}
+	if !strings.HasPrefix(a.GetMessage(), "2 protected file(s)") {
+		t.Errorf("message = %q", a.GetMessage())
+	}
+
+	if a := (Report{Changes: rep.Changes[:1]}).Alert(DefaultProtected); a != nil {
+		t.Errorf("Alert = %v, want nil for an unprotected change", a)
+	}
+}
+
+func TestJoinCapped(t *testing.T) {
+	items := []string{strings.Repeat("a", 10), strings.Repeat("b", 10), strings.Repeat("c", 10)}
+	if got := joinCapped(items, 100); got != strings.Join(items, ", ") {
+		t.Errorf("joinCapped = %q", got)
+	}
+	if got := joinCapped(items, 29); got != "aaaaaaaaaa +2 more" {
+		t.Errorf("joinCapped = %q", got)
+	}
+	if got := joinCapped(items, 5); got != "+3 mo" {
+		t.Errorf("joinCapped = %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+package integrity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/fs"
+)
+
+// DefaultBaselinePath is where the agent keeps its baseline.
+const DefaultBaselinePath = "/var/lib/power-manage/integrity/baseline.json"
+
+// Store keeps a baseline across scans and restarts.
+type Store interface {
+	// Load returns the saved baseline, or ErrNoBaseline.
+	Load(ctx context.Context) (*Baseline, error)
+	// Save replaces the saved baseline.
+	Save(ctx context.Context, b *Baseline) error
+}
+
+// storeFS is the slice of fs.Manager the file store uses.
+type storeFS interface {
+	ReadFileExact(ctx context.Context, path string) ([]byte, error)
+	WriteFile(ctx context.Context, path string, data []byte, opts fs.WriteOptions) error
+	Mkdir(ctx context.Context, path string, opts fs.MkdirOptions) error
+}
+
+type fileStore struct {
+	fsm  storeFS
+	path string
+}
+
+// NewFileStore returns a Store that keeps the baseline as JSON at path. Save
+// goes through fs.Manager.WriteFile, so the file is replaced atomically and a
+// crash mid-save leaves the previous baseline intact. The file is root-only
+// (0600): it lists what the monitor watches.
+func NewFileStore(runner exec.Runner, path string) (Store, error) {
+	if runner == nil {
+		return nil, fmt.Errorf("integrity: %w", exec.ErrRunnerRequired)
+	}
+	if err := fs.ValidatePath(path); err != nil {
+		return nil, fmt.Errorf("integrity: %w", err)
+	}
+	fsm, err := fs.New(runner)
+	if err != nil {
+		return nil, fmt.Errorf("integrity: %w", err)
+	}
+	return &fileStore{fsm: fsm, path: path}, nil
+}
+
+func (s *fileStore) Load(ctx context.Context) (*Baseline, error) {
+	// A baseline is one JSON line that outgrows the escalated ReadFile's
+	// limit long before MaxEntries.
+	data, err := s.fsm.ReadFileExact(ctx, s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoBaseline
+	}
+	if err != nil {
+		return nil, fmt.Errorf("integrity: load baseline: %w", err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("integrity: parse baseline %s: %w", s.path, err)
+	}
+	if b.Entries == nil {
+		b.Entries = map[string]Entry{}
+	}
+	return &b, nil
+}
+
+func (s *fileStore) Save(ctx context.Context, b *Baseline) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("integrity: encode baseline: %w", err)
+	}
+	if err := s.fsm.Mkdir(ctx, path.Dir(s.path), fs.MkdirOptions{Recursive: true, Mode: 0o700}); err != nil {
+		return fmt.Errorf("integrity: save baseline: %w", err)
+	}
+	if err := s.fsm.WriteFile(ctx, s.path, data, fs.WriteOptions{Mode: 0o600}); err != nil {
+		return fmt.Errorf("integrity: save baseline: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+//go:build linux
+
+package inventory
+
+import (
+	"context"
+	"maps"
+	"net/netip"
+	"strconv"
+
+	"github.com/manchtools/power-manage-sdk/sys/fs"
+)
+
+// Facts collects what a file template can read about this device, together
+// with the device labels delivered in its manifest:
+//
+//	.hostname .arch .kernel .cpu_cores .memory_mb
+//	.os.id .os.name .os.version .os.version_id .os.pretty_name
+//	.interfaces.<name>.mac .state .addresses .ipv4 .ipv6
+//	.ipv4 .ipv6   the first global address of the first UP interface
+//	.labels.<key>
+//
+// .ipv4 and .ipv6 are left out when no UP interface has one, so a template
+// that needs them fails with fs.ErrMissingFact instead of rendering blank.
+func Facts(ctx context.Context, c Collector, labels map[string]string) (fs.Facts, error) {
+	sys, err := c.System(ctx)
+	if err != nil {
+		return nil, err
+	}
+	osInfo, err := c.OS()
+	if err != nil {
+		return nil, err
+	}
+	ifaces, err := c.NetworkInterfaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildFacts(sys, osInfo, ifaces, labels), nil
+}
+
+// buildFacts shapes collected inventory into fs.Facts. Numbers become
+// strings, as fs.Facts requires.
+func buildFacts(sys *SystemInfo, osInfo *OSInfo, ifaces []NetworkInterface, labels map[string]string) fs.Facts {
+	f := fs.Facts{
+		"hostname":  sys.Hostname,
+		"arch":      sys.Arch,
+		"kernel":    sys.KernelVersion,
+		"cpu_cores": strconv.Itoa(sys.CPUCores),
+		"memory_mb": strconv.FormatInt(sys.MemoryTotalMB, 10),
+		"os": map[string]string{
+			"id":          osInfo.ID,
+			"name":        osInfo.Name,
+			"version":     osInfo.Version,
+			"version_id":  osInfo.VersionID,
+			"pretty_name": osInfo.PrettyName,
+		},
+		// Never nil, so a missing label is a missing key.
+		"labels": maps.Clone(labels),
+	}
+	if labels == nil {
+		f["labels"] = map[string]string{}
+	}
+
+	byName := make(map[string]any, len(ifaces))
+	for _, ni := range ifaces {
+		v4, v6 := globalAddrs(ni.Addresses)
+		byName[ni.Name] = map[string]any{
+			"mac":       ni.MAC,
+			"state":     ni.State,
+			"addresses": append([]string{}, ni.Addresses...),
+			"ipv4":      v4,
+			"ipv6":      v6,
+		}
+		if ni.State != "UP" {
+			continue
+		}
+		if _, ok := f["ipv4"]; !ok && len(v4) > 0 {
+			f["ipv4"] = v4[0]
+		}
+		if _, ok := f["ipv6"]; !ok && len(v6) > 0 {
+			f["ipv6"] = v6[0]
+		}
+	}
+	f["interfaces"] = byName
+	return f
+}
+
+// globalAddrs splits CIDR addresses into their global unicast IPv4 and IPv6
+// addresses, without the prefix length. Loopback and link-local addresses
+// say nothing about how to reach the device and are dropped.
+func globalAddrs(cidrs []string) (v4, v6 []string) {
+	v4, v6 = []string{}, []string{}
+	for _, c := range cidrs {
+		p, err := netip.ParsePrefix(c)
+		if err != nil || !p.Addr().IsGlobalUnicast() {
+			continue
+		}
+		if p.Addr().Is4() {
+			v4 = append(v4, p.Addr().String())
+		} else {
+			v6 = append(v6, p.Addr().String())
+		}
+	}
+	return v4, v6
+}
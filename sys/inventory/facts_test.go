@@ -0,0 +1,56 @@
+//go:build linux
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/manchtools/power-manage-sdk/sys/exec"
+	"github.com/manchtools/power-manage-sdk/sys/exec/exectest"
+	"github.com/manchtools/power-manage-sdk/sys/fs"
+)
+
+func TestFacts_RenderThroughTemplate(t *testing.T) {
+	oc, om, oo, oh := cpuinfoPath, meminfoPath, osReleasePath, hostnameFn
+	t.Cleanup(func() { cpuinfoPath, meminfoPath, osReleasePath, hostnameFn = oc, om, oo, oh })
+	cpuinfoPath = writeTemp(t, "processor\t: 0\nmodel name\t: Test CPU\n")
+	meminfoPath = writeTemp(t, "MemTotal:       2048000 kB\n")
+	osReleasePath = writeTemp(t, "ID=debian\nVERSION_ID=\"12\"\n")
+	hostnameFn = func() (string, error) { return "web01", nil }
+
+	r := exectest.New(exec.Direct)
+	r.Push(exec.Result{Stdout: "6.1.0\n"}, nil)
+	r.Push(exec.Result{Stdout: `[
+		{"ifname":"lo","address":"00:00:00:00:00:00","operstate":"unknown",
+		 "addr_info":[{"local":"127.0.0.1","prefixlen":8}]},
+		{"ifname":"eth1","address":"aa:bb:cc:dd:ee:01","operstate":"down",
+		 "addr_info":[{"local":"10.9.9.9","prefixlen":24}]},
+		{"ifname":"eth0","address":"aa:bb:cc:dd:ee:ff","operstate":"up",
+		 "addr_info":[{"local":"fe80::1","prefixlen":64},{"local":"192.168.1.5","prefixlen":24},{"local":"2001:db8::5","prefixlen":64}]}
+	]`}, nil)
+
+	facts, err := Facts(context.Background(), newCollector(t, r), map[string]string{"role": "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := fs.Render(
+		"{{.hostname}} {{.os.id}}{{.os.version_id}} {{.cpu_cores}}x{{.memory_mb}} {{.ipv4}} {{.ipv6}} {{.labels.role}} {{fact .interfaces.eth1.ipv4 0}}",
+		facts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "web01 debian12 1x2000 192.168.1.5 2001:db8::5 web 10.9.9.9"; string(out.Content) != want {
+		t.Errorf("rendered %q, want %q", out.Content, want)
+	}
+}
+
+func TestBuildFacts_MissingFactsStayMissing(t *testing.T) {
+	facts := buildFacts(&SystemInfo{}, &OSInfo{}, []NetworkInterface{{Name: "lo", State: "UNKNOWN", Addresses: []string{"127.0.0.1/8"}}}, nil)
+	for _, tmpl := range []string{"{{.ipv4}}", "{{.ipv6}}", "{{.labels.role}}"} {
+		if _, err := fs.Render(tmpl, facts); !errors.Is(err, fs.ErrMissingFact) {
+			t.Errorf("Render(%q) err = %v, want ErrMissingFact", tmpl, err)
+		}
+	}
+}
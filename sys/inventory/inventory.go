@@ -96,7 +96,7 @@ type collector struct {
 // read runs an unprivileged query and returns its stdout, mapping a non-zero
 // exit (or a failure to execute) to an error.
 func (c *collector) read(ctx context.Context, name string, args ...string) (string, error) {
-	res, err := c.r.Run(ctx, exec.Command{Name: name, Args: args})
+	res, err := c.r.Run(ctx, exec.Command{Name: name, Args: args, ReadOnly: true})
 	if err != nil {
 		return "", err
 	}
@@ -39,7 +39,7 @@ func (s *journaldSource) Query(ctx context.Context, q Query) ([]string, error) {
 	if q.Grep != "" {
 		args = append(args, "--grep", q.Grep)
 	}
-	res, err := s.r.Run(ctx, exec.Command{Name: "journalctl", Args: args, Escalate: true})
+	res, err := s.r.Run(ctx, exec.Command{Name: "journalctl", Args: args, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +146,7 @@ func validateQuery(q Query) error {
 // non-zero exit becomes a *CommandError unless it is in okExitCodes (some tools,
 // e.g. grep, use a non-zero exit to mean "no matches", which is not a failure).
 func runEscalated(ctx context.Context, r exec.Runner, okExitCodes map[int]bool, name string, args ...string) (string, error) {
-	res, err := r.Run(ctx, exec.Command{Name: name, Args: args, Escalate: true})
+	res, err := r.Run(ctx, exec.Command{Name: name, Args: args, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return "", err
 	}
@@ -159,6 +159,34 @@ func TestStagedModify_WriteCertsFails(t *testing.T) {
 	}
 }
 
+// A dry run stages no certificates: the install is planned, the modify
+// captured, and nothing reaches disk.
+func TestStagedModify_DryRunStagesNothing(t *testing.T) {
+	certDir := filepath.Join(t.TempDir(), "eap")
+	base := &recordingRunner{}
+	d, err := exec.NewDryRunner(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &networkManager{r: d}
+	changed, err := m.stagedModify(context.Background(), eapProfile(t, certDir), nil)
+	if err != nil || !changed {
+		t.Fatalf("stagedModify = %v, %v; want a planned change", changed, err)
+	}
+	for _, p := range []string{certDir, certDir + ".tmp"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("dry run created %s", p)
+		}
+	}
+	if len(base.calls) != 0 {
+		t.Errorf("base ran %d commands, want none", len(base.calls))
+	}
+	steps := d.Plan().Steps
+	if len(steps) != 2 || steps[0].Change != "install certificates in "+certDir || steps[1].Name != "nmcli" {
+		t.Errorf("plan = %q", d.Plan())
+	}
+}
+
 func TestStagedModify_NmcliModFails(t *testing.T) {
 	certDir := filepath.Join(t.TempDir(), "eap")
 	r := &recordingRunner{}
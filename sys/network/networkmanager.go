@@ -21,7 +21,7 @@ type networkManager struct {
 // nmcliRead runs an unprivileged nmcli query and returns stdout, mapping a
 // non-zero exit (or a failure to execute) to an error.
 func (m *networkManager) nmcliRead(ctx context.Context, args ...string) (string, error) {
-	res, err := m.r.Run(ctx, exec.Command{Name: "nmcli", Args: args})
+	res, err := m.r.Run(ctx, exec.Command{Name: "nmcli", Args: args, ReadOnly: true})
 	if err != nil {
 		return "", err
 	}
@@ -116,8 +116,10 @@ func (m *networkManager) create(ctx context.Context, p Profile) (bool, error) {
 		return true, nil
 	}
 	// EAP-TLS (validateProfile guarantees no other auth type reaches here).
-	if err := writeCerts(p); err != nil {
-		return false, fmt.Errorf("write certificates: %w", err)
+	if !exec.Planned(m.r, "write certificates to "+p.CertDir) {
+		if err := writeCerts(p); err != nil {
+			return false, fmt.Errorf("write certificates: %w", err)
+		}
 	}
 	if err := m.nmcliWrite(ctx, buildAddArgs(p)...); err != nil {
 		if rmErr := removeCerts(p.CertDir); rmErr != nil {
@@ -158,8 +160,11 @@ func (m *networkManager) update(ctx context.Context, p Profile) (bool, error) {
 // NetworkManager. The PSK reaches disk only through the keyfile; it never
 // touches argv.
 func (m *networkManager) provisionPSK(ctx context.Context, p Profile) error {
-	if err := writeKeyfile(keyfilePath(p.Name), buildPSKKeyfile(p)); err != nil {
-		return err
+	path := keyfilePath(p.Name)
+	if !exec.Planned(m.r, "write keyfile "+path) {
+		if err := writeKeyfile(path, buildPSKKeyfile(p)); err != nil {
+			return err
+		}
 	}
 	if err := m.nmcliWrite(ctx, "connection", "reload"); err != nil {
 		return fmt.Errorf("nmcli connection reload: %w", err)
@@ -173,6 +178,14 @@ func (m *networkManager) provisionPSK(ctx context.Context, p Profile) error {
 // rollback. The live cert directory is never destroyed before the staged copy is
 // in place.
 func (m *networkManager) stagedModify(ctx context.Context, p Profile, current map[string]string) (bool, error) {
+	// A dry run stages nothing: it records the cert install and plans the
+	// modify.
+	if exec.Planned(m.r, "install certificates in "+p.CertDir) {
+		if err := m.nmcliWrite(ctx, buildModifyArgs(p, current)...); err != nil {
+			return false, fmt.Errorf("modify connection: %w", err)
+		}
+		return true, nil
+	}
 	tmpDir := p.CertDir + ".tmp"
 	staged := p
 	staged.CertDir = tmpDir
@@ -233,7 +246,7 @@ func (m *networkManager) Delete(ctx context.Context, name string, opts DeleteOpt
 			return fmt.Errorf("delete connection %s: %w", name, err)
 		}
 	}
-	if opts.CertDir != "" {
+	if opts.CertDir != "" && !exec.Planned(m.r, "remove certificate directory "+opts.CertDir) {
 		if err := safeRemoveCertDir(opts.CertDir); err != nil {
 			return err
 		}
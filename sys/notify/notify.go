@@ -195,7 +195,7 @@ func (n *notifier) sendDesktopNotifications(ctx context.Context, title, message
 // enumeration is an error (we couldn't determine who to notify); a single
 // session whose details can't be read is skipped (best-effort per session).
 func (n *notifier) listGraphicalSessions(ctx context.Context) ([]session, error) {
-	res, err := n.r.Run(ctx, exec.Command{Name: "loginctl", Args: []string{"list-sessions", "--no-legend"}, Escalate: true})
+	res, err := n.r.Run(ctx, exec.Command{Name: "loginctl", Args: []string{"list-sessions", "--no-legend"}, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return nil, fmt.Errorf("list sessions: %w", err)
 	}
@@ -212,6 +212,7 @@ func (n *notifier) listGraphicalSessions(ctx context.Context) ([]session, error)
 			Name:     "loginctl",
 			Args:     []string{"show-session", sessionID, "-p", "Type", "-p", "Name", "-p", "User"},
 			Escalate: true,
+			ReadOnly: true,
 		})
 		if err != nil || info.ExitCode != 0 {
 			continue
@@ -329,7 +329,7 @@ func (c *client) execQuery(ctx context.Context, query string) (string, error) {
 		args = append(args, "--json", query)
 	}
 
-	res, err := c.r.Run(ctx, exec.Command{Name: c.binaryPath, Args: args, Escalate: true})
+	res, err := c.r.Run(ctx, exec.Command{Name: c.binaryPath, Args: args, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return "", fmt.Errorf("%w: %v", ErrQueryFailed, err)
 	}
@@ -92,7 +92,7 @@ func (rb *rebooter) IsRequired(ctx context.Context) (bool, error) {
 		return false, fmt.Errorf("stat %s: %w", rebootRequiredPath, err)
 	}
 
-	res, err := rb.r.Run(ctx, exec.Command{Name: "needs-restarting", Args: []string{"-r"}})
+	res, err := rb.r.Run(ctx, exec.Command{Name: "needs-restarting", Args: []string{"-r"}, ReadOnly: true})
 	if err != nil {
 		if errors.Is(err, exec.ErrBackendUnavailable) {
 			// needs-restarting isn't installed → no detection available on this
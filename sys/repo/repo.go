@@ -324,10 +324,11 @@ func (m *manager) runNonFatal(ctx context.Context, log *strings.Builder, warnMsg
 
 // runStdin runs an UNPRIVILEGED command with stdin (the gpg --dearmor path: it
 // processes a public key, needs no privilege, and must not touch any file —
-// fs.Manager performs the privileged keyring write). A non-zero exit folds into
+// fs.Manager performs the privileged keyring write), so it is ReadOnly and a
+// dry run still dearmors the key it plans to write. A non-zero exit folds into
 // an *exec.CommandError.
 func (m *manager) runStdin(ctx context.Context, stdin []byte, name string, args ...string) (pmexec.Result, error) {
-	cmd := pmexec.Command{Name: name, Args: args}
+	cmd := pmexec.Command{Name: name, Args: args, ReadOnly: true}
 	if len(stdin) > 0 {
 		cmd.Stdin = bytes.NewReader(stdin)
 	}
@@ -63,7 +63,7 @@ var validSystemctlOutputs = map[string]map[string]struct{}{
 func (s *systemd) query(ctx context.Context, unit, verb string) (string, error) {
 	ctx, cancel := ensureCtx(ctx)
 	defer cancel()
-	res, err := s.r.Run(ctx, exec.Command{Name: "systemctl", Args: []string{verb, "--", unit}})
+	res, err := s.r.Run(ctx, exec.Command{Name: "systemctl", Args: []string{verb, "--", unit}, ReadOnly: true})
 	if err != nil {
 		return "", fmt.Errorf("systemctl %s %s: %w", verb, unit, err)
 	}
@@ -277,7 +277,7 @@ func (s *systemd) NeedsReload(ctx context.Context, unit string) (bool, error) {
 	}
 	ctx, cancel := ensureCtx(ctx)
 	defer cancel()
-	res, err := s.r.Run(ctx, exec.Command{Name: "systemctl", Args: []string{"show", "--property=NeedDaemonReload", "--", unit}})
+	res, err := s.r.Run(ctx, exec.Command{Name: "systemctl", Args: []string{"show", "--property=NeedDaemonReload", "--", unit}, ReadOnly: true})
 	if err != nil {
 		return false, fmt.Errorf("systemctl show %s: %w", unit, err)
 	}
@@ -299,7 +299,7 @@ func (s *systemd) NeedsReload(ctx context.Context, unit string) (bool, error) {
 func (s *systemd) Version(ctx context.Context) (int, error) {
 	ctx, cancel := ensureCtx(ctx)
 	defer cancel()
-	res, err := s.r.Run(ctx, exec.Command{Name: "systemctl", Args: []string{"--version"}})
+	res, err := s.r.Run(ctx, exec.Command{Name: "systemctl", Args: []string{"--version"}, ReadOnly: true})
 	if err != nil {
 		return 0, fmt.Errorf("systemctl --version: %w", err)
 	}
@@ -164,7 +164,7 @@ func (c *collector) Device(ctx context.Context, dev string) (Device, error) {
 // error downstream. Only a Runner-level failure (binary missing, escalation
 // denied) is surfaced here.
 func (c *collector) run(ctx context.Context, args ...string) (string, error) {
-	res, err := c.r.Run(ctx, exec.Command{Name: "smartctl", Args: args, Escalate: true})
+	res, err := c.r.Run(ctx, exec.Command{Name: "smartctl", Args: args, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return "", fmt.Errorf("smart: run smartctl: %w", err)
 	}
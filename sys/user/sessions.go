@@ -53,7 +53,7 @@ func (u *shadowUtils) LastLogin(ctx context.Context, name string) (time.Time, er
 	if err := validateUsername(name); err != nil {
 		return time.Time{}, err
 	}
-	res, err := u.exec(ctx, exec.Command{Name: "last", Args: []string{"-1", "-F", name}})
+	res, err := u.exec(ctx, exec.Command{Name: "last", Args: []string{"-1", "-F", name}, ReadOnly: true})
 	if err != nil {
 		return time.Time{}, fmt.Errorf("last login for %s: %w", name, err)
 	}
@@ -198,7 +198,7 @@ func (u *shadowUtils) run(ctx context.Context, name string, args ...string) erro
 // query executes an unescalated read command and returns trimmed stdout, mapping
 // a non-zero exit to a *exec.CommandError.
 func (u *shadowUtils) query(ctx context.Context, name string, args ...string) (string, error) {
-	res, err := u.exec(ctx, exec.Command{Name: name, Args: args})
+	res, err := u.exec(ctx, exec.Command{Name: name, Args: args, ReadOnly: true})
 	if err != nil {
 		return "", err
 	}
@@ -434,7 +434,7 @@ func (u *shadowUtils) Unlock(ctx context.Context, name string) error {
 // /etc/shadow entry, read escalated (root-only). Returns an error when the
 // entry can't be read or is malformed.
 func (u *shadowUtils) shadowPassword(ctx context.Context, name string) (string, error) {
-	res, err := u.exec(ctx, exec.Command{Name: "getent", Args: []string{"shadow", name}, Escalate: true})
+	res, err := u.exec(ctx, exec.Command{Name: "getent", Args: []string{"shadow", name}, Escalate: true, ReadOnly: true})
 	if err != nil {
 		return "", err
 	}
@@ -512,7 +512,7 @@ func (u *shadowUtils) Exists(ctx context.Context, name string) (bool, error) {
 	}
 	ctx, cancel := ensureCtx(ctx)
 	defer cancel()
-	res, err := u.exec(ctx, exec.Command{Name: "id", Args: []string{name}})
+	res, err := u.exec(ctx, exec.Command{Name: "id", Args: []string{name}, ReadOnly: true})
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,255 @@
+package validate
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+
+	pm "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+)
+
+// Action runs the semantic checks struct tags cannot express: the params
+// variant must match the action type, and per-type rules that span fields
+// ("custom_config is required when access_level is CUSTOM"). It returns nil
+// when the action passes. It does not repeat the struct-tag checks; run
+// Struct first, and format the result of both with FormatValidationErrors.
+//
+// Field paths are snake_case and rooted at the action ("wifi.client_key",
+// "agent_update.amd64.checksum_url"), so agent, CLI pre-flight and control
+// report the same rule the same way.
+func Action(a *pm.Action) validator.ValidationErrors {
+	if a == nil {
+		return validator.ValidationErrors{fieldError("action", "required", "", nil)}
+	}
+	var c checker
+	c.params(a)
+	switch p := a.Params.(type) {
+	case *pm.Action_Package:
+		c.pkg(p.Package)
+	case *pm.Action_Shell:
+		c.shell(p.Shell)
+	case *pm.Action_Repository:
+		c.repository(p.Repository)
+	case *pm.Action_AdminPolicy:
+		c.adminPolicy(p.AdminPolicy)
+	case *pm.Action_Wifi:
+		c.wifi(p.Wifi)
+	case *pm.Action_AgentUpdate:
+		c.agentUpdate(p.AgentUpdate)
+	}
+	return c.errs
+}
+
+// paramsFor is the params variant each action type carries; "" for the
+// parameterless instant actions.
+var paramsFor = map[pm.ActionType]string{
+	pm.ActionType_ACTION_TYPE_PACKAGE:      "package",
+	pm.ActionType_ACTION_TYPE_UPDATE:       "update",
+	pm.ActionType_ACTION_TYPE_REPOSITORY:   "repository",
+	pm.ActionType_ACTION_TYPE_APP_IMAGE:    "app",
+	pm.ActionType_ACTION_TYPE_DEB:          "app",
+	pm.ActionType_ACTION_TYPE_RPM:          "app",
+	pm.ActionType_ACTION_TYPE_FLATPAK:      "flatpak",
+	pm.ActionType_ACTION_TYPE_SHELL:        "shell",
+	pm.ActionType_ACTION_TYPE_SCRIPT_RUN:   "shell",
+	pm.ActionType_ACTION_TYPE_SERVICE:      "service",
+	pm.ActionType_ACTION_TYPE_FILE:         "file",
+	pm.ActionType_ACTION_TYPE_DIRECTORY:    "directory",
+	pm.ActionType_ACTION_TYPE_REBOOT:       "",
+	pm.ActionType_ACTION_TYPE_SYNC:         "",
+	pm.ActionType_ACTION_TYPE_USER:         "user",
+	pm.ActionType_ACTION_TYPE_GROUP:        "group",
+	pm.ActionType_ACTION_TYPE_SSH:          "ssh",
+	pm.ActionType_ACTION_TYPE_SSHD:         "sshd",
+	pm.ActionType_ACTION_TYPE_ADMIN_POLICY: "admin_policy",
+	pm.ActionType_ACTION_TYPE_LPS:          "lps",
+	pm.ActionType_ACTION_TYPE_ENCRYPTION:   "encryption",
+	pm.ActionType_ACTION_TYPE_WIFI:         "wifi",
+	pm.ActionType_ACTION_TYPE_AGENT_UPDATE: "agent_update",
+}
+
+type checker struct {
+	errs validator.ValidationErrors
+}
+
+func (c *checker) add(path, tag, param string, value any) {
+	c.errs = append(c.errs, fieldError(path, tag, param, value))
+}
+
+// params checks the oneof against the type. An unknown type is left to the
+// struct tags and the agent; there is no variant to hold it to.
+func (c *checker) params(a *pm.Action) {
+	want, known := paramsFor[a.Type]
+	if !known {
+		return
+	}
+	got := ""
+	m := a.ProtoReflect()
+	if fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("params")); fd != nil {
+		got = string(fd.Name())
+	}
+	if got != want {
+		c.add("params", "params", want, got)
+	}
+}
+
+func (c *checker) pkg(p *pm.PackageParams) {
+	if p.GetName() == "" && p.GetAptName() == "" && p.GetDnfName() == "" && p.GetPacmanName() == "" && p.GetZypperName() == "" {
+		c.add("package.name", "required_without_all", "apt_name dnf_name pacman_name zypper_name", "")
+	}
+}
+
+func (c *checker) shell(p *pm.ShellParams) {
+	// The sandbox drops every capability; a root script would lose what it
+	// asked run_as_root for.
+	if p.GetSandbox() != nil && p.GetRunAsRoot() {
+		c.add("shell.sandbox", "excluded_with", "run_as_root", true)
+	}
+	// The agent refuses these when it builds the sandbox: a path that is not
+	// clean names something other than it reads as, and "/" would undo the
+	// read-only root. The tags already require the leading "/".
+	for i, w := range p.GetSandbox().GetWritablePaths() {
+		if strings.HasPrefix(w, "/") && (w == "/" || path.Clean(w) != w) {
+			c.add(fmt.Sprintf("shell.sandbox.writable_paths[%d]", i), "clean_path", "", w)
+		}
+	}
+	if p.GetIsCompliance() {
+		if p.GetDetectionScript() == "" {
+			c.add("shell.detection_script", "required_if", "is_compliance true", "")
+		}
+		return
+	}
+	if p.GetScript() == "" && p.GetDetectionScript() == "" {
+		c.add("shell.script", "required_without_all", "detection_script", "")
+	}
+}
+
+func (c *checker) repository(p *pm.RepositoryParams) {
+	if p.GetApt() == nil && p.GetDnf() == nil && p.GetPacman() == nil && p.GetZypper() == nil {
+		c.add("repository.apt", "required_without_all", "dnf pacman zypper", nil)
+	}
+}
+
+func (c *checker) adminPolicy(p *pm.AdminPolicyParams) {
+	if p.GetAccessLevel() == pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_CUSTOM && p.GetCustomConfig() == "" {
+		c.add("admin_policy.custom_config", "required_if", "access_level "+p.GetAccessLevel().String(), "")
+	}
+	// An unknown backend would leave the agent no policy format to render.
+	if _, known := pm.PrivilegeBackend_name[int32(p.GetBackend())]; !known {
+		c.add("admin_policy.backend", "oneof", privilegeBackends(), p.GetBackend())
+	}
+	// polkit authorizes run0 to start a unit, never the command inside it,
+	// so no rule can limit what an admin runs.
+	switch p.GetAccessLevel() {
+	case pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_LIMITED, pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_TERMINAL_ADMIN_LIMITED:
+		if p.GetBackend() == pm.PrivilegeBackend_PRIVILEGE_BACKEND_RUN0 {
+			c.add("admin_policy.access_level", "ne_if", "backend "+p.GetBackend().String(), p.GetAccessLevel())
+		}
+	}
+}
+
+// privilegeBackends lists the known backends in number order.
+func privilegeBackends() string {
+	names := make([]string, 0, len(pm.PrivilegeBackend_name))
+	for n := range int32(len(pm.PrivilegeBackend_name)) {
+		names = append(names, pm.PrivilegeBackend(n).String())
+	}
+	return strings.Join(names, " ")
+}
+
+func (c *checker) wifi(p *pm.WifiParams) {
+	switch p.GetAuthType() {
+	case pm.WifiAuthType_WIFI_AUTH_TYPE_PSK:
+		if p.GetPsk() == nil {
+			c.add("wifi.psk", "required_if", "auth_type "+p.GetAuthType().String(), nil)
+		}
+	case pm.WifiAuthType_WIFI_AUTH_TYPE_EAP_TLS:
+		if p.GetClientCert() == "" {
+			c.add("wifi.client_cert", "required_if", "auth_type "+p.GetAuthType().String(), "")
+		}
+		if p.GetClientKey() == nil {
+			c.add("wifi.client_key", "required_if", "auth_type "+p.GetAuthType().String(), nil)
+		}
+	}
+}
+
+func (c *checker) agentUpdate(p *pm.AgentUpdateParams) {
+	if p.GetAmd64() == nil && p.GetArm64() == nil {
+		c.add("agent_update.amd64", "required_without_all", "arm64", nil)
+	}
+	for _, arch := range []struct {
+		name string
+		src  *pm.AgentUpdateArch
+	}{{"amd64", p.GetAmd64()}, {"arm64", p.GetArm64()}} {
+		if arch.src != nil && arch.src.GetChecksumUrl() == "" && arch.src.GetExpectedSha256() == "" {
+			c.add("agent_update."+arch.name+".checksum_url", "required_without_all", "expected_sha256", "")
+		}
+	}
+}
+
+// semanticError is a validator.FieldError for a rule checked in code rather
+// than by tag, so both kinds format and aggregate the same way. Field and
+// Namespace are the full snake_case path; Tag reuses the validator tag with
+// the same meaning where one exists.
+type semanticError struct {
+	path, tag, param string
+	value            any
+}
+
+func fieldError(path, tag, param string, value any) validator.FieldError {
+	return &semanticError{path: path, tag: tag, param: param, value: value}
+}
+
+func (e *semanticError) Tag() string             { return e.tag }
+func (e *semanticError) ActualTag() string       { return e.tag }
+func (e *semanticError) Namespace() string       { return e.path }
+func (e *semanticError) StructNamespace() string { return e.path }
+func (e *semanticError) Field() string           { return e.path }
+func (e *semanticError) StructField() string     { return e.path[strings.LastIndexByte(e.path, '.')+1:] }
+func (e *semanticError) Value() any              { return e.value }
+func (e *semanticError) Param() string           { return e.param }
+func (e *semanticError) Translate(ut.Translator) string {
+	return e.Error()
+}
+func (e *semanticError) Error() string { return FormatFieldError(e) }
+
+func (e *semanticError) Kind() reflect.Kind {
+	if e.value == nil {
+		return reflect.Invalid
+	}
+	return reflect.TypeOf(e.value).Kind()
+}
+
+func (e *semanticError) Type() reflect.Type {
+	if e.value == nil {
+		return nil
+	}
+	return reflect.TypeOf(e.value)
+}
+
+// siblings renders the space-separated field names of a cross-field tag
+// parameter as paths next to field: "apt_name dnf_name" beside
+// "package.name" becomes "package.apt_name", "package.dnf_name".
+func siblings(field, names string) []string {
+	prefix := field[:strings.LastIndexByte(field, '.')+1]
+	var out []string
+	for _, name := range strings.Fields(names) {
+		out = append(out, prefix+ToSnakeCase(name))
+	}
+	return out
+}
+
+// conditions renders a required_if parameter, "Field value" pairs, as
+// "field is value" clauses beside field.
+func conditions(field, param string) string {
+	parts := strings.Fields(param)
+	var out []string
+	for i := 0; i+1 < len(parts); i += 2 {
+		out = append(out, fmt.Sprintf("%s is %s", siblings(field, parts[i])[0], parts[i+1]))
+	}
+	return strings.Join(out, " and ")
+}
@@ -0,0 +1,202 @@
+package validate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	pm "github.com/manchtools/power-manage-sdk/gen/go/powermanage/v1"
+)
+
+func sealed() *pm.SealedValue {
+	return &pm.SealedValue{Version: 1, Ciphertext: bytes.Repeat([]byte{1}, 61), KeyId: bytes.Repeat([]byte{2}, 8)}
+}
+
+func TestAction(t *testing.T) {
+	id := &pm.ActionId{Value: "01JNXZQK7C93M0F42YVSDHE5DA"}
+	tests := []struct {
+		name   string
+		action *pm.Action
+		want   []string // formatted messages, in order; none means valid
+	}{
+		{
+			name:   "nil action",
+			action: nil,
+			want:   []string{"action is required"},
+		},
+		{
+			name:   "package with a generic name",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_PACKAGE, Params: &pm.Action_Package{Package: &pm.PackageParams{Name: "htop"}}},
+		},
+		{
+			name:   "package with only a manager-specific name",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_PACKAGE, Params: &pm.Action_Package{Package: &pm.PackageParams{DnfName: "htop"}}},
+		},
+		{
+			name:   "package without any name",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_PACKAGE, Params: &pm.Action_Package{Package: &pm.PackageParams{}}},
+			want:   []string{"one of package.name, package.apt_name, package.dnf_name, package.pacman_name, package.zypper_name is required"},
+		},
+		{
+			name:   "params variant does not match the type",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_DEB, Params: &pm.Action_Package{Package: &pm.PackageParams{Name: "htop"}}},
+			want:   []string{"params must be app for this action type"},
+		},
+		{
+			name:   "params missing",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_FILE},
+			want:   []string{"params must be file for this action type"},
+		},
+		{
+			name:   "instant action without params",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_REBOOT},
+		},
+		{
+			name:   "instant action with params",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_SYNC, Params: &pm.Action_Update{Update: &pm.UpdateParams{}}},
+			want:   []string{"params must be empty for this action type"},
+		},
+		{
+			name:   "shell without any script",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_SHELL, Params: &pm.Action_Shell{Shell: &pm.ShellParams{}}},
+			want:   []string{"one of shell.script, shell.detection_script is required"},
+		},
+		{
+			name:   "compliance check without a detection script",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_SHELL, Params: &pm.Action_Shell{Shell: &pm.ShellParams{Script: "true", IsCompliance: true}}},
+			want:   []string{"shell.detection_script is required when shell.is_compliance is true"},
+		},
+		{
+			name:   "sandboxed root script",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_SHELL, Params: &pm.Action_Shell{Shell: &pm.ShellParams{Script: "true", RunAsRoot: true, Sandbox: &pm.ShellSandbox{}}}},
+			want:   []string{"shell.sandbox must be unset when shell.run_as_root is set"},
+		},
+		{
+			name:   "sandboxed script",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_SHELL, Params: &pm.Action_Shell{Shell: &pm.ShellParams{Script: "true", Sandbox: &pm.ShellSandbox{WritablePaths: []string{"/var/lib/app"}}}}},
+		},
+		{
+			name:   "sandbox writable paths the agent refuses",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_SHELL, Params: &pm.Action_Shell{Shell: &pm.ShellParams{Script: "true", Sandbox: &pm.ShellSandbox{WritablePaths: []string{"/", "/a/../b", "//x", "/var/lib/app/"}}}}},
+			want: []string{
+				"shell.sandbox.writable_paths[0] must be a clean absolute path other than /",
+				"shell.sandbox.writable_paths[1] must be a clean absolute path other than /",
+				"shell.sandbox.writable_paths[2] must be a clean absolute path other than /",
+				"shell.sandbox.writable_paths[3] must be a clean absolute path other than /",
+			},
+		},
+		{
+			name:   "repository without a manager",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_REPOSITORY, Params: &pm.Action_Repository{Repository: &pm.RepositoryParams{Name: "corp"}}},
+			want:   []string{"one of repository.apt, repository.dnf, repository.pacman, repository.zypper is required"},
+		},
+		{
+			name: "custom admin policy without config",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_ADMIN_POLICY, Params: &pm.Action_AdminPolicy{AdminPolicy: &pm.AdminPolicyParams{
+				AccessLevel: pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_CUSTOM, Users: []string{"alice"},
+			}}},
+			want: []string{"admin_policy.custom_config is required when admin_policy.access_level is ADMIN_ACCESS_LEVEL_CUSTOM"},
+		},
+		{
+			name: "full admin policy needs no config",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_ADMIN_POLICY, Params: &pm.Action_AdminPolicy{AdminPolicy: &pm.AdminPolicyParams{
+				AccessLevel: pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_FULL, Users: []string{"alice"},
+			}}},
+		},
+		{
+			name: "limited admin policy under run0",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_ADMIN_POLICY, Params: &pm.Action_AdminPolicy{AdminPolicy: &pm.AdminPolicyParams{
+				AccessLevel: pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_LIMITED, Users: []string{"alice"}, Backend: pm.PrivilegeBackend_PRIVILEGE_BACKEND_RUN0,
+			}}},
+			want: []string{"admin_policy.access_level must not be ADMIN_ACCESS_LEVEL_LIMITED when admin_policy.backend is PRIVILEGE_BACKEND_RUN0"},
+		},
+		{
+			name: "limited admin policy under pkexec",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_ADMIN_POLICY, Params: &pm.Action_AdminPolicy{AdminPolicy: &pm.AdminPolicyParams{
+				AccessLevel: pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_LIMITED, Users: []string{"alice"}, Backend: pm.PrivilegeBackend_PRIVILEGE_BACKEND_PKEXEC,
+			}}},
+		},
+		{
+			name: "admin policy for an unknown backend",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_ADMIN_POLICY, Params: &pm.Action_AdminPolicy{AdminPolicy: &pm.AdminPolicyParams{
+				AccessLevel: pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_FULL, Users: []string{"alice"}, Backend: pm.PrivilegeBackend(4),
+			}}},
+			want: []string{"admin_policy.backend must be one of: PRIVILEGE_BACKEND_SUDO PRIVILEGE_BACKEND_DOAS PRIVILEGE_BACKEND_RUN0 PRIVILEGE_BACKEND_PKEXEC"},
+		},
+		{
+			name:   "psk wifi without a key",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_WIFI, Params: &pm.Action_Wifi{Wifi: &pm.WifiParams{Ssid: "corp", AuthType: pm.WifiAuthType_WIFI_AUTH_TYPE_PSK}}},
+			want:   []string{"wifi.psk is required when wifi.auth_type is WIFI_AUTH_TYPE_PSK"},
+		},
+		{
+			name:   "eap-tls wifi without a client cert and key",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_WIFI, Params: &pm.Action_Wifi{Wifi: &pm.WifiParams{Ssid: "corp", AuthType: pm.WifiAuthType_WIFI_AUTH_TYPE_EAP_TLS, Psk: sealed()}}},
+			want: []string{
+				"wifi.client_cert is required when wifi.auth_type is WIFI_AUTH_TYPE_EAP_TLS",
+				"wifi.client_key is required when wifi.auth_type is WIFI_AUTH_TYPE_EAP_TLS",
+			},
+		},
+		{
+			name: "eap-tls wifi with a client cert and key",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_WIFI, Params: &pm.Action_Wifi{Wifi: &pm.WifiParams{
+				Ssid: "corp", AuthType: pm.WifiAuthType_WIFI_AUTH_TYPE_EAP_TLS, ClientCert: "-----BEGIN CERTIFICATE-----", ClientKey: sealed(),
+			}}},
+		},
+		{
+			name:   "agent update without an architecture",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_AGENT_UPDATE, Params: &pm.Action_AgentUpdate{AgentUpdate: &pm.AgentUpdateParams{}}},
+			want:   []string{"one of agent_update.amd64, agent_update.arm64 is required"},
+		},
+		{
+			name: "agent update without an integrity source",
+			action: &pm.Action{Id: id, Type: pm.ActionType_ACTION_TYPE_AGENT_UPDATE, Params: &pm.Action_AgentUpdate{AgentUpdate: &pm.AgentUpdateParams{
+				Amd64: &pm.AgentUpdateArch{BinaryUrl: "https://example.com/agent", ChecksumUrl: "https://example.com/SHA256SUMS"},
+				Arm64: &pm.AgentUpdateArch{BinaryUrl: "https://example.com/agent-arm64"},
+			}}},
+			want: []string{"one of agent_update.arm64.checksum_url, agent_update.arm64.expected_sha256 is required"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Action(tt.action)
+			var got []string
+			for _, e := range errs {
+				got = append(got, FormatFieldError(e))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Action() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// Semantic and tag errors aggregate through the same formatter, and a tag
+// error for the same rule reads the same way.
+func TestAction_FormatsLikeTagErrors(t *testing.T) {
+	errs := Action(&pm.Action{Type: pm.ActionType_ACTION_TYPE_SHELL, Params: &pm.Action_Shell{Shell: &pm.ShellParams{}}})
+	if got, want := FormatValidationErrors(errs), "validation failed: one of shell.script, shell.detection_script is required"; got != want {
+		t.Errorf("FormatValidationErrors = %q, want %q", got, want)
+	}
+	if errs[0].Error() != FormatFieldError(errs[0]) {
+		t.Errorf("Error() = %q, want the formatted message", errs[0].Error())
+	}
+
+	msg, ok := Struct(NewValidator(), &pm.AdminPolicyParams{AccessLevel: pm.AdminAccessLevel_ADMIN_ACCESS_LEVEL_CUSTOM, Users: []string{"alice"}})
+	if ok || !strings.Contains(msg, "custom_config is required when access_level is 3") {
+		t.Errorf("Struct = %q, want the required_if message", msg)
+	}
+}
+
+// Every action type is held to a params variant, so a new type cannot ship
+// without deciding what it carries.
+func TestAction_EveryTypeHasParams(t *testing.T) {
+	for n := range pm.ActionType_name {
+		typ := pm.ActionType(n)
+		if typ == pm.ActionType_ACTION_TYPE_UNSPECIFIED {
+			continue
+		}
+		if _, ok := paramsFor[typ]; !ok {
+			t.Errorf("%s has no entry in paramsFor", typ)
+		}
+	}
+}
@@ -81,6 +81,21 @@ func FormatFieldError(e validator.FieldError) string {
 		return fmt.Sprintf("%s must be one of: %s", field, e.Param())
 	case "startswith":
 		return fmt.Sprintf("%s must start with %s", field, e.Param())
+	case "required_if":
+		return fmt.Sprintf("%s is required when %s", field, conditions(field, e.Param()))
+	case "ne_if":
+		return fmt.Sprintf("%s must not be %v when %s", field, e.Value(), conditions(field, e.Param()))
+	case "excluded_with":
+		return fmt.Sprintf("%s must be unset when %s is set", field, strings.Join(siblings(field, e.Param()), ", "))
+	case "required_without_all":
+		return fmt.Sprintf("one of %s is required", strings.Join(append([]string{field}, siblings(field, e.Param())...), ", "))
+	case "clean_path":
+		return fmt.Sprintf("%s must be a clean absolute path other than /", field)
+	case "params":
+		if e.Param() == "" {
+			return fmt.Sprintf("%s must be empty for this action type", field)
+		}
+		return fmt.Sprintf("%s must be %s for this action type", field, e.Param())
 	default:
 		return fmt.Sprintf("%s failed validation: %s", field, e.Tag())
 	}